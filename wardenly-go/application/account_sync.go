@@ -0,0 +1,192 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+	"wardenly-go/domain/account"
+	"wardenly-go/infrastructure/sheetsync"
+)
+
+// accountSyncTimeout bounds reading the spreadsheet and saving its changes.
+const accountSyncTimeout = 2 * time.Minute
+
+// AccountSync imports account changes from an external spreadsheet. The UI
+// previews the differences and applies the ones the user keeps; when the
+// settings set an interval, the sheet is also re-synced on a timer and all
+// changes are applied. Accounts missing from the sheet are never deleted.
+type AccountSync struct {
+	accountService *account.Service
+	eventBus       eventbus.EventBus
+	reader         *sheetsync.Reader
+	settingsPath   string
+	logger         *slog.Logger
+
+	mu       sync.Mutex
+	settings sheetsync.Settings
+
+	reschedule chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// AccountSyncConfig holds configuration for AccountSync.
+type AccountSyncConfig struct {
+	AccountService *account.Service
+	EventBus       eventbus.EventBus // Optional; receives AccountsSynced
+	// Reader reads the spreadsheet. Defaults to sheetsync.NewReader(nil).
+	Reader *sheetsync.Reader
+	// Settings are the sync settings loaded at startup
+	Settings sheetsync.Settings
+	// SettingsPath is where changed settings are saved (optional)
+	SettingsPath string
+	Logger       *slog.Logger
+}
+
+// NewAccountSync creates an account sync. Call Start to enable scheduled re-syncs.
+func NewAccountSync(cfg *AccountSyncConfig) *AccountSync {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.Reader == nil {
+		cfg.Reader = sheetsync.NewReader(nil)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &AccountSync{
+		accountService: cfg.AccountService,
+		eventBus:       cfg.EventBus,
+		reader:         cfg.Reader,
+		settingsPath:   cfg.SettingsPath,
+		logger:         cfg.Logger,
+		settings:       cfg.Settings,
+		reschedule:     make(chan struct{}, 1),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// Start begins the scheduled re-sync loop.
+func (s *AccountSync) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop ends the re-sync loop, waiting for a running sync to finish.
+func (s *AccountSync) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Settings returns the current sync settings.
+func (s *AccountSync) Settings() sheetsync.Settings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.settings
+}
+
+// SetSettings replaces the sync settings, saves them and restarts the
+// re-sync timer. The settings are used even if saving fails.
+func (s *AccountSync) SetSettings(settings sheetsync.Settings) error {
+	s.mu.Lock()
+	s.settings = settings
+	s.mu.Unlock()
+
+	select {
+	case s.reschedule <- struct{}{}:
+	default:
+	}
+
+	if s.settingsPath == "" {
+		return nil
+	}
+	return sheetsync.SaveSettings(s.settingsPath, settings)
+}
+
+// Preview reads the spreadsheet and compares it with all stored accounts,
+// archived ones included. Nothing is saved.
+func (s *AccountSync) Preview(ctx context.Context) (*account.SyncPlan, error) {
+	settings := s.Settings()
+	sheet, err := s.reader.Read(ctx, settings.Source)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := s.accountService.ListAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load accounts: %w", err)
+	}
+	return account.PlanSync(sheet, settings.Columns, accounts)
+}
+
+// Apply saves the given changes of a preview.
+func (s *AccountSync) Apply(ctx context.Context, changes []account.SyncChange) error {
+	if err := s.accountService.ApplySync(ctx, changes); err != nil {
+		return err
+	}
+	s.logger.Info("Accounts synced", "changes", len(changes))
+	return nil
+}
+
+func (s *AccountSync) loop() {
+	defer s.wg.Done()
+
+	for {
+		var tick <-chan time.Time
+		var timer *time.Timer
+		if interval := s.Settings().Interval(); interval > 0 {
+			timer = time.NewTimer(interval)
+			tick = timer.C
+		}
+
+		select {
+		case <-s.ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-s.reschedule:
+			if timer != nil {
+				timer.Stop()
+			}
+		case <-tick:
+			s.syncScheduled()
+		}
+	}
+}
+
+// syncScheduled applies every change in the spreadsheet and reports the
+// outcome on the event bus.
+func (s *AccountSync) syncScheduled() {
+	ctx, cancel := context.WithTimeout(s.ctx, accountSyncTimeout)
+	defer cancel()
+
+	plan, err := s.Preview(ctx)
+	if err == nil {
+		err = s.Apply(ctx, plan.Changes)
+	}
+	if err != nil {
+		s.logger.Warn("Scheduled account sync failed", "error", err)
+		s.publish(event.NewAccountsSynced(0, 0, 0, err))
+		return
+	}
+
+	added, updated := plan.Counts()
+	for _, rowErr := range plan.RowErrors {
+		s.logger.Warn("Account sync skipped a row", "error", rowErr)
+	}
+	if added > 0 || updated > 0 || len(plan.RowErrors) > 0 {
+		s.publish(event.NewAccountsSynced(added, updated, len(plan.RowErrors), nil))
+	}
+}
+
+func (s *AccountSync) publish(e event.Event) {
+	if s.eventBus != nil {
+		s.eventBus.Publish(e)
+	}
+}
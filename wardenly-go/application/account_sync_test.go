@@ -0,0 +1,118 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"wardenly-go/domain/account"
+	"wardenly-go/infrastructure/sheetsync"
+)
+
+// memAccountRepo is an in-memory account.Repository.
+type memAccountRepo struct {
+	mu       sync.Mutex
+	accounts map[string]*account.Account
+	nextID   int
+}
+
+func (r *memAccountRepo) FindByID(ctx context.Context, id string) (*account.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if acc, ok := r.accounts[id]; ok {
+		return acc.Clone(), nil
+	}
+	return nil, nil
+}
+
+func (r *memAccountRepo) FindAll(ctx context.Context) ([]*account.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	all := make([]*account.Account, 0, len(r.accounts))
+	for _, acc := range r.accounts {
+		all = append(all, acc.Clone())
+	}
+	return all, nil
+}
+
+func (r *memAccountRepo) Insert(ctx context.Context, acc *account.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	acc.ID = "new" + strconv.Itoa(r.nextID)
+	r.accounts[acc.ID] = acc.Clone()
+	return nil
+}
+
+func (r *memAccountRepo) Update(ctx context.Context, acc *account.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accounts[acc.ID] = acc.Clone()
+	return nil
+}
+
+func (r *memAccountRepo) UpdateCookies(ctx context.Context, id string, cookies []account.Cookie) error {
+	return nil
+}
+
+func (r *memAccountRepo) UpdateScriptParams(ctx context.Context, id, scriptName string, params map[string]string) error {
+	return nil
+}
+
+func (r *memAccountRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.accounts, id)
+	return nil
+}
+
+func TestAccountSync_PreviewApply(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "accounts.csv")
+	csv := "Server,Role,ranking\n1,Alice,3\n1,Bob,1\n"
+	if err := os.WriteFile(source, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &memAccountRepo{accounts: map[string]*account.Account{
+		"a1": {ID: "a1", ServerID: 1, RoleName: "Alice", Ranking: 1, Archived: true},
+	}}
+	settingsPath := filepath.Join(dir, "account_sync.json")
+	syncer := NewAccountSync(&AccountSyncConfig{
+		AccountService: account.NewService(repo),
+		SettingsPath:   settingsPath,
+	})
+
+	err := syncer.SetSettings(sheetsync.Settings{
+		Source:  source,
+		Columns: account.ColumnMapping{account.SyncFieldServerID: "Server", account.SyncFieldRoleName: "Role"},
+	})
+	if err != nil {
+		t.Fatalf("SetSettings() error = %v", err)
+	}
+	if saved, err := sheetsync.LoadSettings(settingsPath); err != nil || saved.Source != source {
+		t.Errorf("saved settings = %+v, %v", saved, err)
+	}
+
+	plan, err := syncer.Preview(context.Background())
+	if err != nil {
+		t.Fatalf("Preview() error = %v", err)
+	}
+	// Archived accounts are matched too, so Alice is updated rather than re-added
+	if added, updated := plan.Counts(); added != 1 || updated != 1 {
+		t.Fatalf("Counts() = %d added, %d updated, want 1 and 1", added, updated)
+	}
+	if repo.accounts["a1"].Ranking != 1 {
+		t.Error("Preview() should not save changes")
+	}
+
+	if err := syncer.Apply(context.Background(), plan.Changes); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(repo.accounts) != 2 || repo.accounts["a1"].Ranking != 3 {
+		t.Errorf("accounts after Apply() = %+v", repo.accounts)
+	}
+}
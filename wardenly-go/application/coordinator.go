@@ -4,14 +4,17 @@ package application
 import (
 	"context"
 	"fmt"
+	"image"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"wardenly-go/application/session"
 	"wardenly-go/core/command"
 	"wardenly-go/core/event"
 	"wardenly-go/core/eventbus"
+	"wardenly-go/core/state"
 	"wardenly-go/domain/account"
 	domainscene "wardenly-go/domain/scene"
 	domainscript "wardenly-go/domain/script"
@@ -25,21 +28,64 @@ type Coordinator struct {
 	sessions   map[string]*session.Session
 	sessionsMu sync.RWMutex
 
+	// Auto cleanup of sessions whose script finished normally
+	stopOnFinish    atomic.Bool
+	stopOnFinishIDs map[string]bool // per-launch opt-in, guarded by sessionsMu
+	finishing       map[string]bool // sessions waiting for cookies before stop, guarded by sessionsMu
+
+	// scriptClaims maps sessions to the script started on them, so exclusion
+	// groups can be checked before the session reports ScriptStarted.
+	// Guarded by sessionsMu.
+	scriptClaims map[string]string
+
+	// setups holds the first-login setup of sessions whose account needs
+	// one, until the setup script stops. Guarded by sessionsMu.
+	setups map[string]*setupRun
+
+	// canvases holds the canvas size sessions were started at, so a
+	// relaunched browser keeps it. Guarded by sessionsMu.
+	canvases map[string]browser.CanvasSize
+
+	// logins holds the sessions started by the coordinator that are still
+	// Starting or LoggingIn. With maxLogins set, sessions started while
+	// it is full wait in startQueue. Guarded by sessionsMu.
+	maxLogins  int
+	logins     map[string]bool
+	startQueue []queuedStart
+
 	// Dependencies
 	eventBus       eventbus.EventBus
 	sceneRegistry  *domainscene.Registry
 	scriptRegistry *domainscript.Registry
 	ocrClient      ocr.Client
+	accountService *account.Service
+	flushStats     func(ctx context.Context) error
 	driverFactory  DriverFactory
+	browserBase    *browser.DriverConfig
+	sceneThreshold float64
+	watchdog       session.WatchdogConfig
+	reconnect      session.ReconnectConfig
+	loginRetry     session.LoginRetryConfig
+	loginFlow      *session.LoginFlow
+	dataTap        *session.DataTap
+	captcha        session.CaptchaConfig
+	nearMisses     *domainscene.NearMisses
+	matchPool      *domainscene.Pool
+	observeMatch   func(matched bool)
 	logger         *slog.Logger
 
+	// Login page selectors for new sessions, replaced by calibration
+	loginProfile     browser.LoginProfile
+	loginProfilePath string
+	loginProfileMu   sync.RWMutex
+
 	// Lifecycle
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
-// DriverFactory creates browser drivers.
-type DriverFactory func() browser.Driver
+// DriverFactory creates a browser driver from a per-session configuration.
+type DriverFactory func(config *browser.DriverConfig) browser.Driver
 
 // CoordinatorConfig holds configuration for the Coordinator.
 type CoordinatorConfig struct {
@@ -47,8 +93,63 @@ type CoordinatorConfig struct {
 	SceneRegistry  *domainscene.Registry
 	ScriptRegistry *domainscript.Registry
 	OCRClient      ocr.Client
+	AccountService *account.Service                // Optional: persists cookies of auto-stopped sessions
+	FlushStats     func(ctx context.Context) error // Optional: records the statistics of auto-stopped sessions
 	DriverFactory  DriverFactory
 	Logger         *slog.Logger
+
+	// Browser is the base browser configuration of new sessions
+	// (browser.DefaultDriverConfig if nil)
+	Browser *browser.DriverConfig
+
+	// SceneThreshold is the matcher threshold of scenes without their own
+	// (the matcher default if zero)
+	SceneThreshold float64
+
+	// LoginProfile holds the login page selectors (defaults if empty).
+	// LoginProfilePath, if set, is where calibrated profiles are saved.
+	LoginProfile     browser.LoginProfile
+	LoginProfilePath string
+
+	// StopOnScriptFinish stops sessions whose script completed normally
+	// or ran out of resources
+	StopOnScriptFinish bool
+
+	// Watchdog handles scripts that match no scene for too long
+	// (disabled if zero)
+	Watchdog session.WatchdogConfig
+
+	// Reconnect restarts and logs in sessions whose browser crashed
+	// (disabled if zero)
+	Reconnect session.ReconnectConfig
+
+	// LoginRetry retries failed logins, falling back from cookies to the
+	// password (tried once if zero)
+	LoginRetry session.LoginRetryConfig
+
+	// LoginFlow, if set, replaces the drivers' built-in password login
+	LoginFlow *session.LoginFlow
+
+	// DataTap, if set, reads resource values from the game's API
+	// responses into script variables
+	DataTap *session.DataTap
+
+	// Captcha answers captchas shown while sessions log in (none if its
+	// Solver is nil)
+	Captcha session.CaptchaConfig
+
+	// SceneTuning suggests or raises the thresholds of scenes that keep
+	// nearly matching across sessions (disabled if zero)
+	SceneTuning domainscene.NearMissConfig
+
+	// MaxConcurrentLogins is how many sessions may be starting their
+	// browser or logging in at once; later ones are queued (no limit if
+	// zero). Reconnects after a crash are not counted.
+	MaxConcurrentLogins int
+
+	// ObserveSceneMatch, if set, is told whether each frame a script
+	// checks matched one of its steps, in every session
+	ObserveSceneMatch func(matched bool)
 }
 
 // NewCoordinator creates a new session coordinator.
@@ -60,15 +161,40 @@ func NewCoordinator(cfg *CoordinatorConfig) *Coordinator {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	c := &Coordinator{
-		sessions:       make(map[string]*session.Session),
-		eventBus:       cfg.EventBus,
-		sceneRegistry:  cfg.SceneRegistry,
-		scriptRegistry: cfg.ScriptRegistry,
-		ocrClient:      cfg.OCRClient,
-		driverFactory:  cfg.DriverFactory,
-		logger:         cfg.Logger,
-		ctx:            ctx,
-		cancel:         cancel,
+		sessions:        make(map[string]*session.Session),
+		stopOnFinishIDs: make(map[string]bool),
+		finishing:       make(map[string]bool),
+		scriptClaims:    make(map[string]string),
+		setups:          make(map[string]*setupRun),
+		canvases:        make(map[string]browser.CanvasSize),
+		maxLogins:       cfg.MaxConcurrentLogins,
+		logins:          make(map[string]bool),
+		eventBus:        cfg.EventBus,
+		sceneRegistry:   cfg.SceneRegistry,
+		scriptRegistry:  cfg.ScriptRegistry,
+		ocrClient:       cfg.OCRClient,
+		accountService:  cfg.AccountService,
+		flushStats:      cfg.FlushStats,
+		driverFactory:   cfg.DriverFactory,
+		browserBase:     cfg.Browser,
+		sceneThreshold:  cfg.SceneThreshold,
+		watchdog:        cfg.Watchdog,
+		reconnect:       cfg.Reconnect,
+		loginRetry:      cfg.LoginRetry,
+		loginFlow:       cfg.LoginFlow,
+		dataTap:         cfg.DataTap,
+		captcha:         cfg.Captcha,
+		observeMatch:    cfg.ObserveSceneMatch,
+		matchPool:       domainscene.NewPool(0),
+		logger:          cfg.Logger,
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+	c.stopOnFinish.Store(cfg.StopOnScriptFinish)
+	c.loginProfile = cfg.LoginProfile
+	c.loginProfilePath = cfg.LoginProfilePath
+	if cfg.SceneTuning.Enabled() {
+		c.nearMisses = domainscene.NewNearMisses(cfg.SceneTuning, c.publishSceneSuggestion)
 	}
 
 	// Subscribe to events if event bus is available
@@ -94,6 +220,8 @@ func (c *Coordinator) Stop() {
 		sessions = append(sessions, s)
 	}
 	c.sessions = make(map[string]*session.Session)
+	c.logins = make(map[string]bool)
+	c.startQueue = nil
 	c.sessionsMu.Unlock()
 
 	// Stop all sessions in parallel
@@ -118,13 +246,16 @@ func (c *Coordinator) Stop() {
 	case <-time.After(5 * time.Second):
 		c.logger.Warn("Coordinator stop timeout, some sessions may not have stopped cleanly")
 	}
+	// Sessions still matching after the timeout check scenes themselves
+	c.matchPool.Close()
 
 	c.logger.Info("Coordinator stopped")
 }
 
 // Dispatch sends a command to the appropriate handler.
 func (c *Coordinator) Dispatch(cmd command.Command) error {
-	c.logger.Debug("Dispatching command", "command", cmd.CommandName())
+	correlationID := command.Correlate(cmd)
+	c.logger.Debug("Dispatching command", "command", cmd.CommandName(), "correlation_id", correlationID)
 
 	switch cmd := cmd.(type) {
 	// Session lifecycle
@@ -134,6 +265,12 @@ func (c *Coordinator) Dispatch(cmd command.Command) error {
 		return c.handleStopSession(cmd)
 	case *command.StopAllSessions:
 		return c.handleStopAllSessions(cmd)
+	case *command.RelaunchSession:
+		return c.handleRelaunchSession(cmd)
+	case *command.SetStopOnScriptFinish:
+		c.stopOnFinish.Store(cmd.Enabled)
+		c.logger.Info("Stop on script finish changed", "enabled", cmd.Enabled)
+		return nil
 
 	// Multi-session operations
 	case *command.ClickAll:
@@ -146,6 +283,8 @@ func (c *Coordinator) Dispatch(cmd command.Command) error {
 		return c.handleStopAllScripts(cmd)
 	case *command.SyncScriptSelection:
 		return c.handleSyncScriptSelection(cmd)
+	case *command.StartScript:
+		return c.handleStartScript(cmd)
 
 	// Session-specific commands
 	default:
@@ -158,6 +297,12 @@ func (c *Coordinator) Dispatch(cmd command.Command) error {
 
 // CreateSession creates a new session for an account.
 func (c *Coordinator) CreateSession(acc *account.Account) (*session.Session, error) {
+	return c.createSession(acc, browser.CanvasSize{})
+}
+
+// createSession creates a new session for an account. A non-zero canvas
+// replaces the viewport, and scene and script coordinates are scaled to it.
+func (c *Coordinator) createSession(acc *account.Account, canvas browser.CanvasSize) (*session.Session, error) {
 	c.sessionsMu.Lock()
 	defer c.sessionsMu.Unlock()
 
@@ -168,32 +313,236 @@ func (c *Coordinator) CreateSession(acc *account.Account) (*session.Session, err
 	}
 
 	// Create browser driver
+	config := driverConfig(c.browserBase, acc)
+	config.Login = c.LoginProfile()
+	canvas.Apply(config)
 	var driver browser.Driver
 	if c.driverFactory != nil {
-		driver = c.driverFactory()
+		driver = c.driverFactory(config)
 	} else {
-		driver = browser.NewChromeDPDriver(nil)
+		driver = browser.NewChromeDPDriver(config)
 	}
 
 	// Create session
 	sess := session.New(&session.Config{
-		ID:             sessionID,
-		Account:        acc,
-		Driver:         driver,
-		EventBus:       c.eventBus,
-		SceneRegistry:  c.sceneRegistry,
-		ScriptRegistry: c.scriptRegistry,
-		OCRClient:      c.ocrClient,
-		Logger:         c.logger.With("account", acc.Identity()),
+		ID:                sessionID,
+		Account:           acc,
+		Driver:            driver,
+		EventBus:          c.eventBus,
+		SceneRegistry:     c.sceneRegistry,
+		ScriptRegistry:    c.scriptRegistry,
+		OCRClient:         c.ocrClient,
+		Watchdog:          c.watchdog,
+		Reconnect:         c.reconnect,
+		LoginRetry:        c.loginRetry,
+		Captcha:           c.captcha,
+		LoginURL:          loginURL(config.Login, acc),
+		LoginFlow:         c.loginFlow,
+		DataTap:           c.dataTap,
+		NearMisses:        c.nearMisses,
+		MatchPool:         c.matchPool,
+		Frame:             config.Login.Frame,
+		FrameOrigin:       config.Login.FrameOrigin,
+		Scale:             canvas.Scale(),
+		SceneThreshold:    c.sceneThreshold,
+		ObserveSceneMatch: c.observeMatch,
+		Logger:            c.logger.With("account", acc.Identity()),
 	})
 
 	c.sessions[sessionID] = sess
+	c.canvases[sessionID] = canvas
 	sess.Start()
 
 	c.logger.Info("Session created", "session_id", sessionID, "account", acc.Identity())
 	return sess, nil
 }
 
+// loginURL returns the login page of the account's server, from the
+// account's URL template or else the login profile's.
+func loginURL(profile browser.LoginProfile, acc *account.Account) string {
+	template := profile.URLTemplate
+	if acc.URLTemplate != "" {
+		template = acc.URLTemplate
+	}
+	return browser.LoginURL(template, acc.ServerID)
+}
+
+// driverConfig returns a copy of the base browser configuration (the
+// default if nil) with the account's proxy and browser settings merged over it.
+func driverConfig(base *browser.DriverConfig, acc *account.Account) *browser.DriverConfig {
+	config := browser.DefaultDriverConfig()
+	if base != nil {
+		copied := *base
+		config = &copied
+	}
+	if acc.Proxy.Enabled() {
+		config.Proxy = &browser.Proxy{
+			Host:     acc.Proxy.Host,
+			Port:     acc.Proxy.Port,
+			Username: acc.Proxy.Username,
+			Password: acc.Proxy.Password,
+		}
+	}
+
+	if s := acc.Browser; !s.IsZero() {
+		if s.Headless != nil {
+			config.Headless = *s.Headless
+		}
+		// Resize the window with the viewport, keeping the browser chrome margin
+		if s.ViewportWidth > 0 {
+			config.WindowWidth += s.ViewportWidth - config.ViewportWidth
+			config.ViewportWidth = s.ViewportWidth
+		}
+		if s.ViewportHeight > 0 {
+			config.WindowHeight += s.ViewportHeight - config.ViewportHeight
+			config.ViewportHeight = s.ViewportHeight
+		}
+		if s.UserDataDir != "" {
+			config.UserDataDir = s.UserDataDir
+		}
+		if s.PageScale > 0 {
+			config.PageScale = s.PageScale
+		}
+		if s.DeviceScaleFactor > 0 {
+			config.DeviceScaleFactor = s.DeviceScaleFactor
+		}
+		config.Mobile = s.Mobile
+	}
+	return config
+}
+
+// LoginProfile returns the login page selectors used by new sessions.
+func (c *Coordinator) LoginProfile() browser.LoginProfile {
+	c.loginProfileMu.RLock()
+	defer c.loginProfileMu.RUnlock()
+	return c.loginProfile
+}
+
+// CalibrateLogin opens the login page of the account's server in a visible
+// browser and records the login form elements the user clicks (see
+// browser.CalibrateLogin). The resulting profile is saved and used by
+// sessions started afterwards. It blocks until the user is done, so call it
+// off the UI thread.
+func (c *Coordinator) CalibrateLogin(ctx context.Context, acc *account.Account, onStep func(browser.LoginField)) (browser.LoginProfile, error) {
+	config := driverConfig(c.browserBase, acc)
+	config.Login = c.LoginProfile()
+
+	profile, err := browser.CalibrateLogin(ctx, config, loginURL(config.Login, acc), onStep)
+	if err != nil {
+		return browser.LoginProfile{}, err
+	}
+
+	c.loginProfileMu.Lock()
+	c.loginProfile = profile
+	path := c.loginProfilePath
+	c.loginProfileMu.Unlock()
+
+	c.logger.Info("Login profile calibrated",
+		"username", profile.Username, "password", profile.Password, "submit", profile.Submit)
+	if path != "" {
+		if err := browser.SaveLoginProfile(path, profile); err != nil {
+			return profile, err
+		}
+	}
+	return profile, nil
+}
+
+// DetectFrameOrigin returns where the game frame of a running session
+// currently starts, to use as the profile's FrameOrigin.
+func (c *Coordinator) DetectFrameOrigin(ctx context.Context, sessionID string) (browser.Point, error) {
+	sess := c.GetSession(sessionID)
+	if sess == nil {
+		return browser.Point{}, fmt.Errorf("session not found: %s", sessionID)
+	}
+	if !sess.State().CanAcceptOperations() {
+		return browser.Point{}, fmt.Errorf("session %s is not ready", sessionID)
+	}
+	selector := c.LoginProfile().Frame
+	if selector == "" {
+		selector = browser.DefaultLoginProfile().Frame
+	}
+	return sess.GetBrowserController().FrameOrigin(ctx, selector)
+}
+
+// SetFrameOrigin sets where scene and script coordinates assume the game
+// frame starts (nil turns translation off) and saves the login profile.
+// Sessions started afterwards use it.
+func (c *Coordinator) SetFrameOrigin(origin *browser.Point) (browser.LoginProfile, error) {
+	c.loginProfileMu.Lock()
+	c.loginProfile.FrameOrigin = origin
+	profile := c.loginProfile
+	path := c.loginProfilePath
+	c.loginProfileMu.Unlock()
+
+	c.logger.Info("Frame origin set", "origin", origin)
+	if path != "" {
+		if err := browser.SaveLoginProfile(path, profile); err != nil {
+			return profile, err
+		}
+	}
+	return profile, nil
+}
+
+// StartSessionCommand builds the command that starts a session for a stored account.
+// RoleName (not Identity) is sent to avoid double-prefixing with ServerID.
+func StartSessionCommand(acc *account.Account) *command.StartSession {
+	cmd := &command.StartSession{
+		AccountID:      acc.ID,
+		RoleName:       acc.RoleName,
+		UserName:       acc.UserName,
+		Password:       acc.Password,
+		ServerID:       acc.ServerID,
+		URLTemplate:    acc.URLTemplate,
+		ScriptParams:   acc.ScriptParams,
+		AllowedScripts: acc.AllowedScripts,
+		BlockedScripts: acc.BlockedScripts,
+	}
+
+	if acc.NeedsSetup() {
+		cmd.SetupScript = acc.SetupScript
+	}
+
+	if acc.Proxy.Enabled() {
+		cmd.Proxy = &command.Proxy{
+			Host:     acc.Proxy.Host,
+			Port:     acc.Proxy.Port,
+			Username: acc.Proxy.Username,
+			Password: acc.Proxy.Password,
+		}
+	}
+
+	if !acc.Browser.IsZero() {
+		cmd.Browser = &command.BrowserOverrides{
+			Headless:       acc.Browser.Headless,
+			ViewportWidth:  acc.Browser.ViewportWidth,
+			ViewportHeight: acc.Browser.ViewportHeight,
+			UserDataDir:    acc.Browser.UserDataDir,
+
+			PageScale:         acc.Browser.PageScale,
+			DeviceScaleFactor: acc.Browser.DeviceScaleFactor,
+			Mobile:            acc.Browser.Mobile,
+		}
+	}
+
+	if len(acc.Cookies) > 0 {
+		cmd.Cookies = make([]command.Cookie, len(acc.Cookies))
+		for i, c := range acc.Cookies {
+			cmd.Cookies[i] = command.Cookie{
+				Name:       c.Name,
+				Value:      c.Value,
+				Domain:     c.Domain,
+				Path:       c.Path,
+				HTTPOnly:   c.HTTPOnly,
+				Secure:     c.Secure,
+				SourcePort: c.SourcePort,
+				Expires:    c.Expires,
+			}
+		}
+	}
+
+	return cmd
+}
+
 // GetSession returns a session by ID.
 func (c *Coordinator) GetSession(id string) *session.Session {
 	c.sessionsMu.RLock()
@@ -201,6 +550,36 @@ func (c *Coordinator) GetSession(id string) *session.Session {
 	return c.sessions[id]
 }
 
+// LastFrame returns the newest frame a session captured or streamed and when
+// it arrived, so a view can show it until a fresh frame comes in.
+func (c *Coordinator) LastFrame(sessionID string) (image.Image, time.Time, bool) {
+	sess := c.GetSession(sessionID)
+	if sess == nil {
+		return nil, time.Time{}, false
+	}
+	img, at := sess.GetScreenCapture().LastFrame()
+	return img, at, img != nil
+}
+
+// Tuning returns the current matching and pacing settings of a session.
+func (c *Coordinator) Tuning(sessionID string) (session.Tuning, bool) {
+	sess := c.GetSession(sessionID)
+	if sess == nil {
+		return session.Tuning{}, false
+	}
+	return sess.Tuning(), true
+}
+
+// CheckScenes matches every scene against a frame captured from a session.
+// It returns false if the session doesn't exist.
+func (c *Coordinator) CheckScenes(sessionID string, frame image.Image) ([]session.SceneCheck, bool) {
+	sess := c.GetSession(sessionID)
+	if sess == nil {
+		return nil, false
+	}
+	return sess.CheckScenes(frame), true
+}
+
 // GetAllSessions returns all active sessions.
 func (c *Coordinator) GetAllSessions() []*session.Session {
 	c.sessionsMu.RLock()
@@ -234,6 +613,134 @@ func (c *Coordinator) SessionCount() int {
 	return len(c.sessions)
 }
 
+// SceneRevalidation reports how an edited scene behaves on recently captured frames.
+type SceneRevalidation struct {
+	// Frames are the recent frames from all sessions, newest first per session
+	Frames []image.Image
+	// SessionIDs holds the session that captured each frame
+	SessionIDs []string
+	// Checks holds the per-point results of the edited scene for each frame
+	Checks []domainscene.FrameCheck
+	// Regressions are indices of frames the original scene matched but the edited one does not
+	Regressions []int
+}
+
+// RevalidateScene checks an edited scene against the recent frames of all sessions,
+// so edits that would break currently working scripts are caught.
+// original may be nil for a new scene; no regressions are reported then.
+func (c *Coordinator) RevalidateScene(original, edited *domainscene.Scene) *SceneRevalidation {
+	result := &SceneRevalidation{}
+	for _, sess := range c.GetAllSessions() {
+		for _, frame := range sess.GetScreenCapture().RecentFrames() {
+			result.Frames = append(result.Frames, frame)
+			result.SessionIDs = append(result.SessionIDs, sess.ID())
+		}
+	}
+
+	matcher := domainscene.NewMatcher(c.sceneThreshold)
+	result.Checks = matcher.Revalidate(edited, result.Frames)
+	if original != nil {
+		result.Regressions = matcher.Regressions(original, edited, result.Frames)
+	}
+	return result
+}
+
+// RevalidateEdits re-validates scenes changed in the user scenes directory
+// and publishes SceneRegressed for each one that stopped matching frames
+// its previous definition matched.
+func (c *Coordinator) RevalidateEdits(edits []domainscene.Edit) {
+	for _, edit := range edits {
+		if edit.Original == nil {
+			continue
+		}
+		result := c.RevalidateScene(edit.Original, edit.Edited)
+		if len(result.Regressions) == 0 {
+			continue
+		}
+
+		points := missedPoints(edit.Edited, result)
+		c.logger.Warn("Edited scene no longer matches recent frames", "scene", edit.Edited.Name,
+			"frames", len(result.Frames), "regressions", len(result.Regressions), "points", points)
+		if c.eventBus != nil {
+			c.eventBus.Publish(event.NewSceneRegressed(
+				edit.Edited.Name, len(result.Frames), len(result.Regressions), points))
+		}
+	}
+}
+
+// missedPoints describes the points of scene that missed on the regressed
+// frames, each with its largest diff, or the best template score.
+func missedPoints(scene *domainscene.Scene, result *SceneRevalidation) []string {
+	if scene.Template != nil {
+		best := -1.0
+		for _, i := range result.Regressions {
+			if m := result.Checks[i].Template; m != nil {
+				best = max(best, m.Score)
+			}
+		}
+		return []string{fmt.Sprintf("template score %.2f", best)}
+	}
+
+	diffs := make([]float64, len(scene.Points))
+	for _, i := range result.Regressions {
+		for j, check := range result.Checks[i].Points {
+			if !check.Matched {
+				diffs[j] = max(diffs[j], check.Diff)
+			}
+		}
+	}
+	var points []string
+	for j, diff := range diffs {
+		if diff > 0 {
+			p := scene.Points[j]
+			points = append(points, fmt.Sprintf("(%d, %d) diff %.1f", p.X, p.Y, diff))
+		}
+	}
+	return points
+}
+
+// publishSceneSuggestion reports a scene threshold suggestion from the
+// shared near miss tracker.
+func (c *Coordinator) publishSceneSuggestion(s domainscene.Suggestion) {
+	c.logger.Warn("Scene keeps nearly matching", "scene", s.Scene, "threshold", s.Threshold,
+		"suggested", s.Suggested, "count", s.Count, "sessions", s.Sessions, "applied", s.Applied)
+	if c.eventBus != nil {
+		c.eventBus.Publish(event.NewSceneThresholdSuggested(
+			s.Scene, s.Threshold, s.Suggested, s.Count, s.Sessions, s.Applied))
+	}
+}
+
+// ReadRoster captures the current screen of a session and returns the text
+// lines the OCR service recognizes on it, e.g. a page of the guild member list.
+func (c *Coordinator) ReadRoster(ctx context.Context, sessionID string) ([]string, error) {
+	sess := c.GetSession(sessionID)
+	if sess == nil {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+	if !sess.State().CanAcceptOperations() {
+		return nil, fmt.Errorf("session %s is not ready", sessionID)
+	}
+	if c.ocrClient == nil || !c.ocrClient.IsHealthy() {
+		return nil, fmt.Errorf("OCR service is currently unavailable")
+	}
+
+	frame, err := sess.GetScreenCapture().Capture(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture roster screen: %w", err)
+	}
+
+	result, err := c.ocrClient.RecognizeTextFromImage(ocr.WithSession(ctx, sessionID), frame, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read roster: %w", err)
+	}
+
+	lines := make([]string, 0, len(result.Lines))
+	for _, line := range result.Lines {
+		lines = append(lines, line.Text)
+	}
+	return lines, nil
+}
+
 // Command handlers
 
 func (c *Coordinator) handleStartSession(cmd *command.StartSession) error {
@@ -243,6 +750,38 @@ func (c *Coordinator) handleStartSession(cmd *command.StartSession) error {
 		UserName: cmd.UserName,
 		Password: cmd.Password,
 		ServerID: cmd.ServerID,
+
+		URLTemplate: cmd.URLTemplate,
+
+		AllowedScripts: cmd.AllowedScripts,
+		BlockedScripts: cmd.BlockedScripts,
+	}
+
+	if cmd.Proxy != nil {
+		acc.Proxy = &account.Proxy{
+			Host:     cmd.Proxy.Host,
+			Port:     cmd.Proxy.Port,
+			Username: cmd.Proxy.Username,
+			Password: cmd.Proxy.Password,
+		}
+	}
+
+	if cmd.Browser != nil {
+		acc.Browser = &account.BrowserSettings{
+			Headless:       cmd.Browser.Headless,
+			ViewportWidth:  cmd.Browser.ViewportWidth,
+			ViewportHeight: cmd.Browser.ViewportHeight,
+			UserDataDir:    cmd.Browser.UserDataDir,
+
+			PageScale:         cmd.Browser.PageScale,
+			DeviceScaleFactor: cmd.Browser.DeviceScaleFactor,
+			Mobile:            cmd.Browser.Mobile,
+		}
+	}
+
+	// Copy remembered script params so the session owns its own maps
+	for name, params := range cmd.ScriptParams {
+		acc.RememberParams(name, params)
 	}
 
 	// Convert cookies
@@ -257,30 +796,46 @@ func (c *Coordinator) handleStartSession(cmd *command.StartSession) error {
 				HTTPOnly:   c.HTTPOnly,
 				Secure:     c.Secure,
 				SourcePort: c.SourcePort,
+				Expires:    c.Expires,
 			}
 		}
 	}
 
-	sess, err := c.CreateSession(acc)
+	sess, err := c.createSession(acc, browser.CanvasSize{Width: cmd.CanvasWidth, Height: cmd.CanvasHeight})
 	if err != nil {
 		return err
 	}
 
-	// Start browser
-	return sess.StartBrowser()
+	if cmd.StopOnScriptFinish {
+		c.sessionsMu.Lock()
+		c.stopOnFinishIDs[acc.ID] = true
+		c.sessionsMu.Unlock()
+	}
+	if cmd.SetupScript != "" {
+		c.registerSetup(acc.ID, cmd.SetupScript, cmd.CorrelationID())
+	}
+
+	// Lets the UI pick up sessions it didn't start itself (e.g. scheduled runs)
+	if c.eventBus != nil {
+		c.eventBus.Publish(event.Correlate(event.NewSessionStarted(acc.ID, acc.ID, acc.Identity()), cmd.CorrelationID()))
+	}
+
+	// Start browser, or wait for a login slot
+	return c.startBrowser(sess, cmd.CorrelationID())
 }
 
 func (c *Coordinator) handleStopSession(cmd *command.StopSession) error {
 	c.sessionsMu.Lock()
 	sess, exists := c.sessions[cmd.SessionID()]
 	if exists {
-		delete(c.sessions, cmd.SessionID())
+		c.forgetSessionLocked(cmd.SessionID())
 	}
 	c.sessionsMu.Unlock()
 
 	if !exists {
 		return fmt.Errorf("session not found: %s", cmd.SessionID())
 	}
+	c.admitQueued()
 
 	sess.Stop()
 	c.logger.Info("Session stopped", "session_id", cmd.SessionID())
@@ -294,6 +849,12 @@ func (c *Coordinator) handleStopAllSessions(cmd *command.StopAllSessions) error
 		sessions = append(sessions, s)
 	}
 	c.sessions = make(map[string]*session.Session)
+	c.stopOnFinishIDs = make(map[string]bool)
+	c.finishing = make(map[string]bool)
+	c.setups = make(map[string]*setupRun)
+	c.canvases = make(map[string]browser.CanvasSize)
+	c.logins = make(map[string]bool)
+	c.startQueue = nil
 	c.sessionsMu.Unlock()
 
 	for _, s := range sessions {
@@ -304,6 +865,82 @@ func (c *Coordinator) handleStopAllSessions(cmd *command.StopAllSessions) error
 	return nil
 }
 
+// handleRelaunchSession stops a session and starts it again under the same
+// ID with the overrides applied, at the same canvas size. The account,
+// including cookies captured since login, is taken from the stopped
+// session, so the new browser logs in with them.
+func (c *Coordinator) handleRelaunchSession(cmd *command.RelaunchSession) error {
+	sessionID := cmd.SessionID()
+	c.sessionsMu.Lock()
+	sess, exists := c.sessions[sessionID]
+	canvas := c.canvases[sessionID]
+	stopOnFinish := c.stopOnFinishIDs[sessionID]
+	if exists {
+		c.forgetSessionLocked(sessionID)
+	}
+	c.sessionsMu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	// Read the account once the session's goroutines are done with it
+	sess.Stop()
+	acc := withBrowserOverrides(sess.Account(), cmd.Browser)
+	c.logger.Info("Relaunching session", "session_id", sessionID, "account", acc.Identity())
+
+	relaunched, err := c.createSession(acc, canvas)
+	if err != nil {
+		return err
+	}
+	if stopOnFinish {
+		c.sessionsMu.Lock()
+		c.stopOnFinishIDs[sessionID] = true
+		c.sessionsMu.Unlock()
+	}
+	if c.eventBus != nil {
+		c.eventBus.Publish(event.Correlate(event.NewSessionStarted(sessionID, acc.ID, acc.Identity()), cmd.CorrelationID()))
+	}
+	return c.startBrowser(relaunched, cmd.CorrelationID())
+}
+
+// withBrowserOverrides returns a copy of acc whose browser settings have the
+// set fields of o replaced. acc is not modified.
+func withBrowserOverrides(acc *account.Account, o *command.BrowserOverrides) *account.Account {
+	copied := *acc
+	if o == nil {
+		return &copied
+	}
+	settings := account.BrowserSettings{}
+	if acc.Browser != nil {
+		settings = *acc.Browser
+	}
+	if o.Headless != nil {
+		headless := *o.Headless
+		settings.Headless = &headless
+	}
+	if o.ViewportWidth > 0 {
+		settings.ViewportWidth = o.ViewportWidth
+	}
+	if o.ViewportHeight > 0 {
+		settings.ViewportHeight = o.ViewportHeight
+	}
+	if o.UserDataDir != "" {
+		settings.UserDataDir = o.UserDataDir
+	}
+	if o.PageScale > 0 {
+		settings.PageScale = o.PageScale
+	}
+	if o.DeviceScaleFactor > 0 {
+		settings.DeviceScaleFactor = o.DeviceScaleFactor
+	}
+	if o.Mobile {
+		settings.Mobile = true
+	}
+	copied.Browser = &settings
+	return &copied
+}
+
 func (c *Coordinator) handleClickAll(cmd *command.ClickAll) error {
 	sessions := c.GetActiveSessions()
 
@@ -313,8 +950,10 @@ func (c *Coordinator) handleClickAll(cmd *command.ClickAll) error {
 		go func(s *session.Session) {
 			defer wg.Done()
 			clickCmd := command.NewClick(s.ID(), cmd.X, cmd.Y)
+			clickCmd.SetCorrelationID(cmd.CorrelationID())
 			if err := s.Send(clickCmd); err != nil {
-				c.logger.Warn("Failed to send click to session", "session_id", s.ID(), "error", err)
+				c.logger.Warn("Failed to send click to session", "session_id", s.ID(),
+					"correlation_id", cmd.CorrelationID(), "error", err)
 			}
 		}(sess)
 	}
@@ -332,8 +971,10 @@ func (c *Coordinator) handleDragAll(cmd *command.DragAll) error {
 		go func(s *session.Session) {
 			defer wg.Done()
 			dragCmd := command.NewDrag(s.ID(), cmd.Points)
+			dragCmd.SetCorrelationID(cmd.CorrelationID())
 			if err := s.Send(dragCmd); err != nil {
-				c.logger.Warn("Failed to send drag to session", "session_id", s.ID(), "error", err)
+				c.logger.Warn("Failed to send drag to session", "session_id", s.ID(),
+					"correlation_id", cmd.CorrelationID(), "error", err)
 			}
 		}(sess)
 	}
@@ -351,9 +992,15 @@ func (c *Coordinator) handleStartAllScripts(cmd *command.StartAllScripts) error
 			if scriptName == "" {
 				continue
 			}
+			if !c.permitScript(sess, scriptName, cmd.CorrelationID()) {
+				continue
+			}
 			startCmd := command.NewStartScript(sess.ID(), scriptName)
+			startCmd.SetCorrelationID(cmd.CorrelationID())
 			if err := sess.Send(startCmd); err != nil {
-				c.logger.Warn("Failed to start script on session", "session_id", sess.ID(), "error", err)
+				c.releaseScript(sess.ID())
+				c.logger.Warn("Failed to start script on session", "session_id", sess.ID(),
+					"correlation_id", cmd.CorrelationID(), "error", err)
 			}
 		}
 	}
@@ -361,14 +1008,127 @@ func (c *Coordinator) handleStartAllScripts(cmd *command.StartAllScripts) error
 	return nil
 }
 
+func (c *Coordinator) handleStartScript(cmd *command.StartScript) error {
+	sess := c.GetSession(cmd.SessionID())
+	if sess == nil {
+		return fmt.Errorf("session not found: %s", cmd.SessionID())
+	}
+	if c.deferBehindSetup(sess.ID(), cmd.ScriptName, cmd.CorrelationID()) {
+		return nil
+	}
+	if !c.permitScript(sess, cmd.ScriptName, cmd.CorrelationID()) {
+		return nil
+	}
+	if err := sess.Send(cmd); err != nil {
+		c.releaseScript(sess.ID())
+		return err
+	}
+	return nil
+}
+
+// permitScript checks the account's script lists and exclusion groups and
+// publishes a refusal, tagged with the correlation ID of the command that
+// started it, if denied. A permitted script is claimed for the session.
+func (c *Coordinator) permitScript(sess *session.Session, scriptName, correlationID string) bool {
+	err := sess.Account().CheckScript(scriptName)
+	if err == nil {
+		err = c.claimScript(sess, scriptName)
+	}
+	if err == nil {
+		return true
+	}
+
+	c.logger.Warn("Script refused", "session_id", sess.ID(), "script", scriptName,
+		"correlation_id", correlationID, "reason", err)
+	if c.eventBus != nil {
+		c.eventBus.Publish(event.Correlate(event.NewScriptRefused(sess.ID(), scriptName, err), correlationID))
+	}
+	return false
+}
+
+// ScriptConflict returns an error if scriptName shares an exclusion group
+// with a script running on another session of the same account.
+// Sessions of the same account share a login, even on different servers.
+func (c *Coordinator) ScriptConflict(sess *session.Session, scriptName string) error {
+	c.sessionsMu.RLock()
+	defer c.sessionsMu.RUnlock()
+	return c.scriptConflictLocked(sess, c.lookupScript(scriptName))
+}
+
+// scriptConflictLocked implements ScriptConflict. Caller must hold sessionsMu.
+func (c *Coordinator) scriptConflictLocked(sess *session.Session, script *domainscript.Script) error {
+	if script == nil || len(script.ExclusionGroups) == 0 {
+		return nil
+	}
+	login := loginOf(sess.Account())
+	for id, running := range c.scriptClaims {
+		other := c.sessions[id]
+		if id == sess.ID() || other == nil || loginOf(other.Account()) != login {
+			continue
+		}
+		if group := script.ConflictsWith(c.lookupScript(running)); group != "" {
+			return fmt.Errorf("script %q conflicts with %q running on %s (exclusion group %q)",
+				script.Name, running, other.Account().Identity(), group)
+		}
+	}
+	return nil
+}
+
+// claimScript records scriptName as started on sess unless it conflicts
+// with a running script (see ScriptConflict).
+func (c *Coordinator) claimScript(sess *session.Session, scriptName string) error {
+	c.sessionsMu.Lock()
+	defer c.sessionsMu.Unlock()
+
+	script := c.lookupScript(scriptName)
+	if script == nil {
+		return nil // The session reports unknown scripts itself
+	}
+	if err := c.scriptConflictLocked(sess, script); err != nil {
+		return err
+	}
+
+	// A session that can't start the script ignores the command, so
+	// claiming it would block other sessions until the next stop
+	if sess.State().CanStartScript() {
+		c.scriptClaims[sess.ID()] = scriptName
+	}
+	return nil
+}
+
+// releaseScript drops the script claim of a session.
+func (c *Coordinator) releaseScript(sessionID string) {
+	c.sessionsMu.Lock()
+	delete(c.scriptClaims, sessionID)
+	c.sessionsMu.Unlock()
+}
+
+// lookupScript returns the registered script, or nil without a registry.
+func (c *Coordinator) lookupScript(name string) *domainscript.Script {
+	if c.scriptRegistry == nil {
+		return nil
+	}
+	return c.scriptRegistry.Get(name)
+}
+
+// loginOf identifies the game login an account plays on.
+func loginOf(acc *account.Account) string {
+	if acc.UserName == "" {
+		return acc.ID
+	}
+	return acc.UserName
+}
+
 func (c *Coordinator) handleStopAllScripts(cmd *command.StopAllScripts) error {
 	sessions := c.GetActiveSessions()
 
 	for _, sess := range sessions {
 		if sess.State().CanStopScript() {
 			stopCmd := command.NewStopScript(sess.ID())
+			stopCmd.SetCorrelationID(cmd.CorrelationID())
 			if err := sess.Send(stopCmd); err != nil {
-				c.logger.Warn("Failed to stop script on session", "session_id", sess.ID(), "error", err)
+				c.logger.Warn("Failed to stop script on session", "session_id", sess.ID(),
+					"correlation_id", cmd.CorrelationID(), "error", err)
 			}
 		}
 	}
@@ -381,8 +1141,10 @@ func (c *Coordinator) handleSyncScriptSelection(cmd *command.SyncScriptSelection
 
 	for _, sess := range sessions {
 		selectCmd := command.NewSetScriptSelection(sess.ID(), cmd.ScriptName)
+		selectCmd.SetCorrelationID(cmd.CorrelationID())
 		if err := sess.Send(selectCmd); err != nil {
-			c.logger.Warn("Failed to sync script selection", "session_id", sess.ID(), "error", err)
+			c.logger.Warn("Failed to sync script selection", "session_id", sess.ID(),
+				"correlation_id", cmd.CorrelationID(), "error", err)
 		}
 	}
 
@@ -397,13 +1159,155 @@ func (c *Coordinator) routeToSession(cmd command.SessionCommand) error {
 	return sess.Send(cmd)
 }
 
+// forgetSessionLocked drops all coordinator state for a session.
+// Caller must hold sessionsMu.
+func (c *Coordinator) forgetSessionLocked(sessionID string) {
+	delete(c.sessions, sessionID)
+	delete(c.stopOnFinishIDs, sessionID)
+	delete(c.finishing, sessionID)
+	delete(c.scriptClaims, sessionID)
+	delete(c.setups, sessionID)
+	delete(c.canvases, sessionID)
+	delete(c.logins, sessionID)
+	for i, q := range c.startQueue {
+		if q.sessionID == sessionID {
+			c.startQueue = append(c.startQueue[:i:i], c.startQueue[i+1:]...)
+			break
+		}
+	}
+}
+
 // handleEvent handles events from the event bus.
 func (c *Coordinator) handleEvent(e event.Event) {
+	continued := c.onSetupEvent(e)
+
 	switch evt := e.(type) {
+	case *event.SessionStateChanged:
+		if evt.OldState == state.StateLoggingIn && evt.NewState == state.StateReady {
+			c.startSetup(evt.SessionID())
+		}
+		if isLoggingIn(evt.OldState) && !isLoggingIn(evt.NewState) {
+			c.releaseLogin(evt.SessionID())
+		}
 	case *event.SessionStopped:
 		c.sessionsMu.Lock()
-		delete(c.sessions, evt.SessionID())
+		c.forgetSessionLocked(evt.SessionID())
 		c.sessionsMu.Unlock()
+		c.admitQueued()
 		c.logger.Info("Session removed from coordinator", "session_id", evt.SessionID())
+	case *event.ScriptStarted:
+		// Also covers scripts started without going through the coordinator
+		c.sessionsMu.Lock()
+		if _, exists := c.sessions[evt.SessionID()]; exists {
+			c.scriptClaims[evt.SessionID()] = evt.ScriptName
+		}
+		c.sessionsMu.Unlock()
+	case *event.ScriptStopped:
+		c.releaseScript(evt.SessionID())
+		if !continued {
+			c.onScriptStopped(evt)
+		}
+	case *event.CookiesSaved:
+		if c.takeFinishing(evt.SessionID()) {
+			go c.finishSession(evt.SessionID(), true)
+		}
+	case *event.OperationFailed:
+		if evt.Operation == "start_script" {
+			c.releaseScript(evt.SessionID())
+		}
+		if evt.Operation == "save_cookies" && c.takeFinishing(evt.SessionID()) {
+			go c.finishSession(evt.SessionID(), false)
+		}
+	}
+}
+
+// onScriptStopped starts the auto cleanup of a session whose script finished.
+// Cookies are captured first; the session is stopped once they arrive.
+func (c *Coordinator) onScriptStopped(evt *event.ScriptStopped) {
+	if evt.Reason != event.StopReasonNormal && evt.Reason != event.StopReasonResourceExhausted {
+		return
+	}
+
+	c.sessionsMu.Lock()
+	sess, exists := c.sessions[evt.SessionID()]
+	enabled := c.stopOnFinish.Load() || c.stopOnFinishIDs[evt.SessionID()]
+	if exists && enabled {
+		c.finishing[evt.SessionID()] = true
+	}
+	c.sessionsMu.Unlock()
+
+	if !exists || !enabled {
+		return
+	}
+
+	c.logger.Info("Script finished, cleaning up session",
+		"session_id", evt.SessionID(), "script", evt.ScriptName, "reason", evt.Reason)
+
+	if err := sess.Send(command.NewSaveCookies(evt.SessionID())); err != nil {
+		c.logger.Warn("Failed to request cookies before cleanup", "session_id", evt.SessionID(), "error", err)
+		if c.takeFinishing(evt.SessionID()) {
+			go c.finishSession(evt.SessionID(), false)
+		}
+	}
+}
+
+// takeFinishing reports whether the session was waiting for cleanup and clears the mark.
+func (c *Coordinator) takeFinishing(sessionID string) bool {
+	c.sessionsMu.Lock()
+	defer c.sessionsMu.Unlock()
+
+	if !c.finishing[sessionID] {
+		return false
+	}
+	delete(c.finishing, sessionID)
+	return true
+}
+
+// finishSession persists cookies (if captured), waits for the script
+// statistics to record the run, then stops the session. Runs outside the
+// event dispatch goroutine because stopping waits for the session.
+func (c *Coordinator) finishSession(sessionID string, saveCookies bool) {
+	c.sessionsMu.RLock()
+	sess, exists := c.sessions[sessionID]
+	c.sessionsMu.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	if saveCookies && c.accountService != nil {
+		acc := sess.Account()
+		ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+		if err := c.accountService.SaveCookies(ctx, acc.ID, acc.Cookies); err != nil {
+			c.logger.Error("Failed to persist cookies", "session_id", sessionID, "error", err)
+		}
+		cancel()
+	}
+
+	if c.flushStats != nil {
+		ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+		if err := c.flushStats(ctx); err != nil {
+			c.logger.Warn("Script statistics not flushed before stopping session", "session_id", sessionID, "error", err)
+		}
+		cancel()
+	}
+
+	c.sessionsMu.Lock()
+	_, exists = c.sessions[sessionID]
+	if exists {
+		c.forgetSessionLocked(sessionID)
+	}
+	c.sessionsMu.Unlock()
+
+	if !exists {
+		return
+	}
+	c.admitQueued()
+
+	sess.Stop()
+	c.logger.Info("Finished session stopped", "session_id", sessionID)
+
+	if c.eventBus != nil {
+		c.eventBus.Publish(event.NewSessionStopped(sessionID, nil))
 	}
 }
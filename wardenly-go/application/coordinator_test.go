@@ -1,9 +1,20 @@
 package application
 
 import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
 	"testing"
+	"time"
 
+	"wardenly-go/application/session"
+	"wardenly-go/core/command"
+	"wardenly-go/core/event"
 	"wardenly-go/core/eventbus"
+	"wardenly-go/core/state"
+	"wardenly-go/domain/account"
 	domainscene "wardenly-go/domain/scene"
 	domainscript "wardenly-go/domain/script"
 	"wardenly-go/infrastructure/browser"
@@ -23,7 +34,7 @@ func TestCoordinatorConfig(t *testing.T) {
 		SceneRegistry:  sceneReg,
 		ScriptRegistry: scriptReg,
 		OCRClient:      ocrClient,
-		DriverFactory: func() browser.Driver {
+		DriverFactory: func(*browser.DriverConfig) browser.Driver {
 			return browser.NewChromeDPDriver(nil)
 		},
 	}
@@ -97,6 +108,20 @@ func TestCoordinator_GetSession_NotFound(t *testing.T) {
 	}
 }
 
+func TestCoordinator_LastFrame_NotFound(t *testing.T) {
+	cfg := &CoordinatorConfig{
+		SceneRegistry:  domainscene.NewRegistry(),
+		ScriptRegistry: domainscript.NewRegistry(),
+	}
+
+	coord := NewCoordinator(cfg)
+	defer coord.Stop()
+
+	if img, _, ok := coord.LastFrame("nonexistent"); ok || img != nil {
+		t.Error("Expected no frame for nonexistent session")
+	}
+}
+
 func TestCoordinator_GetAllSessions_Empty(t *testing.T) {
 	cfg := &CoordinatorConfig{
 		SceneRegistry:  domainscene.NewRegistry(),
@@ -139,3 +164,664 @@ func TestCoordinator_StartStop(t *testing.T) {
 	// Should not panic
 	coord.Stop()
 }
+
+func TestCoordinator_SetStopOnScriptFinish(t *testing.T) {
+	cfg := &CoordinatorConfig{
+		SceneRegistry:      domainscene.NewRegistry(),
+		ScriptRegistry:     domainscript.NewRegistry(),
+		StopOnScriptFinish: true,
+	}
+
+	coord := NewCoordinator(cfg)
+	defer coord.Stop()
+
+	if !coord.stopOnFinish.Load() {
+		t.Error("stopOnFinish not initialized from config")
+	}
+
+	if err := coord.Dispatch(&command.SetStopOnScriptFinish{Enabled: false}); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	if coord.stopOnFinish.Load() {
+		t.Error("stopOnFinish still enabled after SetStopOnScriptFinish")
+	}
+}
+
+func TestCoordinator_ScriptStopped_UnknownSession(t *testing.T) {
+	cfg := &CoordinatorConfig{
+		SceneRegistry:      domainscene.NewRegistry(),
+		ScriptRegistry:     domainscript.NewRegistry(),
+		StopOnScriptFinish: true,
+	}
+
+	coord := NewCoordinator(cfg)
+	defer coord.Stop()
+
+	coord.handleEvent(event.NewScriptStopped("missing", "test", event.StopReasonNormal, nil))
+
+	if coord.takeFinishing("missing") {
+		t.Error("unknown session should not be marked for cleanup")
+	}
+}
+
+func TestCoordinator_ScriptStopped_FlushesStats(t *testing.T) {
+	// Sessions still open when the statistics were flushed
+	flushed := make(chan int, 1)
+	var coord *Coordinator
+	coord = NewCoordinator(&CoordinatorConfig{
+		SceneRegistry:      domainscene.NewRegistry(),
+		ScriptRegistry:     domainscript.NewRegistry(),
+		StopOnScriptFinish: true,
+		FlushStats: func(context.Context) error {
+			flushed <- coord.SessionCount()
+			return nil
+		},
+		DriverFactory: func(*browser.DriverConfig) browser.Driver {
+			return browser.NewChromeDPDriver(nil)
+		},
+	})
+	defer coord.Stop()
+
+	acc := &account.Account{ID: "a", ServerID: 1, RoleName: "hero"}
+	if _, err := coord.CreateSession(acc); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	coord.handleEvent(event.NewScriptStopped("a", "daily", event.StopReasonNormal, nil))
+	coord.handleEvent(event.NewCookiesSaved("a"))
+
+	select {
+	case n := <-flushed:
+		if n != 1 {
+			t.Errorf("statistics flushed after the session was removed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("statistics were not flushed")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for coord.SessionCount() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := coord.SessionCount(); n != 0 {
+		t.Errorf("SessionCount() = %d after cleanup, want 0", n)
+	}
+}
+
+func TestCoordinator_RevalidateEdits(t *testing.T) {
+	eventBus := eventbus.New(10)
+	defer eventBus.Close()
+	events := make(chan event.Event, 10)
+	eventBus.Subscribe(func(e event.Event) { events <- e })
+
+	coord := NewCoordinator(&CoordinatorConfig{
+		EventBus:       eventBus,
+		SceneRegistry:  domainscene.NewRegistry(),
+		ScriptRegistry: domainscript.NewRegistry(),
+		DriverFactory: func(*browser.DriverConfig) browser.Driver {
+			return browser.NewChromeDPDriver(nil)
+		},
+	})
+	defer coord.Stop()
+
+	sess, err := coord.CreateSession(&account.Account{ID: "a", ServerID: 1, RoleName: "a"})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	frame := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	draw.Draw(frame, frame.Bounds(), image.NewUniform(color.RGBA{R: 200, A: 255}), image.Point{}, draw.Src)
+	sess.GetScreenCapture().Record(frame)
+
+	original := &domainscene.Scene{Name: "main_city", Points: []domainscene.Point{{X: 5, Y: 5, Color: color.RGBA{R: 200, A: 255}}}}
+	edited := &domainscene.Scene{Name: "main_city", Points: []domainscene.Point{{X: 5, Y: 5, Color: color.RGBA{B: 200, A: 255}}}}
+	coord.RevalidateEdits([]domainscene.Edit{
+		{Original: nil, Edited: edited}, // New scenes can't regress
+		{Original: original, Edited: edited},
+	})
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case e := <-events:
+			regressed, ok := e.(*event.SceneRegressed)
+			if !ok {
+				continue
+			}
+			if regressed.SceneName != "main_city" || regressed.Regressions != 1 || len(regressed.Points) != 1 {
+				t.Errorf("event = %+v, want one regressed frame and point", regressed)
+			}
+			return
+		case <-timeout:
+			t.Fatal("no SceneRegressed event")
+		}
+	}
+}
+
+func TestCoordinator_SceneTuning(t *testing.T) {
+	eventBus := eventbus.New(10)
+	defer eventBus.Close()
+	events := make(chan event.Event, 10)
+	eventBus.Subscribe(func(e event.Event) { events <- e })
+
+	coord := NewCoordinator(&CoordinatorConfig{
+		EventBus:       eventBus,
+		SceneRegistry:  domainscene.NewRegistry(),
+		ScriptRegistry: domainscript.NewRegistry(),
+		SceneTuning:    domainscene.NearMissConfig{Margin: 1, MinCount: 2, MinSessions: 2},
+	})
+	defer coord.Stop()
+
+	coord.nearMisses.Record("main_city", "s1", 5.3, 5)
+	coord.nearMisses.Record("main_city", "s2", 5.25, 5)
+
+	select {
+	case e := <-events:
+		got, ok := e.(*event.SceneThresholdSuggested)
+		if !ok || got.SceneName != "main_city" || got.Suggested != 5.3 || got.Applied {
+			t.Errorf("event = %+v, want a suggestion of 5.3 for main_city", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no SceneThresholdSuggested event")
+	}
+}
+
+func TestCoordinator_ExclusionGroups(t *testing.T) {
+	scripts := domainscript.NewRegistry()
+	scripts.Register(&domainscript.Script{Name: "forge", ExclusionGroups: []string{"gold"}})
+	scripts.Register(&domainscript.Script{Name: "market", ExclusionGroups: []string{"gold"}})
+	scripts.Register(&domainscript.Script{Name: "daily"})
+
+	coord := NewCoordinator(&CoordinatorConfig{
+		SceneRegistry:  domainscene.NewRegistry(),
+		ScriptRegistry: scripts,
+		DriverFactory: func(*browser.DriverConfig) browser.Driver {
+			return browser.NewChromeDPDriver(nil)
+		},
+	})
+	defer coord.Stop()
+
+	newSession := func(id, user string) *session.Session {
+		sess, err := coord.CreateSession(&account.Account{ID: id, UserName: user, ServerID: 1, RoleName: id})
+		if err != nil {
+			t.Fatalf("CreateSession(%s) error = %v", id, err)
+		}
+		return sess
+	}
+	a := newSession("a", "alice")
+	b := newSession("b", "alice") // Same login on another role
+	c := newSession("c", "carol")
+
+	coord.handleEvent(event.NewScriptStarted("a", "forge"))
+
+	if err := coord.ScriptConflict(b, "market"); err == nil {
+		t.Error("market should conflict with forge running on the same login")
+	}
+	if err := coord.claimScript(b, "market"); err == nil {
+		t.Error("market should be refused while forge runs on the same login")
+	}
+	if err := coord.claimScript(b, "daily"); err != nil {
+		t.Errorf("daily has no exclusion group, got %v", err)
+	}
+	if err := coord.claimScript(c, "market"); err != nil {
+		t.Errorf("other logins are not affected, got %v", err)
+	}
+	if err := coord.claimScript(a, "market"); err != nil {
+		t.Errorf("a session does not conflict with itself, got %v", err)
+	}
+
+	coord.handleEvent(event.NewScriptStopped("a", "forge", event.StopReasonNormal, nil))
+
+	if err := coord.claimScript(b, "market"); err != nil {
+		t.Errorf("market should be allowed after forge stopped, got %v", err)
+	}
+}
+
+func TestCoordinator_CorrelatesRefusal(t *testing.T) {
+	eventBus := eventbus.New(10)
+	defer eventBus.Close()
+	events := make(chan event.Event, 10)
+	eventBus.Subscribe(func(e event.Event) { events <- e })
+
+	coord := NewCoordinator(&CoordinatorConfig{
+		EventBus:       eventBus,
+		SceneRegistry:  domainscene.NewRegistry(),
+		ScriptRegistry: domainscript.NewRegistry(),
+		DriverFactory: func(*browser.DriverConfig) browser.Driver {
+			return browser.NewChromeDPDriver(nil)
+		},
+	})
+	defer coord.Stop()
+
+	acc := &account.Account{ID: "a", ServerID: 1, RoleName: "a", BlockedScripts: []string{"daily"}}
+	if _, err := coord.CreateSession(acc); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	cmd := command.NewStartScript("a", "daily")
+	if err := coord.Dispatch(cmd); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	if cmd.CorrelationID() == "" {
+		t.Fatal("Dispatch() did not assign a correlation ID")
+	}
+
+	select {
+	case e := <-events:
+		refused, ok := e.(*event.ScriptRefused)
+		if !ok || refused.CorrelationID() != cmd.CorrelationID() {
+			t.Errorf("event = %+v, want ScriptRefused with correlation ID %q", e, cmd.CorrelationID())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no ScriptRefused event")
+	}
+}
+
+func TestCoordinator_FirstLoginSetup(t *testing.T) {
+	repo := &memAccountRepo{accounts: map[string]*account.Account{
+		"a": {ID: "a", ServerID: 1, RoleName: "a", SetupScript: "tutorial"},
+		"b": {ID: "b", ServerID: 1, RoleName: "b", SetupScript: "tutorial"},
+	}}
+	coord := NewCoordinator(&CoordinatorConfig{
+		SceneRegistry:  domainscene.NewRegistry(),
+		ScriptRegistry: domainscript.NewRegistry(),
+		AccountService: account.NewService(repo),
+		DriverFactory: func(*browser.DriverConfig) browser.Driver {
+			return browser.NewChromeDPDriver(nil)
+		},
+	})
+	defer coord.Stop()
+
+	setup := func(id string) *setupRun {
+		coord.sessionsMu.RLock()
+		defer coord.sessionsMu.RUnlock()
+		return coord.setups[id]
+	}
+	ready := func(id string) {
+		coord.handleEvent(event.NewSessionStateChanged(id, state.StateLoggingIn, state.StateReady))
+	}
+
+	for _, id := range []string{"a", "b"} {
+		if _, err := coord.CreateSession(repo.accounts[id]); err != nil {
+			t.Fatal(err)
+		}
+		coord.registerSetup(id, "tutorial", "")
+		if err := coord.handleStartScript(command.NewStartScript(id, "daily")); err != nil {
+			t.Fatalf("handleStartScript() error = %v", err)
+		}
+		if run := setup(id); run == nil || run.deferred != "daily" {
+			t.Fatalf("setup of %s = %+v, want daily deferred", id, run)
+		}
+		ready(id)
+		if run := setup(id); run == nil || !run.started {
+			t.Fatalf("setup of %s = %+v, want it started on Ready", id, run)
+		}
+		coord.handleEvent(event.NewScriptStarted(id, "tutorial"))
+	}
+
+	t.Run("completed", func(t *testing.T) {
+		if !coord.onSetupEvent(event.NewScriptStopped("a", "tutorial", event.StopReasonNormal, nil)) {
+			t.Error("the deferred script should keep the session from being cleaned up")
+		}
+
+		deadline := time.Now().Add(time.Second)
+		acc, _ := repo.FindByID(context.Background(), "a")
+		for acc.NeedsSetup() && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+			acc, _ = repo.FindByID(context.Background(), "a")
+		}
+		if acc.NeedsSetup() {
+			t.Error("completed setup was not recorded")
+		}
+
+		// A login after a browser restart doesn't repeat it
+		ready("a")
+		if run := setup("a"); run != nil {
+			t.Errorf("setup after completion = %+v, want none", run)
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		if coord.onSetupEvent(event.NewScriptStopped("b", "tutorial", event.StopReasonError, nil)) {
+			t.Error("the deferred script should be dropped after a failed setup")
+		}
+		if run := setup("b"); run != nil {
+			t.Errorf("setup after failure = %+v, want none", run)
+		}
+		if acc, _ := repo.FindByID(context.Background(), "b"); !acc.NeedsSetup() {
+			t.Error("failed setup should not be recorded")
+		}
+	})
+}
+
+func TestStartSessionCommand_Setup(t *testing.T) {
+	acc := &account.Account{ID: "a", SetupScript: "tutorial"}
+	if cmd := StartSessionCommand(acc); cmd.SetupScript != "tutorial" {
+		t.Errorf("SetupScript = %q, want tutorial", cmd.SetupScript)
+	}
+	acc.SetupCompletedAt = time.Now()
+	if cmd := StartSessionCommand(acc); cmd.SetupScript != "" {
+		t.Errorf("SetupScript = %q after completion, want empty", cmd.SetupScript)
+	}
+}
+
+func TestStartSessionCommand_Proxy(t *testing.T) {
+	acc := &account.Account{ID: "a", Proxy: &account.Proxy{Host: "10.0.0.1", Port: 8080, Username: "u", Password: "p"}}
+
+	cmd := StartSessionCommand(acc)
+	if cmd.Proxy == nil || cmd.Proxy.Host != "10.0.0.1" || cmd.Proxy.Password != "p" {
+		t.Fatalf("command proxy = %+v", cmd.Proxy)
+	}
+
+	config := driverConfig(nil, acc)
+	if config.Proxy == nil || config.Proxy.Server() != "http://10.0.0.1:8080" || config.Proxy.Username != "u" {
+		t.Errorf("driver proxy = %+v", config.Proxy)
+	}
+	if driverConfig(nil, &account.Account{}).Proxy != nil {
+		t.Error("accounts without a proxy should connect directly")
+	}
+}
+
+func TestStartSessionCommand_URLTemplate(t *testing.T) {
+	acc := &account.Account{ID: "a", ServerID: 7, URLTemplate: "https://mirror.example/wly/{server}"}
+	if cmd := StartSessionCommand(acc); cmd.URLTemplate != acc.URLTemplate {
+		t.Fatalf("command URL template = %q", cmd.URLTemplate)
+	}
+
+	profile := browser.LoginProfile{URLTemplate: "http://test.example/s/{server}"}
+	if got := loginURL(profile, acc); got != "https://mirror.example/wly/7" {
+		t.Errorf("loginURL() with an account template = %q", got)
+	}
+	if got := loginURL(profile, &account.Account{ServerID: 7}); got != "http://test.example/s/7" {
+		t.Errorf("loginURL() with the profile template = %q", got)
+	}
+	if got := loginURL(browser.LoginProfile{}, &account.Account{ServerID: 7}); got != "http://www.lequ.com/server/wly/s/7" {
+		t.Errorf("loginURL() by default = %q", got)
+	}
+}
+
+func TestStartSessionCommand_Browser(t *testing.T) {
+	headless := false
+	acc := &account.Account{ID: "a", Browser: &account.BrowserSettings{
+		Headless:       &headless,
+		ViewportWidth:  1280,
+		ViewportHeight: 800,
+		UserDataDir:    "/tmp/profile-a",
+	}}
+
+	cmd := StartSessionCommand(acc)
+	if cmd.Browser == nil || cmd.Browser.ViewportWidth != 1280 || cmd.Browser.UserDataDir != "/tmp/profile-a" {
+		t.Fatalf("command browser = %+v", cmd.Browser)
+	}
+
+	def := browser.DefaultDriverConfig()
+	config := driverConfig(nil, acc)
+	if config.Headless || config.ViewportWidth != 1280 || config.ViewportHeight != 800 || config.UserDataDir != "/tmp/profile-a" {
+		t.Errorf("driver config = %+v", config)
+	}
+	if config.WindowHeight-config.ViewportHeight != def.WindowHeight-def.ViewportHeight {
+		t.Errorf("window height = %d, want viewport plus the default margin", config.WindowHeight)
+	}
+
+	// Unset fields keep the default
+	config = driverConfig(nil, &account.Account{Browser: &account.BrowserSettings{ViewportHeight: 600}})
+	if config.Headless != def.Headless || config.ViewportWidth != def.ViewportWidth || config.ViewportHeight != 600 {
+		t.Errorf("partial override config = %+v", config)
+	}
+	// Overrides apply to a copy of the configured base
+	base := browser.DefaultDriverConfig()
+	base.Headless = false
+	base.DisableGPU = true
+	config = driverConfig(base, &account.Account{Browser: &account.BrowserSettings{ViewportWidth: 1280}})
+	if config.Headless || !config.DisableGPU || config.ViewportWidth != 1280 || base.ViewportWidth != def.ViewportWidth {
+		t.Errorf("config from base = %+v, base = %+v", config, base)
+	}
+	if StartSessionCommand(&account.Account{ID: "b"}).Browser != nil {
+		t.Error("accounts without settings should not carry overrides")
+	}
+}
+
+// unstartableDriver fails to start, so sessions can be launched in tests
+// without a browser.
+type unstartableDriver struct {
+	browser.Driver
+}
+
+func (unstartableDriver) Start(context.Context) error { return errors.New("no browser in tests") }
+func (unstartableDriver) IsRunning() bool             { return false }
+
+func TestCoordinator_RelaunchSession(t *testing.T) {
+	var configs []*browser.DriverConfig
+	coord := NewCoordinator(&CoordinatorConfig{
+		SceneRegistry:  domainscene.NewRegistry(),
+		ScriptRegistry: domainscript.NewRegistry(),
+		DriverFactory: func(config *browser.DriverConfig) browser.Driver {
+			configs = append(configs, config)
+			return unstartableDriver{}
+		},
+	})
+	defer coord.Stop()
+
+	canvas := browser.CanvasPresets[0]
+	acc := &account.Account{ID: "a", RoleName: "alice", ServerID: 1,
+		Cookies: []account.Cookie{{Name: "sid", Value: "1"}}}
+	old, err := coord.createSession(acc, canvas)
+	if err != nil {
+		t.Fatalf("createSession() error = %v", err)
+	}
+
+	headless := false
+	err = coord.Dispatch(command.NewRelaunchSession("a", &command.BrowserOverrides{Headless: &headless}))
+	if err == nil {
+		t.Fatal("Dispatch() should report the browser that failed to start")
+	}
+	if len(configs) != 2 {
+		t.Fatalf("driver configs = %d, want 2", len(configs))
+	}
+	if !configs[0].Headless || configs[1].Headless {
+		t.Errorf("headless = %v then %v, want true then false", configs[0].Headless, configs[1].Headless)
+	}
+	if configs[1].ViewportWidth != canvas.Width || configs[1].ViewportHeight != canvas.Height {
+		t.Errorf("relaunched viewport = %dx%d, want the %s canvas", configs[1].ViewportWidth, configs[1].ViewportHeight, canvas)
+	}
+
+	relaunched := coord.GetSession("a")
+	if relaunched == nil || relaunched == old {
+		t.Fatal("the session should be replaced")
+	}
+	if got := relaunched.Account(); len(got.Cookies) != 1 || got.Browser == nil || *got.Browser.Headless {
+		t.Errorf("relaunched account = %+v", got)
+	}
+	if acc.Browser != nil {
+		t.Error("the overrides should not modify the original account")
+	}
+
+	if err := coord.Dispatch(command.NewRelaunchSession("missing", nil)); err == nil {
+		t.Error("relaunching an unknown session should fail")
+	}
+}
+
+// heldDriver blocks in Start until a value is sent on release, then fails.
+type heldDriver struct {
+	browser.Driver
+	release chan struct{}
+}
+
+func (d heldDriver) Start(context.Context) error {
+	<-d.release
+	return errors.New("no browser in tests")
+}
+func (heldDriver) IsRunning() bool { return false }
+
+func TestCoordinator_StartQueue(t *testing.T) {
+	eventBus := eventbus.New(50)
+	defer eventBus.Close()
+	queued := make(chan *event.SessionQueued, 10)
+	stopped := make(chan *event.SessionStopped, 10)
+	eventBus.Subscribe(func(e event.Event) {
+		switch evt := e.(type) {
+		case *event.SessionQueued:
+			queued <- evt
+		case *event.SessionStopped:
+			stopped <- evt
+		}
+	})
+
+	release := make(chan struct{})
+	defer close(release)
+	coord := NewCoordinator(&CoordinatorConfig{
+		EventBus:            eventBus,
+		SceneRegistry:       domainscene.NewRegistry(),
+		ScriptRegistry:      domainscript.NewRegistry(),
+		MaxConcurrentLogins: 1,
+		DriverFactory: func(*browser.DriverConfig) browser.Driver {
+			return heldDriver{release: release}
+		},
+	})
+	defer coord.Stop()
+
+	expectQueued := func(sessionID string, position int) {
+		t.Helper()
+		select {
+		case evt := <-queued:
+			if evt.SessionID() != sessionID || evt.Position != position {
+				t.Errorf("queued %s at %d, want %s at %d", evt.SessionID(), evt.Position, sessionID, position)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s was not queued at %d", sessionID, position)
+		}
+	}
+
+	go coord.Dispatch(StartSessionCommand(&account.Account{ID: "a", RoleName: "a", ServerID: 1}))
+	deadline := time.Now().Add(time.Second)
+	for {
+		coord.sessionsMu.RLock()
+		started := coord.logins["a"]
+		coord.sessionsMu.RUnlock()
+		if started {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("a did not take the login slot")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	for i, id := range []string{"b", "c"} {
+		if err := coord.Dispatch(StartSessionCommand(&account.Account{ID: id, RoleName: id, ServerID: 1})); err != nil {
+			t.Fatalf("Dispatch(%s) error = %v", id, err)
+		}
+		expectQueued(id, i+1)
+	}
+	if st := coord.GetSession("b").State(); st != state.StateIdle {
+		t.Errorf("queued session state = %s, want Idle", st)
+	}
+
+	// a fails to start; b takes its slot and c moves up
+	release <- struct{}{}
+	expectQueued("c", 1)
+
+	// Stopping c drops it from the queue
+	if err := coord.Dispatch(command.NewStopSession("c")); err != nil {
+		t.Fatalf("Dispatch(stop c) error = %v", err)
+	}
+
+	// b fails as well and is reported stopped, as nobody waits on its start
+	release <- struct{}{}
+	select {
+	case evt := <-stopped:
+		if evt.SessionID() != "b" || evt.Error == nil {
+			t.Errorf("stopped = %s, %v, want b with the start error", evt.SessionID(), evt.Error)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("b was not reported stopped")
+	}
+	coord.sessionsMu.RLock()
+	defer coord.sessionsMu.RUnlock()
+	if len(coord.logins) != 0 || len(coord.startQueue) != 0 {
+		t.Errorf("logins = %v, queue = %v, want both empty", coord.logins, coord.startQueue)
+	}
+}
+
+func TestStartSessionCommand_Emulation(t *testing.T) {
+	acc := &account.Account{ID: "a", Browser: &account.BrowserSettings{
+		PageScale:         0.8,
+		DeviceScaleFactor: 2,
+		Mobile:            true,
+	}}
+
+	cmd := StartSessionCommand(acc)
+	if cmd.Browser == nil || cmd.Browser.PageScale != 0.8 || cmd.Browser.DeviceScaleFactor != 2 || !cmd.Browser.Mobile {
+		t.Fatalf("command browser = %+v", cmd.Browser)
+	}
+
+	def := browser.DefaultDriverConfig()
+	config := driverConfig(nil, acc)
+	if config.PageScale != 0.8 || config.DeviceScaleFactor != 2 || !config.Mobile {
+		t.Errorf("driver config = %+v", config)
+	}
+	if config.ViewportWidth != def.ViewportWidth || config.Headless != def.Headless {
+		t.Errorf("emulation should keep the default viewport, got %+v", config)
+	}
+}
+
+func TestCoordinator_Preflight(t *testing.T) {
+	scenes := domainscene.NewRegistry()
+	scenes.Register(&domainscene.Scene{Name: "main"})
+	scripts := domainscript.NewRegistry()
+	scripts.RegisterAll([]*domainscript.Script{
+		{Name: "daily", Steps: []domainscript.Step{{
+			ExpectedScene: "main",
+			Actions:       []domainscript.Action{{Type: domainscript.ActionTypeCall, Script: "close_popups"}},
+		}}},
+		{Name: "close_popups", Steps: []domainscript.Step{{
+			ExpectedScene: "popup",
+			OCRRule:       &domainscript.OCRRule{Name: domainscript.OCRRuleQuitWhenExhausted},
+		}}},
+	})
+	coord := NewCoordinator(&CoordinatorConfig{
+		SceneRegistry:  scenes,
+		ScriptRegistry: scripts,
+		OCRClient:      ocr.NewNoOpClient(),
+	})
+
+	accounts := []*account.Account{
+		{ID: "a1", RoleName: "one", Password: "secret"},
+		{ID: "a2", RoleName: "two", BlockedScripts: []string{"daily"}, Cookies: []account.Cookie{{Name: "sid"}}},
+	}
+	report := coord.Preflight("daily", accounts)
+	got := make(map[string]PreflightStatus)
+	for _, c := range report.Checks {
+		got[c.Name] = c.Status
+	}
+	want := map[string]PreflightStatus{
+		"Script":             PreflightPassed,
+		"Scenes":             PreflightFailed, // popup, reached through the call
+		"OCR service":        PreflightFailed,
+		"Credentials":        PreflightPassed,
+		"Script permissions": PreflightWarning,
+	}
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("check %s = %v, want %v", name, got[name], status)
+		}
+	}
+	if !report.Blocked() {
+		t.Error("Blocked() = false, want true with failed checks")
+	}
+
+	accounts = append(accounts, &account.Account{ID: "a3", RoleName: "three"})
+	report = coord.Preflight("", accounts)
+	if len(report.Checks) != 1 || report.Checks[0].Name != "Credentials" || !report.Blocked() {
+		t.Errorf("Preflight without script = %+v, want a failed Credentials check only", report.Checks)
+	}
+
+	expired := []*account.Account{{ID: "a4", RoleName: "four",
+		Cookies: []account.Cookie{{Name: "sid", Expires: time.Now().Add(-time.Hour)}}}}
+	if report := coord.Preflight("", expired); !report.Blocked() {
+		t.Error("Preflight should fail accounts with only expired cookies")
+	}
+
+	if report := coord.Preflight("missing", nil); !report.Blocked() {
+		t.Error("Preflight(missing) should be blocked")
+	}
+}
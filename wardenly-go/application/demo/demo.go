@@ -0,0 +1,160 @@
+// Package demo provides what the app needs to run without MongoDB, Chrome
+// or real game credentials: fake accounts and groups for an in-memory store
+// and replayed frames for every session.
+//
+// Sessions use browser.ReplayDriver, so logins always succeed and input is
+// only counted. Without recorded frames, a synthetic frame is replayed and
+// registered as "main_city", so sessions reach Ready and scripts can be
+// started, although they find no other scene.
+package demo
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"time"
+
+	"wardenly-go/domain/account"
+	"wardenly-go/domain/group"
+	"wardenly-go/domain/scene"
+	"wardenly-go/infrastructure/browser"
+)
+
+// EnvFrames is a directory of recorded PNG or JPEG frames to replay instead
+// of the synthetic one, such as screenshots saved from real sessions.
+const EnvFrames = "WARDENLY_DEMO_FRAMES"
+
+// driverLatency makes the replayed page respond about as fast as a real one.
+const driverLatency = 30 * time.Millisecond
+
+// frameWidth and frameHeight are the size of the synthetic frames.
+const (
+	frameWidth  = 1080
+	frameHeight = 720
+)
+
+// Accounts are the fake accounts Seed creates. Passwords are placeholders;
+// the replay driver never sends them anywhere.
+var Accounts = []account.Account{
+	{RoleName: "Aster", UserName: "aster@demo.invalid", Password: "demo", ServerID: 101, Ranking: 1, Label: "MAIN", LabelColor: account.LabelColorBlue},
+	{RoleName: "Bramble", UserName: "bramble@demo.invalid", Password: "demo", ServerID: 101, Ranking: 2, Label: "farm", LabelColor: account.LabelColorGreen},
+	{RoleName: "Cinder", UserName: "cinder@demo.invalid", Password: "demo", ServerID: 101, Ranking: 3, Label: "farm", LabelColor: account.LabelColorGreen},
+	{RoleName: "Dusk", UserName: "dusk@demo.invalid", Password: "demo", ServerID: 202, Ranking: 4, Label: "ALT", LabelColor: account.LabelColorPurple},
+	{RoleName: "Ember", UserName: "ember@demo.invalid", Password: "demo", ServerID: 202, Ranking: 5},
+	{RoleName: "Frost", UserName: "frost@demo.invalid", Password: "demo", ServerID: 202, Ranking: 6, Archived: true},
+}
+
+// Seed fills empty account and group stores with the demo data: the
+// Accounts, a group of the first three and a smart group of the farm
+// accounts.
+func Seed(ctx context.Context, accounts *account.Service, groups *group.Service) error {
+	var ids []string
+	for _, acc := range Accounts {
+		if err := accounts.CreateAccount(ctx, &acc); err != nil {
+			return fmt.Errorf("failed to create demo account %s: %w", acc.RoleName, err)
+		}
+		ids = append(ids, acc.ID)
+	}
+
+	for _, grp := range []*group.Group{
+		{
+			Name:        "Morning Run",
+			Description: "Demo group of the server 101 accounts",
+			AccountIDs:  ids[:3],
+			Ranking:     1,
+			Settings:    group.RunSettings{StartInterval: 2 * time.Second},
+		},
+		{
+			Name:        "Farmers",
+			Description: "Demo smart group of the accounts tagged farm",
+			Query:       "tag=farm",
+			Ranking:     2,
+		},
+	} {
+		if err := groups.CreateGroup(ctx, grp); err != nil {
+			return fmt.Errorf("failed to create demo group %s: %w", grp.Name, err)
+		}
+	}
+	return nil
+}
+
+// Frames returns the frames to replay: those in the WARDENLY_DEMO_FRAMES
+// directory, or synthetic ones if it is not set. A directory that can't be
+// read is reported and the synthetic frames are used.
+func Frames() (frames []image.Image, synthetic bool, err error) {
+	dir := os.Getenv(EnvFrames)
+	if dir == "" {
+		return syntheticFrames(), true, nil
+	}
+	if frames, err = browser.LoadReplayFrames(dir); err != nil {
+		return syntheticFrames(), true, fmt.Errorf("%s: %w", EnvFrames, err)
+	}
+	return frames, false, nil
+}
+
+// DriverFactory returns a driver factory that replays frames in every
+// session.
+func DriverFactory(frames []image.Image) func(*browser.DriverConfig) browser.Driver {
+	return func(*browser.DriverConfig) browser.Driver {
+		return browser.NewReplayDriver(&browser.ReplayDriverConfig{Frames: frames, Latency: driverLatency})
+	}
+}
+
+// RegisterLoginScene registers "main_city" as the synthetic frames, which
+// no real scene matches, so logins finish. It is only needed for synthetic
+// frames; recorded ones match the real scenes.
+func RegisterLoginScene(registry *scene.Registry, frames []image.Image) {
+	if len(frames) == 0 {
+		return
+	}
+	img := frames[0]
+	b := img.Bounds()
+	sc := &scene.Scene{Name: "main_city", Category: "demo"}
+	// Sample the quadrants, which stay the same in every frame
+	for _, f := range [][2]int{{1, 1}, {3, 1}, {1, 3}, {3, 3}} {
+		x := b.Min.X + b.Dx()*f[0]/4
+		y := b.Min.Y + b.Dy()*f[1]/4
+		c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+		sc.Points = append(sc.Points, scene.Point{X: x, Y: y, Color: c})
+	}
+	registry.Register(sc)
+}
+
+// syntheticFrames draws four colored quadrants with a bar moving along the
+// bottom edge, so the streamed view visibly updates.
+func syntheticFrames() []image.Image {
+	quadrants := []color.RGBA{
+		{R: 70, G: 110, B: 160, A: 255},
+		{R: 90, G: 150, B: 90, A: 255},
+		{R: 170, G: 130, B: 70, A: 255},
+		{R: 130, G: 80, B: 140, A: 255},
+	}
+	bar := color.RGBA{R: 230, G: 230, B: 230, A: 255}
+	const steps = 8
+
+	frames := make([]image.Image, steps)
+	for i := range frames {
+		img := image.NewRGBA(image.Rect(0, 0, frameWidth, frameHeight))
+		barStart := frameWidth * i / steps
+		for y := 0; y < frameHeight; y++ {
+			for x := 0; x < frameWidth; x++ {
+				if y >= frameHeight-24 && x >= barStart && x < barStart+frameWidth/steps {
+					img.SetRGBA(x, y, bar)
+					continue
+				}
+				q := 0
+				if x >= frameWidth/2 {
+					q++
+				}
+				if y >= frameHeight/2 {
+					q += 2
+				}
+				img.SetRGBA(x, y, quadrants[q])
+			}
+		}
+		frames[i] = img
+	}
+	return frames
+}
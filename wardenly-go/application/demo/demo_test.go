@@ -0,0 +1,57 @@
+package demo
+
+import (
+	"context"
+	"testing"
+
+	"wardenly-go/domain/account"
+	"wardenly-go/domain/group"
+	"wardenly-go/domain/scene"
+	"wardenly-go/infrastructure/repository"
+)
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+	db := repository.NewMemoryDB()
+	accountRepo := repository.NewFileAccountRepository(db, nil, nil)
+	accounts := account.NewService(accountRepo)
+	groups := group.NewService(repository.NewFileGroupRepository(db, nil), accountRepo)
+
+	if err := Seed(ctx, accounts, groups); err != nil {
+		t.Fatalf("Seed() error = %v", err)
+	}
+	if active, err := accounts.ListActiveAccounts(ctx); err != nil || len(active) != len(Accounts)-1 {
+		t.Errorf("ListActiveAccounts() = %d accounts, %v; want the unarchived ones", len(active), err)
+	}
+	for name, want := range map[string]int{"Morning Run": 3, "Farmers": 2} {
+		resolved, err := groups.GetGroupWithAccountsByName(ctx, name)
+		if err != nil || len(resolved.Accounts) != want {
+			t.Errorf("group %s = %+v, %v; want %d accounts", name, resolved, err, want)
+		}
+	}
+	if Accounts[0].ID != "" {
+		t.Error("Seed() changed the Accounts template")
+	}
+}
+
+func TestFrames_Synthetic(t *testing.T) {
+	t.Setenv(EnvFrames, "")
+	frames, synthetic, err := Frames()
+	if err != nil || !synthetic || len(frames) == 0 {
+		t.Fatalf("Frames() = %d frames, %v, %v", len(frames), synthetic, err)
+	}
+
+	registry := scene.NewRegistry()
+	RegisterLoginScene(registry, frames)
+	matcher := scene.NewMatcher(0)
+	for i, img := range frames {
+		if sc := registry.FindMatch(img, matcher, "main_city"); sc == nil {
+			t.Errorf("frame %d doesn't match the login scene", i)
+		}
+	}
+
+	t.Setenv(EnvFrames, t.TempDir()+"/missing")
+	if frames, synthetic, err := Frames(); err == nil || !synthetic || len(frames) == 0 {
+		t.Error("Frames() should report a missing frame directory and fall back to synthetic frames")
+	}
+}
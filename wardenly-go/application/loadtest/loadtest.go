@@ -0,0 +1,487 @@
+// Package loadtest drives many replayed sessions through the Coordinator and
+// EventBus to measure how the actor pipeline behaves under load.
+//
+// Sessions use browser.ReplayDriver, so no browser is started. Each session
+// logs in, runs a script and receives a steady stream of click commands,
+// while a probe measures the time from dispatching a command to receiving
+// the event it produces.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"wardenly-go/application"
+	"wardenly-go/application/session"
+	"wardenly-go/core/command"
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+	"wardenly-go/core/state"
+	domainscene "wardenly-go/domain/scene"
+	domainscript "wardenly-go/domain/script"
+	"wardenly-go/infrastructure/browser"
+)
+
+// syntheticScript is the name of the script used when Config.ScriptName is empty.
+const syntheticScript = "loadtest"
+
+// Config holds configuration for a load test run.
+type Config struct {
+	// Sessions is the number of fake sessions. Defaults to 10.
+	Sessions int
+	// Duration is how long the workload runs once all sessions are ready.
+	// Defaults to 30s.
+	Duration time.Duration
+	// ReadyTimeout bounds the wait for sessions to log in. Defaults to 60s.
+	ReadyTimeout time.Duration
+
+	// Frames are replayed by every session's driver. When SceneRegistry is
+	// nil, the first frame is registered as "main_city" so logins succeed
+	// and the synthetic script matches.
+	Frames []image.Image
+	// DriverLatency is added to every replayed browser operation.
+	DriverLatency time.Duration
+
+	// CommandRate is the number of click commands per second sent to each
+	// session. Defaults to 10.
+	CommandRate int
+	// ProbeInterval is the pause between latency probes per session.
+	// Defaults to 200ms.
+	ProbeInterval time.Duration
+	// ScreencastFPS starts a screencast on every session when positive,
+	// adding a frame event stream to the bus.
+	ScreencastFPS int
+
+	// SceneRegistry and ScriptRegistry replace the synthetic registries,
+	// e.g. to replay recorded frames against the real scenes and scripts.
+	SceneRegistry  *domainscene.Registry
+	ScriptRegistry *domainscript.Registry
+	// ScriptName is started on every session. Defaults to a synthetic
+	// script that clicks the centre of "main_city" in a loop.
+	ScriptName string
+
+	// EventBufferSize is the event bus buffer. Defaults to 100 like the app.
+	EventBufferSize int
+
+	Logger *slog.Logger
+}
+
+// Run executes a load test and returns its report. It returns early with
+// an error if ctx is cancelled or not a single session becomes ready.
+func Run(ctx context.Context, cfg *Config) (*Report, error) {
+	cfg = withDefaults(cfg)
+	r := newRunner(cfg)
+	return r.run(ctx)
+}
+
+func withDefaults(cfg *Config) *Config {
+	c := *cfg
+	if c.Sessions <= 0 {
+		c.Sessions = 10
+	}
+	if c.Duration <= 0 {
+		c.Duration = 30 * time.Second
+	}
+	if c.ReadyTimeout <= 0 {
+		c.ReadyTimeout = 60 * time.Second
+	}
+	if len(c.Frames) == 0 {
+		c.Frames = []image.Image{syntheticFrame()}
+	}
+	if c.CommandRate <= 0 {
+		c.CommandRate = 10
+	}
+	if c.ProbeInterval <= 0 {
+		c.ProbeInterval = 200 * time.Millisecond
+	}
+	if c.EventBufferSize <= 0 {
+		c.EventBufferSize = 100
+	}
+	if c.Logger == nil {
+		c.Logger = slog.Default()
+	}
+	if c.SceneRegistry == nil {
+		c.SceneRegistry = domainscene.NewRegistry()
+		c.SceneRegistry.Register(sceneFromFrame("main_city", c.Frames[0]))
+	}
+	if c.ScriptName == "" {
+		if c.ScriptRegistry == nil {
+			c.ScriptRegistry = domainscript.NewRegistry()
+		}
+		c.ScriptRegistry.Register(syntheticLoopScript(c.Frames[0].Bounds()))
+		c.ScriptName = syntheticScript
+	}
+	if c.ScriptRegistry == nil {
+		c.ScriptRegistry = domainscript.NewRegistry()
+	}
+	return &c
+}
+
+// runner holds the state of one load test run.
+type runner struct {
+	cfg *Config
+
+	bus         *countingBus
+	coordinator *application.Coordinator
+
+	// Session readiness
+	readyMu sync.Mutex
+	ready   map[string]bool
+	readyCh chan struct{}
+
+	// Outstanding latency probes by session ID
+	probeMu sync.Mutex
+	probes  map[string]chan struct{}
+
+	// Counters
+	measuring  atomic.Bool
+	sent       atomic.Int64
+	dropped    atomic.Int64
+	failed     atomic.Int64
+	events     atomic.Int64
+	probesLost atomic.Int64
+	latencies  latencyRecorder
+	goroutines goroutineSampler
+}
+
+func newRunner(cfg *Config) *runner {
+	return &runner{
+		cfg:     cfg,
+		ready:   make(map[string]bool),
+		readyCh: make(chan struct{}),
+		probes:  make(map[string]chan struct{}),
+	}
+}
+
+func (r *runner) run(ctx context.Context) (*Report, error) {
+	report := &Report{
+		Sessions:           r.cfg.Sessions,
+		CommandRate:        r.cfg.CommandRate,
+		GoroutinesBaseline: runtime.NumGoroutine(),
+	}
+
+	r.bus = &countingBus{EventBus: eventbus.New(r.cfg.EventBufferSize), measuring: &r.measuring}
+	r.bus.Subscribe(r.handleEvent)
+
+	frames, latency := r.cfg.Frames, r.cfg.DriverLatency
+	r.coordinator = application.NewCoordinator(&application.CoordinatorConfig{
+		EventBus:       r.bus,
+		SceneRegistry:  r.cfg.SceneRegistry,
+		ScriptRegistry: r.cfg.ScriptRegistry,
+		DriverFactory: func(*browser.DriverConfig) browser.Driver {
+			return browser.NewReplayDriver(&browser.ReplayDriverConfig{Frames: frames, Latency: latency})
+		},
+		Logger: r.cfg.Logger,
+	})
+	r.coordinator.Start()
+
+	samplerCtx, stopSampler := context.WithCancel(ctx)
+	go r.goroutines.run(samplerCtx, 100*time.Millisecond)
+
+	var shutdownOnce sync.Once
+	shutdown := func() {
+		shutdownOnce.Do(func() {
+			stopSampler()
+			r.coordinator.Stop()
+			r.bus.Close()
+		})
+	}
+	defer shutdown()
+
+	// Start sessions and wait for them to log in
+	started := time.Now()
+	ids := make([]string, r.cfg.Sessions)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("loadtest-%03d", i+1)
+		if err := r.coordinator.Dispatch(&command.StartSession{
+			AccountID: ids[i],
+			RoleName:  ids[i],
+			UserName:  ids[i],
+			ServerID:  1,
+		}); err != nil {
+			r.cfg.Logger.Warn("Failed to start load test session", "session_id", ids[i], "error", err)
+		}
+	}
+
+	select {
+	case <-r.readyCh:
+	case <-time.After(r.cfg.ReadyTimeout):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	report.ReadySessions = r.readyCount()
+	report.StartupTime = time.Since(started)
+	if report.ReadySessions == 0 {
+		return nil, errors.New("no session became ready")
+	}
+
+	// Run the workload on the ready sessions
+	for _, id := range ids {
+		if !r.isReady(id) {
+			continue
+		}
+		if err := r.coordinator.Dispatch(command.NewStartScript(id, r.cfg.ScriptName)); err != nil {
+			r.cfg.Logger.Warn("Failed to start load test script", "session_id", id, "error", err)
+		}
+		if r.cfg.ScreencastFPS > 0 {
+			if err := r.coordinator.Dispatch(command.NewStartScreencast(id, 60, r.cfg.ScreencastFPS)); err != nil {
+				r.cfg.Logger.Warn("Failed to start load test screencast", "session_id", id, "error", err)
+			}
+		}
+	}
+
+	workCtx, stopWork := context.WithTimeout(ctx, r.cfg.Duration)
+	defer stopWork()
+
+	r.measuring.Store(true)
+	workStart := time.Now()
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		if !r.isReady(id) {
+			continue
+		}
+		wg.Add(2)
+		go func(id string) {
+			defer wg.Done()
+			r.sendCommands(workCtx, id)
+		}(id)
+		go func(id string) {
+			defer wg.Done()
+			r.probe(workCtx, id)
+		}(id)
+	}
+	wg.Wait()
+	r.measuring.Store(false)
+	report.Duration = time.Since(workStart)
+	report.GoroutinesRunning = runtime.NumGoroutine()
+
+	// Stop everything and let goroutines wind down before the final count
+	if err := r.coordinator.Dispatch(&command.StopAllSessions{}); err != nil {
+		r.cfg.Logger.Warn("Failed to stop load test sessions", "error", err)
+	}
+	r.waitStopped(5 * time.Second)
+	report.GoroutinesPeak = r.goroutines.peak()
+	shutdown()
+	settleGoroutines(report.GoroutinesBaseline, time.Second)
+
+	report.CommandsSent = r.sent.Load()
+	report.CommandsDropped = r.dropped.Load()
+	report.CommandsFailed = r.failed.Load()
+	report.EventsPublished = r.bus.published.Load()
+	report.EventsReceived = r.events.Load()
+	report.ProbesLost = r.probesLost.Load()
+	report.Latency = r.latencies.summary()
+	report.GoroutinesAfterStop = runtime.NumGoroutine()
+
+	return report, nil
+}
+
+// sendCommands dispatches clicks at the configured rate until ctx ends.
+func (r *runner) sendCommands(ctx context.Context, sessionID string) {
+	ticker := time.NewTicker(time.Second / time.Duration(r.cfg.CommandRate))
+	defer ticker.Stop()
+
+	bounds := r.cfg.Frames[0].Bounds()
+	x, y := float64(bounds.Dx())/2, float64(bounds.Dy())/2
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.dispatch(command.NewClick(sessionID, x, y))
+		}
+	}
+}
+
+// probe repeatedly sends SaveCookies and waits for the matching CookiesSaved
+// event. Only the harness sends SaveCookies, so each event answers the one
+// outstanding probe of its session.
+func (r *runner) probe(ctx context.Context, sessionID string) {
+	const probeTimeout = 2 * time.Second
+
+	for {
+		done := make(chan struct{})
+		r.probeMu.Lock()
+		r.probes[sessionID] = done
+		r.probeMu.Unlock()
+
+		sentAt := time.Now()
+		if r.dispatch(command.NewSaveCookies(sessionID)) {
+			select {
+			case <-done:
+				r.latencies.record(time.Since(sentAt))
+			case <-time.After(probeTimeout):
+				r.probesLost.Add(1)
+			case <-ctx.Done():
+				// Still in flight when the workload ended; not counted as lost
+			}
+		}
+
+		r.probeMu.Lock()
+		delete(r.probes, sessionID)
+		r.probeMu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.cfg.ProbeInterval):
+		}
+	}
+}
+
+// dispatch sends a command and counts the outcome. It reports whether the
+// command was accepted.
+func (r *runner) dispatch(cmd command.Command) bool {
+	r.sent.Add(1)
+	err := r.coordinator.Dispatch(cmd)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, session.ErrQueueFull):
+		r.dropped.Add(1)
+	default:
+		r.failed.Add(1)
+	}
+	return false
+}
+
+func (r *runner) handleEvent(e event.Event) {
+	if r.measuring.Load() {
+		r.events.Add(1)
+	}
+
+	switch evt := e.(type) {
+	case *event.SessionStateChanged:
+		if evt.NewState == state.StateReady && evt.OldState == state.StateLoggingIn {
+			r.markReady(evt.SessionID())
+		}
+	case *event.CookiesSaved:
+		r.probeMu.Lock()
+		if done, ok := r.probes[evt.SessionID()]; ok {
+			close(done)
+			delete(r.probes, evt.SessionID())
+		}
+		r.probeMu.Unlock()
+	}
+}
+
+func (r *runner) markReady(sessionID string) {
+	r.readyMu.Lock()
+	defer r.readyMu.Unlock()
+
+	if r.ready[sessionID] {
+		return
+	}
+	r.ready[sessionID] = true
+	if len(r.ready) == r.cfg.Sessions {
+		close(r.readyCh)
+	}
+}
+
+func (r *runner) isReady(sessionID string) bool {
+	r.readyMu.Lock()
+	defer r.readyMu.Unlock()
+	return r.ready[sessionID]
+}
+
+func (r *runner) readyCount() int {
+	r.readyMu.Lock()
+	defer r.readyMu.Unlock()
+	return len(r.ready)
+}
+
+// countingBus counts the events published while the workload runs.
+// Comparing with the delivered count reveals events the bus dropped.
+type countingBus struct {
+	eventbus.EventBus
+	measuring *atomic.Bool
+	published atomic.Int64
+}
+
+func (b *countingBus) Publish(e event.Event) {
+	if b.measuring.Load() {
+		b.published.Add(1)
+	}
+	b.EventBus.Publish(e)
+}
+
+// waitStopped waits until the coordinator has no sessions left.
+func (r *runner) waitStopped(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for r.coordinator.SessionCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// settleGoroutines waits until the goroutine count drops to baseline,
+// giving exiting goroutines a moment to finish.
+func settleGoroutines(baseline int, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// syntheticFrame returns a frame with distinct colours in each quadrant,
+// so a scene built from it doesn't match a blank screen.
+func syntheticFrame() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 1080, 720))
+	quadrants := []color.RGBA{
+		{R: 200, G: 60, B: 60, A: 255},
+		{R: 60, G: 200, B: 60, A: 255},
+		{R: 60, G: 60, B: 200, A: 255},
+		{R: 200, G: 200, B: 60, A: 255},
+	}
+	for y := 0; y < 720; y++ {
+		for x := 0; x < 1080; x++ {
+			q := 0
+			if x >= 540 {
+				q++
+			}
+			if y >= 360 {
+				q += 2
+			}
+			img.SetRGBA(x, y, quadrants[q])
+		}
+	}
+	return img
+}
+
+// sceneFromFrame builds a scene whose check points are sampled from img.
+func sceneFromFrame(name string, img image.Image) *domainscene.Scene {
+	b := img.Bounds()
+	sc := &domainscene.Scene{Name: name, Category: "loadtest"}
+	for _, f := range [][2]int{{1, 1}, {3, 1}, {2, 2}, {1, 3}, {3, 3}} {
+		x := b.Min.X + b.Dx()*f[0]/4
+		y := b.Min.Y + b.Dy()*f[1]/4
+		c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+		sc.Points = append(sc.Points, domainscene.Point{X: x, Y: y, Color: c})
+	}
+	return sc
+}
+
+// syntheticLoopScript clicks the frame centre whenever "main_city" matches.
+func syntheticLoopScript(bounds image.Rectangle) *domainscript.Script {
+	centre := domainscript.Point{X: float64(bounds.Dx()) / 2, Y: float64(bounds.Dy()) / 2}
+	return &domainscript.Script{
+		Name:        syntheticScript,
+		Description: "Load test workload",
+		Steps: []domainscript.Step{{
+			ExpectedScene: "main_city",
+			Timeout:       5 * time.Second,
+			Actions: []domainscript.Action{
+				{Type: domainscript.ActionTypeClick, Points: []domainscript.Point{centre}},
+				{Type: domainscript.ActionTypeWait, Duration: 100 * time.Millisecond},
+			},
+		}},
+	}
+}
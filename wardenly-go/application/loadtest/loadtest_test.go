@@ -0,0 +1,68 @@
+package loadtest
+
+import (
+	"context"
+	"image"
+	"testing"
+	"time"
+
+	domainscene "wardenly-go/domain/scene"
+)
+
+func TestSceneFromFrame_MatchesOnlyItsFrame(t *testing.T) {
+	frame := syntheticFrame()
+	sc := sceneFromFrame("main_city", frame)
+	matcher := domainscene.NewMatcher(0)
+
+	if !matcher.Match(sc, frame) {
+		t.Error("scene does not match the frame it was built from")
+	}
+	if matcher.Match(sc, image.NewRGBA(frame.Bounds())) {
+		t.Error("scene matches a blank frame")
+	}
+}
+
+func TestLatencyRecorder_Summary(t *testing.T) {
+	var l latencyRecorder
+	for i := 100; i >= 1; i-- {
+		l.record(time.Duration(i) * time.Millisecond)
+	}
+
+	got := l.summary()
+	want := LatencySummary{
+		Count: 100,
+		P50:   50 * time.Millisecond,
+		P95:   95 * time.Millisecond,
+		P99:   99 * time.Millisecond,
+		Max:   100 * time.Millisecond,
+	}
+	if got != want {
+		t.Errorf("summary() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRun_ReplayedSessions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("load test waits for sessions to log in")
+	}
+
+	report, err := Run(context.Background(), &Config{
+		Sessions:     3,
+		Duration:     time.Second,
+		ReadyTimeout: 30 * time.Second,
+		CommandRate:  20,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.ReadySessions != 3 {
+		t.Errorf("ReadySessions = %d, want 3", report.ReadySessions)
+	}
+	if report.CommandsSent == 0 || report.EventsReceived == 0 {
+		t.Errorf("no traffic: %d commands, %d events", report.CommandsSent, report.EventsReceived)
+	}
+	if report.Latency.Count == 0 {
+		t.Error("no latency probes answered")
+	}
+}
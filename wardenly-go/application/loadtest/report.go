@@ -0,0 +1,193 @@
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Report summarizes a load test run.
+type Report struct {
+	Sessions      int
+	ReadySessions int
+	CommandRate   int // click commands per second per session
+
+	StartupTime time.Duration // until all sessions were ready (or the timeout)
+	Duration    time.Duration // length of the measured workload
+
+	CommandsSent    int64
+	CommandsDropped int64 // rejected because a session queue was full
+	CommandsFailed  int64 // rejected for any other reason
+	EventsPublished int64 // events published during the workload
+	EventsReceived  int64 // events delivered to subscribers during the workload
+	ProbesLost      int64 // probes without a reply within the timeout
+
+	// Latency is the time from dispatching a probe command to receiving its event.
+	Latency LatencySummary
+
+	GoroutinesBaseline  int // before the coordinator was created
+	GoroutinesRunning   int // at the end of the workload
+	GoroutinesPeak      int // peak over the whole run
+	GoroutinesAfterStop int // after all sessions stopped
+}
+
+// LatencySummary holds latency percentiles.
+type LatencySummary struct {
+	Count         int
+	P50, P95, P99 time.Duration
+	Max           time.Duration
+}
+
+// DropRate returns the fraction of commands rejected because a queue was full.
+func (r *Report) DropRate() float64 {
+	if r.CommandsSent == 0 {
+		return 0
+	}
+	return float64(r.CommandsDropped) / float64(r.CommandsSent)
+}
+
+// EventDropRate returns the fraction of published events the bus never
+// delivered because its buffer was full.
+func (r *Report) EventDropRate() float64 {
+	if r.EventsPublished == 0 || r.EventsReceived >= r.EventsPublished {
+		return 0
+	}
+	return float64(r.EventsPublished-r.EventsReceived) / float64(r.EventsPublished)
+}
+
+// CommandThroughput returns the accepted commands per second.
+func (r *Report) CommandThroughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	accepted := r.CommandsSent - r.CommandsDropped - r.CommandsFailed
+	return float64(accepted) / r.Duration.Seconds()
+}
+
+// EventThroughput returns the delivered events per second.
+func (r *Report) EventThroughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.EventsReceived) / r.Duration.Seconds()
+}
+
+// LeakedGoroutines returns how many goroutines outlived the run.
+func (r *Report) LeakedGoroutines() int {
+	if n := r.GoroutinesAfterStop - r.GoroutinesBaseline; n > 0 {
+		return n
+	}
+	return 0
+}
+
+// WriteTo writes a human-readable summary to w.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	printf := func(format string, args ...any) error {
+		n, err := fmt.Fprintf(w, format, args...)
+		total += int64(n)
+		return err
+	}
+
+	lines := []struct {
+		format string
+		args   []any
+	}{
+		{"Sessions:    %d/%d ready in %s\n", []any{r.ReadySessions, r.Sessions, r.StartupTime.Round(time.Millisecond)}},
+		{"Workload:    %s at %d cmd/s per session\n", []any{r.Duration.Round(time.Millisecond), r.CommandRate}},
+		{"Commands:    %d sent, %d dropped (%.2f%%), %d failed, %.1f/s accepted\n",
+			[]any{r.CommandsSent, r.CommandsDropped, r.DropRate() * 100, r.CommandsFailed, r.CommandThroughput()}},
+		{"Events:      %d published, %d received (%.2f%% dropped), %.1f/s\n",
+			[]any{r.EventsPublished, r.EventsReceived, r.EventDropRate() * 100, r.EventThroughput()}},
+		{"Latency:     p50 %s, p95 %s, p99 %s, max %s (%d probes, %d lost)\n",
+			[]any{r.Latency.P50, r.Latency.P95, r.Latency.P99, r.Latency.Max, r.Latency.Count, r.ProbesLost}},
+		{"Goroutines:  %d baseline, %d running, %d peak, %d after stop\n",
+			[]any{r.GoroutinesBaseline, r.GoroutinesRunning, r.GoroutinesPeak, r.GoroutinesAfterStop}},
+	}
+	for _, l := range lines {
+		if err := printf(l.format, l.args...); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// latencyRecorder collects latency samples.
+type latencyRecorder struct {
+	mu      sync.Mutex
+	samples []time.Duration
+}
+
+func (l *latencyRecorder) record(d time.Duration) {
+	l.mu.Lock()
+	l.samples = append(l.samples, d)
+	l.mu.Unlock()
+}
+
+func (l *latencyRecorder) summary() LatencySummary {
+	l.mu.Lock()
+	samples := append([]time.Duration(nil), l.samples...)
+	l.mu.Unlock()
+
+	if len(samples) == 0 {
+		return LatencySummary{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	return LatencySummary{
+		Count: len(samples),
+		P50:   percentile(samples, 50),
+		P95:   percentile(samples, 95),
+		P99:   percentile(samples, 99),
+		Max:   samples[len(samples)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted samples.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// goroutineSampler tracks the peak goroutine count.
+type goroutineSampler struct {
+	mu  sync.Mutex
+	max int
+}
+
+func (g *goroutineSampler) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		g.sample()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *goroutineSampler) sample() {
+	n := runtime.NumGoroutine()
+	g.mu.Lock()
+	if n > g.max {
+		g.max = n
+	}
+	g.mu.Unlock()
+}
+
+func (g *goroutineSampler) peak() int {
+	g.sample()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.max
+}
@@ -0,0 +1,194 @@
+package application
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"wardenly-go/domain/account"
+	domainscript "wardenly-go/domain/script"
+)
+
+// PreflightStatus is the outcome of a single preflight check.
+type PreflightStatus int
+
+const (
+	PreflightPassed PreflightStatus = iota
+	PreflightWarning
+	PreflightFailed
+)
+
+// String returns the status as shown in the checklist.
+func (s PreflightStatus) String() string {
+	switch s {
+	case PreflightPassed:
+		return "Passed"
+	case PreflightWarning:
+		return "Warning"
+	case PreflightFailed:
+		return "Failed"
+	default:
+		return fmt.Sprintf("PreflightStatus(%d)", int(s))
+	}
+}
+
+// PreflightCheck is one line of the preflight checklist.
+type PreflightCheck struct {
+	Name   string
+	Status PreflightStatus
+	Detail string
+}
+
+// PreflightReport is the checklist run before a group run. Failed checks
+// block the launch; warnings only inform.
+type PreflightReport struct {
+	// ScriptName is the script the group starts, empty for none
+	ScriptName string
+	Checks     []PreflightCheck
+}
+
+// Blocked returns true if any check failed.
+func (r *PreflightReport) Blocked() bool {
+	for _, c := range r.Checks {
+		if c.Status == PreflightFailed {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *PreflightReport) add(name string, status PreflightStatus, detail string, args ...any) {
+	r.Checks = append(r.Checks, PreflightCheck{Name: name, Status: status, Detail: fmt.Sprintf(detail, args...)})
+}
+
+// Preflight checks that a group run of scriptName on accounts can succeed:
+// the script and the scripts it calls pass validation, every scene they wait
+// for is loaded, the OCR service is up if they use OCR rules, and every
+// account can log in. An empty scriptName only checks the accounts.
+func (c *Coordinator) Preflight(scriptName string, accounts []*account.Account) *PreflightReport {
+	report := &PreflightReport{ScriptName: scriptName}
+	if scriptName != "" {
+		c.preflightScript(report, scriptName)
+	}
+	c.preflightAccounts(report, scriptName, accounts)
+	return report
+}
+
+func (c *Coordinator) preflightScript(report *PreflightReport, scriptName string) {
+	script := c.lookupScript(scriptName)
+	if script == nil {
+		report.add("Script", PreflightFailed, "script %q is not loaded", scriptName)
+		return
+	}
+
+	scripts, err := c.calledScripts(script)
+	if err != nil {
+		report.add("Script", PreflightFailed, "%v", err)
+		return
+	}
+	for _, s := range scripts {
+		if err := s.Validate(); err != nil {
+			report.add("Script", PreflightFailed, "%s: %v", s.Name, err)
+			return
+		}
+	}
+	if len(scripts) > 1 {
+		report.add("Script", PreflightPassed, "%s and %d called scripts are valid", scriptName, len(scripts)-1)
+	} else {
+		report.add("Script", PreflightPassed, "%s is valid", scriptName)
+	}
+
+	var scenes, missing []string
+	usesOCR := false
+	for _, s := range scripts {
+		for _, name := range s.Scenes() {
+			if slices.Contains(scenes, name) {
+				continue
+			}
+			scenes = append(scenes, name)
+			if c.sceneRegistry == nil || c.sceneRegistry.Get(name) == nil {
+				missing = append(missing, name)
+			}
+		}
+		for _, category := range s.SceneCategories() {
+			pattern := category + "/*"
+			if slices.Contains(missing, pattern) {
+				continue
+			}
+			if c.sceneRegistry == nil || len(c.sceneRegistry.GetByCategory(category)) == 0 {
+				missing = append(missing, pattern)
+			}
+		}
+		usesOCR = usesOCR || s.UsesOCR()
+	}
+	if len(missing) > 0 {
+		report.add("Scenes", PreflightFailed, "missing scenes: %s", strings.Join(missing, ", "))
+	} else {
+		report.add("Scenes", PreflightPassed, "all %d scenes are loaded", len(scenes))
+	}
+
+	if usesOCR {
+		if c.ocrClient == nil || !c.ocrClient.IsHealthy() {
+			report.add("OCR service", PreflightFailed, "the script uses OCR rules but the OCR service is unavailable")
+		} else {
+			report.add("OCR service", PreflightPassed, "available")
+		}
+	}
+}
+
+// calledScripts returns script followed by every script it calls, directly
+// or indirectly. It fails on an unknown script or a call cycle.
+func (c *Coordinator) calledScripts(script *domainscript.Script) ([]*domainscript.Script, error) {
+	var all []*domainscript.Script
+	var path []string
+	var visit func(s *domainscript.Script) error
+	visit = func(s *domainscript.Script) error {
+		path = append(path, s.Name)
+		defer func() { path = path[:len(path)-1] }()
+		all = append(all, s)
+
+		for _, name := range s.Calls() {
+			if slices.Contains(path, name) {
+				return fmt.Errorf("call cycle: %s -> %s", strings.Join(path, " -> "), name)
+			}
+			if slices.ContainsFunc(all, func(seen *domainscript.Script) bool { return seen.Name == name }) {
+				continue
+			}
+			callee := c.lookupScript(name)
+			if callee == nil {
+				return fmt.Errorf("%s calls unknown script %q", s.Name, name)
+			}
+			if err := visit(callee); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(script); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+func (c *Coordinator) preflightAccounts(report *PreflightReport, scriptName string, accounts []*account.Account) {
+	var noLogin, refused []string
+	now := time.Now()
+	for _, acc := range accounts {
+		if (!acc.HasCookies() || acc.CookiesExpired(now)) && acc.Password == "" {
+			noLogin = append(noLogin, acc.Identity())
+		}
+		if scriptName != "" && acc.CheckScript(scriptName) != nil {
+			refused = append(refused, acc.Identity())
+		}
+	}
+
+	if len(noLogin) > 0 {
+		report.add("Credentials", PreflightFailed, "no valid cookies or password: %s", strings.Join(noLogin, ", "))
+	} else {
+		report.add("Credentials", PreflightPassed, "all %d accounts can log in", len(accounts))
+	}
+	if len(refused) > 0 {
+		report.add("Script permissions", PreflightWarning, "%s will not run on: %s", scriptName, strings.Join(refused, ", "))
+	}
+}
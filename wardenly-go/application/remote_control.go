@@ -0,0 +1,163 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"sort"
+
+	"wardenly-go/application/session"
+	"wardenly-go/core/command"
+	"wardenly-go/domain/account"
+	"wardenly-go/infrastructure/api"
+)
+
+// RemoteControl adapts the coordinator to the remote HTTP API.
+// Sessions it starts show up in the UI through the SessionStarted event.
+type RemoteControl struct {
+	coordinator    *Coordinator
+	accountService *account.Service
+}
+
+// NewRemoteControl creates the API controller.
+func NewRemoteControl(coordinator *Coordinator, accountService *account.Service) *RemoteControl {
+	return &RemoteControl{
+		coordinator:    coordinator,
+		accountService: accountService,
+	}
+}
+
+// Sessions returns all sessions ordered by ID.
+func (rc *RemoteControl) Sessions() []api.SessionInfo {
+	sessions := rc.coordinator.GetAllSessions()
+	infos := make([]api.SessionInfo, 0, len(sessions))
+	for _, sess := range sessions {
+		infos = append(infos, sessionInfo(sess))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// Session returns one session.
+func (rc *RemoteControl) Session(id string) (api.SessionInfo, error) {
+	sess := rc.coordinator.GetSession(id)
+	if sess == nil {
+		return api.SessionInfo{}, fmt.Errorf("%w: session %s", api.ErrNotFound, id)
+	}
+	return sessionInfo(sess), nil
+}
+
+func sessionInfo(sess *session.Session) api.SessionInfo {
+	return api.SessionInfo{
+		ID:             sess.ID(),
+		Account:        sess.Account().Identity(),
+		State:          sess.State().String(),
+		SelectedScript: sess.SelectedScript(),
+		ScriptRunning:  sess.IsScriptRunning(),
+	}
+}
+
+// Accounts returns the stored accounts that are not archived, in ranking order.
+func (rc *RemoteControl) Accounts(ctx context.Context) ([]api.AccountInfo, error) {
+	accounts, err := rc.accountService.ListActiveAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]api.AccountInfo, len(accounts))
+	for i, acc := range accounts {
+		infos[i] = api.AccountInfo{
+			ID:      acc.ID,
+			Name:    acc.Identity(),
+			Running: rc.coordinator.GetSession(acc.ID) != nil,
+		}
+	}
+	return infos, nil
+}
+
+// Scripts returns the registered script names.
+func (rc *RemoteControl) Scripts() []string {
+	return rc.coordinator.scriptRegistry.List()
+}
+
+// StartSession loads an account and starts its session.
+func (rc *RemoteControl) StartSession(ctx context.Context, accountID string) error {
+	if rc.coordinator.GetSession(accountID) != nil {
+		return fmt.Errorf("%w: session %s is already running", api.ErrConflict, accountID)
+	}
+	acc, err := rc.accountService.GetAccount(ctx, accountID)
+	if errors.Is(err, account.ErrAccountNotFound) {
+		return fmt.Errorf("%w: account %s", api.ErrNotFound, accountID)
+	}
+	if err != nil {
+		return err
+	}
+	if acc.Archived {
+		return fmt.Errorf("%w: %v: %s", api.ErrForbidden, account.ErrAccountArchived, acc.Identity())
+	}
+	return rc.coordinator.Dispatch(StartSessionCommand(acc))
+}
+
+// StopSession stops a session.
+func (rc *RemoteControl) StopSession(id string) error {
+	if rc.coordinator.GetSession(id) == nil {
+		return fmt.Errorf("%w: session %s", api.ErrNotFound, id)
+	}
+	return rc.coordinator.Dispatch(command.NewStopSession(id))
+}
+
+// StartScript starts a script, or the selected one when scriptName is empty.
+// Unlike the UI, it reports scripts refused by the account's script lists
+// or by exclusion groups.
+func (rc *RemoteControl) StartScript(id, scriptName string, params map[string]string) error {
+	sess := rc.coordinator.GetSession(id)
+	if sess == nil {
+		return fmt.Errorf("%w: session %s", api.ErrNotFound, id)
+	}
+	if scriptName == "" {
+		scriptName = sess.SelectedScript()
+	}
+	if scriptName == "" {
+		return fmt.Errorf("%w: no script selected on session %s", api.ErrConflict, id)
+	}
+	if !rc.coordinator.scriptRegistry.Exists(scriptName) {
+		return fmt.Errorf("%w: script %s", api.ErrNotFound, scriptName)
+	}
+	if err := sess.Account().CheckScript(scriptName); err != nil {
+		return fmt.Errorf("%w: %v", api.ErrForbidden, err)
+	}
+	if !sess.State().CanStartScript() {
+		return fmt.Errorf("%w: session %s is %s", api.ErrConflict, id, sess.State())
+	}
+	if err := rc.coordinator.ScriptConflict(sess, scriptName); err != nil {
+		return fmt.Errorf("%w: %v", api.ErrConflict, err)
+	}
+	return rc.coordinator.Dispatch(command.NewStartScriptWithParams(id, scriptName, params))
+}
+
+// StopScript stops the running script of a session.
+func (rc *RemoteControl) StopScript(id string) error {
+	sess := rc.coordinator.GetSession(id)
+	if sess == nil {
+		return fmt.Errorf("%w: session %s", api.ErrNotFound, id)
+	}
+	if !sess.IsScriptRunning() {
+		return fmt.Errorf("%w: no script running on session %s", api.ErrConflict, id)
+	}
+	return rc.coordinator.Dispatch(command.NewStopScript(id))
+}
+
+// CaptureScreen captures the current screen of a session.
+func (rc *RemoteControl) CaptureScreen(ctx context.Context, id string) (image.Image, error) {
+	sess := rc.coordinator.GetSession(id)
+	if sess == nil {
+		return nil, fmt.Errorf("%w: session %s", api.ErrNotFound, id)
+	}
+	if !sess.State().CanAcceptOperations() {
+		return nil, fmt.Errorf("%w: session %s is %s", api.ErrConflict, id, sess.State())
+	}
+	return sess.GetScreenCapture().Capture(ctx)
+}
+
+// Ensure RemoteControl implements api.Controller
+var _ api.Controller = (*RemoteControl)(nil)
@@ -0,0 +1,325 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"wardenly-go/core/command"
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+	"wardenly-go/core/state"
+	"wardenly-go/domain/account"
+	"wardenly-go/domain/group"
+	"wardenly-go/domain/schedule"
+)
+
+// Scheduler starts sessions at the times set by enabled schedules, starts the
+// scheduled script once each session is ready, and marks the sessions so the
+// coordinator stops them when the script finishes.
+//
+// Runs missed while the application was closed are skipped; each schedule
+// fires at its next matching time after startup or after a reload.
+type Scheduler struct {
+	coordinator    *Coordinator
+	eventBus       eventbus.EventBus
+	scheduleSvc    *schedule.Service
+	accountService *account.Service
+	groupService   *group.Service
+	logger         *slog.Logger
+
+	checkInterval time.Duration
+	startInterval time.Duration
+	now           func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*scheduleEntry // by schedule ID
+	pending map[string]string         // session ID -> script to start once ready
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// scheduleEntry is an enabled schedule with its next trigger time.
+type scheduleEntry struct {
+	schedule *schedule.Schedule
+	next     time.Time
+}
+
+// SchedulerConfig holds configuration for the Scheduler.
+type SchedulerConfig struct {
+	Coordinator     *Coordinator
+	EventBus        eventbus.EventBus
+	ScheduleService *schedule.Service
+	AccountService  *account.Service
+	GroupService    *group.Service
+	Logger          *slog.Logger
+
+	// CheckInterval is how often due schedules are checked. Defaults to 30s.
+	CheckInterval time.Duration
+	// StartInterval spaces out session starts within a group unless the
+	// group's run settings set one. Defaults to 3s.
+	StartInterval time.Duration
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewScheduler creates a scheduler. Call Start to begin triggering schedules.
+func NewScheduler(cfg *SchedulerConfig) *Scheduler {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 30 * time.Second
+	}
+	if cfg.StartInterval <= 0 {
+		cfg.StartInterval = 3 * time.Second
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &Scheduler{
+		coordinator:    cfg.Coordinator,
+		eventBus:       cfg.EventBus,
+		scheduleSvc:    cfg.ScheduleService,
+		accountService: cfg.AccountService,
+		groupService:   cfg.GroupService,
+		logger:         cfg.Logger,
+		checkInterval:  cfg.CheckInterval,
+		startInterval:  cfg.StartInterval,
+		now:            cfg.Now,
+		entries:        make(map[string]*scheduleEntry),
+		pending:        make(map[string]string),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+
+	if s.eventBus != nil {
+		s.eventBus.Subscribe(s.handleEvent)
+	}
+
+	return s
+}
+
+// Start loads the schedules and begins the check loop. The loop runs even
+// if loading fails, so a later Reload can still activate schedules.
+func (s *Scheduler) Start() error {
+	err := s.Reload(s.ctx)
+
+	s.wg.Add(1)
+	go s.loop()
+
+	s.logger.Info("Scheduler started", "schedules", s.EntryCount())
+	return err
+}
+
+// Stop ends the check loop and any pending group starts.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info("Scheduler stopped")
+}
+
+// Reload re-reads the schedules, e.g. after they were edited.
+// Next trigger times are computed from the current time.
+func (s *Scheduler) Reload(ctx context.Context) error {
+	schedules, err := s.scheduleSvc.ListSchedules(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load schedules: %w", err)
+	}
+
+	now := s.now()
+	entries := make(map[string]*scheduleEntry)
+	for _, sch := range schedules {
+		if !sch.Enabled {
+			continue
+		}
+		next, err := sch.Next(now)
+		if err != nil || next.IsZero() {
+			s.logger.Warn("Skipping schedule that never triggers", "schedule", sch.Name, "spec", sch.Spec, "error", err)
+			continue
+		}
+		entries[sch.ID] = &scheduleEntry{schedule: sch, next: next}
+	}
+
+	s.mu.Lock()
+	s.entries = entries
+	s.mu.Unlock()
+	return nil
+}
+
+// NextRun returns the next trigger time of a schedule,
+// or the zero time if it is disabled or unknown.
+func (s *Scheduler) NextRun(scheduleID string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[scheduleID]; ok {
+		return e.next
+	}
+	return time.Time{}
+}
+
+// EntryCount returns the number of active schedules.
+func (s *Scheduler) EntryCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+func (s *Scheduler) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			for _, sch := range s.due(s.now()) {
+				s.wg.Add(1)
+				go func(sch *schedule.Schedule) {
+					defer s.wg.Done()
+					s.trigger(sch)
+				}(sch)
+			}
+		}
+	}
+}
+
+// due returns the schedules whose trigger time has passed and advances them.
+func (s *Scheduler) due(now time.Time) []*schedule.Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*schedule.Schedule
+	for id, e := range s.entries {
+		if now.Before(e.next) {
+			continue
+		}
+		due = append(due, e.schedule)
+
+		next, err := e.schedule.Next(now)
+		if err != nil || next.IsZero() {
+			delete(s.entries, id)
+			continue
+		}
+		e.next = next
+	}
+	return due
+}
+
+// trigger starts a session for every target account not already running.
+func (s *Scheduler) trigger(sch *schedule.Schedule) {
+	s.logger.Info("Schedule triggered", "schedule", sch.Name, "script", sch.ScriptName)
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	if err := s.scheduleSvc.MarkRun(ctx, sch.ID, s.now()); err != nil {
+		s.logger.Warn("Failed to record schedule run", "schedule", sch.Name, "error", err)
+	}
+	accounts, interval, err := s.resolveAccounts(ctx, sch)
+	cancel()
+	if err != nil {
+		s.logger.Error("Failed to resolve schedule target", "schedule", sch.Name, "error", err)
+		return
+	}
+
+	started := 0
+	for _, acc := range accounts {
+		if s.coordinator.GetSession(acc.ID) != nil {
+			s.logger.Info("Scheduled account already running, skipping", "schedule", sch.Name, "account", acc.Identity())
+			continue
+		}
+
+		if started > 0 {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+
+		s.mu.Lock()
+		s.pending[acc.ID] = sch.ScriptName
+		s.mu.Unlock()
+
+		if err := s.coordinator.Dispatch(scheduledStartSession(acc)); err != nil {
+			s.logger.Error("Failed to start scheduled session", "schedule", sch.Name, "account", acc.Identity(), "error", err)
+			s.mu.Lock()
+			delete(s.pending, acc.ID)
+			s.mu.Unlock()
+			continue
+		}
+		started++
+	}
+}
+
+// resolveAccounts returns the target accounts and the delay between their starts.
+func (s *Scheduler) resolveAccounts(ctx context.Context, sch *schedule.Schedule) ([]*account.Account, time.Duration, error) {
+	switch sch.TargetType {
+	case schedule.TargetAccount:
+		acc, err := s.accountService.GetAccount(ctx, sch.TargetID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if acc.Archived {
+			return nil, 0, fmt.Errorf("%w: %s", account.ErrAccountArchived, acc.Identity())
+		}
+		return []*account.Account{acc}, s.startInterval, nil
+	case schedule.TargetGroup:
+		resolved, err := s.groupService.GetGroupWithAccounts(ctx, sch.TargetID)
+		if err != nil {
+			return nil, 0, err
+		}
+		interval := s.startInterval
+		if resolved.Group.Settings.StartInterval > 0 {
+			interval = resolved.Group.Settings.StartInterval
+		}
+		return resolved.Accounts, interval, nil
+	default:
+		return nil, 0, fmt.Errorf("unknown target type %q", sch.TargetType)
+	}
+}
+
+// handleEvent starts the scheduled script once a scheduled session is ready.
+func (s *Scheduler) handleEvent(e event.Event) {
+	switch evt := e.(type) {
+	case *event.SessionStateChanged:
+		if evt.NewState != state.StateReady || evt.OldState != state.StateLoggingIn {
+			return
+		}
+		s.mu.Lock()
+		scriptName, ok := s.pending[evt.SessionID()]
+		delete(s.pending, evt.SessionID())
+		s.mu.Unlock()
+
+		if ok {
+			// Dispatch outside the event goroutine; the session may be busy
+			go func() {
+				if err := s.coordinator.Dispatch(command.NewStartScript(evt.SessionID(), scriptName)); err != nil {
+					s.logger.Error("Failed to start scheduled script", "session_id", evt.SessionID(), "script", scriptName, "error", err)
+				}
+			}()
+		}
+	case *event.SessionStopped:
+		s.mu.Lock()
+		delete(s.pending, evt.SessionID())
+		s.mu.Unlock()
+	}
+}
+
+// scheduledStartSession builds the start command for a scheduled run.
+// The session stops itself once the script finishes.
+func scheduledStartSession(acc *account.Account) *command.StartSession {
+	cmd := StartSessionCommand(acc)
+	cmd.StopOnScriptFinish = true
+	return cmd
+}
@@ -0,0 +1,128 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"wardenly-go/core/event"
+	domainscene "wardenly-go/domain/scene"
+	"wardenly-go/domain/schedule"
+	domainscript "wardenly-go/domain/script"
+)
+
+// memScheduleRepo is an in-memory schedule.Repository.
+type memScheduleRepo struct {
+	schedules map[string]*schedule.Schedule
+	lastRuns  map[string]time.Time
+}
+
+func newMemScheduleRepo(schedules ...*schedule.Schedule) *memScheduleRepo {
+	r := &memScheduleRepo{
+		schedules: make(map[string]*schedule.Schedule),
+		lastRuns:  make(map[string]time.Time),
+	}
+	for _, sch := range schedules {
+		r.schedules[sch.ID] = sch
+	}
+	return r
+}
+
+func (r *memScheduleRepo) FindByID(ctx context.Context, id string) (*schedule.Schedule, error) {
+	return r.schedules[id], nil
+}
+
+func (r *memScheduleRepo) FindAll(ctx context.Context) ([]*schedule.Schedule, error) {
+	all := make([]*schedule.Schedule, 0, len(r.schedules))
+	for _, sch := range r.schedules {
+		all = append(all, sch)
+	}
+	return all, nil
+}
+
+func (r *memScheduleRepo) Insert(ctx context.Context, sch *schedule.Schedule) error {
+	r.schedules[sch.ID] = sch
+	return nil
+}
+
+func (r *memScheduleRepo) Update(ctx context.Context, sch *schedule.Schedule) error {
+	r.schedules[sch.ID] = sch
+	return nil
+}
+
+func (r *memScheduleRepo) UpdateLastRun(ctx context.Context, id string, at time.Time) error {
+	r.lastRuns[id] = at
+	return nil
+}
+
+func (r *memScheduleRepo) Delete(ctx context.Context, id string) error {
+	delete(r.schedules, id)
+	return nil
+}
+
+func newTestScheduler(t *testing.T, now time.Time, schedules ...*schedule.Schedule) *Scheduler {
+	t.Helper()
+	coord := NewCoordinator(&CoordinatorConfig{
+		SceneRegistry:  domainscene.NewRegistry(),
+		ScriptRegistry: domainscript.NewRegistry(),
+	})
+	t.Cleanup(coord.Stop)
+
+	return NewScheduler(&SchedulerConfig{
+		Coordinator:     coord,
+		ScheduleService: schedule.NewService(newMemScheduleRepo(schedules...)),
+		Now:             func() time.Time { return now },
+	})
+}
+
+func TestScheduler_ReloadSkipsDisabled(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 0, 0, 0, time.UTC)
+	s := newTestScheduler(t, now,
+		&schedule.Schedule{ID: "on", Name: "on", Spec: "daily 06:00", Enabled: true},
+		&schedule.Schedule{ID: "off", Name: "off", Spec: "daily 06:00"},
+	)
+
+	if err := s.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got, want := s.NextRun("on"), time.Date(2026, 3, 4, 6, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("NextRun(on) = %v, want %v", got, want)
+	}
+	if !s.NextRun("off").IsZero() {
+		t.Error("disabled schedule has a next run")
+	}
+}
+
+func TestScheduler_DueAdvancesNextRun(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 0, 0, 0, time.UTC)
+	s := newTestScheduler(t, now,
+		&schedule.Schedule{ID: "a", Name: "a", Spec: "daily 06:00", Enabled: true},
+	)
+	if err := s.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if due := s.due(now.Add(30 * time.Minute)); len(due) != 0 {
+		t.Fatalf("due() before trigger time = %d schedules", len(due))
+	}
+
+	at := time.Date(2026, 3, 4, 6, 0, 20, 0, time.UTC)
+	due := s.due(at)
+	if len(due) != 1 || due[0].ID != "a" {
+		t.Fatalf("due() = %v, want schedule a", due)
+	}
+	if got, want := s.NextRun("a"), time.Date(2026, 3, 5, 6, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("NextRun after trigger = %v, want %v", got, want)
+	}
+}
+
+func TestScheduler_PendingClearedOnStop(t *testing.T) {
+	s := newTestScheduler(t, time.Now())
+	s.pending["acc"] = "daily"
+
+	s.handleEvent(event.NewSessionStopped("acc", nil))
+
+	if _, ok := s.pending["acc"]; ok {
+		t.Error("pending script not cleared when the session stopped")
+	}
+}
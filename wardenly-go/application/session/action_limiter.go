@@ -0,0 +1,41 @@
+package session
+
+import (
+	"time"
+
+	domainscript "wardenly-go/domain/script"
+)
+
+// actionLimiter enforces a run's per-minute click and drag limits over a
+// sliding one-minute window.
+type actionLimiter struct {
+	limits domainscript.Limits
+	recent map[domainscript.ActionType][]time.Time // Oldest first
+}
+
+func newActionLimiter(limits domainscript.Limits) *actionLimiter {
+	return &actionLimiter{
+		limits: limits,
+		recent: make(map[domainscript.ActionType][]time.Time),
+	}
+}
+
+// acquire records an action of type t at now and returns zero, or returns
+// how long to wait before the action stays within its limit.
+func (l *actionLimiter) acquire(t domainscript.ActionType, now time.Time) time.Duration {
+	limit := l.limits.PerMinute(t)
+	if limit <= 0 {
+		return 0
+	}
+
+	times := l.recent[t]
+	for len(times) > 0 && now.Sub(times[0]) >= time.Minute {
+		times = times[1:]
+	}
+	if len(times) >= limit {
+		l.recent[t] = times
+		return times[0].Add(time.Minute).Sub(now)
+	}
+	l.recent[t] = append(times, now)
+	return 0
+}
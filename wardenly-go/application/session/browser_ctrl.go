@@ -2,8 +2,13 @@ package session
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"image"
 	"log/slog"
+	"math"
+	"sync/atomic"
+	"time"
 
 	"wardenly-go/infrastructure/browser"
 )
@@ -12,6 +17,20 @@ import (
 type BrowserController struct {
 	driver browser.Driver
 	logger *slog.Logger
+
+	// onRoundTrip receives the browser's response time for successful
+	// inputs and probes (optional)
+	onRoundTrip func(time.Duration)
+
+	// frameShift is added to game coordinates to reach viewport ones. It
+	// is shared with the views returned by InFrame, which are the only
+	// ones that apply it.
+	frameShift *atomic.Pointer[browser.Point]
+	inFrame    bool
+
+	// scale multiplies game coordinates before the frame shift, for
+	// viewports other than browser.BaseCanvas
+	scale browser.Point
 }
 
 // NewBrowserController creates a new browser controller.
@@ -20,9 +39,79 @@ func NewBrowserController(driver browser.Driver, logger *slog.Logger) *BrowserCo
 		logger = slog.Default()
 	}
 	return &BrowserController{
-		driver: driver,
-		logger: logger,
+		driver:     driver,
+		logger:     logger,
+		frameShift: new(atomic.Pointer[browser.Point]),
+		scale:      browser.Point{X: 1, Y: 1},
+	}
+}
+
+// InFrame returns a view of the controller whose coordinates are game
+// coordinates: inputs are scaled to the viewport and shifted by the frame
+// offset found by CalibrateFrame, and FrameScreen transforms captured
+// screens to match.
+// Scripts and scenes use it; manual input from the canvas does not.
+func (c *BrowserController) InFrame() *BrowserController {
+	view := *c
+	view.inFrame = true
+	return &view
+}
+
+// CalibrateFrame finds the element the game runs in and sets the frame
+// shift to how far its content moved from origin, where scene and script
+// coordinates were recorded, scaled like game coordinates. It returns the
+// detected position.
+func (c *BrowserController) CalibrateFrame(ctx context.Context, selector string, origin browser.Point) (browser.Point, error) {
+	pos, err := c.FrameOrigin(ctx, selector)
+	if err != nil {
+		return browser.Point{}, err
 	}
+	c.SetFrameShift(browser.Point{X: pos.X - origin.X*c.scale.X, Y: pos.Y - origin.Y*c.scale.Y})
+	return pos, nil
+}
+
+// FrameOrigin returns where the content of the element matching selector
+// starts in the viewport.
+func (c *BrowserController) FrameOrigin(ctx context.Context, selector string) (browser.Point, error) {
+	if !c.driver.IsRunning() {
+		return browser.Point{}, fmt.Errorf("browser not running")
+	}
+	return c.driver.FrameOrigin(ctx, selector)
+}
+
+// SetFrameShift sets the offset added to game coordinates.
+func (c *BrowserController) SetFrameShift(shift browser.Point) {
+	c.frameShift.Store(&shift)
+}
+
+// FrameShift returns the offset added to game coordinates.
+func (c *BrowserController) FrameShift() browser.Point {
+	if shift := c.frameShift.Load(); shift != nil {
+		return *shift
+	}
+	return browser.Point{}
+}
+
+// ToViewport converts the view's coordinates to viewport coordinates.
+func (c *BrowserController) ToViewport(x, y float64) (float64, float64) {
+	if !c.inFrame {
+		return x, y
+	}
+	shift := c.FrameShift()
+	return x*c.scale.X + shift.X, y*c.scale.Y + shift.Y
+}
+
+// FrameScreen translates a captured screen into the view's coordinates,
+// so scene points and OCR regions read the same pixels the view's inputs
+// hit. The shift is rounded to whole pixels, and a scaled screen is
+// resampled to the size of browser.BaseCanvas.
+func (c *BrowserController) FrameScreen(img image.Image) image.Image {
+	if !c.inFrame || img == nil {
+		return img
+	}
+	shift := c.FrameShift()
+	img = translateImage(img, image.Pt(int(math.Round(shift.X)), int(math.Round(shift.Y))))
+	return scaleImage(img, 1/c.scale.X, 1/c.scale.Y)
 }
 
 // Click performs a mouse click at the specified coordinates.
@@ -30,7 +119,13 @@ func (c *BrowserController) Click(ctx context.Context, x, y float64) error {
 	if !c.driver.IsRunning() {
 		return fmt.Errorf("browser not running")
 	}
-	return c.driver.Click(ctx, x, y)
+	x, y = c.ToViewport(x, y)
+	start := time.Now()
+	if err := c.driver.Click(ctx, x, y); err != nil {
+		return err
+	}
+	c.recordInput(start, 2, 0)
+	return nil
 }
 
 // Drag performs a mouse drag from one point to another.
@@ -38,7 +133,28 @@ func (c *BrowserController) Drag(ctx context.Context, fromX, fromY, toX, toY flo
 	if !c.driver.IsRunning() {
 		return fmt.Errorf("browser not running")
 	}
-	return c.driver.Drag(ctx, fromX, fromY, toX, toY)
+	fromX, fromY = c.ToViewport(fromX, fromY)
+	toX, toY = c.ToViewport(toX, toY)
+	start := time.Now()
+	if err := c.driver.Drag(ctx, fromX, fromY, toX, toY); err != nil {
+		return err
+	}
+	c.recordInput(start, browser.DragSteps+2, browser.DragPacing(browser.DragSteps))
+	return nil
+}
+
+// Scroll turns the mouse wheel at the specified coordinates.
+func (c *BrowserController) Scroll(ctx context.Context, x, y, deltaX, deltaY float64) error {
+	if !c.driver.IsRunning() {
+		return fmt.Errorf("browser not running")
+	}
+	x, y = c.ToViewport(x, y)
+	start := time.Now()
+	if err := c.driver.Scroll(ctx, x, y, deltaX, deltaY); err != nil {
+		return err
+	}
+	c.recordInput(start, 1, 0)
+	return nil
 }
 
 // DragPath performs a mouse drag along a path of points.
@@ -46,7 +162,47 @@ func (c *BrowserController) DragPath(ctx context.Context, points []browser.Point
 	if !c.driver.IsRunning() {
 		return fmt.Errorf("browser not running")
 	}
-	return c.driver.DragPath(ctx, points)
+	if c.inFrame {
+		shifted := make([]browser.Point, len(points))
+		for i, p := range points {
+			shifted[i].X, shifted[i].Y = c.ToViewport(p.X, p.Y)
+		}
+		points = shifted
+	}
+	start := time.Now()
+	if err := c.driver.DragPath(ctx, points); err != nil {
+		return err
+	}
+	c.recordInput(start, len(points)+1, browser.DragPacing(len(points)-1))
+	return nil
+}
+
+// Ping measures how long the page takes to render its next frame.
+func (c *BrowserController) Ping(ctx context.Context) (time.Duration, error) {
+	if !c.driver.IsRunning() {
+		return 0, fmt.Errorf("browser not running")
+	}
+	start := time.Now()
+	if err := c.driver.Ping(ctx); err != nil {
+		return 0, err
+	}
+	d := time.Since(start)
+	if c.onRoundTrip != nil {
+		c.onRoundTrip(d)
+	}
+	return d, nil
+}
+
+// recordInput reports the response time of an input that dispatched the
+// given number of mouse events since start, excluding the deliberate pacing
+// between drag moves. It is scaled to a two-event click so clicks and drags
+// are comparable.
+func (c *BrowserController) recordInput(start time.Time, dispatches int, pacing time.Duration) {
+	if c.onRoundTrip == nil {
+		return
+	}
+	d := max(time.Since(start)-pacing, 0)
+	c.onRoundTrip(d * 2 / time.Duration(dispatches))
 }
 
 // Refresh refreshes the current page.
@@ -97,6 +253,30 @@ func (c *BrowserController) SendKeys(ctx context.Context, selector, text string)
 	return c.driver.SendKeys(ctx, selector, text)
 }
 
+// Evaluate runs a JavaScript expression in the page.
+func (c *BrowserController) Evaluate(ctx context.Context, expression string) (json.RawMessage, error) {
+	if !c.driver.IsRunning() {
+		return nil, fmt.Errorf("browser not running")
+	}
+	return c.driver.Evaluate(ctx, expression)
+}
+
+// TypeText types text into the focused element.
+func (c *BrowserController) TypeText(ctx context.Context, text string) error {
+	if !c.driver.IsRunning() {
+		return fmt.Errorf("browser not running")
+	}
+	return c.driver.TypeText(ctx, text)
+}
+
+// KeyPress presses and releases a key.
+func (c *BrowserController) KeyPress(ctx context.Context, key string) error {
+	if !c.driver.IsRunning() {
+		return fmt.Errorf("browser not running")
+	}
+	return c.driver.KeyPress(ctx, key)
+}
+
 // ClickElement clicks on an element by selector.
 func (c *BrowserController) ClickElement(ctx context.Context, selector string) error {
 	if !c.driver.IsRunning() {
@@ -2,8 +2,11 @@ package session
 
 import (
 	"context"
+	"encoding/json"
 	"image"
+	"image/color"
 	"testing"
+	"time"
 
 	"wardenly-go/infrastructure/browser"
 )
@@ -18,6 +21,12 @@ type mockDriver struct {
 	reloadCalled   bool
 	navigateCalled bool
 	lastURL        string
+	typed          []string
+	keys           []string
+	scrolls        [][4]float64
+	frameOrigin    browser.Point
+	cookieLoginErr error
+	passwordLogins int
 }
 
 func newMockDriver() *mockDriver {
@@ -27,6 +36,7 @@ func newMockDriver() *mockDriver {
 func (m *mockDriver) Start(ctx context.Context) error { return nil }
 func (m *mockDriver) Stop() error                     { m.running = false; return nil }
 func (m *mockDriver) IsRunning() bool                 { return m.running }
+func (m *mockDriver) Done() <-chan struct{}           { return nil }
 func (m *mockDriver) Navigate(ctx context.Context, url string) error {
 	m.navigateCalled = true
 	m.lastURL = url
@@ -50,29 +60,70 @@ func (m *mockDriver) DragPath(ctx context.Context, points []browser.Point) error
 	m.dragCalled = true
 	return nil
 }
+func (m *mockDriver) Scroll(ctx context.Context, x, y, deltaX, deltaY float64) error {
+	m.scrolls = append(m.scrolls, [4]float64{x, y, deltaX, deltaY})
+	return nil
+}
 func (m *mockDriver) CaptureScreen(ctx context.Context) (image.Image, error) {
 	return image.NewRGBA(image.Rect(0, 0, 100, 100)), nil
 }
+func (m *mockDriver) CaptureRegion(ctx context.Context, rect image.Rectangle) (image.Image, error) {
+	return image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy())), nil
+}
+func (m *mockDriver) CaptureElement(ctx context.Context, selector string) (image.Image, error) {
+	return image.NewRGBA(image.Rect(0, 0, 10, 10)), nil
+}
 func (m *mockDriver) SetViewport(ctx context.Context, width, height int) error  { return nil }
+func (m *mockDriver) PreparePage(ctx context.Context) error                     { return nil }
 func (m *mockDriver) WaitVisible(ctx context.Context, selector string) error    { return nil }
 func (m *mockDriver) SendKeys(ctx context.Context, selector, text string) error { return nil }
 func (m *mockDriver) ClickElement(ctx context.Context, selector string) error   { return nil }
+func (m *mockDriver) FrameOrigin(ctx context.Context, selector string) (browser.Point, error) {
+	return m.frameOrigin, nil
+}
+func (m *mockDriver) Ping(ctx context.Context) error { return nil }
+func (m *mockDriver) Evaluate(ctx context.Context, expression string) (json.RawMessage, error) {
+	return json.RawMessage("null"), nil
+}
+func (m *mockDriver) TypeText(ctx context.Context, text string) error {
+	m.typed = append(m.typed, text)
+	return nil
+}
+func (m *mockDriver) KeyDown(ctx context.Context, key string) error {
+	m.keys = append(m.keys, "down "+key)
+	return nil
+}
+func (m *mockDriver) KeyUp(ctx context.Context, key string) error {
+	m.keys = append(m.keys, "up "+key)
+	return nil
+}
+func (m *mockDriver) KeyPress(ctx context.Context, key string) error {
+	m.keys = append(m.keys, key)
+	return nil
+}
 func (m *mockDriver) GetCookies(ctx context.Context) ([]browser.Cookie, error) {
 	return []browser.Cookie{{Name: "test", Value: "value"}}, nil
 }
 func (m *mockDriver) SetCookies(ctx context.Context, cookies []browser.Cookie) error { return nil }
-func (m *mockDriver) LoginWithPassword(url, username, password string, timeoutSeconds int) error {
+func (m *mockDriver) LoginWithPassword(ctx context.Context, url, username, password string, timeoutSeconds int) error {
+	m.passwordLogins++
 	return nil
 }
-func (m *mockDriver) LoginWithCookies(url string, cookies []browser.Cookie, timeoutSeconds int) error {
-	return nil
+func (m *mockDriver) LoginWithCookies(ctx context.Context, url string, cookies []browser.Cookie, timeoutSeconds int) error {
+	return m.cookieLoginErr
 }
 func (m *mockDriver) StartScreencast(ctx context.Context, quality, maxFPS int) (<-chan image.Image, error) {
 	ch := make(chan image.Image)
 	close(ch)
 	return ch, nil
 }
-func (m *mockDriver) StopScreencast() error { return nil }
+func (m *mockDriver) StopScreencast() error                                  { return nil }
+func (m *mockDriver) BlockURLs(ctx context.Context, patterns []string) error { return nil }
+func (m *mockDriver) WatchResponses(ctx context.Context, pattern string) (<-chan browser.Response, error) {
+	ch := make(chan browser.Response)
+	close(ch)
+	return ch, nil
+}
 func (m *mockDriver) IsScreencasting() bool { return false }
 
 func TestBrowserController_Click(t *testing.T) {
@@ -106,6 +157,33 @@ func TestBrowserController_Click_NotRunning(t *testing.T) {
 	}
 }
 
+func TestBrowserController_RoundTrip(t *testing.T) {
+	driver := newMockDriver()
+	ctrl := NewBrowserController(driver, nil)
+
+	var samples []time.Duration
+	ctrl.onRoundTrip = func(d time.Duration) { samples = append(samples, d) }
+
+	ctx := context.Background()
+	if err := ctrl.Click(ctx, 1, 2); err != nil {
+		t.Fatalf("Click() error = %v", err)
+	}
+	if _, err := ctrl.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("recorded %d round trips, want 2", len(samples))
+	}
+
+	driver.running = false
+	if _, err := ctrl.Ping(ctx); err == nil {
+		t.Error("Expected error when browser not running")
+	}
+	if len(samples) != 2 {
+		t.Error("failed operations should not be recorded")
+	}
+}
+
 func TestBrowserController_Refresh(t *testing.T) {
 	driver := newMockDriver()
 	ctrl := NewBrowserController(driver, nil)
@@ -170,3 +248,91 @@ func TestBrowserController_IsRunning(t *testing.T) {
 		t.Error("IsRunning() = true, want false")
 	}
 }
+
+func TestBrowserController_InFrame(t *testing.T) {
+	driver := newMockDriver()
+	driver.frameOrigin = browser.Point{X: 10, Y: 130}
+	ctrl := NewBrowserController(driver, nil)
+
+	// Scenes were recorded with the frame at y=100; the header grew by 30
+	pos, err := ctrl.CalibrateFrame(context.Background(), "#S_Iframe", browser.Point{X: 10, Y: 100})
+	if err != nil || pos != driver.frameOrigin {
+		t.Fatalf("CalibrateFrame() = %v, %v", pos, err)
+	}
+	frame := ctrl.InFrame()
+
+	if err := frame.Click(context.Background(), 50, 60); err != nil {
+		t.Fatalf("Click() error = %v", err)
+	}
+	if driver.lastClickX != 50 || driver.lastClickY != 90 {
+		t.Errorf("frame click at (%v, %v), want (50, 90)", driver.lastClickX, driver.lastClickY)
+	}
+	// Manual input is not shifted
+	if err := ctrl.Click(context.Background(), 50, 60); err != nil {
+		t.Fatalf("Click() error = %v", err)
+	}
+	if driver.lastClickX != 50 || driver.lastClickY != 60 {
+		t.Errorf("viewport click at (%v, %v), want (50, 60)", driver.lastClickX, driver.lastClickY)
+	}
+
+	screen := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	screen.Set(50, 90, color.RGBA{R: 255, A: 255})
+	if got := frame.FrameScreen(screen).At(50, 60); got != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("frame screen at (50, 60) = %v, want the viewport pixel at (50, 90)", got)
+	}
+	if ctrl.FrameScreen(screen) != image.Image(screen) {
+		t.Error("viewport controller should not translate screens")
+	}
+}
+
+func TestBrowserController_InFrameScaled(t *testing.T) {
+	driver := newMockDriver()
+	driver.frameOrigin = browser.Point{X: 0, Y: 140}
+	ctrl := NewBrowserController(driver, nil)
+	ctrl.scale = browser.CanvasSize{Width: 1620, Height: 1080}.Scale() // 1.5x
+
+	// The frame recorded at y=100 is expected at y=150; it was found 10 higher
+	if _, err := ctrl.CalibrateFrame(context.Background(), "#S_Iframe", browser.Point{X: 0, Y: 100}); err != nil {
+		t.Fatal(err)
+	}
+	frame := ctrl.InFrame()
+	if err := frame.Click(context.Background(), 40, 60); err != nil {
+		t.Fatalf("Click() error = %v", err)
+	}
+	if driver.lastClickX != 60 || driver.lastClickY != 80 {
+		t.Errorf("frame click at (%v, %v), want (60, 80)", driver.lastClickX, driver.lastClickY)
+	}
+
+	// The screen is resampled back to game coordinates
+	screen := image.NewRGBA(image.Rect(0, 0, 300, 300))
+	red := color.RGBA{R: 255, A: 255}
+	for y := 76; y < 85; y++ {
+		for x := 56; x < 65; x++ {
+			screen.Set(x, y, red)
+		}
+	}
+	framed := frame.FrameScreen(screen)
+	if got := framed.At(40, 60); got != red {
+		t.Errorf("frame screen at (40, 60) = %v, want the viewport pixel at (60, 80)", got)
+	}
+	if b := framed.Bounds(); b.Dx() != 200 || b.Dy() != 200 {
+		t.Errorf("frame screen bounds = %v, want 200x200", b)
+	}
+}
+
+func TestBrowserController_KeyPress(t *testing.T) {
+	driver := newMockDriver()
+	ctrl := NewBrowserController(driver, nil)
+
+	if err := ctrl.KeyPress(context.Background(), "Enter"); err != nil {
+		t.Fatalf("KeyPress() error = %v", err)
+	}
+	if len(driver.keys) != 1 || driver.keys[0] != "Enter" {
+		t.Errorf("driver keys = %v, want [Enter]", driver.keys)
+	}
+
+	driver.running = false
+	if err := ctrl.KeyPress(context.Background(), "a"); err == nil {
+		t.Error("Expected error when browser not running")
+	}
+}
@@ -0,0 +1,75 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	domainscene "wardenly-go/domain/scene"
+)
+
+// DefaultCaptchaScene is the scene that shows a captcha during login.
+const DefaultCaptchaScene = "captcha"
+
+// Actions of the captcha scene. Input focuses the answer field; Submit
+// sends the answer, or Enter is pressed if the scene has none.
+const (
+	CaptchaActionInput  = "Input"
+	CaptchaActionSubmit = "Submit"
+)
+
+// CaptchaSolver answers captchas shown while a session logs in.
+type CaptchaSolver interface {
+	// Solve returns the text to enter for the captcha shown on img, the
+	// game frame of the account's session. It blocks until the captcha is
+	// solved, ctx is done, or the solver gives up.
+	Solve(ctx context.Context, account string, img image.Image) (string, error)
+}
+
+// CaptchaConfig configures how a session gets past a captcha while it
+// waits for the game to load.
+type CaptchaConfig struct {
+	// Scene is the scene that shows the captcha (DefaultCaptchaScene if
+	// empty)
+	Scene string
+	// Solver answers the captcha; without one a captcha times out the
+	// login as before.
+	Solver CaptchaSolver
+}
+
+// scene returns the name of the captcha scene.
+func (c CaptchaConfig) scene() string {
+	if c.Scene == "" {
+		return DefaultCaptchaScene
+	}
+	return c.Scene
+}
+
+// solveCaptcha asks the solver to answer the captcha shown on img and
+// enters the answer with the scene's actions.
+func (s *Session) solveCaptcha(frame *BrowserController, img image.Image, scene *domainscene.Scene) error {
+	s.logger.Info("Captcha shown, asking solver", "scene", scene.Name)
+	answer, err := s.captcha.Solver.Solve(s.ctx, s.account.Identity(), img)
+	if err != nil {
+		return fmt.Errorf("captcha not solved: %w", err)
+	}
+
+	if input, ok := scene.Actions[CaptchaActionInput]; ok {
+		if err := frame.Click(s.ctx, input.Point.X, input.Point.Y); err != nil {
+			return fmt.Errorf("failed to focus captcha input: %w", err)
+		}
+	}
+	if err := frame.TypeText(s.ctx, answer); err != nil {
+		return fmt.Errorf("failed to enter captcha: %w", err)
+	}
+	if submit, ok := scene.Actions[CaptchaActionSubmit]; ok {
+		err = frame.Click(s.ctx, submit.Point.X, submit.Point.Y)
+	} else {
+		err = frame.KeyPress(s.ctx, "Enter")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to submit captcha: %w", err)
+	}
+	s.logger.Info("Captcha answered")
+	return nil
+}
@@ -0,0 +1,245 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"wardenly-go/core/event"
+	"wardenly-go/infrastructure/appdir"
+)
+
+// EnvDataTap names the file read by DataTapFromEnv.
+const EnvDataTap = "WARDENLY_DATA_TAP"
+
+// DataTap reads resource values, such as energy or inventory counts, from
+// the game's API responses, so scripts can use exact values instead of
+// reading them with OCR.
+type DataTap struct {
+	Responses []DataTapRule `yaml:"responses"`
+}
+
+// DataTapRule reads values from the JSON bodies of the XHR and fetch
+// responses whose URL matches a browser.MatchURL pattern.
+type DataTapRule struct {
+	URL string `yaml:"url"`
+	// Values maps script variables to dotted paths in the body, such as
+	// "data.energy" or "data.items.0.count". Numbers are truncated to
+	// integers, numeric strings are parsed and booleans are 1 or 0.
+	Values map[string]string `yaml:"values"`
+}
+
+// ParseDataTap reads a data tap from YAML and checks its rules.
+func ParseDataTap(data []byte) (*DataTap, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var tap DataTap
+	if err := dec.Decode(&tap); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse data tap: %w", err)
+	}
+	if err := tap.Validate(); err != nil {
+		return nil, err
+	}
+	return &tap, nil
+}
+
+// LoadDataTap reads a data tap file. A missing file yields nil, which
+// leaves the network alone.
+func LoadDataTap(path string) (*DataTap, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data tap: %w", err)
+	}
+	tap, err := ParseDataTap(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return tap, nil
+}
+
+// DefaultDataTapPath returns where a data tap is looked for unless
+// WARDENLY_DATA_TAP names another file.
+func DefaultDataTapPath() string {
+	return appdir.Path("data_tap.yaml")
+}
+
+// DataTapFromEnv loads the data tap named by WARDENLY_DATA_TAP, or the one
+// at DefaultDataTapPath. It returns nil if there is none.
+func DataTapFromEnv() (*DataTap, error) {
+	path := strings.TrimSpace(os.Getenv(EnvDataTap))
+	if path == "" {
+		return LoadDataTap(DefaultDataTapPath())
+	}
+	tap, err := LoadDataTap(path)
+	if err == nil && tap == nil {
+		err = fmt.Errorf("data tap %s not found", path)
+	}
+	return tap, err
+}
+
+// Validate checks that the tap has rules, each with a URL pattern and
+// values named like script variables.
+func (t *DataTap) Validate() error {
+	if len(t.Responses) == 0 {
+		return fmt.Errorf("data tap has no responses")
+	}
+	for i, rule := range t.Responses {
+		if rule.URL == "" {
+			return fmt.Errorf("response %d: url is required", i+1)
+		}
+		if len(rule.Values) == 0 {
+			return fmt.Errorf("response %d (%s): values are required", i+1, rule.URL)
+		}
+		for name, path := range rule.Values {
+			if !isVariable(name) {
+				return fmt.Errorf("response %d (%s): %q is not a variable name", i+1, rule.URL, name)
+			}
+			if path == "" {
+				return fmt.Errorf("response %d (%s): %s has no path", i+1, rule.URL, name)
+			}
+		}
+	}
+	return nil
+}
+
+// Extract returns the rule's values found in a JSON body. Values whose
+// path is missing or not a number are left out.
+func (r DataTapRule) Extract(body []byte) map[string]int {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil
+	}
+	values := make(map[string]int, len(r.Values))
+	for name, path := range r.Values {
+		if v, ok := jsonInt(lookupJSON(doc, path)); ok {
+			values[name] = v
+		}
+	}
+	return values
+}
+
+// lookupJSON follows a dotted path of object keys and array indexes.
+func lookupJSON(doc any, path string) any {
+	for _, key := range strings.Split(path, ".") {
+		switch node := doc.(type) {
+		case map[string]any:
+			doc = node[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			doc = node[i]
+		default:
+			return nil
+		}
+	}
+	return doc
+}
+
+// rawJSONInt converts a JSON number, numeric string or boolean to an int.
+func rawJSONInt(raw json.RawMessage) (int, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return 0, false
+	}
+	return jsonInt(v)
+}
+
+func jsonInt(v any) (int, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int(i), true
+		}
+		f, err := v.Float64()
+		return int(f), err == nil
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		return i, err == nil
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// isVariable reports whether name can be used in script expressions.
+func isVariable(name string) bool {
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// tapGameData watches the responses of the data tap's rules until the
+// browser exits. It is called each time the browser starts.
+func (s *Session) tapGameData() {
+	if s.dataTap == nil {
+		return
+	}
+	for _, rule := range s.dataTap.Responses {
+		responses, err := s.driver.WatchResponses(s.ctx, rule.URL)
+		if err != nil {
+			s.logger.Warn("Failed to tap game data", "url", rule.URL, "error", err)
+			continue
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for resp := range responses {
+				values := rule.Extract(resp.Body)
+				if len(values) == 0 {
+					s.logger.Debug("Tapped response has no values", "url", resp.URL, "status", resp.Status)
+					continue
+				}
+				s.recordGameData(resp.URL, values)
+			}
+		}()
+	}
+}
+
+// recordGameData keeps values captured from url, publishes them and
+// passes them to a running script.
+func (s *Session) recordGameData(url string, values map[string]int) {
+	s.gameDataMu.Lock()
+	if s.gameData == nil {
+		s.gameData = make(map[string]int)
+	}
+	maps.Copy(s.gameData, values)
+	s.gameDataMu.Unlock()
+
+	s.logger.Debug("Game data captured", "url", url, "values", values)
+	s.publishEvent(event.NewGameDataCaptured(s.id, url, values))
+	s.scriptRunner.mergeGameData(values)
+}
+
+// GameData returns a copy of the latest values captured by the data tap.
+func (s *Session) GameData() map[string]int {
+	s.gameDataMu.Lock()
+	defer s.gameDataMu.Unlock()
+	if s.gameData == nil {
+		return make(map[string]int)
+	}
+	return maps.Clone(s.gameData)
+}
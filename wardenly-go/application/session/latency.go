@@ -0,0 +1,116 @@
+package session
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// LagThreshold is the mean of the recent round trips at which a session
+	// is considered lagging. It recovers once the mean drops below half.
+	LagThreshold = 500 * time.Millisecond
+
+	// latencyWindow is the number of round trips the statistics cover.
+	latencyWindow = 50
+
+	// latencyRecent is the number of latest round trips lag detection averages.
+	latencyRecent = 5
+
+	// latencyProbeInterval is the pause between page responsiveness probes.
+	latencyProbeInterval = 10 * time.Second
+)
+
+// LatencyStats summarizes a session's recent browser round-trip times.
+type LatencyStats struct {
+	Samples int
+	Mean    time.Duration
+	P95     time.Duration
+	Max     time.Duration
+	// Lagging is true while the recent round trips are above LagThreshold.
+	Lagging bool
+}
+
+// LatencyTracker keeps a rolling window of round-trip times for input
+// dispatches and responsiveness probes, and detects lag spikes.
+type LatencyTracker struct {
+	mu        sync.Mutex
+	threshold time.Duration
+	samples   []time.Duration // Ring buffer of at most latencyWindow samples
+	next      int             // Index of the oldest sample once the ring is full
+	lagging   bool
+}
+
+// NewLatencyTracker creates a tracker that flags lag at threshold.
+func NewLatencyTracker(threshold time.Duration) *LatencyTracker {
+	if threshold <= 0 {
+		threshold = LagThreshold
+	}
+	return &LatencyTracker{threshold: threshold}
+}
+
+// Record adds a round-trip time and reports whether the lagging flag
+// changed. Lag starts when the mean of the latest samples reaches the
+// threshold and ends when it falls below half of it, so a session near
+// the threshold does not flap.
+func (t *LatencyTracker) Record(d time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.samples) < latencyWindow {
+		t.samples = append(t.samples, d)
+	} else {
+		t.samples[t.next] = d
+		t.next = (t.next + 1) % latencyWindow
+	}
+
+	recent := t.recentMean()
+	wasLagging := t.lagging
+	switch {
+	case !t.lagging && recent >= t.threshold:
+		t.lagging = true
+	case t.lagging && recent < t.threshold/2:
+		t.lagging = false
+	}
+	return t.lagging != wasLagging
+}
+
+// recentMean returns the mean of the latest samples. Callers hold mu.
+func (t *LatencyTracker) recentMean() time.Duration {
+	n := min(latencyRecent, len(t.samples))
+	var sum time.Duration
+	for i := 1; i <= n; i++ {
+		// The newest sample sits just before next (next is 0 until the ring fills)
+		sum += t.samples[(t.next-i+len(t.samples))%len(t.samples)]
+	}
+	return sum / time.Duration(n)
+}
+
+// Lagging returns true while the session is lagging.
+func (t *LatencyTracker) Lagging() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lagging
+}
+
+// Stats returns statistics over the rolling window.
+func (t *LatencyTracker) Stats() LatencyStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := LatencyStats{Samples: len(t.samples), Lagging: t.lagging}
+	if len(t.samples) == 0 {
+		return stats
+	}
+
+	sorted := slices.Clone(t.samples)
+	slices.Sort(sorted)
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	stats.Mean = sum / time.Duration(len(sorted))
+	stats.P95 = sorted[(len(sorted)*95-1)/100]
+	stats.Max = sorted[len(sorted)-1]
+	return stats
+}
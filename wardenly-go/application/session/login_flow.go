@@ -0,0 +1,180 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"wardenly-go/infrastructure/appdir"
+	"wardenly-go/infrastructure/browser"
+)
+
+// EnvLoginFlow names the file read by LoginFlowFromEnv.
+const EnvLoginFlow = "WARDENLY_LOGIN_FLOW"
+
+// Login flow step types.
+const (
+	LoginStepNavigate    = "navigate"
+	LoginStepWaitVisible = "waitVisible"
+	LoginStepSendKeys    = "sendKeys"
+	LoginStepClick       = "click"
+)
+
+// Placeholders replaced in a step's URL and text.
+const (
+	placeholderURL      = "{url}"
+	placeholderUsername = "{username}"
+	placeholderPassword = "{password}"
+)
+
+// LoginFlow is a password login defined as data, so a portal change can be
+// followed by editing a file instead of releasing a build. The session runs
+// its steps through the driver's generic page operations.
+type LoginFlow struct {
+	Steps []LoginStep `yaml:"steps"`
+}
+
+// LoginStep is one operation of a login flow. Selectors are CSS selectors;
+// "{url}", "{username}" and "{password}" in URL and Text are replaced by
+// the login page and the account's credentials.
+type LoginStep struct {
+	// Type is navigate, waitVisible, sendKeys or click
+	Type     string `yaml:"type"`
+	URL      string `yaml:"url,omitempty"`
+	Selector string `yaml:"selector,omitempty"`
+	Text     string `yaml:"text,omitempty"`
+}
+
+// DefaultLoginFlow returns the flow the drivers' built-in password login
+// runs with the given login profile.
+func DefaultLoginFlow(profile browser.LoginProfile) *LoginFlow {
+	return &LoginFlow{Steps: []LoginStep{
+		{Type: LoginStepNavigate, URL: placeholderURL},
+		{Type: LoginStepWaitVisible, Selector: profile.Username},
+		{Type: LoginStepSendKeys, Selector: profile.Username, Text: placeholderUsername},
+		{Type: LoginStepSendKeys, Selector: profile.Password, Text: placeholderPassword},
+		{Type: LoginStepClick, Selector: profile.Submit},
+		{Type: LoginStepWaitVisible, Selector: profile.Ready},
+	}}
+}
+
+// ParseLoginFlow reads a login flow from YAML and checks its steps.
+func ParseLoginFlow(data []byte) (*LoginFlow, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var flow LoginFlow
+	if err := dec.Decode(&flow); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse login flow: %w", err)
+	}
+	if err := flow.Validate(); err != nil {
+		return nil, err
+	}
+	return &flow, nil
+}
+
+// LoadLoginFlow reads a login flow file. A missing file yields nil, which
+// keeps the driver's built-in login.
+func LoadLoginFlow(path string) (*LoginFlow, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read login flow: %w", err)
+	}
+	flow, err := ParseLoginFlow(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return flow, nil
+}
+
+// DefaultLoginFlowPath returns where a login flow is looked for unless
+// WARDENLY_LOGIN_FLOW names another file.
+func DefaultLoginFlowPath() string {
+	return appdir.Path("login_flow.yaml")
+}
+
+// LoginFlowFromEnv loads the login flow named by WARDENLY_LOGIN_FLOW, or
+// the one at DefaultLoginFlowPath. It returns nil if there is none.
+func LoginFlowFromEnv() (*LoginFlow, error) {
+	path := strings.TrimSpace(os.Getenv(EnvLoginFlow))
+	if path == "" {
+		return LoadLoginFlow(DefaultLoginFlowPath())
+	}
+	flow, err := LoadLoginFlow(path)
+	if err == nil && flow == nil {
+		err = fmt.Errorf("login flow %s not found", path)
+	}
+	return flow, err
+}
+
+// Validate checks that the flow has steps and that each step has the
+// fields its type needs.
+func (f *LoginFlow) Validate() error {
+	if len(f.Steps) == 0 {
+		return fmt.Errorf("login flow has no steps")
+	}
+	for i, step := range f.Steps {
+		var missing string
+		switch step.Type {
+		case LoginStepNavigate:
+			if step.URL == "" {
+				missing = "url"
+			}
+		case LoginStepWaitVisible, LoginStepSendKeys, LoginStepClick:
+			if step.Selector == "" {
+				missing = "selector"
+			}
+		default:
+			return fmt.Errorf("step %d: unknown type %q", i+1, step.Type)
+		}
+		if missing != "" {
+			return fmt.Errorf("step %d (%s): %s is required", i+1, step.Type, missing)
+		}
+	}
+	return nil
+}
+
+// run performs the steps on driver within timeout. The page is prepared
+// as the built-in logins do before the first step.
+func (f *LoginFlow) run(ctx context.Context, driver browser.Driver, url, username, password string, timeout time.Duration) error {
+	if err := driver.PreparePage(ctx); err != nil {
+		return fmt.Errorf("start page failure: %w", err)
+	}
+
+	loginCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	expand := strings.NewReplacer(placeholderURL, url, placeholderUsername, username, placeholderPassword, password).Replace
+	for i, step := range f.Steps {
+		var err error
+		switch step.Type {
+		case LoginStepNavigate:
+			err = driver.Navigate(loginCtx, expand(step.URL))
+		case LoginStepWaitVisible:
+			err = driver.WaitVisible(loginCtx, step.Selector)
+		case LoginStepSendKeys:
+			err = driver.SendKeys(loginCtx, step.Selector, expand(step.Text))
+		case LoginStepClick:
+			err = driver.ClickElement(loginCtx, step.Selector)
+		default:
+			err = fmt.Errorf("unknown type %q", step.Type)
+		}
+		if err == nil {
+			continue
+		}
+		if ctx.Err() == nil && loginCtx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("login timeout after %s at step %d (%s) (server may be down or in maintenance)", timeout, i+1, step.Type)
+		}
+		return fmt.Errorf("login failure at step %d (%s): %w", i+1, step.Type, err)
+	}
+	return nil
+}
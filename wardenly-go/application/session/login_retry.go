@@ -0,0 +1,30 @@
+package session
+
+import (
+	"time"
+)
+
+// Login retry defaults.
+const (
+	DefaultLoginAttempts      = 3
+	DefaultLoginRetryDelay    = 5 * time.Second
+	DefaultLoginRetryMaxDelay = time.Minute
+)
+
+// LoginRetryConfig configures how often a failed login is tried again
+// before the session is left in Ready for manual login. A failed cookie
+// login is retried with the password when the account has one.
+type LoginRetryConfig struct {
+	// MaxAttempts is how many times a login is tried in all; zero or one
+	// tries once.
+	MaxAttempts int
+	// Delay is the wait before the first retry; it doubles after every
+	// failed retry up to MaxDelay.
+	Delay    time.Duration
+	MaxDelay time.Duration
+}
+
+// backoff returns the wait before the given retry, counting from 1.
+func (c LoginRetryConfig) backoff(retry int) time.Duration {
+	return backoff(c.Delay, c.MaxDelay, retry)
+}
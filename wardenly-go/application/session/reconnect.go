@@ -0,0 +1,49 @@
+package session
+
+import (
+	"errors"
+	"time"
+)
+
+// Reconnect defaults.
+const (
+	DefaultReconnectAttempts = 5
+	DefaultReconnectDelay    = 5 * time.Second
+	DefaultReconnectMaxDelay = 2 * time.Minute
+)
+
+// errBrowserExited is the cause reported when the browser exits on its own.
+var errBrowserExited = errors.New("browser exited unexpectedly")
+
+// ReconnectConfig configures how a session recovers when its browser
+// crashes: the browser is restarted and logged in again, waiting longer
+// after each failed attempt.
+type ReconnectConfig struct {
+	// MaxAttempts is how many restarts are tried in a row before the
+	// session stops; zero disables reconnecting.
+	MaxAttempts int
+	// Delay is the wait before the first attempt; it doubles after every
+	// failed attempt up to MaxDelay.
+	Delay    time.Duration
+	MaxDelay time.Duration
+}
+
+// backoff returns the wait before the given attempt, counting from 1.
+func (c ReconnectConfig) backoff(attempt int) time.Duration {
+	return backoff(c.Delay, c.MaxDelay, attempt)
+}
+
+// backoff returns the wait before the given attempt, counting from 1:
+// delay, doubled after every attempt up to maxDelay (unbounded if zero).
+func backoff(delay, maxDelay time.Duration, attempt int) time.Duration {
+	for i := 1; i < attempt; i++ {
+		if maxDelay > 0 && delay >= maxDelay {
+			break
+		}
+		delay *= 2
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
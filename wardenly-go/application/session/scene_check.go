@@ -0,0 +1,113 @@
+package session
+
+import (
+	"cmp"
+	"image"
+	"slices"
+
+	"wardenly-go/infrastructure/browser"
+)
+
+// SceneCheck is how one scene compares against a frame. Positions are in
+// viewport coordinates, so they can be drawn over the captured frame.
+type SceneCheck struct {
+	Name    string
+	Matched bool
+	// AvgDiff and Threshold are the point scene's average diff and the
+	// threshold it was held against
+	AvgDiff   float64
+	Threshold float64
+	Points    []PointMark
+	// Template is set for template scenes
+	Template *TemplateMark
+}
+
+// PointMark is one scene point and how far the frame is from its color.
+type PointMark struct {
+	X, Y    float64
+	Diff    float64
+	Matched bool
+}
+
+// TemplateMark is the best placement of a scene's template.
+type TemplateMark struct {
+	Score     float64
+	Threshold float64
+	// Min and Max are the corners of the placement
+	Min, Max browser.Point
+}
+
+// Distance returns how far the scene is from matching, relative to its
+// threshold: at most 1 when it matches.
+func (c *SceneCheck) Distance() float64 {
+	if c.Template != nil {
+		if c.Template.Threshold >= 1 {
+			return 1 - c.Template.Score
+		}
+		return (1 - c.Template.Score) / (1 - c.Template.Threshold)
+	}
+	if c.Threshold <= 0 {
+		return c.AvgDiff
+	}
+	return c.AvgDiff / c.Threshold
+}
+
+// CheckScenes matches every registered scene against a captured frame,
+// the same way scripts see it, and returns the results with matched
+// scenes first, then by distance. Scenes with nothing to match are left
+// out.
+func (s *Session) CheckScenes(frame image.Image) []SceneCheck {
+	if s.sceneRegistry == nil || frame == nil {
+		return nil
+	}
+	ctrl := s.browserCtrl.InFrame()
+	screen := ctrl.FrameScreen(frame)
+	matcher := s.GetSceneMatcher()
+
+	var checks []SceneCheck
+	for _, scene := range s.sceneRegistry.All() {
+		if scene.Template == nil && len(scene.Points) == 0 {
+			continue
+		}
+		result := matcher.MatchWithDetails(scene, screen)
+		check := SceneCheck{
+			Name:      scene.Name,
+			Matched:   result.Matched,
+			AvgDiff:   result.AvgDiff,
+			Threshold: result.Threshold,
+		}
+		if scene.Template != nil {
+			bounds := scene.Template.Bounds()
+			if result.Template != nil {
+				bounds = bounds.Add(result.Template.Offset)
+			}
+			mark := &TemplateMark{Score: -1, Threshold: result.Threshold}
+			if result.Template != nil {
+				mark.Score = result.Template.Score
+			}
+			mark.Min.X, mark.Min.Y = ctrl.ToViewport(float64(bounds.Min.X), float64(bounds.Min.Y))
+			mark.Max.X, mark.Max.Y = ctrl.ToViewport(float64(bounds.Max.X), float64(bounds.Max.Y))
+			check.Template = mark
+		} else {
+			check.Points = make([]PointMark, len(scene.Points))
+			for i := range scene.Points {
+				at := scene.At(i, result.Offset)
+				x, y := ctrl.ToViewport(float64(at.X), float64(at.Y))
+				diff := result.PointDiffs[i]
+				check.Points[i] = PointMark{X: x, Y: y, Diff: diff, Matched: diff <= result.Threshold}
+			}
+		}
+		checks = append(checks, check)
+	}
+
+	slices.SortFunc(checks, func(a, b SceneCheck) int {
+		if a.Matched != b.Matched {
+			if a.Matched {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(cmp.Compare(a.Distance(), b.Distance()), cmp.Compare(a.Name, b.Name))
+	})
+	return checks
+}
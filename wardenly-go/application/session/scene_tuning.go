@@ -0,0 +1,8 @@
+package session
+
+// Defaults for scene threshold tuning.
+const (
+	DefaultSceneNearMissMargin   = 1.0
+	DefaultSceneNearMissCount    = 20
+	DefaultSceneNearMissSessions = 2
+)
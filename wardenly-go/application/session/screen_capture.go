@@ -4,20 +4,41 @@ import (
 	"context"
 	"fmt"
 	"image"
+	"image/color"
+	"image/draw"
 	"image/png"
 	"log/slog"
+	"math"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
+	xdraw "golang.org/x/image/draw"
+
 	"wardenly-go/infrastructure/browser"
 )
 
+// Recent frames kept for re-validating edited scenes: frameHistorySize
+// frames at least frameHistoryInterval apart, so a few seconds of
+// screencast don't fill the history with the same screen.
+const (
+	frameHistorySize     = 10
+	frameHistoryInterval = 2 * time.Second
+)
+
 // ScreenCapture handles screen capture operations for a session.
 type ScreenCapture struct {
 	driver  browser.Driver
 	logger  *slog.Logger
 	saveDir string
+
+	// Newest frame and when it was recorded, and the recent frames, oldest first
+	frameMu   sync.Mutex
+	last      image.Image
+	lastAt    time.Time
+	history   []image.Image
+	historyAt time.Time
 }
 
 // NewScreenCapture creates a new screen capture service.
@@ -28,12 +49,12 @@ func NewScreenCapture(driver browser.Driver, logger *slog.Logger) *ScreenCapture
 	return &ScreenCapture{
 		driver:  driver,
 		logger:  logger,
-		saveDir: getDefaultSaveDir(),
+		saveDir: DefaultSaveDir(),
 	}
 }
 
-// getDefaultSaveDir returns the default directory for saving screenshots.
-func getDefaultSaveDir() string {
+// DefaultSaveDir returns the default directory for saving screenshots.
+func DefaultSaveDir() string {
 	// Try to use user's Pictures folder
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -52,7 +73,58 @@ func (s *ScreenCapture) Capture(ctx context.Context) (image.Image, error) {
 	if !s.driver.IsRunning() {
 		return nil, fmt.Errorf("browser not running")
 	}
-	return s.driver.CaptureScreen(ctx)
+	img, err := s.driver.CaptureScreen(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.Record(img)
+	return img, nil
+}
+
+// Record keeps a frame as the newest one and adds it to the recent frames
+// if the last one added is old enough.
+// Frames from screencast are recorded here as well as direct captures.
+func (s *ScreenCapture) Record(img image.Image) {
+	if img == nil {
+		return
+	}
+
+	s.frameMu.Lock()
+	defer s.frameMu.Unlock()
+
+	now := time.Now()
+	s.last = img
+	s.lastAt = now
+	if now.Sub(s.historyAt) < frameHistoryInterval {
+		return
+	}
+	if len(s.history) >= frameHistorySize {
+		copy(s.history, s.history[1:])
+		s.history = s.history[:len(s.history)-1]
+	}
+	s.history = append(s.history, img)
+	s.historyAt = now
+}
+
+// LastFrame returns the newest recorded frame and when it was recorded,
+// or nil if no frame has been recorded yet.
+func (s *ScreenCapture) LastFrame() (image.Image, time.Time) {
+	s.frameMu.Lock()
+	defer s.frameMu.Unlock()
+
+	return s.last, s.lastAt
+}
+
+// RecentFrames returns the recent frames, newest first.
+func (s *ScreenCapture) RecentFrames() []image.Image {
+	s.frameMu.Lock()
+	defer s.frameMu.Unlock()
+
+	frames := make([]image.Image, len(s.history))
+	for i, img := range s.history {
+		frames[len(s.history)-1-i] = img
+	}
+	return frames
 }
 
 // CaptureAndSave captures the screen and saves it to a file.
@@ -114,3 +186,106 @@ func (s *ScreenCapture) CropImage(img image.Image, x, y, width, height int) (ima
 	rect := image.Rect(x, y, x+width, y+height)
 	return subImager.SubImage(rect), nil
 }
+
+// translateImage returns img with its coordinates moved by -offset, so the
+// pixel at offset becomes (0, 0). RGBA images share their pixels; others
+// are copied.
+func translateImage(img image.Image, offset image.Point) image.Image {
+	if offset == (image.Point{}) {
+		return img
+	}
+	rgba, ok := img.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(img.Bounds())
+		draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	}
+	return &image.RGBA{Pix: rgba.Pix, Stride: rgba.Stride, Rect: rgba.Rect.Sub(offset)}
+}
+
+// scaleImage resamples img by the given factors, scaling its bounds
+// (including their origin) so coordinates scale the same way.
+func scaleImage(img image.Image, sx, sy float64) image.Image {
+	if sx == 1 && sy == 1 {
+		return img
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(
+		int(math.Round(float64(b.Min.X)*sx)), int(math.Round(float64(b.Min.Y)*sy)),
+		int(math.Round(float64(b.Max.X)*sx)), int(math.Round(float64(b.Max.Y)*sy)),
+	))
+	xdraw.ApproxBiLinear.Scale(dst, dst.Rect, img, b, xdraw.Src, nil)
+	return dst
+}
+
+// ScreenHash returns a 64-bit difference hash of img as 16 hex digits.
+// Screens that look alike hash alike, so a trace shows when a script keeps
+// acting on the same screen.
+func ScreenHash(img image.Image) string {
+	const cols, rows = 9, 8
+
+	// Average luminance of a 9x8 grid of cells
+	b := img.Bounds()
+	if b.Dx() < cols || b.Dy() < rows {
+		return ""
+	}
+	var grid [rows][cols]uint64
+	for cy := 0; cy < rows; cy++ {
+		y0, y1 := b.Min.Y+cy*b.Dy()/rows, b.Min.Y+(cy+1)*b.Dy()/rows
+		for cx := 0; cx < cols; cx++ {
+			x0, x1 := b.Min.X+cx*b.Dx()/cols, b.Min.X+(cx+1)*b.Dx()/cols
+			var sum, n uint64
+			// Sample every 4th pixel; the cells are large
+			for y := y0; y < y1; y += 4 {
+				for x := x0; x < x1; x += 4 {
+					sum += luminance(img.At(x, y))
+					n++
+				}
+			}
+			grid[cy][cx] = sum / n
+		}
+	}
+
+	var hash uint64
+	for cy := 0; cy < rows; cy++ {
+		for cx := 0; cx < cols-1; cx++ {
+			hash <<= 1
+			if grid[cy][cx] < grid[cy][cx+1] {
+				hash |= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%016x", hash)
+}
+
+// FrameDelta returns how much two captures differ: the mean absolute
+// difference of their luminance, from 0 for identical frames to 255, over
+// every 4th pixel of every 4th row. Frames of different sizes differ by
+// 255.
+func FrameDelta(a, b image.Image) float64 {
+	ab, bb := a.Bounds(), b.Bounds()
+	if ab.Size() != bb.Size() {
+		return 255
+	}
+	var sum, n uint64
+	for y := 0; y < ab.Dy(); y += 4 {
+		for x := 0; x < ab.Dx(); x += 4 {
+			la, lb := luminance(a.At(ab.Min.X+x, ab.Min.Y+y)), luminance(b.At(bb.Min.X+x, bb.Min.Y+y))
+			if la > lb {
+				sum += la - lb
+			} else {
+				sum += lb - la
+			}
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return float64(sum) / float64(n) / 0x101
+}
+
+// luminance returns the 16-bit luminance of c.
+func luminance(c color.Color) uint64 {
+	r, g, b, _ := c.RGBA()
+	return (299*uint64(r) + 587*uint64(g) + 114*uint64(b)) / 1000
+}
@@ -3,7 +3,10 @@ package session
 import (
 	"context"
 	"image"
+	"image/color"
+	"image/draw"
 	"testing"
+	"time"
 )
 
 func TestScreenCapture_Capture(t *testing.T) {
@@ -66,3 +69,131 @@ func TestScreenCapture_CropImage(t *testing.T) {
 		t.Errorf("Cropped size = %dx%d, want 200x200", bounds.Dx(), bounds.Dy())
 	}
 }
+
+func TestTranslateImage(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	rgba.Set(12, 25, red)
+	moved := translateImage(rgba, image.Pt(2, 5))
+	if moved.Bounds() != image.Rect(-2, -5, 38, 25) || moved.At(10, 20) != red {
+		t.Errorf("RGBA: bounds %v, at(10, 20) = %v", moved.Bounds(), moved.At(10, 20))
+	}
+	if &moved.(*image.RGBA).Pix[0] != &rgba.Pix[0] {
+		t.Error("RGBA pixels should be shared, not copied")
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	nrgba.Set(12, 25, red)
+	if got := translateImage(nrgba, image.Pt(2, 5)).At(10, 20); got != red {
+		t.Errorf("NRGBA: at(10, 20) = %v, want red", got)
+	}
+
+	if translateImage(nrgba, image.Point{}) != image.Image(nrgba) {
+		t.Error("zero offset should return the image unchanged")
+	}
+}
+
+func TestScreenCapture_RecentFrames(t *testing.T) {
+	cap := NewScreenCapture(newMockDriver(), nil)
+
+	if frames := cap.RecentFrames(); len(frames) != 0 {
+		t.Errorf("RecentFrames() returned %d frames, want 0", len(frames))
+	}
+
+	var last image.Image
+	for i := 0; i < frameHistorySize+3; i++ {
+		last = image.NewRGBA(image.Rect(0, 0, i+1, i+1))
+		cap.Record(last)
+		cap.historyAt = time.Time{} // As if the interval had passed
+	}
+	cap.Record(nil)
+
+	frames := cap.RecentFrames()
+	if len(frames) != frameHistorySize {
+		t.Fatalf("RecentFrames() returned %d frames, want %d", len(frames), frameHistorySize)
+	}
+	if frames[0] != last {
+		t.Error("RecentFrames() should return newest frame first")
+	}
+
+	// Frames within the interval only replace the last frame
+	cap.Record(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	cap.Record(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	if frames := cap.RecentFrames(); frames[0] == last || frames[1] != last {
+		t.Error("RecentFrames() should hold one frame per interval")
+	}
+}
+
+func TestScreenCapture_LastFrame(t *testing.T) {
+	cap := NewScreenCapture(newMockDriver(), nil)
+
+	if img, at := cap.LastFrame(); img != nil || !at.IsZero() {
+		t.Error("LastFrame() should be empty before any frame is recorded")
+	}
+
+	before := time.Now()
+	first := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	last := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	cap.Record(first)
+	cap.Record(last)
+	cap.Record(nil)
+
+	img, at := cap.LastFrame()
+	if img != last {
+		t.Error("LastFrame() should return the newest frame")
+	}
+	if at.Before(before) {
+		t.Errorf("LastFrame() time = %v, want at or after %v", at, before)
+	}
+}
+
+func TestScreenHash(t *testing.T) {
+	gradient := func(shift int) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, 90, 80))
+		for y := 0; y < 80; y++ {
+			for x := 0; x < 90; x++ {
+				img.Set(x, y, color.Gray{Y: uint8((x*2 + shift) % 256)})
+			}
+		}
+		return img
+	}
+
+	// Brightness rising left to right sets every bit
+	if got := ScreenHash(gradient(0)); got != "ffffffffffffffff" {
+		t.Errorf("ScreenHash(gradient) = %q", got)
+	}
+	if ScreenHash(gradient(0)) != ScreenHash(gradient(1)) {
+		t.Error("nearly identical screens hash differently")
+	}
+	if got := ScreenHash(image.NewRGBA(image.Rect(0, 0, 90, 80))); got != "0000000000000000" {
+		t.Errorf("ScreenHash(blank) = %q", got)
+	}
+	if got := ScreenHash(image.NewRGBA(image.Rect(0, 0, 4, 4))); got != "" {
+		t.Errorf("ScreenHash(tiny) = %q, want empty", got)
+	}
+}
+
+func TestFrameDelta(t *testing.T) {
+	frame := func(gray uint8) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+		draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: gray}), image.Point{}, draw.Src)
+		return img
+	}
+
+	if got := FrameDelta(frame(100), frame(100)); got != 0 {
+		t.Errorf("FrameDelta(same) = %v, want 0", got)
+	}
+	if got := FrameDelta(frame(100), frame(110)); got < 9.9 || got > 10.1 {
+		t.Errorf("FrameDelta(10 brighter) = %v, want 10", got)
+	}
+	if got := FrameDelta(frame(0), image.NewRGBA(image.Rect(0, 0, 20, 20))); got != 255 {
+		t.Errorf("FrameDelta(other size) = %v, want 255", got)
+	}
+
+	// Sub-images are compared from their own corners
+	moved := frame(100).SubImage(image.Rect(8, 8, 40, 40))
+	if got := FrameDelta(moved, frame(100).SubImage(image.Rect(0, 0, 32, 32))); got != 0 {
+		t.Errorf("FrameDelta(sub-images) = %v, want 0", got)
+	}
+}
@@ -1,15 +1,21 @@
 package session
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"image"
 	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"wardenly-go/core/event"
+	domainscene "wardenly-go/domain/scene"
 	domainscript "wardenly-go/domain/script"
 	"wardenly-go/infrastructure/ocr"
 )
@@ -22,9 +28,50 @@ type ScriptRunner struct {
 	// Execution state
 	running   atomic.Bool
 	script    *domainscript.Script
+	params    map[string]string
 	counters  map[string]int
 	counterMu sync.Mutex
 
+	// correlationID is that of the command that started the run; the
+	// run's events carry it
+	correlationID string
+
+	// calls holds the names of the scripts being run by call actions,
+	// outermost first
+	calls []string
+
+	// deadline is the earliest Timeout of the running and called scripts
+	// (nil without one); failure is why a stepResultFailed stopped the run
+	deadline *runDeadline
+	failure  error
+
+	// watchdog notices runs that match no scene for too long
+	watchdog *watchdog
+	// limiter holds back clicks and drags beyond the script's Limits;
+	// called scripts share the caller's
+	limiter *actionLimiter
+
+	// resume is non-nil while the run is paused and closed to resume it
+	resume  chan struct{}
+	pauseMu sync.Mutex
+	// paused is how long the run has been paused or halted; only the run
+	// goroutine touches it
+	paused time.Duration
+
+	// debug halts the run before each action until Step sends on step;
+	// halted is set while it waits
+	debug  atomic.Bool
+	halted atomic.Bool
+	step   chan struct{}
+
+	// scene is the scene that matched the step being run, which a step
+	// with a SceneCategory doesn't name
+	scene string
+
+	// ocrROIs caches the winning candidate ROI index per OCR rule.
+	// It outlives individual runs so a session keeps its known-good ROI.
+	ocrROIs map[string]int
+
 	// Control
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -40,25 +87,44 @@ func NewScriptRunner(session *Session, logger *slog.Logger) *ScriptRunner {
 		session:  session,
 		logger:   logger,
 		counters: make(map[string]int),
+		ocrROIs:  make(map[string]int),
+		limiter:  newActionLimiter(domainscript.Limits{}),
 	}
 }
 
-// Start begins executing the specified script.
-func (r *ScriptRunner) Start(script *domainscript.Script) {
+// Start begins executing the specified script for the command with
+// correlationID. Integer params seed the counters so conditions can
+// compare against them, over the latest values of the data tap; all
+// params are kept for send_keys text. With
+// debug, the run halts before each action.
+func (r *ScriptRunner) Start(script *domainscript.Script, params map[string]string, correlationID string, debug bool) {
 	if r.running.Load() {
 		r.logger.Warn("Script already running")
 		return
 	}
 
 	r.script = script
-	r.counters = make(map[string]int)
+	r.params = params
+	r.correlationID = correlationID
+	counters := r.session.GameData()
+	maps.Copy(counters, script.IntParams(params))
+	r.counterMu.Lock()
+	r.counters = counters
+	r.counterMu.Unlock()
+	r.deadline = nil
+	r.failure = nil
+	r.paused = 0
+	r.debug.Store(debug)
+	r.step = make(chan struct{}, 1)
+	r.watchdog = newWatchdog(r.session.watchdog, r.clock())
+	r.limiter = newActionLimiter(script.Limits)
 	r.running.Store(true)
 	r.ctx, r.cancel = context.WithCancel(r.session.Context())
 
 	r.wg.Add(1)
 	go r.run()
 
-	r.logger.Info("Script started", "name", script.Name)
+	r.logger.Info("Script started", "name", script.Name, "params", params, "debug", debug, "correlation_id", correlationID)
 }
 
 // Stop signals the script to stop.
@@ -77,14 +143,186 @@ func (r *ScriptRunner) Stop() {
 		close(done)
 	}()
 
+	timeout := 2 * time.Second
+	if r.script != nil && len(r.script.OnStop) > 0 {
+		timeout += onStopTimeout
+	}
 	select {
 	case <-done:
-	case <-time.After(2 * time.Second):
+	case <-time.After(timeout):
 		r.logger.Warn("Script stop timeout")
 	}
 	r.logger.Info("Script stopped")
 }
 
+// Pause suspends the run before its next action or scene check. It
+// returns false if no script is running or it is already paused.
+func (r *ScriptRunner) Pause() bool {
+	if !r.running.Load() {
+		return false
+	}
+	r.pauseMu.Lock()
+	defer r.pauseMu.Unlock()
+	if r.resume != nil {
+		return false
+	}
+	r.resume = make(chan struct{})
+	r.logger.Info("Script paused", "name", r.script.Name)
+	return true
+}
+
+// Resume continues a paused run. It returns false if the run is not paused.
+func (r *ScriptRunner) Resume() bool {
+	r.pauseMu.Lock()
+	defer r.pauseMu.Unlock()
+	if r.resume == nil {
+		return false
+	}
+	close(r.resume)
+	r.resume = nil
+	r.logger.Info("Script resumed", "name", r.script.Name)
+	return true
+}
+
+// IsPaused returns true if the running script is paused.
+func (r *ScriptRunner) IsPaused() bool {
+	r.pauseMu.Lock()
+	defer r.pauseMu.Unlock()
+	return r.resume != nil
+}
+
+// waitIfPaused blocks while the run is paused. It returns false if ctx
+// was done meanwhile.
+func (r *ScriptRunner) waitIfPaused(ctx context.Context) bool {
+	r.pauseMu.Lock()
+	resume := r.resume
+	r.pauseMu.Unlock()
+	if resume == nil {
+		return true
+	}
+
+	start := time.Now()
+	defer func() { r.paused += time.Since(start) }()
+	select {
+	case <-resume:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Step lets a run halted in debug mode perform its pending action. With
+// cont, the run also leaves debug mode and carries on without halting. It
+// returns false if the run is not in debug mode.
+func (r *ScriptRunner) Step(cont bool) bool {
+	if !r.running.Load() || !r.debug.Load() {
+		return false
+	}
+	if cont {
+		r.debug.Store(false)
+	}
+	if r.halted.Load() {
+		select {
+		case r.step <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+// halt publishes the action about to run with the current screen and
+// waits for Step. It returns false if ctx was done meanwhile.
+func (r *ScriptRunner) halt(ctx context.Context, action *domainscript.Action, step *domainscript.Step) bool {
+	// Drop a step sent for the previous halt
+	select {
+	case <-r.step:
+	default:
+	}
+	r.halted.Store(true)
+	defer r.halted.Store(false)
+	// Step may have left debug mode before seeing the halt
+	if !r.debug.Load() {
+		return true
+	}
+
+	name := r.script.Name
+	if n := len(r.calls); n > 0 {
+		name = r.calls[n-1]
+	}
+	var where string
+	if step != nil {
+		where = cmp.Or(step.Label, r.scene, step.ExpectedScene)
+	}
+	screen, err := r.capture(ctx)
+	if err != nil {
+		r.logger.Warn("Failed to capture screen for debugging", "error", err)
+	}
+	desc := action.Describe()
+	r.logger.Info("Script halted", "script", name, "step", where, "action", desc)
+	r.publish(event.NewScriptHalted(r.session.ID(), name, where, desc, screen))
+
+	start := time.Now()
+	defer func() { r.paused += time.Since(start) }()
+	select {
+	case <-r.step:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// clock returns the run's time, which stands still while the run is
+// paused or halted, so step timeouts, deadlines and the watchdog don't count pauses.
+func (r *ScriptRunner) clock() time.Time {
+	return time.Now().Add(-r.paused)
+}
+
+// publish publishes an event of the run, tagged with its correlation ID.
+func (r *ScriptRunner) publish(e event.Event) {
+	r.session.publishEvent(event.Correlate(e, r.correlationID))
+}
+
+// publishCounters publishes a copy of the run's counters, so the UI can
+// show them as they change.
+func (r *ScriptRunner) publishCounters() {
+	r.counterMu.Lock()
+	counters := maps.Clone(r.counters)
+	r.counterMu.Unlock()
+	r.publish(event.NewCountersUpdated(r.session.ID(), r.script.Name, counters))
+}
+
+// mergeGameData copies values captured by the data tap into the counters
+// of a running script, so its conditions read them like variables set by
+// OCR rules.
+func (r *ScriptRunner) mergeGameData(values map[string]int) {
+	if !r.running.Load() {
+		return
+	}
+	r.counterMu.Lock()
+	maps.Copy(r.counters, values)
+	r.counterMu.Unlock()
+	r.publishCounters()
+}
+
+// textParams returns the params send_keys text expands, with ${scene} the
+// scene that matched the running step unless a prompt has that key.
+func (r *ScriptRunner) textParams() map[string]string {
+	if _, ok := r.params["scene"]; ok || r.scene == "" {
+		return r.params
+	}
+	params := maps.Clone(r.params)
+	if params == nil {
+		params = make(map[string]string, 1)
+	}
+	params["scene"] = r.scene
+	return params
+}
+
+// Params returns the prompt values the current script was started with.
+func (r *ScriptRunner) Params() map[string]string {
+	return r.params
+}
+
 // IsRunning returns true if a script is currently running.
 func (r *ScriptRunner) IsRunning() bool {
 	return r.running.Load()
@@ -95,7 +333,7 @@ func (r *ScriptRunner) run() {
 	defer r.wg.Done()
 	defer r.cleanup()
 
-	scriptName := r.script.Name
+	scriptName, correlationID := r.script.Name, r.correlationID
 	var stopReason event.StopReason
 	var stopErr error
 
@@ -105,19 +343,35 @@ func (r *ScriptRunner) run() {
 			stopReason = event.StopReasonError
 			stopErr = fmt.Errorf("panic: %v", rec)
 		}
-		r.session.OnScriptStopped(scriptName, stopReason, stopErr)
+		r.runOnStop(stopReason)
+		r.counterMu.Lock()
+		counters := maps.Clone(r.counters)
+		r.counterMu.Unlock()
+		r.session.OnScriptStopped(scriptName, correlationID, stopReason, stopErr, counters)
 	}()
 
-	const defaultWaitDuration = 500 * time.Millisecond
+	r.publishCounters()
+	cursor := newStepCursor(r.script)
+	defer r.pushDeadline(r.script, r.clock())()
 
 	for r.running.Load() {
+		if !r.waitIfPaused(r.ctx) {
+			stopReason = r.cancelReason()
+			return
+		}
 		select {
 		case <-r.ctx.Done():
-			stopReason = event.StopReasonManual
+			stopReason = r.cancelReason()
 			return
 		default:
 		}
 
+		if r.pastDeadline(r.clock()) {
+			stopReason = event.StopReasonError
+			stopErr = r.failure
+			return
+		}
+
 		// Check if browser is still running
 		if !r.session.GetBrowserController().IsRunning() {
 			r.logger.Info("Browser stopped, ending script")
@@ -126,57 +380,416 @@ func (r *ScriptRunner) run() {
 		}
 
 		// Capture current screen
-		screen, err := r.session.GetScreenCapture().Capture(r.ctx)
+		screen, err := r.capture(r.ctx)
 		if err != nil {
 			r.logger.Warn("Failed to capture screen", "error", err)
 			if r.running.Load() {
-				time.Sleep(defaultWaitDuration)
+				time.Sleep(r.pollInterval())
 			}
 			continue
 		}
 
-		// Try to find matching scene
-		var matchedStep *domainscript.Step
-		for i := range r.script.Steps {
-			step := &r.script.Steps[i]
-			scene := r.session.GetSceneRegistry().FindMatch(
-				screen,
-				r.session.GetSceneMatcher(),
-				step.ExpectedScene,
-			)
-			if scene != nil {
-				matchedStep = step
-				break
+		// A screen that stopped changing means the game hung
+		r.watchdog.frame(screen, r.clock())
+		if action, frozen, ok := r.watchdog.checkFrozen(r.clock()); ok {
+			if reason, stop := r.stopReason(r.recoverFrozen(action, frozen)); stop {
+				stopReason = reason
+				stopErr = r.failure
+				return
 			}
+			continue
 		}
 
-		if matchedStep == nil {
-			time.Sleep(defaultWaitDuration)
+		// Try to find matching scene among the steps the cursor allows
+		matchedIndex, scene := r.matchStep(cursor, screen)
+		if matchedIndex < 0 {
+			if from, ok := cursor.expire(r.clock()); ok && r.stepTimedOut(r.script, from) {
+				stopReason = event.StopReasonError
+				stopErr = r.failure
+				return
+			}
+			if action, idle, ok := r.watchdog.check(r.clock()); ok {
+				if reason, stop := r.stopReason(r.recoverStuck(action, idle)); stop {
+					stopReason = reason
+					stopErr = r.failure
+					return
+				}
+			}
+			time.Sleep(r.pollInterval())
 			continue
 		}
+		r.watchdog.matched(r.clock())
 
-		// Execute matched step
-		result := r.executeStep(matchedStep, screen)
-		if result == stepResultQuit {
-			stopReason = event.StopReasonNormal
-			return
+		// Execute matched step; a step skipped by its text rule doesn't branch
+		result := r.runStep(r.script, matchedIndex, scene, screen)
+		if result != stepResultSkipped {
+			cursor.matched(matchedIndex, r.clock())
 		}
-		if result == stepResultResourceExhausted {
-			stopReason = event.StopReasonResourceExhausted
+		if reason, stop := r.stopReason(result); stop {
+			stopReason = reason
+			stopErr = r.failure
 			return
 		}
 
 		if r.running.Load() {
-			time.Sleep(defaultWaitDuration)
+			time.Sleep(r.pollInterval())
 		}
 	}
 
-	stopReason = event.StopReasonManual
+	stopReason = r.cancelReason()
+}
+
+// onStopTimeout bounds the onStop actions of a run.
+const onStopTimeout = 15 * time.Second
+
+// runOnStop runs the script's onStop actions, best-effort: a failed action
+// is logged and the rest still run. They are skipped when the browser or
+// the session is gone, since there is no screen left to clean up.
+func (r *ScriptRunner) runOnStop(reason event.StopReason) {
+	actions := r.script.OnStop
+	if len(actions) == 0 || reason == event.StopReasonBrowserStopped ||
+		!r.session.GetBrowserController().IsRunning() || r.session.Context().Err() != nil {
+		return
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			r.logger.Error("Script onStop actions panicked", "error", rec)
+		}
+	}()
+
+	r.logger.Info("Running onStop actions", "script", r.script.Name, "reason", reason, "actions", len(actions))
+	ctx, cancel := context.WithTimeout(r.session.Context(), onStopTimeout)
+	defer cancel()
+	for i := range actions {
+		if ctx.Err() != nil {
+			r.logger.Warn("Script onStop actions timed out", "script", r.script.Name, "done", i)
+			return
+		}
+		if result := r.executeAction(ctx, &actions[i], nil); result != stepResultContinue {
+			r.logger.Warn("Script onStop action failed", "script", r.script.Name, "index", i,
+				"type", actions[i].Type, "result", result)
+		}
+	}
+}
+
+// cancelReason tells a crashed browser, which the session releases before
+// stopping the script, from a manual stop.
+func (r *ScriptRunner) cancelReason() event.StopReason {
+	if !r.session.GetBrowserController().IsRunning() {
+		return event.StopReasonBrowserStopped
+	}
+	return event.StopReasonManual
+}
+
+// stopReason returns why a step result ends the run, if it does.
+func (r *ScriptRunner) stopReason(result stepResult) (event.StopReason, bool) {
+	switch result {
+	case stepResultQuit:
+		return event.StopReasonNormal, true
+	case stepResultResourceExhausted:
+		return event.StopReasonResourceExhausted, true
+	case stepResultFailed:
+		return event.StopReasonError, true
+	case stepResultStuck:
+		return event.StopReasonStuck, true
+	default:
+		return 0, false
+	}
+}
+
+// recoverStuck publishes that the run matched no scene for idle and takes
+// the watchdog action.
+func (r *ScriptRunner) recoverStuck(action WatchdogAction, idle time.Duration) stepResult {
+	idle = idle.Round(time.Second)
+	r.logger.Warn("Script stuck", "script", r.script.Name, "idle", idle, "action", action)
+	r.publish(event.NewScriptStuck(r.session.ID(), r.script.Name, idle, string(action)))
+	return r.watchdogAction(action, fmt.Errorf("no scene matched for %s", idle))
+}
+
+// recoverFrozen publishes that the run's screen hasn't changed for frozen
+// and takes the watchdog action.
+func (r *ScriptRunner) recoverFrozen(action WatchdogAction, frozen time.Duration) stepResult {
+	frozen = frozen.Round(time.Second)
+	r.logger.Warn("Screen frozen", "script", r.script.Name, "frozen", frozen, "action", action)
+	r.publish(event.NewScreenFrozen(r.session.ID(), r.script.Name, frozen, string(action)))
+	return r.watchdogAction(action, fmt.Errorf("screen unchanged for %s", frozen))
+}
+
+// watchdogAction takes a watchdog action, stopping the run with failure.
+// A failed refresh or recovery script is logged and the run goes on until
+// the watchdog fires again.
+func (r *ScriptRunner) watchdogAction(action WatchdogAction, failure error) stepResult {
+	switch action {
+	case WatchdogRefresh:
+		if err := r.session.GetBrowserController().Refresh(r.ctx); err != nil {
+			r.logger.Warn("Watchdog refresh failed", "error", err)
+		}
+		return stepResultContinue
+	case WatchdogScript:
+		if result := r.callScript(r.session.watchdog.Script); result != stepResultError {
+			return result
+		}
+		return stepResultContinue
+	default:
+		r.failure = failure
+		return stepResultStuck
+	}
+}
+
+// capture captures the screen in game coordinates, the ones scenes and
+// scripts use.
+func (r *ScriptRunner) capture(ctx context.Context) (image.Image, error) {
+	screen, err := r.session.GetScreenCapture().Capture(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.session.GetBrowserController().InFrame().FrameScreen(screen), nil
+}
+
+// publishAction reports an input of the running script, in viewport
+// coordinates so it lines up with the captured screen.
+func (r *ScriptRunner) publishAction(ctrl *BrowserController, t domainscript.ActionType, from, to domainscript.Point) {
+	fromX, fromY := ctrl.ToViewport(from.X, from.Y)
+	toX, toY := ctrl.ToViewport(to.X, to.Y)
+	r.publish(event.NewActionPerformed(r.session.ID(), r.script.Name, string(t),
+		fromX, fromY, toX, toY))
+}
+
+// pollInterval returns the pause between screen checks, which the session
+// may be tuned to change.
+func (r *ScriptRunner) pollInterval() time.Duration {
+	if d := r.session.Tuning().PollInterval; d > 0 {
+		return d
+	}
+	return DefaultPollInterval
+}
+
+// jitter returns the jitter of the running script, unless the session is
+// tuned to override it.
+func (r *ScriptRunner) jitter() domainscript.Jitter {
+	if j := r.session.Tuning().Jitter; j != nil {
+		return *j
+	}
+	return r.script.Jitter
+}
+
+// pace returns d with the wait jitter and lag slowdown applied.
+func (r *ScriptRunner) pace(d time.Duration) time.Duration {
+	s := *r.script
+	s.Jitter = r.jitter()
+	return s.Pace(d, r.session.Lagging(), rand.Float64)
+}
+
+// matchStep returns the index of the step the cursor allows whose scene
+// matches screen best and that scene's name, or -1. A step with a
+// SceneCategory competes with every scene of the category. Of steps
+// sharing a scene the first wins. When the script sets a match margin, a
+// runner-up closer than it makes the frame ambiguous and no step matches.
+func (r *ScriptRunner) matchStep(cursor *stepCursor, screen image.Image) (int, string) {
+	registry := r.session.GetSceneRegistry()
+	var names []string
+	var steps []int
+	for _, i := range cursor.candidates() {
+		step := &cursor.script.Steps[i]
+		if step.SceneCategory == "" {
+			names = append(names, step.ExpectedScene)
+			steps = append(steps, i)
+			continue
+		}
+		for _, scene := range registry.GetByCategory(step.SceneCategory) {
+			names = append(names, scene.Name)
+			steps = append(steps, i)
+		}
+	}
+
+	matched, scene := -1, ""
+	// No names would match every scene
+	var best *domainscene.BestMatch
+	if len(names) > 0 {
+		best = registry.FindBestMatch(screen, r.session.GetSceneMatcher(), names...)
+	}
+	switch {
+	case best == nil:
+	case best.Margin() < cursor.script.MatchMargin:
+		r.logger.Debug("Ambiguous scene match", "scene", best.Scene().Name, "score", best.Score(), "runner_up", best.RunnerUp)
+	default:
+		scene = best.Scene().Name
+		matched = steps[slices.Index(names, scene)]
+	}
+	if r.session.observeMatch != nil {
+		r.session.observeMatch(matched >= 0)
+	}
+	return matched, scene
+}
+
+// callScript runs the steps of a called script inline, matching them
+// against the screen like the main loop does. It returns once no step
+// matches and no goto is pending. Counters are shared with the caller,
+// and quitting, exhausting a resource or failing in the called script
+// stops the whole script.
+func (r *ScriptRunner) callScript(name string) stepResult {
+	if slices.Contains(r.calls, name) || name == r.script.Name {
+		r.logger.Error("Script call cycle", "script", name, "calls", r.calls)
+		return stepResultError
+	}
+	var called *domainscript.Script
+	if registry := r.session.GetScriptRegistry(); registry != nil {
+		called = registry.Get(name)
+	}
+	if called == nil {
+		r.logger.Error("Called script not found", "script", name)
+		return stepResultError
+	}
+
+	r.calls = append(r.calls, name)
+	defer func() { r.calls = r.calls[:len(r.calls)-1] }()
+	r.logger.Debug("Calling script", "script", name)
+
+	cursor := newStepCursor(called)
+	defer r.pushDeadline(called, r.clock())()
+	for r.running.Load() {
+		if !r.waitIfPaused(r.ctx) {
+			return stepResultQuit
+		}
+		if r.pastDeadline(r.clock()) {
+			return stepResultFailed
+		}
+
+		screen, err := r.capture(r.ctx)
+		if err != nil {
+			r.logger.Warn("Failed to capture screen in called script", "script", name, "error", err)
+			return stepResultError
+		}
+
+		i, scene := r.matchStep(cursor, screen)
+		if i < 0 {
+			if cursor.target < 0 {
+				return stepResultContinue
+			}
+			if from, ok := cursor.expire(r.clock()); ok && r.stepTimedOut(called, from) {
+				return stepResultFailed
+			}
+		} else {
+			result := r.runStep(called, i, scene, screen)
+			if result != stepResultSkipped {
+				cursor.matched(i, r.clock())
+			}
+			if result == stepResultQuit || result == stepResultResourceExhausted || result == stepResultFailed {
+				return result
+			}
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return stepResultQuit
+		case <-time.After(r.pollInterval()):
+		}
+	}
+	return stepResultQuit
+}
+
+// stepCursor tracks which steps the runner is waiting for. Without a
+// pending goto every step is a candidate; after a step with OnMatch only
+// the target step is, until it matches or its timeout follows OnTimeout.
+type stepCursor struct {
+	script *domainscript.Script
+	target int // index of the awaited step, -1 for all steps
+	since  time.Time
+}
+
+func newStepCursor(script *domainscript.Script) *stepCursor {
+	return &stepCursor{script: script, target: -1}
+}
+
+// candidates returns the indices of the steps to match, in priority order.
+func (c *stepCursor) candidates() []int {
+	if c.target >= 0 {
+		return []int{c.target}
+	}
+	all := make([]int, len(c.script.Steps))
+	for i := range all {
+		all[i] = i
+	}
+	return all
+}
+
+// matched follows the OnMatch branch of the step at index i.
+func (c *stepCursor) matched(i int, now time.Time) {
+	c.goTo(c.script.Steps[i].OnMatch, now)
+}
+
+// expire follows the OnTimeout branch when the awaited step's timeout has
+// passed, returning that step.
+func (c *stepCursor) expire(now time.Time) (*domainscript.Step, bool) {
+	if c.target < 0 {
+		return nil, false
+	}
+	step := &c.script.Steps[c.target]
+	if step.Timeout <= 0 || now.Sub(c.since) < step.Timeout {
+		return nil, false
+	}
+	c.goTo(step.OnTimeout, now)
+	return step, true
+}
+
+func (c *stepCursor) goTo(label string, now time.Time) {
+	c.target = c.script.StepIndex(label)
+	c.since = now
+}
+
+// runDeadline is when a script with a Timeout must have finished.
+type runDeadline struct {
+	at      time.Time
+	script  string
+	timeout time.Duration
+}
+
+// pushDeadline starts the Timeout of script at now, if it is earlier than
+// the current deadline. The returned function restores the previous one.
+func (r *ScriptRunner) pushDeadline(script *domainscript.Script, now time.Time) func() {
+	prev := r.deadline
+	if script.Timeout > 0 {
+		at := now.Add(script.Timeout)
+		if prev == nil || at.Before(prev.at) {
+			r.deadline = &runDeadline{at: at, script: script.Name, timeout: script.Timeout}
+		}
+	}
+	return func() { r.deadline = prev }
+}
+
+// pastDeadline reports whether the deadline has passed, recording the
+// failure. Deadlines are checked between steps and loop iterations.
+func (r *ScriptRunner) pastDeadline(now time.Time) bool {
+	if r.deadline == nil || now.Before(r.deadline.at) {
+		return false
+	}
+	r.logger.Warn("Script timed out", "script", r.deadline.script, "timeout", r.deadline.timeout)
+	r.failure = fmt.Errorf("script %q timed out after %s", r.deadline.script, r.deadline.timeout)
+	return true
+}
+
+// stepTimedOut handles an awaited step of script whose timeout passed. It
+// reports whether the run fails: a step without an OnTimeout branch fails
+// it unless it continues on failure.
+func (r *ScriptRunner) stepTimedOut(script *domainscript.Script, from *domainscript.Step) bool {
+	if from.OnTimeout != "" || from.ContinueOnFailure {
+		r.logger.Info("Step timed out", "script", script.Name, "label", from.Label, "scene", from.ScenePattern(), "goto", from.OnTimeout)
+		return false
+	}
+	r.logger.Warn("Step timed out, stopping script", "script", script.Name, "label", from.Label, "scene", from.ScenePattern())
+	r.failure = fmt.Errorf("step %q of %q timed out after %s waiting for scene %q",
+		from.Label, script.Name, from.Timeout, from.ScenePattern())
+	return true
 }
 
 func (r *ScriptRunner) cleanup() {
 	r.running.Store(false)
+	r.pauseMu.Lock()
+	r.resume = nil
+	r.pauseMu.Unlock()
+	r.counterMu.Lock()
 	r.counters = make(map[string]int)
+	r.counterMu.Unlock()
 }
 
 type stepResult int
@@ -186,8 +799,50 @@ const (
 	stepResultQuit
 	stepResultResourceExhausted
 	stepResultError
+	stepResultSkipped // A match_text rule didn't find its text
+	stepResultFailed  // Stop the script with failure as the error
+	stepResultStuck   // The watchdog stopped the script
 )
 
+func (r stepResult) String() string {
+	switch r {
+	case stepResultContinue:
+		return "continue"
+	case stepResultQuit:
+		return "quit"
+	case stepResultResourceExhausted:
+		return "exhausted"
+	case stepResultError:
+		return "error"
+	case stepResultSkipped:
+		return "skipped"
+	case stepResultFailed:
+		return "failed"
+	case stepResultStuck:
+		return "stuck"
+	default:
+		return "unknown"
+	}
+}
+
+// runStep executes a step of script matched on scene and publishes it for
+// the execution trace.
+func (r *ScriptRunner) runStep(script *domainscript.Script, index int, scene string, screen image.Image) stepResult {
+	step := &script.Steps[index]
+	prev := r.scene
+	r.scene = scene
+	defer func() { r.scene = prev }()
+	result := r.executeStep(step, screen)
+
+	actions := make([]string, len(step.Actions))
+	for i, action := range step.Actions {
+		actions[i] = string(action.Type)
+	}
+	r.publish(event.NewScriptStepExecuted(r.session.ID(), script.Name, index,
+		scene, actions, result.String(), ScreenHash(screen)))
+	return result
+}
+
 // executeStep executes a single script step.
 func (r *ScriptRunner) executeStep(step *domainscript.Step, screen image.Image) stepResult {
 	if !r.running.Load() {
@@ -195,8 +850,12 @@ func (r *ScriptRunner) executeStep(step *domainscript.Step, screen image.Image)
 	}
 
 	// Check OCR rule before executing actions
-	if step.OCRRule != nil {
-		shouldStop, err := r.checkOCRRule(step.ExpectedScene, step.OCRRule, screen)
+	if step.OCRRule != nil && step.OCRRule.ReadsText() {
+		if !r.checkTextRule(step.OCRRule, screen) {
+			return stepResultSkipped
+		}
+	} else if step.OCRRule != nil {
+		shouldStop, err := r.checkOCRRule(r.scene, step.OCRRule, screen)
 		if err != nil {
 			r.logger.Error("OCR rule check failed", "error", err)
 			return stepResultQuit
@@ -245,6 +904,10 @@ func (r *ScriptRunner) executeLoopedStep(step *domainscript.Step) stepResult {
 		default:
 		}
 
+		if r.pastDeadline(r.clock()) {
+			return stepResultFailed
+		}
+
 		// Execute loop actions
 		if result := r.executeActions(step.Actions[startIdx:endIdx+1], step); result != stepResultContinue {
 			return result
@@ -252,7 +915,7 @@ func (r *ScriptRunner) executeLoopedStep(step *domainscript.Step) stepResult {
 
 		// Check until condition
 		if loop.HasUntilCondition() {
-			screen, err := r.session.GetScreenCapture().Capture(r.ctx)
+			screen, err := r.capture(r.ctx)
 			if err != nil {
 				r.logger.Warn("Failed to capture screen in loop", "error", err)
 				break
@@ -276,7 +939,7 @@ func (r *ScriptRunner) executeLoopedStep(step *domainscript.Step) stepResult {
 			select {
 			case <-r.ctx.Done():
 				return stepResultQuit
-			case <-time.After(loop.Interval):
+			case <-time.After(r.pace(loop.Interval)):
 			}
 		}
 	}
@@ -296,13 +959,19 @@ func (r *ScriptRunner) executeActions(actions []domainscript.Action, step *domai
 			return stepResultQuit
 		}
 
+		if !r.waitIfPaused(r.ctx) {
+			return stepResultQuit
+		}
+		if r.debug.Load() && !r.halt(r.ctx, &action, step) {
+			return stepResultQuit
+		}
 		select {
 		case <-r.ctx.Done():
 			return stepResultQuit
 		default:
 		}
 
-		result := r.executeAction(&action, step)
+		result := r.executeAction(r.ctx, &action, step)
 		if result != stepResultContinue {
 			return result
 		}
@@ -310,27 +979,51 @@ func (r *ScriptRunner) executeActions(actions []domainscript.Action, step *domai
 	return stepResultContinue
 }
 
-// executeAction executes a single action.
-func (r *ScriptRunner) executeAction(action *domainscript.Action, step *domainscript.Step) stepResult {
-	ctx := r.ctx
-	browserCtrl := r.session.GetBrowserController()
+// executeAction executes a single action, until ctx is done.
+func (r *ScriptRunner) executeAction(ctx context.Context, action *domainscript.Action, step *domainscript.Step) stepResult {
+	browserCtrl := r.session.GetBrowserController().InFrame()
 
 	switch action.Type {
 	case domainscript.ActionTypeClick:
-		if len(action.Points) < 1 {
-			r.logger.Error("Click action requires a point")
+		point, ok := action.ClickPoint(rand.Float64)
+		if !ok {
+			r.logger.Error("Click action requires a point or region")
 			return stepResultError
 		}
-		if err := browserCtrl.Click(ctx, action.Points[0].X, action.Points[0].Y); err != nil {
+		if action.Region == nil {
+			jitter := r.jitter()
+			point = jitter.Offset(point, action.JitterPixels(jitter), rand.Float64)
+		}
+		if !r.throttle(ctx, action.Type) {
+			return stepResultQuit
+		}
+		if err := browserCtrl.Click(ctx, point.X, point.Y); err != nil {
 			r.logger.Error("Click failed", "error", err)
 			return stepResultError
 		}
+		r.publishAction(browserCtrl, action.Type, point, point)
+
+	case domainscript.ActionTypeScroll:
+		point, ok := action.ClickPoint(rand.Float64)
+		if !ok {
+			r.logger.Error("Scroll action requires a point or region")
+			return stepResultError
+		}
+		if action.Region == nil {
+			jitter := r.jitter()
+			point = jitter.Offset(point, action.JitterPixels(jitter), rand.Float64)
+		}
+		if err := browserCtrl.Scroll(ctx, point.X, point.Y, action.DeltaX, action.DeltaY); err != nil {
+			r.logger.Error("Scroll failed", "error", err)
+			return stepResultError
+		}
+		r.publishAction(browserCtrl, action.Type, point, point)
 
 	case domainscript.ActionTypeWait:
 		select {
 		case <-ctx.Done():
 			return stepResultQuit
-		case <-time.After(action.Duration):
+		case <-time.After(r.pace(action.Duration)):
 		}
 
 	case domainscript.ActionTypeDrag:
@@ -338,15 +1031,90 @@ func (r *ScriptRunner) executeAction(action *domainscript.Action, step *domainsc
 			r.logger.Error("Drag action requires at least 2 points")
 			return stepResultError
 		}
-		// Convert points
-		points := make([]struct{ X, Y float64 }, len(action.Points))
-		for i, p := range action.Points {
-			points[i] = struct{ X, Y float64 }{p.X, p.Y}
+		jitter := r.jitter()
+		pixels := action.JitterPixels(jitter)
+		from := jitter.Offset(action.Points[0], pixels, rand.Float64)
+		to := jitter.Offset(action.Points[len(action.Points)-1], pixels, rand.Float64)
+		if !r.throttle(ctx, action.Type) {
+			return stepResultQuit
 		}
-		if err := browserCtrl.Drag(ctx, points[0].X, points[0].Y, points[len(points)-1].X, points[len(points)-1].Y); err != nil {
+		if err := browserCtrl.Drag(ctx, from.X, from.Y, to.X, to.Y); err != nil {
 			r.logger.Error("Drag failed", "error", err)
 			return stepResultError
 		}
+		r.publishAction(browserCtrl, action.Type, from, to)
+
+	case domainscript.ActionTypeClickSelector:
+		// Counted against the click limit like coordinate clicks
+		if !r.throttle(ctx, domainscript.ActionTypeClick) {
+			return stepResultQuit
+		}
+		if err := browserCtrl.ClickElement(ctx, action.Selector); err != nil {
+			r.logger.Error("Click element failed", "selector", action.Selector, "error", err)
+			return stepResultError
+		}
+
+	case domainscript.ActionTypeWaitSelector:
+		waitCtx := ctx
+		if action.Duration > 0 {
+			var cancel context.CancelFunc
+			waitCtx, cancel = context.WithTimeout(ctx, action.Duration)
+			defer cancel()
+		}
+		if err := browserCtrl.WaitVisible(waitCtx, action.Selector); err != nil {
+			r.logger.Error("Element did not show", "selector", action.Selector, "error", err)
+			return stepResultError
+		}
+
+	case domainscript.ActionTypeEvaluate:
+		result, err := browserCtrl.Evaluate(ctx, action.Expression)
+		if err != nil {
+			r.logger.Error("Evaluate failed", "expression", action.Expression, "error", err)
+			return stepResultError
+		}
+		if action.Key == "" {
+			break
+		}
+		value, ok := rawJSONInt(result)
+		if !ok {
+			r.logger.Error("Evaluate result is not a number", "key", action.Key, "result", string(result))
+			return stepResultError
+		}
+		r.counterMu.Lock()
+		r.counters[action.Key] = value
+		r.counterMu.Unlock()
+		r.publishCounters()
+
+	case domainscript.ActionTypeSendKeys:
+		r.counterMu.Lock()
+		text := action.ExpandText(r.counters, r.textParams())
+		r.counterMu.Unlock()
+		if action.Selector != "" {
+			if err := browserCtrl.SendKeys(ctx, action.Selector, text); err != nil {
+				r.logger.Error("Send keys failed", "selector", action.Selector, "error", err)
+				return stepResultError
+			}
+			break
+		}
+		// Click the text box first when a point is given, so it has focus
+		if point, ok := action.ClickPoint(rand.Float64); ok {
+			if action.Region == nil {
+				jitter := r.jitter()
+				point = jitter.Offset(point, action.JitterPixels(jitter), rand.Float64)
+			}
+			if !r.throttle(ctx, domainscript.ActionTypeClick) {
+				return stepResultQuit
+			}
+			if err := browserCtrl.Click(ctx, point.X, point.Y); err != nil {
+				r.logger.Error("Click before send keys failed", "error", err)
+				return stepResultError
+			}
+			r.publishAction(browserCtrl, action.Type, point, point)
+		}
+		if err := browserCtrl.TypeText(ctx, text); err != nil {
+			r.logger.Error("Typing text failed", "length", len(text), "error", err)
+			return stepResultError
+		}
 
 	case domainscript.ActionTypeIncr:
 		if action.Key == "" {
@@ -356,6 +1124,7 @@ func (r *ScriptRunner) executeAction(action *domainscript.Action, step *domainsc
 		r.counterMu.Lock()
 		r.counters[action.Key]++
 		r.counterMu.Unlock()
+		r.publishCounters()
 
 	case domainscript.ActionTypeDecr:
 		if action.Key == "" {
@@ -365,6 +1134,27 @@ func (r *ScriptRunner) executeAction(action *domainscript.Action, step *domainsc
 		r.counterMu.Lock()
 		r.counters[action.Key]--
 		r.counterMu.Unlock()
+		r.publishCounters()
+
+	case domainscript.ActionTypeSet, domainscript.ActionTypeAdd:
+		if action.Key == "" || action.Value == nil {
+			r.logger.Error("Variable action requires a key and a value", "type", action.Type)
+			return stepResultError
+		}
+		r.counterMu.Lock()
+		value, err := action.Value.Eval(r.counters)
+		if err == nil {
+			if action.Type == domainscript.ActionTypeAdd {
+				value += r.counters[action.Key]
+			}
+			r.counters[action.Key] = value
+		}
+		r.counterMu.Unlock()
+		if err != nil {
+			r.logger.Error("Variable expression failed", "key", action.Key, "value", action.Value, "error", err)
+			return stepResultError
+		}
+		r.publishCounters()
 
 	case domainscript.ActionTypeQuit:
 		if action.Condition != nil {
@@ -379,14 +1169,27 @@ func (r *ScriptRunner) executeAction(action *domainscript.Action, step *domainsc
 		}
 
 	case domainscript.ActionTypeCheckScene:
+		// A text rule skips the rest of the step when its text is gone
+		if step != nil && step.OCRRule != nil && step.OCRRule.ReadsText() {
+			screen, err := r.capture(ctx)
+			if err != nil {
+				r.logger.Warn("Failed to capture screen for check_scene", "error", err)
+				return stepResultContinue
+			}
+			if !r.checkTextRule(step.OCRRule, screen) {
+				return stepResultSkipped
+			}
+			return stepResultContinue
+		}
+
 		// Check OCR rule if defined
 		if step != nil && step.OCRRule != nil {
-			screen, err := r.session.GetScreenCapture().Capture(ctx)
+			screen, err := r.capture(ctx)
 			if err != nil {
 				r.logger.Warn("Failed to capture screen for check_scene", "error", err)
 				return stepResultContinue
 			}
-			shouldStop, err := r.checkOCRRule(step.ExpectedScene, step.OCRRule, screen)
+			shouldStop, err := r.checkOCRRule(r.scene, step.OCRRule, screen)
 			if err != nil {
 				r.logger.Error("OCR rule check failed in check_scene", "error", err)
 				return stepResultQuit
@@ -397,6 +1200,9 @@ func (r *ScriptRunner) executeAction(action *domainscript.Action, step *domainsc
 			}
 		}
 
+	case domainscript.ActionTypeCall:
+		return r.callScript(action.Script)
+
 	default:
 		r.logger.Warn("Unknown action type", "type", action.Type)
 	}
@@ -404,6 +1210,28 @@ func (r *ScriptRunner) executeAction(action *domainscript.Action, step *domainsc
 	return stepResultContinue
 }
 
+// throttle waits until an action of type t stays within the script's
+// per-minute limit, publishing ScriptThrottled when it has to pause. It
+// returns false if ctx was done meanwhile.
+func (r *ScriptRunner) throttle(ctx context.Context, t domainscript.ActionType) bool {
+	for {
+		pause := r.limiter.acquire(t, time.Now())
+		if pause <= 0 {
+			return true
+		}
+		limit := r.script.Limits.PerMinute(t)
+		r.logger.Warn("Script action limit reached, pausing", "script", r.script.Name,
+			"action", t, "limit", limit, "pause", pause)
+		r.publish(event.NewScriptThrottled(r.session.ID(), r.script.Name, string(t), limit, pause))
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(pause):
+		}
+	}
+}
+
 // checkOCRRule checks if an OCR rule condition is met.
 func (r *ScriptRunner) checkOCRRule(expectedScene string, rule *domainscript.OCRRule, screen image.Image) (bool, error) {
 	if rule == nil {
@@ -412,7 +1240,7 @@ func (r *ScriptRunner) checkOCRRule(expectedScene string, rule *domainscript.OCR
 
 	// Validate rule name
 	switch rule.Name {
-	case "quit_when_exhausted":
+	case domainscript.OCRRuleQuitWhenExhausted, domainscript.OCRRuleReadNumber:
 		// Valid rule
 	default:
 		return false, fmt.Errorf("unknown OCR rule: %s", rule.Name)
@@ -435,17 +1263,13 @@ func (r *ScriptRunner) checkOCRRule(expectedScene string, rule *domainscript.OCR
 		return false, nil
 	}
 
-	// Perform OCR
-	roi := &ocr.ROI{
-		X:      rule.ROI.X,
-		Y:      rule.ROI.Y,
-		Width:  rule.ROI.Width,
-		Height: rule.ROI.Height,
+	if rule.Name == domainscript.OCRRuleReadNumber {
+		return r.checkNumberRule(ocrClient, rule, screen), nil
 	}
 
-	result, err := ocrClient.RecognizeUsageRatioFromImage(r.ctx, screen, roi)
-	if err != nil {
-		r.logger.Warn("OCR recognition failed", "error", err)
+	// Perform OCR
+	result := r.recognizeOCR(ocrClient, expectedScene, rule, screen)
+	if result == nil {
 		return false, nil // Don't stop on OCR failure
 	}
 
@@ -457,11 +1281,196 @@ func (r *ScriptRunner) checkOCRRule(expectedScene string, rule *domainscript.OCR
 	)
 
 	// Check quit_when_exhausted rule
-	if rule.Name == "quit_when_exhausted" {
-		if result.Denominator > rule.Threshold || result.Denominator > result.Numerator {
-			return true, nil
+	triggered := false
+	if rule.Name == domainscript.OCRRuleQuitWhenExhausted {
+		triggered = result.Denominator > rule.Threshold || result.Denominator > result.Numerator
+	}
+
+	r.publish(event.NewOCRResultRecognized(r.session.ID(), r.script.Name, rule.Name,
+		result.Numerator, result.Denominator, rule.Threshold, triggered))
+
+	return triggered, nil
+}
+
+// numberCharset restricts the text read_number rules recognize unless
+// they set their own charset.
+const numberCharset = "0123456789,."
+
+// checkNumberRule reads a read_number rule's number and reports whether
+// it compares to the rule's limit so the script stops, keeping it in the
+// rule's Key. The candidate ROIs are tried in order until one yields a
+// confident number; none counts as not triggered.
+func (r *ScriptRunner) checkNumberRule(client ocr.Client, rule *domainscript.OCRRule, screen image.Image) bool {
+	opts := &ocr.TextOptions{Language: rule.Language, Charset: cmp.Or(rule.Charset, numberCharset)}
+	value, found := 0, false
+	for i, c := range rule.Candidates() {
+		result, err := client.RecognizeTextFromImage(ocr.WithSession(r.ctx, r.session.ID()), screen, &ocr.ROI{
+			X:      c.X,
+			Y:      c.Y,
+			Width:  c.Width,
+			Height: c.Height,
+		}, opts)
+		if err != nil {
+			r.logger.Warn("OCR number recognition failed", "rule", rule.Name, "roi_index", i, "error", err)
+			continue
+		}
+		for _, line := range result.Lines {
+			if line.Confidence < rule.MinConfidence {
+				continue
+			}
+			if value, found = domainscript.ParseNumber(line.Text); found {
+				break
+			}
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		r.logger.Warn("OCR rule read no number", "rule", rule.Name)
+		return false
+	}
+
+	r.counterMu.Lock()
+	if rule.Key != "" {
+		r.counters[rule.Key] = value
+	}
+	limit := rule.Limit(r.counters)
+	triggered := rule.Holds(value, r.counters)
+	r.counterMu.Unlock()
+	if rule.Key != "" {
+		r.publishCounters()
+	}
+
+	r.logger.Info("OCR number result", "rule", rule.Name, "value", value, "compare", rule.Compare, "limit", limit, "triggered", triggered)
+	r.publish(event.NewOCRResultRecognized(r.session.ID(), r.script.Name, rule.Name, value, 0, limit, triggered))
+	return triggered
+}
+
+// checkTextRule reads a text rule's region and reports whether it
+// contains one of the expected strings of a match_text rule, or matches
+// the pattern of a match_pattern rule, whose integer groups then set the
+// variables they are named after. The candidate ROIs are tried in
+// order until one yields confident lines. An unavailable OCR service
+// counts as no match.
+func (r *ScriptRunner) checkTextRule(rule *domainscript.OCRRule, screen image.Image) bool {
+	ocrClient := r.session.GetOCRClient()
+	if ocrClient == nil || !ocrClient.IsHealthy() {
+		r.logger.Warn("OCR client not available, skipping step", "rule", rule.Name)
+		return false
+	}
+
+	opts := &ocr.TextOptions{Language: rule.Language, Charset: rule.Charset}
+	var lines []string
+	for i, c := range rule.Candidates() {
+		result, err := ocrClient.RecognizeTextFromImage(ocr.WithSession(r.ctx, r.session.ID()), screen, &ocr.ROI{
+			X:      c.X,
+			Y:      c.Y,
+			Width:  c.Width,
+			Height: c.Height,
+		}, opts)
+		if err != nil {
+			r.logger.Warn("OCR text recognition failed", "rule", rule.Name, "roi_index", i, "error", err)
+			continue
+		}
+		for _, line := range result.Lines {
+			if line.Confidence >= rule.MinConfidence {
+				lines = append(lines, line.Text)
+			}
+		}
+		if len(lines) > 0 {
+			break
+		}
+	}
+
+	var matched string
+	var ok bool
+	if rule.Name == domainscript.OCRRuleMatchPattern {
+		var vars map[string]int
+		matched, vars, ok = rule.MatchPattern(strings.Join(lines, "\n"))
+		if len(vars) > 0 {
+			r.counterMu.Lock()
+			maps.Copy(r.counters, vars)
+			r.counterMu.Unlock()
+			r.publishCounters()
+		}
+	} else {
+		matched, ok = rule.MatchText(lines)
+	}
+	r.logger.Info("OCR text result", "rule", rule.Name, "lines", lines, "matched", matched)
+	r.publish(event.NewOCRTextRecognized(r.session.ID(), r.script.Name, rule.Name, lines, matched))
+	return ok
+}
+
+// recognizeOCR tries the rule's candidate ROIs, starting with the one that last
+// succeeded in this session, and returns the first confident result.
+// Until one has succeeded, all candidates are read in a single request.
+// Returns nil if no candidate yields one.
+func (r *ScriptRunner) recognizeOCR(client ocr.Client, expectedScene string, rule *domainscript.OCRRule, screen image.Image) *ocr.UsageRatioResult {
+	key := r.script.Name + "/" + expectedScene + "/" + rule.Name
+	candidates := rule.Candidates()
+
+	cached, hasCached := r.ocrROIs[key]
+	order := make([]int, 0, len(candidates))
+	if hasCached && cached < len(candidates) {
+		order = append(order, cached)
+	}
+	for i := range candidates {
+		if !hasCached || i != cached {
+			order = append(order, i)
+		}
+	}
+
+	var batch []*ocr.UsageRatioResult
+	if !hasCached && len(candidates) > 1 {
+		rois := make([]ocr.ROI, len(candidates))
+		for i, c := range candidates {
+			rois[i] = ocr.ROI{X: c.X, Y: c.Y, Width: c.Width, Height: c.Height}
+		}
+		results, err := client.RecognizeMany(ocr.WithSession(r.ctx, r.session.ID()), screen, rois)
+		if err != nil {
+			r.logger.Warn("OCR recognition failed", "rule", rule.Name, "error", err)
+			return nil
+		}
+		batch = results
+	}
+
+	for _, i := range order {
+		c := candidates[i]
+		var result *ocr.UsageRatioResult
+		if batch != nil {
+			if result = batch[i]; result == nil {
+				r.logger.Warn("OCR recognition failed", "rule", rule.Name, "roi_index", i, "error", "no ratio found")
+				continue
+			}
+		} else {
+			var err error
+			result, err = client.RecognizeUsageRatioFromImage(ocr.WithSession(r.ctx, r.session.ID()), screen, &ocr.ROI{
+				X:      c.X,
+				Y:      c.Y,
+				Width:  c.Width,
+				Height: c.Height,
+			})
+			if err != nil {
+				r.logger.Warn("OCR recognition failed", "rule", rule.Name, "roi_index", i, "error", err)
+				continue
+			}
+		}
+		if result.Confidence < rule.MinConfidence {
+			r.logger.Debug("OCR result below confidence", "rule", rule.Name, "roi_index", i, "confidence", result.Confidence)
+			continue
+		}
+
+		if !hasCached || cached != i {
+			r.ocrROIs[key] = i
+			if i > 0 {
+				r.logger.Warn("OCR rule resolved to fallback ROI", "rule", rule.Name, "roi_index", i)
+			}
+			rect := image.Rect(c.X, c.Y, c.X+c.Width, c.Y+c.Height)
+			r.publish(event.NewOCRROISelected(r.session.ID(), r.script.Name, rule.Name, i, rect))
 		}
+		return result
 	}
 
-	return false, nil
+	return nil
 }
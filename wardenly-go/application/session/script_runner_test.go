@@ -1,9 +1,22 @@
 package session
 
 import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+	"wardenly-go/domain/account"
+	domainscene "wardenly-go/domain/scene"
 	domainscript "wardenly-go/domain/script"
+	"wardenly-go/infrastructure/ocr"
 )
 
 func TestStepResult_Constants(t *testing.T) {
@@ -100,4 +113,785 @@ func TestActionType_Values(t *testing.T) {
 	if domainscript.ActionTypeCheckScene != "check_scene" {
 		t.Errorf("ActionTypeCheckScene = %v, want check_scene", domainscript.ActionTypeCheckScene)
 	}
+	if domainscript.ActionTypeCall != "call" {
+		t.Errorf("ActionTypeCall = %v, want call", domainscript.ActionTypeCall)
+	}
+	if domainscript.ActionTypeSet != "set" || domainscript.ActionTypeAdd != "add" {
+		t.Errorf("ActionTypeSet, ActionTypeAdd = %v, %v, want set, add", domainscript.ActionTypeSet, domainscript.ActionTypeAdd)
+	}
+	if domainscript.ActionTypeSendKeys != "send_keys" || domainscript.ActionTypeScroll != "scroll" {
+		t.Errorf("ActionTypeSendKeys, ActionTypeScroll = %v, %v, want send_keys, scroll",
+			domainscript.ActionTypeSendKeys, domainscript.ActionTypeScroll)
+	}
+}
+
+func TestScriptRunner_Deadlines(t *testing.T) {
+	r := NewScriptRunner(nil, nil)
+	start := time.Now()
+	outer := &domainscript.Script{Name: "daily", Timeout: time.Hour}
+
+	restoreOuter := r.pushDeadline(outer, start)
+	if r.pastDeadline(start.Add(59 * time.Minute)) {
+		t.Fatal("past deadline before timeout")
+	}
+
+	// A called script's shorter timeout applies while it runs; a longer one doesn't
+	restoreLong := r.pushDeadline(&domainscript.Script{Name: "slow", Timeout: 2 * time.Hour}, start)
+	if r.deadline.script != "daily" {
+		t.Errorf("deadline script = %q, want daily", r.deadline.script)
+	}
+	restoreLong()
+	restoreShort := r.pushDeadline(&domainscript.Script{Name: "collect", Timeout: time.Minute}, start)
+	if !r.pastDeadline(start.Add(time.Minute)) {
+		t.Fatal("called script deadline not enforced")
+	}
+	if r.failure == nil || !strings.Contains(r.failure.Error(), `"collect" timed out after 1m0s`) {
+		t.Errorf("failure = %v", r.failure)
+	}
+	restoreShort()
+	if r.pastDeadline(start.Add(time.Minute)) {
+		t.Error("called script deadline outlived the call")
+	}
+
+	restoreOuter()
+	if r.deadline != nil {
+		t.Errorf("deadline after restore = %+v, want nil", r.deadline)
+	}
+	r.pushDeadline(&domainscript.Script{Name: "forever"}, start)
+	if r.pastDeadline(start.Add(24 * time.Hour)) {
+		t.Error("script without timeout has a deadline")
+	}
+}
+
+func TestScriptRunner_StepTimedOut(t *testing.T) {
+	r := NewScriptRunner(nil, nil)
+	script := &domainscript.Script{Name: "daily"}
+	tests := []struct {
+		name string
+		step domainscript.Step
+		fail bool
+	}{
+		{"fallback step", domainscript.Step{Label: "reward", OnTimeout: "battle"}, false},
+		{"continue on failure", domainscript.Step{Label: "reward", ContinueOnFailure: true}, false},
+		{"stop", domainscript.Step{Label: "reward", ExpectedScene: "reward", Timeout: 3 * time.Second}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.failure = nil
+			if got := r.stepTimedOut(script, &tt.step); got != tt.fail {
+				t.Fatalf("stepTimedOut() = %v, want %v", got, tt.fail)
+			}
+			if tt.fail && (r.failure == nil || r.failure.Error() != `step "reward" of "daily" timed out after 3s waiting for scene "reward"`) {
+				t.Errorf("failure = %v", r.failure)
+			}
+		})
+	}
+}
+
+func TestStepCursor_Branches(t *testing.T) {
+	script := &domainscript.Script{Steps: []domainscript.Step{
+		{ExpectedScene: "quest", OnMatch: "reward"},
+		{Label: "reward", ExpectedScene: "reward", Timeout: 3 * time.Second, OnTimeout: "battle"},
+		{Label: "battle", ExpectedScene: "battle"},
+	}}
+	c := newStepCursor(script)
+	start := time.Now()
+
+	if got := c.candidates(); !slices.Equal(got, []int{0, 1, 2}) {
+		t.Fatalf("candidates() = %v, want all steps", got)
+	}
+
+	c.matched(0, start)
+	if got := c.candidates(); !slices.Equal(got, []int{1}) {
+		t.Fatalf("after goto: candidates() = %v, want [1]", got)
+	}
+	if _, ok := c.expire(start.Add(2 * time.Second)); ok {
+		t.Error("expired before timeout")
+	}
+	from, ok := c.expire(start.Add(3 * time.Second))
+	if !ok || from.Label != "reward" {
+		t.Fatalf("expire() = %v, %v; want reward step", from, ok)
+	}
+	if got := c.candidates(); !slices.Equal(got, []int{2}) {
+		t.Fatalf("after timeout: candidates() = %v, want [2]", got)
+	}
+
+	// No timeout waits indefinitely; a step without OnMatch resumes matching all steps
+	if _, ok := c.expire(start.Add(time.Hour)); ok {
+		t.Error("step without timeout expired")
+	}
+	c.matched(2, start)
+	if got := c.candidates(); len(got) != 3 {
+		t.Errorf("after plain step: candidates() = %v, want all steps", got)
+	}
+}
+
+// fakeOCRClient returns a canned result per ROI x-coordinate.
+type fakeOCRClient struct {
+	results map[int]*ocr.UsageRatioResult
+	texts   map[int]*ocr.TextResult
+	calls   []int
+	// batches are the ROI x-coordinates of each RecognizeMany call
+	batches [][]int
+}
+
+func (c *fakeOCRClient) RecognizeUsageRatio(ctx context.Context, imageBytes []byte, roi *ocr.ROI) (*ocr.UsageRatioResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeOCRClient) RecognizeUsageRatioFromImage(ctx context.Context, img image.Image, roi *ocr.ROI) (*ocr.UsageRatioResult, error) {
+	c.calls = append(c.calls, roi.X)
+	if result, ok := c.results[roi.X]; ok {
+		return result, nil
+	}
+	return nil, errors.New("no text")
+}
+
+func (c *fakeOCRClient) RecognizeMany(ctx context.Context, img image.Image, rois []ocr.ROI) ([]*ocr.UsageRatioResult, error) {
+	xs := make([]int, len(rois))
+	results := make([]*ocr.UsageRatioResult, len(rois))
+	for i, roi := range rois {
+		xs[i] = roi.X
+		results[i] = c.results[roi.X]
+	}
+	c.batches = append(c.batches, xs)
+	return results, nil
+}
+
+func (c *fakeOCRClient) RecognizeText(ctx context.Context, imageBytes []byte, roi *ocr.ROI, opts *ocr.TextOptions) (*ocr.TextResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeOCRClient) RecognizeTextFromImage(ctx context.Context, img image.Image, roi *ocr.ROI, opts *ocr.TextOptions) (*ocr.TextResult, error) {
+	c.calls = append(c.calls, roi.X)
+	if result, ok := c.texts[roi.X]; ok {
+		return result, nil
+	}
+	return nil, errors.New("no text")
+}
+
+func (c *fakeOCRClient) IsHealthy() bool { return true }
+func (c *fakeOCRClient) Close()          {}
+
+// recordingBus collects published events synchronously.
+type recordingBus struct {
+	events []event.Event
+}
+
+func (b *recordingBus) Publish(e event.Event)                          { b.events = append(b.events, e) }
+func (b *recordingBus) Subscribe(handler eventbus.EventHandler) string { return "" }
+func (b *recordingBus) SubscribeSession(sessionID string, handler eventbus.EventHandler) string {
+	return ""
+}
+func (b *recordingBus) Unsubscribe(subscriptionID string) {}
+func (b *recordingBus) Close()                            {}
+
+func TestScriptRunner_RecognizeOCRFailover(t *testing.T) {
+	bus := &recordingBus{}
+	client := &fakeOCRClient{results: map[int]*ocr.UsageRatioResult{
+		10: {Numerator: 5, Denominator: 1, Confidence: 0.2},
+		20: {Numerator: 5, Denominator: 2, Confidence: 0.9},
+	}}
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, EventBus: bus, OCRClient: client})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "daily"}
+	r.ctx = context.Background()
+
+	rule := &domainscript.OCRRule{
+		Name:          "quit_when_exhausted",
+		ROI:           domainscript.ROI{X: 0, Width: 5, Height: 5},
+		FallbackROIs:  []domainscript.ROI{{X: 10, Width: 5, Height: 5}, {X: 20, Width: 5, Height: 5}},
+		MinConfidence: 0.5,
+	}
+	screen := image.NewRGBA(image.Rect(0, 0, 50, 50))
+
+	result := r.recognizeOCR(client, "main", rule, screen)
+	if result == nil || result.Denominator != 2 {
+		t.Fatalf("recognizeOCR() = %+v, want result from second fallback", result)
+	}
+	if len(client.batches) != 1 || !slices.Equal(client.batches[0], []int{0, 10, 20}) || len(client.calls) != 0 {
+		t.Errorf("first lookup read batches %v and ROIs %v, want all candidates in one batch", client.batches, client.calls)
+	}
+	if len(bus.events) != 1 {
+		t.Fatalf("published %d events, want 1", len(bus.events))
+	}
+	selected, ok := bus.events[0].(*event.OCRROISelected)
+	if !ok || selected.ROIIndex != 2 || selected.ROI != image.Rect(20, 0, 25, 5) {
+		t.Errorf("event = %+v, want OCRROISelected for index 2", bus.events[0])
+	}
+
+	// The cached ROI is tried first and no new event is published.
+	client.calls = nil
+	r.recognizeOCR(client, "main", rule, screen)
+	if len(client.calls) != 1 || client.calls[0] != 20 || len(client.batches) != 1 {
+		t.Errorf("second lookup tried ROIs %v, want cached [20]", client.calls)
+	}
+	if len(bus.events) != 1 {
+		t.Errorf("cache hit published %d events, want 1", len(bus.events))
+	}
+}
+
+func TestScriptRunner_VariableActions(t *testing.T) {
+	bus := &recordingBus{}
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, EventBus: bus})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "daily"}
+	r.ctx = context.Background()
+	r.counters = map[string]int{"energy": 7}
+
+	expr := func(src string) *domainscript.Expr {
+		e, err := domainscript.ParseExpr(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	actions := []domainscript.Action{
+		{Type: domainscript.ActionTypeSet, Key: "runs", Value: expr("energy / 2")},
+		{Type: domainscript.ActionTypeAdd, Key: "runs", Value: expr("1")},
+	}
+	for _, a := range actions {
+		if got := r.executeAction(r.ctx, &a, nil); got != stepResultContinue {
+			t.Fatalf("executeAction(%s) = %v, want continue", a.Type, got)
+		}
+	}
+	if r.counters["runs"] != 4 {
+		t.Errorf("runs = %d, want 4", r.counters["runs"])
+	}
+	if len(bus.events) != 2 {
+		t.Fatalf("published %d events, want a CountersUpdated per action", len(bus.events))
+	}
+	updated, ok := bus.events[1].(*event.CountersUpdated)
+	if !ok || updated.ScriptName != "daily" || updated.Counters["runs"] != 4 || updated.Counters["energy"] != 7 {
+		t.Errorf("event = %+v, want CountersUpdated with runs 4", bus.events[1])
+	}
+
+	bad := domainscript.Action{Type: domainscript.ActionTypeSet, Key: "runs", Value: expr("1 / zero")}
+	if got := r.executeAction(r.ctx, &bad, nil); got != stepResultError || r.counters["runs"] != 4 {
+		t.Errorf("division by zero = %v (runs %d), want stepResultError and runs unchanged", got, r.counters["runs"])
+	}
+	if len(bus.events) != 2 {
+		t.Errorf("failed action published %d events, want none", len(bus.events)-2)
+	}
+}
+
+func TestScriptRunner_MatchStepPicksBestScene(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	scenes := domainscene.NewRegistry()
+	scenes.Register(&domainscene.Scene{Name: "popup", Points: []domainscene.Point{{X: 5, Y: 5, Color: color.RGBA{251, 0, 0, 255}}}})
+	scenes.Register(&domainscene.Scene{Name: "shop", Points: []domainscene.Point{{X: 5, Y: 5, Color: red}}})
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, SceneRegistry: scenes, SceneThreshold: 5})
+	r := s.scriptRunner
+
+	script := &domainscript.Script{Name: "daily", Steps: []domainscript.Step{
+		{ExpectedScene: "popup"}, {ExpectedScene: "shop"}, {ExpectedScene: "shop"},
+	}}
+	screen := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	screen.Set(5, 5, red)
+
+	// popup comes first and matches, but shop matches exactly
+	if got, scene := r.matchStep(newStepCursor(script), screen); got != 1 || scene != "shop" {
+		t.Errorf("matchStep() = %d, %q, want 1, shop", got, scene)
+	}
+	// popup is 0.2 behind, within the margin
+	script.MatchMargin = 0.5
+	if got, _ := r.matchStep(newStepCursor(script), screen); got != -1 {
+		t.Errorf("matchStep() with margin = %d, want -1 for an ambiguous frame", got)
+	}
+}
+
+func TestScriptRunner_MatchStepSceneCategory(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	scenes := domainscene.NewRegistry()
+	scenes.Register(&domainscene.Scene{Name: "battle_boss", Category: "battle", Points: []domainscene.Point{{X: 5, Y: 5, Color: red}}})
+	scenes.Register(&domainscene.Scene{Name: "battle_wave", Category: "battle", Points: []domainscene.Point{{X: 5, Y: 5, Color: color.RGBA{0, 0, 255, 255}}}})
+	scenes.Register(&domainscene.Scene{Name: "main", Points: []domainscene.Point{{X: 5, Y: 5, Color: color.RGBA{0, 255, 0, 255}}}})
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, SceneRegistry: scenes, SceneThreshold: 5})
+	r := s.scriptRunner
+
+	script := &domainscript.Script{Name: "farm", Steps: []domainscript.Step{
+		{ExpectedScene: "main"}, {SceneCategory: "battle"},
+	}}
+	screen := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	screen.Set(5, 5, red)
+
+	if got, scene := r.matchStep(newStepCursor(script), screen); got != 1 || scene != "battle_boss" {
+		t.Errorf("matchStep() = %d, %q, want 1, battle_boss", got, scene)
+	}
+
+	// A category without scenes matches nothing, not every scene
+	script.Steps = []domainscript.Step{{SceneCategory: "shop"}}
+	if got, _ := r.matchStep(newStepCursor(script), screen); got != -1 {
+		t.Errorf("matchStep() for an empty category = %d, want -1", got)
+	}
+
+	r.params = map[string]string{"amount": "100"}
+	r.scene = "battle_boss"
+	action := domainscript.Action{Type: domainscript.ActionTypeSendKeys, Text: "${scene}:${amount}"}
+	if got := action.ExpandText(nil, r.textParams()); got != "battle_boss:100" {
+		t.Errorf("ExpandText() = %q, want the matched scene", got)
+	}
+	if _, ok := r.params["scene"]; ok {
+		t.Error("textParams() changed the run's params")
+	}
+}
+
+func TestScriptRunner_PauseResume(t *testing.T) {
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "daily"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if r.Pause() {
+		t.Fatal("Pause() succeeded without a running script")
+	}
+	r.running.Store(true)
+	if !r.Pause() || r.Pause() || !r.IsPaused() {
+		t.Fatal("Pause() should succeed once")
+	}
+
+	done := make(chan bool)
+	go func() { done <- r.waitIfPaused(ctx) }()
+	select {
+	case <-done:
+		t.Fatal("waitIfPaused returned while paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if !r.Resume() || r.Resume() {
+		t.Fatal("Resume() should succeed once")
+	}
+	if !<-done {
+		t.Error("waitIfPaused() = false after Resume")
+	}
+	if r.paused < 20*time.Millisecond {
+		t.Errorf("paused = %v, want the pause excluded from the run clock", r.paused)
+	}
+
+	r.Pause()
+	cancel()
+	if r.waitIfPaused(ctx) {
+		t.Error("waitIfPaused() = true after the run was cancelled")
+	}
+}
+
+func TestScriptRunner_DebugHaltsBeforeEachAction(t *testing.T) {
+	bus := &recordingBus{}
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, EventBus: bus, Driver: newMockDriver()})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "daily"}
+	r.ctx = context.Background()
+	r.step = make(chan struct{}, 1)
+	r.running.Store(true)
+	r.debug.Store(true)
+
+	step := &domainscript.Step{Label: "farm"}
+	actions := []domainscript.Action{
+		{Type: domainscript.ActionTypeIncr, Key: "runs"},
+		{Type: domainscript.ActionTypeIncr, Key: "runs"},
+		{Type: domainscript.ActionTypeIncr, Key: "runs"},
+	}
+	done := make(chan stepResult)
+	go func() { done <- r.executeActions(actions, step) }()
+
+	waitHalted := func() {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !r.halted.Load() {
+			if time.Now().After(deadline) {
+				t.Fatal("runner did not halt")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitHalted()
+	if !r.Step(false) {
+		t.Fatal("Step() = false while halted")
+	}
+	// The second action halts again once the first ran; continuing runs
+	// the rest
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(time.Millisecond) {
+		r.counterMu.Lock()
+		runs := r.counters["runs"]
+		r.counterMu.Unlock()
+		if runs == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("first action did not run after Step")
+		}
+	}
+	waitHalted()
+	r.Step(true)
+
+	if got := <-done; got != stepResultContinue {
+		t.Fatalf("executeActions() = %v, want continue", got)
+	}
+	if r.counters["runs"] != 3 {
+		t.Errorf("runs = %d, want 3", r.counters["runs"])
+	}
+	var halts []*event.ScriptHalted
+	for _, e := range bus.events {
+		if h, ok := e.(*event.ScriptHalted); ok {
+			halts = append(halts, h)
+		}
+	}
+	if len(halts) != 2 || halts[0].ScriptName != "daily" || halts[0].Step != "farm" || halts[0].Action != "Add 1 to `runs`" {
+		t.Errorf("halts = %+v, want two before the first two actions", halts)
+	}
+	if r.Step(false) {
+		t.Error("Step() = true after leaving debug mode")
+	}
+}
+
+func TestScriptRunner_CallScriptRejectsUnresolved(t *testing.T) {
+	registry := domainscript.NewRegistry()
+	registry.Register(&domainscript.Script{Name: "close_popups"})
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, ScriptRegistry: registry})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "daily"}
+	r.ctx = context.Background()
+	r.running.Store(true)
+
+	if got := r.callScript("daily"); got != stepResultError {
+		t.Errorf("callScript(daily) = %v, want stepResultError for a call back into the running script", got)
+	}
+	if got := r.callScript("missing"); got != stepResultError {
+		t.Errorf("callScript(missing) = %v, want stepResultError", got)
+	}
+	r.calls = []string{"close_popups"}
+	if got := r.callScript("close_popups"); got != stepResultError {
+		t.Errorf("callScript(close_popups) = %v, want stepResultError for a nested cycle", got)
+	}
+}
+
+func TestWatchdog_Check(t *testing.T) {
+	start := time.Now()
+	w := newWatchdog(WatchdogConfig{Timeout: 10 * time.Minute, Action: WatchdogRefresh}, start)
+
+	if _, _, ok := w.check(start.Add(9 * time.Minute)); ok {
+		t.Fatal("watchdog fired before timeout")
+	}
+	action, idle, ok := w.check(start.Add(10 * time.Minute))
+	if !ok || action != WatchdogRefresh || idle != 10*time.Minute {
+		t.Fatalf("check() = %q, %v, %v, want refresh after 10m", action, idle, ok)
+	}
+
+	// A second firing without a match since the recovery stops the script
+	if action, _, _ := w.check(start.Add(20 * time.Minute)); action != WatchdogStop {
+		t.Errorf("repeated firing action = %q, want stop", action)
+	}
+
+	// A match restarts the timer and allows recovering again
+	w.matched(start.Add(30 * time.Minute))
+	if _, _, ok := w.check(start.Add(39 * time.Minute)); ok {
+		t.Error("watchdog fired before timeout after a match")
+	}
+	if action, _, _ := w.check(start.Add(40 * time.Minute)); action != WatchdogRefresh {
+		t.Errorf("action after match = %q, want refresh", action)
+	}
+
+	disabled := newWatchdog(WatchdogConfig{}, start)
+	if _, _, ok := disabled.check(start.Add(24 * time.Hour)); ok {
+		t.Error("watchdog without timeout fired")
+	}
+}
+
+func TestWatchdog_CheckFrozen(t *testing.T) {
+	start := time.Now()
+	w := newWatchdog(WatchdogConfig{FreezeTimeout: 3 * time.Minute, Action: WatchdogRefresh}, start)
+	screen := func(gray uint8) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+		for i := range img.Pix {
+			img.Pix[i] = gray
+		}
+		return img
+	}
+
+	w.frame(screen(100), start)
+	w.frame(screen(100), start.Add(time.Minute))
+	if _, _, ok := w.checkFrozen(start.Add(2 * time.Minute)); ok {
+		t.Fatal("watchdog fired before the freeze timeout")
+	}
+	// A changing screen restarts the timer
+	w.frame(screen(140), start.Add(2*time.Minute))
+	if _, _, ok := w.checkFrozen(start.Add(4 * time.Minute)); ok {
+		t.Fatal("watchdog fired although the screen changed")
+	}
+	w.frame(screen(140), start.Add(5*time.Minute))
+	action, frozen, ok := w.checkFrozen(start.Add(5 * time.Minute))
+	if !ok || action != WatchdogRefresh || frozen != 3*time.Minute {
+		t.Fatalf("checkFrozen() = %q, %v, %v, want refresh after 3m", action, frozen, ok)
+	}
+	// Freezing again without a match since the recovery stops the script
+	if action, _, _ := w.checkFrozen(start.Add(8 * time.Minute)); action != WatchdogStop {
+		t.Errorf("repeated freeze action = %q, want stop", action)
+	}
+
+	disabled := newWatchdog(WatchdogConfig{Timeout: time.Minute}, start)
+	disabled.frame(screen(100), start)
+	if _, _, ok := disabled.checkFrozen(start.Add(24 * time.Hour)); ok {
+		t.Error("watchdog without freeze timeout fired")
+	}
+}
+
+func TestScriptRunner_RecoverStuck(t *testing.T) {
+	bus := &recordingBus{}
+	s := New(&Config{
+		ID:             "s1",
+		Account:        &account.Account{ID: "a1"},
+		EventBus:       bus,
+		ScriptRegistry: domainscript.NewRegistry(),
+		Watchdog:       WatchdogConfig{Timeout: time.Minute, Action: WatchdogScript, Script: "missing"},
+	})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "daily"}
+	r.ctx = context.Background()
+	r.running.Store(true)
+
+	// A recovery script that can't run leaves the run going
+	if got := r.recoverStuck(WatchdogScript, 61*time.Second); got != stepResultContinue {
+		t.Errorf("recoverStuck(script) = %v, want continue", got)
+	}
+	got := r.recoverStuck(WatchdogStop, 90*time.Second)
+	if reason, stop := r.stopReason(got); !stop || reason != event.StopReasonStuck {
+		t.Errorf("recoverStuck(stop) = %v, want a stuck stop", got)
+	}
+	if r.failure == nil || r.failure.Error() != "no scene matched for 1m30s" {
+		t.Errorf("failure = %v", r.failure)
+	}
+
+	if len(bus.events) != 2 {
+		t.Fatalf("published %d events, want 2", len(bus.events))
+	}
+	stuck, ok := bus.events[0].(*event.ScriptStuck)
+	if !ok || stuck.ScriptName != "daily" || stuck.Idle != 61*time.Second || stuck.Action != "script" {
+		t.Errorf("event = %+v, want ScriptStuck for daily", bus.events[0])
+	}
+
+	got = r.recoverFrozen(WatchdogStop, 3*time.Minute)
+	if reason, stop := r.stopReason(got); !stop || reason != event.StopReasonStuck {
+		t.Errorf("recoverFrozen(stop) = %v, want a stuck stop", got)
+	}
+	if r.failure == nil || r.failure.Error() != "screen unchanged for 3m0s" {
+		t.Errorf("failure = %v", r.failure)
+	}
+	if frozen, ok := bus.events[len(bus.events)-1].(*event.ScreenFrozen); !ok || frozen.Frozen != 3*time.Minute || frozen.Action != "stop" {
+		t.Errorf("event = %+v, want ScreenFrozen", bus.events[len(bus.events)-1])
+	}
+}
+
+func TestScriptRunner_SendKeys(t *testing.T) {
+	driver := newMockDriver()
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, Driver: driver})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "donate"}
+	r.ctx = context.Background()
+	r.params = map[string]string{"amount": "250"}
+
+	action := domainscript.Action{
+		Type:   domainscript.ActionTypeSendKeys,
+		Points: []domainscript.Point{{X: 300, Y: 200}},
+		Text:   "${amount}",
+	}
+	if got := r.executeAction(r.ctx, &action, nil); got != stepResultContinue {
+		t.Fatalf("executeAction(send_keys) = %v, want continue", got)
+	}
+	if !driver.clickCalled || driver.lastClickX != 300 || driver.lastClickY != 200 {
+		t.Errorf("focus click = %v at (%v, %v), want (300, 200)", driver.clickCalled, driver.lastClickX, driver.lastClickY)
+	}
+	if !slices.Equal(driver.typed, []string{"250"}) {
+		t.Errorf("typed = %q, want [250]", driver.typed)
+	}
+}
+
+func TestScriptRunner_SelectorActions(t *testing.T) {
+	driver := &flowDriver{mockDriver: newMockDriver()}
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, Driver: driver})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "pick_server"}
+	r.ctx = context.Background()
+
+	actions := []domainscript.Action{
+		{Type: domainscript.ActionTypeWaitSelector, Selector: "ul.servers", Duration: time.Second},
+		{Type: domainscript.ActionTypeClickSelector, Selector: "ul.servers li"},
+	}
+	for _, a := range actions {
+		if got := r.executeAction(r.ctx, &a, nil); got != stepResultContinue {
+			t.Fatalf("executeAction(%s) = %v, want continue", a.Type, got)
+		}
+	}
+	if want := []string{"wait ul.servers", "click ul.servers li"}; !slices.Equal(driver.ops, want) {
+		t.Errorf("ops = %q, want %q", driver.ops, want)
+	}
+	if driver.clickCalled {
+		t.Error("selector clicks should not click coordinates")
+	}
+
+	driver.failing = "click #missing"
+	missing := domainscript.Action{Type: domainscript.ActionTypeClickSelector, Selector: "#missing"}
+	if got := r.executeAction(r.ctx, &missing, nil); got != stepResultError {
+		t.Errorf("executeAction(missing element) = %v, want error", got)
+	}
+}
+
+func TestScriptRunner_Scroll(t *testing.T) {
+	driver := newMockDriver()
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, Driver: driver})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "rewards"}
+	r.ctx = context.Background()
+
+	action := domainscript.Action{
+		Type:   domainscript.ActionTypeScroll,
+		Points: []domainscript.Point{{X: 540, Y: 400}},
+		DeltaY: 300,
+	}
+	if got := r.executeAction(r.ctx, &action, nil); got != stepResultContinue {
+		t.Fatalf("executeAction(scroll) = %v, want continue", got)
+	}
+	if want := [][4]float64{{540, 400, 0, 300}}; !slices.Equal(driver.scrolls, want) {
+		t.Errorf("scrolls = %v, want %v", driver.scrolls, want)
+	}
+}
+
+func TestActionLimiter_Acquire(t *testing.T) {
+	l := newActionLimiter(domainscript.Limits{ClicksPerMinute: 2})
+	start := time.Now()
+
+	if l.acquire(domainscript.ActionTypeClick, start) != 0 ||
+		l.acquire(domainscript.ActionTypeClick, start.Add(10*time.Second)) != 0 {
+		t.Fatal("clicks within the limit were held back")
+	}
+	if got := l.acquire(domainscript.ActionTypeClick, start.Add(20*time.Second)); got != 40*time.Second {
+		t.Errorf("third click wait = %v, want 40s until the first leaves the window", got)
+	}
+	if got := l.acquire(domainscript.ActionTypeClick, start.Add(time.Minute)); got != 0 {
+		t.Errorf("click after the window moved wait = %v, want 0", got)
+	}
+	if got := l.acquire(domainscript.ActionTypeClick, start.Add(time.Minute)); got != 10*time.Second {
+		t.Errorf("wait = %v, want 10s until the second leaves the window", got)
+	}
+
+	for range 100 {
+		if l.acquire(domainscript.ActionTypeDrag, start) != 0 {
+			t.Fatal("drags without a limit were held back")
+		}
+	}
+}
+
+func TestScriptRunner_CheckPatternRule(t *testing.T) {
+	bus := &recordingBus{}
+	client := &fakeOCRClient{texts: map[int]*ocr.TextResult{
+		0: {Lines: []ocr.TextLine{{Text: "Stamina", Confidence: 0.9}, {Text: "Gold: 1250", Confidence: 0.9}}},
+	}}
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, EventBus: bus, OCRClient: client})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "daily"}
+	r.ctx = context.Background()
+
+	rule := &domainscript.OCRRule{
+		Name:    domainscript.OCRRuleMatchPattern,
+		ROI:     domainscript.ROI{Width: 5, Height: 5},
+		Pattern: regexp.MustCompile(`Gold:\s*(?P<gold>\d+)`),
+	}
+	screen := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	if !r.checkTextRule(rule, screen) {
+		t.Fatal("checkTextRule() = false, want the pattern to match")
+	}
+	if r.counters["gold"] != 1250 {
+		t.Errorf("counters = %v, want gold 1250", r.counters)
+	}
+	recognized, ok := bus.events[len(bus.events)-1].(*event.OCRTextRecognized)
+	if !ok || recognized.Matched != "Gold: 1250" {
+		t.Errorf("event = %+v, want the matched text", bus.events[len(bus.events)-1])
+	}
+
+	rule.Pattern = regexp.MustCompile(`Silver: (?P<silver>\d+)`)
+	if r.checkTextRule(rule, screen) {
+		t.Error("checkTextRule() = true for a pattern the text doesn't match")
+	}
+}
+
+func TestScriptRunner_CheckNumberRule(t *testing.T) {
+	bus := &recordingBus{}
+	client := &fakeOCRClient{texts: map[int]*ocr.TextResult{
+		10: {Lines: []ocr.TextLine{{Text: "1,250", Confidence: 0.3}}},
+		20: {Lines: []ocr.TextLine{{Text: "Energy 8/120", Confidence: 0.9}}},
+	}}
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, EventBus: bus, OCRClient: client})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "daily"}
+	r.ctx = context.Background()
+	r.counters = map[string]int{"min_energy": 10}
+
+	rule := &domainscript.OCRRule{
+		Name:          domainscript.OCRRuleReadNumber,
+		ROI:           domainscript.ROI{X: 10, Width: 5, Height: 5},
+		FallbackROIs:  []domainscript.ROI{{X: 20, Width: 5, Height: 5}},
+		MinConfidence: 0.5,
+		Compare:       domainscript.CompareLess,
+		Against:       "min_energy",
+		Key:           "energy",
+	}
+	screen := image.NewRGBA(image.Rect(0, 0, 50, 50))
+	if !r.checkNumberRule(client, rule, screen) {
+		t.Error("checkNumberRule() = false, want 8 below min_energy 10")
+	}
+	if r.counters["energy"] != 8 {
+		t.Errorf("counters = %v, want energy 8 from the confident fallback", r.counters)
+	}
+	recognized, ok := bus.events[len(bus.events)-1].(*event.OCRResultRecognized)
+	if !ok || recognized.Numerator != 8 || recognized.Threshold != 10 || !recognized.Triggered {
+		t.Errorf("event = %+v, want 8 against 10, triggered", bus.events[len(bus.events)-1])
+	}
+
+	rule.Against, rule.Threshold = "", 5
+	if r.checkNumberRule(client, rule, screen) {
+		t.Error("checkNumberRule() = true, want 8 not below 5")
+	}
+	client.texts = nil
+	if r.checkNumberRule(client, rule, screen) {
+		t.Error("checkNumberRule() = true without a number")
+	}
+}
+
+func TestScriptRunner_RecognizeOCRNoConfidentROI(t *testing.T) {
+	client := &fakeOCRClient{results: map[int]*ocr.UsageRatioResult{
+		0: {Confidence: 0.1},
+	}}
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, EventBus: &recordingBus{}, OCRClient: client})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "daily"}
+	r.ctx = context.Background()
+
+	rule := &domainscript.OCRRule{Name: "quit_when_exhausted", MinConfidence: 0.5}
+	if result := r.recognizeOCR(client, "main", rule, image.NewRGBA(image.Rect(0, 0, 5, 5))); result != nil {
+		t.Errorf("recognizeOCR() = %+v, want nil", result)
+	}
+}
+
+func TestScriptRunner_OnStop(t *testing.T) {
+	driver := newMockDriver()
+	s := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, Driver: driver})
+	r := s.scriptRunner
+	r.script = &domainscript.Script{Name: "tower", OnStop: []domainscript.Action{
+		{Type: domainscript.ActionTypeClick, Points: []domainscript.Point{{X: 900, Y: 40}}},
+		{Type: domainscript.ActionTypeWait, Duration: time.Millisecond},
+	}}
+	// The run's own context is done once it is stopped manually
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.ctx = ctx
+
+	r.runOnStop(event.StopReasonManual)
+	if !driver.clickCalled || driver.lastClickX != 900 || driver.lastClickY != 40 {
+		t.Errorf("onStop click = %v at (%v, %v), want (900, 40)", driver.clickCalled, driver.lastClickX, driver.lastClickY)
+	}
+
+	driver.clickCalled = false
+	r.runOnStop(event.StopReasonBrowserStopped)
+	if driver.clickCalled {
+		t.Error("onStop actions ran after the browser stopped")
+	}
 }
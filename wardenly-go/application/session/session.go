@@ -3,9 +3,11 @@ package session
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"image"
 	"log/slog"
+	"maps"
 	"sync"
 	"time"
 
@@ -20,6 +22,9 @@ import (
 	"wardenly-go/infrastructure/ocr"
 )
 
+// ErrQueueFull is returned by Send when the command queue has no room.
+var ErrQueueFull = errors.New("command queue full")
+
 // Session represents a single browser session as an Actor.
 // It processes commands serially through a command queue, ensuring thread-safe state management.
 type Session struct {
@@ -37,23 +42,49 @@ type Session struct {
 	browserCtrl  *BrowserController
 	scriptRunner *ScriptRunner
 	screenCap    *ScreenCapture
+	latency      *LatencyTracker
 
 	// Dependencies
 	driver         browser.Driver
 	eventBus       eventbus.EventBus
 	sceneRegistry  *domainscene.Registry
-	sceneMatcher   *domainscene.Matcher
+	sceneMatcher   *domainscene.Matcher // Guarded by tuneMu
 	scriptRegistry *domainscript.Registry
 	ocrClient      ocr.Client
+	watchdog       WatchdogConfig
+	reconnect      ReconnectConfig
+	loginRetry     LoginRetryConfig
+	captcha        CaptchaConfig
+	loginURL       string
+	loginFlow      *LoginFlow
+	dataTap        *DataTap
+	frame          string
+	frameOrigin    *browser.Point
+	observeMatch   func(matched bool)
 	logger         *slog.Logger
 
+	// Live tuning; baseThreshold is the configured scene threshold
+	tuning        Tuning
+	baseThreshold float64
+	tuneMu        sync.RWMutex
+
+	// Latest values captured by the data tap
+	gameData   map[string]int
+	gameDataMu sync.Mutex
+
+	// Remembered prompt values per script, kept apart from the account,
+	// which other goroutines read
+	scriptParams   map[string]map[string]string
+	scriptParamsMu sync.Mutex
+
 	// Command processing
 	cmdChan chan command.Command
 	ctx     context.Context
 	cancel  context.CancelFunc
 	wg      sync.WaitGroup
 
-	// Screencast state
+	// Screencast state, also stopped by superviseBrowser
+	screencastMu     sync.Mutex
 	screencastActive bool
 	screencastCancel context.CancelFunc
 }
@@ -67,8 +98,43 @@ type Config struct {
 	SceneRegistry  *domainscene.Registry
 	ScriptRegistry *domainscript.Registry
 	OCRClient      ocr.Client
-	Logger         *slog.Logger
-	CommandBuffer  int
+	Watchdog       WatchdogConfig
+	// Reconnect restarts the browser when it crashes (disabled if zero)
+	Reconnect ReconnectConfig
+	// LoginRetry retries failed logins (tried once if zero)
+	LoginRetry LoginRetryConfig
+	// Captcha answers captchas shown during login (none if Solver is nil)
+	Captcha CaptchaConfig
+	// NearMisses, if set, records scenes that nearly match and may relax
+	// their thresholds; it is shared by all sessions
+	NearMisses *domainscene.NearMisses
+	// MatchPool, if set, checks large sets of scenes in parallel; it is
+	// shared by all sessions
+	MatchPool *domainscene.Pool
+	// LoginURL is the account's login page (browser.LoginURL with the
+	// default template if empty)
+	LoginURL string
+	// LoginFlow, if set, replaces the driver's built-in password login
+	LoginFlow *LoginFlow
+	// DataTap, if set, reads resource values from the game's API
+	// responses into script variables
+	DataTap *DataTap
+	// Frame and FrameOrigin locate the game in the page; see
+	// browser.LoginProfile. Without FrameOrigin, scene and script
+	// coordinates are used as viewport coordinates.
+	Frame       string
+	FrameOrigin *browser.Point
+	// Scale maps scene and script coordinates, recorded at
+	// browser.BaseCanvas, to the viewport; zero means 1
+	Scale browser.Point
+	// SceneThreshold is the matcher threshold of scenes without their own
+	// (domainscene.NewMatcher's default if zero)
+	SceneThreshold float64
+	// ObserveSceneMatch, if set, is told whether each frame a script checks
+	// matched one of its steps
+	ObserveSceneMatch func(matched bool)
+	Logger            *slog.Logger
+	CommandBuffer     int
 }
 
 // New creates a new Session actor.
@@ -86,13 +152,24 @@ func New(cfg *Config) *Session {
 		id:             cfg.ID,
 		accountID:      cfg.Account.ID,
 		account:        cfg.Account,
+		scriptParams:   maps.Clone(cfg.Account.ScriptParams),
 		state:          state.StateIdle,
 		driver:         cfg.Driver,
 		eventBus:       cfg.EventBus,
 		sceneRegistry:  cfg.SceneRegistry,
-		sceneMatcher:   domainscene.NewMatcher(5.0),
+		sceneMatcher:   domainscene.NewMatcher(cfg.SceneThreshold),
 		scriptRegistry: cfg.ScriptRegistry,
 		ocrClient:      cfg.OCRClient,
+		watchdog:       cfg.Watchdog,
+		reconnect:      cfg.Reconnect,
+		loginRetry:     cfg.LoginRetry,
+		captcha:        cfg.Captcha,
+		loginURL:       cfg.LoginURL,
+		loginFlow:      cfg.LoginFlow,
+		dataTap:        cfg.DataTap,
+		frame:          cfg.Frame,
+		frameOrigin:    cfg.FrameOrigin,
+		observeMatch:   cfg.ObserveSceneMatch,
 		logger:         cfg.Logger.With("session_id", cfg.ID),
 		cmdChan:        make(chan command.Command, cfg.CommandBuffer),
 		ctx:            ctx,
@@ -100,7 +177,17 @@ func New(cfg *Config) *Session {
 	}
 
 	// Initialize components
+	s.sceneMatcher.NearMisses = cfg.NearMisses
+	s.sceneMatcher.Source = cfg.ID
+	s.sceneMatcher.Pool = cfg.MatchPool
+	s.baseThreshold = s.sceneMatcher.Threshold
+	s.tuning = s.defaultTuning()
+	s.latency = NewLatencyTracker(LagThreshold)
 	s.browserCtrl = NewBrowserController(s.driver, s.logger)
+	s.browserCtrl.onRoundTrip = s.recordLatency
+	if cfg.Scale.X > 0 && cfg.Scale.Y > 0 {
+		s.browserCtrl.scale = cfg.Scale
+	}
 	s.screenCap = NewScreenCapture(s.driver, s.logger)
 	s.scriptRunner = NewScriptRunner(s, s.logger)
 
@@ -142,7 +229,7 @@ func (s *Session) Send(cmd command.Command) error {
 	case <-s.ctx.Done():
 		return fmt.Errorf("session is stopped")
 	default:
-		return fmt.Errorf("command queue full")
+		return ErrQueueFull
 	}
 }
 
@@ -175,9 +262,10 @@ func (s *Session) SelectedScript() string {
 	return s.selectedScript
 }
 
-// IsScriptRunning returns true if a script is currently running.
+// IsScriptRunning returns true if a script is currently running, paused
+// or not.
 func (s *Session) IsScriptRunning() bool {
-	return s.State() == state.StateScriptRunning
+	return s.State().CanStopScript()
 }
 
 // run is the main command processing loop.
@@ -201,9 +289,7 @@ func (s *Session) run() {
 // cleanup performs cleanup when the session stops.
 func (s *Session) cleanup() {
 	// Stop screencast if active
-	if s.screencastActive {
-		s.stopScreencast()
-	}
+	s.stopScreencast()
 
 	// Stop script if running
 	if s.IsScriptRunning() {
@@ -222,7 +308,7 @@ func (s *Session) cleanup() {
 
 // processCommand handles a single command.
 func (s *Session) processCommand(cmd command.Command) {
-	s.logger.Debug("Processing command", "command", cmd.CommandName())
+	s.logger.Debug("Processing command", "command", cmd.CommandName(), "correlation_id", command.CorrelationID(cmd))
 
 	switch c := cmd.(type) {
 	// Browser operations
@@ -230,10 +316,16 @@ func (s *Session) processCommand(cmd command.Command) {
 		s.handleClick(c)
 	case *command.Drag:
 		s.handleDrag(c)
+	case *command.Scroll:
+		s.handleScroll(c)
+	case *command.KeyPress:
+		s.handleKeyPress(c)
 	case *command.CaptureScreen:
 		s.handleCaptureScreen(c)
 	case *command.RefreshPage:
 		s.handleRefreshPage(c)
+	case *command.EvaluateJS:
+		s.handleEvaluateJS(c)
 	case *command.SaveCookies:
 		s.handleSaveCookies(c)
 
@@ -248,8 +340,16 @@ func (s *Session) processCommand(cmd command.Command) {
 		s.handleStartScript(c)
 	case *command.StopScript:
 		s.handleStopScript(c)
+	case *command.PauseScript:
+		s.handlePauseScript(c)
+	case *command.ResumeScript:
+		s.handleResumeScript(c)
+	case *command.StepScript:
+		s.handleStepScript(c)
 	case *command.SetScriptSelection:
 		s.handleSetScriptSelection(c)
+	case *command.TuneSession:
+		s.handleTuneSession(c)
 
 	// Session lifecycle
 	case *command.StopSession:
@@ -287,23 +387,29 @@ func (s *Session) publishEvent(e event.Event) {
 	}
 }
 
+// publishCommandEvent publishes an event caused by cmd, tagged with the
+// command's correlation ID.
+func (s *Session) publishCommandEvent(cmd command.Correlated, e event.Event) {
+	s.publishEvent(event.Correlate(e, cmd.CorrelationID()))
+}
+
 // Command handlers
 
 func (s *Session) handleClick(cmd *command.Click) {
 	if !s.State().CanAcceptOperations() {
-		s.logger.Warn("Cannot accept click in current state", "state", s.State())
+		s.logger.Warn("Cannot accept click in current state", "state", s.State(), "correlation_id", cmd.CorrelationID())
 		return
 	}
 
 	if err := s.browserCtrl.Click(s.ctx, cmd.X, cmd.Y); err != nil {
-		s.logger.Error("Click failed", "error", err)
-		s.publishEvent(event.NewOperationFailed(s.id, "click", err))
+		s.logger.Error("Click failed", "error", err, "correlation_id", cmd.CorrelationID())
+		s.publishCommandEvent(cmd, event.NewOperationFailed(s.id, "click", err))
 	}
 }
 
 func (s *Session) handleDrag(cmd *command.Drag) {
 	if !s.State().CanAcceptOperations() {
-		s.logger.Warn("Cannot accept drag in current state", "state", s.State())
+		s.logger.Warn("Cannot accept drag in current state", "state", s.State(), "correlation_id", cmd.CorrelationID())
 		return
 	}
 
@@ -324,60 +430,117 @@ func (s *Session) handleDrag(cmd *command.Drag) {
 			points[i] = browser.Point{X: p.X, Y: p.Y}
 			pathStr[i] = fmt.Sprintf("(%.1f, %.1f)", p.X, p.Y)
 		}
-		s.logger.Info("DragPath", "points", pathStr)
+		s.logger.Info("DragPath", "points", pathStr, "correlation_id", cmd.CorrelationID())
 		err = s.browserCtrl.DragPath(s.ctx, points)
 	}
 
 	if err != nil {
-		s.logger.Error("Drag failed", "error", err)
-		s.publishEvent(event.NewOperationFailed(s.id, "drag", err))
+		s.logger.Error("Drag failed", "error", err, "correlation_id", cmd.CorrelationID())
+		s.publishCommandEvent(cmd, event.NewOperationFailed(s.id, "drag", err))
+	}
+}
+
+func (s *Session) handleScroll(cmd *command.Scroll) {
+	if !s.State().CanAcceptOperations() {
+		s.logger.Warn("Cannot accept scroll in current state", "state", s.State(), "correlation_id", cmd.CorrelationID())
+		return
+	}
+
+	if err := s.browserCtrl.Scroll(s.ctx, cmd.X, cmd.Y, cmd.DeltaX, cmd.DeltaY); err != nil {
+		s.logger.Error("Scroll failed", "error", err, "correlation_id", cmd.CorrelationID())
+		s.publishCommandEvent(cmd, event.NewOperationFailed(s.id, "scroll", err))
+	}
+}
+
+func (s *Session) handleKeyPress(cmd *command.KeyPress) {
+	if !s.State().CanAcceptOperations() {
+		s.logger.Warn("Cannot accept key press in current state", "state", s.State(), "correlation_id", cmd.CorrelationID())
+		return
+	}
+
+	if err := s.browserCtrl.KeyPress(s.ctx, cmd.Key); err != nil {
+		s.logger.Error("Key press failed", "error", err, "key", cmd.Key, "correlation_id", cmd.CorrelationID())
+		s.publishCommandEvent(cmd, event.NewOperationFailed(s.id, "key_press", err))
 	}
 }
 
 func (s *Session) handleCaptureScreen(cmd *command.CaptureScreen) {
 	if !s.State().CanAcceptOperations() {
-		s.logger.Warn("Cannot capture screen in current state", "state", s.State())
+		s.logger.Warn("Cannot capture screen in current state", "state", s.State(), "correlation_id", cmd.CorrelationID())
 		return
 	}
 
 	img, err := s.screenCap.Capture(s.ctx)
 	if err != nil {
-		s.logger.Error("Screen capture failed", "error", err)
-		s.publishEvent(event.NewOperationFailed(s.id, "capture_screen", err))
+		s.logger.Error("Screen capture failed", "error", err, "correlation_id", cmd.CorrelationID())
+		s.publishCommandEvent(cmd, event.NewOperationFailed(s.id, "capture_screen", err))
 		return
 	}
 
 	if cmd.SaveToFile {
 		if err := s.screenCap.SaveToFile(img); err != nil {
-			s.logger.Error("Failed to save screenshot", "error", err)
+			s.logger.Error("Failed to save screenshot", "error", err, "correlation_id", cmd.CorrelationID())
 		}
 	}
 
-	s.publishEvent(event.NewScreenCaptured(s.id, img))
+	s.publishCommandEvent(cmd, event.NewScreenCaptured(s.id, img))
 }
 
 func (s *Session) handleRefreshPage(cmd *command.RefreshPage) {
 	if !s.State().CanAcceptOperations() {
-		s.logger.Warn("Cannot refresh in current state", "state", s.State())
+		s.logger.Warn("Cannot refresh in current state", "state", s.State(), "correlation_id", cmd.CorrelationID())
 		return
 	}
 
 	if err := s.browserCtrl.Refresh(s.ctx); err != nil {
-		s.logger.Error("Refresh failed", "error", err)
-		s.publishEvent(event.NewOperationFailed(s.id, "refresh", err))
+		s.logger.Error("Refresh failed", "error", err, "correlation_id", cmd.CorrelationID())
+		s.publishCommandEvent(cmd, event.NewOperationFailed(s.id, "refresh", err))
+		return
+	}
+	s.calibrateFrame()
+}
+
+func (s *Session) handleEvaluateJS(cmd *command.EvaluateJS) {
+	if !s.State().CanAcceptOperations() {
+		s.logger.Warn("Cannot evaluate script in current state", "state", s.State(), "correlation_id", cmd.CorrelationID())
+		s.publishCommandEvent(cmd, event.NewJSEvaluated(s.id, cmd.Expression, nil,
+			fmt.Errorf("cannot evaluate scripts while %s", s.State())))
+		return
+	}
+
+	result, err := s.browserCtrl.Evaluate(s.ctx, cmd.Expression)
+	if err != nil {
+		s.logger.Warn("Script evaluation failed", "error", err, "correlation_id", cmd.CorrelationID())
+	}
+	s.publishCommandEvent(cmd, event.NewJSEvaluated(s.id, cmd.Expression, result, err))
+}
+
+// calibrateFrame detects where the game frame is, so scene and script
+// coordinates follow it when the portal around the game changes. On
+// failure the previous shift is kept.
+func (s *Session) calibrateFrame() {
+	if s.frameOrigin == nil || s.frame == "" {
+		return
+	}
+	pos, err := s.browserCtrl.CalibrateFrame(s.ctx, s.frame, *s.frameOrigin)
+	if err != nil {
+		s.logger.Warn("Failed to detect game frame", "selector", s.frame, "error", err)
+		return
 	}
+	shift := s.browserCtrl.FrameShift()
+	s.logger.Info("Game frame detected", "x", pos.X, "y", pos.Y, "shift_x", shift.X, "shift_y", shift.Y)
 }
 
 func (s *Session) handleSaveCookies(cmd *command.SaveCookies) {
 	if !s.State().CanAcceptOperations() {
-		s.logger.Warn("Cannot save cookies in current state", "state", s.State())
+		s.logger.Warn("Cannot save cookies in current state", "state", s.State(), "correlation_id", cmd.CorrelationID())
 		return
 	}
 
 	cookies, err := s.browserCtrl.GetCookies(s.ctx)
 	if err != nil {
-		s.logger.Error("Failed to get cookies", "error", err)
-		s.publishEvent(event.NewOperationFailed(s.id, "save_cookies", err))
+		s.logger.Error("Failed to get cookies", "error", err, "correlation_id", cmd.CorrelationID())
+		s.publishCommandEvent(cmd, event.NewOperationFailed(s.id, "save_cookies", err))
 		return
 	}
 
@@ -394,23 +557,27 @@ func (s *Session) handleSaveCookies(cmd *command.SaveCookies) {
 			SourcePort:   c.SourcePort,
 			SourceScheme: c.SourceScheme,
 			Priority:     c.Priority,
+			Expires:      c.Expires,
 		}
 	}
 
 	s.account.Cookies = domainCookies
-	s.publishEvent(event.NewCookiesSaved(s.id))
-	s.logger.Info("Cookies captured", "count", len(cookies))
+	s.publishCommandEvent(cmd, event.NewCookiesSaved(s.id))
+	s.logger.Info("Cookies captured", "count", len(cookies), "correlation_id", cmd.CorrelationID())
 }
 
 func (s *Session) handleStartScreencast(cmd *command.StartScreencast) {
-	// Allow screencast in LoggingIn, Ready, or ScriptRunning states
-	// LoggingIn is allowed so users can see login progress/failures
+	// Allow screencast in LoggingIn, Ready, ScriptRunning or ScriptPaused
+	// states. LoggingIn is allowed so users can see login progress/failures
 	currentState := s.State()
-	if currentState != state.StateLoggingIn && currentState != state.StateReady && currentState != state.StateScriptRunning {
+	if !currentState.CanAcceptOperations() {
 		s.logger.Debug("Screencast not allowed in current state", "state", currentState)
 		return
 	}
 
+	s.screencastMu.Lock()
+	defer s.screencastMu.Unlock()
+
 	if s.screencastActive {
 		s.logger.Debug("Screencast already active")
 		return
@@ -419,8 +586,8 @@ func (s *Session) handleStartScreencast(cmd *command.StartScreencast) {
 	// Start screencast on driver
 	frameChan, err := s.driver.StartScreencast(s.ctx, cmd.Quality, cmd.MaxFPS)
 	if err != nil {
-		s.logger.Error("Failed to start screencast", "error", err)
-		s.publishEvent(event.NewOperationFailed(s.id, "start_screencast", err))
+		s.logger.Error("Failed to start screencast", "error", err, "correlation_id", cmd.CorrelationID())
+		s.publishCommandEvent(cmd, event.NewOperationFailed(s.id, "start_screencast", err))
 		return
 	}
 
@@ -435,8 +602,8 @@ func (s *Session) handleStartScreencast(cmd *command.StartScreencast) {
 	go s.forwardScreencastFrames(screencastCtx, frameChan)
 
 	// Publish event so UI knows screencast actually started
-	s.publishEvent(event.NewScreencastStarted(s.id, cmd.Quality, cmd.MaxFPS))
-	s.logger.Info("Screencast started", "quality", cmd.Quality, "maxFPS", cmd.MaxFPS)
+	s.publishCommandEvent(cmd, event.NewScreencastStarted(s.id, cmd.Quality, cmd.MaxFPS))
+	s.logger.Info("Screencast started", "quality", cmd.Quality, "maxFPS", cmd.MaxFPS, "correlation_id", cmd.CorrelationID())
 }
 
 func (s *Session) handleStopScreencast(cmd *command.StopScreencast) {
@@ -445,6 +612,9 @@ func (s *Session) handleStopScreencast(cmd *command.StopScreencast) {
 
 // stopScreencast stops the active screencast.
 func (s *Session) stopScreencast() {
+	s.screencastMu.Lock()
+	defer s.screencastMu.Unlock()
+
 	if !s.screencastActive {
 		return
 	}
@@ -480,6 +650,7 @@ func (s *Session) forwardScreencastFrames(ctx context.Context, frameChan <-chan
 				// Channel closed, screencast ended
 				return
 			}
+			s.screenCap.Record(img)
 			s.publishEvent(event.NewScreenCaptured(s.id, img))
 		}
 	}
@@ -487,62 +658,129 @@ func (s *Session) forwardScreencastFrames(ctx context.Context, frameChan <-chan
 
 // IsScreencasting returns true if screencast is active.
 func (s *Session) IsScreencasting() bool {
+	s.screencastMu.Lock()
+	defer s.screencastMu.Unlock()
 	return s.screencastActive
 }
 
 func (s *Session) handleStartScript(cmd *command.StartScript) {
 	if !s.State().CanStartScript() {
-		s.logger.Warn("Cannot start script in current state", "state", s.State())
+		s.logger.Warn("Cannot start script in current state", "state", s.State(), "correlation_id", cmd.CorrelationID())
 		return
 	}
 
 	script := s.scriptRegistry.Get(cmd.ScriptName)
 	if script == nil {
-		s.logger.Error("Script not found", "name", cmd.ScriptName)
+		s.logger.Error("Script not found", "name", cmd.ScriptName, "correlation_id", cmd.CorrelationID())
+		return
+	}
+
+	params, err := script.ResolveParams(cmd.Params, s.rememberedParams(cmd.ScriptName))
+	if err != nil {
+		s.logger.Error("Invalid script params", "name", cmd.ScriptName, "error", err, "correlation_id", cmd.CorrelationID())
+		s.publishCommandEvent(cmd, event.NewOperationFailed(s.id, "start_script", err))
 		return
 	}
+	if len(cmd.Params) > 0 {
+		s.rememberParams(cmd.ScriptName, params)
+	}
 
 	if err := s.transitionTo(state.StateScriptRunning); err != nil {
-		s.logger.Error("Failed to transition to script running state", "error", err)
+		s.logger.Error("Failed to transition to script running state", "error", err, "correlation_id", cmd.CorrelationID())
 		return
 	}
 
-	s.scriptRunner.Start(script)
-	s.publishEvent(event.NewScriptStarted(s.id, cmd.ScriptName))
+	s.scriptRunner.Start(script, params, cmd.CorrelationID(), cmd.Debug)
+	s.publishCommandEvent(cmd, event.NewScriptStarted(s.id, cmd.ScriptName))
+}
+
+// rememberedParams returns the prompt values last used for a script.
+func (s *Session) rememberedParams(scriptName string) map[string]string {
+	s.scriptParamsMu.Lock()
+	defer s.scriptParamsMu.Unlock()
+	return s.scriptParams[scriptName]
+}
+
+// rememberParams stores prompt values for later starts of a script.
+func (s *Session) rememberParams(scriptName string, params map[string]string) {
+	s.scriptParamsMu.Lock()
+	defer s.scriptParamsMu.Unlock()
+	if s.scriptParams == nil {
+		s.scriptParams = make(map[string]map[string]string)
+	}
+	s.scriptParams[scriptName] = maps.Clone(params)
 }
 
 func (s *Session) handleStopScript(cmd *command.StopScript) {
 	if !s.State().CanStopScript() {
-		s.logger.Warn("Cannot stop script in current state", "state", s.State())
+		s.logger.Warn("Cannot stop script in current state", "state", s.State(), "correlation_id", cmd.CorrelationID())
 		return
 	}
 
 	s.scriptRunner.Stop()
 }
 
+func (s *Session) handlePauseScript(cmd *command.PauseScript) {
+	if !s.State().CanPauseScript() {
+		s.logger.Warn("Cannot pause script in current state", "state", s.State(), "correlation_id", cmd.CorrelationID())
+		return
+	}
+
+	if !s.scriptRunner.Pause() {
+		return
+	}
+	// The run may have stopped meanwhile, leaving the session ready
+	if err := s.transitionTo(state.StateScriptPaused); err != nil {
+		s.logger.Warn("Failed to transition to script paused state", "error", err, "correlation_id", cmd.CorrelationID())
+		s.scriptRunner.Resume()
+	}
+}
+
+func (s *Session) handleResumeScript(cmd *command.ResumeScript) {
+	if !s.State().CanResumeScript() {
+		s.logger.Warn("Cannot resume script in current state", "state", s.State(), "correlation_id", cmd.CorrelationID())
+		return
+	}
+
+	if err := s.transitionTo(state.StateScriptRunning); err != nil {
+		s.logger.Warn("Failed to transition to script running state", "error", err, "correlation_id", cmd.CorrelationID())
+		return
+	}
+	s.scriptRunner.Resume()
+}
+
+func (s *Session) handleStepScript(cmd *command.StepScript) {
+	if !s.scriptRunner.Step(cmd.Continue) {
+		s.logger.Warn("No script is being debugged", "state", s.State(), "correlation_id", cmd.CorrelationID())
+	}
+}
+
 func (s *Session) handleSetScriptSelection(cmd *command.SetScriptSelection) {
 	s.stateMu.Lock()
 	s.selectedScript = cmd.ScriptName
 	s.stateMu.Unlock()
 
-	s.publishEvent(event.NewScriptSelectionChanged(s.id, cmd.ScriptName))
+	s.publishCommandEvent(cmd, event.NewScriptSelectionChanged(s.id, cmd.ScriptName))
 }
 
 func (s *Session) handleStopSession(cmd *command.StopSession) {
-	s.logger.Info("Stop session requested")
+	s.logger.Info("Stop session requested", "correlation_id", cmd.CorrelationID())
 	s.cancel()
 }
 
 // Methods called by ScriptRunner
 
-// OnScriptStopped is called when the script runner finishes.
-func (s *Session) OnScriptStopped(scriptName string, reason event.StopReason, err error) {
-	if s.State() == state.StateScriptRunning {
+// OnScriptStopped is called when the script runner finishes a run started
+// by the command with correlationID, with the counters it stopped with.
+func (s *Session) OnScriptStopped(scriptName, correlationID string, reason event.StopReason, err error, counters map[string]int) {
+	if s.State().CanStopScript() {
 		if transErr := s.transitionTo(state.StateReady); transErr != nil {
 			s.logger.Error("Failed to transition from script running", "error", transErr)
 		}
 	}
-	s.publishEvent(event.NewScriptStopped(s.id, scriptName, reason, err))
+	stopped := event.NewScriptStopped(s.id, scriptName, reason, err)
+	stopped.Counters = counters
+	s.publishEvent(event.Correlate(stopped, correlationID))
 }
 
 // GetScreenCapture returns the screen capture component.
@@ -560,8 +798,16 @@ func (s *Session) GetSceneRegistry() *domainscene.Registry {
 	return s.sceneRegistry
 }
 
-// GetSceneMatcher returns the scene matcher.
+// GetScriptRegistry returns the script registry.
+func (s *Session) GetScriptRegistry() *domainscript.Registry {
+	return s.scriptRegistry
+}
+
+// GetSceneMatcher returns the scene matcher. It is replaced when the
+// session is tuned, so callers should not keep it across checks.
 func (s *Session) GetSceneMatcher() *domainscene.Matcher {
+	s.tuneMu.RLock()
+	defer s.tuneMu.RUnlock()
 	return s.sceneMatcher
 }
 
@@ -592,26 +838,210 @@ func (s *Session) StartBrowser() error {
 
 	// Notify that browser driver is started and ready to render frames
 	s.publishEvent(event.NewDriverStarted(s.id))
+	s.tapGameData()
 
-	// Perform login (this would be async in real implementation)
-	go s.performLogin()
+	s.wg.Add(2)
+	go s.probeLatency()
+	go s.superviseBrowser()
 
 	return nil
 }
 
-func (s *Session) performLogin() {
-	// URL format for the game
-	url := fmt.Sprintf("http://www.lequ.com/server/wly/s/%d", s.account.ServerID)
+// superviseBrowser logs in, then restarts the browser and logs in again
+// whenever it exits on its own, until the session stops or runs out of
+// reconnect attempts.
+func (s *Session) superviseBrowser() {
+	defer s.wg.Done()
+
+	s.performLogin()
 
-	var loginErr error
-	if len(s.account.Cookies) > 0 {
-		// Login with cookies
-		s.logger.Info("Cookies not empty, try to login by cookies")
-		loginErr = s.loginWithCookies(url)
+	// Failed attempts in a row; a crash during the login that follows a
+	// restart counts against the same budget
+	attempts := 0
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.driver.Done():
+		}
+		if s.ctx.Err() != nil {
+			return // Stopping the session closes the browser too
+		}
+
+		s.logger.Warn("Browser exited unexpectedly")
+		s.releaseBrowser()
+
+		cause := errBrowserExited
+		for {
+			attempts++
+			if attempts > s.reconnect.MaxAttempts {
+				s.giveUp(attempts-1, cause)
+				return
+			}
+			loggedIn, err := s.reconnectBrowser(attempts, cause)
+			if s.ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				cause = err
+				continue
+			}
+			if loggedIn {
+				attempts = 0
+			}
+			break
+		}
+	}
+}
+
+// releaseBrowser stops what depends on a crashed browser, then releases
+// what is left of it.
+func (s *Session) releaseBrowser() {
+	if err := s.transitionTo(state.StateReconnecting); err != nil {
+		s.logger.Error("Failed to transition to reconnecting", "error", err)
+	}
+	s.stopScreencast()
+	if err := s.driver.Stop(); err != nil {
+		s.logger.Warn("Failed to release crashed browser", "error", err)
+	}
+	// After the driver, so the script reports that the browser stopped
+	s.scriptRunner.Stop()
+}
+
+// reconnectBrowser waits out the backoff of an attempt, restarts the
+// browser and logs in again. It returns an error only if the browser did
+// not start; a failed login leaves the session ready for manual use.
+func (s *Session) reconnectBrowser(attempt int, cause error) (bool, error) {
+	delay := s.reconnect.backoff(attempt)
+	s.logger.Info("Reconnecting browser", "attempt", attempt, "delay", delay, "cause", cause)
+	s.publishEvent(event.NewSessionReconnecting(s.id, attempt, delay, cause))
+
+	select {
+	case <-s.ctx.Done():
+		return false, s.ctx.Err()
+	case <-time.After(delay):
+	}
+
+	if err := s.driver.Start(s.ctx); err != nil {
+		s.logger.Warn("Failed to restart browser", "attempt", attempt, "error", err)
+		return false, fmt.Errorf("failed to start browser: %w", err)
+	}
+	if err := s.transitionTo(state.StateLoggingIn); err != nil {
+		return false, err
+	}
+	s.publishEvent(event.NewDriverStarted(s.id))
+	s.tapGameData()
+
+	loginErr := s.performLogin()
+	s.publishEvent(event.NewSessionReconnected(s.id, attempt))
+	s.logger.Info("Browser reconnected", "attempts", attempt, "logged_in", loginErr == nil)
+	return loginErr == nil, nil
+}
+
+// giveUp stops the session after the browser could not be brought back.
+func (s *Session) giveUp(attempts int, cause error) {
+	err := cause
+	if attempts > 0 {
+		err = fmt.Errorf("browser crashed and %d reconnect attempts failed: %w", attempts, cause)
+	}
+	s.logger.Error("Stopping session", "error", err)
+	s.publishEvent(event.NewSessionStopped(s.id, err))
+	s.cancel()
+}
+
+// probeLatency periodically measures page responsiveness and publishes the
+// rolling latency statistics until the session stops.
+func (s *Session) probeLatency() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(latencyProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Skip login, where page loads would read as lag
+		if st := s.State(); st != state.StateReady && st != state.StateScriptRunning && st != state.StateScriptPaused {
+			continue
+		}
+		if _, err := s.browserCtrl.Ping(s.ctx); err != nil {
+			s.logger.Debug("Latency probe failed", "error", err)
+			continue
+		}
+
+		stats := s.latency.Stats()
+		s.publishEvent(event.NewLatencyUpdated(s.id, stats.Samples, stats.Mean, stats.P95, stats.Max, stats.Lagging))
+	}
+}
+
+// recordLatency adds a browser round trip and announces lag changes.
+func (s *Session) recordLatency(d time.Duration) {
+	if !s.latency.Record(d) {
+		return
+	}
+
+	stats := s.latency.Stats()
+	if stats.Lagging {
+		s.logger.Warn("Input latency is high, server may be lagging", "mean", stats.Mean, "p95", stats.P95)
 	} else {
-		// Login with username/password
+		s.logger.Info("Input latency recovered", "mean", stats.Mean, "p95", stats.P95)
+	}
+	s.publishEvent(event.NewInputLagChanged(s.id, stats.Lagging, stats.Mean, stats.P95))
+}
+
+// Latency returns the session's rolling browser latency statistics.
+func (s *Session) Latency() LatencyStats {
+	return s.latency.Stats()
+}
+
+// Lagging returns true while the session's input latency is high.
+func (s *Session) Lagging() bool {
+	return s.latency != nil && s.latency.Lagging()
+}
+
+// performLogin logs in and moves the session to Ready, even when the login
+// fails so the user can operate manually. A failed attempt is retried as
+// set by LoginRetryConfig, with the password once cookies have failed. It
+// returns the login error.
+func (s *Session) performLogin() error {
+	url := s.loginURL
+	if url == "" {
+		url = browser.LoginURL("", s.account.ServerID)
+	}
+
+	usePassword := true
+	switch {
+	case !s.account.HasCookies():
 		s.logger.Info("Cookies empty, try to login by user password")
-		loginErr = s.loginWithUserPassword(url)
+	case s.account.CookiesExpired(time.Now()):
+		// Expired cookies would only time out, so skip straight to the password
+		s.logger.Info("Cookies expired, try to login by user password")
+	default:
+		s.logger.Info("Cookies not empty, try to login by cookies")
+		usePassword = false
+	}
+
+	loginErr := s.attemptLogin(url, usePassword)
+	for retry := 1; loginErr != nil && retry < s.loginRetry.MaxAttempts; retry++ {
+		if s.ctx.Err() != nil {
+			break
+		}
+		if !usePassword && s.account.Password != "" {
+			usePassword = true // The cookies may have been revoked
+		}
+		delay := s.loginRetry.backoff(retry)
+		s.logger.Warn("Login failed, retrying", "attempt", retry, "delay", delay, "password", usePassword, "error", loginErr)
+		s.publishEvent(event.NewLoginRetrying(s.id, retry, delay, usePassword, loginErr))
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-time.After(delay):
+		}
+		loginErr = s.attemptLogin(url, usePassword)
 	}
 
 	if loginErr != nil {
@@ -621,18 +1051,7 @@ func (s *Session) performLogin() {
 		if err := s.transitionTo(state.StateReady); err != nil {
 			s.logger.Error("Failed to transition to ready after login failure", "error", err)
 		}
-		return
-	}
-
-	// Wait for game to fully load
-	if err := s.waitLoadingGame(); err != nil {
-		s.logger.Error("Wait loading game failed", "error", err)
-		s.publishEvent(event.NewLoginFailed(s.id, err))
-		// Even on wait failure, transition to Ready state so user can manually operate
-		if err := s.transitionTo(state.StateReady); err != nil {
-			s.logger.Error("Failed to transition to ready after wait failure", "error", err)
-		}
-		return
+		return loginErr
 	}
 
 	// Login successful - save cookies
@@ -645,10 +1064,33 @@ func (s *Session) performLogin() {
 	// Transition to ready state
 	if err := s.transitionTo(state.StateReady); err != nil {
 		s.logger.Error("Failed to transition to ready", "error", err)
-		return
+		return err
 	}
 	s.publishEvent(event.NewLoginSucceeded(s.id))
 	s.logger.Info("Login successful")
+	return nil
+}
+
+// attemptLogin logs in once, with the password or the stored cookies, and
+// waits for the game to load.
+func (s *Session) attemptLogin(url string, usePassword bool) error {
+	var err error
+	if usePassword {
+		err = s.loginWithUserPassword(url)
+	} else {
+		err = s.loginWithCookies(url)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Wait for game to fully load
+	s.calibrateFrame()
+	if err := s.waitLoadingGame(); err != nil {
+		s.logger.Warn("Wait loading game failed", "error", err)
+		return err
+	}
+	return nil
 }
 
 // loginWithCookies attempts to login using stored cookies.
@@ -666,11 +1108,12 @@ func (s *Session) loginWithCookies(url string) error {
 			SourcePort:   c.SourcePort,
 			SourceScheme: c.SourceScheme,
 			Priority:     c.Priority,
+			Expires:      c.Expires,
 		}
 	}
 
 	// Use the driver's LoginWithCookies method which executes all steps in one chromedp.Run
-	if err := s.driver.LoginWithCookies(url, browserCookies, 20); err != nil {
+	if err := s.driver.LoginWithCookies(s.ctx, url, browserCookies, 20); err != nil {
 		return err
 	}
 
@@ -678,10 +1121,17 @@ func (s *Session) loginWithCookies(url string) error {
 	return nil
 }
 
-// loginWithUserPassword attempts to login using username and password.
+// loginWithUserPassword attempts to login using username and password,
+// with the configured login flow if there is one.
 func (s *Session) loginWithUserPassword(url string) error {
-	// Use the driver's LoginWithPassword method which executes all steps in one chromedp.Run
-	if err := s.driver.LoginWithPassword(url, s.account.UserName, s.account.Password, 20); err != nil {
+	var err error
+	if s.loginFlow != nil {
+		err = s.loginFlow.run(s.ctx, s.driver, url, s.account.UserName, s.account.Password, 20*time.Second)
+	} else {
+		// Use the driver's LoginWithPassword method which executes all steps in one chromedp.Run
+		err = s.driver.LoginWithPassword(s.ctx, url, s.account.UserName, s.account.Password, 20)
+	}
+	if err != nil {
 		return err
 	}
 
@@ -690,6 +1140,7 @@ func (s *Session) loginWithUserPassword(url string) error {
 }
 
 // waitLoadingGame waits for the game to fully load by detecting known scenes.
+// A captcha shown meanwhile is passed to the captcha solver, if any.
 func (s *Session) waitLoadingGame() error {
 	const maxAttempts = 10
 	const waitInterval = 2 * time.Second
@@ -707,17 +1158,30 @@ func (s *Session) waitLoadingGame() error {
 		if err != nil {
 			continue
 		}
+		frame := s.browserCtrl.InFrame()
+		img = frame.FrameScreen(img)
 
 		// Check for known scenes
-		scene := s.sceneRegistry.FindMatch(img, s.sceneMatcher, "user_agreement", "main_city")
+		names := []string{"user_agreement", "main_city"}
+		if s.captcha.Solver != nil {
+			names = append(names, s.captcha.scene())
+		}
+		scene := s.sceneRegistry.FindMatch(img, s.GetSceneMatcher(), names...)
 		if scene == nil {
 			continue
 		}
 
+		if s.captcha.Solver != nil && scene.Name == s.captcha.scene() {
+			if err := s.solveCaptcha(frame, img, scene); err != nil {
+				return err
+			}
+			continue
+		}
+
 		if scene.Name == "user_agreement" {
 			// Click agree button
 			if action, ok := scene.Actions["Agree"]; ok {
-				if err := s.browserCtrl.Click(s.ctx, action.Point.X, action.Point.Y); err != nil {
+				if err := frame.Click(s.ctx, action.Point.X, action.Point.Y); err != nil {
 					s.logger.Warn("Click agreement failed", "error", err)
 					continue
 				}
@@ -753,6 +1217,7 @@ func (s *Session) saveCookiesAfterLogin() error {
 			SourcePort:   c.SourcePort,
 			SourceScheme: c.SourceScheme,
 			Priority:     c.Priority,
+			Expires:      c.Expires,
 		}
 	}
 
@@ -1,10 +1,23 @@
 package session
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"maps"
+	"slices"
 	"testing"
+	"time"
 
+	"wardenly-go/core/command"
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
 	"wardenly-go/core/state"
 	"wardenly-go/domain/account"
+	domainscene "wardenly-go/domain/scene"
+	"wardenly-go/infrastructure/browser"
 )
 
 func TestConfig_Defaults(t *testing.T) {
@@ -45,6 +58,66 @@ func TestSession_Identity(t *testing.T) {
 	}
 }
 
+func TestSession_RememberParams(t *testing.T) {
+	acc := &account.Account{ID: "a1", ScriptParams: map[string]map[string]string{"daily": {"runs": "3"}}}
+	sess := New(&Config{ID: "s1", Account: acc})
+
+	if got := sess.rememberedParams("daily"); got["runs"] != "3" {
+		t.Errorf("rememberedParams() = %v, want the account's values", got)
+	}
+	sess.rememberParams("daily", map[string]string{"runs": "5"})
+	sess.rememberParams("arena", map[string]string{"rounds": "2"})
+	if got := sess.rememberedParams("daily"); got["runs"] != "5" {
+		t.Errorf("rememberedParams() = %v, want the new values", got)
+	}
+	// The account is shared with other goroutines and must stay untouched
+	if acc.ScriptParams["daily"]["runs"] != "3" || len(acc.ScriptParams) != 1 {
+		t.Errorf("account ScriptParams = %v, want it unchanged", acc.ScriptParams)
+	}
+}
+
+func TestLatencyTracker(t *testing.T) {
+	tracker := NewLatencyTracker(100 * time.Millisecond)
+
+	for range latencyRecent {
+		if tracker.Record(20 * time.Millisecond) {
+			t.Fatal("fast round trips should not start lag")
+		}
+	}
+
+	changed := false
+	for range latencyRecent {
+		changed = tracker.Record(300*time.Millisecond) || changed
+	}
+	if !changed || !tracker.Lagging() {
+		t.Fatal("slow round trips should start lag")
+	}
+
+	// Still above half the threshold: keeps lagging
+	if tracker.Record(80 * time.Millisecond) {
+		t.Error("lag should not end above half the threshold")
+	}
+	for range latencyRecent {
+		tracker.Record(10 * time.Millisecond)
+	}
+	if tracker.Lagging() {
+		t.Error("lag should end once recent round trips are fast")
+	}
+
+	stats := tracker.Stats()
+	if stats.Samples != 3*latencyRecent+1 || stats.Max != 300*time.Millisecond {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	// The window keeps only the latest samples
+	for range latencyWindow {
+		tracker.Record(time.Millisecond)
+	}
+	if stats := tracker.Stats(); stats.Samples != latencyWindow || stats.Max != time.Millisecond || stats.P95 != time.Millisecond {
+		t.Errorf("Stats() after a full window = %+v", stats)
+	}
+}
+
 func TestStateTransitions(t *testing.T) {
 	// Test state transition logic used by Session
 	tests := []struct {
@@ -105,3 +178,534 @@ func TestStateHelpers(t *testing.T) {
 		})
 	}
 }
+
+func TestReconnectConfig_Backoff(t *testing.T) {
+	cfg := ReconnectConfig{MaxAttempts: 5, Delay: time.Second, MaxDelay: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := cfg.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestParseLoginFlow(t *testing.T) {
+	flow, err := ParseLoginFlow([]byte(`
+steps:
+  - type: navigate
+    url: "{url}?lang=en"
+  - type: click
+    selector: "a.password-tab"
+  - type: sendKeys
+    selector: "input[name=user]"
+    text: "{username}"
+`))
+	if err != nil || len(flow.Steps) != 3 || flow.Steps[1].Selector != "a.password-tab" {
+		t.Fatalf("ParseLoginFlow() = %+v, %v", flow, err)
+	}
+
+	invalid := map[string]string{
+		"no steps":         "steps: []",
+		"unknown type":     "steps:\n  - type: hover\n    selector: a",
+		"missing selector": "steps:\n  - type: click",
+		"missing url":      "steps:\n  - type: navigate",
+		"unknown key":      "steps:\n  - type: click\n    selectr: a",
+	}
+	for name, data := range invalid {
+		if _, err := ParseLoginFlow([]byte(data)); err == nil {
+			t.Errorf("%s: ParseLoginFlow() should fail", name)
+		}
+	}
+	if err := DefaultLoginFlow(browser.DefaultLoginProfile()).Validate(); err != nil {
+		t.Errorf("default flow: %v", err)
+	}
+}
+
+func TestParseDataTap(t *testing.T) {
+	tap, err := ParseDataTap([]byte(`
+responses:
+  - url: "*/api/player/info*"
+    values:
+      energy: data.energy
+      bag_slots: data.bags.0.free
+`))
+	if err != nil || len(tap.Responses) != 1 || tap.Responses[0].Values["energy"] != "data.energy" {
+		t.Fatalf("ParseDataTap() = %+v, %v", tap, err)
+	}
+
+	invalid := map[string]string{
+		"no responses": "responses: []",
+		"missing url":  "responses:\n  - values: {energy: data.energy}",
+		"no values":    "responses:\n  - url: \"*\"",
+		"bad variable": "responses:\n  - url: \"*\"\n    values: {2x: data.energy}",
+		"unknown key":  "responses:\n  - url: \"*\"\n    value: {energy: data.energy}",
+		"missing path": "responses:\n  - url: \"*\"\n    values: {energy: \"\"}",
+	}
+	for name, data := range invalid {
+		if _, err := ParseDataTap([]byte(data)); err == nil {
+			t.Errorf("%s: ParseDataTap() should fail", name)
+		}
+	}
+}
+
+func TestDataTapRule_Extract(t *testing.T) {
+	rule := DataTapRule{URL: "*", Values: map[string]string{
+		"energy":  "data.energy",
+		"gold":    "data.gold",
+		"slots":   "data.bags.1.free",
+		"vip":     "data.vip",
+		"ratio":   "data.ratio",
+		"missing": "data.nothing",
+		"name":    "data.name",
+	}}
+	body := `{"data":{"energy":42,"gold":"1200","bags":[{"free":1},{"free":7}],"vip":true,"ratio":2.5,"name":"alice"}}`
+	want := map[string]int{"energy": 42, "gold": 1200, "slots": 7, "vip": 1, "ratio": 2}
+	if got := rule.Extract([]byte(body)); !maps.Equal(got, want) {
+		t.Errorf("Extract() = %v, want %v", got, want)
+	}
+	if got := rule.Extract([]byte("<html>")); len(got) != 0 {
+		t.Errorf("Extract() of a non-JSON body = %v", got)
+	}
+}
+
+// tapDriver delivers a fixed set of responses to every watch.
+type tapDriver struct {
+	*mockDriver
+	responses []browser.Response
+}
+
+func (d *tapDriver) WatchResponses(ctx context.Context, pattern string) (<-chan browser.Response, error) {
+	ch := make(chan browser.Response, len(d.responses))
+	for _, resp := range d.responses {
+		if browser.MatchURL(pattern, resp.URL) {
+			ch <- resp
+		}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestSession_TapGameData(t *testing.T) {
+	driver := &tapDriver{mockDriver: newMockDriver(), responses: []browser.Response{
+		{URL: "http://s7/api/player", Status: 200, Body: []byte(`{"energy":30}`)},
+		{URL: "http://s7/api/bag", Status: 200, Body: []byte(`{"free":4}`)},
+		{URL: "http://s7/api/player", Status: 200, Body: []byte(`{"energy":25}`)},
+	}}
+	bus := &recordingBus{}
+	s := New(&Config{
+		ID:       "s1",
+		Account:  &account.Account{ID: "a1"},
+		Driver:   driver,
+		EventBus: bus,
+		DataTap: &DataTap{Responses: []DataTapRule{
+			{URL: "*/api/player", Values: map[string]string{"energy": "energy"}},
+		}},
+	})
+
+	s.tapGameData()
+	s.wg.Wait()
+	if got := s.GameData(); !maps.Equal(got, map[string]int{"energy": 25}) {
+		t.Errorf("GameData() = %v, want the latest energy", got)
+	}
+	captured := 0
+	for _, e := range bus.events {
+		if e, ok := e.(*event.GameDataCaptured); ok && e.URL == "http://s7/api/player" {
+			captured++
+		}
+	}
+	if captured != 2 {
+		t.Errorf("captured %d responses, want 2", captured)
+	}
+}
+
+// evalDriver answers every evaluation with a fixed result.
+type evalDriver struct {
+	*mockDriver
+	result string
+}
+
+func (d *evalDriver) Evaluate(ctx context.Context, expression string) (json.RawMessage, error) {
+	return json.RawMessage(d.result), nil
+}
+
+func TestSession_EvaluateJS(t *testing.T) {
+	bus := &recordingBus{}
+	s := New(&Config{
+		ID:       "s1",
+		Account:  &account.Account{ID: "a1"},
+		Driver:   &evalDriver{mockDriver: newMockDriver(), result: `{"width":1080,"height":720}`},
+		EventBus: bus,
+	})
+
+	s.handleEvaluateJS(command.NewEvaluateJS("s1", "document.body.scrollWidth"))
+	s.state = state.StateReady
+	s.handleEvaluateJS(command.NewEvaluateJS("s1", "({width: innerWidth, height: innerHeight})"))
+
+	var results []*event.JSEvaluated
+	for _, e := range bus.events {
+		if e, ok := e.(*event.JSEvaluated); ok {
+			results = append(results, e)
+		}
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if results[0].Error == nil {
+		t.Error("evaluating before the browser is ready should fail")
+	}
+	if results[1].Error != nil || string(results[1].Result) != `{"width":1080,"height":720}` {
+		t.Errorf("result = %s, %v", results[1].Result, results[1].Error)
+	}
+	if v, ok := rawJSONInt(json.RawMessage("1080")); !ok || v != 1080 {
+		t.Errorf("rawJSONInt() = %d, %v", v, ok)
+	}
+}
+
+// flowDriver records the page operations of a login flow.
+type flowDriver struct {
+	*mockDriver
+	ops     []string
+	failing string
+}
+
+func (d *flowDriver) record(op string) error {
+	d.ops = append(d.ops, op)
+	if op == d.failing {
+		return errors.New("no such element")
+	}
+	return nil
+}
+func (d *flowDriver) Navigate(ctx context.Context, url string) error {
+	return d.record("navigate " + url)
+}
+func (d *flowDriver) WaitVisible(ctx context.Context, selector string) error {
+	return d.record("wait " + selector)
+}
+func (d *flowDriver) SendKeys(ctx context.Context, selector, text string) error {
+	return d.record("keys " + selector + " " + text)
+}
+func (d *flowDriver) ClickElement(ctx context.Context, selector string) error {
+	return d.record("click " + selector)
+}
+
+func TestSession_LoginFlow(t *testing.T) {
+	driver := &flowDriver{mockDriver: newMockDriver()}
+	profile := browser.LoginProfile{Username: "#u", Password: "#p", Submit: "#go", Ready: "#game"}
+	s := New(&Config{
+		ID:        "s1",
+		Account:   &account.Account{ID: "a1", UserName: "alice", Password: "secret"},
+		Driver:    driver,
+		LoginFlow: DefaultLoginFlow(profile),
+	})
+
+	if err := s.loginWithUserPassword("http://portal/s/7"); err != nil {
+		t.Fatalf("loginWithUserPassword() error = %v", err)
+	}
+	want := []string{"navigate http://portal/s/7", "wait #u", "keys #u alice", "keys #p secret", "click #go", "wait #game"}
+	if !slices.Equal(driver.ops, want) {
+		t.Errorf("ops = %q, want %q", driver.ops, want)
+	}
+	if driver.passwordLogins != 0 {
+		t.Error("the driver's built-in login should not run with a flow")
+	}
+
+	driver.ops, driver.failing = nil, "click #go"
+	if err := s.loginWithUserPassword("http://portal/s/7"); err == nil || len(driver.ops) != 5 {
+		t.Errorf("a failing step should stop the flow: %v after %q", err, driver.ops)
+	}
+}
+
+func TestSession_LoginRetriesWithPassword(t *testing.T) {
+	scenes := domainscene.NewRegistry()
+	scenes.Register(&domainscene.Scene{
+		Name:   "main_city",
+		Points: []domainscene.Point{{X: 1, Y: 1, Color: color.RGBA{A: 255}}},
+	})
+	driver := newMockDriver()
+	driver.cookieLoginErr = errors.New("server busy")
+	bus := &recordingBus{}
+	s := New(&Config{
+		ID: "s1",
+		Account: &account.Account{ID: "a1", Password: "secret",
+			Cookies: []account.Cookie{{Name: "sid", Value: "stored"}}},
+		Driver:        driver,
+		EventBus:      bus,
+		SceneRegistry: scenes,
+		LoginRetry:    LoginRetryConfig{MaxAttempts: 3, Delay: time.Millisecond},
+	})
+	s.state = state.StateLoggingIn
+
+	if err := s.performLogin(); err != nil {
+		t.Fatalf("performLogin() error = %v", err)
+	}
+	var retries []*event.LoginRetrying
+	succeeded := false
+	for _, e := range bus.events {
+		switch e := e.(type) {
+		case *event.LoginRetrying:
+			retries = append(retries, e)
+		case *event.LoginSucceeded:
+			succeeded = true
+		}
+	}
+	if len(retries) != 1 || retries[0].Attempt != 1 || !retries[0].Password || retries[0].Error == nil {
+		t.Errorf("retries = %+v, want one retry with the password", retries)
+	}
+	if !succeeded || driver.passwordLogins != 1 {
+		t.Errorf("succeeded = %v after %d password logins, want the retry to log in", succeeded, driver.passwordLogins)
+	}
+}
+
+// replayAccount returns the account logged in by newReplaySession tests.
+func replayAccount() *account.Account {
+	return &account.Account{ID: "a1", ServerID: 1, RoleName: "Alice", UserName: "alice"}
+}
+
+// newReplaySession starts a session whose replay frames show main_city, so
+// logins finish after the first load check.
+func newReplaySession(t *testing.T, acc *account.Account, reconnect ReconnectConfig) (*Session, *browser.ReplayDriver, <-chan event.Event) {
+	t.Helper()
+
+	scenes := domainscene.NewRegistry()
+	scenes.Register(&domainscene.Scene{
+		Name:   "main_city",
+		Points: []domainscene.Point{{X: 1, Y: 1, Color: color.RGBA{A: 255}}},
+	})
+	driver := browser.NewReplayDriver(&browser.ReplayDriverConfig{
+		Frames: []image.Image{image.NewRGBA(image.Rect(0, 0, 10, 10))},
+	})
+
+	bus := eventbus.New(100)
+	t.Cleanup(bus.Close)
+	events := make(chan event.Event, 100)
+	bus.Subscribe(func(e event.Event) { events <- e })
+
+	sess := New(&Config{
+		ID:            "s1",
+		Account:       acc,
+		Driver:        driver,
+		EventBus:      bus,
+		SceneRegistry: scenes,
+		Reconnect:     reconnect,
+	})
+	sess.Start()
+	t.Cleanup(sess.Stop)
+	if err := sess.StartBrowser(); err != nil {
+		t.Fatalf("StartBrowser() error = %v", err)
+	}
+	return sess, driver, events
+}
+
+// waitEvent returns the first event accepted by match.
+func waitEvent(t *testing.T, events <-chan event.Event, match func(event.Event) bool) event.Event {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case e := <-events:
+			if match(e) {
+				return e
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for event")
+			return nil
+		}
+	}
+}
+
+func TestSession_LoginSkipsExpiredCookies(t *testing.T) {
+	tests := []struct {
+		name    string
+		expires time.Time
+		want    string // cookie left in the browser after login
+	}{
+		{"valid cookies", time.Now().Add(time.Hour), "sid"},
+		{"session cookies", time.Time{}, "sid"},
+		{"expired cookies", time.Now().Add(-time.Hour), "replay_session"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := replayAccount()
+			acc.Password = "secret"
+			acc.Cookies = []account.Cookie{{Name: "sid", Value: "stored", Expires: tt.expires}}
+			_, driver, events := newReplaySession(t, acc, ReconnectConfig{})
+
+			waitEvent(t, events, func(e event.Event) bool { _, ok := e.(*event.LoginSucceeded); return ok })
+			cookies, err := driver.GetCookies(context.Background())
+			if err != nil || len(cookies) != 1 || cookies[0].Name != tt.want {
+				t.Errorf("cookies after login = %+v, %v; want %s", cookies, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_ReconnectAfterCrash(t *testing.T) {
+	reconnect := ReconnectConfig{MaxAttempts: 3, Delay: 10 * time.Millisecond, MaxDelay: time.Second}
+	sess, driver, events := newReplaySession(t, replayAccount(), reconnect)
+
+	waitEvent(t, events, func(e event.Event) bool { _, ok := e.(*event.LoginSucceeded); return ok })
+	driver.Crash()
+
+	e := waitEvent(t, events, func(e event.Event) bool { _, ok := e.(*event.SessionReconnecting); return ok })
+	if r := e.(*event.SessionReconnecting); r.Attempt != 1 || r.Error == nil {
+		t.Errorf("SessionReconnecting = %+v, want attempt 1 with a cause", r)
+	}
+	waitEvent(t, events, func(e event.Event) bool {
+		c, ok := e.(*event.SessionStateChanged)
+		return ok && c.OldState == state.StateLoggingIn && c.NewState == state.StateReady
+	})
+	waitEvent(t, events, func(e event.Event) bool { _, ok := e.(*event.SessionReconnected); return ok })
+
+	if !driver.IsRunning() || sess.State() != state.StateReady {
+		t.Errorf("after reconnect: running = %v, state = %v", driver.IsRunning(), sess.State())
+	}
+}
+
+func TestSession_ReconnectDisabled(t *testing.T) {
+	sess, driver, events := newReplaySession(t, replayAccount(), ReconnectConfig{})
+
+	waitEvent(t, events, func(e event.Event) bool { _, ok := e.(*event.LoginSucceeded); return ok })
+	driver.Crash()
+
+	e := waitEvent(t, events, func(e event.Event) bool { _, ok := e.(*event.SessionStopped); return ok })
+	if e.(*event.SessionStopped).Error == nil {
+		t.Error("SessionStopped should carry the crash")
+	}
+	select {
+	case <-sess.Context().Done():
+	case <-time.After(time.Second):
+		t.Error("session should stop itself")
+	}
+}
+
+func TestSession_TuneSession(t *testing.T) {
+	bus := eventbus.New(100)
+	t.Cleanup(bus.Close)
+	events := make(chan event.Event, 100)
+	bus.Subscribe(func(e event.Event) { events <- e })
+
+	sess := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, EventBus: bus, SceneThreshold: 6})
+	matcher := sess.GetSceneMatcher()
+
+	threshold, poll, pixels := 8.5, 200*time.Millisecond, 3.0
+	cmd := command.NewTuneSession("s1")
+	cmd.SceneThreshold, cmd.PollInterval, cmd.JitterPixels = &threshold, &poll, &pixels
+	sess.handleTuneSession(cmd)
+
+	got := sess.Tuning()
+	if got.SceneThreshold != 8.5 || got.PollInterval != poll || got.Jitter == nil || got.Jitter.Pixels != 3 || got.Jitter.Wait != 0 {
+		t.Fatalf("Tuning() = %+v", got)
+	}
+	if sess.GetSceneMatcher().Threshold != 8.5 || matcher.Threshold != 6 {
+		t.Errorf("matcher thresholds = %v, old %v; want 8.5, old 6", sess.GetSceneMatcher().Threshold, matcher.Threshold)
+	}
+	if sess.GetSceneMatcher().Source != "s1" {
+		t.Errorf("tuned matcher Source = %q, want s1", sess.GetSceneMatcher().Source)
+	}
+	tuned := waitEvent(t, events, func(e event.Event) bool { _, ok := e.(*event.SessionTuned); return ok }).(*event.SessionTuned)
+	if tuned.SceneThreshold != 8.5 || !tuned.JitterOverride || tuned.JitterPixels != 3 {
+		t.Errorf("SessionTuned = %+v", tuned)
+	}
+
+	// Invalid values leave the tuning unchanged
+	wait := 1.5
+	bad := command.NewTuneSession("s1")
+	bad.JitterWait = &wait
+	sess.handleTuneSession(bad)
+	waitEvent(t, events, func(e event.Event) bool { _, ok := e.(*event.OperationFailed); return ok })
+	if got := sess.Tuning(); got.Jitter == nil || got.Jitter.Wait != 0 {
+		t.Errorf("Tuning() after invalid wait = %+v", got)
+	}
+
+	reset := command.NewTuneSession("s1")
+	reset.Reset = true
+	sess.handleTuneSession(reset)
+	if got := sess.Tuning(); got.SceneThreshold != 6 || got.PollInterval != DefaultPollInterval || got.Jitter != nil {
+		t.Errorf("Tuning() after reset = %+v", got)
+	}
+	if sess.GetSceneMatcher().Threshold != 6 {
+		t.Errorf("matcher threshold after reset = %v, want 6", sess.GetSceneMatcher().Threshold)
+	}
+}
+
+func TestSession_CheckScenes(t *testing.T) {
+	registry := domainscene.NewRegistry()
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+	registry.Register(&domainscene.Scene{Name: "home", Points: []domainscene.Point{{X: 10, Y: 10, Color: red}, {X: 20, Y: 20, Color: red}}})
+	registry.Register(&domainscene.Scene{Name: "shop", Points: []domainscene.Point{{X: 10, Y: 10, Color: red}, {X: 30, Y: 30, Color: red}}})
+	registry.Register(&domainscene.Scene{Name: "map", Points: []domainscene.Point{{X: 10, Y: 10, Color: blue}}})
+	registry.Register(&domainscene.Scene{Name: "empty"})
+
+	sess := New(&Config{ID: "s1", Account: &account.Account{ID: "a1"}, SceneRegistry: registry, SceneThreshold: 5})
+	frame := image.NewRGBA(image.Rect(0, 0, 50, 50))
+	frame.Set(10, 10, red)
+	frame.Set(20, 20, red)
+
+	checks := sess.CheckScenes(frame)
+	if len(checks) != 3 {
+		t.Fatalf("CheckScenes() = %+v, want 3 scenes", checks)
+	}
+	if checks[0].Name != "home" || !checks[0].Matched || checks[0].Threshold != 5 {
+		t.Errorf("checks[0] = %+v, want matched home", checks[0])
+	}
+	// shop is half right, so it is closer than map
+	if checks[1].Name != "shop" || checks[1].Matched || checks[2].Name != "map" {
+		t.Errorf("unmatched order = %s, %s; want shop, map", checks[1].Name, checks[2].Name)
+	}
+	points := checks[1].Points
+	if len(points) != 2 || !points[0].Matched || points[1].Matched || points[1].X != 30 || points[1].Y != 30 {
+		t.Errorf("shop points = %+v", points)
+	}
+	if sess.CheckScenes(nil) != nil {
+		t.Error("CheckScenes(nil) returned checks")
+	}
+}
+
+// fakeSolver answers captchas with answer, or fails with err.
+type fakeSolver struct {
+	answer string
+	err    error
+	asked  []string
+}
+
+func (f *fakeSolver) Solve(ctx context.Context, account string, img image.Image) (string, error) {
+	f.asked = append(f.asked, account)
+	return f.answer, f.err
+}
+
+func TestSession_SolveCaptcha(t *testing.T) {
+	driver := newMockDriver()
+	solver := &fakeSolver{answer: "x7kq"}
+	s := New(&Config{
+		ID:      "s1",
+		Account: &account.Account{ID: "a1", RoleName: "Alice"},
+		Driver:  driver,
+		Captcha: CaptchaConfig{Solver: solver},
+	})
+	scene := &domainscene.Scene{Name: DefaultCaptchaScene, Actions: map[string]domainscene.Action{
+		CaptchaActionInput: {Type: domainscene.ActionTypeClick, Point: domainscene.ActionPoint{X: 10, Y: 20}},
+	}}
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+
+	if err := s.solveCaptcha(s.browserCtrl, img, scene); err != nil {
+		t.Fatalf("solveCaptcha() error = %v", err)
+	}
+	if len(solver.asked) != 1 {
+		t.Errorf("solver asked %d times, want 1", len(solver.asked))
+	}
+	if !driver.clickCalled || driver.lastClickX != 10 || driver.lastClickY != 20 {
+		t.Errorf("input not clicked, last click (%v, %v)", driver.lastClickX, driver.lastClickY)
+	}
+	if !slices.Equal(driver.typed, []string{"x7kq"}) || !slices.Equal(driver.keys, []string{"Enter"}) {
+		t.Errorf("typed %v and pressed %v, want the answer and Enter", driver.typed, driver.keys)
+	}
+
+	// A solver that gives up fails the login instead of typing anything
+	solver.err = errors.New("dismissed")
+	if err := s.solveCaptcha(s.browserCtrl, img, scene); err == nil {
+		t.Error("solveCaptcha() should fail when the solver does")
+	}
+	if len(driver.typed) != 1 {
+		t.Errorf("typed %v after a failed solve", driver.typed)
+	}
+}
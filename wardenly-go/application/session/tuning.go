@@ -0,0 +1,122 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"wardenly-go/core/command"
+	"wardenly-go/core/event"
+	domainscript "wardenly-go/domain/script"
+)
+
+// DefaultPollInterval is the pause between a script's screen checks.
+const DefaultPollInterval = 500 * time.Millisecond
+
+// Poll intervals accepted by TuneSession.
+const (
+	MinPollInterval = 50 * time.Millisecond
+	MaxPollInterval = 10 * time.Second
+)
+
+// Tuning holds the matching and pacing settings that can be adjusted while
+// a session runs.
+type Tuning struct {
+	// SceneThreshold is the scene matcher's threshold
+	SceneThreshold float64
+	// PollInterval is the pause between a script's screen checks
+	PollInterval time.Duration
+	// Jitter, if set, replaces the jitter of the scripts the session runs;
+	// an action's own jitter still wins
+	Jitter *domainscript.Jitter
+}
+
+// Tuning returns the session's current tuning.
+func (s *Session) Tuning() Tuning {
+	s.tuneMu.RLock()
+	defer s.tuneMu.RUnlock()
+	t := s.tuning
+	if t.Jitter != nil {
+		j := *t.Jitter
+		t.Jitter = &j
+	}
+	return t
+}
+
+// defaultTuning returns the tuning the session was created with.
+func (s *Session) defaultTuning() Tuning {
+	return Tuning{
+		SceneThreshold: s.baseThreshold,
+		PollInterval:   DefaultPollInterval,
+	}
+}
+
+func (s *Session) handleTuneSession(cmd *command.TuneSession) {
+	tuning, err := s.applyTuning(cmd)
+	if err != nil {
+		s.logger.Warn("Invalid tuning", "error", err, "correlation_id", cmd.CorrelationID())
+		s.publishCommandEvent(cmd, event.NewOperationFailed(s.id, "tune", err))
+		return
+	}
+
+	evt := event.NewSessionTuned(s.id, tuning.SceneThreshold, tuning.PollInterval)
+	attrs := []any{"threshold", tuning.SceneThreshold, "poll_interval", tuning.PollInterval, "correlation_id", cmd.CorrelationID()}
+	if tuning.Jitter != nil {
+		evt.JitterOverride = true
+		evt.JitterPixels, evt.JitterWait = tuning.Jitter.Pixels, tuning.Jitter.Wait
+		attrs = append(attrs, "jitter_pixels", tuning.Jitter.Pixels, "jitter_wait", tuning.Jitter.Wait)
+	}
+	s.logger.Info("Session tuned", attrs...)
+	s.publishCommandEvent(cmd, evt)
+}
+
+// applyTuning validates cmd and stores the resulting tuning. The scene
+// matcher is replaced rather than changed, since a running script may be
+// using it.
+func (s *Session) applyTuning(cmd *command.TuneSession) (Tuning, error) {
+	s.tuneMu.Lock()
+	defer s.tuneMu.Unlock()
+
+	t := s.tuning
+	if cmd.Reset {
+		t = s.defaultTuning()
+	}
+	if cmd.SceneThreshold != nil {
+		if *cmd.SceneThreshold <= 0 {
+			return t, fmt.Errorf("scene threshold must be positive, got %v", *cmd.SceneThreshold)
+		}
+		t.SceneThreshold = *cmd.SceneThreshold
+	}
+	if cmd.PollInterval != nil {
+		if *cmd.PollInterval < MinPollInterval || *cmd.PollInterval > MaxPollInterval {
+			return t, fmt.Errorf("poll interval must be between %v and %v, got %v", MinPollInterval, MaxPollInterval, *cmd.PollInterval)
+		}
+		t.PollInterval = *cmd.PollInterval
+	}
+	if cmd.ClearJitter {
+		t.Jitter = nil
+	}
+	if cmd.JitterPixels != nil || cmd.JitterWait != nil {
+		var j domainscript.Jitter
+		if t.Jitter != nil {
+			j = *t.Jitter
+		}
+		if cmd.JitterPixels != nil {
+			j.Pixels = *cmd.JitterPixels
+		}
+		if cmd.JitterWait != nil {
+			j.Wait = *cmd.JitterWait
+		}
+		if err := j.Validate(); err != nil {
+			return t, err
+		}
+		t.Jitter = &j
+	}
+
+	if t.SceneThreshold != s.sceneMatcher.Threshold {
+		m := *s.sceneMatcher
+		m.Threshold = t.SceneThreshold
+		s.sceneMatcher = &m
+	}
+	s.tuning = t
+	return t, nil
+}
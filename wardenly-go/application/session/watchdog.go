@@ -0,0 +1,118 @@
+package session
+
+import (
+	"image"
+	"time"
+)
+
+// DefaultWatchdogTimeout is how long a script may match no scene before
+// the watchdog steps in.
+const DefaultWatchdogTimeout = 10 * time.Minute
+
+// DefaultFreezeTimeout is how long the screen may stay unchanged during a
+// run before the watchdog steps in. Game screens animate, so a static one
+// almost always means the game hung.
+const DefaultFreezeTimeout = 3 * time.Minute
+
+// frozenDelta is the FrameDelta below which consecutive captures count as
+// the same screen, allowing for encoding noise.
+const frozenDelta = 0.5
+
+// WatchdogAction is what the watchdog does with a stuck script.
+type WatchdogAction string
+
+const (
+	// WatchdogRefresh reloads the page and keeps the script running.
+	WatchdogRefresh WatchdogAction = "refresh"
+	// WatchdogScript runs WatchdogConfig.Script inline, like a call action.
+	WatchdogScript WatchdogAction = "script"
+	// WatchdogStop stops the script with StopReasonStuck.
+	WatchdogStop WatchdogAction = "stop"
+)
+
+// WatchdogConfig configures the stuck script watchdog of every session.
+type WatchdogConfig struct {
+	// Timeout is how long no scene may match; zero disables the watchdog.
+	Timeout time.Duration
+	// FreezeTimeout is how long the screen may stay unchanged; zero
+	// disables freeze detection.
+	FreezeTimeout time.Duration
+	Action        WatchdogAction
+	// Script is the recovery script run by WatchdogScript.
+	Script string
+}
+
+// watchdog tracks how long a run has gone without matching a scene, and
+// how long its screen has stayed the same.
+type watchdog struct {
+	config    WatchdogConfig
+	lastMatch time.Time
+	// recovered is set once a recovery ran without a match since, so the
+	// next firing stops the script instead of recovering forever
+	recovered bool
+
+	// lastFrame is the last capture that differed from the one before it,
+	// taken at lastChange
+	lastFrame  image.Image
+	lastChange time.Time
+}
+
+func newWatchdog(cfg WatchdogConfig, now time.Time) *watchdog {
+	return &watchdog{config: cfg, lastMatch: now, lastChange: now}
+}
+
+// matched records that a scene matched at now.
+func (w *watchdog) matched(now time.Time) {
+	w.lastMatch = now
+	w.recovered = false
+}
+
+// check reports whether the watchdog fires at now, with the action to take
+// and how long no scene has matched. Firing restarts the timer.
+func (w *watchdog) check(now time.Time) (WatchdogAction, time.Duration, bool) {
+	if w.config.Timeout <= 0 {
+		return "", 0, false
+	}
+	idle := now.Sub(w.lastMatch)
+	if idle < w.config.Timeout {
+		return "", 0, false
+	}
+
+	w.lastMatch = now
+	return w.fire(), idle, true
+}
+
+// frame records the screen captured at now. Captures that barely differ
+// from the last changed one leave the screen frozen.
+func (w *watchdog) frame(screen image.Image, now time.Time) {
+	if w.lastFrame == nil || FrameDelta(w.lastFrame, screen) >= frozenDelta {
+		w.lastFrame = screen
+		w.lastChange = now
+	}
+}
+
+// checkFrozen reports whether the watchdog fires at now because the screen
+// hasn't changed for FreezeTimeout, with the action to take and how long
+// it has been frozen. Firing restarts the timer.
+func (w *watchdog) checkFrozen(now time.Time) (WatchdogAction, time.Duration, bool) {
+	if w.config.FreezeTimeout <= 0 {
+		return "", 0, false
+	}
+	frozen := now.Sub(w.lastChange)
+	if frozen < w.config.FreezeTimeout {
+		return "", 0, false
+	}
+
+	w.lastChange = now
+	return w.fire(), frozen, true
+}
+
+// fire returns the action of a firing: the configured one, or stop when a
+// recovery already ran without a match since.
+func (w *watchdog) fire() WatchdogAction {
+	if w.recovered || w.config.Action == WatchdogStop || w.config.Action == "" {
+		return WatchdogStop
+	}
+	w.recovered = true
+	return w.config.Action
+}
@@ -0,0 +1,174 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"wardenly-go/core/command"
+	"wardenly-go/core/event"
+)
+
+// setupRun tracks the first-login setup script of a session, from the
+// session's start until the script stops.
+type setupRun struct {
+	script        string
+	correlationID string // Of the StartSession command
+	started       bool   // Start was requested
+	running       bool   // The session reported ScriptStarted
+	// deferred is a script requested while the setup was pending; it is
+	// started once the setup completes
+	deferred              string
+	deferredCorrelationID string
+}
+
+// registerSetup marks a new session to run its account's setup script once
+// it reaches Ready.
+func (c *Coordinator) registerSetup(sessionID, scriptName, correlationID string) {
+	c.sessionsMu.Lock()
+	c.setups[sessionID] = &setupRun{script: scriptName, correlationID: correlationID}
+	c.sessionsMu.Unlock()
+}
+
+// startSetup starts a session's pending setup script. It runs once per
+// session; logins after a browser restart don't repeat it.
+func (c *Coordinator) startSetup(sessionID string) {
+	c.sessionsMu.Lock()
+	run, ok := c.setups[sessionID]
+	sess := c.sessions[sessionID]
+	if !ok || run.started || sess == nil {
+		c.sessionsMu.Unlock()
+		return
+	}
+	run.started = true
+	c.sessionsMu.Unlock()
+
+	c.logger.Info("Starting first-login setup", "session_id", sessionID, "script", run.script,
+		"correlation_id", run.correlationID)
+	if !c.permitScript(sess, run.script, run.correlationID) {
+		c.abandonSetup(sessionID, fmt.Errorf("setup script %q was refused", run.script))
+		return
+	}
+	cmd := command.NewStartScript(sessionID, run.script)
+	cmd.SetCorrelationID(run.correlationID)
+	if err := sess.Send(cmd); err != nil {
+		c.releaseScript(sessionID)
+		c.abandonSetup(sessionID, err)
+	}
+}
+
+// deferBehindSetup holds back a script started on a session whose setup
+// hasn't finished, so the setup runs first. It returns true if the script
+// was deferred.
+func (c *Coordinator) deferBehindSetup(sessionID, scriptName, correlationID string) bool {
+	c.sessionsMu.Lock()
+	defer c.sessionsMu.Unlock()
+
+	run, ok := c.setups[sessionID]
+	if !ok || scriptName == run.script {
+		return false
+	}
+	run.deferred, run.deferredCorrelationID = scriptName, correlationID
+	c.logger.Info("Script deferred until first-login setup finishes",
+		"session_id", sessionID, "script", scriptName, "setup", run.script)
+	return true
+}
+
+// onSetupEvent follows the setup script of a session. It returns true if
+// the event ended a setup and a deferred script is being started, in which
+// case the session must not be cleaned up as finished.
+func (c *Coordinator) onSetupEvent(e event.Event) bool {
+	switch evt := e.(type) {
+	case *event.ScriptStarted:
+		c.sessionsMu.Lock()
+		if run, ok := c.setups[evt.SessionID()]; ok && run.started && evt.ScriptName == run.script {
+			run.running = true
+		}
+		c.sessionsMu.Unlock()
+
+	case *event.OperationFailed:
+		if evt.Operation != "start_script" {
+			return false
+		}
+		c.sessionsMu.RLock()
+		run, ok := c.setups[evt.SessionID()]
+		failed := ok && run.started && !run.running
+		c.sessionsMu.RUnlock()
+		if failed {
+			c.abandonSetup(evt.SessionID(), evt.Error)
+		}
+
+	case *event.ScriptStopped:
+		c.sessionsMu.Lock()
+		run, ok := c.setups[evt.SessionID()]
+		if !ok || !run.started || evt.ScriptName != run.script {
+			c.sessionsMu.Unlock()
+			return false
+		}
+		delete(c.setups, evt.SessionID())
+		c.sessionsMu.Unlock()
+
+		if evt.Reason != event.StopReasonNormal {
+			err := fmt.Errorf("setup script stopped: %s", evt.Reason)
+			if evt.Error != nil {
+				err = fmt.Errorf("%w: %w", err, evt.Error)
+			}
+			c.failSetup(evt.SessionID(), run, err)
+			return false
+		}
+		go c.completeSetup(evt.SessionID(), run)
+		return run.deferred != ""
+	}
+	return false
+}
+
+// completeSetup records a finished setup on the account and starts the
+// script deferred behind it. Runs outside the event dispatch goroutine
+// because it writes to the database.
+func (c *Coordinator) completeSetup(sessionID string, run *setupRun) {
+	err := errors.New("no account service to record it")
+	if c.accountService != nil {
+		ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+		err = c.accountService.CompleteSetup(ctx, sessionID, time.Now())
+		cancel()
+	}
+	if err != nil {
+		c.logger.Error("Failed to record first-login setup", "session_id", sessionID, "script", run.script, "error", err)
+		err = fmt.Errorf("setup finished but was not recorded: %w", err)
+	} else {
+		c.logger.Info("First-login setup completed", "session_id", sessionID, "script", run.script)
+	}
+	if c.eventBus != nil {
+		c.eventBus.Publish(event.Correlate(event.NewSetupFinished(sessionID, run.script, err == nil, err), run.correlationID))
+	}
+
+	if run.deferred != "" {
+		cmd := command.NewStartScript(sessionID, run.deferred)
+		cmd.SetCorrelationID(run.deferredCorrelationID)
+		if err := c.handleStartScript(cmd); err != nil {
+			c.logger.Error("Failed to start deferred script", "session_id", sessionID, "script", run.deferred, "error", err)
+		}
+	}
+}
+
+// abandonSetup ends a setup that could not be started.
+func (c *Coordinator) abandonSetup(sessionID string, err error) {
+	c.sessionsMu.Lock()
+	run, ok := c.setups[sessionID]
+	delete(c.setups, sessionID)
+	c.sessionsMu.Unlock()
+	if ok {
+		c.failSetup(sessionID, run, err)
+	}
+}
+
+// failSetup reports a setup that did not complete. The deferred script is
+// dropped because it likely expects the setup to have run.
+func (c *Coordinator) failSetup(sessionID string, run *setupRun, err error) {
+	c.logger.Warn("First-login setup failed", "session_id", sessionID, "script", run.script,
+		"dropped_script", run.deferred, "error", err)
+	if c.eventBus != nil {
+		c.eventBus.Publish(event.Correlate(event.NewSetupFinished(sessionID, run.script, false, err), run.correlationID))
+	}
+}
@@ -0,0 +1,135 @@
+package application
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"wardenly-go/application/session"
+	"wardenly-go/core/event"
+	"wardenly-go/core/state"
+)
+
+// EnvMaxConcurrentLogins limits how many sessions log in at once.
+const EnvMaxConcurrentLogins = "WARDENLY_MAX_CONCURRENT_LOGINS"
+
+// MaxConcurrentLoginsFromEnv reads WARDENLY_MAX_CONCURRENT_LOGINS. Unset or
+// 0 means no limit; an invalid value is reported and ignored.
+func MaxConcurrentLoginsFromEnv() (int, error) {
+	raw := os.Getenv(EnvMaxConcurrentLogins)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s: must be a non-negative integer, got %q", EnvMaxConcurrentLogins, raw)
+	}
+	return n, nil
+}
+
+// queuedStart is a session waiting for a login slot.
+type queuedStart struct {
+	sessionID     string
+	correlationID string
+}
+
+// isLoggingIn reports whether a session in st holds a login slot.
+func isLoggingIn(st state.SessionState) bool {
+	return st == state.StateStarting || st == state.StateLoggingIn
+}
+
+// startBrowser starts a new session's browser if a login slot is free, or
+// queues it and publishes its position. Queued sessions stay Idle until a
+// slot frees up.
+func (c *Coordinator) startBrowser(sess *session.Session, correlationID string) error {
+	sessionID := sess.ID()
+	c.sessionsMu.Lock()
+	if c.maxLogins > 0 && len(c.logins) >= c.maxLogins {
+		c.startQueue = append(c.startQueue, queuedStart{sessionID: sessionID, correlationID: correlationID})
+		position := len(c.startQueue)
+		c.sessionsMu.Unlock()
+
+		c.logger.Info("Session queued for login", "session_id", sessionID, "position", position,
+			"correlation_id", correlationID)
+		if c.eventBus != nil {
+			c.eventBus.Publish(event.Correlate(event.NewSessionQueued(sessionID, position), correlationID))
+		}
+		return nil
+	}
+	c.logins[sessionID] = true
+	c.sessionsMu.Unlock()
+
+	if err := sess.StartBrowser(); err != nil {
+		c.releaseLogin(sessionID)
+		return err
+	}
+	return nil
+}
+
+// releaseLogin frees the login slot of a session, if it holds one, and
+// starts the sessions queued behind it.
+func (c *Coordinator) releaseLogin(sessionID string) {
+	c.sessionsMu.Lock()
+	delete(c.logins, sessionID)
+	c.sessionsMu.Unlock()
+	c.admitQueued()
+}
+
+// admitQueued starts queued sessions while login slots are free and
+// publishes the new positions of those still waiting.
+func (c *Coordinator) admitQueued() {
+	c.sessionsMu.Lock()
+	var admitted []*session.Session
+	var correlationIDs []string
+	for len(c.startQueue) > 0 && (c.maxLogins <= 0 || len(c.logins) < c.maxLogins) {
+		next := c.startQueue[0]
+		c.startQueue = c.startQueue[1:]
+		sess, exists := c.sessions[next.sessionID]
+		if !exists {
+			continue
+		}
+		c.logins[next.sessionID] = true
+		admitted = append(admitted, sess)
+		correlationIDs = append(correlationIDs, next.correlationID)
+	}
+	var waiting []queuedStart
+	if len(admitted) > 0 {
+		waiting = append(waiting, c.startQueue...)
+	}
+	c.sessionsMu.Unlock()
+
+	for i, sess := range admitted {
+		// Starting the browser blocks, and this may run on the event goroutine
+		go c.startQueued(sess, correlationIDs[i])
+	}
+	if c.eventBus != nil {
+		for i, q := range waiting {
+			c.eventBus.Publish(event.Correlate(event.NewSessionQueued(q.sessionID, i+1), q.correlationID))
+		}
+	}
+}
+
+// startQueued starts the browser of a session that left the queue. A
+// session whose browser fails to start is dropped and reported stopped,
+// as nobody is waiting on its start command any more.
+func (c *Coordinator) startQueued(sess *session.Session, correlationID string) {
+	sessionID := sess.ID()
+	c.logger.Info("Starting queued session", "session_id", sessionID, "correlation_id", correlationID)
+	if err := sess.StartBrowser(); err != nil {
+		c.logger.Error("Queued session failed to start", "session_id", sessionID, "error", err)
+		c.sessionsMu.Lock()
+		current := c.sessions[sessionID] == sess
+		if current {
+			c.forgetSessionLocked(sessionID)
+		}
+		c.sessionsMu.Unlock()
+		c.admitQueued()
+
+		if current {
+			sess.Stop()
+			if c.eventBus != nil {
+				c.eventBus.Publish(event.Correlate(event.NewSessionStopped(sessionID, err), correlationID))
+			}
+		}
+	}
+}
@@ -1,9 +1,10 @@
 # Wardenly Build Script
 # For Windows PowerShell
-# Usage: .\build.ps1 [-prod]
+# Usage: .\build.ps1 [-prod] [-playwright]
 
 param(
-    [switch]$prod  # Production build with optimizations
+    [switch]$prod,       # Production build with optimizations
+    [switch]$playwright  # Include the Playwright browser engine
 )
 
 $ErrorActionPreference = "Stop"
@@ -57,17 +58,35 @@ $ldflags = ""
 if ($prod) {
     Write-Info "Production build enabled (optimized, no console)"
     $ldflags = "-s -w -H windowsgui"
+    # Embed version (from git tag) and update feed settings for self-update
+    $version = git describe --tags --always 2>$null
+    if (-not $version) { $version = "dev" }
+    $ldflags += " -X main.version=$version"
+    if ($env:WARDENLY_UPDATE_URL) {
+        $ldflags += " -X main.updateFeedURL=$($env:WARDENLY_UPDATE_URL)"
+    }
+    if ($env:WARDENLY_UPDATE_PUBKEY) {
+        $ldflags += " -X main.updatePublicKey=$($env:WARDENLY_UPDATE_PUBKEY)"
+    }
+    Write-Info "Version: $version"
 } else {
     Write-Info "Development build"
 }
 
+# Optional Playwright browser engine (playwright-go is pinned in go.mod)
+$tags = ""
+if ($playwright) {
+    Write-Info "Including Playwright browser engine"
+    $tags = "playwright"
+}
+
 # Build command
 Write-Info "Building..."
 
 if ($prod) {
-    go build -trimpath -tags prod -ldflags="$ldflags" -o $outputName ./cmd/wardenly
+    go build -trimpath -tags "prod $tags" -ldflags="$ldflags" -o $outputName ./cmd/wardenly
 } else {
-    go build -o $outputName ./cmd/wardenly
+    go build -tags "$tags" -o $outputName ./cmd/wardenly
 }
 
 if ($LASTEXITCODE -ne 0) {
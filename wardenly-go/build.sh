@@ -1,6 +1,6 @@
 #!/bin/bash
 # Wardenly Build Script
-# Usage: ./build.sh [-prod]
+# Usage: ./build.sh [-prod] [-playwright]
 
 set -e
 
@@ -11,6 +11,7 @@ warn() { echo -e "\033[33m[WARN]\033[0m $1"; }
 error() { echo -e "\033[31m[ERROR]\033[0m $1"; }
 
 PROD=false
+PLAYWRIGHT=false
 
 # Parse arguments
 while [[ $# -gt 0 ]]; do
@@ -19,6 +20,10 @@ while [[ $# -gt 0 ]]; do
             PROD=true
             shift
             ;;
+        -playwright|--playwright)
+            PLAYWRIGHT=true
+            shift
+            ;;
         *)
             shift
             ;;
@@ -54,17 +59,34 @@ LDFLAGS=""
 if [ "$PROD" = true ]; then
     info "Production build enabled (optimized, no console)"
     LDFLAGS="-s -w -H windowsgui"
+    # Embed version (from git tag) and update feed settings for self-update
+    VERSION=$(git describe --tags --always 2>/dev/null || echo dev)
+    LDFLAGS="$LDFLAGS -X main.version=$VERSION"
+    if [ -n "$WARDENLY_UPDATE_URL" ]; then
+        LDFLAGS="$LDFLAGS -X main.updateFeedURL=$WARDENLY_UPDATE_URL"
+    fi
+    if [ -n "$WARDENLY_UPDATE_PUBKEY" ]; then
+        LDFLAGS="$LDFLAGS -X main.updatePublicKey=$WARDENLY_UPDATE_PUBKEY"
+    fi
+    info "Version: $VERSION"
 else
     info "Development build"
 fi
 
+# Optional Playwright browser engine (requires playwright-go in go.mod)
+TAGS=""
+if [ "$PLAYWRIGHT" = true ]; then
+    info "Including Playwright browser engine"
+    TAGS="playwright"
+fi
+
 # Build command
 info "Building..."
 
 if [ "$PROD" = true ]; then
-    go build -trimpath -tags prod -ldflags="$LDFLAGS" -o "$OUTPUT_NAME" ./cmd/wardenly
+    go build -trimpath -tags "prod $TAGS" -ldflags="$LDFLAGS" -o "$OUTPUT_NAME" ./cmd/wardenly
 else
-    go build -o "$OUTPUT_NAME" ./cmd/wardenly
+    go build -tags "$TAGS" -o "$OUTPUT_NAME" ./cmd/wardenly
 fi
 
 # Get file size
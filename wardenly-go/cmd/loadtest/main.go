@@ -0,0 +1,95 @@
+// Package main runs the actor pipeline load test against replayed sessions.
+//
+// Usage:
+//
+//	go run ./cmd/loadtest -sessions 50 -duration 1m -rate 20
+//	go run ./cmd/loadtest -frames ./recording -script daily
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"wardenly-go/application/loadtest"
+	domainscene "wardenly-go/domain/scene"
+	domainscript "wardenly-go/domain/script"
+	"wardenly-go/infrastructure/browser"
+	"wardenly-go/resources"
+)
+
+func main() {
+	sessions := flag.Int("sessions", 10, "number of fake sessions")
+	duration := flag.Duration("duration", 0, "workload duration once sessions are ready (default 30s)")
+	rate := flag.Int("rate", 10, "click commands per second per session")
+	latency := flag.Duration("latency", 0, "simulated latency of every browser operation")
+	screencast := flag.Int("screencast", 0, "screencast FPS per session (0 disables)")
+	buffer := flag.Int("buffer", 100, "event bus buffer size")
+	framesDir := flag.String("frames", "", "directory of PNG/JPEG frames to replay (default: synthetic frame)")
+	scriptName := flag.String("script", "", "embedded script to run; loads the embedded scenes and scripts (default: synthetic loop)")
+	verbose := flag.Bool("v", false, "log session activity")
+	flag.Parse()
+
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelInfo
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	cfg := &loadtest.Config{
+		Sessions:        *sessions,
+		Duration:        *duration,
+		CommandRate:     *rate,
+		DriverLatency:   *latency,
+		ScreencastFPS:   *screencast,
+		EventBufferSize: *buffer,
+		Logger:          logger,
+	}
+
+	if *framesDir != "" {
+		frames, err := browser.LoadReplayFrames(*framesDir)
+		if err != nil {
+			fail(err)
+		}
+		if len(frames) == 0 {
+			fail(fmt.Errorf("no frames found in %s", *framesDir))
+		}
+		cfg.Frames = frames
+	}
+
+	if *scriptName != "" {
+		cfg.SceneRegistry = domainscene.NewRegistry()
+		if err := domainscene.NewLoader(cfg.SceneRegistry).LoadFromFS(resources.SceneFiles); err != nil {
+			fail(fmt.Errorf("failed to load scenes: %w", err))
+		}
+		cfg.ScriptRegistry = domainscript.NewRegistry()
+		if err := domainscript.NewLoader(cfg.ScriptRegistry).LoadFromFS(resources.ScriptFiles); err != nil {
+			fail(fmt.Errorf("failed to load scripts: %w", err))
+		}
+		if !cfg.ScriptRegistry.Exists(*scriptName) {
+			fail(fmt.Errorf("unknown script %q", *scriptName))
+		}
+		cfg.ScriptName = *scriptName
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := loadtest.Run(ctx, cfg)
+	if err != nil {
+		fail(err)
+	}
+	report.WriteTo(os.Stdout)
+
+	if n := report.LeakedGoroutines(); n > 0 {
+		fmt.Printf("Warning: %d goroutines still running after stop\n", n)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "loadtest:", err)
+	os.Exit(1)
+}
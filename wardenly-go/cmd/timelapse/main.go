@@ -0,0 +1,100 @@
+// Package main assembles a session's screenshots into a time-lapse.
+//
+// Usage:
+//
+//	go run ./cmd/timelapse -events session.jsonl -o today.mp4
+//	go run ./cmd/timelapse -frames ~/Pictures/snapshot -since 24h -speed 600 -o today.gif
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"wardenly-go/infrastructure/timelapse"
+)
+
+func main() {
+	def := timelapse.DefaultOptions()
+	framesDir := flag.String("frames", "", "directory of saved screenshots (<unix millis>.png)")
+	eventsFile := flag.String("events", "", "event stream recording (JSON lines from /events); supplies frames when -frames is not set, and annotations")
+	session := flag.String("session", "", "session ID to use from the event recording (required if it holds several sessions)")
+	output := flag.String("o", "timelapse.mp4", "output file (.mp4 needs ffmpeg, or .gif)")
+	speed := flag.Float64("speed", def.Speed, "speed-up factor")
+	fps := flag.Int("fps", def.FPS, "output frame rate")
+	maxHold := flag.Duration("max-hold", def.MaxHold, "longest time a single frame stays on screen")
+	noteHold := flag.Duration("note-hold", def.NoteHold, "how long an event annotation stays on screen")
+	width := flag.Int("width", 0, "scale frames down to this width (0 keeps the original size)")
+	since := flag.Duration("since", 0, "only use frames from this long ago until now (0 uses everything)")
+	flag.Parse()
+
+	if *framesDir == "" && *eventsFile == "" {
+		fail(errors.New("one of -frames or -events is required"))
+	}
+	if *speed <= 0 || *fps <= 0 {
+		fail(errors.New("-speed and -fps must be positive"))
+	}
+
+	opts := timelapse.Options{
+		Speed:    *speed,
+		FPS:      *fps,
+		MaxHold:  *maxHold,
+		NoteHold: *noteHold,
+		Width:    *width,
+	}
+
+	var (
+		frames []timelapse.Frame
+		notes  []timelapse.Note
+	)
+	if *eventsFile != "" {
+		f, err := os.Open(*eventsFile)
+		if err != nil {
+			fail(err)
+		}
+		rec, err := timelapse.LoadEventLog(f, *session, opts.FrameSpacing())
+		f.Close()
+		if err != nil {
+			fail(err)
+		}
+		frames, notes = rec.Frames, rec.Notes
+	}
+	if *framesDir != "" {
+		var err error
+		if frames, err = timelapse.LoadFrameDir(*framesDir); err != nil {
+			fail(err)
+		}
+	}
+
+	var from time.Time
+	if *since > 0 {
+		from = time.Now().Add(-*since)
+	}
+	frames, notes = timelapse.Trim(frames, notes, from, time.Time{})
+	if len(frames) == 0 {
+		fail(fmt.Errorf("%w (was the event stream recorded with screenshots?)", timelapse.ErrNoFrames))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	shots := timelapse.Plan(frames, notes, opts)
+	if err := timelapse.Export(ctx, *output, shots, opts); err != nil {
+		fail(err)
+	}
+
+	var length time.Duration
+	for _, s := range shots {
+		length += s.Delay
+	}
+	fmt.Printf("Wrote %s: %d frames, %s\n", *output, len(shots), length.Round(100*time.Millisecond))
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "timelapse:", err)
+	os.Exit(1)
+}
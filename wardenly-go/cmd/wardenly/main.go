@@ -2,29 +2,80 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
 	"os"
+	"path/filepath"
 	"time"
 
 	"wardenly-go/application"
+	"wardenly-go/application/demo"
+	"wardenly-go/application/session"
+	"wardenly-go/core/event"
 	"wardenly-go/core/eventbus"
 	domainaccount "wardenly-go/domain/account"
 	domaingroup "wardenly-go/domain/group"
 	domainscene "wardenly-go/domain/scene"
+	domainschedule "wardenly-go/domain/schedule"
 	domainscript "wardenly-go/domain/script"
+	"wardenly-go/infrastructure/api"
 	"wardenly-go/infrastructure/browser"
+	"wardenly-go/infrastructure/config"
+	"wardenly-go/infrastructure/crypto"
+	"wardenly-go/infrastructure/diagnostics"
+	"wardenly-go/infrastructure/eventstream"
+	"wardenly-go/infrastructure/journal"
 	"wardenly-go/infrastructure/logging"
+	"wardenly-go/infrastructure/metrics"
+	"wardenly-go/infrastructure/notify"
 	"wardenly-go/infrastructure/ocr"
+	"wardenly-go/infrastructure/presence"
 	"wardenly-go/infrastructure/repository"
+	"wardenly-go/infrastructure/scriptstore"
+	"wardenly-go/infrastructure/sheetsync"
+	"wardenly-go/infrastructure/stats"
+	"wardenly-go/infrastructure/trace"
+	"wardenly-go/infrastructure/update"
 	"wardenly-go/presentation"
 	"wardenly-go/resources"
 
 	"fyne.io/fyne/v2/app"
 )
 
+// Set at build time via -ldflags "-X main.version=... -X main.updateFeedURL=...".
+// The WARDENLY_UPDATE_* environment variables override the update settings.
+var (
+	version         = "dev"
+	updateFeedURL   = ""
+	updatePublicKey = ""
+)
+
 func main() {
+	checkConfig := flag.Bool("check-config", false, "check the config file and environment settings, print the problems and exit")
+	demoMode := flag.Bool("demo", false, "run with fake accounts in memory and replayed frames instead of MongoDB and Chrome")
+	flag.Parse()
+
+	// Infrastructure settings from the config file (WARDENLY_CONFIG, or
+	// config.yaml in the user config dir) and environment overrides;
+	// problems are logged once logging is up and shown in the main window
+	appConfig, configErr := config.FromEnv()
+	var configReport *config.Report
+	errors.As(configErr, &configReport)
+	if *checkConfig {
+		if configReport != nil {
+			fmt.Fprint(os.Stderr, configReport.Details())
+			os.Exit(1)
+		}
+		fmt.Printf("%s: OK\n", config.Path())
+		return
+	}
+
 	// Initialize logging (dev: console only, prod: rotating file)
-	logger, closeLog, err := logging.Setup(nil)
+	logger, closeLog, err := logging.Setup(appConfig.Logging())
 	if err != nil {
 		// Fallback to stderr if logging setup fails
 		os.Stderr.WriteString("Failed to initialize logging: " + err.Error() + "\n")
@@ -32,29 +83,132 @@ func main() {
 	}
 	defer closeLog()
 
-	logger.Info("Starting Wardenly")
+	logger.Info("Starting Wardenly", "version", version, "demo", *demoMode)
+	if configReport != nil {
+		logger.Warn("Invalid config settings", "path", config.Path(), "issues", configReport)
+	}
+
+	// Optional profiling hooks (diagnostics section, WARDENLY_PPROF_ADDR,
+	// WARDENLY_PPROF_TOKEN, WARDENLY_PROFILE_INTERVAL)
+	if profiler, err := diagnostics.Start(appConfig.Profiling(), logger); err != nil {
+		logger.Warn("Failed to start diagnostics", "error", err)
+	} else {
+		defer profiler.Stop()
+	}
+
+	// Self-update: release feed and signing key, removes the pre-update binary
+	updateConfig := appConfig.Updates()
+	if updateConfig.FeedURL == "" {
+		updateConfig.FeedURL = updateFeedURL
+	}
+	if updateConfig.PublicKey == nil && updatePublicKey != "" {
+		if updateConfig.PublicKey, err = update.ParsePublicKey(updatePublicKey); err != nil {
+			logger.Warn("Invalid built-in update key", "error", err)
+		}
+	}
+	if updateConfig.FeedURL != "" && updateConfig.PublicKey == nil {
+		logger.Warn("Updates disabled without a signing key", "env", update.EnvPublicKey)
+	}
+	updateConfig.CurrentVersion = version
+	updateConfig.Logger = logger
+	updater := update.NewUpdater(updateConfig)
+	updater.CleanupPrevious()
 
 	ctx := context.Background()
 
-	// Initialize MongoDB
-	mongoDB, err := repository.NewMongoDB(ctx, repository.DefaultMongoDBConfig(), logger)
-	if err != nil {
-		logger.Error("Failed to initialize MongoDB", "error", err)
-		os.Exit(1)
+	// Accounts, groups, templates and schedules are kept in MongoDB, or in
+	// JSON files for a portable install (store.kind: file, or WARDENLY_STORE=file);
+	// demo mode keeps fake ones in memory
+	storeConfig := appConfig.DataStore()
+	var mongoDB *repository.MongoDB // nil with the file store
+	var fileDB *repository.FileDB
+	keyFile := ""
+	if *demoMode {
+		fileDB = repository.NewMemoryDB()
+	} else if storeConfig.Kind == repository.StoreFile {
+		if fileDB, err = repository.NewFileDB(storeConfig.Dir); err != nil {
+			logger.Error("Failed to open file store", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("Using file store", "dir", fileDB.Dir())
+		// The key stays with the data so the install can be moved as a whole
+		keyFile = filepath.Join(fileDB.Dir(), "secret.key")
+	} else {
+		mongoDB, err = repository.NewMongoDB(ctx, appConfig.Mongo(), logger)
+		if err != nil {
+			logger.Error("Failed to initialize MongoDB", "error", err)
+			os.Exit(1)
+		}
+		defer mongoDB.Close(ctx)
+	}
+
+	// Account passwords and cookies are encrypted at rest
+	// (WARDENLY_SECRET_KEY, or a key file generated on first run); demo
+	// data never leaves memory and needs no key
+	var secrets *crypto.Cipher
+	if !*demoMode {
+		if secrets, err = crypto.CipherFromEnv(keyFile); err != nil {
+			logger.Error("Failed to load secret key", "error", err)
+			os.Exit(1)
+		}
 	}
-	defer mongoDB.Close(ctx)
 
 	// Initialize repositories
-	accountRepo := repository.NewMongoAccountRepository(mongoDB, logger)
-	groupRepo := repository.NewMongoGroupRepository(mongoDB, logger)
+	var (
+		accountRepo  domainaccount.Repository
+		groupRepo    domaingroup.Repository
+		templateRepo domaingroup.TemplateRepository
+		scheduleRepo domainschedule.Repository
+	)
+	if fileDB != nil {
+		fileAccounts := repository.NewFileAccountRepository(fileDB, secrets, logger)
+		_, err = fileAccounts.EncryptSecrets(ctx)
+		accountRepo = fileAccounts
+		groupRepo = repository.NewFileGroupRepository(fileDB, logger)
+		templateRepo = repository.NewFileTemplateRepository(fileDB, logger)
+		scheduleRepo = repository.NewFileScheduleRepository(fileDB, logger)
+	} else {
+		mongoAccounts := repository.NewMongoAccountRepository(mongoDB, secrets, logger)
+		_, err = mongoAccounts.EncryptSecrets(ctx)
+		accountRepo = mongoAccounts
+		groupRepo = repository.NewMongoGroupRepository(mongoDB, logger)
+		templateRepo = repository.NewMongoTemplateRepository(mongoDB, logger)
+		scheduleRepo = repository.NewMongoScheduleRepository(mongoDB, logger)
+	}
+	if err != nil {
+		logger.Warn("Failed to encrypt stored account secrets", "error", err)
+	}
 
 	// Initialize domain services
 	accountService := domainaccount.NewService(accountRepo)
 	groupService := domaingroup.NewService(groupRepo, accountRepo)
+	templateService := domaingroup.NewTemplateService(templateRepo, groupRepo)
+	scheduleService := domainschedule.NewService(scheduleRepo)
+	if *demoMode {
+		if err := demo.Seed(ctx, accountService, groupService); err != nil {
+			logger.Error("Failed to create demo data", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Initialize OCR client pool (ocr in the config file,
+	// WARDENLY_OCR_URLS, WARDENLY_OCR_CONCURRENCY, WARDENLY_OCR_TESSERACT)
+	ocrConfig, err := appConfig.OCRPool()
+	if err != nil {
+		logger.Warn("Invalid OCR settings", "error", err)
+	}
 
-	// Initialize OCR client
-	ocrConfig := ocr.DefaultClientConfig()
-	ocrClient := ocr.NewHTTPClient(ocrConfig)
+	// Optional Prometheus metrics (metrics section, WARDENLY_METRICS_ADDR),
+	// collected from here on and served once the coordinator is up
+	var metricsCollector *metrics.Collector
+	var observeSceneMatch func(matched bool)
+	metricsConfig := appConfig.MetricsEndpoint()
+	if metricsConfig.Enabled() {
+		metricsCollector = metrics.NewCollector()
+		ocrConfig.Observe = metricsCollector.ObserveOCR
+		observeSceneMatch = metricsCollector.ObserveSceneMatch
+	}
+	ocrClient := ocr.NewPool(ocrConfig)
 	defer ocrClient.Close()
 
 	// Load scenes
@@ -64,8 +218,32 @@ func main() {
 		logger.Error("Failed to load scenes", "error", err)
 		os.Exit(1)
 	}
+	// User scenes override embedded ones (WARDENLY_SCENES_DIR) and are
+	// reloaded on change once the coordinator runs; the matcher looks
+	// scenes up on every frame
+	userScenesDir := scriptstore.UserScenesDir()
+	if err := os.MkdirAll(userScenesDir, 0755); err != nil {
+		logger.Warn("User scenes directory unavailable", "dir", userScenesDir, "error", err)
+		userScenesDir = ""
+	} else if err := sceneLoader.LoadDir(userScenesDir); err != nil {
+		logger.Warn("Some user scenes failed to load", "dir", userScenesDir, "error", err)
+	}
 	logger.Info("Scenes loaded", "count", sceneRegistry.Count())
 
+	// Demo sessions replay frames (WARDENLY_DEMO_FRAMES, or synthetic ones
+	// that log in as main_city) instead of running a browser
+	var demoFrames []image.Image
+	if *demoMode {
+		frames, synthetic, err := demo.Frames()
+		if err != nil {
+			logger.Warn("Using synthetic demo frames", "error", err)
+		}
+		if synthetic {
+			demo.RegisterLoginScene(sceneRegistry, frames)
+		}
+		demoFrames = frames
+	}
+
 	// Load scripts
 	scriptRegistry := domainscript.NewRegistry()
 	scriptLoader := domainscript.NewLoader(scriptRegistry)
@@ -73,28 +251,328 @@ func main() {
 		logger.Error("Failed to load scripts", "error", err)
 		os.Exit(1)
 	}
+	// User scripts override embedded ones (WARDENLY_SCRIPTS_DIR)
+	userScriptsDir := scriptstore.UserScriptsDir()
+	if err := os.MkdirAll(userScriptsDir, 0755); err != nil {
+		logger.Warn("User scripts directory unavailable", "dir", userScriptsDir, "error", err)
+		userScriptsDir = ""
+	} else if err := scriptLoader.LoadDir(userScriptsDir); err != nil {
+		logger.Warn("Some user scripts failed to load", "dir", userScriptsDir, "error", err)
+	}
 	logger.Info("Scripts loaded", "count", scriptRegistry.Count())
 
+	// Track script revisions (restores any pinned rollback)
+	var scriptVersions *domainscript.VersionService
+	if versionStore, err := scriptstore.NewFileStore(scriptstore.FileStoreConfig{}); err != nil {
+		logger.Warn("Script version store unavailable", "error", err)
+	} else {
+		scriptVersions = domainscript.NewVersionService(versionStore, scriptRegistry)
+		if err := scriptVersions.TrackAll(scriptLoader.Sources()); err != nil {
+			logger.Warn("Failed to track script versions", "error", err)
+		}
+	}
+
 	// Initialize event bus
 	eventBus := eventbus.New(100)
 	defer eventBus.Close()
 
+	// Show each session's log lines in its tab
+	logging.Sessions().SetSink(func(line logging.SessionLine) {
+		eventBus.Publish(event.NewLogRecorded(line.SessionID, line.Time, line.Level, line.Text))
+	})
+	defer logging.Sessions().SetSink(nil)
+
+	// Reload user scripts on change; the UI refreshes its script lists
+	if userScriptsDir != "" {
+		watchCtx, stopWatch := context.WithCancel(context.Background())
+		defer stopWatch()
+		err := scriptLoader.WatchDir(watchCtx, userScriptsDir, func(err error) {
+			if err != nil {
+				logger.Warn("Some user scripts failed to reload", "error", err)
+			}
+			if scriptVersions != nil {
+				if err := scriptVersions.TrackAll(scriptLoader.Sources()); err != nil {
+					logger.Warn("Failed to track script versions", "error", err)
+				}
+			}
+			logger.Info("User scripts reloaded", "count", scriptRegistry.Count())
+			eventBus.Publish(event.NewScriptsReloaded(scriptRegistry.List(), err))
+		})
+		if err != nil {
+			logger.Warn("Failed to watch user scripts", "dir", userScriptsDir, "error", err)
+		}
+	}
+
+	// Browser engine (browser.engine or WARDENLY_BROWSER_ENGINE=chromedp|playwright)
+	newDriver, err := browser.NewDriverFactory(appConfig.Browser.Engine)
+	if err != nil {
+		logger.Warn("Browser engine unavailable, using ChromeDP", "error", err)
+		newDriver, _ = browser.NewDriverFactory(browser.EngineChromeDP)
+	}
+	if *demoMode {
+		newDriver = demo.DriverFactory(demoFrames)
+	}
+
+	// Login page address and selectors: the config file's login section,
+	// overridden by the profile recalibrated from the UI after portal redesigns
+	loginProfilePath := browser.DefaultLoginProfilePath()
+	loginProfile, err := browser.LoadLoginProfile(loginProfilePath, appConfig.LoginProfile())
+	if err != nil {
+		logger.Warn("Using default login profile", "error", err)
+	}
+
+	// Stuck script watchdog (watchdog section, WARDENLY_WATCHDOG_*)
+	watchdogConfig := session.WatchdogConfig{
+		Timeout:       config.DurationOr(appConfig.Watchdog.Timeout, session.DefaultWatchdogTimeout),
+		FreezeTimeout: config.DurationOr(appConfig.Watchdog.FreezeTimeout, session.DefaultFreezeTimeout),
+		Action:        session.WatchdogStop,
+		Script:        appConfig.Watchdog.Script,
+	}
+	if appConfig.Watchdog.Action != "" {
+		watchdogConfig.Action = session.WatchdogAction(appConfig.Watchdog.Action)
+	}
+
+	// Browser crash recovery (reconnect section, WARDENLY_RECONNECT_*)
+	reconnectConfig := session.ReconnectConfig{
+		MaxAttempts: config.ValueOr(appConfig.Reconnect.Attempts, session.DefaultReconnectAttempts),
+		Delay:       config.DurationOr(appConfig.Reconnect.Delay, session.DefaultReconnectDelay),
+		MaxDelay:    config.DurationOr(appConfig.Reconnect.MaxDelay, session.DefaultReconnectMaxDelay),
+	}
+
+	// Declarative password login (WARDENLY_LOGIN_FLOW or login_flow.yaml);
+	// without one the driver's built-in login is used
+	loginFlow, err := session.LoginFlowFromEnv()
+	if err != nil {
+		logger.Warn("Using the built-in login flow", "error", err)
+	}
+
+	// Game data read from API responses (WARDENLY_DATA_TAP or data_tap.yaml)
+	dataTap, err := session.DataTapFromEnv()
+	if err != nil {
+		logger.Warn("Game data tap disabled", "error", err)
+	}
+
+	// Login retries (loginRetry section, WARDENLY_LOGIN_ATTEMPTS, _RETRY_DELAY, _RETRY_MAX_DELAY)
+	loginRetryConfig := session.LoginRetryConfig{
+		MaxAttempts: config.ValueOr(appConfig.LoginRetry.Attempts, session.DefaultLoginAttempts),
+		Delay:       config.DurationOr(appConfig.LoginRetry.Delay, session.DefaultLoginRetryDelay),
+		MaxDelay:    config.DurationOr(appConfig.LoginRetry.MaxDelay, session.DefaultLoginRetryMaxDelay),
+	}
+
+	// Login captchas (captcha section): answered by the solver at
+	// captcha.solverUrl if set, otherwise typed in by the user
+	captchaConfig := session.CaptchaConfig{Scene: appConfig.Captcha.Scene}
+	if solver := appConfig.CaptchaSolver(); solver != nil {
+		captchaConfig.Solver = solver
+	} else {
+		captchaConfig.Solver = presentation.NewCaptchaPrompt()
+	}
+
+	// Login throttling for large group runs (WARDENLY_MAX_CONCURRENT_LOGINS)
+	maxLogins, err := application.MaxConcurrentLoginsFromEnv()
+	if err != nil {
+		logger.Warn("Invalid login limit", "error", err)
+	}
+
+	// Scene threshold tuning (scenes section, WARDENLY_SCENE_NEAR_MISS_*
+	// and WARDENLY_SCENE_AUTO_RELAX)
+	sceneTuning := domainscene.NearMissConfig{
+		Margin:      config.ValueOr(appConfig.Scenes.NearMissMargin, session.DefaultSceneNearMissMargin),
+		MinCount:    cmp.Or(appConfig.Scenes.NearMissCount, session.DefaultSceneNearMissCount),
+		MinSessions: cmp.Or(appConfig.Scenes.NearMissSessions, session.DefaultSceneNearMissSessions),
+		MaxRelax:    appConfig.Scenes.AutoRelax,
+	}
+
+	// Script run statistics per account and day (on unless stats.disabled)
+	var statsStore stats.Store
+	var flushStats func(context.Context) error
+	if statsConfig := appConfig.Statistics(); statsConfig.Enabled() && mongoDB != nil {
+		statsConfig.Logger = logger
+		store := repository.NewMongoStatsStore(mongoDB, logger)
+		if err := store.EnsureIndex(ctx); err != nil {
+			logger.Warn("Failed to create statistics index", "error", err)
+		}
+		statsRecorder := stats.Start(statsConfig, eventBus, store)
+		defer statsRecorder.Stop()
+		statsStore = store
+		// Sessions stopped when their script finished keep their run
+		flushStats = statsRecorder.Flush
+	}
+
 	// Initialize coordinator
 	coordinator := application.NewCoordinator(&application.CoordinatorConfig{
 		EventBus:       eventBus,
 		SceneRegistry:  sceneRegistry,
 		ScriptRegistry: scriptRegistry,
 		OCRClient:      ocrClient,
-		DriverFactory: func() browser.Driver {
-			// Use default config which has Headless=true
-			// Browser runs headless, screenshots are captured via chromedp and displayed in CanvasWindow
-			return browser.NewChromeDPDriver(browser.DefaultDriverConfig())
-		},
-		Logger: logger,
+		AccountService: accountService,
+		FlushStats:     flushStats,
+		// The coordinator passes the configured browser flags (Headless by
+		// default) with the account's proxy; screenshots are captured by the
+		// driver and displayed in CanvasWindow
+		DriverFactory:       newDriver,
+		Browser:             appConfig.Driver(),
+		SceneThreshold:      appConfig.Scenes.Threshold,
+		LoginProfile:        loginProfile,
+		LoginProfilePath:    loginProfilePath,
+		Watchdog:            watchdogConfig,
+		Reconnect:           reconnectConfig,
+		LoginRetry:          loginRetryConfig,
+		LoginFlow:           loginFlow,
+		DataTap:             dataTap,
+		Captcha:             captchaConfig,
+		SceneTuning:         sceneTuning,
+		MaxConcurrentLogins: maxLogins,
+		ObserveSceneMatch:   observeSceneMatch,
+		Logger:              logger,
 	})
 	coordinator.Start()
 	defer coordinator.Stop()
 
+	// Reload user scenes on change; edits are re-validated against the
+	// recent frames of running sessions
+	if userScenesDir != "" {
+		watchCtx, stopWatch := context.WithCancel(context.Background())
+		defer stopWatch()
+		err := sceneLoader.WatchDir(watchCtx, userScenesDir, func(edits []domainscene.Edit, err error) {
+			if err != nil {
+				logger.Warn("Some user scenes failed to reload", "error", err)
+			}
+			logger.Info("User scenes reloaded", "count", sceneRegistry.Count(), "edited", len(edits))
+			coordinator.RevalidateEdits(edits)
+		})
+		if err != nil {
+			logger.Warn("Failed to watch user scenes", "dir", userScenesDir, "error", err)
+		}
+	}
+
+	// Initialize scheduler (timed runs; stopped before the coordinator)
+	scheduler := application.NewScheduler(&application.SchedulerConfig{
+		Coordinator:     coordinator,
+		EventBus:        eventBus,
+		ScheduleService: scheduleService,
+		AccountService:  accountService,
+		GroupService:    groupService,
+		Logger:          logger,
+	})
+	if err := scheduler.Start(); err != nil {
+		logger.Warn("Failed to start scheduler", "error", err)
+	}
+	defer scheduler.Stop()
+
+	// Account sync with an external spreadsheet (settings edited in the UI)
+	accountSyncPath := sheetsync.DefaultPath()
+	accountSyncSettings, err := sheetsync.LoadSettings(accountSyncPath)
+	if err != nil {
+		logger.Warn("Using empty account sync settings", "error", err)
+	}
+	accountSync := application.NewAccountSync(&application.AccountSyncConfig{
+		AccountService: accountService,
+		EventBus:       eventBus,
+		Settings:       accountSyncSettings,
+		SettingsPath:   accountSyncPath,
+		Logger:         logger,
+	})
+	accountSync.Start()
+	defer accountSync.Stop()
+
+	// Optional remote control API (api section, WARDENLY_API_ADDR, WARDENLY_API_TOKEN)
+	if apiConfig := appConfig.RemoteAPI(); apiConfig.Enabled() {
+		apiConfig.Logger = logger
+		apiServer, err := api.Start(apiConfig, application.NewRemoteControl(coordinator, accountService))
+		if err != nil {
+			logger.Warn("Failed to start remote API", "error", err)
+		} else {
+			defer apiServer.Stop()
+		}
+	}
+
+	// Optional WebSocket event stream (events section, WARDENLY_EVENTS_ADDR, WARDENLY_EVENTS_TOKEN)
+	if streamConfig := appConfig.EventStream(); streamConfig.Enabled() {
+		streamConfig.Logger = logger
+		streamServer, err := eventstream.Start(streamConfig, eventBus)
+		if err != nil {
+			logger.Warn("Failed to start event stream", "error", err)
+		} else {
+			defer streamServer.Stop()
+		}
+	}
+
+	if metricsCollector != nil {
+		metricsConfig.Logger = logger
+		metricsServer, err := metrics.Start(metricsConfig, metricsCollector, eventBus)
+		if err != nil {
+			logger.Warn("Failed to start metrics endpoint", "error", err)
+		} else {
+			defer metricsServer.Stop()
+		}
+	}
+
+	// Event journal for incident reconstruction (on unless journal.disabled)
+	var journalDir string
+	journalConfig := appConfig.EventJournal()
+	if journalConfig.Enabled() {
+		journalConfig.Logger = logger
+		eventJournal, err := journal.Start(journalConfig, eventBus)
+		if err != nil {
+			logger.Warn("Failed to start event journal", "error", err)
+		} else {
+			defer eventJournal.Stop()
+			journalDir = journalConfig.Dir
+		}
+	}
+
+	// Script execution traces (on unless trace.disabled)
+	var traceStore trace.Store
+	traceConfig := appConfig.Tracing()
+	if traceConfig.Enabled() {
+		traceConfig.Logger = logger
+		var store trace.Store // nil writes JSON lines files
+		if traceConfig.Store == trace.StoreMongo {
+			if mongoDB != nil {
+				store = repository.NewMongoTraceStore(mongoDB, logger)
+			} else {
+				logger.Warn("MongoDB trace store needs the MongoDB store, using files")
+			}
+		}
+		recorder, err := trace.Start(traceConfig, eventBus, store)
+		if err != nil {
+			logger.Warn("Failed to start script trace recorder", "error", err)
+		} else {
+			defer recorder.Stop()
+			traceStore = recorder.Store()
+		}
+	}
+
+	// Session presence records for other machines and dashboards, expired
+	// by MongoDB once the heartbeat stops (on unless presence.disabled)
+	presenceConfig := appConfig.PresenceRecords()
+	if presenceConfig.Enabled() && mongoDB != nil {
+		presenceConfig.Logger = logger
+		store := repository.NewMongoPresenceStore(mongoDB, presenceConfig.TTL, logger)
+		if err := store.EnsureIndex(ctx); err != nil {
+			logger.Warn("Failed to create presence index", "error", err)
+		}
+		publisher := presence.Start(presenceConfig, eventBus, store)
+		defer publisher.Stop()
+	}
+
+	// Notification center (alerts kept across restarts)
+	notifications, err := notify.NewCenter(&notify.Config{})
+	if err != nil {
+		logger.Warn("Failed to load notifications", "error", err)
+	}
+
+	// Important notifications also go to the configured webhooks
+	var forwarder *notify.Forwarder
+	if forwardConfig := appConfig.Forwarder(); forwardConfig.Enabled() {
+		forwardConfig.Logger = logger
+		forwarder = notify.NewForwarder(forwardConfig)
+		defer forwarder.Close()
+		logger.Info("Forwarding notifications", "webhook", forwardConfig.WebhookURL != "",
+			"discord", forwardConfig.DiscordURL != "", "telegram", forwardConfig.TelegramToken != "")
+	}
+
 	// Initialize UI event bridge
 	bridge := presentation.NewUIEventBridge(&presentation.BridgeConfig{
 		Coordinator: coordinator,
@@ -112,12 +590,34 @@ func main() {
 
 	// Initialize main window
 	mainWindow := presentation.NewMainWindow(&presentation.MainWindowConfig{
-		App:            fyneApp,
-		Bridge:         bridge,
-		Logger:         logger,
-		AccountService: accountService,
-		GroupService:   groupService,
-		ScriptNames:    scriptNames,
+		App:             fyneApp,
+		Bridge:          bridge,
+		Logger:          logger,
+		AccountService:  accountService,
+		GroupService:    groupService,
+		TemplateService: templateService,
+		ScriptNames:     scriptNames,
+		ScriptRegistry:  scriptRegistry,
+		ScriptVersions:  scriptVersions,
+		Updater:         updater,
+		JournalDir:      journalDir,
+		ScreenshotDir:   session.DefaultSaveDir(),
+		TraceStore:      traceStore,
+		StatsStore:      statsStore,
+		Notifications:   notifications,
+		Forwarder:       forwarder,
+		ScheduleService: scheduleService,
+		Scheduler:       scheduler,
+		AccountSync:     accountSync,
+		ConfigPath:      config.Path(),
+		ConfigReport:    configReport,
+		Demo:            *demoMode,
+		// Auto Refresh streaming (screencast in the config file)
+		ScreencastQuality:    appConfig.Screencast.Quality,
+		ScreencastMaxFPS:     appConfig.Screencast.MaxFPS,
+		ScreencastStartDelay: appConfig.Screencast.Delay(),
+		ScreencastIdlePause:  appConfig.Screencast.PauseAfter(),
+		DesktopNotifications: appConfig.Notify.DesktopEnabled(),
 	})
 	defer mainWindow.Cleanup()
 
@@ -20,6 +20,7 @@ func (c *Click) CommandName() string {
 
 // ClickAll performs a mouse click on all running sessions.
 type ClickAll struct {
+	correlation
 	X, Y float64
 }
 
@@ -58,6 +59,7 @@ func (c *Drag) CommandName() string {
 
 // DragAll performs a drag operation on all running sessions.
 type DragAll struct {
+	correlation
 	Points []Point
 }
 
@@ -65,6 +67,46 @@ func (c *DragAll) CommandName() string {
 	return "DragAll"
 }
 
+// Scroll turns the mouse wheel at the specified coordinates. Positive
+// deltas scroll right and down.
+type Scroll struct {
+	baseSessionCommand
+	X, Y           float64
+	DeltaX, DeltaY float64
+}
+
+func NewScroll(sessionID string, x, y, deltaX, deltaY float64) *Scroll {
+	return &Scroll{
+		baseSessionCommand: baseSessionCommand{sessionID: sessionID},
+		X:                  x,
+		Y:                  y,
+		DeltaX:             deltaX,
+		DeltaY:             deltaY,
+	}
+}
+
+func (c *Scroll) CommandName() string {
+	return "Scroll"
+}
+
+// KeyPress presses and releases a key in the browser, e.g. "a", "Enter"
+// or "ArrowLeft" (DOM key values).
+type KeyPress struct {
+	baseSessionCommand
+	Key string
+}
+
+func NewKeyPress(sessionID, key string) *KeyPress {
+	return &KeyPress{
+		baseSessionCommand: baseSessionCommand{sessionID: sessionID},
+		Key:                key,
+	}
+}
+
+func (c *KeyPress) CommandName() string {
+	return "KeyPress"
+}
+
 // CaptureScreen captures the current browser screen.
 type CaptureScreen struct {
 	baseSessionCommand
@@ -82,6 +124,25 @@ func (c *CaptureScreen) CommandName() string {
 	return "CaptureScreen"
 }
 
+// EvaluateJS runs a JavaScript expression in the session's page, such as
+// reading the game frame's offset or the canvas size. The result is
+// published as JSEvaluated.
+type EvaluateJS struct {
+	baseSessionCommand
+	Expression string
+}
+
+func NewEvaluateJS(sessionID, expression string) *EvaluateJS {
+	return &EvaluateJS{
+		baseSessionCommand: baseSessionCommand{sessionID: sessionID},
+		Expression:         expression,
+	}
+}
+
+func (c *EvaluateJS) CommandName() string {
+	return "EvaluateJS"
+}
+
 // RefreshPage refreshes the browser page.
 type RefreshPage struct {
 	baseSessionCommand
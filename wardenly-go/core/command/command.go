@@ -2,6 +2,11 @@
 // Commands represent user intentions and are processed by the application layer.
 package command
 
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
 // Command is the base interface for all commands.
 // Commands are sent from the presentation layer to the application layer.
 type Command interface {
@@ -18,9 +23,60 @@ type SessionCommand interface {
 
 // baseSessionCommand provides common implementation for session commands.
 type baseSessionCommand struct {
+	correlation
 	sessionID string
 }
 
 func (c *baseSessionCommand) SessionID() string {
 	return c.sessionID
 }
+
+// Correlated is implemented by all commands. The correlation ID ties a
+// command to the log lines and events it causes, across sessions.
+type Correlated interface {
+	CorrelationID() string
+	SetCorrelationID(id string)
+}
+
+// correlation provides the Correlated implementation for commands.
+type correlation struct {
+	correlationID string
+}
+
+func (c *correlation) CorrelationID() string {
+	return c.correlationID
+}
+
+func (c *correlation) SetCorrelationID(id string) {
+	c.correlationID = id
+}
+
+// NewCorrelationID returns a new random correlation ID.
+func NewCorrelationID() string {
+	var b [6]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// CorrelationID returns the correlation ID of cmd, or "" if it has none.
+func CorrelationID(cmd Command) string {
+	if c, ok := cmd.(Correlated); ok {
+		return c.CorrelationID()
+	}
+	return ""
+}
+
+// Correlate assigns a new correlation ID to cmd unless it already has one,
+// and returns the command's correlation ID.
+func Correlate(cmd Command) string {
+	c, ok := cmd.(Correlated)
+	if !ok {
+		return ""
+	}
+	if c.CorrelationID() == "" {
+		c.SetCorrelationID(NewCorrelationID())
+	}
+	return c.CorrelationID()
+}
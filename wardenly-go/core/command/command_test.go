@@ -10,19 +10,25 @@ func TestCommand_Names(t *testing.T) {
 		{&StartSession{}, "StartSession"},
 		{NewStopSession("s1"), "StopSession"},
 		{&StopAllSessions{}, "StopAllSessions"},
+		{&SetStopOnScriptFinish{Enabled: true}, "SetStopOnScriptFinish"},
 		{NewClick("s1", 100, 200), "Click"},
 		{&ClickAll{X: 100, Y: 200}, "ClickAll"},
 		{NewDrag("s1", []Point{{0, 0}, {100, 100}}), "Drag"},
 		{&DragAll{}, "DragAll"},
 		{NewCaptureScreen("s1", true), "CaptureScreen"},
 		{NewRefreshPage("s1"), "RefreshPage"},
+		{NewEvaluateJS("s1", "1 + 1"), "EvaluateJS"},
 		{NewSaveCookies("s1"), "SaveCookies"},
 		{NewStartScript("s1", "test"), "StartScript"},
 		{NewStopScript("s1"), "StopScript"},
+		{NewPauseScript("s1"), "PauseScript"},
+		{NewResumeScript("s1"), "ResumeScript"},
+		{NewStepScript("s1"), "StepScript"},
 		{&StartAllScripts{}, "StartAllScripts"},
 		{&StopAllScripts{}, "StopAllScripts"},
 		{NewSetScriptSelection("s1", "test"), "SetScriptSelection"},
 		{&SyncScriptSelection{ScriptName: "test"}, "SyncScriptSelection"},
+		{NewTuneSession("s1"), "TuneSession"},
 	}
 
 	for _, tt := range tests {
@@ -45,10 +51,15 @@ func TestSessionCommand_SessionID(t *testing.T) {
 		{"Drag", NewDrag("session-789", nil), "session-789"},
 		{"CaptureScreen", NewCaptureScreen("session-abc", false), "session-abc"},
 		{"RefreshPage", NewRefreshPage("session-def"), "session-def"},
+		{"EvaluateJS", NewEvaluateJS("session-js", "1 + 1"), "session-js"},
 		{"SaveCookies", NewSaveCookies("session-ghi"), "session-ghi"},
 		{"StartScript", NewStartScript("session-jkl", "test"), "session-jkl"},
 		{"StopScript", NewStopScript("session-mno"), "session-mno"},
+		{"PauseScript", NewPauseScript("session-pau"), "session-pau"},
+		{"ResumeScript", NewResumeScript("session-res"), "session-res"},
+		{"StepScript", NewStepScript("session-stp"), "session-stp"},
 		{"SetScriptSelection", NewSetScriptSelection("session-pqr", "test"), "session-pqr"},
+		{"TuneSession", NewTuneSession("session-stu"), "session-stu"},
 	}
 
 	for _, tt := range tests {
@@ -79,3 +90,25 @@ func TestNewDragFromTo(t *testing.T) {
 		t.Errorf("Second point = (%v, %v), want (30, 40)", cmd.Points[1].X, cmd.Points[1].Y)
 	}
 }
+
+func TestCorrelate(t *testing.T) {
+	tests := []Command{NewClick("s1", 1, 2), &ClickAll{}, &StartSession{}, &StopAllScripts{}}
+	for _, cmd := range tests {
+		t.Run(cmd.CommandName(), func(t *testing.T) {
+			if got := CorrelationID(cmd); got != "" {
+				t.Fatalf("CorrelationID() = %q before Correlate", got)
+			}
+			id := Correlate(cmd)
+			if id == "" || CorrelationID(cmd) != id {
+				t.Fatalf("Correlate() = %q, CorrelationID() = %q", id, CorrelationID(cmd))
+			}
+			if again := Correlate(cmd); again != id {
+				t.Errorf("Correlate() replaced ID %q with %q", id, again)
+			}
+		})
+	}
+
+	if NewCorrelationID() == NewCorrelationID() {
+		t.Error("NewCorrelationID() returned the same ID twice")
+	}
+}
@@ -1,9 +1,16 @@
 package command
 
+import "time"
+
 // StartScript starts a script on a session.
 type StartScript struct {
 	baseSessionCommand
 	ScriptName string
+	// Params holds prompt values collected at start time (optional).
+	// Missing values fall back to the account's remembered values, then prompt defaults.
+	Params map[string]string
+	// Debug halts the script before each action until a StepScript
+	Debug bool
 }
 
 func NewStartScript(sessionID, scriptName string) *StartScript {
@@ -13,6 +20,14 @@ func NewStartScript(sessionID, scriptName string) *StartScript {
 	}
 }
 
+func NewStartScriptWithParams(sessionID, scriptName string, params map[string]string) *StartScript {
+	return &StartScript{
+		baseSessionCommand: baseSessionCommand{sessionID: sessionID},
+		ScriptName:         scriptName,
+		Params:             params,
+	}
+}
+
 func (c *StartScript) CommandName() string {
 	return "StartScript"
 }
@@ -30,16 +45,63 @@ func (c *StopScript) CommandName() string {
 	return "StopScript"
 }
 
+// PauseScript suspends the running script on a session after its current
+// action, keeping its place, loop iterations and counters.
+type PauseScript struct {
+	baseSessionCommand
+}
+
+func NewPauseScript(sessionID string) *PauseScript {
+	return &PauseScript{baseSessionCommand{sessionID: sessionID}}
+}
+
+func (c *PauseScript) CommandName() string {
+	return "PauseScript"
+}
+
+// ResumeScript continues a paused script on a session.
+type ResumeScript struct {
+	baseSessionCommand
+}
+
+func NewResumeScript(sessionID string) *ResumeScript {
+	return &ResumeScript{baseSessionCommand{sessionID: sessionID}}
+}
+
+func (c *ResumeScript) CommandName() string {
+	return "ResumeScript"
+}
+
+// StepScript lets a script halted in debug mode perform its pending action
+// and halt before the next one. With Continue, the script leaves debug mode
+// and runs on.
+type StepScript struct {
+	baseSessionCommand
+	Continue bool
+}
+
+func NewStepScript(sessionID string) *StepScript {
+	return &StepScript{baseSessionCommand: baseSessionCommand{sessionID: sessionID}}
+}
+
+func (c *StepScript) CommandName() string {
+	return "StepScript"
+}
+
 // StartAllScripts starts scripts on all sessions that are not currently running a script.
 // Each session uses its own selected script.
-type StartAllScripts struct{}
+type StartAllScripts struct {
+	correlation
+}
 
 func (c *StartAllScripts) CommandName() string {
 	return "StartAllScripts"
 }
 
 // StopAllScripts stops scripts on all sessions that are currently running a script.
-type StopAllScripts struct{}
+type StopAllScripts struct {
+	correlation
+}
 
 func (c *StopAllScripts) CommandName() string {
 	return "StopAllScripts"
@@ -64,9 +126,36 @@ func (c *SetScriptSelection) CommandName() string {
 
 // SyncScriptSelection synchronizes script selection to all sessions.
 type SyncScriptSelection struct {
+	correlation
 	ScriptName string
 }
 
 func (c *SyncScriptSelection) CommandName() string {
 	return "SyncScriptSelection"
 }
+
+// TuneSession adjusts a session's matching and pacing while it runs. Nil
+// fields keep their current value; Reset first restores the configured
+// values. Changes apply to a running script on its next step.
+type TuneSession struct {
+	baseSessionCommand
+	Reset bool
+	// SceneThreshold is the scene matcher's color distance threshold
+	SceneThreshold *float64
+	// PollInterval is how long scripts wait between scene checks
+	PollInterval *time.Duration
+	// ClearJitter drops a jitter override, so scripts use their own again
+	ClearJitter bool
+	// JitterPixels and JitterWait override the script's humanization
+	// jitter; setting either replaces the script's jitter with both
+	JitterPixels *float64
+	JitterWait   *float64
+}
+
+func NewTuneSession(sessionID string) *TuneSession {
+	return &TuneSession{baseSessionCommand: baseSessionCommand{sessionID: sessionID}}
+}
+
+func (c *TuneSession) CommandName() string {
+	return "TuneSession"
+}
@@ -1,19 +1,65 @@
 package command
 
+import "time"
+
 // StartSession starts a new browser session for an account.
 type StartSession struct {
+	correlation
 	AccountID string
 	RoleName  string // In-game character name
 	ServerID  int
 	UserName  string
 	Password  string
 	Cookies   []Cookie // Optional: for cookie-based login
+	// URLTemplate overrides the login page of the login profile (optional)
+	URLTemplate string
+	// ScriptParams holds remembered prompt values per script name (optional)
+	ScriptParams map[string]map[string]string
+	// AllowedScripts and BlockedScripts restrict which scripts may run (optional)
+	AllowedScripts []string
+	BlockedScripts []string
+	// StopOnScriptFinish stops the session once a script completes normally,
+	// regardless of the coordinator-wide setting
+	StopOnScriptFinish bool
+	// SetupScript runs once the session first reaches Ready, before any
+	// other script; set only while the account's setup is pending
+	SetupScript string
+	// Proxy routes the session's browser through a proxy (optional)
+	Proxy *Proxy
+	// Browser overrides the default browser settings (optional)
+	Browser *BrowserOverrides
+	// CanvasWidth and CanvasHeight run the game at a preset viewport size,
+	// scaling scene and script coordinates to it; zero keeps the viewport
+	// of Browser or the default
+	CanvasWidth  int
+	CanvasHeight int
 }
 
 func (c *StartSession) CommandName() string {
 	return "StartSession"
 }
 
+// Proxy is an HTTP proxy for a session's browser.
+type Proxy struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// BrowserOverrides replaces parts of the default browser configuration for
+// a session. Zero fields keep the default.
+type BrowserOverrides struct {
+	Headless       *bool
+	ViewportWidth  int
+	ViewportHeight int
+	UserDataDir    string
+
+	PageScale         float64
+	DeviceScaleFactor float64
+	Mobile            bool
+}
+
 // Cookie represents a browser cookie for session restoration.
 type Cookie struct {
 	Name       string
@@ -23,6 +69,23 @@ type Cookie struct {
 	HTTPOnly   bool
 	Secure     bool
 	SourcePort int
+	Expires    time.Time // Zero for session cookies
+}
+
+// RelaunchSession replaces a running session's browser with a new one
+// started with the overrides merged over the session's settings, then logs
+// in again with the cookies the session holds. A running script is stopped.
+type RelaunchSession struct {
+	baseSessionCommand
+	Browser *BrowserOverrides
+}
+
+func NewRelaunchSession(sessionID string, browser *BrowserOverrides) *RelaunchSession {
+	return &RelaunchSession{baseSessionCommand: baseSessionCommand{sessionID: sessionID}, Browser: browser}
+}
+
+func (c *RelaunchSession) CommandName() string {
+	return "RelaunchSession"
 }
 
 // StopSession stops a running session.
@@ -39,8 +102,21 @@ func (c *StopSession) CommandName() string {
 }
 
 // StopAllSessions stops all running sessions.
-type StopAllSessions struct{}
+type StopAllSessions struct {
+	correlation
+}
 
 func (c *StopAllSessions) CommandName() string {
 	return "StopAllSessions"
 }
+
+// SetStopOnScriptFinish toggles the coordinator-wide auto cleanup of sessions
+// whose script completed normally.
+type SetStopOnScriptFinish struct {
+	correlation
+	Enabled bool
+}
+
+func (c *SetStopOnScriptFinish) CommandName() string {
+	return "SetStopOnScriptFinish"
+}
@@ -1,6 +1,10 @@
 package event
 
-import "image"
+import (
+	"encoding/json"
+	"image"
+	"time"
+)
 
 // ScreenCaptured is published when a screenshot is captured.
 type ScreenCaptured struct {
@@ -19,6 +23,28 @@ func (e *ScreenCaptured) EventName() string {
 	return "ScreenCaptured"
 }
 
+// JSEvaluated is published in reply to EvaluateJS with the expression's
+// value as JSON, or the error that stopped it.
+type JSEvaluated struct {
+	baseSessionEvent
+	Expression string
+	Result     json.RawMessage
+	Error      error
+}
+
+func NewJSEvaluated(sessionID, expression string, result json.RawMessage, err error) *JSEvaluated {
+	return &JSEvaluated{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		Expression:       expression,
+		Result:           result,
+		Error:            err,
+	}
+}
+
+func (e *JSEvaluated) EventName() string {
+	return "JSEvaluated"
+}
+
 // LoginSucceeded is published when login completes successfully.
 type LoginSucceeded struct {
 	baseSessionEvent
@@ -51,6 +77,29 @@ func (e *LoginFailed) EventName() string {
 	return "LoginFailed"
 }
 
+// LoginRetrying is published before a failed login is tried again.
+type LoginRetrying struct {
+	baseSessionEvent
+	Attempt  int           // 1 for the first retry
+	Delay    time.Duration // Wait before this attempt
+	Password bool          // The attempt logs in with the password rather than cookies
+	Error    error         // Why the previous attempt failed
+}
+
+func NewLoginRetrying(sessionID string, attempt int, delay time.Duration, password bool, err error) *LoginRetrying {
+	return &LoginRetrying{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		Attempt:          attempt,
+		Delay:            delay,
+		Password:         password,
+		Error:            err,
+	}
+}
+
+func (e *LoginRetrying) EventName() string {
+	return "LoginRetrying"
+}
+
 // CookiesSaved is published when cookies are saved successfully.
 type CookiesSaved struct {
 	baseSessionEvent
@@ -134,3 +183,71 @@ func NewDriverStarted(sessionID string) *DriverStarted {
 func (e *DriverStarted) EventName() string {
 	return "DriverStarted"
 }
+
+// LatencyUpdated is published periodically with a session's rolling
+// browser round-trip statistics.
+type LatencyUpdated struct {
+	baseSessionEvent
+	Samples int
+	Mean    time.Duration
+	P95     time.Duration
+	Max     time.Duration
+	Lagging bool
+}
+
+func NewLatencyUpdated(sessionID string, samples int, mean, p95, maxRTT time.Duration, lagging bool) *LatencyUpdated {
+	return &LatencyUpdated{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		Samples:          samples,
+		Mean:             mean,
+		P95:              p95,
+		Max:              maxRTT,
+		Lagging:          lagging,
+	}
+}
+
+func (e *LatencyUpdated) EventName() string {
+	return "LatencyUpdated"
+}
+
+// InputLagChanged is published when a session starts or stops lagging,
+// which usually means server lag or throttling.
+type InputLagChanged struct {
+	baseSessionEvent
+	Lagging bool
+	Mean    time.Duration
+	P95     time.Duration
+}
+
+func NewInputLagChanged(sessionID string, lagging bool, mean, p95 time.Duration) *InputLagChanged {
+	return &InputLagChanged{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		Lagging:          lagging,
+		Mean:             mean,
+		P95:              p95,
+	}
+}
+
+func (e *InputLagChanged) EventName() string {
+	return "InputLagChanged"
+}
+
+// GameDataCaptured is published when the data tap reads values from one
+// of the game's API responses.
+type GameDataCaptured struct {
+	baseSessionEvent
+	URL    string
+	Values map[string]int
+}
+
+func NewGameDataCaptured(sessionID, url string, values map[string]int) *GameDataCaptured {
+	return &GameDataCaptured{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		URL:              url,
+		Values:           values,
+	}
+}
+
+func (e *GameDataCaptured) EventName() string {
+	return "GameDataCaptured"
+}
@@ -2,7 +2,12 @@
 // Events represent state changes and are consumed by the presentation layer.
 package event
 
-import "wardenly-go/core/state"
+import (
+	"log/slog"
+	"time"
+
+	"wardenly-go/core/state"
+)
 
 // Event is the base interface for all events.
 // Events are published by the application layer and consumed by subscribers.
@@ -20,13 +25,46 @@ type SessionEvent interface {
 
 // baseSessionEvent provides common implementation for session events.
 type baseSessionEvent struct {
-	sessionID string
+	sessionID     string
+	correlationID string
 }
 
 func (e *baseSessionEvent) SessionID() string {
 	return e.sessionID
 }
 
+func (e *baseSessionEvent) CorrelationID() string {
+	return e.correlationID
+}
+
+func (e *baseSessionEvent) SetCorrelationID(id string) {
+	e.correlationID = id
+}
+
+// Correlated is implemented by session events. The correlation ID is that
+// of the command that caused the event, empty if it was not caused by one.
+type Correlated interface {
+	CorrelationID() string
+	SetCorrelationID(id string)
+}
+
+// CorrelationID returns the correlation ID of e, or "" if it has none.
+func CorrelationID(e Event) string {
+	if c, ok := e.(Correlated); ok {
+		return c.CorrelationID()
+	}
+	return ""
+}
+
+// Correlate sets the correlation ID of e if it is a session event, and
+// returns e.
+func Correlate(e Event, correlationID string) Event {
+	if c, ok := e.(Correlated); ok && correlationID != "" {
+		c.SetCorrelationID(correlationID)
+	}
+	return e
+}
+
 // SessionStarted is published when a session starts successfully.
 type SessionStarted struct {
 	baseSessionEvent
@@ -81,3 +119,150 @@ func NewSessionStateChanged(sessionID string, oldState, newState state.SessionSt
 func (e *SessionStateChanged) EventName() string {
 	return "SessionStateChanged"
 }
+
+// SessionQueued is published when a new session waits for a login slot,
+// and again whenever its place in the queue changes.
+type SessionQueued struct {
+	baseSessionEvent
+	Position int // 1 for the next session to start
+}
+
+func NewSessionQueued(sessionID string, position int) *SessionQueued {
+	return &SessionQueued{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		Position:         position,
+	}
+}
+
+func (e *SessionQueued) EventName() string {
+	return "SessionQueued"
+}
+
+// SessionReconnecting is published before each attempt to restart a
+// session's browser after it exited unexpectedly.
+type SessionReconnecting struct {
+	baseSessionEvent
+	Attempt int           // 1 for the first restart
+	Delay   time.Duration // Wait before this attempt
+	Error   error         // Why the browser exited or the previous attempt failed
+}
+
+func NewSessionReconnecting(sessionID string, attempt int, delay time.Duration, err error) *SessionReconnecting {
+	return &SessionReconnecting{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		Attempt:          attempt,
+		Delay:            delay,
+		Error:            err,
+	}
+}
+
+func (e *SessionReconnecting) EventName() string {
+	return "SessionReconnecting"
+}
+
+// SessionReconnected is published once a crashed session's browser is
+// running again and the login finished. A failed login is reported
+// separately by LoginFailed.
+type SessionReconnected struct {
+	baseSessionEvent
+	Attempts int
+}
+
+func NewSessionReconnected(sessionID string, attempts int) *SessionReconnected {
+	return &SessionReconnected{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		Attempts:         attempts,
+	}
+}
+
+func (e *SessionReconnected) EventName() string {
+	return "SessionReconnected"
+}
+
+// LogRecorded is published for each line logged by a session, so the UI
+// can show its recent log.
+type LogRecorded struct {
+	baseSessionEvent
+	Time  time.Time
+	Level slog.Level
+	Text  string // Message and attributes
+}
+
+func NewLogRecorded(sessionID string, t time.Time, level slog.Level, text string) *LogRecorded {
+	return &LogRecorded{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		Time:             t,
+		Level:            level,
+		Text:             text,
+	}
+}
+
+func (e *LogRecorded) EventName() string {
+	return "LogRecorded"
+}
+
+// AccountsSynced is published after a scheduled account sync with an
+// external spreadsheet. It is not tied to a session.
+type AccountsSynced struct {
+	Added   int
+	Updated int
+	Skipped int   // Rows with invalid values
+	Error   error // Why the sync failed; nothing was changed if the sheet could not be read
+}
+
+func NewAccountsSynced(added, updated, skipped int, err error) *AccountsSynced {
+	return &AccountsSynced{Added: added, Updated: updated, Skipped: skipped, Error: err}
+}
+
+func (e *AccountsSynced) EventName() string {
+	return "AccountsSynced"
+}
+
+// SceneThresholdSuggested is published when a scene keeps scoring just above
+// its match threshold across sessions. It is not tied to a session.
+type SceneThresholdSuggested struct {
+	SceneName string
+	Threshold float64 // Threshold the near misses were measured against
+	Suggested float64 // Threshold that would have matched them
+	Count     int
+	Sessions  int
+	Applied   bool // The scene now matches with the suggested threshold
+}
+
+func NewSceneThresholdSuggested(sceneName string, threshold, suggested float64, count, sessions int, applied bool) *SceneThresholdSuggested {
+	return &SceneThresholdSuggested{
+		SceneName: sceneName,
+		Threshold: threshold,
+		Suggested: suggested,
+		Count:     count,
+		Sessions:  sessions,
+		Applied:   applied,
+	}
+}
+
+func (e *SceneThresholdSuggested) EventName() string {
+	return "SceneThresholdSuggested"
+}
+
+// SceneRegressed is published when an edited scene no longer matches
+// recent frames of running sessions that its previous definition matched.
+// It is not tied to a session.
+type SceneRegressed struct {
+	SceneName   string
+	Frames      int      // Recent frames the scene was checked against
+	Regressions int      // Frames only the previous definition matched
+	Points      []string // Points that missed on those frames, with their diffs
+}
+
+func NewSceneRegressed(sceneName string, frames, regressions int, points []string) *SceneRegressed {
+	return &SceneRegressed{
+		SceneName:   sceneName,
+		Frames:      frames,
+		Regressions: regressions,
+		Points:      points,
+	}
+}
+
+func (e *SceneRegressed) EventName() string {
+	return "SceneRegressed"
+}
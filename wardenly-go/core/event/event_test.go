@@ -3,7 +3,9 @@ package event
 import (
 	"errors"
 	"image"
+	"log/slog"
 	"testing"
+	"time"
 
 	"wardenly-go/core/state"
 )
@@ -16,15 +18,38 @@ func TestEvent_Names(t *testing.T) {
 		{NewSessionStarted("s1", "acc1", "Account 1"), "SessionStarted"},
 		{NewSessionStopped("s1", nil), "SessionStopped"},
 		{NewSessionStateChanged("s1", state.StateIdle, state.StateStarting), "SessionStateChanged"},
+		{NewSessionQueued("s1", 2), "SessionQueued"},
+		{NewSessionReconnecting("s1", 1, time.Second, errors.New("test")), "SessionReconnecting"},
+		{NewSessionReconnected("s1", 1), "SessionReconnected"},
+		{NewLogRecorded("s1", time.Now(), slog.LevelInfo, "Started"), "LogRecorded"},
 		{NewScreenCaptured("s1", nil), "ScreenCaptured"},
 		{NewLoginSucceeded("s1"), "LoginSucceeded"},
 		{NewLoginFailed("s1", errors.New("test")), "LoginFailed"},
+		{NewLoginRetrying("s1", 1, time.Second, true, errors.New("test")), "LoginRetrying"},
 		{NewCookiesSaved("s1"), "CookiesSaved"},
 		{NewOperationFailed("s1", "click", errors.New("test")), "OperationFailed"},
 		{NewScriptStarted("s1", "test"), "ScriptStarted"},
 		{NewScriptStopped("s1", "test", StopReasonNormal, nil), "ScriptStopped"},
-		{NewScriptStepExecuted("s1", 0, "main_city"), "ScriptStepExecuted"},
+		{NewScriptStuck("s1", "test", time.Minute, "stop"), "ScriptStuck"},
+		{NewScreenFrozen("s1", "test", time.Minute, "refresh"), "ScreenFrozen"},
+		{NewScriptHalted("s1", "test", "open_bag", "Click at (1, 2)", nil), "ScriptHalted"},
+		{NewScriptThrottled("s1", "test", "click", 60, time.Second), "ScriptThrottled"},
+		{NewScriptRefused("s1", "test", nil), "ScriptRefused"},
+		{NewSetupFinished("s1", "tutorial", true, nil), "SetupFinished"},
+		{NewScriptStepExecuted("s1", "daily", 0, "main_city", nil, "continue", ""), "ScriptStepExecuted"},
 		{NewScriptSelectionChanged("s1", "test"), "ScriptSelectionChanged"},
+		{NewSessionTuned("s1", 5, time.Second), "SessionTuned"},
+		{NewCountersUpdated("s1", "test", nil), "CountersUpdated"},
+		{NewActionPerformed("s1", "test", "click", 10, 20, 10, 20), "ActionPerformed"},
+		{NewLatencyUpdated("s1", 10, 0, 0, 0, false), "LatencyUpdated"},
+		{NewInputLagChanged("s1", true, 0, 0), "InputLagChanged"},
+		{NewGameDataCaptured("s1", "http://s1/api", nil), "GameDataCaptured"},
+		{NewJSEvaluated("s1", "1 + 1", []byte("2"), nil), "JSEvaluated"},
+		{NewOCRResultRecognized("s1", "test", "quit_when_exhausted", 5, 3, 4, false), "OCRResultRecognized"},
+		{NewOCRTextRecognized("s1", "test", "match_text", []string{"讨伐"}, "讨伐"), "OCRTextRecognized"},
+		{NewScriptsReloaded([]string{"test"}, nil), "ScriptsReloaded"},
+		{NewSceneThresholdSuggested("main_city", 5, 5.4, 10, 2, false), "SceneThresholdSuggested"},
+		{NewSceneRegressed("main_city", 10, 2, []string{"(10, 20) diff 31.0"}), "SceneRegressed"},
 	}
 
 	for _, tt := range tests {
@@ -45,15 +70,30 @@ func TestSessionEvent_SessionID(t *testing.T) {
 		{"SessionStarted", NewSessionStarted("session-123", "acc1", "Account 1"), "session-123"},
 		{"SessionStopped", NewSessionStopped("session-456", nil), "session-456"},
 		{"SessionStateChanged", NewSessionStateChanged("session-789", state.StateIdle, state.StateStarting), "session-789"},
+		{"SessionQueued", NewSessionQueued("session-q", 1), "session-q"},
+		{"SessionReconnecting", NewSessionReconnecting("session-rec", 2, time.Second, nil), "session-rec"},
+		{"SessionReconnected", NewSessionReconnected("session-rec", 2), "session-rec"},
+		{"LogRecorded", NewLogRecorded("session-log", time.Now(), slog.LevelWarn, "Slow"), "session-log"},
 		{"ScreenCaptured", NewScreenCaptured("session-abc", nil), "session-abc"},
 		{"LoginSucceeded", NewLoginSucceeded("session-def"), "session-def"},
 		{"LoginFailed", NewLoginFailed("session-ghi", nil), "session-ghi"},
+		{"LoginRetrying", NewLoginRetrying("session-lr", 2, time.Second, false, nil), "session-lr"},
 		{"CookiesSaved", NewCookiesSaved("session-jkl"), "session-jkl"},
 		{"OperationFailed", NewOperationFailed("session-mno", "click", nil), "session-mno"},
 		{"ScriptStarted", NewScriptStarted("session-pqr", "test"), "session-pqr"},
 		{"ScriptStopped", NewScriptStopped("session-stu", "test", StopReasonNormal, nil), "session-stu"},
-		{"ScriptStepExecuted", NewScriptStepExecuted("session-vwx", 0, "main_city"), "session-vwx"},
+		{"ScriptStepExecuted", NewScriptStepExecuted("session-vwx", "daily", 0, "main_city", nil, "continue", ""), "session-vwx"},
 		{"ScriptSelectionChanged", NewScriptSelectionChanged("session-yz", "test"), "session-yz"},
+		{"SessionTuned", NewSessionTuned("session-tu", 5, time.Second), "session-tu"},
+		{"CountersUpdated", NewCountersUpdated("session-cu", "test", nil), "session-cu"},
+		{"ScriptHalted", NewScriptHalted("session-sh", "test", "", "Wait 1s", nil), "session-sh"},
+		{"ScreenFrozen", NewScreenFrozen("session-fz", "test", time.Minute, "stop"), "session-fz"},
+		{"OCRResultRecognized", NewOCRResultRecognized("session-ocr", "test", "quit_when_exhausted", 5, 3, 4, false), "session-ocr"},
+		{"OCRTextRecognized", NewOCRTextRecognized("session-txt", "test", "match_text", nil, ""), "session-txt"},
+		{"ActionPerformed", NewActionPerformed("session-act", "test", "drag", 1, 2, 3, 4), "session-act"},
+		{"InputLagChanged", NewInputLagChanged("session-lag", false, 0, 0), "session-lag"},
+		{"GameDataCaptured", NewGameDataCaptured("session-tap", "http://s1/api", nil), "session-tap"},
+		{"JSEvaluated", NewJSEvaluated("session-js", "1 + 1", nil, nil), "session-js"},
 	}
 
 	for _, tt := range tests {
@@ -75,6 +115,7 @@ func TestStopReason_String(t *testing.T) {
 		{StopReasonError, "Error"},
 		{StopReasonResourceExhausted, "ResourceExhausted"},
 		{StopReasonBrowserStopped, "BrowserStopped"},
+		{StopReasonStuck, "Stuck"},
 		{StopReason(99), "Unknown"},
 	}
 
@@ -141,3 +182,16 @@ func TestScriptStopped_Fields(t *testing.T) {
 		t.Errorf("Error = %v, want %v", e.Error, testErr)
 	}
 }
+
+func TestCorrelate(t *testing.T) {
+	e := Correlate(NewOperationFailed("s1", "click", errors.New("boom")), "c1")
+	if got := CorrelationID(e); got != "c1" {
+		t.Errorf("CorrelationID() = %q, want c1", got)
+	}
+	if got := CorrelationID(Correlate(NewScriptStarted("s1", "daily"), "")); got != "" {
+		t.Errorf("CorrelationID() = %q without a correlation ID", got)
+	}
+	if got := CorrelationID(Correlate(NewScriptsReloaded(nil, nil), "c1")); got != "" {
+		t.Errorf("CorrelationID() = %q for a non-session event", got)
+	}
+}
@@ -1,5 +1,10 @@
 package event
 
+import (
+	"image"
+	"time"
+)
+
 // ScriptStarted is published when a script starts executing.
 type ScriptStarted struct {
 	baseSessionEvent
@@ -31,6 +36,8 @@ const (
 	StopReasonResourceExhausted
 	// StopReasonBrowserStopped indicates the script stopped because the browser was stopped.
 	StopReasonBrowserStopped
+	// StopReasonStuck indicates the watchdog stopped a script that matched no scene for too long.
+	StopReasonStuck
 )
 
 func (r StopReason) String() string {
@@ -45,6 +52,8 @@ func (r StopReason) String() string {
 		return "ResourceExhausted"
 	case StopReasonBrowserStopped:
 		return "BrowserStopped"
+	case StopReasonStuck:
+		return "Stuck"
 	default:
 		return "Unknown"
 	}
@@ -56,6 +65,8 @@ type ScriptStopped struct {
 	ScriptName string
 	Reason     StopReason
 	Error      error // Non-nil if Reason is StopReasonError
+	// Counters holds the script's counters when it stopped
+	Counters map[string]int
 }
 
 func NewScriptStopped(sessionID, scriptName string, reason StopReason, err error) *ScriptStopped {
@@ -71,18 +82,164 @@ func (e *ScriptStopped) EventName() string {
 	return "ScriptStopped"
 }
 
-// ScriptStepExecuted is published when a script step is executed.
+// ScriptStuck is published when the watchdog finds a script that matched no
+// scene for Idle, before it takes Action ("refresh", "script" or "stop").
+type ScriptStuck struct {
+	baseSessionEvent
+	ScriptName string
+	Idle       time.Duration
+	Action     string
+}
+
+func NewScriptStuck(sessionID, scriptName string, idle time.Duration, action string) *ScriptStuck {
+	return &ScriptStuck{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		ScriptName:       scriptName,
+		Idle:             idle,
+		Action:           action,
+	}
+}
+
+func (e *ScriptStuck) EventName() string {
+	return "ScriptStuck"
+}
+
+// ScreenFrozen is published when the watchdog finds that a running
+// script's screen hasn't changed for Frozen, which usually means the game
+// hung, before it takes Action ("refresh", "script" or "stop").
+type ScreenFrozen struct {
+	baseSessionEvent
+	ScriptName string
+	Frozen     time.Duration
+	Action     string
+}
+
+func NewScreenFrozen(sessionID, scriptName string, frozen time.Duration, action string) *ScreenFrozen {
+	return &ScreenFrozen{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		ScriptName:       scriptName,
+		Frozen:           frozen,
+		Action:           action,
+	}
+}
+
+func (e *ScreenFrozen) EventName() string {
+	return "ScreenFrozen"
+}
+
+// ScriptHalted is published when a script run in debug mode halts before
+// an action, with the screen it is about to act on. Step names the step by
+// its label, or its expected scene without one.
+type ScriptHalted struct {
+	baseSessionEvent
+	ScriptName string
+	Step       string
+	Action     string
+	Screen     image.Image
+}
+
+func NewScriptHalted(sessionID, scriptName, step, action string, screen image.Image) *ScriptHalted {
+	return &ScriptHalted{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		ScriptName:       scriptName,
+		Step:             step,
+		Action:           action,
+		Screen:           screen,
+	}
+}
+
+func (e *ScriptHalted) EventName() string {
+	return "ScriptHalted"
+}
+
+// ScriptThrottled is published when a run reaches its per-minute limit for
+// Action ("click" or "drag") and pauses for Pause.
+type ScriptThrottled struct {
+	baseSessionEvent
+	ScriptName string
+	Action     string
+	Limit      int
+	Pause      time.Duration
+}
+
+func NewScriptThrottled(sessionID, scriptName, action string, limit int, pause time.Duration) *ScriptThrottled {
+	return &ScriptThrottled{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		ScriptName:       scriptName,
+		Action:           action,
+		Limit:            limit,
+		Pause:            pause,
+	}
+}
+
+func (e *ScriptThrottled) EventName() string {
+	return "ScriptThrottled"
+}
+
+// ScriptRefused is published when a script is not permitted to run on a session's account.
+type ScriptRefused struct {
+	baseSessionEvent
+	ScriptName string
+	Reason     error
+}
+
+func NewScriptRefused(sessionID, scriptName string, reason error) *ScriptRefused {
+	return &ScriptRefused{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		ScriptName:       scriptName,
+		Reason:           reason,
+	}
+}
+
+func (e *ScriptRefused) EventName() string {
+	return "ScriptRefused"
+}
+
+// SetupFinished is published when an account's first-login setup script
+// stops. Completed is true if it finished normally and was recorded, so it
+// won't run again; otherwise Error says why not.
+type SetupFinished struct {
+	baseSessionEvent
+	ScriptName string
+	Completed  bool
+	Error      error
+}
+
+func NewSetupFinished(sessionID, scriptName string, completed bool, err error) *SetupFinished {
+	return &SetupFinished{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		ScriptName:       scriptName,
+		Completed:        completed,
+		Error:            err,
+	}
+}
+
+func (e *SetupFinished) EventName() string {
+	return "SetupFinished"
+}
+
+// ScriptStepExecuted is published after a matched script step runs.
+// ScriptName is the script the step belongs to, which differs from the
+// running script inside a call.
 type ScriptStepExecuted struct {
 	baseSessionEvent
-	StepIndex int
-	SceneName string
+	ScriptName string
+	StepIndex  int
+	SceneName  string
+	Actions    []string // Action types of the step, in order
+	Result     string   // continue, quit, exhausted, error, skipped or failed
+	ScreenHash string   // Perceptual hash of the matched screen, hex
 }
 
-func NewScriptStepExecuted(sessionID string, stepIndex int, sceneName string) *ScriptStepExecuted {
+func NewScriptStepExecuted(sessionID, scriptName string, stepIndex int, sceneName string, actions []string, result, screenHash string) *ScriptStepExecuted {
 	return &ScriptStepExecuted{
 		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		ScriptName:       scriptName,
 		StepIndex:        stepIndex,
 		SceneName:        sceneName,
+		Actions:          actions,
+		Result:           result,
+		ScreenHash:       screenHash,
 	}
 }
 
@@ -90,6 +247,32 @@ func (e *ScriptStepExecuted) EventName() string {
 	return "ScriptStepExecuted"
 }
 
+// ActionPerformed is published when a script click or drag reaches the
+// browser. For a click, To equals From.
+type ActionPerformed struct {
+	baseSessionEvent
+	ScriptName   string
+	Action       string
+	FromX, FromY float64
+	ToX, ToY     float64
+}
+
+func NewActionPerformed(sessionID, scriptName, action string, fromX, fromY, toX, toY float64) *ActionPerformed {
+	return &ActionPerformed{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		ScriptName:       scriptName,
+		Action:           action,
+		FromX:            fromX,
+		FromY:            fromY,
+		ToX:              toX,
+		ToY:              toY,
+	}
+}
+
+func (e *ActionPerformed) EventName() string {
+	return "ActionPerformed"
+}
+
 // ScriptSelectionChanged is published when the selected script changes.
 type ScriptSelectionChanged struct {
 	baseSessionEvent
@@ -106,3 +289,141 @@ func NewScriptSelectionChanged(sessionID, scriptName string) *ScriptSelectionCha
 func (e *ScriptSelectionChanged) EventName() string {
 	return "ScriptSelectionChanged"
 }
+
+// CountersUpdated is published with all of a running script's counters
+// when it starts and whenever a variable action changes one.
+type CountersUpdated struct {
+	baseSessionEvent
+	ScriptName string
+	Counters   map[string]int
+}
+
+func NewCountersUpdated(sessionID, scriptName string, counters map[string]int) *CountersUpdated {
+	return &CountersUpdated{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		ScriptName:       scriptName,
+		Counters:         counters,
+	}
+}
+
+func (e *CountersUpdated) EventName() string {
+	return "CountersUpdated"
+}
+
+// SessionTuned is published when a session's matching and pacing are
+// changed while it runs.
+type SessionTuned struct {
+	baseSessionEvent
+	SceneThreshold float64
+	PollInterval   time.Duration
+	// JitterOverride reports whether JitterPixels and JitterWait replace the
+	// jitter of the session's scripts
+	JitterOverride bool
+	JitterPixels   float64
+	JitterWait     float64
+}
+
+func NewSessionTuned(sessionID string, threshold float64, pollInterval time.Duration) *SessionTuned {
+	return &SessionTuned{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		SceneThreshold:   threshold,
+		PollInterval:     pollInterval,
+	}
+}
+
+func (e *SessionTuned) EventName() string {
+	return "SessionTuned"
+}
+
+// OCRROISelected is published when an OCR rule settles on a candidate ROI
+// for a session, so a drifted primary ROI can be spotted and fixed.
+type OCRROISelected struct {
+	baseSessionEvent
+	ScriptName string
+	RuleName   string
+	ROIIndex   int // 0 is the primary ROI, higher values are fallbacks
+	ROI        image.Rectangle
+}
+
+func NewOCRROISelected(sessionID, scriptName, ruleName string, roiIndex int, roi image.Rectangle) *OCRROISelected {
+	return &OCRROISelected{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		ScriptName:       scriptName,
+		RuleName:         ruleName,
+		ROIIndex:         roiIndex,
+		ROI:              roi,
+	}
+}
+
+func (e *OCRROISelected) EventName() string {
+	return "OCRROISelected"
+}
+
+// OCRResultRecognized is published each time an OCR rule reads a value,
+// so monitoring tools can follow resource usage without parsing logs. A
+// read_number rule reports its number as Numerator, with Denominator 0,
+// and the limit it was compared against as Threshold.
+type OCRResultRecognized struct {
+	baseSessionEvent
+	ScriptName  string
+	RuleName    string
+	Numerator   int
+	Denominator int
+	Threshold   int
+	Triggered   bool // The rule stops the script
+}
+
+func NewOCRResultRecognized(sessionID, scriptName, ruleName string, numerator, denominator, threshold int, triggered bool) *OCRResultRecognized {
+	return &OCRResultRecognized{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		ScriptName:       scriptName,
+		RuleName:         ruleName,
+		Numerator:        numerator,
+		Denominator:      denominator,
+		Threshold:        threshold,
+		Triggered:        triggered,
+	}
+}
+
+func (e *OCRResultRecognized) EventName() string {
+	return "OCRResultRecognized"
+}
+
+// OCRTextRecognized is published each time a text OCR rule reads its
+// region, with the expected string that matched (empty if none).
+type OCRTextRecognized struct {
+	baseSessionEvent
+	ScriptName string
+	RuleName   string
+	Lines      []string
+	Matched    string
+}
+
+func NewOCRTextRecognized(sessionID, scriptName, ruleName string, lines []string, matched string) *OCRTextRecognized {
+	return &OCRTextRecognized{
+		baseSessionEvent: baseSessionEvent{sessionID: sessionID},
+		ScriptName:       scriptName,
+		RuleName:         ruleName,
+		Lines:            lines,
+		Matched:          matched,
+	}
+}
+
+func (e *OCRTextRecognized) EventName() string {
+	return "OCRTextRecognized"
+}
+
+// ScriptsReloaded is published after the user scripts directory is reloaded.
+// It is not tied to a session.
+type ScriptsReloaded struct {
+	Names []string // All registered script names, sorted
+	Error error    // Files that failed to load; they keep their previous version
+}
+
+func NewScriptsReloaded(names []string, err error) *ScriptsReloaded {
+	return &ScriptsReloaded{Names: names, Error: err}
+}
+
+func (e *ScriptsReloaded) EventName() string {
+	return "ScriptsReloaded"
+}
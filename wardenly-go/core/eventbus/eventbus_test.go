@@ -298,3 +298,24 @@ func TestEventBus_ConcurrentPublish(t *testing.T) {
 		t.Errorf("Timeout: received %d of %d events", received.Load(), numEvents)
 	}
 }
+
+func TestQueue_DropsWhenFull(t *testing.T) {
+	q := NewQueue(2)
+	for _, name := range []string{"a", "b", "c"} {
+		q.Push(&mockEvent{name: name})
+	}
+	if q.Dropped() != 1 {
+		t.Errorf("dropped = %d, want 1", q.Dropped())
+	}
+
+	first := <-q.Events()
+	if first.Event.EventName() != "a" || first.At.IsZero() {
+		t.Errorf("first = %+v, want a with its time", first)
+	}
+
+	var drained []string
+	q.Drain(func(e Queued) { drained = append(drained, e.Event.EventName()) })
+	if len(drained) != 1 || drained[0] != "b" {
+		t.Errorf("drained %v, want [b]", drained)
+	}
+}
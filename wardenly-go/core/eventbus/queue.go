@@ -0,0 +1,61 @@
+package eventbus
+
+import (
+	"sync/atomic"
+	"time"
+
+	"wardenly-go/core/event"
+)
+
+// Queued is an event and the time it was queued.
+type Queued struct {
+	Event event.Event
+	At    time.Time
+}
+
+// Queue hands events from a handler, which runs on the bus dispatch
+// goroutine and must not block, to a goroutine doing slower work. Events
+// that arrive while the queue is full are dropped rather than slowing the
+// bus down.
+type Queue struct {
+	events  chan Queued
+	dropped atomic.Int64
+}
+
+// NewQueue creates a queue buffering up to size events.
+func NewQueue(size int) *Queue {
+	return &Queue{events: make(chan Queued, size)}
+}
+
+// Push queues e without blocking and reports whether it was kept.
+func (q *Queue) Push(e event.Event) bool {
+	select {
+	case q.events <- Queued{Event: e, At: time.Now()}:
+		return true
+	default:
+		q.dropped.Add(1)
+		return false
+	}
+}
+
+// Events returns the channel queued events are received from.
+func (q *Queue) Events() <-chan Queued {
+	return q.events
+}
+
+// Drain passes the events still queued to handle without waiting for more.
+func (q *Queue) Drain(handle func(Queued)) {
+	for {
+		select {
+		case e := <-q.events:
+			handle(e)
+		default:
+			return
+		}
+	}
+}
+
+// Dropped returns how many events were dropped because the queue was full.
+func (q *Queue) Dropped() int64 {
+	return q.dropped.Load()
+}
@@ -21,6 +21,12 @@ const (
 	StateStopping
 	// StateStopped indicates the session has been terminated.
 	StateStopped
+	// StateReconnecting indicates the browser crashed and is being
+	// restarted before logging in again.
+	StateReconnecting
+	// StateScriptPaused indicates a script is suspended between actions
+	// and will continue where it left off when resumed.
+	StateScriptPaused
 )
 
 // String returns the string representation of the state.
@@ -40,6 +46,10 @@ func (s SessionState) String() string {
 		return "Stopping"
 	case StateStopped:
 		return "Stopped"
+	case StateReconnecting:
+		return "Reconnecting"
+	case StateScriptPaused:
+		return "ScriptPaused"
 	default:
 		return fmt.Sprintf("Unknown(%d)", s)
 	}
@@ -50,9 +60,11 @@ func (s SessionState) String() string {
 var validTransitions = map[SessionState][]SessionState{
 	StateIdle:          {StateStarting},
 	StateStarting:      {StateLoggingIn, StateStopping, StateStopped},
-	StateLoggingIn:     {StateReady, StateStopping, StateStopped},
-	StateReady:         {StateScriptRunning, StateStopping},
-	StateScriptRunning: {StateReady, StateStopping},
+	StateLoggingIn:     {StateReady, StateReconnecting, StateStopping, StateStopped},
+	StateReady:         {StateScriptRunning, StateReconnecting, StateStopping},
+	StateScriptRunning: {StateReady, StateScriptPaused, StateReconnecting, StateStopping},
+	StateScriptPaused:  {StateScriptRunning, StateReady, StateReconnecting, StateStopping},
+	StateReconnecting:  {StateLoggingIn, StateStopping, StateStopped},
 	StateStopping:      {StateStopped},
 	StateStopped:       {}, // Terminal state, no transitions allowed
 }
@@ -89,7 +101,7 @@ func (s SessionState) IsActive() bool {
 // CanAcceptOperations returns true if the session can accept user operations.
 // This includes LoggingIn state to allow screen capture during login.
 func (s SessionState) CanAcceptOperations() bool {
-	return s == StateLoggingIn || s == StateReady || s == StateScriptRunning
+	return s == StateLoggingIn || s == StateReady || s == StateScriptRunning || s == StateScriptPaused
 }
 
 // CanStartScript returns true if a script can be started in this state.
@@ -99,9 +111,19 @@ func (s SessionState) CanStartScript() bool {
 
 // CanStopScript returns true if a script can be stopped in this state.
 func (s SessionState) CanStopScript() bool {
+	return s == StateScriptRunning || s == StateScriptPaused
+}
+
+// CanPauseScript returns true if a script can be paused in this state.
+func (s SessionState) CanPauseScript() bool {
 	return s == StateScriptRunning
 }
 
+// CanResumeScript returns true if a paused script can be resumed in this state.
+func (s SessionState) CanResumeScript() bool {
+	return s == StateScriptPaused
+}
+
 // TransitionError represents an invalid state transition attempt.
 type TransitionError struct {
 	From   SessionState
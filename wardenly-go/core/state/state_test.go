@@ -14,6 +14,8 @@ func TestSessionState_String(t *testing.T) {
 		{StateScriptRunning, "ScriptRunning"},
 		{StateStopping, "Stopping"},
 		{StateStopped, "Stopped"},
+		{StateReconnecting, "Reconnecting"},
+		{StateScriptPaused, "ScriptPaused"},
 		{SessionState(99), "Unknown(99)"},
 	}
 
@@ -58,6 +60,21 @@ func TestSessionState_CanTransitionTo(t *testing.T) {
 		{"ScriptRunning -> Stopping", StateScriptRunning, StateStopping, true},
 		{"ScriptRunning -> Idle (invalid)", StateScriptRunning, StateIdle, false},
 
+		// Pause and resume
+		{"ScriptRunning -> ScriptPaused", StateScriptRunning, StateScriptPaused, true},
+		{"ScriptPaused -> ScriptRunning", StateScriptPaused, StateScriptRunning, true},
+		{"ScriptPaused -> Ready", StateScriptPaused, StateReady, true},
+		{"ScriptPaused -> Reconnecting", StateScriptPaused, StateReconnecting, true},
+		{"ScriptPaused -> Stopping", StateScriptPaused, StateStopping, true},
+		{"Ready -> ScriptPaused (invalid)", StateReady, StateScriptPaused, false},
+
+		// Crash recovery
+		{"Ready -> Reconnecting", StateReady, StateReconnecting, true},
+		{"ScriptRunning -> Reconnecting", StateScriptRunning, StateReconnecting, true},
+		{"Reconnecting -> LoggingIn", StateReconnecting, StateLoggingIn, true},
+		{"Reconnecting -> Stopped", StateReconnecting, StateStopped, true},
+		{"Reconnecting -> Ready (invalid)", StateReconnecting, StateReady, false},
+
 		// Valid transitions from Stopping
 		{"Stopping -> Stopped", StateStopping, StateStopped, true},
 		{"Stopping -> Ready (invalid)", StateStopping, StateReady, false},
@@ -132,6 +149,7 @@ func TestSessionState_CanAcceptOperations(t *testing.T) {
 		{StateLoggingIn, false},
 		{StateReady, true},
 		{StateScriptRunning, true},
+		{StateScriptPaused, true},
 		{StateStopping, false},
 		{StateStopped, false},
 	}
@@ -194,3 +212,30 @@ func TestTransitionError_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionState_PauseAndResume(t *testing.T) {
+	tests := []struct {
+		state             SessionState
+		canPause, canStop bool
+		canResume         bool
+	}{
+		{StateReady, false, false, false},
+		{StateScriptRunning, true, true, false},
+		{StateScriptPaused, false, true, true},
+		{StateStopping, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state.String(), func(t *testing.T) {
+			if got := tt.state.CanPauseScript(); got != tt.canPause {
+				t.Errorf("CanPauseScript() = %v, want %v", got, tt.canPause)
+			}
+			if got := tt.state.CanResumeScript(); got != tt.canResume {
+				t.Errorf("CanResumeScript() = %v, want %v", got, tt.canResume)
+			}
+			if got := tt.state.CanStopScript(); got != tt.canStop {
+				t.Errorf("CanStopScript() = %v, want %v", got, tt.canStop)
+			}
+		})
+	}
+}
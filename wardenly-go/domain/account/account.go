@@ -1,7 +1,13 @@
 // Package account defines the Account entity and related types.
 package account
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Account represents a game account with authentication credentials and metadata.
 type Account struct {
@@ -23,8 +29,110 @@ type Account struct {
 	// ServerID is the game server identifier
 	ServerID int
 
+	// URLTemplate overrides the login page of the login profile, with
+	// "{server}" standing for ServerID, e.g. for a mirror domain or a test
+	// server (optional)
+	URLTemplate string
+
 	// Cookies stores browser cookies for session restoration
 	Cookies []Cookie
+
+	// ScriptParams stores the last prompt values used per script name
+	ScriptParams map[string]map[string]string
+
+	// AllowedScripts restricts the account to these scripts (empty allows all)
+	AllowedScripts []string
+
+	// BlockedScripts are scripts that must never run on this account
+	BlockedScripts []string
+
+	// Archived hides the account from selectors and group runs and prevents
+	// it from being started, while keeping its data
+	Archived bool
+
+	// Proxy routes the account's browser through an HTTP proxy (optional)
+	Proxy *Proxy
+
+	// Browser overrides the default browser settings for the account's
+	// sessions (optional)
+	Browser *BrowserSettings
+
+	// Label is a short tag shown on the account's sessions, e.g. "MAIN"
+	// (optional)
+	Label string
+
+	// LabelColor is the color of the label; empty uses LabelColorGray
+	LabelColor LabelColor
+
+	// SetupScript is a one-time setup flow (e.g. skipping the tutorial) run
+	// the first time a session of the account reaches Ready (optional)
+	SetupScript string
+
+	// SetupCompletedAt is when SetupScript last finished normally; zero
+	// until then
+	SetupCompletedAt time.Time
+}
+
+// LabelColor is one of the colors a session label can be shown in.
+type LabelColor string
+
+const (
+	LabelColorRed    LabelColor = "red"
+	LabelColorOrange LabelColor = "orange"
+	LabelColorYellow LabelColor = "yellow"
+	LabelColorGreen  LabelColor = "green"
+	LabelColorBlue   LabelColor = "blue"
+	LabelColorPurple LabelColor = "purple"
+	LabelColorGray   LabelColor = "gray"
+)
+
+// LabelColors lists the label colors in display order.
+var LabelColors = []LabelColor{
+	LabelColorRed, LabelColorOrange, LabelColorYellow, LabelColorGreen,
+	LabelColorBlue, LabelColorPurple, LabelColorGray,
+}
+
+// OrDefault returns c, or LabelColorGray if c is not a known color.
+func (c LabelColor) OrDefault() LabelColor {
+	if slices.Contains(LabelColors, c) {
+		return c
+	}
+	return LabelColorGray
+}
+
+// Proxy is an HTTP proxy a session's browser connects through.
+type Proxy struct {
+	Host     string
+	Port     int
+	Username string // Optional
+	Password string // Optional
+}
+
+// Enabled returns true if the proxy has an address.
+func (p *Proxy) Enabled() bool {
+	return p != nil && p.Host != "" && p.Port > 0
+}
+
+// BrowserSettings overrides the default browser configuration for an
+// account. Zero fields keep the default.
+type BrowserSettings struct {
+	Headless       *bool // nil keeps the default
+	ViewportWidth  int
+	ViewportHeight int
+	UserDataDir    string
+
+	// PageScale zooms the page, e.g. 0.8; zero keeps 1
+	PageScale float64
+	// DeviceScaleFactor is the emulated device pixel ratio; zero keeps 1
+	DeviceScaleFactor float64
+	// Mobile emulates a mobile device with touch input
+	Mobile bool
+}
+
+// IsZero returns true if no setting is overridden.
+func (b *BrowserSettings) IsZero() bool {
+	return b == nil || (b.Headless == nil && b.ViewportWidth <= 0 && b.ViewportHeight <= 0 && b.UserDataDir == "" &&
+		b.PageScale <= 0 && b.DeviceScaleFactor <= 0 && !b.Mobile)
 }
 
 // Cookie represents a browser cookie for session persistence.
@@ -38,6 +146,13 @@ type Cookie struct {
 	SourcePort   int
 	SourceScheme string
 	Priority     string
+	// Expires is when the cookie expires; zero for session cookies
+	Expires time.Time
+}
+
+// Expired returns true if the cookie has an expiry that is not after now.
+func (c Cookie) Expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !now.Before(c.Expires)
 }
 
 // Identity returns a human-readable identifier for the account.
@@ -46,11 +161,49 @@ func (a *Account) Identity() string {
 	return fmt.Sprintf("%d - %s", a.ServerID, a.RoleName)
 }
 
+// IdentityKey identifies an account by server and role name, ignoring case
+// and surrounding spaces, e.g. to match accounts across stores.
+func IdentityKey(serverID int, roleName string) string {
+	return strconv.Itoa(serverID) + "\x00" + strings.ToLower(strings.TrimSpace(roleName))
+}
+
+// LoginKey identifies an account by server and user name, ignoring case and
+// surrounding spaces. Unlike role names, user names don't change in game.
+func LoginKey(serverID int, userName string) string {
+	return IdentityKey(serverID, userName)
+}
+
+// DisplayName returns the identity prefixed with the label, if any.
+// Format: "MAIN · ServerID - RoleName".
+func (a *Account) DisplayName() string {
+	if a.Label == "" {
+		return a.Identity()
+	}
+	return a.Label + " · " + a.Identity()
+}
+
 // HasCookies returns true if the account has stored cookies.
 func (a *Account) HasCookies() bool {
 	return len(a.Cookies) > 0
 }
 
+// CookiesExpired returns true if any stored cookie has expired by now, in
+// which case logging in with the cookies would fail.
+func (a *Account) CookiesExpired(now time.Time) bool {
+	for _, c := range a.Cookies {
+		if c.Expired(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsSetup returns true if the account has a setup script that hasn't
+// completed yet.
+func (a *Account) NeedsSetup() bool {
+	return a.SetupScript != "" && a.SetupCompletedAt.IsZero()
+}
+
 // Clone creates a deep copy of the account.
 func (a *Account) Clone() *Account {
 	clone := &Account{
@@ -60,6 +213,15 @@ func (a *Account) Clone() *Account {
 		Password: a.Password,
 		Ranking:  a.Ranking,
 		ServerID: a.ServerID,
+		Archived: a.Archived,
+
+		URLTemplate: a.URLTemplate,
+
+		Label:      a.Label,
+		LabelColor: a.LabelColor,
+
+		SetupScript:      a.SetupScript,
+		SetupCompletedAt: a.SetupCompletedAt,
 	}
 
 	if len(a.Cookies) > 0 {
@@ -67,5 +229,89 @@ func (a *Account) Clone() *Account {
 		copy(clone.Cookies, a.Cookies)
 	}
 
+	if a.Proxy != nil {
+		proxy := *a.Proxy
+		clone.Proxy = &proxy
+	}
+
+	if a.Browser != nil {
+		browser := *a.Browser
+		if a.Browser.Headless != nil {
+			headless := *a.Browser.Headless
+			browser.Headless = &headless
+		}
+		clone.Browser = &browser
+	}
+
+	if len(a.AllowedScripts) > 0 {
+		clone.AllowedScripts = make([]string, len(a.AllowedScripts))
+		copy(clone.AllowedScripts, a.AllowedScripts)
+	}
+
+	if len(a.BlockedScripts) > 0 {
+		clone.BlockedScripts = make([]string, len(a.BlockedScripts))
+		copy(clone.BlockedScripts, a.BlockedScripts)
+	}
+
+	if len(a.ScriptParams) > 0 {
+		clone.ScriptParams = make(map[string]map[string]string, len(a.ScriptParams))
+		for name, params := range a.ScriptParams {
+			clone.ScriptParams[name] = cloneParams(params)
+		}
+	}
+
+	return clone
+}
+
+// ActiveAccounts returns the accounts that are not archived, keeping order.
+func ActiveAccounts(accounts []*Account) []*Account {
+	active := make([]*Account, 0, len(accounts))
+	for _, acc := range accounts {
+		if !acc.Archived {
+			active = append(active, acc)
+		}
+	}
+	return active
+}
+
+// CheckScript returns an error if the script may not run on this account.
+// A blocked script is always refused; when AllowedScripts is set, only those may run.
+func (a *Account) CheckScript(scriptName string) error {
+	for _, name := range a.BlockedScripts {
+		if name == scriptName {
+			return fmt.Errorf("script %q is blocked for account %s", scriptName, a.Identity())
+		}
+	}
+
+	if len(a.AllowedScripts) == 0 {
+		return nil
+	}
+	for _, name := range a.AllowedScripts {
+		if name == scriptName {
+			return nil
+		}
+	}
+	return fmt.Errorf("script %q is not allowed for account %s", scriptName, a.Identity())
+}
+
+// RememberedParams returns the last prompt values used for a script.
+// Returns nil if none were remembered.
+func (a *Account) RememberedParams(scriptName string) map[string]string {
+	return a.ScriptParams[scriptName]
+}
+
+// RememberParams stores prompt values for a script, replacing any previous values.
+func (a *Account) RememberParams(scriptName string, params map[string]string) {
+	if a.ScriptParams == nil {
+		a.ScriptParams = make(map[string]map[string]string)
+	}
+	a.ScriptParams[scriptName] = cloneParams(params)
+}
+
+func cloneParams(params map[string]string) map[string]string {
+	clone := make(map[string]string, len(params))
+	for k, v := range params {
+		clone[k] = v
+	}
 	return clone
 }
@@ -1,6 +1,9 @@
 package account
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestAccount_Identity(t *testing.T) {
 	tests := []struct {
@@ -34,6 +37,33 @@ func TestAccount_Identity(t *testing.T) {
 	}
 }
 
+func TestAccount_DisplayName(t *testing.T) {
+	acc := &Account{ServerID: 12, RoleName: "Hero"}
+	if got := acc.DisplayName(); got != "12 - Hero" {
+		t.Errorf("DisplayName() = %q, want identity without label", got)
+	}
+	acc.Label = "MAIN"
+	if got := acc.DisplayName(); got != "MAIN · 12 - Hero" {
+		t.Errorf("DisplayName() = %q", got)
+	}
+
+	clone := acc.Clone()
+	if clone.Label != "MAIN" {
+		t.Errorf("clone label = %q", clone.Label)
+	}
+}
+
+func TestLabelColor_OrDefault(t *testing.T) {
+	if got := LabelColorOrange.OrDefault(); got != LabelColorOrange {
+		t.Errorf("OrDefault() = %q, want orange", got)
+	}
+	for _, c := range []LabelColor{"", "teal"} {
+		if got := c.OrDefault(); got != LabelColorGray {
+			t.Errorf("%q.OrDefault() = %q, want gray", c, got)
+		}
+	}
+}
+
 func TestAccount_HasCookies(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -66,6 +96,30 @@ func TestAccount_HasCookies(t *testing.T) {
 	}
 }
 
+func TestAccount_CookiesExpired(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		cookies  []Cookie
+		expected bool
+	}{
+		{"no cookies", nil, false},
+		{"session cookie", []Cookie{{Name: "sid"}}, false},
+		{"not yet expired", []Cookie{{Name: "sid", Expires: now.Add(time.Minute)}}, false},
+		{"expires now", []Cookie{{Name: "sid", Expires: now}}, true},
+		{"one expired", []Cookie{{Name: "sid"}, {Name: "auth", Expires: now.Add(-time.Hour)}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := &Account{Cookies: tt.cookies}
+			if got := acc.CookiesExpired(now); got != tt.expected {
+				t.Errorf("CookiesExpired() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestAccount_Clone(t *testing.T) {
 	original := &Account{
 		ID:       "123",
@@ -75,6 +129,9 @@ func TestAccount_Clone(t *testing.T) {
 		Ranking:  1,
 		ServerID: 100,
 		Cookies:  []Cookie{{Name: "session", Value: "abc123"}},
+		Archived: true,
+
+		URLTemplate: "https://mirror.example/wly/{server}",
 	}
 
 	clone := original.Clone()
@@ -98,6 +155,12 @@ func TestAccount_Clone(t *testing.T) {
 	if clone.ServerID != original.ServerID {
 		t.Errorf("ServerID not copied")
 	}
+	if !clone.Archived {
+		t.Errorf("Archived not copied")
+	}
+	if clone.URLTemplate != original.URLTemplate {
+		t.Errorf("URLTemplate not copied")
+	}
 
 	// Verify slices are deep copied
 	if len(clone.Cookies) != len(original.Cookies) {
@@ -123,3 +186,146 @@ func TestAccount_Clone_EmptySlices(t *testing.T) {
 		t.Error("Expected nil Cookies for empty original")
 	}
 }
+
+func TestActiveAccounts(t *testing.T) {
+	accounts := []*Account{
+		{ID: "1"},
+		{ID: "2", Archived: true},
+		{ID: "3"},
+	}
+
+	active := ActiveAccounts(accounts)
+	if len(active) != 2 || active[0].ID != "1" || active[1].ID != "3" {
+		t.Errorf("ActiveAccounts() = %v, want accounts 1 and 3", active)
+	}
+}
+
+func TestAccount_RememberParams(t *testing.T) {
+	acc := &Account{ID: "123"}
+
+	if got := acc.RememberedParams("daily"); got != nil {
+		t.Errorf("RememberedParams() = %v, want nil", got)
+	}
+
+	params := map[string]string{"rounds": "5"}
+	acc.RememberParams("daily", params)
+	params["rounds"] = "9"
+
+	if got := acc.RememberedParams("daily")["rounds"]; got != "5" {
+		t.Errorf("RememberedParams()[rounds] = %q, want %q", got, "5")
+	}
+
+	clone := acc.Clone()
+	clone.ScriptParams["daily"]["rounds"] = "7"
+	if got := acc.RememberedParams("daily")["rounds"]; got != "5" {
+		t.Error("ScriptParams was not deep copied")
+	}
+}
+
+func TestAccount_CheckScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		account *Account
+		script  string
+		wantErr bool
+	}{
+		{"no lists", &Account{}, "arena", false},
+		{"blocked", &Account{BlockedScripts: []string{"arena"}}, "arena", true},
+		{"not blocked", &Account{BlockedScripts: []string{"arena"}}, "daily", false},
+		{"allowed", &Account{AllowedScripts: []string{"daily"}}, "daily", false},
+		{"not in allowed", &Account{AllowedScripts: []string{"daily"}}, "arena", true},
+		{"blocked wins", &Account{AllowedScripts: []string{"arena"}, BlockedScripts: []string{"arena"}}, "arena", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.account.CheckScript(tt.script); (err != nil) != tt.wantErr {
+				t.Errorf("CheckScript() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAccount_Clone_ScriptLists(t *testing.T) {
+	original := &Account{
+		AllowedScripts: []string{"daily"},
+		BlockedScripts: []string{"arena"},
+	}
+
+	clone := original.Clone()
+	clone.AllowedScripts[0] = "modified"
+	clone.BlockedScripts[0] = "modified"
+
+	if original.AllowedScripts[0] != "daily" || original.BlockedScripts[0] != "arena" {
+		t.Error("Script lists were not deep copied")
+	}
+}
+
+func TestAccount_Clone_Proxy(t *testing.T) {
+	original := &Account{Proxy: &Proxy{Host: "10.0.0.1", Port: 8080, Username: "u"}}
+
+	clone := original.Clone()
+	clone.Proxy.Port = 3128
+
+	if original.Proxy.Port != 8080 {
+		t.Error("Proxy was not deep copied")
+	}
+}
+
+func TestAccount_Clone_Browser(t *testing.T) {
+	headless := false
+	original := &Account{Browser: &BrowserSettings{Headless: &headless, ViewportWidth: 1280}}
+
+	clone := original.Clone()
+	*clone.Browser.Headless = true
+	clone.Browser.ViewportWidth = 800
+
+	if *original.Browser.Headless || original.Browser.ViewportWidth != 1280 {
+		t.Error("Browser settings were not deep copied")
+	}
+}
+
+func TestBrowserSettings_IsZero(t *testing.T) {
+	var none *BrowserSettings
+	if !none.IsZero() || !(&BrowserSettings{}).IsZero() {
+		t.Error("empty settings should be zero")
+	}
+	headless := true
+	if (&BrowserSettings{Headless: &headless}).IsZero() || (&BrowserSettings{UserDataDir: "/tmp/a"}).IsZero() {
+		t.Error("settings with an override should not be zero")
+	}
+	if (&BrowserSettings{PageScale: 0.8}).IsZero() || (&BrowserSettings{Mobile: true}).IsZero() {
+		t.Error("settings with emulation should not be zero")
+	}
+}
+
+func TestProxy_Enabled(t *testing.T) {
+	var none *Proxy
+	if none.Enabled() {
+		t.Error("nil proxy should be disabled")
+	}
+	if (&Proxy{Host: "10.0.0.1"}).Enabled() {
+		t.Error("proxy without port should be disabled")
+	}
+	if !(&Proxy{Host: "10.0.0.1", Port: 8080}).Enabled() {
+		t.Error("proxy with host and port should be enabled")
+	}
+}
+
+func TestAccount_NeedsSetup(t *testing.T) {
+	acc := &Account{}
+	if acc.NeedsSetup() {
+		t.Error("NeedsSetup() = true without a setup script")
+	}
+	acc.SetupScript = "tutorial"
+	if !acc.NeedsSetup() {
+		t.Error("NeedsSetup() = false before the setup completed")
+	}
+	acc.SetupCompletedAt = time.Now()
+	if acc.NeedsSetup() {
+		t.Error("NeedsSetup() = true after the setup completed")
+	}
+	if clone := acc.Clone(); clone.SetupScript != "tutorial" || !clone.SetupCompletedAt.Equal(acc.SetupCompletedAt) {
+		t.Errorf("Clone() lost the setup: %+v", clone)
+	}
+}
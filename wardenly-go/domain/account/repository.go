@@ -22,6 +22,9 @@ type Repository interface {
 	// This is a specialized method for frequent cookie updates.
 	UpdateCookies(ctx context.Context, id string, cookies []Cookie) error
 
+	// UpdateScriptParams stores the remembered prompt values for one script.
+	UpdateScriptParams(ctx context.Context, id, scriptName string, params map[string]string) error
+
 	// Delete removes an account by its identifier.
 	Delete(ctx context.Context, id string) error
 }
@@ -3,13 +3,16 @@ package account
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sort"
+	"time"
 )
 
 // Common errors for account operations.
 var (
 	ErrAccountNotFound = errors.New("account not found")
 	ErrDuplicateID     = errors.New("account with this ID already exists")
+	ErrAccountArchived = errors.New("account is archived")
 )
 
 // Service provides business logic for account management.
@@ -52,11 +55,50 @@ func (s *Service) ListAccounts(ctx context.Context) ([]*Account, error) {
 	return accounts, nil
 }
 
+// ListActiveAccounts retrieves the accounts that are not archived, in the
+// same order as ListAccounts.
+func (s *Service) ListActiveAccounts(ctx context.Context) ([]*Account, error) {
+	accounts, err := s.ListAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ActiveAccounts(accounts), nil
+}
+
+// SetArchived archives or restores an account. All other data is kept.
+func (s *Service) SetArchived(ctx context.Context, id string, archived bool) error {
+	acc, err := s.GetAccount(ctx, id)
+	if err != nil {
+		return err
+	}
+	if acc.Archived == archived {
+		return nil
+	}
+	acc.Archived = archived
+	return s.repo.Update(ctx, acc)
+}
+
+// CompleteSetup records that the account's setup script finished, so it
+// doesn't run again.
+func (s *Service) CompleteSetup(ctx context.Context, id string, at time.Time) error {
+	acc, err := s.GetAccount(ctx, id)
+	if err != nil {
+		return err
+	}
+	acc.SetupCompletedAt = at
+	return s.repo.Update(ctx, acc)
+}
+
 // SaveCookies updates the cookies for an account.
 func (s *Service) SaveCookies(ctx context.Context, id string, cookies []Cookie) error {
 	return s.repo.UpdateCookies(ctx, id, cookies)
 }
 
+// SaveScriptParams remembers the prompt values used to start a script on an account.
+func (s *Service) SaveScriptParams(ctx context.Context, id, scriptName string, params map[string]string) error {
+	return s.repo.UpdateScriptParams(ctx, id, scriptName, params)
+}
+
 // CreateAccount creates a new account.
 func (s *Service) CreateAccount(ctx context.Context, account *Account) error {
 	return s.repo.Insert(ctx, account)
@@ -67,6 +109,24 @@ func (s *Service) UpdateAccount(ctx context.Context, account *Account) error {
 	return s.repo.Update(ctx, account)
 }
 
+// ApplySync saves the accounts of sync changes, creating new ones and
+// updating existing ones. It stops at the first failure; changes saved
+// before it are kept.
+func (s *Service) ApplySync(ctx context.Context, changes []SyncChange) error {
+	for _, change := range changes {
+		var err error
+		if change.Kind == SyncAdd {
+			err = s.repo.Insert(ctx, change.Account)
+		} else {
+			err = s.repo.Update(ctx, change.Account)
+		}
+		if err != nil {
+			return fmt.Errorf("row %d (%s): %w", change.Row, change.Account.Identity(), err)
+		}
+	}
+	return nil
+}
+
 // DeleteAccount removes an account.
 func (s *Service) DeleteAccount(ctx context.Context, id string) error {
 	return s.repo.Delete(ctx, id)
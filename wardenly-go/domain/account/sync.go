@@ -0,0 +1,279 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SyncField is an account field that can be read from a spreadsheet column.
+type SyncField string
+
+const (
+	SyncFieldServerID   SyncField = "server_id"
+	SyncFieldRoleName   SyncField = "role_name"
+	SyncFieldUserName   SyncField = "user_name"
+	SyncFieldPassword   SyncField = "password"
+	SyncFieldRanking    SyncField = "ranking"
+	SyncFieldLabel      SyncField = "label"
+	SyncFieldLabelColor SyncField = "label_color"
+	SyncFieldArchived   SyncField = "archived"
+)
+
+// SyncFields lists the fields a spreadsheet can set, in display order.
+// ServerID and RoleName identify the account and are required.
+var SyncFields = []SyncField{
+	SyncFieldServerID, SyncFieldRoleName, SyncFieldUserName, SyncFieldPassword,
+	SyncFieldRanking, SyncFieldLabel, SyncFieldLabelColor, SyncFieldArchived,
+}
+
+// ColumnMapping maps account fields to spreadsheet column headers. Fields
+// without an entry use their own name as the header, e.g. "server_id".
+type ColumnMapping map[SyncField]string
+
+// Column returns the header the field is read from.
+func (m ColumnMapping) Column(field SyncField) string {
+	if header := strings.TrimSpace(m[field]); header != "" {
+		return header
+	}
+	return string(field)
+}
+
+// Sheet is a table read from a spreadsheet: a header row and data rows.
+type Sheet struct {
+	Header []string
+	Rows   [][]string
+}
+
+// SyncChangeKind tells whether a sync change creates or updates an account.
+type SyncChangeKind string
+
+const (
+	SyncAdd    SyncChangeKind = "add"
+	SyncUpdate SyncChangeKind = "update"
+)
+
+// FieldChange is one field a sync changes. Values are as written in the
+// spreadsheet; Old is empty for new accounts.
+type FieldChange struct {
+	Field SyncField
+	Old   string
+	New   string
+}
+
+// SyncChange is an account a sync creates or updates.
+type SyncChange struct {
+	Kind SyncChangeKind
+	// Row is the spreadsheet row, counting the header as row 1
+	Row int
+	// Account is the account as it will be saved. For updates it is a
+	// clone of the stored account with the changes applied.
+	Account *Account
+	Fields  []FieldChange
+}
+
+// SyncPlan is the difference between a spreadsheet and the stored accounts.
+type SyncPlan struct {
+	Changes []SyncChange
+	// Unchanged counts rows that match their account exactly
+	Unchanged int
+	// Missing holds stored accounts with no row; a sync leaves them alone
+	Missing []*Account
+	// RowErrors holds rows that were skipped because a value is invalid
+	RowErrors []error
+}
+
+// Counts returns the number of accounts the plan adds and updates.
+func (p *SyncPlan) Counts() (added, updated int) {
+	for _, c := range p.Changes {
+		if c.Kind == SyncAdd {
+			added++
+		} else {
+			updated++
+		}
+	}
+	return added, updated
+}
+
+// PlanSync compares a spreadsheet with the stored accounts. Rows are matched
+// to accounts by server and role name, ignoring case and surrounding spaces.
+// Only mapped columns present in the sheet are compared, and empty cells
+// keep the stored value, so a sheet without passwords never clears them.
+// It fails only if the server or role name column is missing.
+func PlanSync(sheet *Sheet, mapping ColumnMapping, existing []*Account) (*SyncPlan, error) {
+	columns := make(map[SyncField]int)
+	for i, header := range sheet.Header {
+		header = strings.TrimSpace(header)
+		for _, field := range SyncFields {
+			if _, ok := columns[field]; !ok && strings.EqualFold(header, mapping.Column(field)) {
+				columns[field] = i
+			}
+		}
+	}
+	for _, field := range []SyncField{SyncFieldServerID, SyncFieldRoleName} {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("column %q for %s not found", mapping.Column(field), field)
+		}
+	}
+
+	byKey := make(map[string]*Account, len(existing))
+	for _, acc := range existing {
+		byKey[IdentityKey(acc.ServerID, acc.RoleName)] = acc
+	}
+
+	plan := &SyncPlan{}
+	seen := make(map[string]int) // key -> row
+	for i, record := range sheet.Rows {
+		row := i + 2
+		cell := func(field SyncField) (string, bool) {
+			col, ok := columns[field]
+			if !ok || col >= len(record) {
+				return "", false
+			}
+			value := strings.TrimSpace(record[col])
+			return value, value != ""
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		rawServer, _ := cell(SyncFieldServerID)
+		serverID, err := strconv.Atoi(rawServer)
+		if err != nil {
+			plan.RowErrors = append(plan.RowErrors, fmt.Errorf("row %d: invalid server %q", row, rawServer))
+			continue
+		}
+		roleName, ok := cell(SyncFieldRoleName)
+		if !ok {
+			plan.RowErrors = append(plan.RowErrors, fmt.Errorf("row %d: role name is empty", row))
+			continue
+		}
+		key := IdentityKey(serverID, roleName)
+		if first, dup := seen[key]; dup {
+			plan.RowErrors = append(plan.RowErrors, fmt.Errorf("row %d: duplicates row %d", row, first))
+			continue
+		}
+		seen[key] = row
+
+		change := SyncChange{Row: row}
+		if stored := byKey[key]; stored != nil {
+			change.Kind = SyncUpdate
+			change.Account = stored.Clone()
+		} else {
+			change.Kind = SyncAdd
+			change.Account = &Account{ServerID: serverID, RoleName: roleName}
+		}
+
+		var rowErrs []error
+		for _, field := range SyncFields[2:] {
+			value, ok := cell(field)
+			if !ok {
+				continue
+			}
+			old := syncValue(change.Account, field)
+			if err := setSyncValue(change.Account, field, value); err != nil {
+				rowErrs = append(rowErrs, fmt.Errorf("row %d: %w", row, err))
+				continue
+			}
+			updated := syncValue(change.Account, field)
+			if change.Kind == SyncAdd {
+				change.Fields = append(change.Fields, FieldChange{Field: field, New: updated})
+			} else if updated != old {
+				change.Fields = append(change.Fields, FieldChange{Field: field, Old: old, New: updated})
+			}
+		}
+		if len(rowErrs) > 0 {
+			plan.RowErrors = append(plan.RowErrors, rowErrs...)
+			continue
+		}
+
+		switch {
+		case change.Kind == SyncAdd:
+			plan.Changes = append(plan.Changes, change)
+		case len(change.Fields) > 0:
+			plan.Changes = append(plan.Changes, change)
+		default:
+			plan.Unchanged++
+		}
+	}
+
+	for _, acc := range existing {
+		if _, ok := seen[IdentityKey(acc.ServerID, acc.RoleName)]; !ok {
+			plan.Missing = append(plan.Missing, acc)
+		}
+	}
+	return plan, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// syncValue returns a field in the form a spreadsheet writes it.
+func syncValue(acc *Account, field SyncField) string {
+	switch field {
+	case SyncFieldUserName:
+		return acc.UserName
+	case SyncFieldPassword:
+		return acc.Password
+	case SyncFieldRanking:
+		return strconv.Itoa(acc.Ranking)
+	case SyncFieldLabel:
+		return acc.Label
+	case SyncFieldLabelColor:
+		return string(acc.LabelColor)
+	case SyncFieldArchived:
+		return strconv.FormatBool(acc.Archived)
+	}
+	return ""
+}
+
+// setSyncValue parses a spreadsheet cell into a field.
+func setSyncValue(acc *Account, field SyncField, value string) error {
+	switch field {
+	case SyncFieldUserName:
+		acc.UserName = value
+	case SyncFieldPassword:
+		acc.Password = value
+	case SyncFieldRanking:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid ranking %q", value)
+		}
+		acc.Ranking = n
+	case SyncFieldLabel:
+		acc.Label = value
+	case SyncFieldLabelColor:
+		color := LabelColor(strings.ToLower(value))
+		if color.OrDefault() != color {
+			return fmt.Errorf("unknown label color %q", value)
+		}
+		acc.LabelColor = color
+	case SyncFieldArchived:
+		archived, err := parseSyncBool(value)
+		if err != nil {
+			return err
+		}
+		acc.Archived = archived
+	default:
+		return errors.New("field cannot be synced: " + string(field))
+	}
+	return nil
+}
+
+// parseSyncBool accepts the spellings spreadsheets commonly use.
+func parseSyncBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "1", "x":
+		return true, nil
+	case "false", "no", "n", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid archived value %q", value)
+}
@@ -0,0 +1,104 @@
+package account
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanSync(t *testing.T) {
+	existing := []*Account{
+		{ID: "a1", ServerID: 1, RoleName: "Alice", UserName: "alice", Password: "secret", Ranking: 1},
+		{ID: "a2", ServerID: 1, RoleName: "Bob", UserName: "bob", Ranking: 2},
+		{ID: "a3", ServerID: 2, RoleName: "Carol"},
+	}
+	sheet := &Sheet{
+		Header: []string{"Server", " Character ", "Login", "password", "ranking", "Tag"},
+		Rows: [][]string{
+			{"1", "alice", "alice", "", "5", "MAIN"}, // empty password keeps it
+			{"1", "Bob", "bob", "", "2", ""},         // unchanged
+			{"3", "Dave", "dave", "pw", "", ""},      // new
+			{"x", "Eve", "", "", "", ""},             // bad server
+			{"1", "Bob", "", "", "", ""},             // duplicate
+			{"1", "Frank", "", "", "high", ""},       // bad ranking
+			{"", "", "", "", "", ""},                 // blank
+		},
+	}
+	mapping := ColumnMapping{
+		SyncFieldServerID: "server",
+		SyncFieldRoleName: "character",
+		SyncFieldUserName: "Login",
+		SyncFieldLabel:    "Tag",
+	}
+
+	plan, err := PlanSync(sheet, mapping, existing)
+	if err != nil {
+		t.Fatalf("PlanSync() error = %v", err)
+	}
+	if added, updated := plan.Counts(); added != 1 || updated != 1 {
+		t.Fatalf("Counts() = %d added, %d updated, want 1 and 1", added, updated)
+	}
+
+	update := plan.Changes[0]
+	if update.Kind != SyncUpdate || update.Account.ID != "a1" || update.Row != 2 {
+		t.Errorf("first change = %+v, want an update of a1 from row 2", update)
+	}
+	wantFields := []FieldChange{
+		{Field: SyncFieldRanking, Old: "1", New: "5"},
+		{Field: SyncFieldLabel, Old: "", New: "MAIN"},
+	}
+	if !reflect.DeepEqual(update.Fields, wantFields) {
+		t.Errorf("update fields = %+v, want %+v", update.Fields, wantFields)
+	}
+	if update.Account.Password != "secret" {
+		t.Error("an empty cell should keep the stored password")
+	}
+	if existing[0].Ranking != 1 {
+		t.Error("planning should not modify stored accounts")
+	}
+
+	add := plan.Changes[1]
+	if add.Kind != SyncAdd || add.Account.ServerID != 3 || add.Account.RoleName != "Dave" || add.Account.Password != "pw" {
+		t.Errorf("second change = %+v, want Dave added on server 3", add.Account)
+	}
+
+	if plan.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1", plan.Unchanged)
+	}
+	if len(plan.RowErrors) != 3 {
+		t.Errorf("RowErrors = %v, want 3", plan.RowErrors)
+	}
+	if len(plan.Missing) != 1 || plan.Missing[0].ID != "a3" {
+		t.Errorf("Missing = %v, want a3", plan.Missing)
+	}
+}
+
+func TestPlanSync_RequiredColumns(t *testing.T) {
+	sheet := &Sheet{Header: []string{"server_id", "user_name"}}
+	if _, err := PlanSync(sheet, nil, nil); err == nil {
+		t.Error("PlanSync() should fail without a role name column")
+	}
+}
+
+func TestPlanSync_Values(t *testing.T) {
+	sheet := &Sheet{
+		Header: []string{"server_id", "role_name", "label_color", "archived"},
+		Rows: [][]string{
+			{"1", "A", "Blue", "yes"},
+			{"1", "B", "pink", ""},
+			{"1", "C", "", "maybe"},
+		},
+	}
+	plan, err := PlanSync(sheet, nil, nil)
+	if err != nil {
+		t.Fatalf("PlanSync() error = %v", err)
+	}
+	if len(plan.Changes) != 1 {
+		t.Fatalf("Changes = %d, want 1", len(plan.Changes))
+	}
+	if acc := plan.Changes[0].Account; acc.LabelColor != LabelColorBlue || !acc.Archived {
+		t.Errorf("account = %+v, want blue and archived", acc)
+	}
+	if len(plan.RowErrors) != 2 {
+		t.Errorf("RowErrors = %v, want the unknown color and archived value", plan.RowErrors)
+	}
+}
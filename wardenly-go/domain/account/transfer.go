@@ -0,0 +1,269 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExportVersion is the version of the export format written by ExportAll.
+const ExportVersion = 1
+
+// Format is the file format of an export.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// FormatForPath returns FormatYAML for .yaml and .yml files, otherwise FormatJSON.
+func FormatForPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	default:
+		return FormatJSON
+	}
+}
+
+// Marshal encodes v in the format.
+func (f Format) Marshal(v any) ([]byte, error) {
+	if f == FormatYAML {
+		return yaml.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// Unmarshal decodes data in the format into v.
+func (f Format) Unmarshal(data []byte, v any) error {
+	if f == FormatYAML {
+		return yaml.Unmarshal(data, v)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// CheckExportVersion returns an error if an export was written by a newer version.
+func CheckExportVersion(version int) error {
+	if version > ExportVersion {
+		return fmt.Errorf("export version %d is newer than supported version %d", version, ExportVersion)
+	}
+	return nil
+}
+
+// ImportResult summarizes an import.
+type ImportResult struct {
+	Added int
+	// Skipped lists entries that were already stored or repeated in the file
+	Skipped []string
+	// Warnings lists problems that didn't stop an entry from being imported
+	Warnings []string
+	// Unmatched lists group members with no matching account, as
+	// "group: member"; the groups are imported without them
+	Unmatched []string
+}
+
+// accountExport is the document written by ExportAll.
+type accountExport struct {
+	Version  int             `json:"version" yaml:"version"`
+	Accounts []accountRecord `json:"accounts" yaml:"accounts"`
+}
+
+// accountRecord is an exported account. IDs are not exported: imported
+// accounts get new ones, and groups refer to accounts by server and role name.
+type accountRecord struct {
+	ServerID         int                          `json:"serverId" yaml:"serverId"`
+	RoleName         string                       `json:"roleName" yaml:"roleName"`
+	URLTemplate      string                       `json:"urlTemplate,omitempty" yaml:"urlTemplate,omitempty"`
+	UserName         string                       `json:"userName,omitempty" yaml:"userName,omitempty"`
+	Password         string                       `json:"password,omitempty" yaml:"password,omitempty"`
+	Ranking          int                          `json:"ranking,omitempty" yaml:"ranking,omitempty"`
+	Archived         bool                         `json:"archived,omitempty" yaml:"archived,omitempty"`
+	Label            string                       `json:"label,omitempty" yaml:"label,omitempty"`
+	LabelColor       LabelColor                   `json:"labelColor,omitempty" yaml:"labelColor,omitempty"`
+	AllowedScripts   []string                     `json:"allowedScripts,omitempty" yaml:"allowedScripts,omitempty"`
+	BlockedScripts   []string                     `json:"blockedScripts,omitempty" yaml:"blockedScripts,omitempty"`
+	Proxy            *proxyRecord                 `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	Browser          *browserRecord               `json:"browser,omitempty" yaml:"browser,omitempty"`
+	SetupScript      string                       `json:"setupScript,omitempty" yaml:"setupScript,omitempty"`
+	SetupCompletedAt time.Time                    `json:"setupCompletedAt,omitzero" yaml:"setupCompletedAt,omitempty"`
+	ScriptParams     map[string]map[string]string `json:"scriptParams,omitempty" yaml:"scriptParams,omitempty"`
+	Cookies          []cookieRecord               `json:"cookies,omitempty" yaml:"cookies,omitempty"`
+}
+
+type proxyRecord struct {
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+}
+
+type browserRecord struct {
+	Headless          *bool   `json:"headless,omitempty" yaml:"headless,omitempty"`
+	ViewportWidth     int     `json:"viewportWidth,omitempty" yaml:"viewportWidth,omitempty"`
+	ViewportHeight    int     `json:"viewportHeight,omitempty" yaml:"viewportHeight,omitempty"`
+	UserDataDir       string  `json:"userDataDir,omitempty" yaml:"userDataDir,omitempty"`
+	PageScale         float64 `json:"pageScale,omitempty" yaml:"pageScale,omitempty"`
+	DeviceScaleFactor float64 `json:"deviceScaleFactor,omitempty" yaml:"deviceScaleFactor,omitempty"`
+	Mobile            bool    `json:"mobile,omitempty" yaml:"mobile,omitempty"`
+}
+
+type cookieRecord struct {
+	Name         string    `json:"name" yaml:"name"`
+	Value        string    `json:"value" yaml:"value"`
+	Domain       string    `json:"domain,omitempty" yaml:"domain,omitempty"`
+	Path         string    `json:"path,omitempty" yaml:"path,omitempty"`
+	HTTPOnly     bool      `json:"httpOnly,omitempty" yaml:"httpOnly,omitempty"`
+	Secure       bool      `json:"secure,omitempty" yaml:"secure,omitempty"`
+	SourcePort   int       `json:"sourcePort,omitempty" yaml:"sourcePort,omitempty"`
+	SourceScheme string    `json:"sourceScheme,omitempty" yaml:"sourceScheme,omitempty"`
+	Priority     string    `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Expires      time.Time `json:"expires,omitzero" yaml:"expires,omitempty"`
+}
+
+// ExportAll encodes all accounts, including archived ones, for a backup or
+// another machine. Passwords and cookies are written in plaintext.
+func (s *Service) ExportAll(ctx context.Context, format Format) ([]byte, error) {
+	accounts, err := s.ListAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := accountExport{Version: ExportVersion, Accounts: make([]accountRecord, len(accounts))}
+	for i, acc := range accounts {
+		doc.Accounts[i] = toRecord(acc)
+	}
+	return format.Marshal(doc)
+}
+
+// ImportAll creates the accounts of an export. Accounts that match a stored
+// account by server and role name, or an earlier entry of the file, are
+// skipped and never overwritten. It stops at the first failure; accounts
+// created before it are kept.
+func (s *Service) ImportAll(ctx context.Context, data []byte, format Format) (*ImportResult, error) {
+	var doc accountExport
+	if err := format.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse accounts: %w", err)
+	}
+	if err := CheckExportVersion(doc.Version); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(existing)+len(doc.Accounts))
+	for _, acc := range existing {
+		seen[IdentityKey(acc.ServerID, acc.RoleName)] = true
+	}
+
+	result := &ImportResult{}
+	for i, rec := range doc.Accounts {
+		acc := rec.toAccount()
+		if strings.TrimSpace(acc.RoleName) == "" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("account %d: role name is empty", i+1))
+			continue
+		}
+		key := IdentityKey(acc.ServerID, acc.RoleName)
+		if seen[key] {
+			result.Skipped = append(result.Skipped, acc.Identity())
+			continue
+		}
+		if err := s.repo.Insert(ctx, acc); err != nil {
+			return result, fmt.Errorf("%s: %w", acc.Identity(), err)
+		}
+		seen[key] = true
+		result.Added++
+	}
+	return result, nil
+}
+
+func toRecord(acc *Account) accountRecord {
+	rec := accountRecord{
+		ServerID:         acc.ServerID,
+		RoleName:         acc.RoleName,
+		URLTemplate:      acc.URLTemplate,
+		UserName:         acc.UserName,
+		Password:         acc.Password,
+		Ranking:          acc.Ranking,
+		Archived:         acc.Archived,
+		Label:            acc.Label,
+		LabelColor:       acc.LabelColor,
+		AllowedScripts:   acc.AllowedScripts,
+		BlockedScripts:   acc.BlockedScripts,
+		SetupScript:      acc.SetupScript,
+		SetupCompletedAt: acc.SetupCompletedAt,
+		ScriptParams:     acc.ScriptParams,
+	}
+	if acc.Proxy != nil {
+		rec.Proxy = &proxyRecord{
+			Host:     acc.Proxy.Host,
+			Port:     acc.Proxy.Port,
+			Username: acc.Proxy.Username,
+			Password: acc.Proxy.Password,
+		}
+	}
+	if !acc.Browser.IsZero() {
+		rec.Browser = &browserRecord{
+			Headless:          acc.Browser.Headless,
+			ViewportWidth:     acc.Browser.ViewportWidth,
+			ViewportHeight:    acc.Browser.ViewportHeight,
+			UserDataDir:       acc.Browser.UserDataDir,
+			PageScale:         acc.Browser.PageScale,
+			DeviceScaleFactor: acc.Browser.DeviceScaleFactor,
+			Mobile:            acc.Browser.Mobile,
+		}
+	}
+	for _, c := range acc.Cookies {
+		rec.Cookies = append(rec.Cookies, cookieRecord(c))
+	}
+	return rec
+}
+
+func (rec *accountRecord) toAccount() *Account {
+	acc := &Account{
+		ServerID:         rec.ServerID,
+		RoleName:         strings.TrimSpace(rec.RoleName),
+		URLTemplate:      strings.TrimSpace(rec.URLTemplate),
+		UserName:         rec.UserName,
+		Password:         rec.Password,
+		Ranking:          rec.Ranking,
+		Archived:         rec.Archived,
+		Label:            rec.Label,
+		LabelColor:       rec.LabelColor,
+		AllowedScripts:   rec.AllowedScripts,
+		BlockedScripts:   rec.BlockedScripts,
+		SetupScript:      rec.SetupScript,
+		SetupCompletedAt: rec.SetupCompletedAt,
+		ScriptParams:     rec.ScriptParams,
+	}
+	if rec.Proxy != nil {
+		acc.Proxy = &Proxy{
+			Host:     rec.Proxy.Host,
+			Port:     rec.Proxy.Port,
+			Username: rec.Proxy.Username,
+			Password: rec.Proxy.Password,
+		}
+	}
+	if rec.Browser != nil {
+		acc.Browser = &BrowserSettings{
+			Headless:          rec.Browser.Headless,
+			ViewportWidth:     rec.Browser.ViewportWidth,
+			ViewportHeight:    rec.Browser.ViewportHeight,
+			UserDataDir:       rec.Browser.UserDataDir,
+			PageScale:         rec.Browser.PageScale,
+			DeviceScaleFactor: rec.Browser.DeviceScaleFactor,
+			Mobile:            rec.Browser.Mobile,
+		}
+	}
+	for _, c := range rec.Cookies {
+		acc.Cookies = append(acc.Cookies, Cookie(c))
+	}
+	return acc
+}
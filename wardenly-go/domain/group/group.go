@@ -23,13 +23,29 @@ type Group struct {
 	// AccountIDs is the list of account IDs in this group
 	AccountIDs []string
 
+	// Query makes this a smart group whose members are the accounts
+	// matching it at run time; AccountIDs is then ignored (see ParseQuery)
+	Query string
+
 	// Ranking is for sorting groups in UI (lower = higher priority)
 	Ranking int
+
+	// Settings control how the group is run
+	Settings RunSettings
+
+	// TemplateID is the template the group was created from (empty if none)
+	TemplateID string
+}
+
+// IsSmart returns true if the group's members are resolved from its query.
+func (g *Group) IsSmart() bool {
+	return g.Query != ""
 }
 
 // IsEmpty returns true if the group has no accounts.
+// Smart groups are never empty until their query is resolved.
 func (g *Group) IsEmpty() bool {
-	return len(g.AccountIDs) == 0
+	return !g.IsSmart() && len(g.AccountIDs) == 0
 }
 
 // AccountCount returns the number of accounts in the group.
@@ -75,7 +91,10 @@ func (g *Group) Clone() *Group {
 		ID:          g.ID,
 		Name:        g.Name,
 		Description: g.Description,
+		Query:       g.Query,
 		Ranking:     g.Ranking,
+		Settings:    g.Settings,
+		TemplateID:  g.TemplateID,
 	}
 
 	if len(g.AccountIDs) > 0 {
@@ -0,0 +1,222 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"wardenly-go/domain/account"
+)
+
+// ErrInvalidQuery is returned for smart group queries that don't parse.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// QueryFields lists the account fields a query can test, in display order.
+// "tag" is the account label.
+var QueryFields = []string{"server_id", "ranking", "role_name", "user_name", "tag", "label_color"}
+
+// QueryOperators lists the comparison operators, in display order.
+var QueryOperators = []string{"=", "!=", "<", "<=", ">", ">="}
+
+// Query selects accounts by their fields, e.g.
+//
+//	server_id=126 AND tag=farm OR role_name="Big Boss"
+//
+// Conditions are joined with AND and OR (case-insensitive); AND binds
+// tighter. Text fields compare case-insensitively and only support = and
+// !=; number fields support all operators. Values with spaces are quoted.
+type Query struct {
+	// any holds the OR branches, each a list of conditions that must all hold
+	any [][]condition
+}
+
+type condition struct {
+	field string
+	op    string
+	text  string
+	num   int
+}
+
+// ParseQuery parses a smart group query.
+func ParseQuery(s string) (*Query, error) {
+	tokens, err := tokenizeQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty query", ErrInvalidQuery)
+	}
+
+	q := &Query{}
+	var branch []condition
+	for i := 0; ; i += 4 {
+		if len(tokens) < i+3 {
+			return nil, fmt.Errorf("%w: incomplete condition at the end", ErrInvalidQuery)
+		}
+		c, err := parseCondition(tokens[i], tokens[i+1], tokens[i+2])
+		if err != nil {
+			return nil, err
+		}
+		branch = append(branch, c)
+
+		if len(tokens) == i+3 {
+			break
+		}
+		switch strings.ToUpper(tokens[i+3]) {
+		case "AND":
+		case "OR":
+			q.any = append(q.any, branch)
+			branch = nil
+		default:
+			return nil, fmt.Errorf("%w: expected AND or OR, got %q", ErrInvalidQuery, tokens[i+3])
+		}
+	}
+	q.any = append(q.any, branch)
+	return q, nil
+}
+
+func parseCondition(field, op, value string) (condition, error) {
+	field = strings.ToLower(field)
+	c := condition{field: field, op: op, text: value}
+
+	if !slices.Contains(QueryOperators, op) {
+		return c, fmt.Errorf("%w: unknown operator %q", ErrInvalidQuery, op)
+	}
+	switch field {
+	case "server_id", "ranking":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return c, fmt.Errorf("%w: %s needs a number, got %q", ErrInvalidQuery, field, value)
+		}
+		c.num = n
+	case "role_name", "user_name", "tag", "label_color":
+		if op != "=" && op != "!=" {
+			return c, fmt.Errorf("%w: %s only supports = and !=", ErrInvalidQuery, field)
+		}
+	default:
+		return c, fmt.Errorf("%w: unknown field %q", ErrInvalidQuery, field)
+	}
+	return c, nil
+}
+
+// tokenizeQuery splits a query into words, operators and quoted values.
+func tokenizeQuery(s string) ([]string, error) {
+	var tokens []string
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidQuery)
+			}
+			tokens = append(tokens, string(runes[i+1:end]))
+			i = end + 1
+		case strings.ContainsRune("=!<>", r):
+			end := i + 1
+			if end < len(runes) && runes[end] == '=' {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("=!<>\"", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// Match reports whether the account satisfies the query.
+func (q *Query) Match(acc *account.Account) bool {
+	for _, branch := range q.any {
+		all := true
+		for _, c := range branch {
+			if !c.match(acc) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the accounts that satisfy the query.
+func (q *Query) Filter(accounts []*account.Account) []*account.Account {
+	var matched []*account.Account
+	for _, acc := range accounts {
+		if q.Match(acc) {
+			matched = append(matched, acc)
+		}
+	}
+	return matched
+}
+
+func (c condition) match(acc *account.Account) bool {
+	switch c.field {
+	case "server_id":
+		return compareInt(acc.ServerID, c.op, c.num)
+	case "ranking":
+		return compareInt(acc.Ranking, c.op, c.num)
+	case "role_name":
+		return c.matchText(acc.RoleName)
+	case "user_name":
+		return c.matchText(acc.UserName)
+	case "tag":
+		return c.matchText(acc.Label)
+	case "label_color":
+		return c.matchText(string(acc.LabelColor.OrDefault()))
+	}
+	return false
+}
+
+func (c condition) matchText(value string) bool {
+	equal := strings.EqualFold(value, c.text)
+	if c.op == "!=" {
+		return !equal
+	}
+	return equal
+}
+
+func compareInt(a int, op string, b int) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+// FormatCondition renders one condition for appending to a query, quoting
+// values that would not survive as a single word.
+func FormatCondition(field, op, value string) string {
+	if value == "" || strings.ContainsAny(value, " \t=!<>") {
+		value = `"` + value + `"`
+	}
+	return field + op + value
+}
@@ -0,0 +1,71 @@
+package group
+
+import (
+	"errors"
+	"testing"
+
+	"wardenly-go/domain/account"
+)
+
+func TestParseQuery_Match(t *testing.T) {
+	farm := &account.Account{ServerID: 126, Ranking: 3, RoleName: "Big Boss", Label: "FARM", LabelColor: account.LabelColorGreen}
+	main := &account.Account{ServerID: 126, Ranking: 1, RoleName: "Alice", Label: "main"}
+	other := &account.Account{ServerID: 7, Ranking: 5, RoleName: "Bob", Label: "farm"}
+
+	tests := []struct {
+		query string
+		want  []bool // farm, main, other
+	}{
+		{"server_id=126 AND tag=farm", []bool{true, false, false}},
+		{"server_id = 126 and tag != farm", []bool{false, true, false}},
+		{"tag=farm AND server_id=126 OR role_name=bob", []bool{true, false, true}},
+		{`role_name="Big Boss"`, []bool{true, false, false}},
+		{"ranking>=3", []bool{true, false, true}},
+		{"ranking<3 OR server_id<10", []bool{false, true, true}},
+		{"label_color=gray", []bool{false, true, true}},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) error = %v", tt.query, err)
+			continue
+		}
+		for i, acc := range []*account.Account{farm, main, other} {
+			if got := q.Match(acc); got != tt.want[i] {
+				t.Errorf("%q matches %s = %v, want %v", tt.query, acc.RoleName, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestParseQuery_Invalid(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"server_id",
+		"server_id=126 AND",
+		"server_id=abc",
+		"tag>farm",
+		"level=3",
+		"server_id=126 tag=farm",
+		`role_name="Big`,
+		"server_id!126",
+	} {
+		if _, err := ParseQuery(query); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("ParseQuery(%q) error = %v, want ErrInvalidQuery", query, err)
+		}
+	}
+}
+
+func TestFormatCondition(t *testing.T) {
+	cond := FormatCondition("role_name", "=", "Big Boss")
+	if cond != `role_name="Big Boss"` {
+		t.Fatalf("FormatCondition = %s", cond)
+	}
+	q, err := ParseQuery(cond + " AND " + FormatCondition("server_id", ">", "100"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Match(&account.Account{RoleName: "big boss", ServerID: 126}) {
+		t.Error("formatted conditions should parse back")
+	}
+}
@@ -28,3 +28,22 @@ type Repository interface {
 	// Delete removes a group by its identifier.
 	Delete(ctx context.Context, id string) error
 }
+
+// TemplateRepository defines the interface for template persistence operations.
+type TemplateRepository interface {
+	// FindByID retrieves a template by its unique identifier.
+	// Returns nil if not found.
+	FindByID(ctx context.Context, id string) (*Template, error)
+
+	// FindAll retrieves all templates.
+	FindAll(ctx context.Context) ([]*Template, error)
+
+	// Insert creates a new template.
+	Insert(ctx context.Context, tmpl *Template) error
+
+	// Update updates an existing template.
+	Update(ctx context.Context, tmpl *Template) error
+
+	// Delete removes a template by its identifier.
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,78 @@
+package group
+
+import (
+	"strings"
+	"unicode"
+
+	"wardenly-go/domain/account"
+)
+
+// RosterMatch is the result of matching OCR'd guild roster lines to accounts.
+type RosterMatch struct {
+	// Matched holds the matched accounts in roster order, without duplicates.
+	Matched []*account.Account
+	// Unmatched holds roster lines that matched no account.
+	Unmatched []string
+}
+
+// AccountIDs returns the IDs of the matched accounts.
+func (m *RosterMatch) AccountIDs() []string {
+	ids := make([]string, len(m.Matched))
+	for i, acc := range m.Matched {
+		ids[i] = acc.ID
+	}
+	return ids
+}
+
+// MatchRoster matches roster lines against account role names.
+// A line matches when, ignoring case and whitespace, it equals a role name or
+// one of its whitespace-separated fields does (OCR often picks up levels or
+// titles next to the name). serverID restricts matches to one server; 0 matches any.
+func MatchRoster(lines []string, accounts []*account.Account, serverID int) *RosterMatch {
+	byName := make(map[string]*account.Account)
+	for _, acc := range accounts {
+		if serverID != 0 && acc.ServerID != serverID {
+			continue
+		}
+		if key := normalizeRoleName(acc.RoleName); key != "" {
+			byName[key] = acc
+		}
+	}
+
+	result := &RosterMatch{}
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		acc := byName[normalizeRoleName(line)]
+		if acc == nil {
+			for _, field := range strings.Fields(line) {
+				if acc = byName[normalizeRoleName(field)]; acc != nil {
+					break
+				}
+			}
+		}
+
+		switch {
+		case acc == nil:
+			result.Unmatched = append(result.Unmatched, line)
+		case !seen[acc.ID]:
+			seen[acc.ID] = true
+			result.Matched = append(result.Matched, acc)
+		}
+	}
+	return result
+}
+
+// normalizeRoleName lowercases a name and strips all whitespace.
+func normalizeRoleName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, s)
+}
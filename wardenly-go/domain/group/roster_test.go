@@ -0,0 +1,37 @@
+package group
+
+import (
+	"reflect"
+	"testing"
+
+	"wardenly-go/domain/account"
+)
+
+func TestMatchRoster(t *testing.T) {
+	accounts := []*account.Account{
+		{ID: "a1", RoleName: "Alice", ServerID: 1},
+		{ID: "a2", RoleName: "Bob Smith", ServerID: 1},
+		{ID: "a3", RoleName: "Carol", ServerID: 2},
+	}
+
+	lines := []string{
+		"  alice ",
+		"BobSmith",
+		"Lv.80 Alice", // duplicate, matched via a field
+		"Carol",       // wrong server
+		"Dave",
+		"",
+	}
+
+	m := MatchRoster(lines, accounts, 1)
+	if got, want := m.AccountIDs(), []string{"a1", "a2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AccountIDs() = %v, want %v", got, want)
+	}
+	if want := []string{"Carol", "Dave"}; !reflect.DeepEqual(m.Unmatched, want) {
+		t.Errorf("Unmatched = %v, want %v", m.Unmatched, want)
+	}
+
+	if m := MatchRoster([]string{"Carol"}, accounts, 0); len(m.Matched) != 1 {
+		t.Error("serverID 0 should match accounts on any server")
+	}
+}
@@ -51,8 +51,9 @@ func (s *Service) GetGroupByName(ctx context.Context, name string) (*Group, erro
 	return grp, nil
 }
 
-// GetGroupWithAccounts loads a group and resolves its account IDs to actual accounts.
-// Invalid account IDs are silently ignored.
+// GetGroupWithAccounts loads a group and resolves its account IDs, or for a
+// smart group its query, to actual accounts.
+// Invalid account IDs and archived accounts are silently ignored.
 func (s *Service) GetGroupWithAccounts(ctx context.Context, groupID string) (*ResolvedGroup, error) {
 	grp, err := s.groupRepo.FindByID(ctx, groupID)
 	if err != nil {
@@ -62,16 +63,9 @@ func (s *Service) GetGroupWithAccounts(ctx context.Context, groupID string) (*Re
 		return nil, ErrGroupNotFound
 	}
 
-	accounts := make([]*account.Account, 0, len(grp.AccountIDs))
-	for _, accID := range grp.AccountIDs {
-		acc, err := s.accountRepo.FindByID(ctx, accID)
-		if err != nil {
-			continue // Skip on error
-		}
-		if acc != nil {
-			accounts = append(accounts, acc)
-		}
-		// Silently skip invalid/missing accounts
+	accounts, err := s.members(ctx, grp)
+	if err != nil {
+		return nil, err
 	}
 
 	// Sort by ranking (lower ranking = higher priority)
@@ -86,6 +80,7 @@ func (s *Service) GetGroupWithAccounts(ctx context.Context, groupID string) (*Re
 }
 
 // GetGroupWithAccountsByName loads a group by name and resolves its accounts.
+// Archived accounts are skipped.
 func (s *Service) GetGroupWithAccountsByName(ctx context.Context, name string) (*ResolvedGroup, error) {
 	grp, err := s.groupRepo.FindByName(ctx, name)
 	if err != nil {
@@ -95,15 +90,9 @@ func (s *Service) GetGroupWithAccountsByName(ctx context.Context, name string) (
 		return nil, ErrGroupNotFound
 	}
 
-	accounts := make([]*account.Account, 0, len(grp.AccountIDs))
-	for _, accID := range grp.AccountIDs {
-		acc, err := s.accountRepo.FindByID(ctx, accID)
-		if err != nil {
-			continue
-		}
-		if acc != nil {
-			accounts = append(accounts, acc)
-		}
+	accounts, err := s.members(ctx, grp)
+	if err != nil {
+		return nil, err
 	}
 
 	// Sort by ranking first, then by ID for stable ordering
@@ -120,6 +109,35 @@ func (s *Service) GetGroupWithAccountsByName(ctx context.Context, name string) (
 	}, nil
 }
 
+// members returns the group's non-archived accounts: those matching its
+// query for smart groups, otherwise those listed in AccountIDs.
+func (s *Service) members(ctx context.Context, grp *Group) ([]*account.Account, error) {
+	if grp.IsSmart() {
+		query, err := ParseQuery(grp.Query)
+		if err != nil {
+			return nil, err
+		}
+		all, err := s.accountRepo.FindAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return query.Filter(account.ActiveAccounts(all)), nil
+	}
+
+	accounts := make([]*account.Account, 0, len(grp.AccountIDs))
+	for _, accID := range grp.AccountIDs {
+		acc, err := s.accountRepo.FindByID(ctx, accID)
+		if err != nil {
+			continue // Skip on error
+		}
+		if acc != nil && !acc.Archived {
+			accounts = append(accounts, acc)
+		}
+		// Silently skip invalid/missing/archived accounts
+	}
+	return accounts, nil
+}
+
 // ListAllGroups retrieves all groups sorted by ranking then ID.
 func (s *Service) ListAllGroups(ctx context.Context) ([]*Group, error) {
 	groups, err := s.groupRepo.FindAll(ctx)
@@ -138,16 +156,30 @@ func (s *Service) ListAllGroups(ctx context.Context) ([]*Group, error) {
 	return groups, nil
 }
 
-// CreateGroup creates a new group.
+// CreateGroup creates a new group. A smart group's query must parse.
 func (s *Service) CreateGroup(ctx context.Context, grp *Group) error {
+	if err := validateQuery(grp); err != nil {
+		return err
+	}
 	return s.groupRepo.Insert(ctx, grp)
 }
 
-// UpdateGroup updates an existing group.
+// UpdateGroup updates an existing group. A smart group's query must parse.
 func (s *Service) UpdateGroup(ctx context.Context, grp *Group) error {
+	if err := validateQuery(grp); err != nil {
+		return err
+	}
 	return s.groupRepo.Update(ctx, grp)
 }
 
+func validateQuery(grp *Group) error {
+	if !grp.IsSmart() {
+		return nil
+	}
+	_, err := ParseQuery(grp.Query)
+	return err
+}
+
 // DeleteGroup removes a group.
 func (s *Service) DeleteGroup(ctx context.Context, id string) error {
 	return s.groupRepo.Delete(ctx, id)
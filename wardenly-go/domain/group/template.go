@@ -0,0 +1,89 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Common errors for template operations.
+var (
+	ErrTemplateNotFound = errors.New("template not found")
+	ErrInvalidTemplate  = errors.New("invalid template")
+)
+
+// DefaultStartInterval is the delay between account starts of a group run
+// when its settings don't set one.
+const DefaultStartInterval = 3 * time.Second
+
+// RunSettings control how a group is run.
+type RunSettings struct {
+	// ScriptName is started on each session once it is ready (optional)
+	ScriptName string
+
+	// StartInterval staggers account starts (zero means DefaultStartInterval)
+	StartInterval time.Duration
+
+	// StopWhenDone stops each session once its script finishes
+	StopWhenDone bool
+}
+
+// Interval returns the delay between account starts.
+func (r RunSettings) Interval() time.Duration {
+	if r.StartInterval <= 0 {
+		return DefaultStartInterval
+	}
+	return r.StartInterval
+}
+
+// Template is a reusable preset for new groups. Groups created from it copy
+// its settings and remember it, so later changes can be propagated.
+type Template struct {
+	// ID is the unique identifier (MongoDB ObjectID)
+	ID string
+
+	// Name is the display name of the template
+	Name string
+
+	// Description is an optional description
+	Description string
+
+	// Settings are copied to groups created from the template
+	Settings RunSettings
+
+	// ScheduleSpec schedules the default script on new groups (optional);
+	// see schedule.ParseSpec for the format
+	ScheduleSpec string
+}
+
+// Validate checks that the template is complete.
+func (t *Template) Validate() error {
+	switch {
+	case t.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidTemplate)
+	case t.Settings.StartInterval < 0:
+		return fmt.Errorf("%w: start interval must not be negative", ErrInvalidTemplate)
+	case t.ScheduleSpec != "" && t.Settings.ScriptName == "":
+		return fmt.Errorf("%w: a schedule needs a default script", ErrInvalidTemplate)
+	}
+	return nil
+}
+
+// NewGroup returns a group named name with the template's settings.
+func (t *Template) NewGroup(name string) *Group {
+	grp := &Group{Name: name}
+	t.Apply(grp)
+	return grp
+}
+
+// Apply copies the template's settings to grp and links it to the template.
+func (t *Template) Apply(grp *Group) {
+	grp.Settings = t.Settings
+	grp.TemplateID = t.ID
+}
+
+// Clone creates a copy of the template.
+func (t *Template) Clone() *Template {
+	clone := *t
+	return &clone
+}
@@ -0,0 +1,135 @@
+package group
+
+import (
+	"context"
+	"sort"
+)
+
+// TemplateService provides business logic for group templates.
+type TemplateService struct {
+	templateRepo TemplateRepository
+	groupRepo    Repository
+}
+
+// NewTemplateService creates a new template service.
+func NewTemplateService(templateRepo TemplateRepository, groupRepo Repository) *TemplateService {
+	return &TemplateService{
+		templateRepo: templateRepo,
+		groupRepo:    groupRepo,
+	}
+}
+
+// GetTemplate retrieves a template by ID.
+func (s *TemplateService) GetTemplate(ctx context.Context, id string) (*Template, error) {
+	tmpl, err := s.templateRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil {
+		return nil, ErrTemplateNotFound
+	}
+	return tmpl, nil
+}
+
+// ListTemplates retrieves all templates sorted by name then ID.
+func (s *TemplateService) ListTemplates(ctx context.Context) ([]*Template, error) {
+	templates, err := s.templateRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Name != templates[j].Name {
+			return templates[i].Name < templates[j].Name
+		}
+		return templates[i].ID < templates[j].ID
+	})
+
+	return templates, nil
+}
+
+// CreateTemplate validates and creates a new template.
+func (s *TemplateService) CreateTemplate(ctx context.Context, tmpl *Template) error {
+	if err := tmpl.Validate(); err != nil {
+		return err
+	}
+	return s.templateRepo.Insert(ctx, tmpl)
+}
+
+// UpdateTemplate validates and updates an existing template. With propagate
+// the new settings are also copied to every group created from it.
+// Returns the number of groups updated.
+func (s *TemplateService) UpdateTemplate(ctx context.Context, tmpl *Template, propagate bool) (int, error) {
+	if err := tmpl.Validate(); err != nil {
+		return 0, err
+	}
+	if err := s.templateRepo.Update(ctx, tmpl); err != nil {
+		return 0, err
+	}
+	if !propagate {
+		return 0, nil
+	}
+
+	groups, err := s.DerivedGroups(ctx, tmpl.ID)
+	if err != nil {
+		return 0, err
+	}
+	for i, grp := range groups {
+		tmpl.Apply(grp)
+		if err := s.groupRepo.Update(ctx, grp); err != nil {
+			return i, err
+		}
+	}
+	return len(groups), nil
+}
+
+// DeleteTemplate removes a template. Groups created from it keep their
+// settings but are no longer linked to it.
+func (s *TemplateService) DeleteTemplate(ctx context.Context, id string) error {
+	groups, err := s.DerivedGroups(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.templateRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	for _, grp := range groups {
+		grp.TemplateID = ""
+		if err := s.groupRepo.Update(ctx, grp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateGroup creates an empty group named name from a template.
+func (s *TemplateService) CreateGroup(ctx context.Context, templateID, name string) (*Group, error) {
+	tmpl, err := s.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	grp := tmpl.NewGroup(name)
+	if err := s.groupRepo.Insert(ctx, grp); err != nil {
+		return nil, err
+	}
+	return grp, nil
+}
+
+// DerivedGroups returns the groups created from a template.
+func (s *TemplateService) DerivedGroups(ctx context.Context, templateID string) ([]*Group, error) {
+	if templateID == "" {
+		return nil, nil
+	}
+	groups, err := s.groupRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var derived []*Group
+	for _, grp := range groups {
+		if grp.TemplateID == templateID {
+			derived = append(derived, grp)
+		}
+	}
+	return derived, nil
+}
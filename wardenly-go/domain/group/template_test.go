@@ -0,0 +1,189 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// memGroupRepo is an in-memory Repository.
+type memGroupRepo struct {
+	groups map[string]*Group
+	nextID int
+}
+
+func (r *memGroupRepo) FindByID(ctx context.Context, id string) (*Group, error) {
+	if grp, ok := r.groups[id]; ok {
+		return grp.Clone(), nil
+	}
+	return nil, nil
+}
+
+func (r *memGroupRepo) FindByName(ctx context.Context, name string) (*Group, error) {
+	for _, grp := range r.groups {
+		if grp.Name == name {
+			return grp.Clone(), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memGroupRepo) FindAll(ctx context.Context) ([]*Group, error) {
+	var out []*Group
+	for _, grp := range r.groups {
+		out = append(out, grp.Clone())
+	}
+	return out, nil
+}
+
+func (r *memGroupRepo) FindByAccountID(ctx context.Context, accountID string) ([]*Group, error) {
+	var out []*Group
+	for _, grp := range r.groups {
+		if grp.ContainsAccount(accountID) {
+			out = append(out, grp.Clone())
+		}
+	}
+	return out, nil
+}
+
+func (r *memGroupRepo) Insert(ctx context.Context, grp *Group) error {
+	r.nextID++
+	grp.ID = "g" + strconv.Itoa(r.nextID)
+	r.groups[grp.ID] = grp.Clone()
+	return nil
+}
+
+func (r *memGroupRepo) Update(ctx context.Context, grp *Group) error {
+	if _, ok := r.groups[grp.ID]; !ok {
+		return ErrGroupNotFound
+	}
+	r.groups[grp.ID] = grp.Clone()
+	return nil
+}
+
+func (r *memGroupRepo) Delete(ctx context.Context, id string) error {
+	delete(r.groups, id)
+	return nil
+}
+
+// memTemplateRepo is an in-memory TemplateRepository.
+type memTemplateRepo struct {
+	templates map[string]*Template
+}
+
+func (r *memTemplateRepo) FindByID(ctx context.Context, id string) (*Template, error) {
+	if tmpl, ok := r.templates[id]; ok {
+		return tmpl.Clone(), nil
+	}
+	return nil, nil
+}
+
+func (r *memTemplateRepo) FindAll(ctx context.Context) ([]*Template, error) {
+	var out []*Template
+	for _, tmpl := range r.templates {
+		out = append(out, tmpl.Clone())
+	}
+	return out, nil
+}
+
+func (r *memTemplateRepo) Insert(ctx context.Context, tmpl *Template) error {
+	tmpl.ID = "t" + strconv.Itoa(len(r.templates)+1)
+	r.templates[tmpl.ID] = tmpl.Clone()
+	return nil
+}
+
+func (r *memTemplateRepo) Update(ctx context.Context, tmpl *Template) error {
+	if _, ok := r.templates[tmpl.ID]; !ok {
+		return ErrTemplateNotFound
+	}
+	r.templates[tmpl.ID] = tmpl.Clone()
+	return nil
+}
+
+func (r *memTemplateRepo) Delete(ctx context.Context, id string) error {
+	delete(r.templates, id)
+	return nil
+}
+
+func TestTemplate_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl Template
+	}{
+		{"missing name", Template{}},
+		{"negative interval", Template{Name: "t", Settings: RunSettings{StartInterval: -time.Second}}},
+		{"schedule without script", Template{Name: "t", ScheduleSpec: "daily 04:30"}},
+	}
+	for _, tt := range tests {
+		if err := tt.tmpl.Validate(); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("%s: Validate() = %v, want ErrInvalidTemplate", tt.name, err)
+		}
+	}
+
+	valid := Template{Name: "t", Settings: RunSettings{ScriptName: "daily"}, ScheduleSpec: "daily 04:30"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestRunSettings_Interval(t *testing.T) {
+	if got := (RunSettings{}).Interval(); got != DefaultStartInterval {
+		t.Errorf("Interval() = %v, want default %v", got, DefaultStartInterval)
+	}
+	if got := (RunSettings{StartInterval: 10 * time.Second}).Interval(); got != 10*time.Second {
+		t.Errorf("Interval() = %v, want 10s", got)
+	}
+}
+
+func TestTemplateService_Propagate(t *testing.T) {
+	ctx := context.Background()
+	groups := &memGroupRepo{groups: map[string]*Group{}}
+	svc := NewTemplateService(&memTemplateRepo{templates: map[string]*Template{}}, groups)
+
+	tmpl := &Template{Name: "daily", Settings: RunSettings{ScriptName: "daily", StartInterval: 5 * time.Second}}
+	if err := svc.CreateTemplate(ctx, tmpl); err != nil {
+		t.Fatalf("CreateTemplate: %v", err)
+	}
+	derived, err := svc.CreateGroup(ctx, tmpl.ID, "server 1")
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	if derived.TemplateID != tmpl.ID || derived.Settings != tmpl.Settings {
+		t.Errorf("derived group = %+v, want template settings", derived)
+	}
+	other := &Group{Name: "manual"}
+	groups.Insert(ctx, other)
+
+	// Without propagation derived groups keep their copy
+	tmpl.Settings.StopWhenDone = true
+	if n, err := svc.UpdateTemplate(ctx, tmpl, false); err != nil || n != 0 {
+		t.Fatalf("UpdateTemplate(false) = %d, %v", n, err)
+	}
+	if got, _ := groups.FindByID(ctx, derived.ID); got.Settings.StopWhenDone {
+		t.Error("settings propagated without propagate")
+	}
+
+	if n, err := svc.UpdateTemplate(ctx, tmpl, true); err != nil || n != 1 {
+		t.Fatalf("UpdateTemplate(true) = %d, %v; want 1 group", n, err)
+	}
+	if got, _ := groups.FindByID(ctx, derived.ID); !got.Settings.StopWhenDone {
+		t.Error("settings not propagated to derived group")
+	}
+	if got, _ := groups.FindByID(ctx, other.ID); got.Settings != (RunSettings{}) {
+		t.Errorf("unrelated group settings = %+v, want untouched", got.Settings)
+	}
+
+	// Deleting the template unlinks derived groups but keeps their settings
+	if err := svc.DeleteTemplate(ctx, tmpl.ID); err != nil {
+		t.Fatalf("DeleteTemplate: %v", err)
+	}
+	got, _ := groups.FindByID(ctx, derived.ID)
+	if got.TemplateID != "" || got.Settings.ScriptName != "daily" {
+		t.Errorf("group after template delete = %+v", got)
+	}
+	if _, err := svc.CreateGroup(ctx, tmpl.ID, "server 2"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("CreateGroup from deleted template = %v, want ErrTemplateNotFound", err)
+	}
+}
@@ -0,0 +1,215 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"wardenly-go/domain/account"
+)
+
+// groupExport is the document written by ExportAll.
+type groupExport struct {
+	Version int           `json:"version" yaml:"version"`
+	Groups  []groupRecord `json:"groups" yaml:"groups"`
+}
+
+// groupRecord is an exported group. Members are referred to by server and
+// user name, since account IDs differ between stores; templates are not
+// exported, so the template link is dropped.
+type groupRecord struct {
+	Name        string         `json:"name" yaml:"name"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Members     []memberRecord `json:"members,omitempty" yaml:"members,omitempty"`
+	Query       string         `json:"query,omitempty" yaml:"query,omitempty"`
+	Ranking     int            `json:"ranking,omitempty" yaml:"ranking,omitempty"`
+	Settings    settingsRecord `json:"settings" yaml:"settings"`
+}
+
+// memberRecord identifies a member by server and user name. The role name
+// is kept for reading and to match exports written before user names were
+// included.
+type memberRecord struct {
+	ServerID int    `json:"serverId" yaml:"serverId"`
+	UserName string `json:"userName,omitempty" yaml:"userName,omitempty"`
+	RoleName string `json:"roleName" yaml:"roleName"`
+}
+
+// String describes the member in import reports.
+func (m memberRecord) String() string {
+	if m.UserName == "" {
+		return fmt.Sprintf("%d - %s", m.ServerID, m.RoleName)
+	}
+	return fmt.Sprintf("%d - %s (user %s)", m.ServerID, m.RoleName, m.UserName)
+}
+
+// memberIndex resolves exported members to stored account IDs.
+type memberIndex struct {
+	byLogin map[string][]string // LoginKey -> IDs
+	byRole  map[string]string   // IdentityKey -> ID
+}
+
+func newMemberIndex(accounts []*account.Account) *memberIndex {
+	idx := &memberIndex{
+		byLogin: make(map[string][]string, len(accounts)),
+		byRole:  make(map[string]string, len(accounts)),
+	}
+	for _, acc := range accounts {
+		if acc.UserName != "" {
+			key := account.LoginKey(acc.ServerID, acc.UserName)
+			idx.byLogin[key] = append(idx.byLogin[key], acc.ID)
+		}
+		idx.byRole[account.IdentityKey(acc.ServerID, acc.RoleName)] = acc.ID
+	}
+	return idx
+}
+
+// resolve returns the account of a member: the one with its server and user
+// name, or, for members without a user name or whose user name is shared
+// by several accounts, the one with its server and role name.
+func (idx *memberIndex) resolve(m memberRecord) (string, bool) {
+	if m.UserName != "" {
+		ids := idx.byLogin[account.LoginKey(m.ServerID, m.UserName)]
+		switch len(ids) {
+		case 0:
+			return "", false
+		case 1:
+			return ids[0], true
+		}
+		id, ok := idx.byRole[account.IdentityKey(m.ServerID, m.RoleName)]
+		return id, ok && slices.Contains(ids, id)
+	}
+	id, ok := idx.byRole[account.IdentityKey(m.ServerID, m.RoleName)]
+	return id, ok
+}
+
+type settingsRecord struct {
+	ScriptName    string `json:"scriptName,omitempty" yaml:"scriptName,omitempty"`
+	StartInterval string `json:"startInterval,omitempty" yaml:"startInterval,omitempty"` // e.g. "30s"
+	StopWhenDone  bool   `json:"stopWhenDone,omitempty" yaml:"stopWhenDone,omitempty"`
+}
+
+// ExportAll encodes all groups for a backup or another machine. Member IDs
+// that no longer match an account are left out.
+func (s *Service) ExportAll(ctx context.Context, format account.Format) ([]byte, error) {
+	groups, err := s.ListAllGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := s.accountRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*account.Account, len(accounts))
+	for _, acc := range accounts {
+		byID[acc.ID] = acc
+	}
+
+	doc := groupExport{Version: account.ExportVersion, Groups: make([]groupRecord, len(groups))}
+	for i, grp := range groups {
+		rec := groupRecord{
+			Name:        grp.Name,
+			Description: grp.Description,
+			Query:       grp.Query,
+			Ranking:     grp.Ranking,
+			Settings: settingsRecord{
+				ScriptName:   grp.Settings.ScriptName,
+				StopWhenDone: grp.Settings.StopWhenDone,
+			},
+		}
+		if grp.Settings.StartInterval > 0 {
+			rec.Settings.StartInterval = grp.Settings.StartInterval.String()
+		}
+		for _, id := range grp.AccountIDs {
+			if acc := byID[id]; acc != nil {
+				rec.Members = append(rec.Members, memberRecord{ServerID: acc.ServerID, UserName: acc.UserName, RoleName: acc.RoleName})
+			}
+		}
+		doc.Groups[i] = rec
+	}
+	return format.Marshal(doc)
+}
+
+// ImportAll creates the groups of an export, mapping members to the stored
+// accounts with the same server and user name (see memberIndex.resolve), so
+// accounts should be imported first. Groups whose name, ignoring case,
+// matches a stored group or an earlier entry of the file are skipped.
+// Members with no matching account are dropped and reported in Unmatched.
+// It stops at the first failure; groups created before it are kept.
+func (s *Service) ImportAll(ctx context.Context, data []byte, format account.Format) (*account.ImportResult, error) {
+	var doc groupExport
+	if err := format.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse groups: %w", err)
+	}
+	if err := account.CheckExportVersion(doc.Version); err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.accountRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	members := newMemberIndex(accounts)
+	existing, err := s.groupRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(existing)+len(doc.Groups))
+	for _, grp := range existing {
+		seen[groupKey(grp.Name)] = true
+	}
+
+	result := &account.ImportResult{}
+	for i, rec := range doc.Groups {
+		name := strings.TrimSpace(rec.Name)
+		if name == "" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("group %d: name is empty", i+1))
+			continue
+		}
+		if seen[groupKey(name)] {
+			result.Skipped = append(result.Skipped, name)
+			continue
+		}
+
+		grp := &Group{
+			Name:        name,
+			Description: rec.Description,
+			Query:       rec.Query,
+			Ranking:     rec.Ranking,
+			Settings: RunSettings{
+				ScriptName:   rec.Settings.ScriptName,
+				StopWhenDone: rec.Settings.StopWhenDone,
+			},
+		}
+		if rec.Settings.StartInterval != "" {
+			interval, err := time.ParseDuration(rec.Settings.StartInterval)
+			if err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: invalid start interval %q, using the default", name, rec.Settings.StartInterval))
+			} else {
+				grp.Settings.StartInterval = interval
+			}
+		}
+		for _, m := range rec.Members {
+			id, ok := members.resolve(m)
+			if !ok {
+				result.Unmatched = append(result.Unmatched, name+": "+m.String())
+				continue
+			}
+			grp.AddAccount(id)
+		}
+
+		if err := s.CreateGroup(ctx, grp); err != nil {
+			return result, fmt.Errorf("%s: %w", name, err)
+		}
+		seen[groupKey(name)] = true
+		result.Added++
+	}
+	return result, nil
+}
+
+// groupKey identifies a group by name, ignoring case and surrounding spaces.
+func groupKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
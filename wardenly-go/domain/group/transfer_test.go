@@ -0,0 +1,198 @@
+package group
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"wardenly-go/domain/account"
+)
+
+// memAccountRepo is an in-memory account.Repository.
+type memAccountRepo struct {
+	accounts map[string]*account.Account
+	prefix   string
+}
+
+func (r *memAccountRepo) FindByID(ctx context.Context, id string) (*account.Account, error) {
+	if acc, ok := r.accounts[id]; ok {
+		return acc.Clone(), nil
+	}
+	return nil, nil
+}
+
+func (r *memAccountRepo) FindAll(ctx context.Context) ([]*account.Account, error) {
+	var out []*account.Account
+	for _, acc := range r.accounts {
+		out = append(out, acc.Clone())
+	}
+	return out, nil
+}
+
+func (r *memAccountRepo) Insert(ctx context.Context, acc *account.Account) error {
+	acc.ID = r.prefix + strconv.Itoa(len(r.accounts)+1)
+	r.accounts[acc.ID] = acc.Clone()
+	return nil
+}
+
+func (r *memAccountRepo) Update(ctx context.Context, acc *account.Account) error {
+	r.accounts[acc.ID] = acc.Clone()
+	return nil
+}
+
+func (r *memAccountRepo) UpdateCookies(ctx context.Context, id string, cookies []account.Cookie) error {
+	return nil
+}
+
+func (r *memAccountRepo) UpdateScriptParams(ctx context.Context, id, scriptName string, params map[string]string) error {
+	return nil
+}
+
+func (r *memAccountRepo) Delete(ctx context.Context, id string) error {
+	delete(r.accounts, id)
+	return nil
+}
+
+func TestExportImportAll(t *testing.T) {
+	for _, format := range []account.Format{account.FormatJSON, account.FormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			ctx := context.Background()
+
+			// Source machine
+			srcAccounts := &memAccountRepo{accounts: map[string]*account.Account{}, prefix: "src"}
+			srcGroups := &memGroupRepo{groups: map[string]*Group{}}
+			alice := &account.Account{ServerID: 1, RoleName: "Alice", UserName: "alice01", Password: "secret",
+				Proxy:   &account.Proxy{Host: "proxy", Port: 8080},
+				Cookies: []account.Cookie{{Name: "sid", Value: "abc", Expires: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)}}}
+			bob := &account.Account{ServerID: 2, RoleName: "Bob", UserName: "bob01"}
+			srcAccounts.Insert(ctx, alice)
+			srcAccounts.Insert(ctx, bob)
+			srcGroups.Insert(ctx, &Group{Name: "daily", AccountIDs: []string{alice.ID, bob.ID, "gone"},
+				Settings: RunSettings{ScriptName: "daily", StartInterval: 30 * time.Second}})
+
+			accountData, err := account.NewService(srcAccounts).ExportAll(ctx, format)
+			if err != nil {
+				t.Fatalf("account ExportAll() error = %v", err)
+			}
+			groupData, err := NewService(srcGroups, srcAccounts).ExportAll(ctx, format)
+			if err != nil {
+				t.Fatalf("group ExportAll() error = %v", err)
+			}
+
+			// Target machine already has Bob (different case)
+			dstAccounts := &memAccountRepo{accounts: map[string]*account.Account{
+				"dst1": {ID: "dst1", ServerID: 2, RoleName: "bob", UserName: "BOB01"},
+			}, prefix: "dst"}
+			dstGroups := &memGroupRepo{groups: map[string]*Group{}}
+
+			result, err := account.NewService(dstAccounts).ImportAll(ctx, accountData, format)
+			if err != nil {
+				t.Fatalf("account ImportAll() error = %v", err)
+			}
+			if result.Added != 1 || len(result.Skipped) != 1 || result.Skipped[0] != "2 - Bob" {
+				t.Fatalf("account ImportAll() = %+v, want Alice added and Bob skipped", result)
+			}
+			var imported *account.Account
+			for _, acc := range dstAccounts.accounts {
+				if acc.RoleName == "Alice" {
+					imported = acc
+				}
+			}
+			if imported == nil || imported.ID == alice.ID || imported.Password != "secret" ||
+				!imported.Proxy.Enabled() || len(imported.Cookies) != 1 || !imported.Cookies[0].Expires.Equal(alice.Cookies[0].Expires) {
+				t.Fatalf("imported account = %+v", imported)
+			}
+
+			groups := NewService(dstGroups, dstAccounts)
+			result, err = groups.ImportAll(ctx, groupData, format)
+			if err != nil || result.Added != 1 || len(result.Warnings) != 0 || len(result.Unmatched) != 0 {
+				t.Fatalf("group ImportAll() = %+v, %v", result, err)
+			}
+			grp, err := groups.GetGroupByName(ctx, "daily")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(grp.AccountIDs, ","); got != imported.ID+",dst1" {
+				t.Errorf("AccountIDs = %s, want remapped to %s,dst1", got, imported.ID)
+			}
+			if grp.Settings.StartInterval != 30*time.Second || grp.Settings.ScriptName != "daily" {
+				t.Errorf("Settings = %+v", grp.Settings)
+			}
+
+			// Importing again adds nothing
+			if result, _ := groups.ImportAll(ctx, groupData, format); result.Added != 0 || len(result.Skipped) != 1 {
+				t.Errorf("second group ImportAll() = %+v, want skipped", result)
+			}
+		})
+	}
+}
+
+func TestImportAll_MissingMembers(t *testing.T) {
+	ctx := context.Background()
+	data := []byte(`{"version": 1, "groups": [{"name": "arena", "members": [{"serverId": 9, "userName": "nobody", "roleName": "Nobody"}]}, {"name": ""}]}`)
+	svc := NewService(&memGroupRepo{groups: map[string]*Group{}}, &memAccountRepo{accounts: map[string]*account.Account{}})
+
+	result, err := svc.ImportAll(ctx, data, account.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 1 || len(result.Warnings) != 1 || len(result.Unmatched) != 1 || result.Unmatched[0] != "arena: 9 - Nobody (user nobody)" {
+		t.Errorf("ImportAll() = %+v, want one group, one warning and one unmatched member", result)
+	}
+
+	if _, err := svc.ImportAll(ctx, []byte(`{"version": 2}`), account.FormatJSON); err == nil {
+		t.Error("ImportAll() should reject a newer export version")
+	}
+}
+
+func TestImportAll_MemberResolution(t *testing.T) {
+	ctx := context.Background()
+	accounts := &memAccountRepo{accounts: map[string]*account.Account{
+		"renamed": {ID: "renamed", ServerID: 1, UserName: "carol01", RoleName: "Carol the Bold"},
+		"twin-a":  {ID: "twin-a", ServerID: 1, UserName: "shared", RoleName: "Twin A"},
+		"twin-b":  {ID: "twin-b", ServerID: 1, UserName: "shared", RoleName: "Twin B"},
+		"legacy":  {ID: "legacy", ServerID: 2, UserName: "dave01", RoleName: "Dave"},
+		"other":   {ID: "other", ServerID: 3, UserName: "erin01", RoleName: "Erin"},
+	}}
+	data := []byte(`{"version": 1, "groups": [{"name": "mixed", "members": [
+		{"serverId": 1, "userName": "Carol01", "roleName": "Carol"},
+		{"serverId": 1, "userName": "shared", "roleName": "Twin B"},
+		{"serverId": 2, "roleName": "dave"},
+		{"serverId": 3, "userName": "frank01", "roleName": "Erin"}
+	]}]}`)
+	svc := NewService(&memGroupRepo{groups: map[string]*Group{}}, accounts)
+
+	result, err := svc.ImportAll(ctx, data, account.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grp, err := svc.GetGroupByName(ctx, "mixed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Renamed roles match by user name, shared user names fall back to the
+	// role name, members of older exports match by role name, and a role
+	// name alone doesn't match a member whose user name differs
+	if got := strings.Join(grp.AccountIDs, ","); got != "renamed,twin-b,legacy" {
+		t.Errorf("AccountIDs = %s, want renamed,twin-b,legacy", got)
+	}
+	if len(result.Unmatched) != 1 || !strings.Contains(result.Unmatched[0], "frank01") {
+		t.Errorf("Unmatched = %v, want frank01", result.Unmatched)
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	tests := map[string]account.Format{
+		"backup.json": account.FormatJSON,
+		"backup.YAML": account.FormatYAML,
+		"backup.yml":  account.FormatYAML,
+		"backup":      account.FormatJSON,
+	}
+	for path, want := range tests {
+		if got := account.FormatForPath(path); got != want {
+			t.Errorf("FormatForPath(%q) = %s, want %s", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package scene
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+)
+
+// LoadDir loads the YAML and JSON scene files in dir on top of the embedded
+// ones and brings the registry in line with the directory: a scene replaces
+// an embedded scene of the same name, and removing it from its file restores
+// the embedded version or unregisters the scene. A file that fails to load keeps
+// its previously loaded scenes; all such errors are returned joined.
+func (l *Loader) LoadDir(dir string) error {
+	_, err := l.loadDir(dir)
+	return err
+}
+
+// Edit is a scene whose definition changed when the scenes directory was
+// reloaded. Original is the definition it replaced, nil for a new scene.
+type Edit struct {
+	Original *Scene
+	Edited   *Scene
+}
+
+// loadDir is LoadDir, also returning the scenes it added or changed.
+func (l *Loader) loadDir(dir string) ([]Edit, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenes directory: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	images := os.DirFS(dir) // Template images sit next to the scene files
+	var paths []string      // In file name order, so the last duplicate wins
+	loaded := make(map[string][]*Scene)
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !isSceneFile(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+
+		data, err := os.ReadFile(path)
+		var scenes []*Scene
+		if err == nil {
+			scenes, err = Parse(data, images)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("scene file %s: %w", path, err))
+			prev, ok := l.dirFiles[path]
+			if !ok {
+				continue
+			}
+			scenes = prev
+		}
+		loaded[path] = scenes
+		paths = append(paths, path)
+	}
+
+	// Scenes whose file was removed or no longer defines them
+	for path, prev := range l.dirFiles {
+		for _, scene := range prev {
+			if !slices.ContainsFunc(loaded[path], func(s *Scene) bool { return s.Name == scene.Name }) {
+				l.restoreLocked(scene.Name)
+			}
+		}
+	}
+
+	var edits []Edit
+	for _, path := range paths {
+		for _, scene := range loaded[path] {
+			if original := l.registry.Get(scene.Name); !reflect.DeepEqual(original, scene) {
+				edits = append(edits, Edit{Original: original, Edited: scene})
+			}
+			l.registry.Register(scene)
+		}
+	}
+	l.dirFiles = loaded
+
+	return edits, errors.Join(errs...)
+}
+
+// restoreLocked registers the embedded version of a scene, or unregisters it
+// when there is none. Caller must hold mu.
+func (l *Loader) restoreLocked(name string) {
+	if scene, ok := l.embedded[name]; ok {
+		l.registry.Register(scene)
+		return
+	}
+	l.registry.Unregister(name)
+}
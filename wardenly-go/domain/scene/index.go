@@ -0,0 +1,67 @@
+package scene
+
+import (
+	"image"
+	"image/color"
+)
+
+// probe is the point of a scene read first. A point scene matches on the
+// average diff of its points, so if one point is off by more than the
+// number of points times the threshold, the scene can't match and its
+// other points needn't be read.
+type probe struct {
+	at     image.Point
+	color  color.RGBA
+	points int
+}
+
+// index holds the probe of each point scene in a registry, except scenes
+// with Bounds, whose points move with them. Probes sit where the most
+// scenes have a point, so a frame's pixel there is read once for all of
+// them.
+type index struct {
+	probes map[*Scene]probe
+}
+
+func buildIndex(scenes map[string]*Scene) *index {
+	shared := make(map[image.Point]int)
+	for _, scene := range scenes {
+		if scene.HasRegion() {
+			continue
+		}
+		for _, p := range scene.Points {
+			shared[image.Pt(p.X, p.Y)]++
+		}
+	}
+
+	ix := &index{probes: make(map[*Scene]probe, len(scenes))}
+	for _, scene := range scenes {
+		if scene.Template != nil || len(scene.Points) == 0 || scene.HasRegion() {
+			continue
+		}
+		best := scene.Points[0]
+		for _, p := range scene.Points[1:] {
+			if shared[image.Pt(p.X, p.Y)] > shared[image.Pt(best.X, best.Y)] {
+				best = p
+			}
+		}
+		ix.probes[scene] = probe{at: image.Pt(best.X, best.Y), color: best.Color, points: len(scene.Points)}
+	}
+	return ix
+}
+
+// minAvgDiff returns the least average diff the scene can have on img,
+// from its probe alone. pixels caches the frame's pixels read so far.
+// Scenes without a probe return 0.
+func (ix *index) minAvgDiff(scene *Scene, img image.Image, pixels map[image.Point]color.Color) float64 {
+	p, ok := ix.probes[scene]
+	if !ok {
+		return 0
+	}
+	c, ok := pixels[p.at]
+	if !ok {
+		c = img.At(p.at.X, p.at.Y)
+		pixels[p.at] = c
+	}
+	return colorDiff(c, p.color) / float64(p.points)
+}
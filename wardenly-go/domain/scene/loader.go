@@ -2,9 +2,11 @@ package scene
 
 import (
 	"fmt"
+	"image"
 	"image/color"
 	"io/fs"
 	"path/filepath"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,9 +18,28 @@ type yamlSceneDefinition struct {
 }
 
 type yamlScene struct {
-	Name    string                `yaml:"name"`
-	Points  []yamlPoint           `yaml:"points"`
-	Actions map[string]yamlAction `yaml:"actions"`
+	Name      string                `yaml:"name"`
+	Points    []yamlPoint           `yaml:"points"`
+	Template  *templateSpec         `yaml:"template"`
+	Threshold float64               `yaml:"threshold"`
+	Bounds    *yamlRect             `yaml:"bounds"`
+	Search    *yamlRect             `yaml:"search"`
+	Actions   map[string]yamlAction `yaml:"actions"`
+}
+
+type yamlRect struct {
+	X      int `yaml:"x"`
+	Y      int `yaml:"y"`
+	Width  int `yaml:"width"`
+	Height int `yaml:"height"`
+}
+
+// rect converts r to a rectangle; nil is empty.
+func (r *yamlRect) rect() image.Rectangle {
+	if r == nil {
+		return image.Rectangle{}
+	}
+	return image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height)
 }
 
 type yamlPoint struct {
@@ -40,15 +61,29 @@ type yamlActionPoint struct {
 // Loader handles loading scene definitions from various sources.
 type Loader struct {
 	registry *Registry
+
+	mu       sync.Mutex
+	embedded map[string]*Scene   // Scenes loaded by LoadFromFS, restored when an override goes away
+	dirFiles map[string][]*Scene // Scenes per file loaded by LoadDir
 }
 
 // NewLoader creates a new scene loader that populates the given registry.
 func NewLoader(registry *Registry) *Loader {
-	return &Loader{registry: registry}
+	return &Loader{
+		registry: registry,
+		embedded: make(map[string]*Scene),
+	}
+}
+
+// isSceneFile reports whether name is a scene definition file: YAML, or
+// JSON, which parses as YAML.
+func isSceneFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".json"
 }
 
 // LoadFromFS loads scene definitions from an embedded or real filesystem.
-// It expects YAML files in a "scenes" subdirectory.
+// It expects YAML or JSON files in a "scenes" subdirectory.
 func (l *Loader) LoadFromFS(fsys fs.FS) error {
 	entries, err := fs.ReadDir(fsys, "scenes")
 	if err != nil {
@@ -56,7 +91,7 @@ func (l *Loader) LoadFromFS(fsys fs.FS) error {
 	}
 
 	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+		if entry.IsDir() || !isSceneFile(entry.Name()) {
 			continue
 		}
 
@@ -75,26 +110,76 @@ func (l *Loader) loadFile(fsys fs.FS, path string) error {
 		return fmt.Errorf("failed to read scene file %s: %w", path, err)
 	}
 
-	var def yamlSceneDefinition
-	if err := yaml.Unmarshal(data, &def); err != nil {
+	images, err := fs.Sub(fsys, filepath.ToSlash(filepath.Dir(path)))
+	if err != nil {
+		return fmt.Errorf("failed to read scene file %s: %w", path, err)
+	}
+	scenes, err := Parse(data, images)
+	if err != nil {
 		return fmt.Errorf("failed to parse scene file %s: %w", path, err)
 	}
 
-	for _, ys := range def.Scenes {
-		scene := convertYAMLScene(&ys, def.Category)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, scene := range scenes {
 		l.registry.Register(scene)
+		l.embedded[scene.Name] = scene
 	}
 
 	return nil
 }
 
+// Parse parses a YAML or JSON scene definition file. Template images are read
+// from images, the directory holding the file (may be nil when no scene
+// uses a template).
+func Parse(data []byte, images fs.FS) ([]*Scene, error) {
+	var def yamlSceneDefinition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, err
+	}
+
+	scenes := make([]*Scene, 0, len(def.Scenes))
+	for _, ys := range def.Scenes {
+		if ys.Threshold < 0 {
+			return nil, fmt.Errorf("scene %s: threshold must not be negative, got %v", ys.Name, ys.Threshold)
+		}
+		scene := convertYAMLScene(&ys, def.Category)
+		if ys.Template != nil {
+			if len(ys.Points) > 0 {
+				return nil, fmt.Errorf("scene %s: points and template are mutually exclusive", ys.Name)
+			}
+			tmpl, err := loadTemplate(images, ys.Template)
+			if err != nil {
+				return nil, fmt.Errorf("scene %s: %w", ys.Name, err)
+			}
+			scene.Template = tmpl
+		}
+		for _, r := range []struct {
+			name string
+			rect *yamlRect
+		}{{"bounds", ys.Bounds}, {"search", ys.Search}} {
+			if r.rect != nil && (r.rect.Width <= 0 || r.rect.Height <= 0) {
+				return nil, fmt.Errorf("scene %s: %s must have a positive width and height", ys.Name, r.name)
+			}
+		}
+		if err := scene.validateRegion(); err != nil {
+			return nil, fmt.Errorf("scene %s: %w", ys.Name, err)
+		}
+		scenes = append(scenes, scene)
+	}
+	return scenes, nil
+}
+
 // convertYAMLScene converts a YAML scene to a domain Scene.
 func convertYAMLScene(ys *yamlScene, category string) *Scene {
 	scene := &Scene{
-		Name:     ys.Name,
-		Category: category,
-		Points:   make([]Point, len(ys.Points)),
-		Actions:  make(map[string]Action),
+		Name:      ys.Name,
+		Category:  category,
+		Points:    make([]Point, len(ys.Points)),
+		Actions:   make(map[string]Action),
+		Threshold: ys.Threshold,
+		Bounds:    ys.Bounds.rect(),
+		Search:    ys.Search.rect(),
 	}
 
 	for i, yp := range ys.Points {
@@ -0,0 +1,173 @@
+package scene
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"testing/fstest"
+)
+
+func sceneFile(category string, scenes ...string) string {
+	s := "category: " + category + "\nscenes:\n"
+	for _, scene := range scenes {
+		s += scene
+	}
+	return s
+}
+
+func sceneYAML(name string, x int) string {
+	return "  - name: " + name + "\n    points:\n      - {x: " + strconv.Itoa(x) + ", y: 1, color: {r: 1, g: 2, b: 3, a: 255}}\n"
+}
+
+func TestLoader_LoadDir(t *testing.T) {
+	registry := NewRegistry()
+	loader := NewLoader(registry)
+	embedded := fstest.MapFS{
+		"scenes/city.yaml": {Data: []byte(sceneFile("city", sceneYAML("main_city", 1), sceneYAML("user_agreement", 1)))},
+	}
+	if err := loader.LoadFromFS(embedded); err != nil {
+		t.Fatalf("LoadFromFS() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	write := func(file, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pointX := func(name string) int {
+		t.Helper()
+		s := registry.Get(name)
+		if s == nil || len(s.Points) == 0 {
+			t.Fatalf("scene %s missing", name)
+		}
+		return s.Points[0].X
+	}
+
+	write("city.yaml", sceneFile("city", sceneYAML("main_city", 2), sceneYAML("tower_gate", 2)))
+	write("notes.txt", "ignored")
+	if err := loader.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if got := pointX("main_city"); got != 2 {
+		t.Errorf("main_city x = %d, want the directory version", got)
+	}
+	if got := pointX("user_agreement"); got != 1 {
+		t.Errorf("user_agreement x = %d, want the embedded version untouched", got)
+	}
+	if registry.Count() != 3 {
+		t.Errorf("registry = %v, want main_city, user_agreement and tower_gate", registry.List())
+	}
+
+	// A broken edit keeps the file's last good scenes
+	write("city.yaml", "category: city\nscenes: [\n")
+	if err := loader.LoadDir(dir); err == nil {
+		t.Error("LoadDir() should report the broken file")
+	}
+	if got := pointX("tower_gate"); got != 2 {
+		t.Errorf("tower_gate x = %d, want the last good version", got)
+	}
+
+	// Dropping a scene from the file restores the embedded one or unregisters it
+	write("city.yaml", sceneFile("city", sceneYAML("tower_gate", 3)))
+	if err := loader.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if got := pointX("main_city"); got != 1 {
+		t.Errorf("main_city x = %d, want the embedded version restored", got)
+	}
+	if got := pointX("tower_gate"); got != 3 {
+		t.Errorf("tower_gate x = %d, want the edited version", got)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "city.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if registry.Get("tower_gate") != nil {
+		t.Error("tower_gate should be unregistered after its file is removed")
+	}
+}
+
+func TestLoader_LoadDirEdits(t *testing.T) {
+	registry := NewRegistry()
+	loader := NewLoader(registry)
+	dir := t.TempDir()
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "city.yaml"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(sceneFile("city", sceneYAML("main_city", 1), sceneYAML("tower_gate", 1)))
+	if _, err := loader.loadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	original := registry.Get("tower_gate")
+
+	write(sceneFile("city", sceneYAML("main_city", 1), sceneYAML("tower_gate", 2), sceneYAML("arena", 1)))
+	edits, err := loader.loadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edits) != 2 {
+		t.Fatalf("edits = %+v, want tower_gate and arena", edits)
+	}
+	if edits[0].Original != original || edits[0].Edited.Points[0].X != 2 {
+		t.Errorf("tower_gate edit = %+v", edits[0])
+	}
+	if edits[1].Original != nil || edits[1].Edited.Name != "arena" {
+		t.Errorf("arena edit = %+v, want a new scene", edits[1])
+	}
+}
+
+func TestParse_Threshold(t *testing.T) {
+	data := sceneFile("city", sceneYAML("main_city", 1)+"    threshold: 6.5\n", sceneYAML("tower_gate", 1))
+	scenes, err := Parse([]byte(data), nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(scenes) != 2 || scenes[0].Threshold != 6.5 || scenes[1].Threshold != 0 {
+		t.Errorf("thresholds = %v and %v, want 6.5 and unset", scenes[0].Threshold, scenes[1].Threshold)
+	}
+
+	json := `{"category": "city", "scenes": [{"name": "gate", "threshold": 2.5,
+		"points": [{"x": 1, "y": 2, "color": {"r": 1, "g": 2, "b": 3, "a": 255}}]}]}`
+	scenes, err = Parse([]byte(json), nil)
+	if err != nil || len(scenes) != 1 || scenes[0].Threshold != 2.5 || scenes[0].Points[0].Y != 2 {
+		t.Errorf("Parse(JSON) = %+v, %v", scenes, err)
+	}
+
+	if _, err := Parse([]byte(sceneFile("city", sceneYAML("gate", 1)+"    threshold: -1\n")), nil); err == nil {
+		t.Error("Parse() accepted a negative threshold")
+	}
+}
+
+func TestParse_Bounds(t *testing.T) {
+	region := "    bounds: {x: 300, y: 200, width: 40, height: 20}\n    search: {x: 0, y: 0, width: 960, height: 540}\n"
+	scenes, err := Parse([]byte(sceneFile("popup", sceneYAML("reward", 5)+region)), nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	s := scenes[0]
+	if s.Bounds != image.Rect(300, 200, 340, 220) || s.Search != image.Rect(0, 0, 960, 540) || !s.HasRegion() {
+		t.Errorf("bounds = %v, search = %v", s.Bounds, s.Search)
+	}
+
+	for name, yaml := range map[string]string{
+		"point outside":    sceneYAML("reward", 50) + "    bounds: {x: 300, y: 200, width: 40, height: 20}\n",
+		"empty bounds":     sceneYAML("reward", 5) + "    bounds: {x: 300, y: 200, width: 0, height: 20}\n",
+		"search too small": sceneYAML("reward", 5) + "    bounds: {x: 0, y: 0, width: 40, height: 20}\n    search: {x: 0, y: 0, width: 30, height: 30}\n",
+		"search only":      sceneYAML("reward", 5) + "    search: {x: 0, y: 0, width: 30, height: 30}\n",
+	} {
+		if _, err := Parse([]byte(sceneFile("popup", yaml)), nil); err == nil {
+			t.Errorf("%s: Parse() accepted the scene", name)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package scene
+
+import (
+	"math"
+	"sync"
+)
+
+// NearMissConfig configures tracking of scenes that score just above their
+// match threshold, which usually means the game art changed slightly.
+type NearMissConfig struct {
+	// Margin is how far above the threshold an average diff counts as a
+	// near miss; zero disables tracking.
+	Margin float64
+	// MinCount and MinSessions are how many near misses, from how many
+	// different sessions, make a scene worth tuning.
+	MinCount    int
+	MinSessions int
+	// MaxRelax is how far a scene's threshold may be raised automatically;
+	// zero only suggests new thresholds.
+	MaxRelax float64
+}
+
+// Enabled returns true if near misses are tracked.
+func (c NearMissConfig) Enabled() bool {
+	return c.Margin > 0
+}
+
+// Suggestion proposes a new threshold for a scene that keeps nearly matching.
+type Suggestion struct {
+	Scene string
+	// Threshold is the threshold the near misses were measured against
+	Threshold float64
+	// Suggested is the lowest threshold, rounded up to 0.1, that would have
+	// matched all of them
+	Suggested float64
+	Count     int
+	Sessions  int
+	// Applied is true if the scene now matches with the suggested threshold
+	Applied bool
+}
+
+// NearMisses records near misses of point scenes across sessions. Once a
+// scene has nearly matched often enough it suggests a higher threshold,
+// and raises the scene's threshold to it if that stays within MaxRelax.
+// It is safe for concurrent use by the matchers of all sessions.
+type NearMisses struct {
+	config    NearMissConfig
+	onSuggest func(Suggestion)
+
+	mu     sync.Mutex
+	scenes map[string]*nearMissStats
+}
+
+type nearMissStats struct {
+	count     int
+	sessions  map[string]struct{}
+	worst     float64
+	relaxed   float64 // Raised threshold, 0 if not relaxed
+	suggested float64 // Last suggested threshold
+}
+
+// NewNearMisses creates a near miss tracker. onSuggest, if set, is called
+// with each new suggestion; it must not block.
+func NewNearMisses(config NearMissConfig, onSuggest func(Suggestion)) *NearMisses {
+	return &NearMisses{
+		config:    config,
+		onSuggest: onSuggest,
+		scenes:    make(map[string]*nearMissStats),
+	}
+}
+
+// Margin returns how far above its threshold a scene counts as a near
+// miss, or 0 if near misses aren't tracked.
+func (n *NearMisses) Margin() float64 {
+	return n.config.Margin
+}
+
+// Threshold returns the threshold to match a scene with: base, or the
+// relaxed threshold if it was raised.
+func (n *NearMisses) Threshold(scene string, base float64) float64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if s, ok := n.scenes[scene]; ok && s.relaxed > base {
+		return s.relaxed
+	}
+	return base
+}
+
+// Record notes a failed match of a scene with base threshold by source,
+// e.g. a session ID. Diffs outside the near miss margin are ignored.
+func (n *NearMisses) Record(scene, source string, avgDiff, base float64) {
+	if !n.config.Enabled() {
+		return
+	}
+
+	n.mu.Lock()
+	s, ok := n.scenes[scene]
+	if !ok {
+		s = &nearMissStats{sessions: make(map[string]struct{})}
+		n.scenes[scene] = s
+	}
+	threshold := max(base, s.relaxed)
+	if avgDiff <= threshold || avgDiff > threshold+n.config.Margin {
+		n.mu.Unlock()
+		return
+	}
+
+	s.count++
+	s.sessions[source] = struct{}{}
+	s.worst = max(s.worst, avgDiff)
+	if s.count < n.config.MinCount || len(s.sessions) < n.config.MinSessions {
+		n.mu.Unlock()
+		return
+	}
+
+	suggestion := Suggestion{
+		Scene:     scene,
+		Threshold: threshold,
+		Suggested: math.Ceil(s.worst*10-1e-9) / 10,
+		Count:     s.count,
+		Sessions:  len(s.sessions),
+	}
+	s.count, s.worst = 0, 0
+	clear(s.sessions)
+
+	if n.config.MaxRelax > 0 && suggestion.Suggested <= base+n.config.MaxRelax {
+		s.relaxed = suggestion.Suggested
+		suggestion.Applied = true
+	} else if suggestion.Suggested <= s.suggested {
+		// Already suggested; don't repeat it on every batch of near misses
+		n.mu.Unlock()
+		return
+	}
+	s.suggested = suggestion.Suggested
+	n.mu.Unlock()
+
+	if n.onSuggest != nil {
+		n.onSuggest(suggestion)
+	}
+}
@@ -0,0 +1,122 @@
+package scene
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// nearScene scores an average diff of 5.33 against a black image.
+func nearScene() *Scene {
+	return &Scene{
+		Name:   "main_city",
+		Points: []Point{{X: 1, Y: 1, Color: color.RGBA{R: 16, A: 255}}},
+	}
+}
+
+func TestNearMisses_Suggest(t *testing.T) {
+	var got []Suggestion
+	tracker := NewNearMisses(NearMissConfig{Margin: 1, MinCount: 3, MinSessions: 2},
+		func(s Suggestion) { got = append(got, s) })
+	a := &Matcher{Threshold: 5, NearMisses: tracker, Source: "s1"}
+	b := &Matcher{Threshold: 5, NearMisses: tracker, Source: "s2"}
+	scene, img := nearScene(), newMockImage()
+
+	for i := 0; i < 3; i++ {
+		if a.Match(scene, img) {
+			t.Fatal("Match() = true above the threshold")
+		}
+	}
+	if len(got) != 0 {
+		t.Fatalf("suggested after near misses from one session: %+v", got)
+	}
+	b.Match(scene, img)
+	want := Suggestion{Scene: "main_city", Threshold: 5, Suggested: 5.4, Count: 4, Sessions: 2}
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("suggestions = %+v, want %+v", got, want)
+	}
+
+	// The same suggestion is not repeated
+	for i := 0; i < 4; i++ {
+		a.Match(scene, img)
+		b.Match(scene, img)
+	}
+	if len(got) != 1 {
+		t.Errorf("suggestions = %+v, want one", got)
+	}
+	if a.Match(scene, img) {
+		t.Error("Match() = true without auto relaxing")
+	}
+}
+
+func TestNearMisses_AutoRelax(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxRelax float64
+		applied  bool
+	}{
+		{"within bound", 0.5, true},
+		{"beyond bound", 0.3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Suggestion
+			tracker := NewNearMisses(NearMissConfig{Margin: 1, MinCount: 2, MinSessions: 1, MaxRelax: tt.maxRelax},
+				func(s Suggestion) { got = append(got, s) })
+			m := &Matcher{Threshold: 5, NearMisses: tracker, Source: "s1"}
+			scene, img := nearScene(), newMockImage()
+
+			m.Match(scene, img)
+			m.Match(scene, img)
+			if len(got) != 1 || got[0].Applied != tt.applied {
+				t.Fatalf("suggestions = %+v, want applied = %v", got, tt.applied)
+			}
+			if matched := m.Match(scene, img); matched != tt.applied {
+				t.Errorf("Match() after suggestion = %v, want %v", matched, tt.applied)
+			}
+			if got := tracker.Threshold("main_city", 5); tt.applied && got != 5.4 {
+				t.Errorf("Threshold() = %v, want 5.4", got)
+			}
+		})
+	}
+}
+
+func TestNearMisses_IgnoresFarMisses(t *testing.T) {
+	var got []Suggestion
+	tracker := NewNearMisses(NearMissConfig{Margin: 1, MinCount: 1, MinSessions: 1},
+		func(s Suggestion) { got = append(got, s) })
+	m := &Matcher{Threshold: 5, NearMisses: tracker, Source: "s1"}
+	scene := &Scene{
+		Name:   "battle",
+		Points: []Point{{X: 1, Y: 1, Color: color.RGBA{R: 40, A: 255}}},
+	}
+
+	m.Match(scene, newMockImage())
+	if len(got) != 0 {
+		t.Errorf("suggestions = %+v, want none for a clear miss", got)
+	}
+}
+
+func TestMatcher_SceneThreshold(t *testing.T) {
+	scene, img := nearScene(), newMockImage()
+	m := NewMatcher(5)
+	if m.Match(scene, img) {
+		t.Fatal("Match() = true above the matcher threshold")
+	}
+	scene.Threshold = 6
+	if !m.Match(scene, img) {
+		t.Error("Match() should use the scene's own threshold")
+	}
+	if checks := m.Revalidate(scene, []image.Image{img}); !checks[0].Matched {
+		t.Error("Revalidate() should use the scene's own threshold")
+	}
+	if result := m.MatchWithDetails(scene, img); !result.Matched || result.Threshold != 6 {
+		t.Errorf("MatchWithDetails() = %+v, want a match against the scene's threshold 6", result)
+	}
+
+	// A tighter scene threshold wins over a looser matcher
+	scene.Threshold = 1
+	if loose := NewMatcher(20); loose.Match(scene, img) || loose.MatchWithDetails(scene, img).Matched {
+		t.Error("a looser matcher overrode the scene's own threshold")
+	}
+}
@@ -0,0 +1,96 @@
+package scene
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelMin is the fewest scenes a matcher checks on its pool; fewer are
+// quicker to check in turn.
+const parallelMin = 32
+
+// Pool checks scenes on a fixed set of worker goroutines shared by the
+// matchers of all sessions, so matching a large registry uses every core
+// without starting goroutines for each frame. It is safe for concurrent
+// use.
+type Pool struct {
+	workers int
+	jobs    chan func()
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewPool starts a pool with the given number of workers, or one per CPU
+// if workers is not positive. Close stops them.
+func NewPool(workers int) *Pool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	p := &Pool{
+		workers: workers,
+		jobs:    make(chan func(), workers),
+	}
+	for range workers {
+		go func() {
+			for job := range p.jobs {
+				job()
+			}
+		}()
+	}
+	return p
+}
+
+// Workers returns the number of worker goroutines.
+func (p *Pool) Workers() int {
+	return p.workers
+}
+
+// Run calls fn for each i in [0, n) and returns once all calls are done.
+// The calls are split into batches, one per worker; the caller runs the
+// first batch itself, and any the workers are too busy to take. After
+// Close, all calls are made by the caller.
+func (p *Pool) Run(n int, fn func(i int)) {
+	if n <= 0 {
+		return
+	}
+	size := (n + p.workers - 1) / p.workers
+
+	var wg sync.WaitGroup
+	p.mu.RLock()
+	for start := size; start < n; start += size {
+		batch := func() {
+			for i := start; i < min(start+size, n); i++ {
+				fn(i)
+			}
+		}
+		if p.closed {
+			batch()
+			continue
+		}
+		wg.Add(1)
+		select {
+		case p.jobs <- func() { defer wg.Done(); batch() }:
+		default:
+			batch()
+			wg.Done()
+		}
+	}
+	p.mu.RUnlock()
+
+	for i := range min(size, n) {
+		fn(i)
+	}
+	wg.Wait()
+}
+
+// Close stops the workers once they finish the batches they were given.
+// Calling Close more than once is a no-op.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+}
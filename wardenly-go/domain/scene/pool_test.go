@@ -0,0 +1,57 @@
+package scene
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPool_Run(t *testing.T) {
+	p := NewPool(4)
+	defer p.Close()
+
+	for _, n := range []int{0, 1, 3, 4, 10, 101} {
+		counts := make([]atomic.Int32, n)
+		p.Run(n, func(i int) { counts[i].Add(1) })
+		for i := range counts {
+			if got := counts[i].Load(); got != 1 {
+				t.Fatalf("Run(%d): fn(%d) called %d times, want 1", n, i, got)
+			}
+		}
+	}
+
+	// Sessions share the pool
+	var wg sync.WaitGroup
+	var total atomic.Int32
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Run(50, func(int) { total.Add(1) })
+		}()
+	}
+	wg.Wait()
+	if total.Load() != 400 {
+		t.Errorf("concurrent runs made %d calls, want 400", total.Load())
+	}
+}
+
+func TestPool_RunAfterClose(t *testing.T) {
+	p := NewPool(2)
+	p.Close()
+	p.Close()
+
+	var calls atomic.Int32
+	p.Run(10, func(int) { calls.Add(1) })
+	if calls.Load() != 10 {
+		t.Errorf("Run after Close made %d calls, want 10", calls.Load())
+	}
+}
+
+func TestNewPool_DefaultWorkers(t *testing.T) {
+	p := NewPool(0)
+	defer p.Close()
+	if p.Workers() < 1 {
+		t.Errorf("Workers() = %d, want at least 1", p.Workers())
+	}
+}
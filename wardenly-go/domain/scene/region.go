@@ -0,0 +1,94 @@
+package scene
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// HasRegion reports whether the scene's points are relative to Bounds and
+// searched for rather than read at fixed positions.
+func (s *Scene) HasRegion() bool {
+	return !s.Bounds.Empty()
+}
+
+// searchArea returns the part of img the scene's Bounds may be moved in.
+func (s *Scene) searchArea(img image.Image) image.Rectangle {
+	if s.Search.Empty() {
+		return img.Bounds()
+	}
+	return s.Search.Intersect(img.Bounds())
+}
+
+// At returns where the scene's point i lies in the frame when the scene
+// was found at offset from its Bounds. Points of scenes without Bounds
+// are at their own position.
+func (s *Scene) At(i int, offset image.Point) image.Point {
+	p := s.Points[i]
+	return image.Pt(p.X, p.Y).Add(s.Bounds.Min).Add(offset)
+}
+
+// locate reads the scene's points on img and returns their diffs and
+// average. A scene with Bounds is tried at every placement within its
+// search area and the placement with the lowest average is returned, with
+// its offset from Bounds; a placement is given up as soon as its diffs add
+// up to more than the best so far. If Bounds doesn't fit in the search
+// area the average is +Inf.
+func (s *Scene) locate(img image.Image) (offset image.Point, diffs []float64, avg float64) {
+	diffs = make([]float64, len(s.Points))
+	if !s.HasRegion() {
+		var total float64
+		for i, p := range s.Points {
+			diffs[i] = colorDiff(img.At(p.X, p.Y), p.Color)
+			total += diffs[i]
+		}
+		return image.Point{}, diffs, total / float64(len(s.Points))
+	}
+
+	area := s.searchArea(img)
+	size := s.Bounds.Size()
+	best := math.Inf(1)
+	scratch := make([]float64, len(s.Points))
+	for y := area.Min.Y; y+size.Y <= area.Max.Y; y++ {
+		for x := area.Min.X; x+size.X <= area.Max.X; x++ {
+			var total float64
+			for i, p := range s.Points {
+				scratch[i] = colorDiff(img.At(x+p.X, y+p.Y), p.Color)
+				total += scratch[i]
+				if total >= best {
+					break
+				}
+			}
+			if total < best {
+				best = total
+				copy(diffs, scratch)
+				offset = image.Pt(x, y).Sub(s.Bounds.Min)
+			}
+		}
+	}
+	return offset, diffs, best / float64(len(s.Points))
+}
+
+// validateRegion checks that the scene's points lie within its Bounds and
+// that Bounds fits in its search area.
+func (s *Scene) validateRegion() error {
+	if !s.HasRegion() {
+		if !s.Search.Empty() {
+			return fmt.Errorf("search needs bounds")
+		}
+		return nil
+	}
+	if s.Template != nil {
+		return fmt.Errorf("bounds and template are mutually exclusive")
+	}
+	size := image.Rectangle{Max: s.Bounds.Size()}
+	for _, p := range s.Points {
+		if !image.Pt(p.X, p.Y).In(size) {
+			return fmt.Errorf("point (%d, %d) is outside bounds of size %v", p.X, p.Y, s.Bounds.Size())
+		}
+	}
+	if !s.Search.Empty() && (s.Search.Dx() < size.Dx() || s.Search.Dy() < size.Dy()) {
+		return fmt.Errorf("search area %v is smaller than bounds %v", s.Search, s.Bounds)
+	}
+	return nil
+}
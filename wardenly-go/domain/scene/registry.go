@@ -2,13 +2,18 @@ package scene
 
 import (
 	"image"
+	"math"
+	"slices"
 	"sync"
+	"sync/atomic"
 )
 
 // Registry manages scene definitions and provides lookup functionality.
 type Registry struct {
 	scenes map[string]*Scene
 	mu     sync.RWMutex
+	// index is built on the first lookup after the scenes change
+	index atomic.Pointer[index]
 }
 
 // NewRegistry creates a new empty scene registry.
@@ -24,6 +29,7 @@ func (r *Registry) Register(scene *Scene) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.scenes[scene.Name] = scene
+	r.index.Store(nil)
 }
 
 // RegisterAll adds multiple scenes to the registry.
@@ -33,6 +39,15 @@ func (r *Registry) RegisterAll(scenes []*Scene) {
 	for _, scene := range scenes {
 		r.scenes[scene.Name] = scene
 	}
+	r.index.Store(nil)
+}
+
+// Unregister removes a scene from the registry.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.scenes, name)
+	r.index.Store(nil)
 }
 
 // Get retrieves a scene by name.
@@ -93,38 +108,122 @@ func (r *Registry) Clear() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.scenes = make(map[string]*Scene)
+	r.index.Store(nil)
+}
+
+// candidates returns the named scenes, or all if no names are given,
+// and the index of the registry.
+func (r *Registry) candidates(names []string) ([]*Scene, *index) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ix := r.index.Load()
+	if ix == nil {
+		ix = buildIndex(r.scenes)
+		r.index.Store(ix)
+	}
+
+	var scenes []*Scene
+	if len(names) == 0 {
+		scenes = make([]*Scene, 0, len(r.scenes))
+		for _, scene := range r.scenes {
+			scenes = append(scenes, scene)
+		}
+		return scenes, ix
+	}
+	for _, name := range names {
+		if scene, ok := r.scenes[name]; ok && !slices.Contains(scenes, scene) {
+			scenes = append(scenes, scene)
+		}
+	}
+	return scenes, ix
 }
 
 // FindMatch searches for a matching scene in the given image.
-// If names are provided, only those scenes are checked.
+// If names are provided, only those scenes are checked, and the first of
+// them that matches is returned. Large sets of scenes are narrowed down
+// by the registry's index and checked on the matcher's pool.
 // Returns nil if no match is found.
 func (r *Registry) FindMatch(img image.Image, matcher *Matcher, names ...string) *Scene {
 	if img == nil || matcher == nil {
 		return nil
 	}
 
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	scenes, ix := r.candidates(names)
+	for i, c := range matcher.checkAll(img, scenes, ix) {
+		if c.result != nil && c.result.Matched {
+			return scenes[i]
+		}
+	}
+	return nil
+}
 
-	if len(names) == 0 {
-		// Check all scenes
-		for _, scene := range r.scenes {
-			if matcher.Match(scene, img) {
-				return scene
-			}
+// BestMatch is the closest of several scenes that match an image.
+type BestMatch struct {
+	// Result is the matching scene with the lowest score
+	Result *MatchResult
+	// RunnerUp is the score of the next closest scene, matched or not, or
+	// +Inf when there is none
+	RunnerUp float64
+}
+
+// Scene returns the best matching scene.
+func (b *BestMatch) Scene() *Scene {
+	return b.Result.Scene
+}
+
+// Score returns the best scene's score; see MatchResult.Score.
+func (b *BestMatch) Score() float64 {
+	return b.Result.Score()
+}
+
+// Margin returns how much closer the best scene is than the runner-up.
+// A small margin means two candidates look alike on this image.
+func (b *BestMatch) Margin() float64 {
+	return b.RunnerUp - b.Score()
+}
+
+// FindBestMatch checks every candidate scene against the image and returns
+// the matching one with the lowest score, which for point scenes with the
+// same threshold is the lowest AvgDiff. Unlike FindMatch it doesn't stop
+// at the first match, so it picks the right one of similar scenes. If
+// names are provided, only those scenes are checked. Candidates that miss
+// are recorded as near misses, as Match does. Returns nil if no scene
+// matches.
+func (r *Registry) FindBestMatch(img image.Image, matcher *Matcher, names ...string) *BestMatch {
+	if img == nil || matcher == nil {
+		return nil
+	}
+
+	scenes, ix := r.candidates(names)
+	checks := matcher.checkAll(img, scenes, ix)
+
+	var best *BestMatch
+	bestScore, runnerUp := math.Inf(1), math.Inf(1)
+	for _, c := range checks {
+		if c.result == nil {
+			continue
 		}
-	} else {
-		// Check only specified scenes
-		for _, name := range names {
-			if scene, ok := r.scenes[name]; ok {
-				if matcher.Match(scene, img) {
-					return scene
-				}
-			}
+		score := c.result.Score()
+		if c.result.Matched && (best == nil || score < bestScore) {
+			runnerUp = min(runnerUp, bestScore)
+			best, bestScore = &BestMatch{Result: c.result}, score
+		} else {
+			runnerUp = min(runnerUp, score)
 		}
 	}
-
-	return nil
+	if best == nil {
+		return nil
+	}
+	// Scenes the index ruled out can't match, but one may still be the
+	// runner-up
+	for i, c := range checks {
+		if c.result == nil && c.minScore < runnerUp {
+			runnerUp = min(runnerUp, matcher.MatchWithDetails(scenes[i], img).Score())
+		}
+	}
+	best.RunnerUp = runnerUp
+	return best
 }
 
 // FindAllMatches returns all scenes that match the given image.
@@ -133,13 +232,11 @@ func (r *Registry) FindAllMatches(img image.Image, matcher *Matcher) []*Scene {
 		return nil
 	}
 
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
+	scenes, ix := r.candidates(nil)
 	var matches []*Scene
-	for _, scene := range r.scenes {
-		if matcher.Match(scene, img) {
-			matches = append(matches, scene)
+	for i, c := range matcher.checkAll(img, scenes, ix) {
+		if c.result != nil && c.result.Matched {
+			matches = append(matches, scenes[i])
 		}
 	}
 	return matches
@@ -4,9 +4,11 @@ package scene
 import (
 	"image"
 	"image/color"
+	"math"
 )
 
-// Scene represents a recognizable game state defined by color points.
+// Scene represents a recognizable game state defined by color points or
+// an image template.
 type Scene struct {
 	// Name is the unique identifier for this scene
 	Name string
@@ -17,6 +19,21 @@ type Scene struct {
 	// Points are the color checkpoints used to identify this scene
 	Points []Point
 
+	// Template identifies the scene by an image patch instead of Points (optional)
+	Template *Template
+
+	// Threshold overrides the matcher threshold for Points (optional)
+	Threshold float64
+
+	// Bounds, if set, is the part of the frame the scene was recorded in,
+	// such as a dialog: Points are relative to its top-left corner, and the
+	// scene matches wherever that part is found within Search (optional)
+	Bounds image.Rectangle
+
+	// Search is the area of the frame Bounds may be moved around in; empty
+	// searches the whole frame (optional)
+	Search image.Rectangle
+
 	// Actions are predefined actions available in this scene
 	Actions map[string]Action
 }
@@ -58,6 +75,14 @@ type Matcher struct {
 	// Threshold is the maximum average color difference allowed for a match.
 	// Lower values require more precise matches.
 	Threshold float64
+
+	// NearMisses, if set, records point scenes that miss by a small margin
+	// and supplies their relaxed thresholds (optional)
+	NearMisses *NearMisses
+	// Source identifies this matcher's near misses, e.g. a session ID
+	Source string
+	// Pool, if set, checks large sets of scenes in parallel (optional)
+	Pool *Pool
 }
 
 // NewMatcher creates a new scene matcher with the specified threshold.
@@ -69,19 +94,100 @@ func NewMatcher(threshold float64) *Matcher {
 }
 
 // Match checks if the given image matches this scene.
+// Scenes with a template are matched by the template alone.
 func (m *Matcher) Match(scene *Scene, img image.Image) bool {
+	if scene.Template != nil {
+		return img != nil && scene.Template.Find(img).Matched
+	}
 	if len(scene.Points) == 0 || img == nil {
 		return false
 	}
 
-	var totalDiff float64
-	for _, point := range scene.Points {
-		diff := colorDiff(img.At(point.X, point.Y), point.Color)
-		totalDiff += diff
+	_, _, avgDiff := scene.locate(img)
+	base := m.baseThreshold(scene)
+	if m.NearMisses == nil {
+		return avgDiff <= base
+	}
+	if avgDiff <= m.NearMisses.Threshold(scene.Name, base) {
+		return true
+	}
+	m.NearMisses.Record(scene.Name, m.Source, avgDiff, base)
+	return false
+}
+
+// recordMiss records a point scene that failed to match as a near miss.
+func (m *Matcher) recordMiss(result *MatchResult) {
+	scene := result.Scene
+	if m.NearMisses == nil || result.Matched || scene.Template != nil || len(scene.Points) == 0 {
+		return
+	}
+	m.NearMisses.Record(scene.Name, m.Source, result.AvgDiff, m.baseThreshold(scene))
+}
+
+// check is the outcome of checking a candidate scene: its result, or for a
+// scene ruled out by its probe, the least score it can have.
+type check struct {
+	result   *MatchResult
+	minScore float64
+}
+
+// checkAll checks each scene against img. Point scenes whose probe in ix
+// is too far off to match, or to be a near miss, are ruled out without
+// reading their other points; the rest are checked on the pool if there
+// are enough of them. ix may be nil.
+func (m *Matcher) checkAll(img image.Image, scenes []*Scene, ix *index) []check {
+	checks := make([]check, len(scenes))
+	pixels := make(map[image.Point]color.Color)
+	todo := make([]int, 0, len(scenes))
+	for i, scene := range scenes {
+		if ix != nil {
+			threshold := m.threshold(scene)
+			if avg := ix.minAvgDiff(scene, img, pixels); avg > threshold+m.nearMissMargin() {
+				checks[i].minScore = avg / threshold
+				continue
+			}
+		}
+		todo = append(todo, i)
+	}
+
+	checkOne := func(j int) {
+		i := todo[j]
+		checks[i].result = m.MatchWithDetails(scenes[i], img)
+		m.recordMiss(checks[i].result)
 	}
+	if m.Pool != nil && len(todo) >= parallelMin {
+		m.Pool.Run(len(todo), checkOne)
+	} else {
+		for j := range todo {
+			checkOne(j)
+		}
+	}
+	return checks
+}
+
+func (m *Matcher) nearMissMargin() float64 {
+	if m.NearMisses == nil {
+		return 0
+	}
+	return m.NearMisses.Margin()
+}
 
-	avgDiff := totalDiff / float64(len(scene.Points))
-	return avgDiff <= m.Threshold
+// baseThreshold returns the scene's own threshold, or the matcher's.
+func (m *Matcher) baseThreshold(scene *Scene) float64 {
+	if scene.Threshold > 0 {
+		return scene.Threshold
+	}
+	return m.Threshold
+}
+
+// threshold returns the threshold a scene is matched with, including any
+// relaxation from near misses.
+func (m *Matcher) threshold(scene *Scene) float64 {
+	base := m.baseThreshold(scene)
+	if m.NearMisses != nil {
+		return m.NearMisses.Threshold(scene.Name, base)
+	}
+	return base
 }
 
 // MatchResult contains details about a scene match attempt.
@@ -90,6 +196,13 @@ type MatchResult struct {
 	Matched    bool
 	AvgDiff    float64
 	PointDiffs []float64
+	// Offset is how far a scene with Bounds was found from them
+	Offset image.Point
+	// Threshold is what AvgDiff was held against, or the minimum score for
+	// template scenes
+	Threshold float64
+	// Template is the best template placement (template scenes only)
+	Template *TemplateMatch
 }
 
 // MatchWithDetails performs matching and returns detailed results.
@@ -99,21 +212,115 @@ func (m *Matcher) MatchWithDetails(scene *Scene, img image.Image) *MatchResult {
 		PointDiffs: make([]float64, len(scene.Points)),
 	}
 
+	if scene.Template != nil {
+		result.Threshold = scene.Template.Threshold
+		if img != nil {
+			match := scene.Template.Find(img)
+			result.Template = &match
+			result.Matched = match.Matched
+		}
+		return result
+	}
 	if len(scene.Points) == 0 || img == nil {
 		return result
 	}
 
-	var totalDiff float64
-	for i, point := range scene.Points {
-		diff := colorDiff(img.At(point.X, point.Y), point.Color)
-		result.PointDiffs[i] = diff
-		totalDiff += diff
+	result.Offset, result.PointDiffs, result.AvgDiff = scene.locate(img)
+	result.Threshold = m.threshold(scene)
+	result.Matched = result.AvgDiff <= result.Threshold
+
+	return result
+}
+
+// Score returns how far the frame is from the scene relative to its
+// threshold: 0 is a perfect match and at most 1 is a match. Point scenes
+// score AvgDiff over the threshold, template scenes the shortfall of the
+// correlation from 1 over that of the threshold. Scenes that couldn't be
+// checked score +Inf.
+func (r *MatchResult) Score() float64 {
+	if r.Scene.Template != nil {
+		if r.Template == nil {
+			return math.Inf(1)
+		}
+		if r.Threshold >= 1 {
+			return 1 - r.Template.Score
+		}
+		return (1 - r.Template.Score) / (1 - r.Threshold)
+	}
+	if len(r.Scene.Points) == 0 || r.Threshold <= 0 {
+		return math.Inf(1)
 	}
+	return r.AvgDiff / r.Threshold
+}
 
-	result.AvgDiff = totalDiff / float64(len(scene.Points))
-	result.Matched = result.AvgDiff <= m.Threshold
+// PointCheck is the outcome of comparing one scene point against a frame.
+type PointCheck struct {
+	Point   Point
+	Actual  color.RGBA
+	Diff    float64
+	Matched bool
+}
 
-	return result
+// FrameCheck is the outcome of validating a scene against one frame.
+type FrameCheck struct {
+	Matched bool
+	AvgDiff float64
+	Points  []PointCheck
+	// Offset is how far a scene with Bounds was found from them
+	Offset image.Point
+	// Template is the best template placement (template scenes only)
+	Template *TemplateMatch
+}
+
+// Revalidate checks a scene against each frame and reports per-point diffs.
+// A point matches when its diff is within the scene's threshold; the frame
+// matches under the same average rule as Match. Template scenes report the
+// best template placement instead.
+func (m *Matcher) Revalidate(scene *Scene, frames []image.Image) []FrameCheck {
+	checks := make([]FrameCheck, len(frames))
+	threshold := m.threshold(scene)
+
+	for i, img := range frames {
+		if img == nil {
+			continue
+		}
+		if scene.Template != nil {
+			match := scene.Template.Find(img)
+			checks[i] = FrameCheck{Matched: match.Matched, Template: &match}
+			continue
+		}
+		if len(scene.Points) == 0 {
+			continue
+		}
+
+		offset, diffs, avg := scene.locate(img)
+		check := FrameCheck{Points: make([]PointCheck, len(scene.Points)), AvgDiff: avg, Offset: offset}
+		for j, point := range scene.Points {
+			at := scene.At(j, offset)
+			check.Points[j] = PointCheck{
+				Point:   point,
+				Actual:  color.RGBAModel.Convert(img.At(at.X, at.Y)).(color.RGBA),
+				Diff:    diffs[j],
+				Matched: diffs[j] <= threshold,
+			}
+		}
+		check.Matched = check.AvgDiff <= threshold
+		checks[i] = check
+	}
+
+	return checks
+}
+
+// Regressions returns the indices of frames matched by the original scene
+// but no longer matched by the edited one.
+func (m *Matcher) Regressions(original, edited *Scene, frames []image.Image) []int {
+	var indices []int
+	for i, img := range frames {
+		if m.Match(original, img) && !m.Match(edited, img) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
 }
 
 // colorDiff calculates the color difference between two colors.
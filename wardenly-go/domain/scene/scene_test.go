@@ -1,9 +1,16 @@
 package scene
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
 	"testing"
+	"testing/fstest"
 )
 
 // mockImage creates a simple image for testing.
@@ -125,11 +132,71 @@ func TestMatcher_MatchWithDetails(t *testing.T) {
 	if result.AvgDiff != 0 {
 		t.Errorf("Expected 0 avg diff for exact match, got %v", result.AvgDiff)
 	}
+	if result.Threshold != 5.0 {
+		t.Errorf("Threshold = %v, want 5", result.Threshold)
+	}
 	if len(result.PointDiffs) != 1 {
 		t.Errorf("Expected 1 point diff, got %d", len(result.PointDiffs))
 	}
 }
 
+func TestMatcher_Revalidate(t *testing.T) {
+	matcher := NewMatcher(5.0)
+
+	scene := &Scene{
+		Name: "test_scene",
+		Points: []Point{
+			{X: 100, Y: 100, Color: color.RGBA{255, 0, 0, 255}},
+			{X: 200, Y: 200, Color: color.RGBA{0, 255, 0, 255}},
+		},
+	}
+
+	matching := newMockImage()
+	matching.SetColor(100, 100, color.RGBA{255, 0, 0, 255})
+	matching.SetColor(200, 200, color.RGBA{0, 255, 0, 255})
+
+	partial := newMockImage()
+	partial.SetColor(100, 100, color.RGBA{255, 0, 0, 255})
+
+	checks := matcher.Revalidate(scene, []image.Image{matching, partial, nil})
+	if len(checks) != 3 {
+		t.Fatalf("Expected 3 frame checks, got %d", len(checks))
+	}
+
+	if !checks[0].Matched || !checks[0].Points[0].Matched || !checks[0].Points[1].Matched {
+		t.Error("Expected all points to match on first frame")
+	}
+	if checks[1].Matched {
+		t.Error("Expected second frame not to match")
+	}
+	if !checks[1].Points[0].Matched || checks[1].Points[1].Matched {
+		t.Error("Expected only first point to match on second frame")
+	}
+	if checks[1].Points[1].Actual != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("Unexpected actual color %v", checks[1].Points[1].Actual)
+	}
+	if checks[2].Matched || checks[2].Points != nil {
+		t.Error("Expected nil frame to produce an empty check")
+	}
+}
+
+func TestMatcher_Regressions(t *testing.T) {
+	matcher := NewMatcher(5.0)
+
+	original := &Scene{Points: []Point{{X: 10, Y: 10, Color: color.RGBA{255, 0, 0, 255}}}}
+	edited := &Scene{Points: []Point{{X: 10, Y: 10, Color: color.RGBA{0, 0, 255, 255}}}}
+
+	red := newMockImage()
+	red.SetColor(10, 10, color.RGBA{255, 0, 0, 255})
+	blue := newMockImage()
+	blue.SetColor(10, 10, color.RGBA{0, 0, 255, 255})
+
+	got := matcher.Regressions(original, edited, []image.Image{blue, red})
+	if len(got) != 1 || got[0] != 1 {
+		t.Errorf("Regressions() = %v, want [1]", got)
+	}
+}
+
 func TestNewMatcher_DefaultThreshold(t *testing.T) {
 	matcher := NewMatcher(0)
 	if matcher.Threshold != 5.0 {
@@ -251,3 +318,297 @@ func TestRegistry_FindMatch(t *testing.T) {
 		}
 	})
 }
+
+func TestRegistry_FindBestMatch(t *testing.T) {
+	registry := NewRegistry()
+	matcher := NewMatcher(5.0)
+
+	exact := &Scene{Name: "exact", Points: []Point{{X: 100, Y: 100, Color: color.RGBA{255, 0, 0, 255}}}}
+	near := &Scene{Name: "close", Points: []Point{{X: 100, Y: 100, Color: color.RGBA{249, 0, 0, 255}}}}
+	other := &Scene{Name: "other", Points: []Point{{X: 200, Y: 200, Color: color.RGBA{0, 0, 255, 255}}}}
+	registry.RegisterAll([]*Scene{exact, near, other})
+
+	img := newMockImage()
+	img.SetColor(100, 100, color.RGBA{255, 0, 0, 255})
+
+	// FindMatch takes the first candidate under the threshold
+	if found := registry.FindMatch(img, matcher, "close", "exact"); found != near {
+		t.Fatalf("FindMatch() = %v, want close", found)
+	}
+
+	best := registry.FindBestMatch(img, matcher, "close", "exact", "other")
+	if best == nil || best.Scene() != exact {
+		t.Fatalf("FindBestMatch() = %+v, want exact", best)
+	}
+	if best.Score() != 0 || best.RunnerUp != 0.4 || best.Margin() != 0.4 {
+		t.Errorf("score %v, runner-up %v, margin %v; want 0, 0.4, 0.4", best.Score(), best.RunnerUp, best.Margin())
+	}
+
+	if best := registry.FindBestMatch(img, matcher, "exact"); best == nil || !math.IsInf(best.RunnerUp, 1) {
+		t.Errorf("FindBestMatch(exact) = %+v, want no runner-up", best)
+	}
+	if best := registry.FindBestMatch(img, matcher, "other"); best != nil {
+		t.Errorf("FindBestMatch(other) = %+v, want nil", best)
+	}
+	if registry.FindBestMatch(nil, matcher) != nil {
+		t.Error("FindBestMatch(nil) matched")
+	}
+}
+
+func TestRegistry_IndexRulesOutScenes(t *testing.T) {
+	registry := NewRegistry()
+	matcher := NewMatcher(5.0)
+	red := color.RGBA{255, 0, 0, 255}
+
+	// Two points: the probe may be off by up to 10 and still match
+	home := &Scene{Name: "home", Points: []Point{{X: 10, Y: 10, Color: red}, {X: 20, Y: 20, Color: red}}}
+	blue := &Scene{Name: "blue", Points: []Point{{X: 10, Y: 10, Color: color.RGBA{0, 0, 255, 255}}, {X: 20, Y: 20, Color: red}}}
+	near := &Scene{Name: "near", Points: []Point{{X: 10, Y: 10, Color: color.RGBA{231, 0, 0, 255}}, {X: 20, Y: 20, Color: color.RGBA{246, 0, 0, 255}}}}
+	registry.RegisterAll([]*Scene{home, blue, near})
+
+	img := newMockImage()
+	img.SetColor(10, 10, red)
+	img.SetColor(20, 20, red)
+
+	scenes, ix := registry.candidates([]string{"home", "blue", "near"})
+	checks := matcher.checkAll(img, scenes, ix)
+	if checks[0].result == nil || !checks[0].result.Matched {
+		t.Errorf("home = %+v, want checked and matched", checks[0])
+	}
+	if checks[1].result != nil || checks[1].minScore <= 1 {
+		t.Errorf("blue = %+v, want ruled out", checks[1])
+	}
+	// near's probe is off by 8, within the 10 it may be
+	if checks[2].result == nil || checks[2].result.Matched {
+		t.Errorf("near = %+v, want checked and missed", checks[2])
+	}
+
+	// A ruled out scene still counts as runner-up
+	best := registry.FindBestMatch(img, matcher, "home", "blue")
+	if best == nil || best.Scene() != home || best.RunnerUp != 17 {
+		t.Errorf("FindBestMatch() = %+v, want home with runner-up 17", best)
+	}
+
+	// Changing the registry rebuilds the index
+	registry.Unregister("blue")
+	if _, ix2 := registry.candidates(nil); ix2 == ix || len(ix2.probes) != 2 {
+		t.Error("index not rebuilt after Unregister")
+	}
+}
+
+func TestRegistry_FindWithPool(t *testing.T) {
+	pool := NewPool(4)
+	defer pool.Close()
+	registry := NewRegistry()
+	for i := range 3 * parallelMin {
+		c := color.RGBA{uint8(i), 0, 0, 255}
+		registry.Register(&Scene{Name: fmt.Sprint("scene", i), Points: []Point{{X: i % 7, Y: 0, Color: c}, {X: 50, Y: 50, Color: c}}})
+	}
+	img := newMockImage()
+	img.SetColor(3, 0, color.RGBA{40, 0, 0, 255})
+	img.SetColor(50, 50, color.RGBA{40, 0, 0, 255})
+
+	sequential := NewMatcher(5.0)
+	parallel := NewMatcher(5.0)
+	parallel.Pool = pool
+	want := registry.FindAllMatches(img, sequential)
+	got := registry.FindAllMatches(img, parallel)
+	if len(want) == 0 || len(got) != len(want) {
+		t.Fatalf("FindAllMatches() = %d scenes with the pool, %d without", len(got), len(want))
+	}
+	best := registry.FindBestMatch(img, sequential)
+	pooled := registry.FindBestMatch(img, parallel)
+	if best == nil || best.Scene().Name != "scene38" {
+		t.Fatalf("FindBestMatch() = %+v, want scene38", best)
+	}
+	if pooled == nil || pooled.Scene() != best.Scene() || pooled.RunnerUp != best.RunnerUp {
+		t.Errorf("FindBestMatch() with the pool = %+v, want %+v", pooled, best)
+	}
+}
+
+func TestMatcher_RegionScene(t *testing.T) {
+	matcher := NewMatcher(5.0)
+	red, white := color.RGBA{255, 0, 0, 255}, color.RGBA{255, 255, 255, 255}
+	// A dialog recorded at (100, 100) with a red title bar and white body
+	popup := &Scene{
+		Name:   "popup",
+		Points: []Point{{X: 2, Y: 1, Color: red}, {X: 10, Y: 1, Color: red}, {X: 6, Y: 8, Color: white}},
+		Bounds: image.Rect(100, 100, 120, 112),
+	}
+	drawPopup := func(img *mockImage, at image.Point) {
+		for _, p := range popup.Points {
+			img.SetColor(at.X+p.X, at.Y+p.Y, p.Color)
+		}
+	}
+
+	img := newMockImage()
+	drawPopup(img, image.Pt(40, 70))
+	result := matcher.MatchWithDetails(popup, img)
+	if !result.Matched || result.Offset != image.Pt(-60, -30) || result.AvgDiff != 0 {
+		t.Fatalf("MatchWithDetails() = %+v, want a match at offset (-60, -30)", result)
+	}
+	if at := popup.At(2, result.Offset); at != image.Pt(46, 78) {
+		t.Errorf("At() = %v, want (46, 78)", at)
+	}
+	checks := matcher.Revalidate(popup, []image.Image{img})
+	if !checks[0].Matched || checks[0].Offset != result.Offset || checks[0].Points[0].Actual != red {
+		t.Errorf("Revalidate() = %+v", checks[0])
+	}
+
+	// The search area keeps it from matching elsewhere
+	popup.Search = image.Rect(80, 80, 200, 200)
+	if matcher.Match(popup, img) {
+		t.Error("Match() found the popup outside its search area")
+	}
+	drawPopup(img, image.Pt(150, 90))
+	if !matcher.Match(popup, img) {
+		t.Error("Match() missed the popup in its search area")
+	}
+	// Bounds that don't fit in the search area never match
+	popup.Search = image.Rect(0, 0, 10, 10)
+	if result := matcher.MatchWithDetails(popup, img); result.Matched || !math.IsInf(result.Score(), 1) {
+		t.Errorf("MatchWithDetails() = %+v, want no placement", result)
+	}
+}
+
+func TestMatchResult_Score(t *testing.T) {
+	points := &MatchResult{Scene: &Scene{Points: make([]Point, 2)}, AvgDiff: 2.5, Threshold: 5}
+	if got := points.Score(); got != 0.5 {
+		t.Errorf("point Score() = %v, want 0.5", got)
+	}
+	tmpl := &MatchResult{Scene: &Scene{Template: &Template{}}, Threshold: 0.9, Template: &TemplateMatch{Score: 0.95}}
+	if got := tmpl.Score(); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("template Score() = %v, want 0.5", got)
+	}
+	if got := (&MatchResult{Scene: &Scene{Name: "empty"}}).Score(); !math.IsInf(got, 1) {
+		t.Errorf("empty Score() = %v, want +Inf", got)
+	}
+}
+
+// texturedFrame returns a frame with a non-repeating pattern, offset in brightness.
+func texturedFrame(brightness int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 150))
+	for y := 0; y < 150; y++ {
+		for x := 0; x < 200; x++ {
+			v := (x*37+y*91+(x*y)%23*11)%200 + brightness
+			img.Set(x, y, color.RGBA{uint8(v), uint8(v / 2), uint8(255 - v), 255})
+		}
+	}
+	return img
+}
+
+func crop(img image.Image, r image.Rectangle) *image.RGBA {
+	patch := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(patch, patch.Bounds(), img, r.Min, draw.Src)
+	return patch
+}
+
+func TestTemplate_Find(t *testing.T) {
+	frame := texturedFrame(0)
+	patch := crop(frame, image.Rect(60, 40, 80, 56))
+
+	// The UI moved by (3, -2) since the template was recorded
+	tmpl, err := NewTemplate(patch, 57, 42, 0, 0)
+	if err != nil {
+		t.Fatalf("NewTemplate() error = %v", err)
+	}
+	if tmpl.Search != DefaultTemplateSearch || tmpl.Threshold != DefaultTemplateThreshold {
+		t.Errorf("defaults = %d, %v", tmpl.Search, tmpl.Threshold)
+	}
+
+	match := tmpl.Find(frame)
+	if !match.Matched || match.Offset != image.Pt(3, -2) || match.Score < 0.999 {
+		t.Errorf("Find() = %+v, want match at offset (3,-2)", match)
+	}
+
+	// Correlation ignores a uniform brightness change
+	if match := tmpl.Find(texturedFrame(40)); !match.Matched {
+		t.Errorf("Find(brighter frame) = %+v, want match", match)
+	}
+
+	// Shifted further than the search window
+	narrow, _ := NewTemplate(patch, 57, 42, 2, 0)
+	if match := narrow.Find(frame); match.Matched {
+		t.Errorf("Find() outside search window = %+v, want no match", match)
+	}
+
+	if match := tmpl.Find(image.NewRGBA(frame.Bounds())); match.Matched {
+		t.Errorf("Find(blank frame) = %+v, want no match", match)
+	}
+
+	if _, err := NewTemplate(image.NewRGBA(image.Rect(0, 0, 8, 8)), 0, 0, 0, 0); !errors.Is(err, ErrFlatTemplate) {
+		t.Errorf("NewTemplate(flat) error = %v, want ErrFlatTemplate", err)
+	}
+}
+
+func TestMatcher_TemplateScene(t *testing.T) {
+	frame := texturedFrame(0)
+	tmpl, _ := NewTemplate(crop(frame, image.Rect(100, 80, 124, 100)), 100, 80, 4, 0)
+	scene := &Scene{Name: "template_scene", Template: tmpl}
+	matcher := NewMatcher(5.0)
+
+	if !matcher.Match(scene, frame) {
+		t.Error("Match() = false, want true")
+	}
+	if matcher.Match(scene, nil) {
+		t.Error("Match(nil) = true, want false")
+	}
+
+	result := matcher.MatchWithDetails(scene, frame)
+	if !result.Matched || result.Template == nil || result.Template.Offset != (image.Point{}) {
+		t.Errorf("MatchWithDetails() = %+v", result)
+	}
+
+	checks := matcher.Revalidate(scene, []image.Image{frame, texturedFrame(0).SubImage(image.Rect(0, 0, 50, 50))})
+	if !checks[0].Matched || checks[0].Template == nil {
+		t.Errorf("Revalidate()[0] = %+v, want template match", checks[0])
+	}
+	if checks[1].Matched {
+		t.Errorf("Revalidate()[1] = %+v, want no match on a frame without the region", checks[1])
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	frame := texturedFrame(0)
+	var shot, patch bytes.Buffer
+	if err := png.Encode(&shot, frame); err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(&patch, crop(frame, image.Rect(10, 20, 40, 35))); err != nil {
+		t.Fatal(err)
+	}
+	images := fstest.MapFS{
+		"shot.png":  {Data: shot.Bytes()},
+		"patch.png": {Data: patch.Bytes()},
+	}
+
+	// A region of a full screenshot
+	tmpl, err := loadTemplate(images, &templateSpec{Image: "shot.png", X: 10, Y: 20, Width: 30, Height: 15, Search: 3})
+	if err != nil {
+		t.Fatalf("loadTemplate(region) error = %v", err)
+	}
+	if tmpl.Bounds() != image.Rect(10, 20, 40, 35) || tmpl.Search != 3 || !tmpl.Find(frame).Matched {
+		t.Errorf("region template bounds = %v, search = %d", tmpl.Bounds(), tmpl.Search)
+	}
+
+	// The image is the patch
+	tmpl, err = loadTemplate(images, &templateSpec{Image: "patch.png", X: 10, Y: 20, Threshold: 0.95})
+	if err != nil {
+		t.Fatalf("loadTemplate(patch) error = %v", err)
+	}
+	if tmpl.Bounds() != image.Rect(10, 20, 40, 35) || tmpl.Threshold != 0.95 {
+		t.Errorf("patch template bounds = %v, threshold = %v", tmpl.Bounds(), tmpl.Threshold)
+	}
+
+	for name, spec := range map[string]*templateSpec{
+		"missing image":  {Image: "missing.png"},
+		"region outside": {Image: "patch.png", Width: 100, Height: 100},
+	} {
+		if _, err := loadTemplate(images, spec); err == nil {
+			t.Errorf("loadTemplate(%s) succeeded, want error", name)
+		}
+	}
+	if _, err := loadTemplate(nil, &templateSpec{Image: "shot.png"}); err == nil {
+		t.Error("loadTemplate without images succeeded, want error")
+	}
+}
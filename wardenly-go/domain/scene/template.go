@@ -0,0 +1,218 @@
+package scene
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"math"
+)
+
+// Template matching defaults.
+const (
+	DefaultTemplateSearch    = 8
+	DefaultTemplateThreshold = 0.9
+)
+
+// ErrFlatTemplate is returned for template patches of a single color, which
+// can't be matched by correlation.
+var ErrFlatTemplate = errors.New("template patch has no contrast")
+
+// Template identifies a scene by an image patch expected near a position in
+// the frame. It tolerates small UI shifts that break color points: the patch
+// is searched within Search pixels of its position and scored by normalized
+// cross-correlation, which also ignores uniform brightness changes.
+type Template struct {
+	// Image is the patch to find
+	Image image.Image
+
+	// X, Y are where the patch's top-left corner is expected in the frame
+	X, Y int
+
+	// Search is how far the patch may be shifted in each direction
+	Search int
+
+	// Threshold is the minimum correlation score (up to 1) for a match
+	Threshold float64
+
+	// Precomputed from Image: luminance minus mean, and its norm
+	w, h int
+	zero []float64
+	norm float64
+}
+
+// NewTemplate prepares a template for matching. Zero search and threshold
+// use the defaults.
+func NewTemplate(img image.Image, x, y, search int, threshold float64) (*Template, error) {
+	if search <= 0 {
+		search = DefaultTemplateSearch
+	}
+	if threshold <= 0 {
+		threshold = DefaultTemplateThreshold
+	}
+
+	b := img.Bounds()
+	t := &Template{
+		Image:     img,
+		X:         x,
+		Y:         y,
+		Search:    search,
+		Threshold: threshold,
+		w:         b.Dx(),
+		h:         b.Dy(),
+	}
+	if t.w == 0 || t.h == 0 {
+		return nil, fmt.Errorf("template patch is empty")
+	}
+
+	gray := luminance(img, b)
+	var mean float64
+	for _, v := range gray {
+		mean += v
+	}
+	mean /= float64(len(gray))
+
+	var sumSq float64
+	for i, v := range gray {
+		gray[i] = v - mean
+		sumSq += gray[i] * gray[i]
+	}
+	if sumSq < 1e-6 {
+		return nil, ErrFlatTemplate
+	}
+	t.zero = gray
+	t.norm = math.Sqrt(sumSq)
+	return t, nil
+}
+
+// Bounds returns the expected position of the patch in the frame.
+func (t *Template) Bounds() image.Rectangle {
+	return image.Rect(t.X, t.Y, t.X+t.w, t.Y+t.h)
+}
+
+// TemplateMatch is the best placement of a template in a frame.
+type TemplateMatch struct {
+	// Score is the correlation at the best placement (-1 to 1)
+	Score float64
+	// Offset is the shift of the best placement from the expected position
+	Offset image.Point
+	// Matched reports whether Score reaches the template threshold
+	Matched bool
+}
+
+// Find searches the frame around the expected position and returns the
+// best placement. Placements that would extend past the frame are skipped.
+func (t *Template) Find(img image.Image) TemplateMatch {
+	best := TemplateMatch{Score: -1}
+	if img == nil {
+		return best
+	}
+
+	area := t.Bounds().Inset(-t.Search).Intersect(img.Bounds())
+	if area.Dx() < t.w || area.Dy() < t.h {
+		return best
+	}
+	frame := luminance(img, area)
+	stride := area.Dx()
+	n := float64(t.w * t.h)
+
+	for oy := 0; oy+t.h <= area.Dy(); oy++ {
+		for ox := 0; ox+t.w <= area.Dx(); ox++ {
+			var sum, sumSq, cross float64
+			for py := 0; py < t.h; py++ {
+				row := frame[(oy+py)*stride+ox:]
+				patch := t.zero[py*t.w:]
+				for px := 0; px < t.w; px++ {
+					v := row[px]
+					sum += v
+					sumSq += v * v
+					cross += v * patch[px]
+				}
+			}
+			// cross equals the correlation of the mean-subtracted window,
+			// since the patch values sum to zero
+			variance := sumSq - sum*sum/n
+			if variance < 1e-6 {
+				continue
+			}
+			score := cross / (math.Sqrt(variance) * t.norm)
+			if score > best.Score {
+				best.Score = score
+				best.Offset = image.Pt(area.Min.X+ox-t.X, area.Min.Y+oy-t.Y)
+			}
+		}
+	}
+
+	best.Matched = best.Score >= t.Threshold
+	return best
+}
+
+// luminance returns the 8-bit luminance of r in img, row by row.
+func luminance(img image.Image, r image.Rectangle) []float64 {
+	rgba, ok := img.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(r)
+		draw.Draw(rgba, r, img, r.Min, draw.Src)
+	}
+
+	out := make([]float64, 0, r.Dx()*r.Dy())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := rgba.PixOffset(r.Min.X, y)
+		for x := r.Min.X; x < r.Max.X; x++ {
+			p := rgba.Pix[i : i+3 : i+3]
+			out = append(out, 0.299*float64(p[0])+0.587*float64(p[1])+0.114*float64(p[2]))
+			i += 4
+		}
+	}
+	return out
+}
+
+// templateSpec is the template section of a scene file.
+type templateSpec struct {
+	// Image is a PNG file relative to the scene file
+	Image string `yaml:"image"`
+	// X, Y are the expected position of the patch in the frame
+	X int `yaml:"x"`
+	Y int `yaml:"y"`
+	// Width and Height, when set, crop the patch at X, Y out of Image, so a
+	// full screenshot can be referenced; otherwise Image is the patch
+	Width     int     `yaml:"width"`
+	Height    int     `yaml:"height"`
+	Search    int     `yaml:"search"`
+	Threshold float64 `yaml:"threshold"`
+}
+
+// loadTemplate reads the template image of a scene file from images.
+func loadTemplate(images fs.FS, spec *templateSpec) (*Template, error) {
+	if images == nil {
+		return nil, fmt.Errorf("template image %s: no directory to load it from", spec.Image)
+	}
+	f, err := images.Open(spec.Image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open template image: %w", err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode template image %s: %w", spec.Image, err)
+	}
+
+	if spec.Width > 0 || spec.Height > 0 {
+		crop := image.Rect(spec.X, spec.Y, spec.X+spec.Width, spec.Y+spec.Height)
+		if spec.Width <= 0 || spec.Height <= 0 || !crop.In(img.Bounds()) {
+			return nil, fmt.Errorf("template region %v is outside image %s %v", crop, spec.Image, img.Bounds())
+		}
+		patch := image.NewRGBA(image.Rect(0, 0, spec.Width, spec.Height))
+		draw.Draw(patch, patch.Bounds(), img, crop.Min, draw.Src)
+		img = patch
+	}
+
+	tmpl, err := NewTemplate(img, spec.X, spec.Y, spec.Search, spec.Threshold)
+	if err != nil {
+		return nil, fmt.Errorf("template image %s: %w", spec.Image, err)
+	}
+	return tmpl, nil
+}
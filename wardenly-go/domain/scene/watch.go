@@ -0,0 +1,63 @@
+package scene
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay coalesces bursts of file events; editors often write a file
+// several times when saving.
+const reloadDelay = 300 * time.Millisecond
+
+// WatchDir reloads dir with LoadDir whenever a scene file or template image
+// in it changes, until ctx is done. Call LoadDir first for the initial load. onReload runs
+// on the watcher goroutine after each reload with the scenes that were added
+// or changed and LoadDir's error.
+func (l *Loader) WatchDir(ctx context.Context, dir string, onReload func(edits []Edit, err error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var reload <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// Scene files and their template images
+				if isSceneFile(ev.Name) || filepath.Ext(ev.Name) == ".png" {
+					reload = time.After(reloadDelay)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				// Events may have been lost (e.g. queue overflow); rescan
+				reload = time.After(reloadDelay)
+			case <-reload:
+				reload = nil
+				edits, err := l.loadDir(dir)
+				if onReload != nil {
+					onReload(edits, err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package schedule
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the interface for schedule persistence operations.
+type Repository interface {
+	// FindByID retrieves a schedule by its unique identifier.
+	// Returns nil if not found.
+	FindByID(ctx context.Context, id string) (*Schedule, error)
+
+	// FindAll retrieves all schedules.
+	FindAll(ctx context.Context) ([]*Schedule, error)
+
+	// Insert creates a new schedule.
+	Insert(ctx context.Context, schedule *Schedule) error
+
+	// Update updates an existing schedule.
+	Update(ctx context.Context, schedule *Schedule) error
+
+	// UpdateLastRun records when a schedule last triggered.
+	UpdateLastRun(ctx context.Context, id string, at time.Time) error
+
+	// Delete removes a schedule by its identifier.
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,82 @@
+// Package schedule defines timed runs of a script on an account or group.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Common errors for schedule operations.
+var (
+	ErrScheduleNotFound = errors.New("schedule not found")
+	ErrInvalidSchedule  = errors.New("invalid schedule")
+)
+
+// TargetType identifies what a schedule runs on.
+type TargetType string
+
+const (
+	TargetAccount TargetType = "account"
+	TargetGroup   TargetType = "group"
+)
+
+// Schedule runs a script on an account or every account of a group at the
+// times described by Spec. Sessions started by a schedule stop themselves
+// once the script finishes.
+type Schedule struct {
+	// ID is the unique identifier (MongoDB ObjectID)
+	ID string
+
+	// Name is the display name of the schedule
+	Name string
+
+	// TargetType and TargetID select the account or group to run
+	TargetType TargetType
+	TargetID   string
+
+	// ScriptName is the script started once each session is ready
+	ScriptName string
+
+	// Spec is a cron expression ("30 4 * * 1-5") or a daily time ("daily 04:30")
+	Spec string
+
+	// Enabled schedules are picked up by the scheduler
+	Enabled bool
+
+	// LastRunAt is when the schedule last triggered (zero if never)
+	LastRunAt time.Time
+}
+
+// Validate checks that the schedule is complete and its spec parses.
+func (s *Schedule) Validate() error {
+	switch {
+	case s.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidSchedule)
+	case s.TargetType != TargetAccount && s.TargetType != TargetGroup:
+		return fmt.Errorf("%w: unknown target type %q", ErrInvalidSchedule, s.TargetType)
+	case s.TargetID == "":
+		return fmt.Errorf("%w: target is required", ErrInvalidSchedule)
+	case s.ScriptName == "":
+		return fmt.Errorf("%w: script is required", ErrInvalidSchedule)
+	}
+	if _, err := ParseSpec(s.Spec); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	return nil
+}
+
+// Next returns the first trigger time strictly after t.
+func (s *Schedule) Next(t time.Time) (time.Time, error) {
+	spec, err := ParseSpec(s.Spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return spec.Next(t), nil
+}
+
+// Clone creates a copy of the schedule.
+func (s *Schedule) Clone() *Schedule {
+	clone := *s
+	return &clone
+}
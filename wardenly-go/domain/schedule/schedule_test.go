@@ -0,0 +1,88 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseSpec_Invalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"daily 25:00",
+		"daily",
+	} {
+		if _, err := ParseSpec(s); err == nil {
+			t.Errorf("ParseSpec(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestSpec_Next(t *testing.T) {
+	// 2026-03-04 is a Wednesday
+	base := time.Date(2026, 3, 4, 10, 15, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 4, 10, 16, 0, 0, time.UTC)},
+		{"daily 04:30", time.Date(2026, 3, 5, 4, 30, 0, 0, time.UTC)},
+		{"daily 10:20", time.Date(2026, 3, 4, 10, 20, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2026, 3, 4, 10, 20, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2026, 3, 4, 13, 0, 0, 0, time.UTC)},
+		{"0 8 * * 1,5", time.Date(2026, 3, 6, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 7", time.Date(2026, 3, 8, 8, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches (the 10th or a Monday)
+		{"0 0 10 * 1", time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		spec, err := ParseSpec(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseSpec(%q) error = %v", tt.spec, err)
+		}
+		if got := spec.Next(base); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+
+	never, _ := ParseSpec("0 0 31 2 *")
+	if got := never.Next(base); !got.IsZero() {
+		t.Errorf("Next() for an impossible date = %v, want zero", got)
+	}
+}
+
+func TestSchedule_Validate(t *testing.T) {
+	valid := Schedule{
+		Name:       "Morning",
+		TargetType: TargetGroup,
+		TargetID:   "g1",
+		ScriptName: "daily",
+		Spec:       "daily 06:00",
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	for name, mutate := range map[string]func(*Schedule){
+		"name":   func(s *Schedule) { s.Name = "" },
+		"type":   func(s *Schedule) { s.TargetType = "server" },
+		"target": func(s *Schedule) { s.TargetID = "" },
+		"script": func(s *Schedule) { s.ScriptName = "" },
+		"spec":   func(s *Schedule) { s.Spec = "sometimes" },
+	} {
+		sch := valid
+		mutate(&sch)
+		if err := sch.Validate(); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("%s: Validate() error = %v, want ErrInvalidSchedule", name, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Service provides business logic for schedule management.
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a new schedule service.
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// GetSchedule retrieves a schedule by ID.
+func (s *Service) GetSchedule(ctx context.Context, id string) (*Schedule, error) {
+	sch, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sch == nil {
+		return nil, ErrScheduleNotFound
+	}
+	return sch, nil
+}
+
+// ListSchedules retrieves all schedules sorted by name then ID.
+func (s *Service) ListSchedules(ctx context.Context) ([]*Schedule, error) {
+	schedules, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].Name != schedules[j].Name {
+			return schedules[i].Name < schedules[j].Name
+		}
+		return schedules[i].ID < schedules[j].ID
+	})
+
+	return schedules, nil
+}
+
+// CreateSchedule validates and creates a new schedule.
+func (s *Service) CreateSchedule(ctx context.Context, sch *Schedule) error {
+	if err := sch.Validate(); err != nil {
+		return err
+	}
+	return s.repo.Insert(ctx, sch)
+}
+
+// UpdateSchedule validates and updates an existing schedule.
+func (s *Service) UpdateSchedule(ctx context.Context, sch *Schedule) error {
+	if err := sch.Validate(); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, sch)
+}
+
+// MarkRun records that a schedule triggered at the given time.
+func (s *Service) MarkRun(ctx context.Context, id string, at time.Time) error {
+	return s.repo.UpdateLastRun(ctx, id, at)
+}
+
+// DeleteSchedule removes a schedule.
+func (s *Service) DeleteSchedule(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
@@ -0,0 +1,156 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec is a parsed schedule expression with minute resolution.
+type Spec struct {
+	minute, hour, dom, month, dow uint64 // bit i set = value i allowed
+	domStar, dowStar              bool
+}
+
+// field describes the range of one cron field.
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = [5]field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7}, // 7 is an alias for Sunday
+}
+
+// ParseSpec parses a standard five-field cron expression
+// ("minute hour day-of-month month day-of-week", each field accepting
+// "*", numbers, ranges "a-b", lists "a,b" and steps "*/n" or "a-b/n")
+// or the shorthand "daily HH:MM".
+func ParseSpec(s string) (*Spec, error) {
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(s, "daily "); ok {
+		hm, err := time.Parse("15:04", strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid daily time %q, want HH:MM", rest)
+		}
+		s = fmt.Sprintf("%d %d * * *", hm.Minute(), hm.Hour())
+	}
+
+	parts := strings.Fields(s)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("expected 5 cron fields or \"daily HH:MM\", got %q", s)
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		b, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+
+	// Fold Sunday=7 into 0
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &Spec{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: parts[2] == "*",
+		dowStar: parts[4] == "*",
+	}, nil
+}
+
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		lo, hi := f.min, f.max
+		if rangePart != "*" {
+			a, b, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseValue(a, f); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = parseValue(b, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max // "a/n" means from a to the end
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid %s range %q", f.name, rangePart)
+			}
+		}
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", f.name, stepPart)
+			}
+			step = n
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, f field) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q (want %d-%d)", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time strictly after t (at minute resolution, in
+// t's location) that matches the spec. It returns the zero time if nothing
+// matches within five years, e.g. for "0 0 31 2 *".
+func (s *Spec) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's day rule: when both day fields are restricted,
+// either may match.
+func (s *Spec) dayMatches(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
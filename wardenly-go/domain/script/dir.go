@@ -0,0 +1,92 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// dirScript is a script loaded from a user scripts directory.
+type dirScript struct {
+	script *Script
+	source []byte
+}
+
+// LoadDir loads the YAML scripts in dir on top of the embedded ones and
+// brings the registry in line with the directory: a file replaces an embedded
+// script of the same name, and removing the file restores the embedded
+// version or unregisters the script. A file that fails to load keeps its
+// previously loaded version; all such errors are returned joined, along with
+// any unresolved calls or call cycles, which leave the scripts registered.
+func (l *Loader) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read scripts directory: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var paths []string // In file name order, so the last duplicate wins
+	loaded := make(map[string]dirScript)
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+
+		data, err := os.ReadFile(path)
+		var script *Script
+		if err == nil {
+			script, err = Parse(data)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("script file %s: %w", path, err))
+			prev, ok := l.dirFiles[path]
+			if !ok {
+				continue
+			}
+			loaded[path] = prev
+		} else {
+			loaded[path] = dirScript{script: script, source: data}
+		}
+		paths = append(paths, path)
+	}
+
+	// Scripts whose file was removed or now defines another name
+	for path, prev := range l.dirFiles {
+		if cur, ok := loaded[path]; ok && cur.script.Name == prev.script.Name {
+			continue
+		}
+		l.restoreLocked(prev.script.Name)
+	}
+
+	for _, path := range paths {
+		ds := loaded[path]
+		l.registry.Register(ds.script)
+		l.sources[ds.script.Name] = ds.source
+	}
+	l.dirFiles = loaded
+
+	// Calls resolve across files, so check them once everything is registered
+	if err := l.registry.CheckCalls(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// restoreLocked registers the embedded version of a script, or unregisters
+// it when there is none. Caller must hold mu.
+func (l *Loader) restoreLocked(name string) {
+	if data, ok := l.embedded[name]; ok {
+		if script, err := Parse(data); err == nil {
+			l.registry.Register(script)
+			l.sources[name] = data
+			return
+		}
+	}
+	l.registry.Unregister(name)
+	delete(l.sources, name)
+}
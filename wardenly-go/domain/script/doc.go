@@ -0,0 +1,364 @@
+package script
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Markdown describes the script for operators: what it is for, the scenes
+// and scripts it depends on, its parameters and limits, and what each step
+// does. The description is derived from the script itself, so it is never
+// out of date.
+func (s *Script) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", s.Name)
+	if s.Description != "" {
+		b.WriteString(strings.TrimSpace(s.Description) + "\n\n")
+	}
+	var meta []string
+	if s.Version != "" {
+		meta = append(meta, "Version "+s.Version)
+	}
+	if s.Author != "" {
+		meta = append(meta, "by "+s.Author)
+	}
+	if len(meta) > 0 {
+		b.WriteString("*" + strings.Join(meta, ", ") + "*\n\n")
+	}
+
+	b.WriteString("## Summary\n\n")
+	fmt.Fprintf(&b, "- **Steps:** %d\n", len(s.Steps))
+	fmt.Fprintf(&b, "- **Scenes:** %s\n", codeList(s.Scenes(), "none"))
+	if categories := s.SceneCategories(); len(categories) > 0 {
+		fmt.Fprintf(&b, "- **Scene categories:** %s\n", codeList(categories, ""))
+	}
+	if calls := s.Calls(); len(calls) > 0 {
+		fmt.Fprintf(&b, "- **Calls:** %s\n", codeList(calls, ""))
+	}
+	fmt.Fprintf(&b, "- **OCR:** %s\n", s.ocrSummary())
+	fmt.Fprintf(&b, "- **Loops:** %s\n", s.loopSummary())
+	if len(s.ExclusionGroups) > 0 {
+		fmt.Fprintf(&b, "- **Exclusion groups:** %s (never runs alongside scripts sharing one on the same account)\n",
+			codeList(s.ExclusionGroups, ""))
+	}
+
+	b.WriteString("\n## Limits\n\n")
+	for _, line := range s.limitLines() {
+		b.WriteString("- " + line + "\n")
+	}
+
+	if len(s.Prompts) > 0 {
+		b.WriteString("\n## Parameters\n\n")
+		b.WriteString("Asked for when the script starts; the account's remembered values are offered first.\n\n")
+		b.WriteString("| Key | Type | Question | Default |\n|---|---|---|---|\n")
+		for _, p := range s.Prompts {
+			typ := p.Type
+			if typ == "" {
+				typ = PromptTypeString
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", p.Key, typ, tableCell(p.Label), tableCell(p.Default))
+		}
+	}
+
+	b.WriteString("\n## Steps\n\n")
+	b.WriteString("Steps run whenever their scene appears, the first matching step winning, unless a step waits for a labeled one.\n")
+	for i, step := range s.Steps {
+		s.writeStep(&b, i, &step)
+	}
+
+	if len(s.OnStop) > 0 {
+		b.WriteString("\n## On Stop\n\n")
+		b.WriteString("Run whenever the script stops, unless the browser is gone, to leave the game on a safe screen.\n\n")
+		for _, action := range s.OnStop {
+			fmt.Fprintf(&b, "- %s\n", describeAction(&action))
+		}
+	}
+	return b.String()
+}
+
+func (s *Script) writeStep(b *strings.Builder, i int, step *Step) {
+	if step.SceneCategory != "" {
+		fmt.Fprintf(b, "\n### %d. On any `%s` scene", i+1, step.SceneCategory)
+	} else {
+		fmt.Fprintf(b, "\n### %d. On `%s`", i+1, step.ExpectedScene)
+	}
+	if step.Label != "" {
+		fmt.Fprintf(b, " (label `%s`)", step.Label)
+	}
+	b.WriteString("\n\n")
+
+	if step.OCRRule != nil {
+		b.WriteString("- " + describeOCRRule(step.OCRRule) + "\n")
+	}
+	if len(step.Actions) == 0 {
+		b.WriteString("- No actions\n")
+	}
+	for j, action := range step.Actions {
+		fmt.Fprintf(b, "- %s\n", describeAction(&action))
+		if loop := step.Loop; loop != nil && j == loop.EndIndex {
+			fmt.Fprintf(b, "  - %s\n", describeLoop(loop))
+		}
+	}
+
+	if step.OnMatch != "" {
+		fmt.Fprintf(b, "- Then waits for step `%s`\n", step.OnMatch)
+	}
+	if step.Timeout > 0 {
+		fallback := "the script stops with an error"
+		switch {
+		case step.OnTimeout != "":
+			fallback = fmt.Sprintf("it waits for step `%s` instead", step.OnTimeout)
+		case step.ContinueOnFailure:
+			fallback = "matching continues with all steps"
+		}
+		fmt.Fprintf(b, "- When waited for, the scene must appear within %s; otherwise %s\n", step.Timeout, fallback)
+	}
+}
+
+func (s *Script) ocrSummary() string {
+	var rules []string
+	for i, step := range s.Steps {
+		if step.OCRRule != nil {
+			rules = append(rules, fmt.Sprintf("`%s` in step %d", step.OCRRule.Name, i+1))
+		}
+	}
+	if len(rules) == 0 {
+		return "not used"
+	}
+	return strings.Join(rules, ", ") + " (needs the OCR service)"
+}
+
+func (s *Script) loopSummary() string {
+	count, infinite := 0, 0
+	for _, step := range s.Steps {
+		if step.Loop == nil {
+			continue
+		}
+		count++
+		if step.Loop.Count <= 0 && !step.Loop.HasUntilCondition() {
+			infinite++
+		}
+	}
+	switch {
+	case count == 0:
+		return "none"
+	case infinite > 0:
+		return fmt.Sprintf("%d, %d repeating until the script stops", count, infinite)
+	default:
+		return fmt.Sprint(count)
+	}
+}
+
+func (s *Script) limitLines() []string {
+	var lines []string
+	if s.Timeout > 0 {
+		lines = append(lines, fmt.Sprintf("Stops with an error after %s", s.Timeout))
+	} else {
+		lines = append(lines, "Runs until it quits or is stopped")
+	}
+	for _, limit := range []struct {
+		n    int
+		what string
+	}{{s.Limits.ClicksPerMinute, "clicks"}, {s.Limits.DragsPerMinute, "drags"}} {
+		if limit.n > 0 {
+			lines = append(lines, fmt.Sprintf("At most %d %s per minute; the run pauses when it reaches the limit", limit.n, limit.what))
+		}
+	}
+	if s.Jitter.Pixels > 0 {
+		lines = append(lines, fmt.Sprintf("Clicks and drags land up to %g px from their point", s.Jitter.Pixels))
+	}
+	if s.Jitter.Wait > 0 {
+		lines = append(lines, fmt.Sprintf("Waits vary by up to %g%%", s.Jitter.Wait*100))
+	}
+	if s.LagSlowdown > 0 {
+		lines = append(lines, fmt.Sprintf("Waits and loop intervals are %gx longer while the session lags", s.LagSlowdown))
+	}
+	if s.MatchMargin > 0 {
+		lines = append(lines, fmt.Sprintf("A step matches only if its scene is %g closer than any other", s.MatchMargin))
+	}
+	return lines
+}
+
+// Describe says what the action does in one line, as in the script's
+// documentation.
+func (a *Action) Describe() string {
+	return describeAction(a)
+}
+
+// describeAction says what an action does in one line.
+func describeAction(a *Action) string {
+	switch a.Type {
+	case ActionTypeClick:
+		return "Click " + describeTarget(a)
+	case ActionTypeDrag:
+		if len(a.Points) >= 2 {
+			return fmt.Sprintf("Drag from %s to %s", formatPoint(a.Points[0]), formatPoint(a.Points[1]))
+		}
+		return "Drag"
+	case ActionTypeWait:
+		return "Wait " + a.Duration.String()
+	case ActionTypeScroll:
+		return fmt.Sprintf("Scroll by (%g, %g) %s", a.DeltaX, a.DeltaY, describeTarget(a))
+	case ActionTypeSendKeys:
+		text := fmt.Sprintf("Type %q", a.Text)
+		if a.Selector != "" {
+			return text + fmt.Sprintf(" into `%s`", a.Selector)
+		}
+		if a.Region != nil || len(a.Points) > 0 {
+			return text + " after clicking " + describeTarget(a)
+		}
+		return text
+	case ActionTypeIncr:
+		return fmt.Sprintf("Add 1 to `%s`", a.Key)
+	case ActionTypeDecr:
+		return fmt.Sprintf("Subtract 1 from `%s`", a.Key)
+	case ActionTypeSet:
+		return fmt.Sprintf("Set `%s` to `%s`", a.Key, a.Value)
+	case ActionTypeAdd:
+		return fmt.Sprintf("Add `%s` to `%s`", a.Value, a.Key)
+	case ActionTypeQuit:
+		if a.Condition == nil {
+			return "Quit the script"
+		}
+		return fmt.Sprintf("Quit the script if `%s`", describeCondition(a.Condition))
+	case ActionTypeCheckScene:
+		return "Check the step's OCR rule"
+	case ActionTypeCall:
+		return fmt.Sprintf("Run the steps of `%s`", a.Script)
+	case ActionTypeClickSelector:
+		return fmt.Sprintf("Click the page element `%s`", a.Selector)
+	case ActionTypeWaitSelector:
+		if a.Duration > 0 {
+			return fmt.Sprintf("Wait up to %s for `%s` to show", a.Duration, a.Selector)
+		}
+		return fmt.Sprintf("Wait for `%s` to show", a.Selector)
+	case ActionTypeEvaluate:
+		if a.Key != "" {
+			return fmt.Sprintf("Evaluate `%s` into `%s`", a.Expression, a.Key)
+		}
+		return fmt.Sprintf("Evaluate `%s`", a.Expression)
+	default:
+		return string(a.Type)
+	}
+}
+
+// describeTarget names where a click or scroll lands.
+func describeTarget(a *Action) string {
+	if r := a.Region; r != nil {
+		text := fmt.Sprintf("in the %gx%g area at %s", r.Width, r.Height, formatPoint(Point{X: r.X, Y: r.Y}))
+		if r.Distribution == DistributionCenter {
+			text += ", favoring its center"
+		}
+		return text
+	}
+	if len(a.Points) > 0 {
+		return "at " + formatPoint(a.Points[0])
+	}
+	return "at the current position"
+}
+
+func describeCondition(c *Condition) string {
+	if c.Expr != nil {
+		return c.Expr.String()
+	}
+	ops := map[string]string{"eq": "==", "neq": "!=", "gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+	op, ok := ops[c.Op]
+	if !ok {
+		op = c.Op
+	}
+	return fmt.Sprintf("%s %s %d", c.Key, op, c.Value)
+}
+
+func describeLoop(l *Loop) string {
+	text := fmt.Sprintf("Repeats actions %d-%d", l.StartIndex+1, l.EndIndex+1)
+	// Only a positive count bounds the loop
+	if l.Count > 0 {
+		text += fmt.Sprintf(" up to %d times", l.Count)
+	}
+	if l.HasUntilCondition() {
+		text += fmt.Sprintf(" until `%s` appears", l.Until)
+	} else if l.Count <= 0 {
+		text += " until the script stops"
+	}
+	if l.Interval > 0 {
+		text += ", " + l.Interval.String() + " apart"
+	}
+	return text
+}
+
+// patternVars returns the names of the pattern's named groups.
+func patternVars(pattern *regexp.Regexp) []string {
+	var names []string
+	for _, name := range pattern.SubexpNames() {
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func describeOCRRule(r *OCRRule) string {
+	var text string
+	switch r.Name {
+	case OCRRuleQuitWhenExhausted:
+		text = fmt.Sprintf("At check_scene, reads an `a/b` counter and stops the script as exhausted when b exceeds a or %d", r.Threshold)
+	case OCRRuleMatchText:
+		expected := make([]string, len(r.Expected))
+		for i, e := range r.Expected {
+			expected[i] = fmt.Sprintf("%q", e)
+		}
+		text = "At check_scene, skips the rest of the step unless the text shows " + strings.Join(expected, " or ")
+		if r.Tolerance > 0 {
+			text += fmt.Sprintf(", allowing %d misread character(s)", r.Tolerance)
+		}
+	case OCRRuleReadNumber:
+		limit := strconv.Itoa(r.Threshold)
+		if r.Against != "" {
+			limit = "`" + r.Against + "`"
+		}
+		ops := map[string]string{CompareLess: "is below", CompareGreater: "is above", CompareEqual: "equals"}
+		text = fmt.Sprintf("At check_scene, reads a number and stops the script as exhausted when it %s %s", ops[r.Compare], limit)
+		if r.Key != "" {
+			text += fmt.Sprintf(", keeping it in `%s`", r.Key)
+		}
+	case OCRRuleMatchPattern:
+		text = fmt.Sprintf("At check_scene, skips the rest of the step unless the text matches `%s`", r.Pattern)
+		if names := patternVars(r.Pattern); len(names) > 0 {
+			text += ", setting " + codeList(names, "")
+		}
+	default:
+		text = fmt.Sprintf("OCR rule `%s`", r.Name)
+	}
+	text += fmt.Sprintf(" (reads %dx%d at (%d, %d)", r.ROI.Width, r.ROI.Height, r.ROI.X, r.ROI.Y)
+	if n := len(r.FallbackROIs); n > 0 {
+		text += fmt.Sprintf(", %d fallback area(s)", n)
+	}
+	return text + ")"
+}
+
+func formatPoint(p Point) string {
+	return fmt.Sprintf("(%g, %g)", p.X, p.Y)
+}
+
+// codeList formats names as inline code, or returns none if there are none.
+func codeList(names []string, none string) string {
+	if len(names) == 0 {
+		return none
+	}
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "`" + name + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// tableCell escapes a value for a Markdown table; empty cells show a dash.
+func tableCell(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
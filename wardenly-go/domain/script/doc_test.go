@@ -0,0 +1,74 @@
+package script
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScript_Markdown(t *testing.T) {
+	s, err := Parse([]byte(`name: farm
+description: Farms the tower until energy runs out.
+version: "2"
+author: ops
+timeout: 30m
+limits:
+  clicksPerMinute: 40
+prompts:
+  - key: floors
+    type: int
+    label: Floors to climb
+    default: "3"
+steps:
+  - scene: tower
+    label: climb
+    ocrRule:
+      name: quit_when_exhausted
+      roi: {x: 10, y: 20, width: 50, height: 12}
+      threshold: 5
+    actions:
+      - type: check_scene
+      - type: click
+        points: [{x: 100, y: 200}]
+      - type: wait
+        duration: 2s
+    loop:
+      startIndex: 1
+      endIndex: 2
+      until: tower_top
+    onMatch: climb
+  - scene: main_city
+    timeout: 5s
+    actions:
+      - type: quit
+        condition: "floors <= 0"
+onStop:
+  - type: click
+    points: [{x: 900, y: 40}]
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	doc := s.Markdown()
+	for _, want := range []string{
+		"# farm",
+		"Farms the tower until energy runs out.",
+		"*Version 2, by ops*",
+		"- **Scenes:** `tower`, `tower_top`, `main_city`",
+		"`quit_when_exhausted` in step 1",
+		"Stops with an error after 30m0s",
+		"At most 40 clicks per minute",
+		"| `floors` | int | Floors to climb | 3 |",
+		"### 1. On `tower` (label `climb`)",
+		"- Click at (100, 200)",
+		"  - Repeats actions 2-3 until `tower_top` appears",
+		"- Then waits for step `climb`",
+		"- Quit the script if `floors <= 0`",
+		"within 5s; otherwise the script stops with an error",
+		"## On Stop",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("Markdown() lacks %q:\n%s", want, doc)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrDivisionByZero is returned when an expression divides by zero.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// Expr is a parsed integer expression over script variables, used by
+// conditions and the set/add actions. It supports integer literals,
+// variable names, parentheses, the arithmetic operators + - * / %, the
+// comparisons == != < <= > >=, and the logical operators && || !.
+// Comparisons and logical operators yield 1 or 0; unset variables are 0.
+type Expr struct {
+	src  string
+	root exprNode
+}
+
+// ParseExpr parses an expression such as "energy < 10 && runs >= 3".
+func ParseExpr(src string) (*Expr, error) {
+	tokens, err := tokenizeExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", src, err)
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", src, err)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression against vars.
+func (e *Expr) Eval(vars map[string]int) (int, error) {
+	return e.root.eval(vars)
+}
+
+// Bool evaluates the expression and reports whether the result is non-zero.
+func (e *Expr) Bool(vars map[string]int) (bool, error) {
+	v, err := e.Eval(vars)
+	return v != 0, err
+}
+
+// Vars returns the names of the variables the expression reads, in order
+// of first use.
+func (e *Expr) Vars() []string {
+	var names []string
+	var walk func(exprNode)
+	walk = func(n exprNode) {
+		switch n := n.(type) {
+		case exprVar:
+			if !slices.Contains(names, string(n)) {
+				names = append(names, string(n))
+			}
+		case exprUnary:
+			walk(n.operand)
+		case exprBinary:
+			walk(n.left)
+			walk(n.right)
+		}
+	}
+	walk(e.root)
+	return names
+}
+
+// Expression tokens.
+
+type exprTokenKind int
+
+const (
+	tokenNumber exprTokenKind = iota
+	tokenIdent
+	tokenOp
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	text string
+}
+
+// exprOps lists the operators, two-character ones first.
+var exprOps = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")"}
+
+func tokenizeExpr(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	rest := src
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			break
+		}
+
+		r := []rune(rest)[0]
+		switch {
+		case unicode.IsDigit(r):
+			n := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsDigit(r) })
+			if n < 0 {
+				n = len(rest)
+			}
+			tokens = append(tokens, exprToken{tokenNumber, rest[:n]})
+			rest = rest[n:]
+		case r == '_' || unicode.IsLetter(r):
+			n := strings.IndexFunc(rest, func(r rune) bool { return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+			if n < 0 {
+				n = len(rest)
+			}
+			tokens = append(tokens, exprToken{tokenIdent, rest[:n]})
+			rest = rest[n:]
+		default:
+			op := ""
+			for _, candidate := range exprOps {
+				if strings.HasPrefix(rest, candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q", r)
+			}
+			tokens = append(tokens, exprToken{tokenOp, op})
+			rest = rest[len(op):]
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+	return tokens, nil
+}
+
+// exprParser is a recursive descent parser. Precedence from lowest:
+// ||, &&, comparisons, + -, * / %, unary ! -.
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) peekOp(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// parseBinary parses a left-associative chain of ops over next.
+func (p *exprParser) parseBinary(next func() (exprNode, error), ops ...string) (exprNode, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.peekOp(ops...)
+		if !ok {
+			return left, nil
+		}
+		p.pos++
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = exprBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	return p.parseBinary(p.parseSum, "==", "!=", "<=", ">=", "<", ">")
+}
+
+func (p *exprParser) parseSum() (exprNode, error) {
+	return p.parseBinary(p.parseProduct, "+", "-")
+}
+
+func (p *exprParser) parseProduct() (exprNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if op, ok := p.peekOp("!", "-"); ok {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return exprUnary{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("unexpected end of expression")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	switch tok.kind {
+	case tokenNumber:
+		v, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.text)
+		}
+		return exprNumber(v), nil
+	case tokenIdent:
+		return exprVar(tok.text), nil
+	}
+
+	if tok.text == "(" {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.peekOp(")"); !ok {
+			return nil, errors.New("missing )")
+		}
+		p.pos++
+		return inner, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", tok.text)
+}
+
+// Expression nodes.
+
+type exprNode interface {
+	eval(vars map[string]int) (int, error)
+}
+
+type exprNumber int
+
+func (n exprNumber) eval(map[string]int) (int, error) { return int(n), nil }
+
+type exprVar string
+
+func (v exprVar) eval(vars map[string]int) (int, error) { return vars[string(v)], nil }
+
+type exprUnary struct {
+	op      string
+	operand exprNode
+}
+
+func (u exprUnary) eval(vars map[string]int) (int, error) {
+	v, err := u.operand.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	if u.op == "!" {
+		return boolInt(v == 0), nil
+	}
+	return -v, nil
+}
+
+type exprBinary struct {
+	op          string
+	left, right exprNode
+}
+
+func (b exprBinary) eval(vars map[string]int) (int, error) {
+	l, err := b.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	// Short-circuit the logical operators
+	switch {
+	case b.op == "&&" && l == 0:
+		return 0, nil
+	case b.op == "||" && l != 0:
+		return 1, nil
+	}
+	r, err := b.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	switch b.op {
+	case "&&", "||":
+		return boolInt(r != 0), nil
+	case "==":
+		return boolInt(l == r), nil
+	case "!=":
+		return boolInt(l != r), nil
+	case "<":
+		return boolInt(l < r), nil
+	case "<=":
+		return boolInt(l <= r), nil
+	case ">":
+		return boolInt(l > r), nil
+	case ">=":
+		return boolInt(l >= r), nil
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/", "%":
+		if r == 0 {
+			return 0, ErrDivisionByZero
+		}
+		if b.op == "/" {
+			return l / r, nil
+		}
+		return l % r, nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", b.op)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -11,20 +14,50 @@ import (
 
 // yamlScript is the YAML structure for script definitions.
 type yamlScript struct {
-	Name        string     `yaml:"name"`
-	Description string     `yaml:"description"`
-	Version     string     `yaml:"version"`
-	Author      string     `yaml:"author"`
-	Steps       []yamlStep `yaml:"steps"`
+	Name        string       `yaml:"name"`
+	Description string       `yaml:"description"`
+	Version     string       `yaml:"version"`
+	Author      string       `yaml:"author"`
+	Steps       []yamlStep   `yaml:"steps"`
+	Prompts     []yamlPrompt `yaml:"prompts,omitempty"`
+
+	ExclusionGroups []string     `yaml:"exclusionGroups,omitempty"`
+	Jitter          *yamlJitter  `yaml:"jitter,omitempty"`
+	LagSlowdown     float64      `yaml:"lagSlowdown,omitempty"`
+	MatchMargin     float64      `yaml:"matchMargin,omitempty"`
+	Timeout         duration     `yaml:"timeout,omitempty"`
+	Limits          *yamlLimits  `yaml:"limits,omitempty"`
+	OnStop          []yamlAction `yaml:"onStop,omitempty"`
+}
+
+type yamlJitter struct {
+	Pixels float64 `yaml:"pixels"`
+	Wait   float64 `yaml:"wait"`
+}
+
+type yamlLimits struct {
+	ClicksPerMinute int `yaml:"clicksPerMinute"`
+	DragsPerMinute  int `yaml:"dragsPerMinute"`
+}
+
+type yamlPrompt struct {
+	Key     string `yaml:"key"`
+	Type    string `yaml:"type"`
+	Label   string `yaml:"label"`
+	Default string `yaml:"default,omitempty"`
 }
 
 type yamlStep struct {
+	Label             string       `yaml:"label,omitempty"`
 	Scene             string       `yaml:"scene"`
+	SceneCategory     string       `yaml:"sceneCategory,omitempty"`
 	Timeout           duration     `yaml:"timeout"`
 	Actions           []yamlAction `yaml:"actions"`
 	ContinueOnFailure bool         `yaml:"continueOnFailure"`
 	Loop              *yamlLoop    `yaml:"loop,omitempty"`
 	OCRRule           *yamlOCRRule `yaml:"ocrRule,omitempty"`
+	OnMatch           string       `yaml:"onMatch,omitempty"`   // "goto <label>"
+	OnTimeout         string       `yaml:"onTimeout,omitempty"` // "goto <label>"
 }
 
 type yamlAction struct {
@@ -33,7 +66,24 @@ type yamlAction struct {
 	Duration   duration       `yaml:"duration,omitempty"`
 	RetryCount int            `yaml:"retryCount,omitempty"`
 	Key        string         `yaml:"key,omitempty"`
+	Value      string         `yaml:"value,omitempty"` // Expression for set/add
+	Jitter     *float64       `yaml:"jitter,omitempty"`
 	Condition  *yamlCondition `yaml:"condition,omitempty"`
+	Region     *yamlRegion    `yaml:"region,omitempty"`
+	Call       string         `yaml:"call,omitempty"` // Script name; implies type call
+	Text       string         `yaml:"text,omitempty"`
+	Selector   string         `yaml:"selector,omitempty"`
+	DeltaX     float64        `yaml:"deltaX,omitempty"`
+	DeltaY     float64        `yaml:"deltaY,omitempty"`
+	Expression string         `yaml:"expression,omitempty"`
+}
+
+type yamlRegion struct {
+	X            float64 `yaml:"x"`
+	Y            float64 `yaml:"y"`
+	Width        float64 `yaml:"width"`
+	Height       float64 `yaml:"height"`
+	Distribution string  `yaml:"distribution,omitempty"`
 }
 
 type yamlPoint struct {
@@ -45,6 +95,17 @@ type yamlCondition struct {
 	Op    string `yaml:"op"`
 	Key   string `yaml:"key"`
 	Value int    `yaml:"value"`
+
+	Expr string `yaml:"-"` // Set when the condition is written as an expression
+}
+
+// UnmarshalYAML accepts either an expression string or an op/key/value map.
+func (c *yamlCondition) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&c.Expr)
+	}
+	type plain yamlCondition
+	return value.Decode((*plain)(c))
 }
 
 type yamlLoop struct {
@@ -56,9 +117,19 @@ type yamlLoop struct {
 }
 
 type yamlOCRRule struct {
-	Name      string  `yaml:"name"`
-	ROI       yamlROI `yaml:"roi"`
-	Threshold int     `yaml:"threshold"`
+	Name          string    `yaml:"name"`
+	ROI           yamlROI   `yaml:"roi"`
+	FallbackROIs  []yamlROI `yaml:"fallbackRois,omitempty"`
+	MinConfidence float64   `yaml:"minConfidence,omitempty"`
+	Threshold     int       `yaml:"threshold"`
+	Expected      []string  `yaml:"expected,omitempty"`
+	Tolerance     int       `yaml:"tolerance,omitempty"`
+	Pattern       string    `yaml:"pattern,omitempty"`
+	Compare       string    `yaml:"compare,omitempty"`
+	Against       string    `yaml:"against,omitempty"`
+	Key           string    `yaml:"key,omitempty"`
+	Language      string    `yaml:"language,omitempty"`
+	Charset       string    `yaml:"charset,omitempty"`
 }
 
 type yamlROI struct {
@@ -87,15 +158,26 @@ func (d *duration) UnmarshalYAML(value *yaml.Node) error {
 // Loader handles loading script definitions from various sources.
 type Loader struct {
 	registry *Registry
+
+	mu       sync.Mutex
+	sources  map[string][]byte    // Active YAML per script name
+	embedded map[string][]byte    // YAML loaded by LoadFromFS, restored when an override goes away
+	dirFiles map[string]dirScript // Scripts loaded by LoadDir, keyed by file path
 }
 
 // NewLoader creates a new script loader that populates the given registry.
 func NewLoader(registry *Registry) *Loader {
-	return &Loader{registry: registry}
+	return &Loader{
+		registry: registry,
+		sources:  make(map[string][]byte),
+		embedded: make(map[string][]byte),
+		dirFiles: make(map[string]dirScript),
+	}
 }
 
 // LoadFromFS loads script definitions from an embedded or real filesystem.
-// It expects YAML files in a "scripts" subdirectory.
+// It expects YAML files in a "scripts" subdirectory. Every call action must
+// name a loaded script, without cycles.
 func (l *Loader) LoadFromFS(fsys fs.FS) error {
 	entries, err := fs.ReadDir(fsys, "scripts")
 	if err != nil {
@@ -112,7 +194,7 @@ func (l *Loader) LoadFromFS(fsys fs.FS) error {
 		}
 	}
 
-	return nil
+	return l.registry.CheckCalls()
 }
 
 // loadFile loads a single script definition file.
@@ -122,44 +204,115 @@ func (l *Loader) loadFile(fsys fs.FS, path string) error {
 		return fmt.Errorf("failed to read script file %s: %w", path, err)
 	}
 
+	script, err := Parse(data)
+	if err != nil {
+		return fmt.Errorf("script file %s: %w", path, err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.registry.Register(script)
+	l.sources[script.Name] = data
+	l.embedded[script.Name] = data
+
+	return nil
+}
+
+// Sources returns the raw YAML content of every loaded script, keyed by name.
+func (l *Loader) Sources() map[string][]byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sources := make(map[string][]byte, len(l.sources))
+	for name, data := range l.sources {
+		sources[name] = data
+	}
+	return sources
+}
+
+// Parse parses and validates a single YAML script definition.
+func Parse(data []byte) (*Script, error) {
 	var ys yamlScript
 	if err := yaml.Unmarshal(data, &ys); err != nil {
-		return fmt.Errorf("failed to parse script file %s: %w", path, err)
+		return nil, fmt.Errorf("failed to parse script: %w", err)
 	}
 
-	script := convertYAMLScript(&ys)
-	l.registry.Register(script)
+	script, err := convertYAMLScript(&ys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
+	if err := script.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
 
-	return nil
+	return script, nil
 }
 
 // convertYAMLScript converts a YAML script to a domain Script.
-func convertYAMLScript(ys *yamlScript) *Script {
+func convertYAMLScript(ys *yamlScript) (*Script, error) {
 	script := &Script{
 		Name:        ys.Name,
 		Description: ys.Description,
 		Version:     ys.Version,
 		Author:      ys.Author,
 		Steps:       make([]Step, len(ys.Steps)),
+
+		ExclusionGroups: ys.ExclusionGroups,
+		LagSlowdown:     ys.LagSlowdown,
+		MatchMargin:     ys.MatchMargin,
+		Timeout:         time.Duration(ys.Timeout),
+	}
+	if ys.Jitter != nil {
+		script.Jitter = Jitter{Pixels: ys.Jitter.Pixels, Wait: ys.Jitter.Wait}
+	}
+	if ys.Limits != nil {
+		script.Limits = Limits{ClicksPerMinute: ys.Limits.ClicksPerMinute, DragsPerMinute: ys.Limits.DragsPerMinute}
 	}
 
 	for i, ystep := range ys.Steps {
-		script.Steps[i] = convertYAMLStep(&ystep)
+		step, err := convertYAMLStep(&ystep)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i, err)
+		}
+		script.Steps[i] = step
+	}
+
+	for i, ya := range ys.OnStop {
+		action, err := convertYAMLAction(&ya)
+		if err != nil {
+			return nil, fmt.Errorf("onStop action %d: %w", i, err)
+		}
+		script.OnStop = append(script.OnStop, action)
+	}
+
+	for _, yp := range ys.Prompts {
+		script.Prompts = append(script.Prompts, Prompt{
+			Key:     yp.Key,
+			Type:    PromptType(yp.Type),
+			Label:   yp.Label,
+			Default: yp.Default,
+		})
 	}
 
-	return script
+	return script, nil
 }
 
-func convertYAMLStep(ys *yamlStep) Step {
+func convertYAMLStep(ys *yamlStep) (Step, error) {
 	step := Step{
+		Label:             ys.Label,
 		ExpectedScene:     ys.Scene,
+		SceneCategory:     ys.SceneCategory,
 		Timeout:           time.Duration(ys.Timeout),
+		OnMatch:           gotoLabel(ys.OnMatch),
+		OnTimeout:         gotoLabel(ys.OnTimeout),
 		ContinueOnFailure: ys.ContinueOnFailure,
 		Actions:           make([]Action, len(ys.Actions)),
 	}
 
 	for i, ya := range ys.Actions {
-		step.Actions[i] = convertYAMLAction(&ya)
+		action, err := convertYAMLAction(&ya)
+		if err != nil {
+			return Step{}, fmt.Errorf("action %d: %w", i, err)
+		}
+		step.Actions[i] = action
 	}
 
 	if ys.Loop != nil {
@@ -174,40 +327,103 @@ func convertYAMLStep(ys *yamlStep) Step {
 
 	if ys.OCRRule != nil {
 		step.OCRRule = &OCRRule{
-			Name:      ys.OCRRule.Name,
-			Threshold: ys.OCRRule.Threshold,
-			ROI: ROI{
-				X:      ys.OCRRule.ROI.X,
-				Y:      ys.OCRRule.ROI.Y,
-				Width:  ys.OCRRule.ROI.Width,
-				Height: ys.OCRRule.ROI.Height,
-			},
+			Name:          ys.OCRRule.Name,
+			Threshold:     ys.OCRRule.Threshold,
+			MinConfidence: ys.OCRRule.MinConfidence,
+			ROI:           convertYAMLROI(ys.OCRRule.ROI),
+			Expected:      ys.OCRRule.Expected,
+			Tolerance:     ys.OCRRule.Tolerance,
+			Compare:       ys.OCRRule.Compare,
+			Against:       ys.OCRRule.Against,
+			Key:           ys.OCRRule.Key,
+			Language:      ys.OCRRule.Language,
+			Charset:       ys.OCRRule.Charset,
+		}
+		for _, yr := range ys.OCRRule.FallbackROIs {
+			step.OCRRule.FallbackROIs = append(step.OCRRule.FallbackROIs, convertYAMLROI(yr))
+		}
+		if ys.OCRRule.Pattern != "" {
+			pattern, err := regexp.Compile(ys.OCRRule.Pattern)
+			if err != nil {
+				return Step{}, fmt.Errorf("ocrRule pattern: %w", err)
+			}
+			step.OCRRule.Pattern = pattern
 		}
 	}
 
-	return step
+	return step, nil
 }
 
-func convertYAMLAction(ya *yamlAction) Action {
+// gotoLabel returns the label of a "goto <label>" branch.
+func gotoLabel(branch string) string {
+	label, _ := strings.CutPrefix(strings.TrimSpace(branch), "goto ")
+	return strings.TrimSpace(label)
+}
+
+func convertYAMLROI(yr yamlROI) ROI {
+	return ROI{
+		X:      yr.X,
+		Y:      yr.Y,
+		Width:  yr.Width,
+		Height: yr.Height,
+	}
+}
+
+func convertYAMLAction(ya *yamlAction) (Action, error) {
 	action := Action{
 		Type:       ActionType(ya.Type),
 		Duration:   time.Duration(ya.Duration),
 		RetryCount: ya.RetryCount,
 		Key:        ya.Key,
 		Points:     make([]Point, len(ya.Points)),
+		Jitter:     ya.Jitter,
+		Text:       ya.Text,
+		Selector:   ya.Selector,
+		DeltaX:     ya.DeltaX,
+		DeltaY:     ya.DeltaY,
+		Expression: ya.Expression,
+	}
+	if ya.Call != "" {
+		action.Type = ActionTypeCall
+		action.Script = ya.Call
 	}
 
 	for i, yp := range ya.Points {
 		action.Points[i] = Point{X: yp.X, Y: yp.Y}
 	}
 
+	if ya.Region != nil {
+		action.Region = &Region{
+			X:            ya.Region.X,
+			Y:            ya.Region.Y,
+			Width:        ya.Region.Width,
+			Height:       ya.Region.Height,
+			Distribution: Distribution(ya.Region.Distribution),
+		}
+	}
+
+	if ya.Value != "" {
+		expr, err := ParseExpr(ya.Value)
+		if err != nil {
+			return Action{}, err
+		}
+		action.Value = expr
+	}
+
 	if ya.Condition != nil {
 		action.Condition = &Condition{
 			Op:    ya.Condition.Op,
 			Key:   ya.Condition.Key,
 			Value: ya.Condition.Value,
 		}
+		if ya.Condition.Expr != "" {
+			expr, err := ParseExpr(ya.Condition.Expr)
+			if err != nil {
+				return Action{}, fmt.Errorf("condition: %w", err)
+			}
+			action.Condition.Expr = expr
+		}
 	}
 
-	return action
+	return action, nil
 }
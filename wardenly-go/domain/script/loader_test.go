@@ -0,0 +1,70 @@
+package script
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoader_LoadDir(t *testing.T) {
+	registry := NewRegistry()
+	loader := NewLoader(registry)
+	embedded := fstest.MapFS{
+		"scripts/daily.yaml": {Data: []byte("name: daily\ndescription: embedded\n")},
+	}
+	if err := loader.LoadFromFS(embedded); err != nil {
+		t.Fatalf("LoadFromFS() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	write := func(file, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	remove := func(file string) {
+		t.Helper()
+		if err := os.Remove(filepath.Join(dir, file)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("daily.yaml", "name: daily\ndescription: override\n")
+	write("extra.yaml", "name: extra\ndescription: user\n")
+	write("notes.txt", "ignored")
+	if err := loader.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if got := registry.Get("daily").Description; got != "override" {
+		t.Errorf("daily = %q, want the directory version", got)
+	}
+	if !registry.Exists("extra") || registry.Count() != 2 {
+		t.Errorf("registry = %v, want daily and extra", registry.List())
+	}
+	if string(loader.Sources()["extra"]) != "name: extra\ndescription: user\n" {
+		t.Error("Sources() should include directory scripts")
+	}
+
+	// A broken edit keeps the last good version
+	write("extra.yaml", "name: extra\nsteps: [\n")
+	if err := loader.LoadDir(dir); err == nil {
+		t.Error("LoadDir() should report the broken file")
+	}
+	if got := registry.Get("extra"); got == nil || got.Description != "user" {
+		t.Errorf("extra = %+v, want the last good version", got)
+	}
+
+	remove("daily.yaml")
+	remove("extra.yaml")
+	if err := loader.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if got := registry.Get("daily").Description; got != "embedded" {
+		t.Errorf("daily = %q, want the embedded version restored", got)
+	}
+	if registry.Exists("extra") {
+		t.Error("extra should be unregistered after its file is removed")
+	}
+}
@@ -1,7 +1,11 @@
 package script
 
 import (
+	"errors"
+	"fmt"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 )
 
@@ -75,6 +79,13 @@ func (r *Registry) Count() int {
 	return len(r.scripts)
 }
 
+// Unregister removes a script from the registry.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.scripts, name)
+}
+
 // Clear removes all scripts from the registry.
 func (r *Registry) Clear() {
 	r.mu.Lock()
@@ -89,3 +100,61 @@ func (r *Registry) Exists(name string) bool {
 	_, ok := r.scripts[name]
 	return ok
 }
+
+// CheckCalls verifies the call actions of all registered scripts: every
+// called script must exist and no script may call itself, directly or
+// through other scripts. All problems are returned joined.
+func (r *Registry) CheckCalls() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.scripts))
+	for name := range r.scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		for _, callee := range r.scripts[name].Calls() {
+			if _, ok := r.scripts[callee]; !ok {
+				errs = append(errs, fmt.Errorf("script %s calls unknown script %q", name, callee))
+			}
+		}
+	}
+
+	// Depth-first search; a script seen again while on the path closes a cycle
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	marks := make(map[string]int, len(names))
+	var path []string
+	var visit func(name string)
+	visit = func(name string) {
+		marks[name] = onPath
+		path = append(path, name)
+		for _, callee := range r.scripts[name].Calls() {
+			if _, ok := r.scripts[callee]; !ok {
+				continue
+			}
+			switch marks[callee] {
+			case unvisited:
+				visit(callee)
+			case onPath:
+				cycle := append(slices.Clone(path[slices.Index(path, callee):]), callee)
+				errs = append(errs, fmt.Errorf("call cycle: %s", strings.Join(cycle, " -> ")))
+			}
+		}
+		path = path[:len(path)-1]
+		marks[name] = done
+	}
+	for _, name := range names {
+		if marks[name] == unvisited {
+			visit(name)
+		}
+	}
+
+	return errors.Join(errs...)
+}
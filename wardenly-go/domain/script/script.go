@@ -3,6 +3,11 @@ package script
 
 import (
 	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,20 +27,105 @@ type Script struct {
 
 	// Steps are the ordered execution steps
 	Steps []Step
+	// Prompts are the parameters collected from the user when the script starts
+	Prompts []Prompt
+	// ExclusionGroups name resources the script manipulates; two scripts
+	// sharing a group never run at the same time on the same account
+	ExclusionGroups []string
+	// Jitter randomizes the script's clicks, drags and waits
+	Jitter Jitter
+	// LagSlowdown multiplies waits and loop intervals while the session's
+	// input latency is high (0 disables)
+	LagSlowdown float64
+	// MatchMargin is how much closer, relative to its threshold, the best
+	// matching step scene must be than the next closest; a frame where two
+	// of them are nearer than that matches no step. Zero takes the best
+	// match however close the runner-up.
+	MatchMargin float64
+	// Timeout bounds a run of the script, including when it is called by
+	// another script; the run stops with an error once it passes. Zero runs
+	// until stopped.
+	Timeout time.Duration
+	// Limits caps how many clicks and drags a run may send per minute
+	Limits Limits
+	// OnStop are run best-effort whenever a run stops, for any reason but a
+	// lost browser, before the session is released, so it is left on a
+	// known-safe screen (e.g. dialogs closed, back in the main city)
+	OnStop []Action
+}
+
+// Limits guards against loop bugs hammering the game server: a run that
+// reaches a limit pauses until its oldest action is a minute old. Zero
+// leaves an action unlimited.
+type Limits struct {
+	ClicksPerMinute int
+	DragsPerMinute  int
+}
+
+// Jitter is the random variation applied to actions, so repeated clicks
+// don't land on the same pixel at the same rhythm.
+type Jitter struct {
+	// Pixels is the largest offset of a click or drag point on each axis
+	Pixels float64
+	// Wait is the largest fraction by which a wait is shortened or lengthened
+	Wait float64
+}
+
+// Prompt declares a parameter the user is asked for at start time.
+type Prompt struct {
+	// Key is the variable name the value is stored under
+	Key string
+	// Type is the value type (int, string)
+	Type PromptType
+	// Label is the human-readable question shown in the dialog
+	Label string
+	// Default is the value used when neither the user nor the account provides one
+	Default string
 }
 
+// PromptType represents the value type of a prompt.
+type PromptType string
+
+const (
+	PromptTypeInt    PromptType = "int"
+	PromptTypeString PromptType = "string"
+)
+
 // Step represents a single step in script execution.
+// Steps run whenever their scene matches, first match wins. A step with
+// OnMatch instead makes the runner wait for the labeled step only, and
+// OnTimeout redirects that wait when the scene doesn't appear in time.
 type Step struct {
+	// Label names the step as a branch target (optional)
+	Label string
+
 	// ExpectedScene is the scene name this step expects to match
 	ExpectedScene string
 
-	// Timeout is the maximum time to wait for the expected scene
+	// SceneCategory matches the step on any scene of the category instead
+	// of ExpectedScene; the closest one wins
+	SceneCategory string
+
+	// Timeout is the maximum time to wait for the expected scene when the
+	// step is a branch target; zero waits indefinitely. Steps matched in
+	// any order are not timed.
 	Timeout time.Duration
 
+	// OnMatch is the label of the step to wait for after this one runs.
+	// Empty returns to matching all steps.
+	OnMatch string
+
+	// OnTimeout is the label of the step to wait for when this step is a
+	// branch target and its scene doesn't match within Timeout.
+	// Empty returns to matching all steps.
+	OnTimeout string
+
 	// Actions are the actions to perform when the scene matches
 	Actions []Action
 
-	// ContinueOnFailure determines if execution continues when this step fails
+	// ContinueOnFailure determines if execution continues when this step
+	// times out without an OnTimeout branch. Otherwise the script stops
+	// with an error.
 	ContinueOnFailure bool
 
 	// Loop defines optional loop behavior for this step
@@ -53,17 +143,50 @@ type Action struct {
 	// Points are the coordinates for the action
 	Points []Point
 
-	// Duration is the time for the action (e.g., wait duration)
+	// Region is an optional click area; when set, a random point inside it
+	// is chosen on every execution instead of Points[0]
+	Region *Region
+
+	// Jitter overrides the script's Jitter.Pixels for this click or drag
+	// (optional; zero disables it)
+	Jitter *float64
+
+	// Duration is the time for the action (e.g., wait duration), or how
+	// long waitSelector waits (the driver's timeout if zero)
 	Duration time.Duration
 
 	// RetryCount is the number of retries on failure
 	RetryCount int
 
-	// Key is used for counter operations (incr/decr)
+	// Key is the variable changed by incr, decr, set and add
 	Key string
 
+	// Value is the expression assigned by set or added by add
+	Value *Expr
+
 	// Condition is used for conditional actions (quit)
 	Condition *Condition
+
+	// Script is the script run by a call action
+	Script string
+
+	// Text is typed by send_keys; ${key} is replaced with a variable or
+	// param value
+	Text string
+
+	// Selector is the CSS selector of the page element clickSelector
+	// clicks and waitSelector waits for; it makes send_keys type into that
+	// element instead of the focused one (optional)
+	Selector string
+
+	// DeltaX and DeltaY are the wheel distance of a scroll in CSS pixels;
+	// positive values scroll right and down
+	DeltaX float64
+	DeltaY float64
+
+	// Expression is the JavaScript run in the page by evaluate; a number
+	// or boolean result is kept in Key if set
+	Expression string
 }
 
 // ActionType represents the type of action.
@@ -77,6 +200,17 @@ const (
 	ActionTypeIncr       ActionType = "incr"
 	ActionTypeDecr       ActionType = "decr"
 	ActionTypeCheckScene ActionType = "check_scene"
+	ActionTypeCall       ActionType = "call"
+	ActionTypeSet        ActionType = "set"
+	ActionTypeAdd        ActionType = "add"
+	ActionTypeSendKeys   ActionType = "send_keys"
+	ActionTypeScroll     ActionType = "scroll"
+	ActionTypeEvaluate   ActionType = "evaluate"
+
+	// Element actions act on the HTML around the game (login, server
+	// selection, portal popups) by CSS selector instead of coordinates
+	ActionTypeClickSelector ActionType = "clickSelector"
+	ActionTypeWaitSelector  ActionType = "waitSelector"
 )
 
 // Point represents coordinates for actions.
@@ -85,8 +219,32 @@ type Point struct {
 	Y float64
 }
 
-// Condition defines a condition check for script control.
+// Region is a rectangle from which click points are sampled.
+type Region struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+
+	// Distribution controls how points are sampled (uniform, center)
+	Distribution Distribution
+}
+
+// Distribution represents how a point is sampled inside a region.
+type Distribution string
+
+const (
+	DistributionUniform Distribution = "uniform"
+	DistributionCenter  Distribution = "center"
+)
+
+// Condition defines a condition check for script control. It is either an
+// expression over the script variables or a single comparison of one
+// variable (Op, Key, Value).
 type Condition struct {
+	// Expr, when set, is evaluated instead of the single comparison
+	Expr *Expr
+
 	// Op is the comparison operator (eq, gt, lt, neq, gte, lte)
 	Op string
 
@@ -115,6 +273,30 @@ type Loop struct {
 	Interval time.Duration
 }
 
+// OCR rule names.
+const (
+	// OCRRuleQuitWhenExhausted reads a usage ratio and stops the script
+	// when the resource is used up
+	OCRRuleQuitWhenExhausted = "quit_when_exhausted"
+	// OCRRuleMatchText reads text and runs the step only when it contains
+	// one of the expected strings
+	OCRRuleMatchText = "match_text"
+	// OCRRuleMatchPattern reads text and runs the step only when it matches
+	// a regular expression, setting a variable from each named group that
+	// holds an integer
+	OCRRuleMatchPattern = "match_pattern"
+	// OCRRuleReadNumber reads a number and stops the script as exhausted
+	// when it compares to a threshold or variable as set by Compare
+	OCRRuleReadNumber = "read_number"
+)
+
+// Comparisons of a read_number rule.
+const (
+	CompareLess    = "lt"
+	CompareGreater = "gt"
+	CompareEqual   = "eq"
+)
+
 // OCRRule defines OCR-based resource check behavior.
 type OCRRule struct {
 	// Name identifies the rule (e.g., "quit_when_exhausted")
@@ -123,8 +305,43 @@ type OCRRule struct {
 	// ROI is the region of interest for OCR
 	ROI ROI
 
-	// Threshold is the numerator threshold for the quit condition
+	// FallbackROIs are tried in order when ROI does not yield a confident result
+	FallbackROIs []ROI
+
+	// MinConfidence is the lowest OCR confidence accepted from a candidate ROI
+	MinConfidence float64
+
+	// Threshold is the numerator threshold for the quit condition, or
+	// what a read_number rule compares against without Against
 	Threshold int
+
+	// Compare is how a read_number rule compares the number it reads:
+	// lt, gt or eq
+	Compare string
+
+	// Against names the variable a read_number rule compares against
+	// instead of Threshold (optional)
+	Against string
+
+	// Key names the variable a read_number rule stores the number in
+	// (optional)
+	Key string
+
+	// Expected are the strings a match_text rule looks for
+	Expected []string
+
+	// Tolerance is the number of misread characters (insertions, deletions
+	// or substitutions) a match_text rule accepts
+	Tolerance int
+
+	// Pattern is the regular expression a match_pattern rule matches
+	// against the text, its lines joined by newlines
+	Pattern *regexp.Regexp
+
+	// Language and Charset are passed to the OCR service as recognition
+	// hints for text rules (e.g. "ch" for Chinese quest names)
+	Language string
+	Charset  string
 }
 
 // ROI defines a rectangular region of interest for OCR.
@@ -135,11 +352,184 @@ type ROI struct {
 	Height int
 }
 
+// Validate checks that the rule has a known name and the fields it needs.
+func (r *OCRRule) Validate() error {
+	switch r.Name {
+	case OCRRuleQuitWhenExhausted:
+	case OCRRuleMatchText:
+		if len(r.Expected) == 0 {
+			return fmt.Errorf("OCR rule %s needs expected strings", r.Name)
+		}
+		if r.Tolerance < 0 {
+			return fmt.Errorf("OCR rule %s tolerance cannot be negative", r.Name)
+		}
+	case OCRRuleMatchPattern:
+		if r.Pattern == nil {
+			return fmt.Errorf("OCR rule %s needs a pattern", r.Name)
+		}
+	case OCRRuleReadNumber:
+		switch r.Compare {
+		case CompareLess, CompareGreater, CompareEqual:
+		default:
+			return fmt.Errorf("OCR rule %s compare must be %s, %s or %s, got %q",
+				r.Name, CompareLess, CompareGreater, CompareEqual, r.Compare)
+		}
+	default:
+		return fmt.Errorf("unknown OCR rule %q", r.Name)
+	}
+	return nil
+}
+
+// MatchText returns the first expected string found in one of the lines
+// within the rule's tolerance.
+func (r *OCRRule) MatchText(lines []string) (string, bool) {
+	for _, expected := range r.Expected {
+		for _, line := range lines {
+			if FuzzyContains(line, expected, r.Tolerance) {
+				return expected, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ReadsText reports whether the rule reads free text and skips the step
+// when it doesn't match.
+func (r *OCRRule) ReadsText() bool {
+	return r.Name == OCRRuleMatchText || r.Name == OCRRuleMatchPattern
+}
+
+// MatchPattern matches the rule's pattern against text and returns the
+// matched text and the integer values of its named groups, by name.
+// Groups that didn't take part in the match or hold no integer are left
+// out.
+func (r *OCRRule) MatchPattern(text string) (string, map[string]int, bool) {
+	m := r.Pattern.FindStringSubmatchIndex(text)
+	if m == nil {
+		return "", nil, false
+	}
+	vars := make(map[string]int)
+	for i, name := range r.Pattern.SubexpNames() {
+		if name == "" || m[2*i] < 0 {
+			continue
+		}
+		if v, err := strconv.Atoi(strings.TrimSpace(text[m[2*i]:m[2*i+1]])); err == nil {
+			vars[name] = v
+		}
+	}
+	return text[m[0]:m[1]], vars, true
+}
+
+// Limit returns what a read_number rule compares against: the variable
+// named by Against, or Threshold.
+func (r *OCRRule) Limit(vars map[string]int) int {
+	if r.Against != "" {
+		return vars[r.Against]
+	}
+	return r.Threshold
+}
+
+// Holds reports whether value compares to the rule's limit as set by
+// Compare, which stops the script.
+func (r *OCRRule) Holds(value int, vars map[string]int) bool {
+	limit := r.Limit(vars)
+	switch r.Compare {
+	case CompareLess:
+		return value < limit
+	case CompareGreater:
+		return value > limit
+	case CompareEqual:
+		return value == limit
+	default:
+		return false
+	}
+}
+
+// ParseNumber returns the first number in OCR text, such as 1250 in
+// "Gold: 1,250". Commas and dots between digit groups are taken as
+// thousands separators, since game counters show whole numbers.
+func ParseNumber(text string) (int, bool) {
+	start := strings.IndexFunc(text, isDigit)
+	if start < 0 {
+		return 0, false
+	}
+	var digits []rune
+	runes := []rune(text[start:])
+	for i, c := range runes {
+		switch {
+		case isDigit(c):
+			digits = append(digits, c)
+		case (c == ',' || c == '.') && i+1 < len(runes) && isDigit(runes[i+1]):
+		default:
+			return parseDigits(digits)
+		}
+	}
+	return parseDigits(digits)
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+func parseDigits(digits []rune) (int, bool) {
+	n, err := strconv.Atoi(string(digits))
+	return n, err == nil
+}
+
+// FuzzyContains reports whether pattern occurs in text with at most
+// maxEdits character insertions, deletions or substitutions. Whitespace is
+// ignored and letters are compared case-insensitively, since OCR often
+// splits or merges words.
+func FuzzyContains(text, pattern string, maxEdits int) bool {
+	t := []rune(normalizeOCRText(text))
+	p := []rune(normalizeOCRText(pattern))
+	if len(p) <= maxEdits {
+		return true
+	}
+
+	// dist[i] is the edit distance between p[:i] and the best substring of
+	// t ending at the current position (Sellers' algorithm)
+	dist := make([]int, len(p)+1)
+	for i := range dist {
+		dist[i] = i
+	}
+	for _, c := range t {
+		diag := dist[0] // a match may start anywhere, so dist[0] stays 0
+		for i := 1; i <= len(p); i++ {
+			cost := 1
+			if p[i-1] == c {
+				cost = 0
+			}
+			next := min(diag+cost, dist[i]+1, dist[i-1]+1)
+			diag = dist[i]
+			dist[i] = next
+		}
+		if dist[len(p)] <= maxEdits {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeOCRText drops whitespace and lowercases letters.
+func normalizeOCRText(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), ""))
+}
+
+// Candidates returns the primary ROI followed by the fallbacks, in trial order.
+func (r *OCRRule) Candidates() []ROI {
+	return append([]ROI{r.ROI}, r.FallbackROIs...)
+}
+
 // Evaluate checks if the condition is satisfied.
 func (c *Condition) Evaluate(counters map[string]int) bool {
 	if c == nil {
 		return false
 	}
+	if c.Expr != nil {
+		ok, err := c.Expr.Bool(counters)
+		return err == nil && ok
+	}
 
 	value, exists := counters[c.Key]
 	if !exists {
@@ -164,6 +554,488 @@ func (c *Condition) Evaluate(counters map[string]int) bool {
 	}
 }
 
+// Parse validates a raw prompt value against the prompt type.
+func (p *Prompt) Parse(value string) (string, error) {
+	switch p.Type {
+	case PromptTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", fmt.Errorf("prompt %q expects an integer, got %q", p.Key, value)
+		}
+	case PromptTypeString, "":
+	default:
+		return "", fmt.Errorf("prompt %q has unknown type %q", p.Key, p.Type)
+	}
+	return value, nil
+}
+
+// Validate checks that the prompt declaration is well-formed.
+func (p *Prompt) Validate() error {
+	if p.Key == "" {
+		return fmt.Errorf("prompt key cannot be empty")
+	}
+	switch p.Type {
+	case PromptTypeInt, PromptTypeString, "":
+	default:
+		return fmt.Errorf("prompt %q has unknown type %q", p.Key, p.Type)
+	}
+	if p.Default != "" {
+		if _, err := p.Parse(p.Default); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onStopActions are the action types allowed in OnStop; they only send
+// input or wait, since the run is over and no scene is matched.
+var onStopActions = []ActionType{ActionTypeClick, ActionTypeDrag, ActionTypeScroll, ActionTypeWait, ActionTypeSendKeys, ActionTypeClickSelector}
+
+// Validate checks the script's jitter, prompts, OCR rules, loops, actions,
+// onStop actions and branches. Parse runs it on every loaded script.
+func (s *Script) Validate() error {
+	if err := s.Jitter.Validate(); err != nil {
+		return err
+	}
+	if s.LagSlowdown != 0 && s.LagSlowdown < 1 {
+		return fmt.Errorf("lagSlowdown must be at least 1, got %v", s.LagSlowdown)
+	}
+	if s.MatchMargin < 0 {
+		return fmt.Errorf("matchMargin must not be negative, got %v", s.MatchMargin)
+	}
+	if s.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got %v", s.Timeout)
+	}
+	if err := s.Limits.Validate(); err != nil {
+		return err
+	}
+	for i := range s.Prompts {
+		if err := s.Prompts[i].Validate(); err != nil {
+			return err
+		}
+	}
+	if err := s.validatePromptVars(); err != nil {
+		return err
+	}
+	for i, step := range s.Steps {
+		if step.Timeout < 0 {
+			return fmt.Errorf("step %d: timeout must not be negative, got %v", i, step.Timeout)
+		}
+		if step.ExpectedScene != "" && step.SceneCategory != "" {
+			return fmt.Errorf("step %d: scene and sceneCategory are mutually exclusive", i)
+		}
+		if step.OCRRule != nil {
+			if err := step.OCRRule.Validate(); err != nil {
+				return fmt.Errorf("step %d: %w", i, err)
+			}
+		}
+		if step.Loop != nil {
+			if err := step.Loop.ValidateIndices(len(step.Actions)); err != nil {
+				return fmt.Errorf("step %d: %w", i, err)
+			}
+		}
+		for j := range step.Actions {
+			if err := step.Actions[j].Validate(); err != nil {
+				return fmt.Errorf("step %d action %d: %w", i, j, err)
+			}
+		}
+	}
+	for i := range s.OnStop {
+		action := &s.OnStop[i]
+		if !slices.Contains(onStopActions, action.Type) {
+			return fmt.Errorf("onStop action %d: %s is not allowed; use click, drag, scroll, wait, send_keys or clickSelector", i, action.Type)
+		}
+		if err := action.Validate(); err != nil {
+			return fmt.Errorf("onStop action %d: %w", i, err)
+		}
+	}
+	return s.ValidateBranches()
+}
+
+// validatePromptVars rejects string prompts used as variables. Only int
+// prompts seed the counters conditions and expressions read, so a string
+// prompt there would always read as 0; it can only be typed by send_keys.
+func (s *Script) validatePromptVars() error {
+	var stringKeys []string
+	for _, prompt := range s.Prompts {
+		if prompt.Type != PromptTypeInt {
+			stringKeys = append(stringKeys, prompt.Key)
+		}
+	}
+	if len(stringKeys) == 0 {
+		return nil
+	}
+
+	check := func(where string, names ...string) error {
+		for _, name := range names {
+			if slices.Contains(stringKeys, name) {
+				return fmt.Errorf("%s: string prompt %q cannot be used as a variable; declare it as type int", where, name)
+			}
+		}
+		return nil
+	}
+	for i, step := range s.Steps {
+		if step.OCRRule != nil {
+			if err := check(fmt.Sprintf("step %d", i), step.OCRRule.Against); err != nil {
+				return err
+			}
+		}
+		for j := range step.Actions {
+			if err := check(fmt.Sprintf("step %d action %d", i, j), step.Actions[j].Vars()...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Vars returns the names of the variables the action reads or changes.
+func (a *Action) Vars() []string {
+	var names []string
+	switch a.Type {
+	case ActionTypeIncr, ActionTypeDecr, ActionTypeSet, ActionTypeAdd:
+		names = append(names, a.Key)
+	}
+	if a.Value != nil {
+		names = append(names, a.Value.Vars()...)
+	}
+	if c := a.Condition; c != nil {
+		if c.Expr != nil {
+			names = append(names, c.Expr.Vars()...)
+		} else {
+			names = append(names, c.Key)
+		}
+	}
+	return names
+}
+
+// Validate checks that the action has the fields its type needs and a
+// valid jitter and region.
+func (a *Action) Validate() error {
+	switch a.Type {
+	case ActionTypeCall:
+		if a.Script == "" {
+			return fmt.Errorf("call needs a script name")
+		}
+	case ActionTypeSet, ActionTypeAdd:
+		if a.Key == "" || a.Value == nil {
+			return fmt.Errorf("%s needs a key and a value", a.Type)
+		}
+	case ActionTypeSendKeys:
+		if a.Text == "" {
+			return fmt.Errorf("send_keys needs text")
+		}
+	case ActionTypeEvaluate:
+		if a.Expression == "" {
+			return fmt.Errorf("evaluate needs an expression")
+		}
+	case ActionTypeClickSelector, ActionTypeWaitSelector:
+		if a.Selector == "" {
+			return fmt.Errorf("%s needs a selector", a.Type)
+		}
+		if a.Duration < 0 {
+			return fmt.Errorf("%s duration must not be negative", a.Type)
+		}
+	case ActionTypeScroll:
+		if len(a.Points) == 0 && a.Region == nil {
+			return fmt.Errorf("scroll needs a point or region")
+		}
+		if a.DeltaX == 0 && a.DeltaY == 0 {
+			return fmt.Errorf("scroll needs deltaX or deltaY")
+		}
+	}
+	if a.Jitter != nil && *a.Jitter < 0 {
+		return fmt.Errorf("jitter must not be negative")
+	}
+	if a.Region != nil {
+		return a.Region.Validate()
+	}
+	return nil
+}
+
+// Scenes returns the names of the scenes the script's steps and loops wait
+// for, in order of first use.
+func (s *Script) Scenes() []string {
+	var names []string
+	add := func(name string) {
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, step := range s.Steps {
+		add(step.ExpectedScene)
+		if step.Loop != nil {
+			add(step.Loop.Until)
+		}
+	}
+	return names
+}
+
+// SceneCategories returns the scene categories the script's steps wait
+// for, in order of first use.
+func (s *Script) SceneCategories() []string {
+	var categories []string
+	for _, step := range s.Steps {
+		if step.SceneCategory != "" && !slices.Contains(categories, step.SceneCategory) {
+			categories = append(categories, step.SceneCategory)
+		}
+	}
+	return categories
+}
+
+// ScenePattern returns what the step waits for: its scene, or
+// "<category>/*" for a step matching a scene category.
+func (s *Step) ScenePattern() string {
+	if s.SceneCategory != "" {
+		return s.SceneCategory + "/*"
+	}
+	return s.ExpectedScene
+}
+
+// UsesOCR returns true if any step has an OCR rule.
+func (s *Script) UsesOCR() bool {
+	for _, step := range s.Steps {
+		if step.OCRRule != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// StepIndex returns the index of the step with the given label, or -1.
+func (s *Script) StepIndex(label string) int {
+	if label == "" {
+		return -1
+	}
+	for i := range s.Steps {
+		if s.Steps[i].Label == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// ValidateBranches checks that step labels are unique and that every
+// branch names an existing label.
+func (s *Script) ValidateBranches() error {
+	labels := make(map[string]bool)
+	for i, step := range s.Steps {
+		if step.Label == "" {
+			continue
+		}
+		if labels[step.Label] {
+			return fmt.Errorf("step %d: duplicate label %q", i, step.Label)
+		}
+		labels[step.Label] = true
+	}
+	for i, step := range s.Steps {
+		for _, target := range []string{step.OnMatch, step.OnTimeout} {
+			if target != "" && !labels[target] {
+				return fmt.Errorf("step %d: goto unknown label %q", i, target)
+			}
+		}
+	}
+	return nil
+}
+
+// Calls returns the names of the scripts called by the script's actions,
+// in order of first use.
+func (s *Script) Calls() []string {
+	var names []string
+	for _, step := range s.Steps {
+		for _, action := range step.Actions {
+			if action.Type == ActionTypeCall && !slices.Contains(names, action.Script) {
+				names = append(names, action.Script)
+			}
+		}
+	}
+	return names
+}
+
+// ConflictsWith returns the first exclusion group shared with other, or
+// "" when both scripts may run at the same time.
+func (s *Script) ConflictsWith(other *Script) string {
+	if s == nil || other == nil {
+		return ""
+	}
+	for _, group := range s.ExclusionGroups {
+		if slices.Contains(other.ExclusionGroups, group) {
+			return group
+		}
+	}
+	return ""
+}
+
+// HasPrompts returns true if the script asks for parameters at start time.
+func (s *Script) HasPrompts() bool {
+	return s != nil && len(s.Prompts) > 0
+}
+
+// ResolveParams merges prompt values in priority order: explicit values,
+// then remembered values, then prompt defaults.
+// Returns an error if a prompt has no value or a value does not match its type.
+func (s *Script) ResolveParams(values, remembered map[string]string) (map[string]string, error) {
+	params := make(map[string]string, len(s.Prompts))
+	for i := range s.Prompts {
+		prompt := &s.Prompts[i]
+
+		raw, ok := values[prompt.Key]
+		if !ok {
+			raw, ok = remembered[prompt.Key]
+		}
+		if !ok {
+			raw, ok = prompt.Default, prompt.Default != ""
+		}
+		if !ok {
+			return nil, fmt.Errorf("missing value for prompt %q", prompt.Key)
+		}
+
+		value, err := prompt.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		params[prompt.Key] = value
+	}
+	return params, nil
+}
+
+// IntParams returns the integer-typed params, suitable for seeding counters.
+func (s *Script) IntParams(params map[string]string) map[string]int {
+	result := make(map[string]int)
+	for _, prompt := range s.Prompts {
+		if prompt.Type != PromptTypeInt {
+			continue
+		}
+		if v, err := strconv.Atoi(params[prompt.Key]); err == nil {
+			result[prompt.Key] = v
+		}
+	}
+	return result
+}
+
+// Validate checks that the region has a positive size and a known distribution.
+func (r *Region) Validate() error {
+	if r.Width <= 0 || r.Height <= 0 {
+		return fmt.Errorf("region size must be positive, got %vx%v", r.Width, r.Height)
+	}
+	switch r.Distribution {
+	case DistributionUniform, DistributionCenter, "":
+		return nil
+	default:
+		return fmt.Errorf("unknown region distribution %q", r.Distribution)
+	}
+}
+
+// Pick returns a point inside the region.
+// rnd must return values in [0, 1). Center-weighted sampling averages two
+// draws per axis, giving a triangular distribution peaking at the center.
+func (r *Region) Pick(rnd func() float64) Point {
+	sample := rnd
+	if r.Distribution == DistributionCenter {
+		sample = func() float64 { return (rnd() + rnd()) / 2 }
+	}
+	return Point{
+		X: r.X + sample()*r.Width,
+		Y: r.Y + sample()*r.Height,
+	}
+}
+
+// ClickPoint returns the point a click action should hit.
+// A region takes precedence over Points; false is returned when neither is set.
+func (a *Action) ClickPoint(rnd func() float64) (Point, bool) {
+	if a.Region != nil {
+		return a.Region.Pick(rnd), true
+	}
+	if len(a.Points) > 0 {
+		return a.Points[0], true
+	}
+	return Point{}, false
+}
+
+// ExpandText returns the action's text with each ${key} replaced by the
+// variable of that name, or else the param. Unknown keys expand to "".
+func (a *Action) ExpandText(vars map[string]int, params map[string]string) string {
+	return os.Expand(a.Text, func(key string) string {
+		if v, ok := vars[key]; ok {
+			return strconv.Itoa(v)
+		}
+		return params[key]
+	})
+}
+
+// Validate checks that the jitter is non-negative and waits keep their sign.
+func (j Jitter) Validate() error {
+	if j.Pixels < 0 {
+		return fmt.Errorf("jitter pixels must not be negative, got %v", j.Pixels)
+	}
+	if j.Wait < 0 || j.Wait >= 1 {
+		return fmt.Errorf("wait jitter must be in [0, 1), got %v", j.Wait)
+	}
+	return nil
+}
+
+// Validate checks that the limits are non-negative.
+func (l Limits) Validate() error {
+	if l.ClicksPerMinute < 0 {
+		return fmt.Errorf("clicksPerMinute must not be negative, got %d", l.ClicksPerMinute)
+	}
+	if l.DragsPerMinute < 0 {
+		return fmt.Errorf("dragsPerMinute must not be negative, got %d", l.DragsPerMinute)
+	}
+	return nil
+}
+
+// PerMinute returns the limit for an action type, zero if it has none.
+func (l Limits) PerMinute(t ActionType) int {
+	switch t {
+	case ActionTypeClick:
+		return l.ClicksPerMinute
+	case ActionTypeDrag:
+		return l.DragsPerMinute
+	default:
+		return 0
+	}
+}
+
+// Offset moves p by up to pixels on each axis, without leaving the
+// positive quadrant. rnd must return values in [0, 1).
+func (j Jitter) Offset(p Point, pixels float64, rnd func() float64) Point {
+	if pixels <= 0 {
+		return p
+	}
+	return Point{
+		X: max(0, p.X+(2*rnd()-1)*pixels),
+		Y: max(0, p.Y+(2*rnd()-1)*pixels),
+	}
+}
+
+// Duration shortens or lengthens d by up to the Wait fraction.
+// rnd must return values in [0, 1).
+func (j Jitter) Duration(d time.Duration, rnd func() float64) time.Duration {
+	if j.Wait <= 0 || d <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + (2*rnd()-1)*j.Wait))
+}
+
+// Pace returns d with the script's wait jitter applied, stretched by
+// LagSlowdown when lagging. rnd must return values in [0, 1).
+func (s *Script) Pace(d time.Duration, lagging bool, rnd func() float64) time.Duration {
+	d = s.Jitter.Duration(d, rnd)
+	if lagging && s.LagSlowdown > 1 {
+		d = time.Duration(float64(d) * s.LagSlowdown)
+	}
+	return d
+}
+
+// JitterPixels returns the point jitter of a click or drag: the action's own
+// when set, otherwise the script default.
+func (a *Action) JitterPixels(def Jitter) float64 {
+	if a.Jitter != nil {
+		return *a.Jitter
+	}
+	return def.Pixels
+}
+
 // IsInfinite returns true if the loop runs indefinitely.
 func (l *Loop) IsInfinite() bool {
 	return l != nil && l.Count < 0
@@ -1,6 +1,11 @@
 package script
 
-import "testing"
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestCondition_Evaluate(t *testing.T) {
 	counters := map[string]int{
@@ -61,6 +66,90 @@ func TestCondition_Evaluate(t *testing.T) {
 	}
 }
 
+func TestParseExpr(t *testing.T) {
+	vars := map[string]int{"energy": 8, "runs": 3, "zero": 0}
+
+	tests := []struct {
+		src  string
+		want int
+	}{
+		{"energy < 10 && runs >= 3", 1},
+		{"energy < 5 || runs == 3", 1},
+		{"!(runs > 2)", 0},
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"energy - runs - 1", 4},
+		{"-runs + 10 % 4", -1},
+		{"missing == 0", 1},
+		{"zero != 0 && 1 / zero", 0}, // Short-circuits before dividing
+	}
+	for _, tt := range tests {
+		expr, err := ParseExpr(tt.src)
+		if err != nil {
+			t.Errorf("ParseExpr(%q) error = %v", tt.src, err)
+			continue
+		}
+		if got, err := expr.Eval(vars); err != nil || got != tt.want {
+			t.Errorf("Eval(%q) = %d, %v, want %d", tt.src, got, err, tt.want)
+		}
+	}
+
+	for _, src := range []string{"", "energy <", "(runs", "runs $ 2", "1 2"} {
+		if _, err := ParseExpr(src); err == nil {
+			t.Errorf("ParseExpr(%q) succeeded, want error", src)
+		}
+	}
+
+	expr, _ := ParseExpr("runs / zero")
+	if _, err := expr.Eval(vars); err != ErrDivisionByZero {
+		t.Errorf("Eval(runs / zero) error = %v, want ErrDivisionByZero", err)
+	}
+	if (&Condition{Expr: expr}).Evaluate(vars) {
+		t.Error("Condition with a failing expression should evaluate to false")
+	}
+}
+
+func TestParse_Variables(t *testing.T) {
+	s, err := Parse([]byte(`name: farm
+steps:
+  - scene: main
+    actions:
+      - type: set
+        key: runs
+        value: 0
+      - type: add
+        key: runs
+        value: "energy / 2"
+      - type: quit
+        condition: "energy < 10 && runs >= 3"
+      - type: quit
+        condition: {op: gte, key: runs, value: 5}
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	actions := s.Steps[0].Actions
+	if actions[0].Value == nil || actions[1].Value.String() != "energy / 2" {
+		t.Errorf("set/add values = %v, %v", actions[0].Value, actions[1].Value)
+	}
+	vars := map[string]int{"energy": 4, "runs": 3}
+	if !actions[2].Condition.Evaluate(vars) {
+		t.Error("expression condition should hold")
+	}
+	if actions[3].Condition.Expr != nil || actions[3].Condition.Evaluate(vars) {
+		t.Error("map condition should use op/key/value and not hold")
+	}
+
+	for _, src := range []string{
+		"name: bad\nsteps:\n  - scene: main\n    actions:\n      - type: quit\n        condition: \"runs >\"\n",
+		"name: bad\nsteps:\n  - scene: main\n    actions:\n      - type: set\n        key: runs\n",
+	} {
+		if _, err := Parse([]byte(src)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", src)
+		}
+	}
+}
+
 func TestLoop_IsInfinite(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -102,6 +191,297 @@ func TestLoop_HasUntilCondition(t *testing.T) {
 	}
 }
 
+func TestScript_ResolveParams(t *testing.T) {
+	s := &Script{
+		Name: "daily",
+		Prompts: []Prompt{
+			{Key: "rounds", Type: PromptTypeInt, Default: "3"},
+			{Key: "target", Type: PromptTypeString},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		values     map[string]string
+		remembered map[string]string
+		expected   map[string]string
+		wantErr    bool
+	}{
+		{
+			name:       "explicit wins",
+			values:     map[string]string{"rounds": "5", "target": "boss"},
+			remembered: map[string]string{"rounds": "7", "target": "mob"},
+			expected:   map[string]string{"rounds": "5", "target": "boss"},
+		},
+		{
+			name:       "remembered before default",
+			remembered: map[string]string{"rounds": "7", "target": "mob"},
+			expected:   map[string]string{"rounds": "7", "target": "mob"},
+		},
+		{
+			name:     "default fills gap",
+			values:   map[string]string{"target": "boss"},
+			expected: map[string]string{"rounds": "3", "target": "boss"},
+		},
+		{
+			name:    "missing value",
+			values:  map[string]string{"rounds": "5"},
+			wantErr: true,
+		},
+		{
+			name:    "bad int",
+			values:  map[string]string{"rounds": "five", "target": "boss"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ResolveParams(tt.values, tt.remembered)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for k, v := range tt.expected {
+				if got[k] != v {
+					t.Errorf("ResolveParams()[%s] = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestScript_IntParams(t *testing.T) {
+	s := &Script{
+		Prompts: []Prompt{
+			{Key: "rounds", Type: PromptTypeInt},
+			{Key: "target", Type: PromptTypeString},
+		},
+	}
+
+	got := s.IntParams(map[string]string{"rounds": "4", "target": "boss"})
+	if len(got) != 1 || got["rounds"] != 4 {
+		t.Errorf("IntParams() = %v, want map[rounds:4]", got)
+	}
+}
+
+func TestScript_ConflictsWith(t *testing.T) {
+	forge := &Script{Name: "forge", ExclusionGroups: []string{"gold", "bag"}}
+	market := &Script{Name: "market", ExclusionGroups: []string{"gold"}}
+	daily := &Script{Name: "daily"}
+
+	if got := forge.ConflictsWith(market); got != "gold" {
+		t.Errorf("forge.ConflictsWith(market) = %q, want gold", got)
+	}
+	if got := market.ConflictsWith(forge); got != "gold" {
+		t.Errorf("market.ConflictsWith(forge) = %q, want gold", got)
+	}
+	if got := forge.ConflictsWith(daily); got != "" {
+		t.Errorf("forge.ConflictsWith(daily) = %q, want none", got)
+	}
+	if got := forge.ConflictsWith(nil); got != "" {
+		t.Errorf("forge.ConflictsWith(nil) = %q, want none", got)
+	}
+}
+
+func TestScript_ValidateBranches(t *testing.T) {
+	s := &Script{Steps: []Step{
+		{ExpectedScene: "quest", OnMatch: "popup_a"},
+		{Label: "popup_a", ExpectedScene: "reward", OnTimeout: "popup_b"},
+		{Label: "popup_b", ExpectedScene: "battle"},
+	}}
+	if err := s.ValidateBranches(); err != nil {
+		t.Fatalf("ValidateBranches() = %v, want nil", err)
+	}
+	if got := s.StepIndex("popup_b"); got != 2 {
+		t.Errorf("StepIndex(popup_b) = %d, want 2", got)
+	}
+	if got := s.StepIndex(""); got != -1 {
+		t.Errorf("StepIndex(\"\") = %d, want -1", got)
+	}
+
+	s.Steps[2].OnMatch = "missing"
+	if err := s.ValidateBranches(); err == nil {
+		t.Error("ValidateBranches() accepted unknown label")
+	}
+
+	s.Steps[2] = Step{Label: "popup_a"}
+	if err := s.ValidateBranches(); err == nil {
+		t.Error("ValidateBranches() accepted duplicate label")
+	}
+}
+
+func TestOCRRule_MatchText(t *testing.T) {
+	rule := &OCRRule{Name: OCRRuleMatchText, Expected: []string{"领取奖励", "Claim"}, Tolerance: 1}
+	if err := rule.Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want nil", err)
+	}
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+		ok    bool
+	}{
+		{"exact", []string{"点击领取奖励"}, "领取奖励", true},
+		{"one edit", []string{"领取奖历"}, "领取奖励", true},
+		{"case and spaces", []string{"CLAIM  now"}, "Claim", true},
+		{"miss", []string{"开始战斗"}, "", false},
+		{"no lines", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := rule.MatchText(tt.lines)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("MatchText(%v) = %q, %v, want %q, %v", tt.lines, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	if FuzzyContains("领奖", "领取奖励", 1) {
+		t.Error("FuzzyContains accepted two missing characters with tolerance 1")
+	}
+	if err := (&OCRRule{Name: OCRRuleMatchText}).Validate(); err == nil {
+		t.Error("Validate() accepted match_text without expected strings")
+	}
+	if err := (&OCRRule{Name: "unknown"}).Validate(); err == nil {
+		t.Error("Validate() accepted unknown rule")
+	}
+}
+
+func TestOCRRule_MatchPattern(t *testing.T) {
+	s, err := Parse([]byte(`name: farm
+steps:
+  - scene: main
+    ocrRule:
+      name: match_pattern
+      roi: {x: 10, y: 20, width: 200, height: 40}
+      pattern: 'Gold:\s*(?P<gold>\d+)(?:\s*/\s*(?P<cap>\d+))?'
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	rule := s.Steps[0].OCRRule
+	if !rule.ReadsText() || rule.Pattern == nil {
+		t.Fatalf("rule = %+v, want a text rule with a pattern", rule)
+	}
+
+	matched, vars, ok := rule.MatchPattern("Stamina 30\nGold: 1250")
+	if !ok || matched != "Gold: 1250" || len(vars) != 1 || vars["gold"] != 1250 {
+		t.Errorf("MatchPattern() = %q, %v, %v, want gold 1250 without cap", matched, vars, ok)
+	}
+	if _, vars, _ := rule.MatchPattern("Gold: 80 / 100"); vars["gold"] != 80 || vars["cap"] != 100 {
+		t.Errorf("MatchPattern() vars = %v, want gold 80 and cap 100", vars)
+	}
+	if _, _, ok := rule.MatchPattern("Silver: 5"); ok {
+		t.Error("MatchPattern() matched text without the pattern")
+	}
+
+	if _, err := Parse([]byte("name: farm\nsteps:\n  - scene: main\n    ocrRule: {name: match_pattern, pattern: '(['}\n")); err == nil {
+		t.Error("Parse() accepted an invalid pattern")
+	}
+	if err := (&OCRRule{Name: OCRRuleMatchPattern}).Validate(); err == nil {
+		t.Error("Validate() accepted match_pattern without a pattern")
+	}
+}
+
+func TestOCRRule_ReadNumber(t *testing.T) {
+	s, err := Parse([]byte(`name: farm
+steps:
+  - scene: main
+    ocrRule:
+      name: read_number
+      roi: {x: 820, y: 20, width: 120, height: 30}
+      compare: lt
+      against: min_gold
+      key: gold
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	rule := s.Steps[0].OCRRule
+	if rule.Compare != CompareLess || rule.Against != "min_gold" || rule.Key != "gold" {
+		t.Fatalf("rule = %+v", rule)
+	}
+	vars := map[string]int{"min_gold": 500}
+	if !rule.Holds(499, vars) || rule.Holds(500, vars) {
+		t.Error("Holds() should compare against min_gold")
+	}
+	threshold := &OCRRule{Name: OCRRuleReadNumber, Compare: CompareGreater, Threshold: 3}
+	if threshold.Limit(vars) != 3 || !threshold.Holds(4, nil) || threshold.Holds(3, nil) {
+		t.Error("Holds() should compare against Threshold without Against")
+	}
+	if (&OCRRule{Compare: CompareEqual}).Holds(1, nil) || !(&OCRRule{Compare: CompareEqual}).Holds(0, nil) {
+		t.Error("eq should hold only for the limit")
+	}
+	if err := (&OCRRule{Name: OCRRuleReadNumber, Compare: "le"}).Validate(); err == nil {
+		t.Error("Validate() accepted an unknown comparison")
+	}
+
+	tests := []struct {
+		text string
+		want int
+		ok   bool
+	}{
+		{"1250", 1250, true},
+		{"Gold: 1,250", 1250, true},
+		{"12.500.000", 12500000, true},
+		{"8/120", 8, true},
+		{"Lv. 30", 30, true},
+		{"none", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := ParseNumber(tt.text); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseNumber(%q) = %d, %v, want %d, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPrompt_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  Prompt
+		wantErr bool
+	}{
+		{"valid int", Prompt{Key: "n", Type: PromptTypeInt, Default: "1"}, false},
+		{"valid string", Prompt{Key: "s", Type: PromptTypeString}, false},
+		{"empty key", Prompt{Type: PromptTypeInt}, true},
+		{"unknown type", Prompt{Key: "x", Type: "float"}, true},
+		{"bad default", Prompt{Key: "n", Type: PromptTypeInt, Default: "abc"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.prompt.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParse_StringPromptAsVariable(t *testing.T) {
+	const src = `name: farm
+prompts:
+  - key: stage
+    type: int
+  - key: target
+    type: string
+steps:
+  - scene: main
+    actions:
+      - type: send_keys
+        text: "${target} ${stage}"
+      - type: quit
+        condition: "stage > 3 && %s == 1"
+`
+	if _, err := Parse([]byte(strings.ReplaceAll(src, "%s", "stage"))); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	_, err := Parse([]byte(strings.ReplaceAll(src, "%s", "target")))
+	if err == nil || !strings.Contains(err.Error(), `string prompt "target"`) {
+		t.Errorf("Parse() error = %v, want string prompt rejected as a variable", err)
+	}
+}
+
 func TestRegistry_Basic(t *testing.T) {
 	registry := NewRegistry()
 
@@ -199,3 +579,417 @@ func TestRegistry_Replace(t *testing.T) {
 		t.Error("Count should still be 1 after replacing")
 	}
 }
+
+func TestRegistry_CheckCalls(t *testing.T) {
+	parse := func(src string) *Script {
+		t.Helper()
+		s, err := Parse([]byte(src))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		return s
+	}
+
+	registry := NewRegistry()
+	registry.RegisterAll([]*Script{
+		parse("name: daily\nsteps:\n  - scene: main\n    actions:\n      - call: close_popups\n      - call: return_home\n"),
+		parse("name: close_popups\nsteps:\n  - scene: popup\n    actions:\n      - type: click\n        points: [{x: 1, y: 1}]\n"),
+		parse("name: return_home\nsteps:\n  - scene: shop\n    actions:\n      - call: close_popups\n"),
+	})
+	if got := registry.Get("daily").Calls(); len(got) != 2 || got[0] != "close_popups" || got[1] != "return_home" {
+		t.Errorf("Calls() = %v, want [close_popups return_home]", got)
+	}
+	if err := registry.CheckCalls(); err != nil {
+		t.Fatalf("CheckCalls() = %v, want nil", err)
+	}
+
+	registry.Register(parse("name: close_popups\nsteps:\n  - scene: popup\n    actions:\n      - call: daily\n"))
+	if err := registry.CheckCalls(); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("CheckCalls() = %v, want a cycle error", err)
+	}
+
+	registry.Register(parse("name: close_popups\nsteps:\n  - scene: popup\n    actions:\n      - call: missing\n"))
+	if err := registry.CheckCalls(); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("CheckCalls() = %v, want an unknown script error", err)
+	}
+
+	if _, err := Parse([]byte("name: bad\nsteps:\n  - scene: main\n    actions:\n      - type: call\n")); err == nil {
+		t.Error("Parse() accepted call without a script name")
+	}
+}
+
+func TestRegion_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		region  Region
+		wantErr bool
+	}{
+		{"uniform", Region{Width: 10, Height: 10, Distribution: DistributionUniform}, false},
+		{"center", Region{Width: 10, Height: 10, Distribution: DistributionCenter}, false},
+		{"default distribution", Region{Width: 10, Height: 10}, false},
+		{"zero width", Region{Width: 0, Height: 10}, true},
+		{"negative height", Region{Width: 10, Height: -1}, true},
+		{"unknown distribution", Region{Width: 10, Height: 10, Distribution: "gaussian"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.region.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegion_Pick(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2)).Float64
+
+	for _, dist := range []Distribution{DistributionUniform, DistributionCenter} {
+		region := Region{X: 100, Y: 200, Width: 40, Height: 20, Distribution: dist}
+		var inner int
+		for i := 0; i < 1000; i++ {
+			p := region.Pick(rnd)
+			if p.X < 100 || p.X >= 140 || p.Y < 200 || p.Y >= 220 {
+				t.Fatalf("%s: Pick() = %+v, outside region", dist, p)
+			}
+			if p.X >= 110 && p.X < 130 && p.Y >= 205 && p.Y < 215 {
+				inner++
+			}
+		}
+		// The central quarter of the area holds ~25% of uniform samples
+		// and ~56% of center-weighted samples.
+		switch dist {
+		case DistributionUniform:
+			if inner < 180 || inner > 320 {
+				t.Errorf("uniform: %d/1000 samples in center quarter", inner)
+			}
+		case DistributionCenter:
+			if inner < 480 {
+				t.Errorf("center: %d/1000 samples in center quarter", inner)
+			}
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	j := Jitter{Pixels: 3, Wait: 0.2}
+	if err := j.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	for _, bad := range []Jitter{{Pixels: -1}, {Wait: 1}, {Wait: -0.1}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", bad)
+		}
+	}
+
+	rnd := rand.New(rand.NewPCG(1, 2)).Float64
+	for range 100 {
+		p := j.Offset(Point{X: 100, Y: 1}, 3, rnd)
+		if p.X < 97 || p.X > 103 || p.Y < 0 || p.Y > 4 {
+			t.Fatalf("Offset() = %+v, want within 3px of (100, 1) and not negative", p)
+		}
+		d := j.Duration(time.Second, rnd)
+		if d < 800*time.Millisecond || d > 1200*time.Millisecond {
+			t.Fatalf("Duration() = %v, want within 20%% of 1s", d)
+		}
+	}
+	if got := j.Offset(Point{X: 5, Y: 5}, 0, rnd); got != (Point{X: 5, Y: 5}) {
+		t.Errorf("Offset() with no pixels = %+v, want unchanged", got)
+	}
+
+	zero := 0.0
+	if got := (&Action{Jitter: &zero}).JitterPixels(j); got != 0 {
+		t.Errorf("JitterPixels() with action override = %v, want 0", got)
+	}
+	if got := (&Action{}).JitterPixels(j); got != 3 {
+		t.Errorf("JitterPixels() = %v, want script default 3", got)
+	}
+}
+
+func TestScript_Pace(t *testing.T) {
+	rnd := func() float64 { return 0.5 }
+	s := &Script{LagSlowdown: 2}
+
+	if got := s.Pace(time.Second, false, rnd); got != time.Second {
+		t.Errorf("Pace() = %v, want 1s when not lagging", got)
+	}
+	if got := s.Pace(time.Second, true, rnd); got != 2*time.Second {
+		t.Errorf("Pace() = %v, want 2s when lagging", got)
+	}
+	if got := (&Script{}).Pace(time.Second, true, rnd); got != time.Second {
+		t.Errorf("Pace() = %v, want 1s without lagSlowdown", got)
+	}
+
+	if err := (&Script{MatchMargin: -0.1}).Validate(); err == nil {
+		t.Error("Validate() should reject a negative matchMargin")
+	}
+	if err := (&Script{LagSlowdown: 0.5}).Validate(); err == nil {
+		t.Error("Validate() should reject lagSlowdown below 1")
+	}
+}
+
+func TestParse_Timeouts(t *testing.T) {
+	s, err := Parse([]byte(`name: farm
+timeout: 2h
+steps:
+  - label: reward
+    scene: reward
+    timeout: 5s
+    continueOnFailure: true
+    actions:
+      - type: wait
+        duration: 1s
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if s.Timeout != 2*time.Hour || s.Steps[0].Timeout != 5*time.Second || !s.Steps[0].ContinueOnFailure {
+		t.Errorf("Timeout = %v, step = %+v", s.Timeout, s.Steps[0])
+	}
+
+	if err := (&Script{Timeout: -time.Second}).Validate(); err == nil {
+		t.Error("Validate() should reject a negative script timeout")
+	}
+	if err := (&Script{Steps: []Step{{Timeout: -time.Second}}}).Validate(); err == nil {
+		t.Error("Validate() should reject a negative step timeout")
+	}
+}
+
+func TestParse_Limits(t *testing.T) {
+	s, err := Parse([]byte(`name: farm
+limits:
+  clicksPerMinute: 60
+  dragsPerMinute: 10
+steps:
+  - scene: main
+    actions:
+      - type: click
+        points: [{x: 1, y: 2}]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if s.Limits.PerMinute(ActionTypeClick) != 60 || s.Limits.PerMinute(ActionTypeDrag) != 10 ||
+		s.Limits.PerMinute(ActionTypeWait) != 0 {
+		t.Errorf("Limits = %+v", s.Limits)
+	}
+
+	if err := (&Script{Limits: Limits{DragsPerMinute: -1}}).Validate(); err == nil {
+		t.Error("Validate() should reject a negative limit")
+	}
+}
+
+func TestParse_SceneCategory(t *testing.T) {
+	s, err := Parse([]byte(`name: farm
+steps:
+  - sceneCategory: battle
+    actions:
+      - type: click
+        points: [{x: 1, y: 2}]
+  - scene: main
+    actions:
+      - type: wait
+        duration: 1s
+  - sceneCategory: battle
+    timeout: 5s
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if step := s.Steps[0]; step.SceneCategory != "battle" || step.ExpectedScene != "" || step.ScenePattern() != "battle/*" {
+		t.Errorf("step = %+v", step)
+	}
+	if got := s.SceneCategories(); len(got) != 1 || got[0] != "battle" {
+		t.Errorf("SceneCategories() = %v, want [battle]", got)
+	}
+	if got := s.Scenes(); len(got) != 1 || got[0] != "main" {
+		t.Errorf("Scenes() = %v, want [main]", got)
+	}
+
+	both := &Script{Steps: []Step{{ExpectedScene: "main", SceneCategory: "battle"}}}
+	if err := both.Validate(); err == nil {
+		t.Error("Validate() should reject a step with both scene and sceneCategory")
+	}
+}
+
+func TestParse_SendKeys(t *testing.T) {
+	s, err := Parse([]byte(`name: donate
+steps:
+  - scene: donate_dialog
+    actions:
+      - type: send_keys
+        points: [{x: 300, y: 200}]
+        text: "${amount}"
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	action := s.Steps[0].Actions[0]
+	if action.Type != ActionTypeSendKeys || action.Text != "${amount}" || len(action.Points) != 1 {
+		t.Errorf("action = %+v", action)
+	}
+
+	vars := map[string]int{"amount": 500}
+	params := map[string]string{"amount": "100", "name": "Hero"}
+	if got := action.ExpandText(vars, params); got != "500" {
+		t.Errorf("ExpandText() = %q, want the variable over the param", got)
+	}
+	action.Text = "hi ${name}${missing}"
+	if got := action.ExpandText(vars, params); got != "hi Hero" {
+		t.Errorf("ExpandText() = %q, want %q", got, "hi Hero")
+	}
+
+	bad := &Script{Steps: []Step{{Actions: []Action{{Type: ActionTypeSendKeys}}}}}
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate() should reject send_keys without text")
+	}
+}
+
+func TestParse_Scroll(t *testing.T) {
+	s, err := Parse([]byte(`name: rewards
+steps:
+  - scene: reward_list
+    actions:
+      - type: scroll
+        points: [{x: 540, y: 400}]
+        deltaY: 300
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	action := s.Steps[0].Actions[0]
+	if action.Type != ActionTypeScroll || action.DeltaX != 0 || action.DeltaY != 300 || len(action.Points) != 1 {
+		t.Errorf("action = %+v", action)
+	}
+
+	for _, bad := range []Action{
+		{Type: ActionTypeScroll, DeltaY: 100},
+		{Type: ActionTypeScroll, Points: []Point{{X: 1, Y: 1}}},
+	} {
+		s := &Script{Steps: []Step{{Actions: []Action{bad}}}}
+		if err := s.Validate(); err == nil {
+			t.Errorf("Validate() should reject %+v", bad)
+		}
+	}
+}
+
+func TestParse_SelectorActions(t *testing.T) {
+	s, err := Parse([]byte(`name: pick_server
+steps:
+  - scene: portal
+    actions:
+      - type: waitSelector
+        selector: "ul.server-list"
+        duration: 10s
+      - type: clickSelector
+        selector: "ul.server-list li[data-id='126']"
+onStop:
+  - type: clickSelector
+    selector: "#notice .close"
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	wait, click := s.Steps[0].Actions[0], s.Steps[0].Actions[1]
+	if wait.Type != ActionTypeWaitSelector || wait.Selector != "ul.server-list" || wait.Duration != 10*time.Second {
+		t.Errorf("wait = %+v", wait)
+	}
+	if click.Type != ActionTypeClickSelector || click.Selector != "ul.server-list li[data-id='126']" {
+		t.Errorf("click = %+v", click)
+	}
+	if got := wait.Describe(); got != "Wait up to 10s for `ul.server-list` to show" {
+		t.Errorf("Describe() = %q", got)
+	}
+
+	for _, bad := range []Action{
+		{Type: ActionTypeClickSelector},
+		{Type: ActionTypeWaitSelector},
+		{Type: ActionTypeWaitSelector, Selector: "#a", Duration: -time.Second},
+	} {
+		s := &Script{Steps: []Step{{Actions: []Action{bad}}}}
+		if err := s.Validate(); err == nil {
+			t.Errorf("Validate() should reject %+v", bad)
+		}
+	}
+	onStop := &Script{OnStop: []Action{{Type: ActionTypeWaitSelector, Selector: "#a"}}}
+	if err := onStop.Validate(); err == nil {
+		t.Error("Validate() should reject waitSelector in onStop")
+	}
+}
+
+func TestParse_Evaluate(t *testing.T) {
+	s, err := Parse([]byte(`name: canvas
+steps:
+  - scene: main_city
+    actions:
+      - type: evaluate
+        expression: "document.querySelector('canvas').width"
+        key: canvas_width
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	action := s.Steps[0].Actions[0]
+	if action.Type != ActionTypeEvaluate || action.Key != "canvas_width" || action.Expression != "document.querySelector('canvas').width" {
+		t.Errorf("action = %+v", action)
+	}
+	if got := action.Describe(); got != "Evaluate `document.querySelector('canvas').width` into `canvas_width`" {
+		t.Errorf("Describe() = %q", got)
+	}
+
+	bad := &Script{Steps: []Step{{Actions: []Action{{Type: ActionTypeEvaluate, Key: "w"}}}}}
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate() should reject evaluate without an expression")
+	}
+}
+
+func TestParse_OnStop(t *testing.T) {
+	s, err := Parse([]byte(`name: tower
+steps:
+  - scene: tower
+    actions:
+      - type: click
+        points: [{x: 1, y: 2}]
+onStop:
+  - type: click
+    points: [{x: 900, y: 40}]
+  - type: wait
+    duration: 1s
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(s.OnStop) != 2 || s.OnStop[0].Points[0] != (Point{X: 900, Y: 40}) || s.OnStop[1].Duration != time.Second {
+		t.Errorf("OnStop = %+v", s.OnStop)
+	}
+
+	for _, bad := range []Action{
+		{Type: ActionTypeQuit},
+		{Type: ActionTypeCall, Script: "home"},
+		{Type: ActionTypeSendKeys},
+	} {
+		s := &Script{OnStop: []Action{bad}}
+		if err := s.Validate(); err == nil {
+			t.Errorf("Validate() should reject onStop %+v", bad)
+		}
+	}
+}
+
+func TestAction_ClickPoint(t *testing.T) {
+	rnd := func() float64 { return 0.5 }
+
+	action := &Action{Points: []Point{{X: 1, Y: 2}}}
+	if p, ok := action.ClickPoint(rnd); !ok || p != (Point{X: 1, Y: 2}) {
+		t.Errorf("ClickPoint() = %+v, %v, want first point", p, ok)
+	}
+
+	action.Region = &Region{X: 10, Y: 20, Width: 10, Height: 10}
+	if p, ok := action.ClickPoint(rnd); !ok || p != (Point{X: 15, Y: 25}) {
+		t.Errorf("ClickPoint() = %+v, %v, want region center", p, ok)
+	}
+
+	if _, ok := (&Action{}).ClickPoint(rnd); ok {
+		t.Error("ClickPoint() should report false without points or region")
+	}
+}
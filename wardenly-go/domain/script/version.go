@@ -0,0 +1,176 @@
+package script
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrRevisionNotFound is returned when a requested script revision is not stored.
+var ErrRevisionNotFound = errors.New("script revision not found")
+
+// Revision is one stored version of a script's YAML source.
+type Revision struct {
+	Hash    string
+	SavedAt time.Time
+	Content []byte
+}
+
+// ShortHash returns an abbreviated hash for display.
+func (r Revision) ShortHash() string {
+	if len(r.Hash) > 8 {
+		return r.Hash[:8]
+	}
+	return r.Hash
+}
+
+// ContentHash returns the hex-encoded SHA-256 hash of script source.
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// VersionStore persists script revisions and the pinned (rolled back) revision.
+type VersionStore interface {
+	// Revisions returns all stored revisions of a script, newest first.
+	Revisions(name string) ([]Revision, error)
+
+	// Append stores a new revision as the newest one.
+	Append(name string, rev Revision) error
+
+	// Pinned returns the hash of the pinned revision, or "" when the
+	// latest revision is in use.
+	Pinned(name string) (string, error)
+
+	// SetPinned pins a revision by hash. An empty hash clears the pin.
+	SetPinned(name, hash string) error
+}
+
+// VersionService keeps a changelog of script sources and switches the
+// registry between stored revisions.
+type VersionService struct {
+	store    VersionStore
+	registry *Registry
+	now      func() time.Time
+}
+
+// NewVersionService creates a version service backed by the given store.
+func NewVersionService(store VersionStore, registry *Registry) *VersionService {
+	return &VersionService{
+		store:    store,
+		registry: registry,
+		now:      time.Now,
+	}
+}
+
+// Track records content as the newest revision of a script if it differs
+// from the last stored one. A new revision clears any rollback pin, since a
+// fresh edit supersedes it; otherwise a pinned revision is re-registered so
+// rollbacks survive restarts.
+func (s *VersionService) Track(name string, content []byte) error {
+	revisions, err := s.store.Revisions(name)
+	if err != nil {
+		return err
+	}
+
+	hash := ContentHash(content)
+	if len(revisions) == 0 || revisions[0].Hash != hash {
+		rev := Revision{Hash: hash, SavedAt: s.now(), Content: content}
+		if err := s.store.Append(name, rev); err != nil {
+			return err
+		}
+		return s.store.SetPinned(name, "")
+	}
+
+	pinned, err := s.store.Pinned(name)
+	if err != nil || pinned == "" || pinned == hash {
+		return err
+	}
+	return s.activate(name, revisions, pinned)
+}
+
+// TrackAll tracks every source, keyed by script name.
+func (s *VersionService) TrackAll(sources map[string][]byte) error {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if err := s.Track(name, sources[name]); err != nil {
+			errs = append(errs, fmt.Errorf("script %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Changelog returns the stored revisions of a script, newest first.
+func (s *VersionService) Changelog(name string) ([]Revision, error) {
+	return s.store.Revisions(name)
+}
+
+// Active returns the hash of the revision currently registered for a script.
+func (s *VersionService) Active(name string) (string, error) {
+	pinned, err := s.store.Pinned(name)
+	if err != nil || pinned != "" {
+		return pinned, err
+	}
+
+	revisions, err := s.store.Revisions(name)
+	if err != nil || len(revisions) == 0 {
+		return "", err
+	}
+	return revisions[0].Hash, nil
+}
+
+// Rollback registers a stored revision in place of the current one and pins
+// it. Sessions already running keep the script they started with.
+func (s *VersionService) Rollback(name, hash string) error {
+	revisions, err := s.store.Revisions(name)
+	if err != nil {
+		return err
+	}
+	if err := s.activate(name, revisions, hash); err != nil {
+		return err
+	}
+	return s.store.SetPinned(name, hash)
+}
+
+// UseLatest registers the newest revision of a script and clears its pin.
+func (s *VersionService) UseLatest(name string) error {
+	revisions, err := s.store.Revisions(name)
+	if err != nil {
+		return err
+	}
+	if len(revisions) == 0 {
+		return ErrRevisionNotFound
+	}
+	if err := s.activate(name, revisions, revisions[0].Hash); err != nil {
+		return err
+	}
+	return s.store.SetPinned(name, "")
+}
+
+// activate parses the revision with the given hash and registers it.
+func (s *VersionService) activate(name string, revisions []Revision, hash string) error {
+	for _, rev := range revisions {
+		if rev.Hash != hash {
+			continue
+		}
+		script, err := Parse(rev.Content)
+		if err != nil {
+			return err
+		}
+		if script.Name != name {
+			return fmt.Errorf("revision %s defines script %q, want %q", rev.ShortHash(), script.Name, name)
+		}
+		s.registry.Register(script)
+		return nil
+	}
+	return ErrRevisionNotFound
+}
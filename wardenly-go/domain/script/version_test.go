@@ -0,0 +1,127 @@
+package script
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// memoryVersionStore is an in-memory VersionStore for tests.
+type memoryVersionStore struct {
+	revisions map[string][]Revision // newest first
+	pinned    map[string]string
+}
+
+func newMemoryVersionStore() *memoryVersionStore {
+	return &memoryVersionStore{
+		revisions: make(map[string][]Revision),
+		pinned:    make(map[string]string),
+	}
+}
+
+func (m *memoryVersionStore) Revisions(name string) ([]Revision, error) {
+	return append([]Revision(nil), m.revisions[name]...), nil
+}
+
+func (m *memoryVersionStore) Append(name string, rev Revision) error {
+	m.revisions[name] = append([]Revision{rev}, m.revisions[name]...)
+	return nil
+}
+
+func (m *memoryVersionStore) Pinned(name string) (string, error) {
+	return m.pinned[name], nil
+}
+
+func (m *memoryVersionStore) SetPinned(name, hash string) error {
+	m.pinned[name] = hash
+	return nil
+}
+
+var (
+	daily1 = []byte("name: daily\ndescription: first\n")
+	daily2 = []byte("name: daily\ndescription: second\n")
+)
+
+func TestVersionService_Track(t *testing.T) {
+	store := newMemoryVersionStore()
+	svc := NewVersionService(store, NewRegistry())
+
+	if err := svc.Track("daily", daily1); err != nil {
+		t.Fatalf("Track() error = %v", err)
+	}
+	if err := svc.Track("daily", daily1); err != nil {
+		t.Fatalf("Track() error = %v", err)
+	}
+	if got := len(store.revisions["daily"]); got != 1 {
+		t.Fatalf("unchanged content stored %d revisions, want 1", got)
+	}
+
+	if err := svc.Track("daily", daily2); err != nil {
+		t.Fatalf("Track() error = %v", err)
+	}
+	log, _ := svc.Changelog("daily")
+	if len(log) != 2 || log[0].Hash != ContentHash(daily2) {
+		t.Fatalf("Changelog() = %d revisions, newest %q", len(log), log[0].ShortHash())
+	}
+}
+
+func TestVersionService_Rollback(t *testing.T) {
+	store := newMemoryVersionStore()
+	registry := NewRegistry()
+	svc := NewVersionService(store, registry)
+	svc.now = func() time.Time { return time.Unix(0, 0) }
+
+	svc.Track("daily", daily1)
+	svc.Track("daily", daily2)
+
+	if err := svc.Rollback("daily", ContentHash(daily1)); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if got := registry.Get("daily"); got == nil || got.Description != "first" {
+		t.Fatalf("registry script = %+v, want first revision", got)
+	}
+	if active, _ := svc.Active("daily"); active != ContentHash(daily1) {
+		t.Errorf("Active() = %q, want pinned revision", active)
+	}
+
+	// Restart with the same source keeps the rollback.
+	registry.Register(&Script{Name: "daily", Description: "second"})
+	svc.Track("daily", daily2)
+	if got := registry.Get("daily"); got.Description != "first" {
+		t.Errorf("pinned revision not restored after restart, got %q", got.Description)
+	}
+
+	if err := svc.UseLatest("daily"); err != nil {
+		t.Fatalf("UseLatest() error = %v", err)
+	}
+	if got := registry.Get("daily"); got.Description != "second" {
+		t.Errorf("UseLatest() registered %q, want second", got.Description)
+	}
+	if store.pinned["daily"] != "" {
+		t.Error("UseLatest() should clear the pin")
+	}
+}
+
+func TestVersionService_NewRevisionClearsPin(t *testing.T) {
+	store := newMemoryVersionStore()
+	svc := NewVersionService(store, NewRegistry())
+
+	svc.Track("daily", daily1)
+	svc.Track("daily", daily2)
+	svc.Rollback("daily", ContentHash(daily1))
+
+	daily3 := []byte("name: daily\ndescription: third\n")
+	svc.Track("daily", daily3)
+	if active, _ := svc.Active("daily"); active != ContentHash(daily3) {
+		t.Error("a new revision should supersede the rollback")
+	}
+}
+
+func TestVersionService_RollbackUnknown(t *testing.T) {
+	svc := NewVersionService(newMemoryVersionStore(), NewRegistry())
+	svc.Track("daily", daily1)
+
+	if err := svc.Rollback("daily", "missing"); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("Rollback() error = %v, want ErrRevisionNotFound", err)
+	}
+}
@@ -0,0 +1,61 @@
+package script
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay coalesces bursts of file events; editors often write a file
+// several times when saving.
+const reloadDelay = 300 * time.Millisecond
+
+// WatchDir reloads dir with LoadDir whenever a YAML file in it changes,
+// until ctx is done. Call LoadDir first for the initial load. onReload runs
+// on the watcher goroutine after each reload with LoadDir's error.
+func (l *Loader) WatchDir(ctx context.Context, dir string, onReload func(err error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var reload <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Ext(ev.Name) == ".yaml" {
+					reload = time.After(reloadDelay)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				// Events may have been lost (e.g. queue overflow); rescan
+				reload = time.After(reloadDelay)
+			case <-reload:
+				reload = nil
+				err := l.LoadDir(dir)
+				if onReload != nil {
+					onReload(err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,133 @@
+// Package api provides an optional HTTP API for controlling sessions
+// remotely, e.g. from external tooling or a phone browser when the desktop
+// UI is out of reach.
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"wardenly-go/infrastructure/httpguard"
+)
+
+// Environment variables overriding the api section of the config file.
+const (
+	EnvAddr  = "WARDENLY_API_ADDR"
+	EnvToken = "WARDENLY_API_TOKEN"
+)
+
+// Errors a Controller wraps so the API can answer with the right status.
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrForbidden = errors.New("forbidden")
+	ErrConflict  = errors.New("conflict")
+)
+
+// SessionInfo describes a running session.
+type SessionInfo struct {
+	ID             string `json:"id"`
+	Account        string `json:"account"`
+	State          string `json:"state"`
+	SelectedScript string `json:"selectedScript,omitempty"`
+	ScriptRunning  bool   `json:"scriptRunning"`
+}
+
+// AccountInfo describes an account that can be started.
+type AccountInfo struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Running bool   `json:"running"`
+}
+
+// Controller is the application surface exposed by the API.
+type Controller interface {
+	// Sessions returns all sessions.
+	Sessions() []SessionInfo
+	// Session returns one session or an error wrapping ErrNotFound.
+	Session(id string) (SessionInfo, error)
+	// Accounts returns the stored accounts.
+	Accounts(ctx context.Context) ([]AccountInfo, error)
+	// Scripts returns the names of the available scripts.
+	Scripts() []string
+
+	// StartSession starts a session for a stored account.
+	StartSession(ctx context.Context, accountID string) error
+	// StopSession stops a session.
+	StopSession(id string) error
+	// StartScript starts a script; an empty name runs the selected script.
+	StartScript(id, scriptName string, params map[string]string) error
+	// StopScript stops the running script of a session.
+	StopScript(id string) error
+	// CaptureScreen returns the current screen of a session.
+	CaptureScreen(ctx context.Context, id string) (image.Image, error)
+}
+
+// Config holds API server configuration.
+type Config struct {
+	// Addr is the listen address (e.g. "localhost:8631"). Empty disables the API.
+	Addr string
+	// Token is the bearer token clients must send. It may be empty only
+	// when Addr is a loopback address.
+	Token  string
+	Logger *slog.Logger
+}
+
+// Enabled reports whether the API should be started.
+func (c *Config) Enabled() bool {
+	return c != nil && c.Addr != ""
+}
+
+// Server serves the API until stopped.
+type Server struct {
+	server *http.Server
+	logger *slog.Logger
+}
+
+// Start listens on cfg.Addr and serves the API in the background.
+// Stop must be called to release the listener.
+func Start(cfg *Config, ctrl Controller) (*Server, error) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.Token == "" && !httpguard.IsLoopback(cfg.Addr) {
+		return nil, fmt.Errorf("%s is required when the API listens on %s", EnvToken, cfg.Addr)
+	}
+
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", cfg.Addr, err)
+	}
+
+	s := &Server{
+		server: &http.Server{
+			Handler:           NewHandler(ctrl, cfg.Token, cfg.Logger),
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		logger: cfg.Logger,
+	}
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("API server failed", "error", err)
+		}
+	}()
+
+	s.logger.Info("Remote API listening", "addr", ln.Addr().String(), "auth", cfg.Token != "")
+	return s, nil
+}
+
+// Stop shuts the server down, waiting briefly for requests in progress.
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.logger.Warn("API server shutdown failed", "error", err)
+	}
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeController records calls and serves one session "s1".
+type fakeController struct {
+	started  []string
+	scripts  map[string]string
+	stopped  []string
+	refusals map[string]bool
+}
+
+func newFakeController() *fakeController {
+	return &fakeController{scripts: make(map[string]string), refusals: make(map[string]bool)}
+}
+
+func (f *fakeController) Sessions() []SessionInfo {
+	return []SessionInfo{{ID: "s1", Account: "1 - Hero", State: "Ready"}}
+}
+
+func (f *fakeController) Session(id string) (SessionInfo, error) {
+	if id != "s1" {
+		return SessionInfo{}, fmt.Errorf("%w: session %s", ErrNotFound, id)
+	}
+	return f.Sessions()[0], nil
+}
+
+func (f *fakeController) Accounts(ctx context.Context) ([]AccountInfo, error) {
+	return []AccountInfo{{ID: "s1", Name: "1 - Hero", Running: true}}, nil
+}
+
+func (f *fakeController) Scripts() []string { return []string{"daily"} }
+
+func (f *fakeController) StartSession(ctx context.Context, accountID string) error {
+	if accountID == "s1" {
+		return fmt.Errorf("%w: already running", ErrConflict)
+	}
+	f.started = append(f.started, accountID)
+	return nil
+}
+
+func (f *fakeController) StopSession(id string) error {
+	if _, err := f.Session(id); err != nil {
+		return err
+	}
+	f.stopped = append(f.stopped, id)
+	return nil
+}
+
+func (f *fakeController) StartScript(id, scriptName string, params map[string]string) error {
+	if f.refusals[scriptName] {
+		return fmt.Errorf("%w: script %s is blocked", ErrForbidden, scriptName)
+	}
+	f.scripts[id] = scriptName
+	return nil
+}
+
+func (f *fakeController) StopScript(id string) error { return nil }
+
+func (f *fakeController) CaptureScreen(ctx context.Context, id string) (image.Image, error) {
+	if _, err := f.Session(id); err != nil {
+		return nil, err
+	}
+	return image.NewRGBA(image.Rect(0, 0, 4, 3)), nil
+}
+
+func do(t *testing.T, h http.Handler, method, target, body string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_Sessions(t *testing.T) {
+	h := NewHandler(newFakeController(), "", nil)
+
+	rec := do(t, h, "GET", "/api/v1/sessions", "")
+	var sessions []SessionInfo
+	if err := json.NewDecoder(rec.Body).Decode(&sessions); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET sessions = %d, %v", rec.Code, err)
+	}
+	if len(sessions) != 1 || sessions[0].State != "Ready" {
+		t.Errorf("sessions = %+v", sessions)
+	}
+
+	if rec := do(t, h, "GET", "/api/v1/sessions/nope", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET unknown session = %d, want 404", rec.Code)
+	}
+}
+
+func TestHandler_StartSession(t *testing.T) {
+	ctrl := newFakeController()
+	h := NewHandler(ctrl, "", nil)
+
+	if rec := do(t, h, "POST", "/api/v1/sessions", `{"accountId":"a2"}`); rec.Code != http.StatusAccepted {
+		t.Errorf("POST session = %d, want 202", rec.Code)
+	}
+	if len(ctrl.started) != 1 || ctrl.started[0] != "a2" {
+		t.Errorf("started = %v, want [a2]", ctrl.started)
+	}
+
+	if rec := do(t, h, "POST", "/api/v1/sessions", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST without account = %d, want 400", rec.Code)
+	}
+	if rec := do(t, h, "POST", "/api/v1/sessions", `{"accountId":"s1"}`); rec.Code != http.StatusConflict {
+		t.Errorf("POST running account = %d, want 409", rec.Code)
+	}
+}
+
+func TestHandler_StartScript(t *testing.T) {
+	ctrl := newFakeController()
+	ctrl.refusals["arena"] = true
+	h := NewHandler(ctrl, "", nil)
+
+	if rec := do(t, h, "POST", "/api/v1/sessions/s1/script", `{"name":"daily"}`); rec.Code != http.StatusAccepted {
+		t.Errorf("POST script = %d, want 202", rec.Code)
+	}
+	if ctrl.scripts["s1"] != "daily" {
+		t.Errorf("script = %q, want daily", ctrl.scripts["s1"])
+	}
+
+	// No body runs the selected script
+	if rec := do(t, h, "POST", "/api/v1/sessions/s1/script", ""); rec.Code != http.StatusAccepted {
+		t.Errorf("POST script without body = %d, want 202", rec.Code)
+	}
+
+	if rec := do(t, h, "POST", "/api/v1/sessions/s1/script", `{"name":"arena"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("POST refused script = %d, want 403", rec.Code)
+	}
+}
+
+func TestHandler_Screenshot(t *testing.T) {
+	h := NewHandler(newFakeController(), "", nil)
+
+	rec := do(t, h, "GET", "/api/v1/sessions/s1/screenshot", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("GET screenshot = %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	img, err := png.Decode(rec.Body)
+	if err != nil || img.Bounds().Dx() != 4 {
+		t.Errorf("screenshot decode = %v, %v", img, err)
+	}
+
+	rec = do(t, h, "GET", "/api/v1/sessions/s1/screenshot?format=jpeg", "")
+	if rec.Header().Get("Content-Type") != "image/jpeg" {
+		t.Errorf("jpeg screenshot Content-Type = %s", rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestHandler_RejectsCrossSite(t *testing.T) {
+	ctrl := newFakeController()
+	h := NewHandler(ctrl, "", nil)
+
+	// A form a foreign page posts to the loopback address
+	rec := do(t, h, "POST", "/api/v1/sessions", `{"accountId":"a2"}`, "Content-Type", "text/plain")
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain POST = %d, want 415", rec.Code)
+	}
+	rec = do(t, h, "POST", "/api/v1/sessions/s1/script", "", "Origin", "https://evil.example")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("cross-origin POST = %d, want 403", rec.Code)
+	}
+	if len(ctrl.started) != 0 || ctrl.scripts["s1"] != "" {
+		t.Errorf("refused requests reached the controller: %v %v", ctrl.started, ctrl.scripts)
+	}
+
+	rec = do(t, h, "POST", "/api/v1/sessions", `{"accountId":"a2"}`, "Origin", "http://localhost:8631")
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("same-machine POST = %d, want 202", rec.Code)
+	}
+}
+
+func TestHandler_Token(t *testing.T) {
+	h := NewHandler(newFakeController(), "secret", nil)
+
+	if rec := do(t, h, "GET", "/api/v1/sessions", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("no token = %d, want 401", rec.Code)
+	}
+	if rec := do(t, h, "GET", "/api/v1/sessions", "", "Authorization", "Bearer wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token = %d, want 401", rec.Code)
+	}
+	if rec := do(t, h, "GET", "/api/v1/sessions", "", "Authorization", "Bearer secret"); rec.Code != http.StatusOK {
+		t.Errorf("bearer token = %d, want 200", rec.Code)
+	}
+	if rec := do(t, h, "GET", "/api/v1/sessions?token=secret", ""); rec.Code != http.StatusOK {
+		t.Errorf("query token = %d, want 200", rec.Code)
+	}
+}
+
+func TestStart_RequiresTokenOffLoopback(t *testing.T) {
+	if _, err := Start(&Config{Addr: "0.0.0.0:0"}, newFakeController()); err == nil {
+		t.Error("Start() without token on a public address should fail")
+	}
+
+	s, err := Start(&Config{Addr: "127.0.0.1:0"}, newFakeController())
+	if err != nil {
+		t.Fatalf("Start() on loopback error = %v", err)
+	}
+	s.Stop()
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"image/jpeg"
+	"image/png"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"wardenly-go/infrastructure/httpguard"
+)
+
+// NewHandler returns the API routes. When token is non-empty, every request
+// must carry it as "Authorization: Bearer <token>" or a "token" query
+// parameter (for links opened in a browser). Without a token, requests
+// from pages of other origins are refused, and request bodies must be JSON
+// either way, so a web page can't drive the API through the user's browser.
+func NewHandler(ctrl Controller, token string, logger *slog.Logger) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	h := &handler{ctrl: ctrl, logger: logger}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/sessions", h.listSessions)
+	mux.HandleFunc("POST /api/v1/sessions", h.startSession)
+	mux.HandleFunc("GET /api/v1/sessions/{id}", h.getSession)
+	mux.HandleFunc("DELETE /api/v1/sessions/{id}", h.stopSession)
+	mux.HandleFunc("POST /api/v1/sessions/{id}/script", h.startScript)
+	mux.HandleFunc("DELETE /api/v1/sessions/{id}/script", h.stopScript)
+	mux.HandleFunc("GET /api/v1/sessions/{id}/screenshot", h.screenshot)
+	mux.HandleFunc("GET /api/v1/accounts", h.listAccounts)
+	mux.HandleFunc("GET /api/v1/scripts", h.listScripts)
+
+	if token == "" {
+		return requireLocalOrigin(requireJSON(mux))
+	}
+	return requireToken(token, requireJSON(mux))
+}
+
+// requireLocalOrigin refuses requests from pages of other origins.
+func requireLocalOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !httpguard.LocalOrigin(r) {
+			writeError(w, http.StatusForbidden, errors.New("cross-origin request refused"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireJSON refuses request bodies that aren't JSON; browsers send forms
+// cross-site without asking first.
+func requireJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != 0 {
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, errors.New("expected Content-Type: application/json"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireToken rejects requests that don't carry the token.
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !httpguard.Authorized(r, token) {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type handler struct {
+	ctrl   Controller
+	logger *slog.Logger
+}
+
+func (h *handler) listSessions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.ctrl.Sessions())
+}
+
+func (h *handler) getSession(w http.ResponseWriter, r *http.Request) {
+	info, err := h.ctrl.Session(r.PathValue("id"))
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (h *handler) startSession(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AccountID string `json:"accountId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AccountID == "" {
+		writeError(w, http.StatusBadRequest, errors.New(`expected {"accountId": "..."}`))
+		return
+	}
+	if err := h.ctrl.StartSession(r.Context(), req.AccountID); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"id": req.AccountID})
+}
+
+func (h *handler) stopSession(w http.ResponseWriter, r *http.Request) {
+	if err := h.ctrl.StopSession(r.PathValue("id")); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *handler) startScript(w http.ResponseWriter, r *http.Request) {
+	// The body is optional; without it the selected script runs
+	var req struct {
+		Name   string            `json:"name"`
+		Params map[string]string `json:"params"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, errors.New(`expected {"name": "...", "params": {...}}`))
+			return
+		}
+	}
+	if err := h.ctrl.StartScript(r.PathValue("id"), req.Name, req.Params); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *handler) stopScript(w http.ResponseWriter, r *http.Request) {
+	if err := h.ctrl.StopScript(r.PathValue("id")); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// screenshot returns the current screen as PNG, or JPEG with ?format=jpeg
+// for smaller downloads on a phone.
+func (h *handler) screenshot(w http.ResponseWriter, r *http.Request) {
+	img, err := h.ctrl.CaptureScreen(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if r.URL.Query().Get("format") == "jpeg" {
+		w.Header().Set("Content-Type", "image/jpeg")
+		err = jpeg.Encode(w, img, &jpeg.Options{Quality: 80})
+	} else {
+		w.Header().Set("Content-Type", "image/png")
+		err = png.Encode(w, img)
+	}
+	if err != nil {
+		h.logger.Warn("Failed to write screenshot", "error", err)
+	}
+}
+
+func (h *handler) listAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := h.ctrl.Accounts(r.Context())
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, accounts)
+}
+
+func (h *handler) listScripts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.ctrl.Scripts())
+}
+
+// fail maps a controller error to a status code.
+func (h *handler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, ErrConflict):
+		status = http.StatusConflict
+	default:
+		h.logger.Warn("API request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+	}
+	writeError(w, status, err)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
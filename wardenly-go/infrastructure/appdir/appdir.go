@@ -0,0 +1,34 @@
+// Package appdir locates the per-user directory wardenly keeps its
+// settings, data and logs in.
+package appdir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Lookup returns sub in the wardenly directory of os.UserConfigDir, or of
+// os.UserCacheDir when there is no config directory. It fails when there is
+// neither, for files that must not silently move between runs.
+func Lookup(sub string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		var cacheErr error
+		dir, cacheErr = os.UserCacheDir()
+		if cacheErr != nil {
+			return "", fmt.Errorf("no user directory for %s: %w", sub, err)
+		}
+	}
+	return filepath.Join(dir, "wardenly", sub), nil
+}
+
+// Path is Lookup falling back to os.TempDir, for files that can be
+// recreated when lost.
+func Path(sub string) string {
+	path, err := Lookup(sub)
+	if err != nil {
+		return filepath.Join(os.TempDir(), "wardenly", sub)
+	}
+	return path
+}
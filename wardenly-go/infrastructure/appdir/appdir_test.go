@@ -0,0 +1,35 @@
+package appdir
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLookup_FallsBackToCacheDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG variables only apply on Linux")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+
+	if got, err := Lookup("logs"); err != nil || got != filepath.Join(home, "config", "wardenly", "logs") {
+		t.Errorf("Lookup() = %q, %v; want the config dir", got, err)
+	}
+
+	t.Setenv("HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	if got, err := Lookup("logs"); err != nil || got != filepath.Join(home, "cache", "wardenly", "logs") {
+		t.Errorf("Lookup() = %q, %v; want the cache dir", got, err)
+	}
+
+	t.Setenv("XDG_CACHE_HOME", "")
+	if _, err := Lookup("secret.key"); err == nil {
+		t.Error("Lookup() succeeded without a user directory")
+	}
+	if got := Path("logs"); filepath.Base(filepath.Dir(got)) != "wardenly" {
+		t.Errorf("Path() = %q, want a temp dir fallback", got)
+	}
+}
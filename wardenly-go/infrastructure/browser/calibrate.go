@@ -0,0 +1,168 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// LoginField is a login form element captured by CalibrateLogin.
+type LoginField int
+
+// Login fields in the order they are captured.
+const (
+	LoginFieldUsername LoginField = iota
+	LoginFieldPassword
+	LoginFieldSubmit
+)
+
+// loginFields lists the fields captured by CalibrateLogin, in order.
+var loginFields = []LoginField{LoginFieldUsername, LoginFieldPassword, LoginFieldSubmit}
+
+// String returns the field as shown to the user.
+func (f LoginField) String() string {
+	switch f {
+	case LoginFieldUsername:
+		return "username field"
+	case LoginFieldPassword:
+		return "password field"
+	case LoginFieldSubmit:
+		return "login button"
+	default:
+		return fmt.Sprintf("LoginField(%d)", int(f))
+	}
+}
+
+// set stores the selector of a captured field.
+func (p *LoginProfile) set(f LoginField, selector string) {
+	switch f {
+	case LoginFieldUsername:
+		p.Username = selector
+	case LoginFieldPassword:
+		p.Password = selector
+	case LoginFieldSubmit:
+		p.Submit = selector
+	}
+}
+
+// calibrationBinding is the page function that reports a clicked element.
+const calibrationBinding = "wardenlyCalibrate"
+
+// calibrationScript reports the selector of every clicked element through
+// calibrationBinding and swallows the click, so pressing the login button
+// doesn't submit the empty form. The selector is the element's id or name
+// when unique, otherwise the shortest unique path of nth-of-type steps,
+// anchored at the nearest ancestor with an id.
+const calibrationScript = `(() => {
+	if (window.__wardenlyCalibrating) return;
+	window.__wardenlyCalibrating = true;
+
+	const unique = (sel) => {
+		try { return document.querySelectorAll(sel).length === 1; } catch (e) { return false; }
+	};
+	const selectorOf = (el) => {
+		if (el.id && unique('#' + CSS.escape(el.id))) return '#' + CSS.escape(el.id);
+		const name = el.getAttribute('name');
+		if (name) {
+			const sel = el.tagName.toLowerCase() + '[name="' + CSS.escape(name) + '"]';
+			if (unique(sel)) return sel;
+		}
+		const parts = [];
+		for (let e = el; e && e.nodeType === 1 && e !== document.documentElement; e = e.parentElement) {
+			if (e !== el && e.id) {
+				parts.unshift('#' + CSS.escape(e.id));
+				break;
+			}
+			let part = e.tagName.toLowerCase();
+			const siblings = e.parentElement ? Array.from(e.parentElement.children).filter((c) => c.tagName === e.tagName) : [];
+			if (siblings.length > 1) part += ':nth-of-type(' + (siblings.indexOf(e) + 1) + ')';
+			parts.unshift(part);
+			if (unique(parts.join(' > '))) break;
+		}
+		return parts.join(' > ');
+	};
+
+	document.addEventListener('click', (ev) => {
+		ev.preventDefault();
+		ev.stopPropagation();
+		const el = ev.target.closest('input, button, a, select, textarea') || ev.target;
+		window.` + calibrationBinding + `(selectorOf(el));
+	}, true);
+})();`
+
+// CalibrateLogin opens the login page in a visible browser and lets the user
+// click the username field, password field, and login button in turn. The
+// selectors of the clicked elements are captured through a CDP binding and
+// returned with the rest of config.Login. onStep is called before each field
+// is awaited. It returns when all fields are captured, ctx is done, or the
+// browser window is closed.
+func CalibrateLogin(ctx context.Context, config *DriverConfig, url string, onStep func(LoginField)) (LoginProfile, error) {
+	cfg := DefaultDriverConfig()
+	if config != nil {
+		c := *config
+		cfg = &c
+	}
+	cfg.Headless = false
+
+	d := NewChromeDPDriver(cfg)
+	if err := d.Start(ctx); err != nil {
+		return LoginProfile{}, fmt.Errorf("failed to start browser: %w", err)
+	}
+	defer d.Stop()
+
+	browserCtx := d.Context()
+	selectors := make(chan string, 8)
+	chromedp.ListenTarget(browserCtx, func(ev interface{}) {
+		if ev, ok := ev.(*runtime.EventBindingCalled); ok && ev.Name == calibrationBinding {
+			select {
+			case selectors <- ev.Payload:
+			default:
+			}
+		}
+	})
+
+	// The first Run launches the browser, so it must use the browser
+	// context itself rather than a derived one that is cancelled
+	err := chromedp.Run(browserCtx,
+		runtime.AddBinding(calibrationBinding),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			_, err := page.AddScriptToEvaluateOnNewDocument(calibrationScript).Do(ctx)
+			return err
+		}),
+	)
+	if err != nil {
+		return LoginProfile{}, fmt.Errorf("failed to prepare calibration: %w", err)
+	}
+
+	navCtx, cancel, err := d.opContext(ctx, cfg.Timeouts.Navigation)
+	if err != nil {
+		return LoginProfile{}, err
+	}
+	err = chromedp.Run(navCtx,
+		chromedp.EmulateViewport(1080, 720, chromedp.EmulateScale(1)),
+		chromedp.Navigate(url),
+	)
+	cancel()
+	if err != nil {
+		return LoginProfile{}, fmt.Errorf("start page failure: %w", err)
+	}
+
+	profile := cfg.Login
+	for _, field := range loginFields {
+		if onStep != nil {
+			onStep(field)
+		}
+		select {
+		case selector := <-selectors:
+			profile.set(field, selector)
+		case <-browserCtx.Done():
+			return LoginProfile{}, fmt.Errorf("browser closed before the %s was clicked", field)
+		case <-ctx.Done():
+			return LoginProfile{}, ctx.Err()
+		}
+	}
+	return profile, nil
+}
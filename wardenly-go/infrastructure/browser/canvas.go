@@ -0,0 +1,73 @@
+package browser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CanvasSize is a viewport size the game can run at.
+type CanvasSize struct {
+	Width  int
+	Height int
+}
+
+// BaseCanvas is the viewport size scene and script coordinates are
+// recorded at.
+var BaseCanvas = CanvasSize{Width: 1080, Height: 720}
+
+// CanvasPresets lists canvas sizes matching common game resolutions.
+var CanvasPresets = []CanvasSize{
+	{Width: 960, Height: 540},
+	{Width: 1080, Height: 720},
+	{Width: 1280, Height: 720},
+	{Width: 1600, Height: 900},
+}
+
+// IsZero returns true if no size is set.
+func (s CanvasSize) IsZero() bool {
+	return s.Width <= 0 || s.Height <= 0
+}
+
+// String formats the size as "WIDTHxHEIGHT".
+func (s CanvasSize) String() string {
+	return fmt.Sprintf("%dx%d", s.Width, s.Height)
+}
+
+// ParseCanvasSize parses a "WIDTHxHEIGHT" size, e.g. "1280x720".
+func ParseCanvasSize(text string) (CanvasSize, error) {
+	w, h, ok := strings.Cut(strings.ToLower(strings.TrimSpace(text)), "x")
+	if ok {
+		width, errW := strconv.Atoi(strings.TrimSpace(w))
+		height, errH := strconv.Atoi(strings.TrimSpace(h))
+		if errW == nil && errH == nil && width > 0 && height > 0 {
+			return CanvasSize{Width: width, Height: height}, nil
+		}
+	}
+	return CanvasSize{}, fmt.Errorf("invalid canvas size %q, want WIDTHxHEIGHT", text)
+}
+
+// Scale returns the factors that map BaseCanvas coordinates to this size.
+// Each axis is scaled on its own, as the game stretches to the viewport.
+// A zero size scales by 1.
+func (s CanvasSize) Scale() Point {
+	if s.IsZero() {
+		return Point{X: 1, Y: 1}
+	}
+	return Point{
+		X: float64(s.Width) / float64(BaseCanvas.Width),
+		Y: float64(s.Height) / float64(BaseCanvas.Height),
+	}
+}
+
+// Apply sets the viewport of config to the size, resizing the window with
+// it so the browser chrome margin is kept.
+func (s CanvasSize) Apply(config *DriverConfig) {
+	if s.IsZero() {
+		return
+	}
+	config.WindowWidth += s.Width - config.ViewportWidth
+	config.WindowHeight += s.Height - config.ViewportHeight
+	config.ViewportWidth = s.Width
+	config.ViewportHeight = s.Height
+}
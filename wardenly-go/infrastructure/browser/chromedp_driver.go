@@ -4,18 +4,26 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"image"
 	"image/jpeg"
 	"image/png"
 	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/fetch"
 	"github.com/chromedp/cdproto/input"
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/cdproto/storage"
 	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/kb"
 )
 
 // ChromeDPDriver implements Driver using chromedp.
@@ -39,11 +47,34 @@ func NewChromeDPDriver(config *DriverConfig) *ChromeDPDriver {
 	if config == nil {
 		config = DefaultDriverConfig()
 	}
+	config = config.withDefaults()
 	return &ChromeDPDriver{
 		config: config,
 	}
 }
 
+// opContext returns a context for a single browser operation.
+// It derives from the browser context, applies the operation class timeout,
+// and is cancelled when the caller's ctx is done so a wedged CDP call
+// cannot outlive a stopping session.
+func (d *ChromeDPDriver) opContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	d.mu.Lock()
+	browserCtx := d.ctx
+	running := d.running
+	d.mu.Unlock()
+
+	if !running || browserCtx == nil {
+		return nil, nil, fmt.Errorf("browser not running")
+	}
+
+	execCtx, cancel := linkContext(ctx, browserCtx, timeout)
+	return execCtx, cancel, nil
+}
+
 // buildExecAllocatorOptions builds chromedp options from config.
 func (d *ChromeDPDriver) buildExecAllocatorOptions() []chromedp.ExecAllocatorOption {
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
@@ -61,6 +92,10 @@ func (d *ChromeDPDriver) buildExecAllocatorOptions() []chromedp.ExecAllocatorOpt
 		opts = append(opts, chromedp.UserDataDir(d.config.UserDataDir))
 	}
 
+	if d.config.Proxy != nil {
+		opts = append(opts, chromedp.ProxyServer(d.config.Proxy.Server()))
+	}
+
 	return opts
 }
 
@@ -84,9 +119,58 @@ func (d *ChromeDPDriver) Start(ctx context.Context) error {
 	d.ctx, d.cancel = chromedp.NewContext(d.allocCtx)
 
 	d.running = true
+
+	if d.config.Proxy != nil && d.config.Proxy.HasCredentials() {
+		if err := d.enableProxyAuth(); err != nil {
+			d.cleanup()
+			return fmt.Errorf("failed to set up proxy authentication: %w", err)
+		}
+	}
+
+	// Like enableProxyAuth, this may launch the browser, so it runs on the
+	// browser context itself
+	if len(d.config.BlockedURLs) > 0 {
+		if err := chromedp.Run(d.ctx, network.Enable(), network.SetBlockedURLs(d.config.BlockedURLs)); err != nil {
+			d.cleanup()
+			return fmt.Errorf("failed to block URLs: %w", err)
+		}
+	}
 	return nil
 }
 
+// enableProxyAuth answers proxy credential challenges. Chrome takes no
+// credentials on the command line, so requests are intercepted with the
+// Fetch domain, which pauses each request until it is continued.
+// This launches the browser; the first Run must use the browser context
+// itself, since cancelling a derived context would close the browser.
+func (d *ChromeDPDriver) enableProxyAuth() error {
+	browserCtx := d.ctx
+	proxy := d.config.Proxy
+
+	chromedp.ListenTarget(browserCtx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *fetch.EventRequestPaused:
+			go func() {
+				_ = chromedp.Run(browserCtx, fetch.ContinueRequest(ev.RequestID))
+			}()
+		case *fetch.EventAuthRequired:
+			response := &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseDefault}
+			if ev.AuthChallenge != nil && ev.AuthChallenge.Source == fetch.AuthChallengeSourceProxy {
+				response = &fetch.AuthChallengeResponse{
+					Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+					Username: proxy.Username,
+					Password: proxy.Password,
+				}
+			}
+			go func() {
+				_ = chromedp.Run(browserCtx, fetch.ContinueWithAuth(ev.RequestID, response))
+			}()
+		}
+	})
+
+	return chromedp.Run(browserCtx, fetch.Enable().WithHandleAuthRequests(true))
+}
+
 // Stop closes the browser and releases resources.
 func (d *ChromeDPDriver) Stop() error {
 	d.mu.Lock()
@@ -126,69 +210,78 @@ func (d *ChromeDPDriver) IsRunning() bool {
 	return d.running
 }
 
-// Navigate navigates to the specified URL.
-func (d *ChromeDPDriver) Navigate(ctx context.Context, url string) error {
+// Done returns the browser context's Done channel. chromedp cancels the
+// context when its connection to Chrome drops, so the channel also closes
+// when the browser process dies.
+func (d *ChromeDPDriver) Done() <-chan struct{} {
 	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
+	defer d.mu.Unlock()
+	if !d.running || d.ctx == nil {
+		return nil
+	}
+	return d.ctx.Done()
+}
 
-	if !running || browserCtx == nil {
-		return fmt.Errorf("browser not running")
+// Navigate navigates to the specified URL.
+func (d *ChromeDPDriver) Navigate(ctx context.Context, url string) error {
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Navigation)
+	if err != nil {
+		return err
 	}
+	defer cancel()
 
-	return chromedp.Run(browserCtx, chromedp.Navigate(url))
+	return chromedp.Run(execCtx, chromedp.Navigate(url))
 }
 
 // Reload refreshes the current page.
 func (d *ChromeDPDriver) Reload(ctx context.Context) error {
-	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
-
-	if !running || browserCtx == nil {
-		return fmt.Errorf("browser not running")
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Navigation)
+	if err != nil {
+		return err
 	}
+	defer cancel()
 
-	return chromedp.Run(browserCtx, chromedp.Reload())
+	return chromedp.Run(execCtx, chromedp.Reload())
 }
 
 // Click performs a mouse click at the specified coordinates.
 func (d *ChromeDPDriver) Click(ctx context.Context, x, y float64) error {
-	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
-
-	if !running || browserCtx == nil {
-		return fmt.Errorf("browser not running")
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
 	}
-
-	// Add timeout protection
-	timeoutCtx, cancel := context.WithTimeout(browserCtx, 5*time.Second)
 	defer cancel()
 
-	return chromedp.Run(timeoutCtx,
+	return chromedp.Run(execCtx,
 		chromedp.MouseClickXY(x, y, chromedp.ButtonLeft),
 	)
 }
 
+// Scroll dispatches a mouse wheel event at (x, y).
+func (d *ChromeDPDriver) Scroll(ctx context.Context, x, y, deltaX, deltaY float64) error {
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return chromedp.Run(execCtx,
+		input.DispatchMouseEvent(input.MouseWheel, x, y).WithDeltaX(deltaX).WithDeltaY(deltaY),
+	)
+}
+
 // Drag performs a mouse drag from one point to another.
 // It interpolates intermediate points for smooth, realistic dragging.
 func (d *ChromeDPDriver) Drag(ctx context.Context, fromX, fromY, toX, toY float64) error {
-	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
+	const steps = DragSteps
 
-	if !running || browserCtx == nil {
-		return fmt.Errorf("browser not running")
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Input+DragPacing(steps))
+	if err != nil {
+		return err
 	}
+	defer cancel()
 
-	return chromedp.Run(browserCtx, chromedp.ActionFunc(func(ctx context.Context) error {
-		const frameInterval = time.Second / 60 // ~16.67ms per frame
-
+	return chromedp.Run(execCtx, chromedp.ActionFunc(func(ctx context.Context) error {
 		// Press at start position
 		p := &input.DispatchMouseEventParams{
 			Type:       input.MousePressed,
@@ -201,8 +294,7 @@ func (d *ChromeDPDriver) Drag(ctx context.Context, fromX, fromY, toX, toY float6
 			return err
 		}
 
-		// Calculate intermediate points for smooth dragging (10 steps)
-		const steps = 10
+		// Calculate intermediate points for smooth dragging
 		deltaX := (toX - fromX) / float64(steps)
 		deltaY := (toY - fromY) / float64(steps)
 
@@ -216,7 +308,9 @@ func (d *ChromeDPDriver) Drag(ctx context.Context, fromX, fromY, toX, toY float6
 				return err
 			}
 
-			time.Sleep(frameInterval)
+			if err := sleepContext(ctx, dragFrameInterval); err != nil {
+				return err
+			}
 		}
 
 		// Release at end position
@@ -233,19 +327,14 @@ func (d *ChromeDPDriver) DragPath(ctx context.Context, points []Point) error {
 		return fmt.Errorf("drag requires at least 2 points")
 	}
 
-	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
-
-	if !running || browserCtx == nil {
-		return fmt.Errorf("browser not running")
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Input+time.Duration(len(points))*dragFrameInterval)
+	if err != nil {
+		return err
 	}
+	defer cancel()
 
 	// Use ActionFunc for fine-grained control with delays
-	return chromedp.Run(browserCtx, chromedp.ActionFunc(func(ctx context.Context) error {
-		const frameInterval = time.Second / 60 // ~16.67ms per frame
-
+	return chromedp.Run(execCtx, chromedp.ActionFunc(func(ctx context.Context) error {
 		// Press at start position
 		p := &input.DispatchMouseEventParams{
 			Type:       input.MousePressed,
@@ -269,7 +358,9 @@ func (d *ChromeDPDriver) DragPath(ctx context.Context, points []Point) error {
 			}
 
 			// Add frame delay between moves for smooth, realistic dragging
-			time.Sleep(frameInterval)
+			if err := sleepContext(ctx, dragFrameInterval); err != nil {
+				return err
+			}
 		}
 
 		// Release at end position
@@ -280,22 +371,53 @@ func (d *ChromeDPDriver) DragPath(ctx context.Context, points []Point) error {
 
 // CaptureScreen captures the current browser screen.
 func (d *ChromeDPDriver) CaptureScreen(ctx context.Context) (image.Image, error) {
-	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Capture)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
 
-	if !running || browserCtx == nil {
-		return nil, fmt.Errorf("browser not running")
+	var buf []byte
+	if err := chromedp.Run(execCtx, chromedp.CaptureScreenshot(&buf)); err != nil {
+		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
 	}
 
-	// Add timeout protection
-	timeoutCtx, cancel := context.WithTimeout(browserCtx, 3*time.Second)
+	img, err := png.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+
+	return img, nil
+}
+
+// CaptureRegion captures a rectangle of the viewport using a CDP clip.
+func (d *ChromeDPDriver) CaptureRegion(ctx context.Context, rect image.Rectangle) (image.Image, error) {
+	if err := checkRegion(rect); err != nil {
+		return nil, err
+	}
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Capture)
+	if err != nil {
+		return nil, err
+	}
 	defer cancel()
 
 	var buf []byte
-	if err := chromedp.Run(timeoutCtx, chromedp.CaptureScreenshot(&buf)); err != nil {
-		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
+	err = chromedp.Run(execCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		buf, err = page.CaptureScreenshot().
+			WithFormat(page.CaptureScreenshotFormatPng).
+			WithClip(&page.Viewport{
+				X:      float64(rect.Min.X),
+				Y:      float64(rect.Min.Y),
+				Width:  float64(rect.Dx()),
+				Height: float64(rect.Dy()),
+				Scale:  1,
+			}).
+			Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture region: %w", err)
 	}
 
 	img, err := png.Decode(bytes.NewReader(buf))
@@ -306,113 +428,277 @@ func (d *ChromeDPDriver) CaptureScreen(ctx context.Context) (image.Image, error)
 	return img, nil
 }
 
-// SetViewport sets the browser viewport size.
-func (d *ChromeDPDriver) SetViewport(ctx context.Context, width, height int) error {
-	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
+// CaptureElement captures the element matching selector.
+func (d *ChromeDPDriver) CaptureElement(ctx context.Context, selector string) (image.Image, error) {
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Capture)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
 
-	if !running || browserCtx == nil {
-		return fmt.Errorf("browser not running")
+	var buf []byte
+	if err := chromedp.Run(execCtx, chromedp.Screenshot(selector, &buf, chromedp.ByQuery)); err != nil {
+		return nil, fmt.Errorf("failed to capture element %s: %w", selector, err)
 	}
 
-	return chromedp.Run(browserCtx,
-		chromedp.EmulateViewport(int64(width), int64(height)),
+	img, err := png.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+
+	return img, nil
+}
+
+// FrameOrigin reads the element's content quad from the CDP box model,
+// which is relative to the viewport like input coordinates.
+func (d *ChromeDPDriver) FrameOrigin(ctx context.Context, selector string) (Point, error) {
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return Point{}, err
+	}
+	defer cancel()
+
+	var nodes []*cdp.Node
+	var model *dom.BoxModel
+	err = chromedp.Run(execCtx,
+		chromedp.Nodes(selector, &nodes, chromedp.ByQuery),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			model, err = dom.GetBoxModel().WithNodeID(nodes[0].NodeID).Do(ctx)
+			return err
+		}),
 	)
+	if err != nil {
+		return Point{}, fmt.Errorf("failed to locate %s: %w", selector, err)
+	}
+	if len(model.Content) < 2 {
+		return Point{}, fmt.Errorf("element %s has no layout", selector)
+	}
+	return Point{X: model.Content[0], Y: model.Content[1]}, nil
 }
 
-// WaitVisible waits for an element to become visible.
-// The ctx parameter is used for timeout/cancellation - if it has a deadline,
-// we create a derived context from browserCtx with that deadline.
-func (d *ChromeDPDriver) WaitVisible(ctx context.Context, selector string) error {
-	// Check if the provided context is already cancelled
-	if ctx.Err() != nil {
-		return ctx.Err()
+// SetViewport sets the browser viewport size.
+func (d *ChromeDPDriver) SetViewport(ctx context.Context, width, height int) error {
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Storage)
+	if err != nil {
+		return err
 	}
+	defer cancel()
 
-	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
+	return chromedp.Run(execCtx,
+		chromedp.EmulateViewport(int64(width), int64(height), d.emulateOptions()...),
+	)
+}
 
-	if !running || browserCtx == nil {
-		return fmt.Errorf("browser not running")
+// emulateOptions returns the configured device emulation.
+func (d *ChromeDPDriver) emulateOptions() []chromedp.EmulateViewportOption {
+	opts := []chromedp.EmulateViewportOption{chromedp.EmulateScale(d.config.deviceScaleFactor())}
+	if d.config.Mobile {
+		opts = append(opts, chromedp.EmulateMobile, chromedp.EmulateTouch)
 	}
+	return opts
+}
 
-	// If the provided context has a deadline, create a timeout context from browserCtx
-	execCtx := browserCtx
-	if deadline, ok := ctx.Deadline(); ok {
-		timeout := time.Until(deadline)
-		if timeout <= 0 {
-			return context.DeadlineExceeded
-		}
-		var cancel context.CancelFunc
-		execCtx, cancel = context.WithTimeout(browserCtx, timeout)
-		defer cancel()
+// emulate sets the configured viewport, device emulation and page zoom.
+func (d *ChromeDPDriver) emulate() chromedp.Tasks {
+	return chromedp.Tasks{
+		chromedp.EmulateViewport(int64(d.config.ViewportWidth), int64(d.config.ViewportHeight), d.emulateOptions()...),
+		emulation.SetPageScaleFactor(d.config.pageScale()),
 	}
+}
 
-	// Run in a goroutine so we can also monitor the provided context for cancellation
-	done := make(chan error, 1)
-	go func() {
-		done <- chromedp.Run(execCtx,
-			chromedp.WaitVisible(selector, chromedp.ByID),
-		)
-	}()
+// PreparePage applies the configured viewport, device emulation and page zoom.
+func (d *ChromeDPDriver) PreparePage(ctx context.Context) error {
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Storage)
+	if err != nil {
+		return err
+	}
+	defer cancel()
 
-	select {
-	case err := <-done:
+	return chromedp.Run(execCtx, d.emulate())
+}
+
+// WaitVisible waits for an element to become visible.
+// The caller's deadline applies when set; otherwise the navigation timeout bounds the wait.
+func (d *ChromeDPDriver) WaitVisible(ctx context.Context, selector string) error {
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Navigation)
+	if err != nil {
 		return err
-	case <-ctx.Done():
-		return ctx.Err()
 	}
+	defer cancel()
+
+	return chromedp.Run(execCtx,
+		chromedp.WaitVisible(selector, chromedp.ByQuery),
+	)
 }
 
 // SendKeys sends keystrokes to an element.
 func (d *ChromeDPDriver) SendKeys(ctx context.Context, selector, text string) error {
-	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+	defer cancel()
 
-	if !running || browserCtx == nil {
-		return fmt.Errorf("browser not running")
+	return chromedp.Run(execCtx,
+		chromedp.SendKeys(selector, text, chromedp.ByQuery),
+	)
+}
+
+// TypeText dispatches key events for each character of text to the
+// focused element.
+func (d *ChromeDPDriver) TypeText(ctx context.Context, text string) error {
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
 	}
+	defer cancel()
 
-	return chromedp.Run(browserCtx,
-		chromedp.SendKeys(selector, text, chromedp.ByID),
+	return chromedp.Run(execCtx,
+		chromedp.KeyEvent(text),
 	)
 }
 
+// KeyDown presses a key without releasing it, dispatching its char event
+// too so printable keys type their character.
+func (d *ChromeDPDriver) KeyDown(ctx context.Context, key string) error {
+	return d.dispatchKey(ctx, key, func(t input.KeyType) bool { return t != input.KeyUp })
+}
+
+// KeyUp releases a key.
+func (d *ChromeDPDriver) KeyUp(ctx context.Context, key string) error {
+	return d.dispatchKey(ctx, key, func(t input.KeyType) bool { return t == input.KeyUp })
+}
+
+// KeyPress presses and releases a key.
+func (d *ChromeDPDriver) KeyPress(ctx context.Context, key string) error {
+	return d.dispatchKey(ctx, key, func(input.KeyType) bool { return true })
+}
+
+// dispatchKey sends the key's events whose type is selected by include.
+func (d *ChromeDPDriver) dispatchKey(ctx context.Context, key string, include func(input.KeyType) bool) error {
+	events, err := keyEvents(key)
+	if err != nil {
+		return err
+	}
+
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return chromedp.Run(execCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		for _, ev := range events {
+			if !include(ev.Type) {
+				continue
+			}
+			if err := ev.Do(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+var (
+	keyRunesOnce sync.Once
+	keyRunes     map[string]rune
+)
+
+// keyRune returns the rune kb encodes for a DOM key value. Single
+// characters stand for themselves; names such as "Enter" or "F5" are
+// looked up in the kb key table.
+func keyRune(key string) (rune, error) {
+	if r, size := utf8.DecodeRuneInString(key); size == len(key) && r != utf8.RuneError {
+		return r, nil
+	}
+
+	keyRunesOnce.Do(func() {
+		keyRunes = make(map[string]rune)
+		for r, k := range kb.Keys {
+			// Several runes share a name, e.g. "\r" and "\n"; keep the lowest
+			if prev, ok := keyRunes[k.Key]; !ok || r < prev {
+				keyRunes[k.Key] = r
+			}
+		}
+	})
+	if r, ok := keyRunes[key]; ok {
+		return r, nil
+	}
+	return 0, fmt.Errorf("unknown key %q", key)
+}
+
+// keyEvents returns the keyDown, char and keyUp events of a DOM key value.
+func keyEvents(key string) ([]*input.DispatchKeyEventParams, error) {
+	r, err := keyRune(key)
+	if err != nil {
+		return nil, err
+	}
+	return kb.Encode(r), nil
+}
+
 // ClickElement clicks on an element by selector.
 func (d *ChromeDPDriver) ClickElement(ctx context.Context, selector string) error {
-	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+	defer cancel()
 
-	if !running || browserCtx == nil {
-		return fmt.Errorf("browser not running")
+	return chromedp.Run(execCtx,
+		chromedp.Click(selector, chromedp.ByQuery),
+	)
+}
+
+// Ping waits until the page renders its next frame.
+func (d *ChromeDPDriver) Ping(ctx context.Context) error {
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
 	}
+	defer cancel()
 
-	return chromedp.Run(browserCtx,
-		chromedp.Click(selector, chromedp.ByID),
+	var rendered bool
+	return chromedp.Run(execCtx,
+		chromedp.Evaluate(pingScript, &rendered, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithAwaitPromise(true)
+		}),
 	)
 }
 
+// Evaluate runs the expression in the page's main frame.
+func (d *ChromeDPDriver) Evaluate(ctx context.Context, expression string) (json.RawMessage, error) {
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Navigation)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var raw []byte
+	if err := chromedp.Run(execCtx,
+		chromedp.Evaluate(expression, &raw, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithAwaitPromise(true)
+		}),
+	); err != nil {
+		return nil, fmt.Errorf("failed to evaluate script: %w", err)
+	}
+	if len(raw) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	return raw, nil
+}
+
 // GetCookies retrieves all browser cookies.
 func (d *ChromeDPDriver) GetCookies(ctx context.Context) ([]Cookie, error) {
-	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
-
-	if !running || browserCtx == nil {
-		return nil, fmt.Errorf("browser not running")
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Storage)
+	if err != nil {
+		return nil, err
 	}
+	defer cancel()
 
 	var networkCookies []*network.Cookie
-	if err := chromedp.Run(browserCtx,
+	if err := chromedp.Run(execCtx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			var err error
 			networkCookies, err = storage.GetCookies().Do(ctx)
@@ -434,6 +720,7 @@ func (d *ChromeDPDriver) GetCookies(ctx context.Context) ([]Cookie, error) {
 			SourcePort:   int(nc.SourcePort),
 			SourceScheme: string(nc.SourceScheme),
 			Priority:     string(nc.Priority),
+			Expires:      cookieExpiry(nc.Expires),
 		}
 	}
 
@@ -442,14 +729,11 @@ func (d *ChromeDPDriver) GetCookies(ctx context.Context) ([]Cookie, error) {
 
 // SetCookies sets browser cookies.
 func (d *ChromeDPDriver) SetCookies(ctx context.Context, cookies []Cookie) error {
-	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
-
-	if !running || browserCtx == nil {
-		return fmt.Errorf("browser not running")
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Storage)
+	if err != nil {
+		return err
 	}
+	defer cancel()
 
 	actions := make([]chromedp.Action, len(cookies))
 	for i, c := range cookies {
@@ -469,12 +753,16 @@ func (d *ChromeDPDriver) SetCookies(ctx context.Context, cookies []Cookie) error
 			if cookie.SourceScheme != "" {
 				setCookie = setCookie.WithSourceScheme(network.CookieSourceScheme(cookie.SourceScheme))
 			}
+			if !cookie.Expires.IsZero() {
+				expires := cdp.TimeSinceEpoch(cookie.Expires)
+				setCookie = setCookie.WithExpires(&expires)
+			}
 
 			return setCookie.Do(ctx)
 		})
 	}
 
-	return chromedp.Run(browserCtx, actions...)
+	return chromedp.Run(execCtx, actions...)
 }
 
 // Context returns the underlying chromedp context.
@@ -485,43 +773,123 @@ func (d *ChromeDPDriver) Context() context.Context {
 	return d.ctx
 }
 
-// LoginWithPassword performs a complete login flow with username and password.
-// This executes all steps in a single chromedp.Run call for better reliability,
-// matching the behavior of the original implementation.
-func (d *ChromeDPDriver) LoginWithPassword(url, username, password string, timeoutSeconds int) error {
+// BlockURLs fails requests matching the patterns with the Network domain.
+func (d *ChromeDPDriver) BlockURLs(ctx context.Context, patterns []string) error {
+	execCtx, cancel, err := d.opContext(ctx, d.config.Timeouts.Storage)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if patterns == nil {
+		patterns = []string{}
+	}
+	if err := chromedp.Run(execCtx, network.Enable(), network.SetBlockedURLs(patterns)); err != nil {
+		return fmt.Errorf("failed to block URLs: %w", err)
+	}
+	return nil
+}
+
+// WatchResponses listens for Network events. A matching response is held
+// until it finishes loading, then its body is fetched and it is delivered.
+func (d *ChromeDPDriver) WatchResponses(ctx context.Context, pattern string) (<-chan Response, error) {
 	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
+	browserCtx, running := d.ctx, d.running
 	d.mu.Unlock()
-
 	if !running || browserCtx == nil {
-		return fmt.Errorf("browser not running")
+		return nil, fmt.Errorf("browser not running")
 	}
 
-	// Step 1: Set viewport and navigate (without timeout, let it complete)
-	err := chromedp.Run(browserCtx,
-		chromedp.EmulateViewport(1080, 720, chromedp.EmulateScale(1)),
+	match := urlPatterns(pattern)
+	stream := newResponseStream()
+	watchCtx, stop := context.WithCancel(browserCtx)
+	context.AfterFunc(ctx, stop)
+	context.AfterFunc(watchCtx, stream.close)
+
+	var mu sync.Mutex
+	pending := make(map[network.RequestID]Response)
+	chromedp.ListenTarget(watchCtx, func(ev interface{}) {
+		switch e := ev.(type) {
+		case *network.EventResponseReceived:
+			if e.Type != network.ResourceTypeXHR && e.Type != network.ResourceTypeFetch {
+				return
+			}
+			if e.Response == nil || !match.MatchString(e.Response.URL) {
+				return
+			}
+			mu.Lock()
+			pending[e.RequestID] = Response{URL: e.Response.URL, Status: int(e.Response.Status), MIMEType: e.Response.MimeType}
+			mu.Unlock()
+		case *network.EventLoadingFailed:
+			mu.Lock()
+			delete(pending, e.RequestID)
+			mu.Unlock()
+		case *network.EventLoadingFinished:
+			mu.Lock()
+			resp, ok := pending[e.RequestID]
+			delete(pending, e.RequestID)
+			mu.Unlock()
+			if !ok {
+				return
+			}
+			// Commands can't be sent from the listener, which blocks events
+			go func() {
+				_ = chromedp.Run(watchCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+					body, err := network.GetResponseBody(e.RequestID).Do(ctx)
+					resp.Body = body
+					return err
+				}))
+				stream.send(resp)
+			}()
+		}
+	})
+
+	execCtx, cancel := linkContext(ctx, browserCtx, d.config.Timeouts.Storage)
+	defer cancel()
+	if err := chromedp.Run(execCtx, network.Enable()); err != nil {
+		stop()
+		return nil, fmt.Errorf("failed to enable network events: %w", err)
+	}
+	return stream.ch, nil
+}
+
+// LoginWithPassword performs a complete login flow with username and password.
+// This executes all steps in a single chromedp.Run call for better reliability,
+// matching the behavior of the original implementation.
+func (d *ChromeDPDriver) LoginWithPassword(ctx context.Context, url, username, password string, timeoutSeconds int) error {
+	// Step 1: Set viewport and navigate (bounded by the navigation timeout)
+	navCtx, navCancel, err := d.opContext(ctx, d.config.Timeouts.Navigation)
+	if err != nil {
+		return err
+	}
+	err = chromedp.Run(navCtx,
+		d.emulate(),
 		chromedp.Navigate(url),
 	)
+	navCancel()
 	if err != nil {
 		return fmt.Errorf("start page failure: %w", err)
 	}
 
 	// Step 2: Wait for login form, enter credentials, and submit (with timeout)
-	ctx, cancel := context.WithTimeout(browserCtx, time.Duration(timeoutSeconds)*time.Second)
+	loginCtx, cancel, err := d.opContext(ctx, time.Duration(timeoutSeconds)*time.Second)
+	if err != nil {
+		return err
+	}
 	defer cancel()
 
-	err = chromedp.Run(ctx,
-		chromedp.EmulateViewport(1080, 720, chromedp.EmulateScale(1)),
+	login := d.config.Login
+	err = chromedp.Run(loginCtx,
+		d.emulate(),
 		chromedp.Navigate(url),
-		chromedp.WaitVisible(`#username`, chromedp.ByID),
-		chromedp.SendKeys(`#username`, username, chromedp.ByID),
-		chromedp.SendKeys(`#userpwd`, password, chromedp.ByID),
-		chromedp.Click(`#form1 > div.r06 > div.login_box3 > p > input`, chromedp.ByID),
-		chromedp.WaitVisible(`#S_Iframe`, chromedp.ByID),
+		chromedp.WaitVisible(login.Username, chromedp.ByQuery),
+		chromedp.SendKeys(login.Username, username, chromedp.ByQuery),
+		chromedp.SendKeys(login.Password, password, chromedp.ByQuery),
+		chromedp.Click(login.Submit, chromedp.ByQuery),
+		chromedp.WaitVisible(login.Ready, chromedp.ByQuery),
 	)
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
+		if ctx.Err() == nil && loginCtx.Err() == context.DeadlineExceeded {
 			return fmt.Errorf("login timeout after %ds (server may be down or in maintenance)", timeoutSeconds)
 		}
 		return fmt.Errorf("login failure: %w", err)
@@ -533,16 +901,7 @@ func (d *ChromeDPDriver) LoginWithPassword(url, username, password string, timeo
 // LoginWithCookies performs login using stored cookies.
 // This executes all steps in a single chromedp.Run call for better reliability,
 // matching the behavior of the original implementation.
-func (d *ChromeDPDriver) LoginWithCookies(url string, cookies []Cookie, timeoutSeconds int) error {
-	d.mu.Lock()
-	browserCtx := d.ctx
-	running := d.running
-	d.mu.Unlock()
-
-	if !running || browserCtx == nil {
-		return fmt.Errorf("browser not running")
-	}
-
+func (d *ChromeDPDriver) LoginWithCookies(ctx context.Context, url string, cookies []Cookie, timeoutSeconds int) error {
 	// Build cookie actions
 	cookieActions := make([]chromedp.Action, len(cookies))
 	for i, c := range cookies {
@@ -561,30 +920,42 @@ func (d *ChromeDPDriver) LoginWithCookies(url string, cookies []Cookie, timeoutS
 			if cookie.SourceScheme != "" {
 				setCookie = setCookie.WithSourceScheme(network.CookieSourceScheme(cookie.SourceScheme))
 			}
+			if !cookie.Expires.IsZero() {
+				expires := cdp.TimeSinceEpoch(cookie.Expires)
+				setCookie = setCookie.WithExpires(&expires)
+			}
 
 			return setCookie.Do(ctx)
 		})
 	}
 
-	// Step 1: Set cookies and navigate (without timeout)
+	// Step 1: Set cookies and navigate (bounded by the navigation timeout)
+	navCtx, navCancel, err := d.opContext(ctx, d.config.Timeouts.Navigation)
+	if err != nil {
+		return err
+	}
 	actions := append(cookieActions,
-		chromedp.EmulateViewport(1080, 720, chromedp.EmulateScale(1)),
+		d.emulate(),
 		chromedp.Navigate(url),
 	)
-	err := chromedp.Run(browserCtx, actions...)
+	err = chromedp.Run(navCtx, actions...)
+	navCancel()
 	if err != nil {
 		return fmt.Errorf("start page failure: %w", err)
 	}
 
 	// Step 2: Wait for game iframe (with timeout)
-	ctx, cancel := context.WithTimeout(browserCtx, time.Duration(timeoutSeconds)*time.Second)
+	loginCtx, cancel, err := d.opContext(ctx, time.Duration(timeoutSeconds)*time.Second)
+	if err != nil {
+		return err
+	}
 	defer cancel()
 
-	err = chromedp.Run(ctx,
-		chromedp.WaitVisible(`#S_Iframe`, chromedp.ByID),
+	err = chromedp.Run(loginCtx,
+		chromedp.WaitVisible(d.config.Login.Ready, chromedp.ByQuery),
 	)
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
+		if ctx.Err() == nil && loginCtx.Err() == context.DeadlineExceeded {
 			return fmt.Errorf("login timeout after %ds (server may be down or in maintenance)", timeoutSeconds)
 		}
 		return fmt.Errorf("login failure: %w", err)
@@ -652,7 +1023,9 @@ func (d *ChromeDPDriver) StartScreencast(ctx context.Context, quality, maxFPS in
 	})
 
 	// Start screencast
-	err := chromedp.Run(browserCtx,
+	startCtx, startCancel := linkContext(ctx, browserCtx, d.config.Timeouts.Capture)
+	defer startCancel()
+	err := chromedp.Run(startCtx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			return page.StartScreencast().
 				WithFormat(page.ScreencastFormatJpeg).
@@ -689,9 +1062,11 @@ func (d *ChromeDPDriver) stopScreencastInternal() error {
 		return nil
 	}
 
-	// Stop screencast on browser
+	// Stop screencast on browser (bounded so Stop cannot hang on a wedged browser)
 	if d.ctx != nil {
-		_ = chromedp.Run(d.ctx,
+		stopCtx, cancel := context.WithTimeout(d.ctx, d.config.Timeouts.Capture)
+		defer cancel()
+		_ = chromedp.Run(stopCtx,
 			chromedp.ActionFunc(func(ctx context.Context) error {
 				return page.StopScreencast().Do(ctx)
 			}),
@@ -3,7 +3,13 @@ package browser
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"image"
+	"math"
+	"net"
+	"strconv"
+	"time"
 )
 
 // Driver defines the interface for browser automation.
@@ -18,6 +24,11 @@ type Driver interface {
 	// IsRunning returns true if the browser is active.
 	IsRunning() bool
 
+	// Done returns a channel that is closed when the browser exits, whether
+	// it was stopped or crashed. It returns nil while the browser is not
+	// running.
+	Done() <-chan struct{}
+
 	// Navigate navigates to the specified URL.
 	Navigate(ctx context.Context, url string) error
 
@@ -33,34 +44,92 @@ type Driver interface {
 	// DragPath performs a mouse drag along a path of points.
 	DragPath(ctx context.Context, points []Point) error
 
+	// Scroll turns the mouse wheel at (x, y). Positive deltas scroll right
+	// and down, in CSS pixels.
+	Scroll(ctx context.Context, x, y, deltaX, deltaY float64) error
+
 	// CaptureScreen captures the current browser screen.
 	CaptureScreen(ctx context.Context) (image.Image, error)
 
+	// CaptureRegion captures only a rectangle of the viewport (in CSS pixels)
+	// at full quality, which is cheaper than a full capture for small checks.
+	// The returned image's bounds start at (0, 0).
+	CaptureRegion(ctx context.Context, rect image.Rectangle) (image.Image, error)
+
+	// CaptureElement captures the bounding box of the first element matching
+	// a CSS selector. The returned image's bounds start at (0, 0).
+	CaptureElement(ctx context.Context, selector string) (image.Image, error)
+
 	// SetViewport sets the browser viewport size.
 	SetViewport(ctx context.Context, width, height int) error
 
-	// WaitVisible waits for an element to become visible.
+	// PreparePage applies the configured viewport, device emulation and
+	// page zoom, as the login methods do before opening the login page.
+	PreparePage(ctx context.Context) error
+
+	// WaitVisible waits for the element matching a CSS selector to become
+	// visible.
 	WaitVisible(ctx context.Context, selector string) error
 
-	// SendKeys sends keystrokes to an element.
+	// SendKeys sends keystrokes to the element matching a CSS selector.
 	SendKeys(ctx context.Context, selector, text string) error
 
-	// ClickElement clicks on an element by selector.
+	// TypeText types text as raw keyboard input into whatever has focus,
+	// such as a game's canvas text box after clicking it.
+	TypeText(ctx context.Context, text string) error
+
+	// KeyDown presses a key without releasing it. Keys are DOM key values
+	// such as "a", "Enter" or "ArrowLeft".
+	KeyDown(ctx context.Context, key string) error
+
+	// KeyUp releases a key pressed with KeyDown.
+	KeyUp(ctx context.Context, key string) error
+
+	// KeyPress presses and releases a key, typing its character if it has one.
+	KeyPress(ctx context.Context, key string) error
+
+	// ClickElement clicks the element matching a CSS selector.
 	ClickElement(ctx context.Context, selector string) error
 
+	// FrameOrigin returns the top-left corner of the content box of the
+	// first element matching a CSS selector, in viewport CSS pixels. For
+	// the game iframe it is where game coordinates start.
+	FrameOrigin(ctx context.Context, selector string) (Point, error)
+
+	// Ping waits until the page renders its next frame. Its round-trip
+	// time measures how responsive the page is.
+	Ping(ctx context.Context) error
+
+	// Evaluate runs a JavaScript expression in the page, awaiting it if it
+	// is a promise, and returns its value as JSON ("null" for undefined).
+	// It reads DOM state, such as the canvas size, that pixels only hint at.
+	Evaluate(ctx context.Context, expression string) (json.RawMessage, error)
+
 	// GetCookies retrieves all browser cookies.
 	GetCookies(ctx context.Context) ([]Cookie, error)
 
 	// SetCookies sets browser cookies.
 	SetCookies(ctx context.Context, cookies []Cookie) error
 
+	// BlockURLs makes the browser fail requests whose URL matches one of
+	// the patterns, where * matches any run of characters, e.g.
+	// "*://*.doubleclick.net/*" or "*.mp4". It replaces the patterns set
+	// before, including DriverConfig.BlockedURLs; an empty list unblocks all.
+	BlockURLs(ctx context.Context, patterns []string) error
+
+	// WatchResponses returns a channel that receives the XHR and fetch
+	// responses whose URL matches pattern (as in BlockURLs), with their
+	// bodies. Responses arriving while the channel is full are dropped. The
+	// channel is closed when ctx is done or the browser stops.
+	WatchResponses(ctx context.Context, pattern string) (<-chan Response, error)
+
 	// LoginWithPassword performs a complete login flow with username and password.
 	// This executes all steps in a single chromedp.Run call for better reliability.
-	LoginWithPassword(url, username, password string, timeoutSeconds int) error
+	LoginWithPassword(ctx context.Context, url, username, password string, timeoutSeconds int) error
 
 	// LoginWithCookies performs login using stored cookies.
 	// This executes all steps in a single chromedp.Run call for better reliability.
-	LoginWithCookies(url string, cookies []Cookie, timeoutSeconds int) error
+	LoginWithCookies(ctx context.Context, url string, cookies []Cookie, timeoutSeconds int) error
 
 	// StartScreencast starts frame streaming from the browser.
 	// Returns a channel that receives decoded frames.
@@ -74,6 +143,35 @@ type Driver interface {
 	IsScreencasting() bool
 }
 
+// pingScript resolves on the page's next animation frame.
+const pingScript = `new Promise(resolve => requestAnimationFrame(() => resolve(true)))`
+
+// checkRegion rejects empty capture regions.
+func checkRegion(rect image.Rectangle) error {
+	if rect.Empty() || rect.Min.X < 0 || rect.Min.Y < 0 {
+		return fmt.Errorf("invalid capture region %v", rect)
+	}
+	return nil
+}
+
+// Proxy is an HTTP proxy the browser connects through.
+type Proxy struct {
+	Host     string
+	Port     int
+	Username string // Optional; sent when the proxy asks for credentials
+	Password string
+}
+
+// Server returns the proxy address in the form browsers expect.
+func (p *Proxy) Server() string {
+	return "http://" + net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+}
+
+// HasCredentials returns true if the proxy requires authentication.
+func (p *Proxy) HasCredentials() bool {
+	return p.Username != ""
+}
+
 // Point represents a coordinate.
 type Point struct {
 	X, Y float64
@@ -90,6 +188,19 @@ type Cookie struct {
 	SourcePort   int
 	SourceScheme string
 	Priority     string
+	// Expires is when the cookie expires; zero for session cookies
+	Expires time.Time
+}
+
+// cookieExpiry converts an expiry in seconds since the Unix epoch, as
+// browsers report it, to a time. Session cookies report -1 and get the
+// zero time.
+func cookieExpiry(seconds float64) time.Time {
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*1e9))
 }
 
 // DriverConfig holds configuration for browser drivers.
@@ -109,6 +220,15 @@ type DriverConfig struct {
 	// ViewportHeight is the viewport height.
 	ViewportHeight int
 
+	// PageScale zooms the page; zero means 1.
+	PageScale float64
+
+	// DeviceScaleFactor is the emulated device pixel ratio; zero means 1.
+	DeviceScaleFactor float64
+
+	// Mobile emulates a mobile device with touch input.
+	Mobile bool
+
 	// DisableGPU disables GPU acceleration.
 	DisableGPU bool
 
@@ -123,19 +243,176 @@ type DriverConfig struct {
 
 	// UserDataDir specifies a custom user data directory.
 	UserDataDir string
+
+	// Proxy routes all browser traffic through an HTTP proxy (optional).
+	Proxy *Proxy
+
+	// BlockedURLs are URL patterns the browser fails requests for from the
+	// start, such as ads, analytics or heavy assets; see Driver.BlockURLs.
+	BlockedURLs []string
+
+	// Timeouts are the default deadlines per operation class.
+	// A caller context with an earlier deadline always wins.
+	Timeouts OperationTimeouts
+
+	// Login holds the login page selectors. Empty fields use
+	// DefaultLoginProfile.
+	Login LoginProfile
+}
+
+// OperationTimeouts holds default timeouts for each class of browser operation.
+// Zero values fall back to DefaultOperationTimeouts.
+type OperationTimeouts struct {
+	// Input covers mouse and keyboard actions (click, drag, send keys).
+	Input time.Duration
+
+	// Navigation covers page loads, element waits and scripts (navigate,
+	// reload, wait visible, evaluate).
+	Navigation time.Duration
+
+	// Capture covers screenshots and screencast control.
+	Capture time.Duration
+
+	// Storage covers cookies and viewport changes.
+	Storage time.Duration
+}
+
+// DefaultOperationTimeouts returns the default per-class timeouts.
+func DefaultOperationTimeouts() OperationTimeouts {
+	return OperationTimeouts{
+		Input:      5 * time.Second,
+		Navigation: 30 * time.Second,
+		Capture:    3 * time.Second,
+		Storage:    10 * time.Second,
+	}
+}
+
+// withDefaults fills zero fields from DefaultOperationTimeouts.
+func (t OperationTimeouts) withDefaults() OperationTimeouts {
+	def := DefaultOperationTimeouts()
+	if t.Input <= 0 {
+		t.Input = def.Input
+	}
+	if t.Navigation <= 0 {
+		t.Navigation = def.Navigation
+	}
+	if t.Capture <= 0 {
+		t.Capture = def.Capture
+	}
+	if t.Storage <= 0 {
+		t.Storage = def.Storage
+	}
+	return t
+}
+
+// withDefaults returns a copy of the config with default timeouts and login
+// profile filled in. The caller's config, which may be shared by several
+// drivers, is left as it is.
+func (c *DriverConfig) withDefaults() *DriverConfig {
+	cfg := *c
+	cfg.Timeouts = cfg.Timeouts.withDefaults()
+	cfg.Login = cfg.Login.withDefaults()
+	return &cfg
+}
+
+// deviceScaleFactor returns DeviceScaleFactor, defaulting to 1.
+func (c *DriverConfig) deviceScaleFactor() float64 {
+	if c.DeviceScaleFactor > 0 {
+		return c.DeviceScaleFactor
+	}
+	return 1
+}
+
+// pageScale returns PageScale, defaulting to 1.
+func (c *DriverConfig) pageScale() float64 {
+	if c.PageScale > 0 {
+		return c.PageScale
+	}
+	return 1
 }
 
 // DefaultDriverConfig returns default browser configuration.
 func DefaultDriverConfig() *DriverConfig {
 	return &DriverConfig{
 		Headless:           true,
-		WindowWidth:        1080,
-		WindowHeight:       840,
-		ViewportWidth:      1080,
-		ViewportHeight:     720,
+		WindowWidth:        BaseCanvas.Width,
+		WindowHeight:       BaseCanvas.Height + 120,
+		ViewportWidth:      BaseCanvas.Width,
+		ViewportHeight:     BaseCanvas.Height,
 		DisableGPU:         false,
 		MuteAudio:          true,
 		HideScrollbars:     true,
 		DisableWebSecurity: true,
+		Timeouts:           DefaultOperationTimeouts(),
+		Login:              DefaultLoginProfile(),
+	}
+}
+
+// Browser engines selectable with NewDriverFactory.
+const (
+	EngineChromeDP   = "chromedp"
+	EnginePlaywright = "playwright"
+)
+
+// EnvEngine selects the browser engine at startup.
+const EnvEngine = "WARDENLY_BROWSER_ENGINE"
+
+// NewDriverFactory returns the driver constructor for an engine.
+// An empty engine selects ChromeDP. Playwright is only available in builds
+// made with -tags playwright.
+func NewDriverFactory(engine string) (func(config *DriverConfig) Driver, error) {
+	switch engine {
+	case "", EngineChromeDP:
+		return func(config *DriverConfig) Driver { return NewChromeDPDriver(config) }, nil
+	case EnginePlaywright:
+		if !playwrightAvailable {
+			return nil, fmt.Errorf("browser engine %q is not included in this build (build with -tags playwright)", engine)
+		}
+		return newPlaywrightDriver, nil
+	default:
+		return nil, fmt.Errorf("unknown browser engine %q (want %s or %s)", engine, EngineChromeDP, EnginePlaywright)
+	}
+}
+
+// linkContext derives an operation context from browserCtx that is bounded by
+// timeout (or the caller's earlier deadline) and cancelled together with ctx.
+// browserCtx must stay the parent so driver-specific values are preserved.
+func linkContext(ctx, browserCtx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < timeout {
+			timeout = remaining
+		}
+	}
+
+	execCtx, cancel := context.WithTimeout(browserCtx, timeout)
+	stop := context.AfterFunc(ctx, cancel)
+	return execCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// dragFrameInterval is the delay between mouse moves while dragging (~60fps).
+const dragFrameInterval = time.Second / 60
+
+// DragSteps is the number of mouse moves Drag interpolates.
+const DragSteps = 10
+
+// DragPacing returns the delay a drag deliberately spends between its mouse
+// moves, which is not part of the browser's response time.
+func DragPacing(moves int) time.Duration {
+	return time.Duration(moves) * dragFrameInterval
+}
+
+// sleepContext pauses for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
 }
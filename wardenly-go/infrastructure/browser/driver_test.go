@@ -1,6 +1,13 @@
 package browser
 
-import "testing"
+import (
+	"context"
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+	"time"
+)
 
 func TestDefaultDriverConfig(t *testing.T) {
 	config := DefaultDriverConfig()
@@ -40,6 +47,307 @@ func TestDefaultDriverConfig(t *testing.T) {
 	if config.DisableWebSecurity != true {
 		t.Errorf("DisableWebSecurity = %v, want true", config.DisableWebSecurity)
 	}
+
+	if config.Timeouts != DefaultOperationTimeouts() {
+		t.Errorf("Timeouts = %+v, want defaults", config.Timeouts)
+	}
+}
+
+func TestCanvasSize(t *testing.T) {
+	size, err := ParseCanvasSize(" 1280X720 ")
+	if err != nil || size != (CanvasSize{Width: 1280, Height: 720}) {
+		t.Fatalf("ParseCanvasSize() = %v, %v", size, err)
+	}
+	for _, text := range []string{"", "1280", "0x720", "axb"} {
+		if _, err := ParseCanvasSize(text); err == nil {
+			t.Errorf("ParseCanvasSize(%q) should fail", text)
+		}
+	}
+
+	if scale := (CanvasSize{Width: 1620, Height: 540}).Scale(); scale != (Point{X: 1.5, Y: 0.75}) {
+		t.Errorf("Scale() = %v, want {1.5 0.75}", scale)
+	}
+	if scale := (CanvasSize{}).Scale(); scale != (Point{X: 1, Y: 1}) {
+		t.Errorf("zero Scale() = %v, want {1 1}", scale)
+	}
+
+	config := DefaultDriverConfig()
+	size.Apply(config)
+	if config.ViewportWidth != 1280 || config.ViewportHeight != 720 || config.WindowWidth != 1280 || config.WindowHeight != 840 {
+		t.Errorf("Apply() = viewport %dx%d, window %dx%d", config.ViewportWidth, config.ViewportHeight, config.WindowWidth, config.WindowHeight)
+	}
+}
+
+func TestNewDriverFactory(t *testing.T) {
+	for _, engine := range []string{"", EngineChromeDP} {
+		newDriver, err := NewDriverFactory(engine)
+		if err != nil {
+			t.Fatalf("NewDriverFactory(%q) error = %v", engine, err)
+		}
+		if _, ok := newDriver(nil).(*ChromeDPDriver); !ok {
+			t.Errorf("NewDriverFactory(%q) does not build a ChromeDP driver", engine)
+		}
+	}
+
+	if _, err := NewDriverFactory("firefox"); err == nil {
+		t.Error("NewDriverFactory(firefox) should fail")
+	}
+
+	_, err := NewDriverFactory(EnginePlaywright)
+	if playwrightAvailable != (err == nil) {
+		t.Errorf("NewDriverFactory(playwright) error = %v, available = %v", err, playwrightAvailable)
+	}
+}
+
+func TestOperationTimeouts_WithDefaults(t *testing.T) {
+	def := DefaultOperationTimeouts()
+
+	got := OperationTimeouts{Input: time.Second}.withDefaults()
+	if got.Input != time.Second {
+		t.Errorf("Input = %v, want 1s", got.Input)
+	}
+	if got.Navigation != def.Navigation || got.Capture != def.Capture || got.Storage != def.Storage {
+		t.Errorf("zero fields not filled from defaults: %+v", got)
+	}
+}
+
+func TestDriverConfig_EmulationDefaults(t *testing.T) {
+	config := DefaultDriverConfig()
+	if config.deviceScaleFactor() != 1 || config.pageScale() != 1 {
+		t.Errorf("unset scales = %v, %v, want 1", config.deviceScaleFactor(), config.pageScale())
+	}
+
+	config.DeviceScaleFactor, config.PageScale = 2, 0.8
+	if config.deviceScaleFactor() != 2 || config.pageScale() != 0.8 {
+		t.Errorf("scales = %v, %v, want 2, 0.8", config.deviceScaleFactor(), config.pageScale())
+	}
+}
+
+func TestKeyEvents(t *testing.T) {
+	tests := []struct {
+		key     string
+		events  int
+		keyName string
+	}{
+		{"a", 3, "a"},         // keyDown, char, keyUp
+		{"Enter", 3, "Enter"}, // Enter types "\r"
+		{"ArrowLeft", 2, "ArrowLeft"},
+	}
+	for _, tt := range tests {
+		events, err := keyEvents(tt.key)
+		if err != nil {
+			t.Errorf("keyEvents(%q) error = %v", tt.key, err)
+			continue
+		}
+		if len(events) != tt.events || events[0].Key != tt.keyName {
+			t.Errorf("keyEvents(%q) = %d events for %q, want %d for %q", tt.key, len(events), events[0].Key, tt.events, tt.keyName)
+		}
+	}
+
+	if _, err := keyEvents("NoSuchKey"); err == nil {
+		t.Error("keyEvents should reject unknown key names")
+	}
+}
+
+func TestLoginProfile_LoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "login_profile.json")
+
+	// Missing file: defaults
+	got, err := LoadLoginProfile(path, LoginProfile{})
+	if err != nil || got != DefaultLoginProfile() {
+		t.Fatalf("LoadLoginProfile(missing) = %+v, %v; want defaults", got, err)
+	}
+
+	// Saved fields are kept, empty ones fall back to defaults
+	if err := SaveLoginProfile(path, LoginProfile{Username: `input[name="user"]`, Submit: `#login`}); err != nil {
+		t.Fatalf("SaveLoginProfile: %v", err)
+	}
+	got, err = LoadLoginProfile(path, LoginProfile{})
+	if err != nil {
+		t.Fatalf("LoadLoginProfile: %v", err)
+	}
+	want := DefaultLoginProfile()
+	want.Username = `input[name="user"]`
+	want.Submit = `#login`
+	if got != want {
+		t.Errorf("LoadLoginProfile = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoginProfile_LoadOverBase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "login_profile.json")
+	base := LoginProfile{URLTemplate: "https://mirror.example/s/{server}", Ready: `#game`}
+
+	got, err := LoadLoginProfile(path, base)
+	if err != nil || got.URLTemplate != base.URLTemplate || got.Ready != `#game` || got.Username != DefaultLoginProfile().Username {
+		t.Fatalf("LoadLoginProfile(missing, base) = %+v, %v", got, err)
+	}
+
+	// The saved profile wins over the base where it has a field, and
+	// never saves a URL template
+	if err := SaveLoginProfile(path, LoginProfile{URLTemplate: DefaultURLTemplate, Ready: `#S_Iframe`}); err != nil {
+		t.Fatalf("SaveLoginProfile: %v", err)
+	}
+	got, err = LoadLoginProfile(path, base)
+	if err != nil || got.URLTemplate != base.URLTemplate || got.Ready != `#S_Iframe` {
+		t.Errorf("LoadLoginProfile(saved, base) = %+v, %v", got, err)
+	}
+}
+
+func TestLoginURL(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"", "http://www.lequ.com/server/wly/s/42"},
+		{"https://mirror.example/wly/{server}/login", "https://mirror.example/wly/42/login"},
+		{"http://test.example/login", "http://test.example/login"},
+	}
+	for _, tt := range tests {
+		if got := LoginURL(tt.template, 42); got != tt.want {
+			t.Errorf("LoginURL(%q, 42) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestLoginProfile_FrameOrigin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "login_profile.json")
+
+	if err := SaveLoginProfile(path, LoginProfile{FrameOrigin: &Point{X: 0, Y: 96}}); err != nil {
+		t.Fatalf("SaveLoginProfile: %v", err)
+	}
+	got, err := LoadLoginProfile(path, LoginProfile{})
+	if err != nil {
+		t.Fatalf("LoadLoginProfile: %v", err)
+	}
+	if got.FrameOrigin == nil || *got.FrameOrigin != (Point{X: 0, Y: 96}) {
+		t.Errorf("FrameOrigin = %v, want {0 96}", got.FrameOrigin)
+	}
+	if got.Frame != DefaultLoginProfile().Frame {
+		t.Errorf("Frame = %q, want the default", got.Frame)
+	}
+}
+
+func TestLinkContext_CallerDeadlineWins(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	execCtx, execCancel := linkContext(ctx, context.Background(), time.Hour)
+	defer execCancel()
+
+	deadline, ok := execCtx.Deadline()
+	if !ok {
+		t.Fatal("execCtx has no deadline")
+	}
+	if time.Until(deadline) > time.Second {
+		t.Errorf("deadline %v should follow the caller's earlier deadline", time.Until(deadline))
+	}
+}
+
+func TestLinkContext_CallerCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	execCtx, execCancel := linkContext(ctx, context.Background(), time.Hour)
+	defer execCancel()
+
+	cancel()
+
+	select {
+	case <-execCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("execCtx not cancelled with caller context")
+	}
+}
+
+func TestSleepContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := sleepContext(ctx, time.Hour); err != context.Canceled {
+		t.Errorf("sleepContext() = %v, want context.Canceled", err)
+	}
+}
+
+func TestProxy_Server(t *testing.T) {
+	if got := (&Proxy{Host: "10.0.0.1", Port: 8080}).Server(); got != "http://10.0.0.1:8080" {
+		t.Errorf("Server() = %q", got)
+	}
+	if got := (&Proxy{Host: "::1", Port: 3128}).Server(); got != "http://[::1]:3128" {
+		t.Errorf("Server() = %q for IPv6", got)
+	}
+}
+
+func TestMatchURL(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"*://ads.example/*", "https://ads.example/banner.js", true},
+		{"*://ads.example/*", "https://www.lequ.com/ads.example/", false},
+		{"*/api/battle?*", "http://s42.lequ.com/api/battle?id=7", true},
+		{"*/api/battle?*", "http://s42.lequ.com/api/battleXid=7", false},
+		{"http://a.example/x", "http://a.example/x", true},
+		{"http://a.example/x", "http://a.example/xy", false},
+	}
+	for _, tt := range tests {
+		if got := MatchURL(tt.pattern, tt.url); got != tt.want {
+			t.Errorf("MatchURL(%q, %q) = %v, want %v", tt.pattern, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestReplayDriver_WatchResponses(t *testing.T) {
+	d := NewReplayDriver(&ReplayDriverConfig{Frames: []image.Image{image.NewRGBA(image.Rect(0, 0, 10, 10))}})
+	if _, err := d.WatchResponses(context.Background(), "*"); err == nil {
+		t.Error("WatchResponses() before Start should fail")
+	}
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	responses, err := d.WatchResponses(ctx, "*")
+	if err != nil {
+		t.Fatalf("WatchResponses() error = %v", err)
+	}
+	cancel()
+	select {
+	case _, ok := <-responses:
+		if ok {
+			t.Error("replay should not deliver responses")
+		}
+	case <-time.After(time.Second):
+		t.Error("responses should close with the context")
+	}
+}
+
+func TestReplayDriver_CaptureRegion(t *testing.T) {
+	frame := image.NewRGBA(image.Rect(0, 0, 100, 80))
+	frame.Set(30, 20, color.RGBA{R: 255, A: 255})
+
+	d := NewReplayDriver(&ReplayDriverConfig{Frames: []image.Image{frame}})
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	img, err := d.CaptureRegion(context.Background(), image.Rect(30, 20, 40, 25))
+	if err != nil {
+		t.Fatalf("CaptureRegion() error = %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 10, 5) {
+		t.Errorf("bounds = %v, want (0,0)-(10,5)", img.Bounds())
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+		t.Error("region origin should map to the frame pixel at (30,20)")
+	}
+
+	if _, err := d.CaptureRegion(context.Background(), image.Rect(90, 70, 110, 90)); err == nil {
+		t.Error("region outside the frame should fail")
+	}
+	if _, err := d.CaptureRegion(context.Background(), image.Rectangle{}); err == nil {
+		t.Error("empty region should fail")
+	}
 }
 
 func TestNewChromeDPDriver(t *testing.T) {
@@ -69,6 +377,13 @@ func TestNewChromeDPDriver(t *testing.T) {
 		if driver.config.WindowWidth != 1920 {
 			t.Error("Custom config not applied")
 		}
+		if driver.config.Timeouts != DefaultOperationTimeouts() {
+			t.Errorf("Timeouts = %+v, want the defaults", driver.config.Timeouts)
+		}
+		// The caller's config may be shared and must not be modified
+		if config.Timeouts != (OperationTimeouts{}) || config.Login.URLTemplate != "" {
+			t.Errorf("caller's config modified: %+v", config)
+		}
 	})
 }
 
@@ -128,3 +443,13 @@ func TestCookie(t *testing.T) {
 		t.Error("Secure should be true")
 	}
 }
+
+func TestCookieExpiry(t *testing.T) {
+	if got := cookieExpiry(-1); !got.IsZero() {
+		t.Errorf("cookieExpiry(-1) = %v, want zero for a session cookie", got)
+	}
+	want := time.Unix(1750000000, 500000000)
+	if got := cookieExpiry(1750000000.5); !got.Equal(want) {
+		t.Errorf("cookieExpiry() = %v, want %v", got, want)
+	}
+}
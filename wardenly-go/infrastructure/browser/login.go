@@ -0,0 +1,133 @@
+package browser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"wardenly-go/infrastructure/appdir"
+)
+
+// ServerPlaceholder is replaced by the account's server ID in a login URL
+// template.
+const ServerPlaceholder = "{server}"
+
+// DefaultURLTemplate is the login page of the official portal.
+const DefaultURLTemplate = "http://www.lequ.com/server/wly/s/" + ServerPlaceholder
+
+// LoginProfile holds the login page address and the CSS selectors used by
+// LoginWithPassword and LoginWithCookies. Empty fields fall back to
+// DefaultLoginProfile, so a portal redesign only needs the changed ones.
+type LoginProfile struct {
+	// URLTemplate is the login page, with ServerPlaceholder standing for
+	// the server ID. Accounts may override it.
+	URLTemplate string `json:"urlTemplate,omitempty"`
+	// Username is the username input
+	Username string `json:"username,omitempty"`
+	// Password is the password input
+	Password string `json:"password,omitempty"`
+	// Submit is the button that submits the login form
+	Submit string `json:"submit,omitempty"`
+	// Ready appears once the game has loaded after login
+	Ready string `json:"ready,omitempty"`
+	// Frame is the element the game runs in. Its position is detected
+	// after login so scenes and scripts don't depend on the portal layout.
+	Frame string `json:"frame,omitempty"`
+	// FrameOrigin is where Frame's content started when scene and script
+	// coordinates were recorded; they are shifted by how far the frame has
+	// moved since. {0, 0} makes coordinates relative to the game. Nil
+	// leaves coordinates untranslated.
+	FrameOrigin *Point `json:"frameOrigin,omitempty"`
+}
+
+// DefaultLoginProfile returns the selectors of the current login portal.
+func DefaultLoginProfile() LoginProfile {
+	return LoginProfile{
+		URLTemplate: DefaultURLTemplate,
+		Username:    `#username`,
+		Password:    `#userpwd`,
+		Submit:      `#form1 > div.r06 > div.login_box3 > p > input`,
+		Ready:       `#S_Iframe`,
+		Frame:       `#S_Iframe`,
+	}
+}
+
+// withDefaults fills empty fields from DefaultLoginProfile.
+func (p LoginProfile) withDefaults() LoginProfile {
+	def := DefaultLoginProfile()
+	if p.URLTemplate == "" {
+		p.URLTemplate = def.URLTemplate
+	}
+	if p.Username == "" {
+		p.Username = def.Username
+	}
+	if p.Password == "" {
+		p.Password = def.Password
+	}
+	if p.Submit == "" {
+		p.Submit = def.Submit
+	}
+	if p.Ready == "" {
+		p.Ready = def.Ready
+	}
+	if p.Frame == "" {
+		p.Frame = def.Frame
+	}
+	return p
+}
+
+// LoginURL returns the login page of a server from template, or from
+// DefaultURLTemplate if template is empty. A template without
+// ServerPlaceholder is used as is.
+func LoginURL(template string, serverID int) string {
+	if template == "" {
+		template = DefaultURLTemplate
+	}
+	return strings.ReplaceAll(template, ServerPlaceholder, strconv.Itoa(serverID))
+}
+
+// DefaultLoginProfilePath returns where a calibrated login profile is stored.
+func DefaultLoginProfilePath() string {
+	return appdir.Path("login_profile.json")
+}
+
+// LoadLoginProfile reads a login profile saved by SaveLoginProfile over
+// base, the configured profile. Fields the file leaves out keep base's, and
+// those still empty the default. A missing file yields base.
+func LoadLoginProfile(path string, base LoginProfile) (LoginProfile, error) {
+	base = base.withDefaults()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return base, nil
+	}
+	if err != nil {
+		return base, fmt.Errorf("failed to read login profile: %w", err)
+	}
+
+	p := base
+	if err := json.Unmarshal(data, &p); err != nil {
+		return base, fmt.Errorf("failed to parse login profile %s: %w", path, err)
+	}
+	return p.withDefaults(), nil
+}
+
+// SaveLoginProfile writes a login profile, creating its directory.
+// URLTemplate is not saved, so the configured template keeps applying.
+func SaveLoginProfile(path string, p LoginProfile) error {
+	p.URLTemplate = ""
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode login profile: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create login profile dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write login profile: %w", err)
+	}
+	return nil
+}
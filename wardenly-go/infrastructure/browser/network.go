@@ -0,0 +1,70 @@
+package browser
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Response is an XHR or fetch response observed with WatchResponses.
+type Response struct {
+	URL      string
+	Status   int
+	MIMEType string
+	// Body is empty if the browser no longer had it
+	Body []byte
+}
+
+// urlPatterns compiles URL patterns, where * matches any run of characters
+// and the rest is literal, into one expression matching a whole URL. It
+// follows the patterns of the DevTools Network.setBlockedURLs command.
+func urlPatterns(patterns ...string) *regexp.Regexp {
+	alternatives := make([]string, len(patterns))
+	for i, p := range patterns {
+		parts := strings.Split(p, "*")
+		for j, part := range parts {
+			parts[j] = regexp.QuoteMeta(part)
+		}
+		alternatives[i] = strings.Join(parts, ".*")
+	}
+	return regexp.MustCompile(`^(?:` + strings.Join(alternatives, "|") + `)$`)
+}
+
+// MatchURL reports whether url matches a URL pattern of BlockURLs or
+// WatchResponses.
+func MatchURL(pattern, url string) bool {
+	return urlPatterns(pattern).MatchString(url)
+}
+
+// responseStream delivers watched responses until it is closed. Responses
+// are dropped while the buffer is full.
+type responseStream struct {
+	mu     sync.Mutex
+	ch     chan Response
+	closed bool
+}
+
+func newResponseStream() *responseStream {
+	return &responseStream{ch: make(chan Response, 16)}
+}
+
+func (s *responseStream) send(resp Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- resp:
+	default:
+	}
+}
+
+func (s *responseStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
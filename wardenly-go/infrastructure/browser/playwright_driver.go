@@ -0,0 +1,933 @@
+//go:build playwright
+
+package browser
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// PlaywrightDriver implements Driver using playwright-go with Chromium.
+// It offers the same surface as ChromeDPDriver so the two engines can be
+// compared on the same accounts and scripts.
+//
+// Playwright calls take a timeout instead of a context, so the operation
+// class timeout and the caller's deadline are folded into one timeout.
+// A caller cancelling mid-call waits for that timeout at most.
+type PlaywrightDriver struct {
+	config  *DriverConfig
+	mu      sync.Mutex
+	running bool
+
+	pw      *playwright.Playwright
+	browser playwright.Browser // Nil when a persistent context is used
+	context playwright.BrowserContext
+	page    playwright.Page
+
+	// blocked matches the URLs aborted by the route BlockURLs installed
+	blocked *regexp.Regexp
+
+	// done is closed once the browser context closes, including crashes
+	done      chan struct{}
+	closeDone func()
+
+	// Screencast state (Chromium DevTools session on the page)
+	screencastCDP playwright.CDPSession
+	screencasting bool
+	frameMu       sync.Mutex // Guards frameChan against the CDP event goroutine
+	frameChan     chan image.Image
+}
+
+// NewPlaywrightDriver creates a new Playwright-based browser driver.
+func NewPlaywrightDriver(config *DriverConfig) *PlaywrightDriver {
+	if config == nil {
+		config = DefaultDriverConfig()
+	}
+	config = config.withDefaults()
+	return &PlaywrightDriver{
+		config: config,
+	}
+}
+
+func newPlaywrightDriver(config *DriverConfig) Driver {
+	return NewPlaywrightDriver(config)
+}
+
+// playwrightAvailable reports that this build includes the Playwright engine.
+const playwrightAvailable = true
+
+// opPage returns the page and the timeout in milliseconds for one operation.
+func (d *PlaywrightDriver) opPage(ctx context.Context, timeout time.Duration) (playwright.Page, *float64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < timeout {
+			timeout = remaining
+		}
+	}
+
+	d.mu.Lock()
+	page := d.page
+	running := d.running
+	d.mu.Unlock()
+
+	if !running || page == nil {
+		return nil, nil, fmt.Errorf("browser not running")
+	}
+	return page, playwright.Float(float64(timeout.Milliseconds())), nil
+}
+
+// launchArgs mirrors the ChromeDP allocator flags.
+func (d *PlaywrightDriver) launchArgs() []string {
+	args := []string{
+		"--disable-infobars",
+		fmt.Sprintf("--window-size=%d,%d", d.config.WindowWidth, d.config.WindowHeight),
+	}
+	if d.config.HideScrollbars {
+		args = append(args, "--hide-scrollbars")
+	}
+	if d.config.MuteAudio {
+		args = append(args, "--mute-audio")
+	}
+	if d.config.DisableGPU {
+		args = append(args, "--disable-gpu")
+	}
+	if d.config.DisableWebSecurity {
+		args = append(args, "--disable-web-security")
+	}
+	return args
+}
+
+// Start launches the Playwright driver process and a Chromium instance.
+func (d *PlaywrightDriver) Start(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.running {
+		return fmt.Errorf("browser already running")
+	}
+
+	pw, err := playwright.Run()
+	if err != nil {
+		return fmt.Errorf("failed to start playwright (install it with `go run github.com/playwright-community/playwright-go/cmd/playwright install chromium`): %w", err)
+	}
+
+	viewport := &playwright.Size{Width: d.config.ViewportWidth, Height: d.config.ViewportHeight}
+	var browser playwright.Browser
+	var browserCtx playwright.BrowserContext
+
+	if d.config.UserDataDir != "" {
+		browserCtx, err = pw.Chromium.LaunchPersistentContext(d.config.UserDataDir, playwright.BrowserTypeLaunchPersistentContextOptions{
+			Headless:          playwright.Bool(d.config.Headless),
+			Args:              d.launchArgs(),
+			Viewport:          viewport,
+			Proxy:             d.proxy(),
+			DeviceScaleFactor: playwright.Float(d.config.deviceScaleFactor()),
+			IsMobile:          playwright.Bool(d.config.Mobile),
+			HasTouch:          playwright.Bool(d.config.Mobile),
+		})
+	} else {
+		browser, err = pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
+			Headless: playwright.Bool(d.config.Headless),
+			Args:     d.launchArgs(),
+			Proxy:    d.proxy(),
+		})
+		if err == nil {
+			browserCtx, err = browser.NewContext(playwright.BrowserNewContextOptions{
+				Viewport:          viewport,
+				DeviceScaleFactor: playwright.Float(d.config.deviceScaleFactor()),
+				IsMobile:          playwright.Bool(d.config.Mobile),
+				HasTouch:          playwright.Bool(d.config.Mobile),
+			})
+		}
+	}
+	if err != nil {
+		d.release(pw, browser, browserCtx)
+		return fmt.Errorf("failed to launch chromium: %w", err)
+	}
+
+	page, err := browserCtx.NewPage()
+	if err != nil {
+		d.release(pw, browser, browserCtx)
+		return fmt.Errorf("failed to open page: %w", err)
+	}
+	if scale := d.config.pageScale(); scale != 1 {
+		if err := setPageScale(browserCtx, page, scale); err != nil {
+			d.release(pw, browser, browserCtx)
+			return fmt.Errorf("failed to set page scale: %w", err)
+		}
+	}
+	if err := d.blockURLs(browserCtx, d.config.BlockedURLs); err != nil {
+		d.release(pw, browser, browserCtx)
+		return err
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+	closeDone := func() { once.Do(func() { close(done) }) }
+	browserCtx.OnClose(func(playwright.BrowserContext) { closeDone() })
+
+	d.pw, d.browser, d.context, d.page = pw, browser, browserCtx, page
+	d.done, d.closeDone = done, closeDone
+	d.running = true
+	return nil
+}
+
+// setPageScale zooms the page through CDP, which Playwright has no
+// option for.
+func setPageScale(browserCtx playwright.BrowserContext, page playwright.Page, scale float64) error {
+	session, err := browserCtx.NewCDPSession(page)
+	if err != nil {
+		return err
+	}
+	_, err = session.Send("Emulation.setPageScaleFactor", map[string]interface{}{"pageScaleFactor": scale})
+	return err
+}
+
+// proxy converts the configured proxy; Playwright answers credential
+// challenges itself.
+func (d *PlaywrightDriver) proxy() *playwright.Proxy {
+	p := d.config.Proxy
+	if p == nil {
+		return nil
+	}
+	proxy := &playwright.Proxy{Server: p.Server()}
+	if p.HasCredentials() {
+		proxy.Username = playwright.String(p.Username)
+		proxy.Password = playwright.String(p.Password)
+	}
+	return proxy
+}
+
+// release closes whatever part of the browser stack was created.
+func (d *PlaywrightDriver) release(pw *playwright.Playwright, browser playwright.Browser, browserCtx playwright.BrowserContext) {
+	if browserCtx != nil {
+		_ = browserCtx.Close()
+	}
+	if browser != nil {
+		_ = browser.Close()
+	}
+	if pw != nil {
+		_ = pw.Stop()
+	}
+}
+
+// Stop closes the browser and releases resources.
+func (d *PlaywrightDriver) Stop() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.running {
+		return nil
+	}
+
+	if d.screencasting {
+		d.stopScreencastInternal()
+	}
+
+	d.running = false
+	d.release(d.pw, d.browser, d.context)
+	d.closeDone()
+	d.pw, d.browser, d.context, d.page = nil, nil, nil, nil
+	d.done, d.closeDone = nil, nil
+	return nil
+}
+
+// IsRunning returns true if the browser is active.
+func (d *PlaywrightDriver) IsRunning() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.running
+}
+
+// Done returns a channel that is closed when the browser context closes.
+func (d *PlaywrightDriver) Done() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return nil
+	}
+	return d.done
+}
+
+// Navigate navigates to the specified URL.
+func (d *PlaywrightDriver) Navigate(ctx context.Context, url string) error {
+	page, timeout, err := d.opPage(ctx, d.config.Timeouts.Navigation)
+	if err != nil {
+		return err
+	}
+
+	_, err = page.Goto(url, playwright.PageGotoOptions{Timeout: timeout})
+	return err
+}
+
+// Reload refreshes the current page.
+func (d *PlaywrightDriver) Reload(ctx context.Context) error {
+	page, timeout, err := d.opPage(ctx, d.config.Timeouts.Navigation)
+	if err != nil {
+		return err
+	}
+
+	_, err = page.Reload(playwright.PageReloadOptions{Timeout: timeout})
+	return err
+}
+
+// Click performs a mouse click at the specified coordinates.
+func (d *PlaywrightDriver) Click(ctx context.Context, x, y float64) error {
+	page, _, err := d.opPage(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+
+	return page.Mouse().Click(x, y)
+}
+
+// Scroll moves the mouse to (x, y) and turns the wheel there.
+func (d *PlaywrightDriver) Scroll(ctx context.Context, x, y, deltaX, deltaY float64) error {
+	page, _, err := d.opPage(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+
+	if err := page.Mouse().Move(x, y); err != nil {
+		return err
+	}
+	return page.Mouse().Wheel(deltaX, deltaY)
+}
+
+// Drag performs a mouse drag from one point to another.
+// It interpolates intermediate points like the ChromeDP driver.
+func (d *PlaywrightDriver) Drag(ctx context.Context, fromX, fromY, toX, toY float64) error {
+	const steps = DragSteps
+
+	points := make([]Point, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		points = append(points, Point{
+			X: fromX + (toX-fromX)*float64(i)/steps,
+			Y: fromY + (toY-fromY)*float64(i)/steps,
+		})
+	}
+	return d.DragPath(ctx, points)
+}
+
+// DragPath performs a mouse drag along a path of points with frame-based
+// timing (60fps), so games see the same movement as with ChromeDP.
+func (d *PlaywrightDriver) DragPath(ctx context.Context, points []Point) error {
+	if len(points) < 2 {
+		return fmt.Errorf("drag requires at least 2 points")
+	}
+
+	page, _, err := d.opPage(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+	dragCtx, cancel := context.WithTimeout(ctx, d.config.Timeouts.Input+time.Duration(len(points))*dragFrameInterval)
+	defer cancel()
+
+	mouse := page.Mouse()
+	if err := mouse.Move(points[0].X, points[0].Y); err != nil {
+		return err
+	}
+	if err := mouse.Down(); err != nil {
+		return err
+	}
+
+	for _, p := range points[1:] {
+		if err := mouse.Move(p.X, p.Y); err != nil {
+			return err
+		}
+		if err := sleepContext(dragCtx, dragFrameInterval); err != nil {
+			// Don't leave the button pressed
+			_ = mouse.Up()
+			return err
+		}
+	}
+
+	return mouse.Up()
+}
+
+// CaptureScreen captures the current browser screen.
+func (d *PlaywrightDriver) CaptureScreen(ctx context.Context) (image.Image, error) {
+	page, timeout, err := d.opPage(ctx, d.config.Timeouts.Capture)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := page.Screenshot(playwright.PageScreenshotOptions{
+		Type:    playwright.ScreenshotTypePng,
+		Timeout: timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+
+	return img, nil
+}
+
+// CaptureRegion captures a rectangle of the viewport using a screenshot clip.
+func (d *PlaywrightDriver) CaptureRegion(ctx context.Context, rect image.Rectangle) (image.Image, error) {
+	if err := checkRegion(rect); err != nil {
+		return nil, err
+	}
+	page, timeout, err := d.opPage(ctx, d.config.Timeouts.Capture)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := page.Screenshot(playwright.PageScreenshotOptions{
+		Type: playwright.ScreenshotTypePng,
+		Clip: &playwright.Rect{
+			X:      float64(rect.Min.X),
+			Y:      float64(rect.Min.Y),
+			Width:  float64(rect.Dx()),
+			Height: float64(rect.Dy()),
+		},
+		Timeout: timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture region: %w", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+
+	return img, nil
+}
+
+// CaptureElement captures the element matching selector.
+func (d *PlaywrightDriver) CaptureElement(ctx context.Context, selector string) (image.Image, error) {
+	page, timeout, err := d.opPage(ctx, d.config.Timeouts.Capture)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := page.Locator(selector).First().Screenshot(playwright.LocatorScreenshotOptions{
+		Type:    playwright.ScreenshotTypePng,
+		Timeout: timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture element %s: %w", selector, err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+
+	return img, nil
+}
+
+// frameOriginScript returns the content box corner of the element
+// matching its selector argument, or null without one.
+const frameOriginScript = `sel => {
+	const el = document.querySelector(sel);
+	if (!el) return null;
+	const r = el.getBoundingClientRect(), s = getComputedStyle(el);
+	return [r.left + el.clientLeft + parseFloat(s.paddingLeft), r.top + el.clientTop + parseFloat(s.paddingTop)];
+}`
+
+// FrameOrigin evaluates the element's content box in the page.
+func (d *PlaywrightDriver) FrameOrigin(ctx context.Context, selector string) (Point, error) {
+	page, timeout, err := d.opPage(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return Point{}, err
+	}
+
+	if err := page.Locator(selector).First().WaitFor(playwright.LocatorWaitForOptions{
+		State:   playwright.WaitForSelectorStateAttached,
+		Timeout: timeout,
+	}); err != nil {
+		return Point{}, fmt.Errorf("failed to locate %s: %w", selector, err)
+	}
+	result, err := page.Evaluate(frameOriginScript, selector)
+	if err != nil {
+		return Point{}, fmt.Errorf("failed to locate %s: %w", selector, err)
+	}
+	corner, ok := result.([]interface{})
+	if !ok || len(corner) != 2 {
+		return Point{}, fmt.Errorf("element %s not found", selector)
+	}
+	x, xok := toFloat(corner[0])
+	y, yok := toFloat(corner[1])
+	if !xok || !yok {
+		return Point{}, fmt.Errorf("element %s has no layout", selector)
+	}
+	return Point{X: x, Y: y}, nil
+}
+
+// toFloat converts a number returned by page.Evaluate.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// SetViewport sets the browser viewport size.
+func (d *PlaywrightDriver) SetViewport(ctx context.Context, width, height int) error {
+	page, _, err := d.opPage(ctx, d.config.Timeouts.Storage)
+	if err != nil {
+		return err
+	}
+
+	return page.SetViewportSize(width, height)
+}
+
+// PreparePage applies the configured viewport.
+func (d *PlaywrightDriver) PreparePage(ctx context.Context) error {
+	return d.SetViewport(ctx, d.config.ViewportWidth, d.config.ViewportHeight)
+}
+
+// WaitVisible waits for an element to become visible.
+// The caller's deadline applies when set; otherwise the navigation timeout bounds the wait.
+func (d *PlaywrightDriver) WaitVisible(ctx context.Context, selector string) error {
+	page, timeout, err := d.opPage(ctx, d.config.Timeouts.Navigation)
+	if err != nil {
+		return err
+	}
+
+	return page.Locator(selector).WaitFor(playwright.LocatorWaitForOptions{
+		State:   playwright.WaitForSelectorStateVisible,
+		Timeout: timeout,
+	})
+}
+
+// SendKeys fills an element with text.
+func (d *PlaywrightDriver) SendKeys(ctx context.Context, selector, text string) error {
+	page, timeout, err := d.opPage(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+
+	return page.Locator(selector).Fill(text, playwright.LocatorFillOptions{Timeout: timeout})
+}
+
+// TypeText types text into the focused element.
+func (d *PlaywrightDriver) TypeText(ctx context.Context, text string) error {
+	page, _, err := d.opPage(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+
+	return page.Keyboard().Type(text)
+}
+
+// KeyDown presses a key without releasing it.
+func (d *PlaywrightDriver) KeyDown(ctx context.Context, key string) error {
+	page, _, err := d.opPage(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+
+	return page.Keyboard().Down(key)
+}
+
+// KeyUp releases a key.
+func (d *PlaywrightDriver) KeyUp(ctx context.Context, key string) error {
+	page, _, err := d.opPage(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+
+	return page.Keyboard().Up(key)
+}
+
+// KeyPress presses and releases a key.
+func (d *PlaywrightDriver) KeyPress(ctx context.Context, key string) error {
+	page, _, err := d.opPage(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+
+	return page.Keyboard().Press(key)
+}
+
+// ClickElement clicks on an element by selector.
+func (d *PlaywrightDriver) ClickElement(ctx context.Context, selector string) error {
+	page, timeout, err := d.opPage(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+
+	return page.Locator(selector).Click(playwright.LocatorClickOptions{Timeout: timeout})
+}
+
+// Ping waits until the page renders its next frame.
+// Playwright awaits the returned promise itself.
+func (d *PlaywrightDriver) Ping(ctx context.Context) error {
+	page, _, err := d.opPage(ctx, d.config.Timeouts.Input)
+	if err != nil {
+		return err
+	}
+
+	_, err = page.Evaluate(pingScript)
+	return err
+}
+
+// Evaluate runs the expression in the page's main frame. Playwright
+// awaits promises itself and calls the expression if it is a function.
+func (d *PlaywrightDriver) Evaluate(ctx context.Context, expression string) (json.RawMessage, error) {
+	page, _, err := d.opPage(ctx, d.config.Timeouts.Navigation)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := page.Evaluate(expression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate script: %w", err)
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode script result: %w", err)
+	}
+	return raw, nil
+}
+
+// GetCookies retrieves all browser cookies.
+// Playwright does not expose the source port, scheme or priority.
+func (d *PlaywrightDriver) GetCookies(ctx context.Context) ([]Cookie, error) {
+	if _, _, err := d.opPage(ctx, d.config.Timeouts.Storage); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	browserCtx := d.context
+	d.mu.Unlock()
+
+	pwCookies, err := browserCtx.Cookies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cookies: %w", err)
+	}
+
+	cookies := make([]Cookie, len(pwCookies))
+	for i, c := range pwCookies {
+		cookies[i] = Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			HTTPOnly: c.HttpOnly,
+			Secure:   c.Secure,
+			Expires:  cookieExpiry(c.Expires),
+		}
+	}
+
+	return cookies, nil
+}
+
+// SetCookies sets browser cookies.
+func (d *PlaywrightDriver) SetCookies(ctx context.Context, cookies []Cookie) error {
+	if _, _, err := d.opPage(ctx, d.config.Timeouts.Storage); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	browserCtx := d.context
+	d.mu.Unlock()
+
+	return browserCtx.AddCookies(toPlaywrightCookies(cookies))
+}
+
+func toPlaywrightCookies(cookies []Cookie) []playwright.OptionalCookie {
+	pwCookies := make([]playwright.OptionalCookie, len(cookies))
+	for i, c := range cookies {
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		pwCookies[i] = playwright.OptionalCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   playwright.String(c.Domain),
+			Path:     playwright.String(path),
+			HttpOnly: playwright.Bool(c.HTTPOnly),
+			Secure:   playwright.Bool(c.Secure),
+		}
+		if !c.Expires.IsZero() {
+			pwCookies[i].Expires = playwright.Float(float64(c.Expires.Unix()))
+		}
+	}
+	return pwCookies
+}
+
+// BlockURLs aborts matching requests with a route on the browser context.
+func (d *PlaywrightDriver) BlockURLs(ctx context.Context, patterns []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running || d.context == nil {
+		return fmt.Errorf("browser not running")
+	}
+	return d.blockURLs(d.context, patterns)
+}
+
+// blockURLs replaces the blocking route (must be called with lock held).
+func (d *PlaywrightDriver) blockURLs(browserCtx playwright.BrowserContext, patterns []string) error {
+	if d.blocked != nil {
+		if err := browserCtx.Unroute(d.blocked); err != nil {
+			return fmt.Errorf("failed to unblock URLs: %w", err)
+		}
+		d.blocked = nil
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	blocked := urlPatterns(patterns...)
+	err := browserCtx.Route(blocked, func(route playwright.Route) {
+		_ = route.Abort("blockedbyclient")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to block URLs: %w", err)
+	}
+	d.blocked = blocked
+	return nil
+}
+
+// WatchResponses listens for the page's responses. The listener stays
+// registered after the channel closes but no longer delivers.
+func (d *PlaywrightDriver) WatchResponses(ctx context.Context, pattern string) (<-chan Response, error) {
+	d.mu.Lock()
+	page, done, running := d.page, d.done, d.running
+	d.mu.Unlock()
+	if !running || page == nil {
+		return nil, fmt.Errorf("browser not running")
+	}
+
+	match := urlPatterns(pattern)
+	stream := newResponseStream()
+	page.OnResponse(func(resp playwright.Response) {
+		if kind := resp.Request().ResourceType(); kind != "xhr" && kind != "fetch" {
+			return
+		}
+		if !match.MatchString(resp.URL()) {
+			return
+		}
+		// Body waits for the response to finish, so it is read off the
+		// event loop
+		go func() {
+			body, _ := resp.Body()
+			mimeType, _, _ := strings.Cut(resp.Headers()["content-type"], ";")
+			stream.send(Response{URL: resp.URL(), Status: resp.Status(), MIMEType: strings.TrimSpace(mimeType), Body: body})
+		}()
+	})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		stream.close()
+	}()
+	return stream.ch, nil
+}
+
+// LoginWithPassword performs the login flow with username and password,
+// using the page elements of the configured login profile.
+func (d *PlaywrightDriver) LoginWithPassword(ctx context.Context, url, username, password string, timeoutSeconds int) error {
+	// Step 1: Set viewport and navigate (bounded by the navigation timeout)
+	if err := d.SetViewport(ctx, d.config.ViewportWidth, d.config.ViewportHeight); err != nil {
+		return fmt.Errorf("start page failure: %w", err)
+	}
+	if err := d.Navigate(ctx, url); err != nil {
+		return fmt.Errorf("start page failure: %w", err)
+	}
+
+	// Step 2: Wait for login form, enter credentials, and submit (with timeout)
+	loginCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	login := d.config.Login
+	err := d.WaitVisible(loginCtx, login.Username)
+	if err == nil {
+		err = d.SendKeys(loginCtx, login.Username, username)
+	}
+	if err == nil {
+		err = d.SendKeys(loginCtx, login.Password, password)
+	}
+	if err == nil {
+		err = d.ClickElement(loginCtx, login.Submit)
+	}
+	if err == nil {
+		err = d.WaitVisible(loginCtx, login.Ready)
+	}
+	return loginError(ctx, loginCtx, err, timeoutSeconds)
+}
+
+// LoginWithCookies performs login using stored cookies.
+func (d *PlaywrightDriver) LoginWithCookies(ctx context.Context, url string, cookies []Cookie, timeoutSeconds int) error {
+	// Step 1: Set cookies and navigate (bounded by the navigation timeout)
+	if err := d.SetCookies(ctx, cookies); err != nil {
+		return fmt.Errorf("start page failure: %w", err)
+	}
+	if err := d.SetViewport(ctx, d.config.ViewportWidth, d.config.ViewportHeight); err != nil {
+		return fmt.Errorf("start page failure: %w", err)
+	}
+	if err := d.Navigate(ctx, url); err != nil {
+		return fmt.Errorf("start page failure: %w", err)
+	}
+
+	// Step 2: Wait for game iframe (with timeout)
+	loginCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	return loginError(ctx, loginCtx, d.WaitVisible(loginCtx, d.config.Login.Ready), timeoutSeconds)
+}
+
+// loginError reports a timed-out login the same way as the ChromeDP driver.
+// Playwright signals its own timeout rather than the context's, so the
+// remaining time of loginCtx decides.
+func loginError(ctx, loginCtx context.Context, err error, timeoutSeconds int) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() == nil {
+		if deadline, ok := loginCtx.Deadline(); ok && time.Until(deadline) < time.Second {
+			return fmt.Errorf("login timeout after %ds (server may be down or in maintenance)", timeoutSeconds)
+		}
+	}
+	return fmt.Errorf("login failure: %w", err)
+}
+
+// StartScreencast starts frame streaming through a Chromium DevTools session,
+// the same mechanism the ChromeDP driver uses.
+func (d *PlaywrightDriver) StartScreencast(ctx context.Context, quality, maxFPS int) (<-chan image.Image, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.running || d.page == nil {
+		return nil, fmt.Errorf("browser not running")
+	}
+
+	if d.screencasting {
+		return nil, fmt.Errorf("screencast already active")
+	}
+
+	cdp, err := d.context.NewCDPSession(d.page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open devtools session: %w", err)
+	}
+
+	frames := make(chan image.Image, 5) // Buffer a few frames
+	d.frameMu.Lock()
+	d.frameChan = frames
+	d.frameMu.Unlock()
+	d.screencastCDP = cdp
+	d.screencasting = true
+
+	// Runs on the Playwright event goroutine; must not block on a CDP reply
+	cdp.On("Page.screencastFrame", func(params map[string]interface{}) {
+		// Acknowledge the frame to receive the next one
+		go func() {
+			_, _ = cdp.Send("Page.screencastFrameAck", map[string]interface{}{
+				"sessionId": params["sessionId"],
+			})
+		}()
+
+		data, _ := params["data"].(string)
+		frameData, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return
+		}
+		img, err := jpeg.Decode(bytes.NewReader(frameData))
+		if err != nil {
+			return
+		}
+
+		d.frameMu.Lock()
+		defer d.frameMu.Unlock()
+		if d.frameChan == nil {
+			return
+		}
+		select {
+		case d.frameChan <- img:
+		default:
+			// Channel full, drop frame
+		}
+	})
+
+	_, err = cdp.Send("Page.startScreencast", map[string]interface{}{
+		"format":        "jpeg",
+		"quality":       quality,
+		"maxWidth":      d.config.ViewportWidth,
+		"maxHeight":     d.config.ViewportHeight,
+		"everyNthFrame": 60 / maxFPS, // Convert FPS to frame skip
+	})
+	if err != nil {
+		d.stopScreencastInternal()
+		return nil, fmt.Errorf("failed to start screencast: %w", err)
+	}
+
+	return frames, nil
+}
+
+// StopScreencast stops frame streaming.
+func (d *PlaywrightDriver) StopScreencast() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.screencasting {
+		return nil
+	}
+
+	return d.stopScreencastInternal()
+}
+
+// stopScreencastInternal stops screencast without locking (must be called with lock held).
+func (d *PlaywrightDriver) stopScreencastInternal() error {
+	if !d.screencasting {
+		return nil
+	}
+
+	if d.screencastCDP != nil {
+		_, _ = d.screencastCDP.Send("Page.stopScreencast", nil)
+		_ = d.screencastCDP.Detach()
+		d.screencastCDP = nil
+	}
+
+	// Close channel once no frame handler can send to it
+	d.frameMu.Lock()
+	if d.frameChan != nil {
+		close(d.frameChan)
+		d.frameChan = nil
+	}
+	d.frameMu.Unlock()
+
+	d.screencasting = false
+	return nil
+}
+
+// IsScreencasting returns true if screencast is active.
+func (d *PlaywrightDriver) IsScreencasting() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.screencasting
+}
+
+// Ensure PlaywrightDriver implements Driver
+var _ Driver = (*PlaywrightDriver)(nil)
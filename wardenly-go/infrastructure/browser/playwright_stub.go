@@ -0,0 +1,11 @@
+//go:build !playwright
+
+package browser
+
+// playwrightAvailable reports that this build has no Playwright engine.
+// Build with -tags playwright to include it.
+const playwrightAvailable = false
+
+func newPlaywrightDriver(config *DriverConfig) Driver {
+	return nil
+}
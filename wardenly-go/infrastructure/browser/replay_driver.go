@@ -0,0 +1,477 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg" // register JPEG decoder for LoadReplayFrames
+	_ "image/png"  // register PNG decoder for LoadReplayFrames
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReplayDriver is a Driver that replays recorded frames instead of running a
+// browser. Every capture returns the next frame in order (wrapping around),
+// input actions only count and wait for the configured latency, and logins
+// always succeed. It lets sessions run without Chrome, e.g. in load tests.
+type ReplayDriver struct {
+	frames  []image.Image
+	latency time.Duration
+
+	running atomic.Bool
+	next    atomic.Uint64
+
+	mu          sync.Mutex
+	done        chan struct{} // Closed by Stop or Crash
+	cookies     []Cookie
+	castCancel  context.CancelFunc
+	castStopped chan struct{}
+
+	clicks   atomic.Int64
+	drags    atomic.Int64
+	captures atomic.Int64
+}
+
+// ReplayDriverConfig holds configuration for the ReplayDriver.
+type ReplayDriverConfig struct {
+	// Frames are returned by captures and the screencast in order.
+	// A single blank frame is used when empty.
+	Frames []image.Image
+
+	// Latency is added to every browser operation to mimic a real page.
+	Latency time.Duration
+}
+
+// NewReplayDriver creates a driver that replays the given frames.
+func NewReplayDriver(cfg *ReplayDriverConfig) *ReplayDriver {
+	if cfg == nil {
+		cfg = &ReplayDriverConfig{}
+	}
+	frames := cfg.Frames
+	if len(frames) == 0 {
+		frames = []image.Image{image.NewRGBA(image.Rect(0, 0, 1080, 720))}
+	}
+	return &ReplayDriver{
+		frames:  frames,
+		latency: cfg.Latency,
+	}
+}
+
+// LoadReplayFrames reads all PNG and JPEG images in dir, ordered by file name.
+func LoadReplayFrames(dir string) ([]image.Image, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frame directory: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".png", ".jpg", ".jpeg":
+			if !e.IsDir() {
+				names = append(names, e.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	frames := make([]image.Image, 0, len(names))
+	for _, name := range names {
+		img, err := decodeFrame(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, img)
+	}
+	return frames, nil
+}
+
+func decodeFrame(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath.Base(path), err)
+	}
+	return img, nil
+}
+
+// Counts returns the number of clicks, drags and captures performed so far.
+func (d *ReplayDriver) Counts() (clicks, drags, captures int64) {
+	return d.clicks.Load(), d.drags.Load(), d.captures.Load()
+}
+
+// wait sleeps for the configured latency unless ctx ends first.
+func (d *ReplayDriver) wait(ctx context.Context) error {
+	if d.latency <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d.latency):
+		return nil
+	}
+}
+
+// checkRunning returns an error unless the driver has been started.
+func (d *ReplayDriver) checkRunning() error {
+	if !d.running.Load() {
+		return fmt.Errorf("browser not started")
+	}
+	return nil
+}
+
+// nextFrame returns the next frame in replay order.
+func (d *ReplayDriver) nextFrame() image.Image {
+	i := d.next.Add(1) - 1
+	return d.frames[i%uint64(len(d.frames))]
+}
+
+func (d *ReplayDriver) Start(ctx context.Context) error {
+	if err := d.wait(ctx); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.done = make(chan struct{})
+	d.mu.Unlock()
+	d.running.Store(true)
+	return nil
+}
+
+func (d *ReplayDriver) Stop() error {
+	d.StopScreencast()
+	d.exit()
+	return nil
+}
+
+// Crash makes the browser exit as if its process died: operations fail
+// and Done closes until the driver is started again.
+func (d *ReplayDriver) Crash() {
+	d.exit()
+}
+
+func (d *ReplayDriver) exit() {
+	d.running.Store(false)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.done != nil {
+		close(d.done)
+		d.done = nil
+	}
+}
+
+func (d *ReplayDriver) IsRunning() bool {
+	return d.running.Load()
+}
+
+func (d *ReplayDriver) Done() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.done
+}
+
+func (d *ReplayDriver) Navigate(ctx context.Context, url string) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	return d.wait(ctx)
+}
+
+func (d *ReplayDriver) Reload(ctx context.Context) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	return d.wait(ctx)
+}
+
+func (d *ReplayDriver) Click(ctx context.Context, x, y float64) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	if err := d.wait(ctx); err != nil {
+		return err
+	}
+	d.clicks.Add(1)
+	return nil
+}
+
+func (d *ReplayDriver) Scroll(ctx context.Context, x, y, deltaX, deltaY float64) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	return d.wait(ctx)
+}
+
+func (d *ReplayDriver) Drag(ctx context.Context, fromX, fromY, toX, toY float64) error {
+	return d.DragPath(ctx, []Point{{X: fromX, Y: fromY}, {X: toX, Y: toY}})
+}
+
+func (d *ReplayDriver) DragPath(ctx context.Context, points []Point) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	if len(points) < 2 {
+		return fmt.Errorf("drag path requires at least 2 points")
+	}
+	if err := d.wait(ctx); err != nil {
+		return err
+	}
+	d.drags.Add(1)
+	return nil
+}
+
+func (d *ReplayDriver) CaptureScreen(ctx context.Context) (image.Image, error) {
+	if err := d.checkRunning(); err != nil {
+		return nil, err
+	}
+	if err := d.wait(ctx); err != nil {
+		return nil, err
+	}
+	d.captures.Add(1)
+	return d.nextFrame(), nil
+}
+
+// CaptureRegion crops the next frame to rect.
+func (d *ReplayDriver) CaptureRegion(ctx context.Context, rect image.Rectangle) (image.Image, error) {
+	if err := checkRegion(rect); err != nil {
+		return nil, err
+	}
+	frame, err := d.CaptureScreen(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !rect.In(frame.Bounds()) {
+		return nil, fmt.Errorf("capture region %v is outside the frame %v", rect, frame.Bounds())
+	}
+
+	region := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(region, region.Bounds(), frame, rect.Min, draw.Src)
+	return region, nil
+}
+
+// CaptureElement is not supported because recorded frames have no DOM.
+func (d *ReplayDriver) CaptureElement(ctx context.Context, selector string) (image.Image, error) {
+	if err := d.checkRunning(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("replay driver cannot capture element %s", selector)
+}
+
+// Evaluate is not supported because recorded frames have no page.
+func (d *ReplayDriver) Evaluate(ctx context.Context, expression string) (json.RawMessage, error) {
+	if err := d.checkRunning(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("replay driver cannot evaluate scripts")
+}
+
+// FrameOrigin is not supported because recorded frames have no DOM.
+func (d *ReplayDriver) FrameOrigin(ctx context.Context, selector string) (Point, error) {
+	if err := d.checkRunning(); err != nil {
+		return Point{}, err
+	}
+	return Point{}, fmt.Errorf("replay driver cannot locate %s", selector)
+}
+
+func (d *ReplayDriver) SetViewport(ctx context.Context, width, height int) error {
+	return d.checkRunning()
+}
+
+func (d *ReplayDriver) PreparePage(ctx context.Context) error {
+	return d.checkRunning()
+}
+
+func (d *ReplayDriver) WaitVisible(ctx context.Context, selector string) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	return d.wait(ctx)
+}
+
+func (d *ReplayDriver) SendKeys(ctx context.Context, selector, text string) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	return d.wait(ctx)
+}
+
+func (d *ReplayDriver) TypeText(ctx context.Context, text string) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	return d.wait(ctx)
+}
+
+func (d *ReplayDriver) KeyDown(ctx context.Context, key string) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	return d.wait(ctx)
+}
+
+func (d *ReplayDriver) KeyUp(ctx context.Context, key string) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	return d.wait(ctx)
+}
+
+func (d *ReplayDriver) KeyPress(ctx context.Context, key string) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	return d.wait(ctx)
+}
+
+func (d *ReplayDriver) ClickElement(ctx context.Context, selector string) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	return d.wait(ctx)
+}
+
+func (d *ReplayDriver) Ping(ctx context.Context) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	return d.wait(ctx)
+}
+
+func (d *ReplayDriver) GetCookies(ctx context.Context) ([]Cookie, error) {
+	if err := d.checkRunning(); err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Cookie(nil), d.cookies...), nil
+}
+
+func (d *ReplayDriver) SetCookies(ctx context.Context, cookies []Cookie) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.cookies = append([]Cookie(nil), cookies...)
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *ReplayDriver) BlockURLs(ctx context.Context, patterns []string) error {
+	return d.checkRunning()
+}
+
+// WatchResponses never delivers a response, since replays make no
+// requests; the channel closes with ctx or the browser.
+func (d *ReplayDriver) WatchResponses(ctx context.Context, pattern string) (<-chan Response, error) {
+	if err := d.checkRunning(); err != nil {
+		return nil, err
+	}
+	stream := newResponseStream()
+	done := d.Done()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		stream.close()
+	}()
+	return stream.ch, nil
+}
+
+func (d *ReplayDriver) LoginWithPassword(ctx context.Context, url, username, password string, timeoutSeconds int) error {
+	if err := d.checkRunning(); err != nil {
+		return err
+	}
+	if err := d.wait(ctx); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.cookies = []Cookie{{Name: "replay_session", Value: username, Path: "/"}}
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *ReplayDriver) LoginWithCookies(ctx context.Context, url string, cookies []Cookie, timeoutSeconds int) error {
+	if err := d.SetCookies(ctx, cookies); err != nil {
+		return err
+	}
+	return d.wait(ctx)
+}
+
+// StartScreencast emits the replay frames at maxFPS until stopped.
+func (d *ReplayDriver) StartScreencast(ctx context.Context, quality, maxFPS int) (<-chan image.Image, error) {
+	if err := d.checkRunning(); err != nil {
+		return nil, err
+	}
+	if maxFPS <= 0 {
+		maxFPS = 5
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.castCancel != nil {
+		return nil, fmt.Errorf("screencast already active")
+	}
+
+	castCtx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	d.castCancel = cancel
+	d.castStopped = stopped
+
+	frames := make(chan image.Image, 3)
+	go func() {
+		defer close(stopped)
+		defer close(frames)
+
+		ticker := time.NewTicker(time.Second / time.Duration(maxFPS))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-castCtx.Done():
+				return
+			case <-ticker.C:
+				select {
+				case frames <- d.nextFrame():
+				default:
+					// Consumer is behind; drop the frame like a real screencast
+				}
+			}
+		}
+	}()
+
+	return frames, nil
+}
+
+func (d *ReplayDriver) StopScreencast() error {
+	d.mu.Lock()
+	cancel, stopped := d.castCancel, d.castStopped
+	d.castCancel, d.castStopped = nil, nil
+	d.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-stopped
+	}
+	return nil
+}
+
+func (d *ReplayDriver) IsScreencasting() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.castCancel != nil
+}
+
+// Ensure ReplayDriver implements Driver interface
+var _ Driver = (*ReplayDriver)(nil)
@@ -0,0 +1,78 @@
+// Package captcha sends login captchas to an external solver service.
+package captcha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EnvSolverURL overrides captcha.solverUrl in the config file.
+const EnvSolverURL = "WARDENLY_CAPTCHA_SOLVER_URL"
+
+// DefaultTimeout bounds a solver request; human-backed services can take
+// a while.
+const DefaultTimeout = 2 * time.Minute
+
+// Client asks a solver service for the text of a captcha. The frame is
+// sent as a PNG in a POST and the service answers {"text": "..."}.
+type Client struct {
+	url    string
+	client *http.Client
+}
+
+// NewClient creates a client of the solver at url. timeout bounds each
+// request (DefaultTimeout if zero).
+func NewClient(url string, timeout time.Duration) *Client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Client{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Solve sends img to the solver and returns its answer. The account is
+// not sent.
+func (c *Client) Solve(ctx context.Context, account string, img image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("failed to encode captcha: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &buf)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "image/png")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("captcha solver unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("captcha solver returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var answer struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(body, &answer); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if answer.Text == "" {
+		return "", fmt.Errorf("captcha solver returned no text")
+	}
+	return answer.Text, nil
+}
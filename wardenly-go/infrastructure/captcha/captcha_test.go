@@ -0,0 +1,39 @@
+package captcha
+
+import (
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_Solve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "image/png" {
+			t.Errorf("request = %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if img, err := png.Decode(r.Body); err != nil || img.Bounds().Dx() != 40 {
+			t.Errorf("body is not the frame: %v", err)
+		}
+		if r.URL.Query().Has("empty") {
+			w.Write([]byte(`{"text":""}`))
+			return
+		}
+		w.Write([]byte(`{"text":"x7kq"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 0)
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	text, err := client.Solve(context.Background(), "s1 - Alice", img)
+	if err != nil || text != "x7kq" {
+		t.Fatalf("Solve() = %q, %v, want x7kq", text, err)
+	}
+
+	empty := NewClient(server.URL+"?empty", 0)
+	if _, err := empty.Solve(context.Background(), "s1 - Alice", img); err == nil {
+		t.Error("Solve() should fail without an answer")
+	}
+}
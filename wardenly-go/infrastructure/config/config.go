@@ -0,0 +1,802 @@
+// Package config loads infrastructure settings from an optional YAML or
+// TOML file, with environment variables taking precedence over the file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"wardenly-go/infrastructure/api"
+	"wardenly-go/infrastructure/appdir"
+	"wardenly-go/infrastructure/browser"
+	"wardenly-go/infrastructure/captcha"
+	"wardenly-go/infrastructure/diagnostics"
+	"wardenly-go/infrastructure/eventstream"
+	"wardenly-go/infrastructure/journal"
+	"wardenly-go/infrastructure/logging"
+	"wardenly-go/infrastructure/metrics"
+	"wardenly-go/infrastructure/notify"
+	"wardenly-go/infrastructure/ocr"
+	"wardenly-go/infrastructure/presence"
+	"wardenly-go/infrastructure/repository"
+	"wardenly-go/infrastructure/stats"
+	"wardenly-go/infrastructure/trace"
+	"wardenly-go/infrastructure/update"
+)
+
+// Environment variables read by FromEnv. The OCR settings keep their
+// variables (see ocr.PoolConfigFromEnv), and the sections of the other
+// infrastructure packages are overridden by the variables those packages
+// define, such as api.EnvAddr for api.addr.
+const (
+	EnvPath              = "WARDENLY_CONFIG"
+	EnvLogLevel          = "WARDENLY_LOG_LEVEL"
+	EnvMongoURI          = "WARDENLY_MONGODB_URI"
+	EnvMongoDatabase     = "WARDENLY_MONGODB_DATABASE"
+	EnvHeadless          = "WARDENLY_BROWSER_HEADLESS"
+	EnvScreencastQuality = "WARDENLY_SCREENCAST_QUALITY"
+	EnvScreencastFPS     = "WARDENLY_SCREENCAST_FPS"
+
+	EnvSceneNearMissMargin   = "WARDENLY_SCENE_NEAR_MISS_MARGIN"
+	EnvSceneNearMissCount    = "WARDENLY_SCENE_NEAR_MISS_COUNT"
+	EnvSceneNearMissSessions = "WARDENLY_SCENE_NEAR_MISS_SESSIONS"
+	EnvSceneAutoRelax        = "WARDENLY_SCENE_AUTO_RELAX"
+
+	EnvWatchdogTimeout = "WARDENLY_WATCHDOG_TIMEOUT"
+	EnvWatchdogFreeze  = "WARDENLY_WATCHDOG_FREEZE_TIMEOUT"
+	EnvWatchdogAction  = "WARDENLY_WATCHDOG_ACTION"
+	EnvWatchdogScript  = "WARDENLY_WATCHDOG_SCRIPT"
+
+	EnvReconnectAttempts  = "WARDENLY_RECONNECT_ATTEMPTS"
+	EnvReconnectDelay     = "WARDENLY_RECONNECT_DELAY"
+	EnvReconnectMaxDelay  = "WARDENLY_RECONNECT_MAX_DELAY"
+	EnvLoginAttempts      = "WARDENLY_LOGIN_ATTEMPTS"
+	EnvLoginRetryDelay    = "WARDENLY_LOGIN_RETRY_DELAY"
+	EnvLoginRetryMaxDelay = "WARDENLY_LOGIN_RETRY_MAX_DELAY"
+	EnvCaptchaScene       = "WARDENLY_CAPTCHA_SCENE"
+)
+
+// Config holds the settings of the infrastructure components. Zero values
+// keep each component's default.
+type Config struct {
+	Log         LogConfig         `yaml:"log,omitempty" toml:"log,omitempty"`
+	Store       StoreConfig       `yaml:"store,omitempty" toml:"store,omitempty"`
+	MongoDB     MongoDBConfig     `yaml:"mongodb,omitempty" toml:"mongodb,omitempty"`
+	OCR         OCRConfig         `yaml:"ocr,omitempty" toml:"ocr,omitempty"`
+	Browser     BrowserConfig     `yaml:"browser,omitempty" toml:"browser,omitempty"`
+	Login       LoginConfig       `yaml:"login,omitempty" toml:"login,omitempty"`
+	LoginRetry  LoginRetryConfig  `yaml:"loginRetry,omitempty" toml:"loginRetry,omitempty"`
+	Captcha     CaptchaConfig     `yaml:"captcha,omitempty" toml:"captcha,omitempty"`
+	Reconnect   ReconnectConfig   `yaml:"reconnect,omitempty" toml:"reconnect,omitempty"`
+	Watchdog    WatchdogConfig    `yaml:"watchdog,omitempty" toml:"watchdog,omitempty"`
+	Screencast  ScreencastConfig  `yaml:"screencast,omitempty" toml:"screencast,omitempty"`
+	Scenes      ScenesConfig      `yaml:"scenes,omitempty" toml:"scenes,omitempty"`
+	Notify      NotifyConfig      `yaml:"notify,omitempty" toml:"notify,omitempty"`
+	API         APIConfig         `yaml:"api,omitempty" toml:"api,omitempty"`
+	Events      EventsConfig      `yaml:"events,omitempty" toml:"events,omitempty"`
+	Metrics     MetricsConfig     `yaml:"metrics,omitempty" toml:"metrics,omitempty"`
+	Journal     JournalConfig     `yaml:"journal,omitempty" toml:"journal,omitempty"`
+	Trace       TraceConfig       `yaml:"trace,omitempty" toml:"trace,omitempty"`
+	Presence    PresenceConfig    `yaml:"presence,omitempty" toml:"presence,omitempty"`
+	Stats       StatsConfig       `yaml:"stats,omitempty" toml:"stats,omitempty"`
+	Diagnostics DiagnosticsConfig `yaml:"diagnostics,omitempty" toml:"diagnostics,omitempty"`
+	Update      UpdateConfig      `yaml:"update,omitempty" toml:"update,omitempty"`
+}
+
+// LogConfig holds logging settings.
+type LogConfig struct {
+	// Level is debug, info, warn or error.
+	Level     string `yaml:"level,omitempty" toml:"level,omitempty"`
+	AddSource bool   `yaml:"addSource,omitempty" toml:"addSource,omitempty"`
+}
+
+// StoreConfig selects where accounts, groups, templates and schedules are kept.
+type StoreConfig struct {
+	// Kind is mongo (default) or file.
+	Kind string `yaml:"kind,omitempty" toml:"kind,omitempty"`
+	// Dir holds the JSON files of the file store.
+	Dir string `yaml:"dir,omitempty" toml:"dir,omitempty"`
+}
+
+// MongoDBConfig holds the MongoDB connection settings.
+type MongoDBConfig struct {
+	URI      string `yaml:"uri,omitempty" toml:"uri,omitempty"`
+	Database string `yaml:"database,omitempty" toml:"database,omitempty"`
+}
+
+// OCRConfig holds the OCR backend settings.
+type OCRConfig struct {
+	URLs []string `yaml:"urls,omitempty" toml:"urls,omitempty"`
+	// Concurrency caps the requests in flight; 0 means no cap.
+	Concurrency *int `yaml:"concurrency,omitempty" toml:"concurrency,omitempty"`
+	// Tesseract is the local fallback command, or "off".
+	Tesseract string `yaml:"tesseract,omitempty" toml:"tesseract,omitempty"`
+}
+
+// BrowserConfig holds the browser engine and the launch flags of new
+// sessions. Per-account browser settings still take precedence.
+type BrowserConfig struct {
+	Engine             string `yaml:"engine,omitempty" toml:"engine,omitempty"`
+	Headless           *bool  `yaml:"headless,omitempty" toml:"headless,omitempty"`
+	DisableGPU         *bool  `yaml:"disableGpu,omitempty" toml:"disableGpu,omitempty"`
+	MuteAudio          *bool  `yaml:"muteAudio,omitempty" toml:"muteAudio,omitempty"`
+	HideScrollbars     *bool  `yaml:"hideScrollbars,omitempty" toml:"hideScrollbars,omitempty"`
+	DisableWebSecurity *bool  `yaml:"disableWebSecurity,omitempty" toml:"disableWebSecurity,omitempty"`
+	// BlockURLs are URL patterns, with * as a wildcard, whose requests
+	// are aborted, such as ads and analytics.
+	BlockURLs []string `yaml:"blockUrls,omitempty" toml:"blockUrls,omitempty"`
+}
+
+// LoginConfig holds the login page address and selectors of new sessions.
+// A profile calibrated from the UI takes precedence, and accounts may
+// override the URL template.
+type LoginConfig struct {
+	// URLTemplate is the login page, with {server} standing for the
+	// account's server ID.
+	URLTemplate string `yaml:"urlTemplate,omitempty" toml:"urlTemplate,omitempty"`
+	Username    string `yaml:"username,omitempty" toml:"username,omitempty"`
+	Password    string `yaml:"password,omitempty" toml:"password,omitempty"`
+	Submit      string `yaml:"submit,omitempty" toml:"submit,omitempty"`
+	Ready       string `yaml:"ready,omitempty" toml:"ready,omitempty"`
+	Frame       string `yaml:"frame,omitempty" toml:"frame,omitempty"`
+}
+
+// LoginRetryConfig holds how failed logins are retried. Durations are
+// strings such as "5s".
+type LoginRetryConfig struct {
+	// Attempts is how many times a login is tried in all.
+	Attempts *int   `yaml:"attempts,omitempty" toml:"attempts,omitempty"`
+	Delay    string `yaml:"delay,omitempty" toml:"delay,omitempty"`
+	MaxDelay string `yaml:"maxDelay,omitempty" toml:"maxDelay,omitempty"`
+}
+
+// CaptchaConfig holds how login captchas are answered.
+type CaptchaConfig struct {
+	// Scene is the scene that shows the captcha.
+	Scene string `yaml:"scene,omitempty" toml:"scene,omitempty"`
+	// SolverURL receives the captcha as a PNG; unset asks the user.
+	SolverURL string `yaml:"solverUrl,omitempty" toml:"solverUrl,omitempty"`
+}
+
+// ReconnectConfig holds how a crashed browser is restarted. Durations are
+// strings such as "5s".
+type ReconnectConfig struct {
+	// Attempts is how many restarts are tried in a row; 0 disables them.
+	Attempts *int   `yaml:"attempts,omitempty" toml:"attempts,omitempty"`
+	Delay    string `yaml:"delay,omitempty" toml:"delay,omitempty"`
+	MaxDelay string `yaml:"maxDelay,omitempty" toml:"maxDelay,omitempty"`
+}
+
+// WatchdogConfig holds the stuck script watchdog settings.
+type WatchdogConfig struct {
+	// Timeout is how long no scene may match, as a duration such as
+	// "10m"; "0" disables the watchdog.
+	Timeout string `yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+	// FreezeTimeout is how long the screen may stay unchanged; "0"
+	// disables freeze detection.
+	FreezeTimeout string `yaml:"freezeTimeout,omitempty" toml:"freezeTimeout,omitempty"`
+	// Action is refresh, script or stop.
+	Action string `yaml:"action,omitempty" toml:"action,omitempty"`
+	// Script is the recovery script run by the script action.
+	Script string `yaml:"script,omitempty" toml:"script,omitempty"`
+}
+
+// ScreencastConfig holds the Auto Refresh streaming settings.
+type ScreencastConfig struct {
+	// Quality is the JPEG quality of streamed frames (1-100).
+	Quality int `yaml:"quality,omitempty" toml:"quality,omitempty"`
+	MaxFPS  int `yaml:"maxFps,omitempty" toml:"maxFps,omitempty"`
+	// StartDelay is how long after a browser starts streaming begins,
+	// as a duration such as "1s".
+	StartDelay string `yaml:"startDelay,omitempty" toml:"startDelay,omitempty"`
+	// IdlePause pauses the stream of the watched session once its script
+	// has run this long without the canvas being touched, as a duration
+	// such as "10m"; unset keeps streaming.
+	IdlePause string `yaml:"idlePause,omitempty" toml:"idlePause,omitempty"`
+}
+
+// Delay returns StartDelay, or zero if it is unset or invalid.
+func (c ScreencastConfig) Delay() time.Duration {
+	d, err := time.ParseDuration(c.StartDelay)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// PauseAfter returns IdlePause, or zero if it is unset or invalid.
+func (c ScreencastConfig) PauseAfter() time.Duration {
+	d, err := time.ParseDuration(c.IdlePause)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// ScenesConfig holds scene matching settings.
+type ScenesConfig struct {
+	// Threshold is the maximum average color difference for scenes
+	// without their own threshold.
+	Threshold float64 `yaml:"threshold,omitempty" toml:"threshold,omitempty"`
+	// NearMissMargin is how far above its threshold a scene counts as a
+	// near miss; 0 stops recording them.
+	NearMissMargin *float64 `yaml:"nearMissMargin,omitempty" toml:"nearMissMargin,omitempty"`
+	// NearMissCount and NearMissSessions are the near misses, and the
+	// sessions they come from, needed before a threshold is suggested.
+	NearMissCount    int `yaml:"nearMissCount,omitempty" toml:"nearMissCount,omitempty"`
+	NearMissSessions int `yaml:"nearMissSessions,omitempty" toml:"nearMissSessions,omitempty"`
+	// AutoRelax is how far a suggested threshold may be raised on its own.
+	AutoRelax float64 `yaml:"autoRelax,omitempty" toml:"autoRelax,omitempty"`
+}
+
+// NotifyConfig holds where important notifications (failed logins,
+// scripts stopped by an error or exhausted resources, crashed sessions)
+// are sent besides the notification center.
+type NotifyConfig struct {
+	// Desktop shows them as desktop notifications; unset means true.
+	Desktop *bool `yaml:"desktop,omitempty" toml:"desktop,omitempty"`
+	// WebhookURL receives each notification as JSON in a POST.
+	WebhookURL string `yaml:"webhookUrl,omitempty" toml:"webhookUrl,omitempty"`
+	// DiscordURL is a Discord channel webhook.
+	DiscordURL string `yaml:"discordUrl,omitempty" toml:"discordUrl,omitempty"`
+	// TelegramToken and TelegramChatID send them as a Telegram bot.
+	TelegramToken  string `yaml:"telegramToken,omitempty" toml:"telegramToken,omitempty"`
+	TelegramChatID string `yaml:"telegramChatId,omitempty" toml:"telegramChatId,omitempty"`
+}
+
+// DesktopEnabled returns Desktop, true if it is unset.
+func (c NotifyConfig) DesktopEnabled() bool {
+	return c.Desktop == nil || *c.Desktop
+}
+
+// APIConfig holds the remote control API listener.
+type APIConfig struct {
+	// Addr is the listen address, such as localhost:8631; unset disables
+	// the API.
+	Addr string `yaml:"addr,omitempty" toml:"addr,omitempty"`
+	// Token is required when Addr is not a loopback address.
+	Token string `yaml:"token,omitempty" toml:"token,omitempty"`
+}
+
+// EventsConfig holds the WebSocket event stream listener.
+type EventsConfig struct {
+	Addr  string `yaml:"addr,omitempty" toml:"addr,omitempty"`
+	Token string `yaml:"token,omitempty" toml:"token,omitempty"`
+}
+
+// MetricsConfig holds the Prometheus metrics listener.
+type MetricsConfig struct {
+	Addr  string `yaml:"addr,omitempty" toml:"addr,omitempty"`
+	Token string `yaml:"token,omitempty" toml:"token,omitempty"`
+}
+
+// JournalConfig holds the event journal settings.
+type JournalConfig struct {
+	Disabled  bool   `yaml:"disabled,omitempty" toml:"disabled,omitempty"`
+	Dir       string `yaml:"dir,omitempty" toml:"dir,omitempty"`
+	MaxDays   int    `yaml:"maxDays,omitempty" toml:"maxDays,omitempty"`
+	MaxSizeMB int    `yaml:"maxSizeMb,omitempty" toml:"maxSizeMb,omitempty"`
+}
+
+// TraceConfig holds the script trace settings.
+type TraceConfig struct {
+	Disabled bool `yaml:"disabled,omitempty" toml:"disabled,omitempty"`
+	// Store is file (default) or mongo.
+	Store   string `yaml:"store,omitempty" toml:"store,omitempty"`
+	Dir     string `yaml:"dir,omitempty" toml:"dir,omitempty"`
+	MaxRuns int    `yaml:"maxRuns,omitempty" toml:"maxRuns,omitempty"`
+}
+
+// PresenceConfig holds the session presence record settings. Durations
+// are strings such as "30s".
+type PresenceConfig struct {
+	Disabled bool   `yaml:"disabled,omitempty" toml:"disabled,omitempty"`
+	Machine  string `yaml:"machine,omitempty" toml:"machine,omitempty"`
+	Interval string `yaml:"interval,omitempty" toml:"interval,omitempty"`
+	// TTL must be longer than Interval.
+	TTL string `yaml:"ttl,omitempty" toml:"ttl,omitempty"`
+}
+
+// StatsConfig holds the script run statistics settings.
+type StatsConfig struct {
+	Disabled bool `yaml:"disabled,omitempty" toml:"disabled,omitempty"`
+}
+
+// DiagnosticsConfig holds the profiling settings. Durations are strings
+// such as "5m".
+type DiagnosticsConfig struct {
+	PprofAddr       string `yaml:"pprofAddr,omitempty" toml:"pprofAddr,omitempty"`
+	PprofToken      string `yaml:"pprofToken,omitempty" toml:"pprofToken,omitempty"`
+	ProfileInterval string `yaml:"profileInterval,omitempty" toml:"profileInterval,omitempty"`
+	CPUSample       string `yaml:"cpuSample,omitempty" toml:"cpuSample,omitempty"`
+}
+
+// UpdateConfig holds the release feed checked for new builds.
+type UpdateConfig struct {
+	FeedURL string `yaml:"feedUrl,omitempty" toml:"feedUrl,omitempty"`
+	// PublicKey is the base64 or hex Ed25519 key releases are signed with.
+	PublicKey string `yaml:"publicKey,omitempty" toml:"publicKey,omitempty"`
+}
+
+// DurationOr returns raw as a duration, or def if it is unset or invalid.
+func DurationOr(raw string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return def
+	}
+	return d
+}
+
+// ValueOr returns *v, or def if v is nil.
+func ValueOr[T any](v *T, def T) T {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+// DefaultPath returns where the config file is looked for by default.
+func DefaultPath() string {
+	return appdir.Path("config.yaml")
+}
+
+// Path returns the config file named by WARDENLY_CONFIG, or DefaultPath.
+func Path() string {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path
+	}
+	return DefaultPath()
+}
+
+// FromEnv loads the file at Path and applies the environment overrides.
+// A missing default file is not an error. Invalid settings are left at
+// their defaults and returned as a *Report.
+func FromEnv() (*Config, error) {
+	path := Path()
+	cfg, err := Load(path)
+
+	var found issues
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if os.Getenv(EnvPath) != "" {
+			found.add(EnvPath, path, "file not found", "Create the file, or unset "+EnvPath+" to use "+DefaultPath()+".")
+		}
+	case err != nil:
+		// Unknown keys come as a report, syntax errors as they are
+		if !errors.As(err, new(*Report)) {
+			err = &Report{Issues: []Issue{{
+				Reason:     err.Error(),
+				Suggestion: "Fix the syntax; until then the file is ignored and all settings use their defaults.",
+			}}}
+		}
+		found.merge(err)
+	}
+	found.merge(cfg.ApplyEnv())
+	found.merge(cfg.validate())
+	return cfg, found.err(path)
+}
+
+// Load reads a config file; files ending in .toml are parsed as TOML and
+// all others as YAML. Unknown keys are rejected, so typos don't go
+// unnoticed, and returned as a *Report. On error an empty config is
+// returned.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return &Config{}, err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return &Config{}, tomlKeyIssues(undecoded).err(path)
+		}
+		return cfg, nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		if found := yamlKeyIssues(err); len(found) > 0 {
+			return &Config{}, found.err(path)
+		}
+		return &Config{}, err
+	}
+	return cfg, nil
+}
+
+// Save writes cfg to path in the format Load reads it with, creating the
+// directory if needed. The file is replaced atomically; comments of a
+// hand-edited file are not kept.
+func Save(path string, cfg *Config) error {
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+			return fmt.Errorf("failed to encode config: %w", err)
+		}
+		data = buf.Bytes()
+	} else {
+		var err error
+		if data, err = yaml.Marshal(cfg); err != nil {
+			return fmt.Errorf("failed to encode config: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace config: %w", err)
+	}
+	return nil
+}
+
+// EnvOverrides lists the set environment variables that take precedence
+// over the file, so an editor can point out settings it can't change.
+func EnvOverrides() []string {
+	var set []string
+	for _, name := range []string{
+		EnvLogLevel, EnvMongoURI, EnvMongoDatabase, ocr.EnvURLs, ocr.EnvConcurrency, ocr.EnvTesseract,
+		browser.EnvEngine, EnvHeadless, EnvScreencastQuality, EnvScreencastFPS,
+		repository.EnvStore, repository.EnvStoreDir,
+		EnvLoginAttempts, EnvLoginRetryDelay, EnvLoginRetryMaxDelay, EnvCaptchaScene, captcha.EnvSolverURL,
+		EnvReconnectAttempts, EnvReconnectDelay, EnvReconnectMaxDelay,
+		EnvWatchdogTimeout, EnvWatchdogFreeze, EnvWatchdogAction, EnvWatchdogScript,
+		EnvSceneNearMissMargin, EnvSceneNearMissCount, EnvSceneNearMissSessions, EnvSceneAutoRelax,
+		api.EnvAddr, api.EnvToken, eventstream.EnvAddr, eventstream.EnvToken, metrics.EnvAddr, metrics.EnvToken,
+		journal.EnvDisabled, journal.EnvDir, journal.EnvMaxDays, journal.EnvMaxSizeMB,
+		trace.EnvDisabled, trace.EnvStore, trace.EnvDir, trace.EnvMaxRuns,
+		presence.EnvDisabled, presence.EnvMachine, presence.EnvInterval, presence.EnvTTL, stats.EnvDisabled,
+		diagnostics.EnvPprofAddr, diagnostics.EnvPprofToken, diagnostics.EnvProfileInterval, diagnostics.EnvCPUSample,
+		update.EnvFeedURL, update.EnvPublicKey,
+	} {
+		if os.Getenv(name) != "" {
+			set = append(set, name)
+		}
+	}
+	return set
+}
+
+// ApplyEnv overrides c with the environment variables read by FromEnv.
+// Values that don't parse are left unchanged and returned as a *Report;
+// the others are checked with the file settings.
+func (c *Config) ApplyEnv() error {
+	var found issues
+	for _, v := range []struct {
+		name string
+		dst  *string
+	}{
+		{EnvLogLevel, &c.Log.Level},
+		{repository.EnvStore, &c.Store.Kind},
+		{repository.EnvStoreDir, &c.Store.Dir},
+		{EnvMongoURI, &c.MongoDB.URI},
+		{EnvMongoDatabase, &c.MongoDB.Database},
+		{browser.EnvEngine, &c.Browser.Engine},
+		{EnvLoginRetryDelay, &c.LoginRetry.Delay},
+		{EnvLoginRetryMaxDelay, &c.LoginRetry.MaxDelay},
+		{EnvCaptchaScene, &c.Captcha.Scene},
+		{captcha.EnvSolverURL, &c.Captcha.SolverURL},
+		{EnvReconnectDelay, &c.Reconnect.Delay},
+		{EnvReconnectMaxDelay, &c.Reconnect.MaxDelay},
+		{EnvWatchdogTimeout, &c.Watchdog.Timeout},
+		{EnvWatchdogFreeze, &c.Watchdog.FreezeTimeout},
+		{EnvWatchdogAction, &c.Watchdog.Action},
+		{EnvWatchdogScript, &c.Watchdog.Script},
+		{api.EnvAddr, &c.API.Addr},
+		{api.EnvToken, &c.API.Token},
+		{eventstream.EnvAddr, &c.Events.Addr},
+		{eventstream.EnvToken, &c.Events.Token},
+		{metrics.EnvAddr, &c.Metrics.Addr},
+		{metrics.EnvToken, &c.Metrics.Token},
+		{journal.EnvDir, &c.Journal.Dir},
+		{trace.EnvStore, &c.Trace.Store},
+		{trace.EnvDir, &c.Trace.Dir},
+		{presence.EnvMachine, &c.Presence.Machine},
+		{presence.EnvInterval, &c.Presence.Interval},
+		{presence.EnvTTL, &c.Presence.TTL},
+		{diagnostics.EnvPprofAddr, &c.Diagnostics.PprofAddr},
+		{diagnostics.EnvPprofToken, &c.Diagnostics.PprofToken},
+		{diagnostics.EnvProfileInterval, &c.Diagnostics.ProfileInterval},
+		{diagnostics.EnvCPUSample, &c.Diagnostics.CPUSample},
+		{update.EnvFeedURL, &c.Update.FeedURL},
+		{update.EnvPublicKey, &c.Update.PublicKey},
+	} {
+		if raw := os.Getenv(v.name); raw != "" {
+			*v.dst = raw
+		}
+	}
+
+	if v := os.Getenv(EnvHeadless); v != "" {
+		if headless, err := strconv.ParseBool(v); err != nil {
+			found.add(EnvHeadless, v, "must be true or false", "Use true to hide browser windows, false to show them.")
+		} else {
+			c.Browser.Headless = &headless
+		}
+	}
+	for _, v := range []struct {
+		name string
+		dst  *bool
+	}{
+		{journal.EnvDisabled, &c.Journal.Disabled},
+		{trace.EnvDisabled, &c.Trace.Disabled},
+		{presence.EnvDisabled, &c.Presence.Disabled},
+		{stats.EnvDisabled, &c.Stats.Disabled},
+	} {
+		if raw := os.Getenv(v.name); raw != "" {
+			if disabled, err := strconv.ParseBool(raw); err != nil {
+				found.add(v.name, raw, "must be true or false", "Use true to turn it off.")
+			} else {
+				*v.dst = disabled
+			}
+		}
+	}
+
+	for _, v := range []struct {
+		name       string
+		dst        *int
+		suggestion string
+	}{
+		{EnvScreencastQuality, &c.Screencast.Quality, "Use a JPEG quality from 1 to 100."},
+		{EnvScreencastFPS, &c.Screencast.MaxFPS, "Use a frame rate such as 5."},
+		{EnvSceneNearMissCount, &c.Scenes.NearMissCount, "Use the number of near misses to wait for, such as 20."},
+		{EnvSceneNearMissSessions, &c.Scenes.NearMissSessions, "Use the number of sessions they must come from, such as 2."},
+		{journal.EnvMaxDays, &c.Journal.MaxDays, "Use the number of daily files to keep, such as 14."},
+		{journal.EnvMaxSizeMB, &c.Journal.MaxSizeMB, "Use the journal size limit in megabytes, such as 200."},
+		{trace.EnvMaxRuns, &c.Trace.MaxRuns, "Use the number of runs to keep, such as 200."},
+	} {
+		if raw := os.Getenv(v.name); raw != "" {
+			if n, err := strconv.Atoi(raw); err != nil {
+				found.add(v.name, raw, "must be an integer", v.suggestion)
+			} else {
+				*v.dst = n
+			}
+		}
+	}
+	for _, v := range []struct {
+		name       string
+		dst        **int
+		suggestion string
+	}{
+		{EnvLoginAttempts, &c.LoginRetry.Attempts, "Use the number of login tries, such as 3."},
+		{EnvReconnectAttempts, &c.Reconnect.Attempts, "Use the number of restarts to try, such as 5, or 0 to turn reconnecting off."},
+	} {
+		if raw := os.Getenv(v.name); raw != "" {
+			if n, err := strconv.Atoi(raw); err != nil {
+				found.add(v.name, raw, "must be an integer", v.suggestion)
+			} else {
+				*v.dst = &n
+			}
+		}
+	}
+
+	if raw := os.Getenv(EnvSceneNearMissMargin); raw != "" {
+		if f, err := strconv.ParseFloat(raw, 64); err != nil {
+			found.add(EnvSceneNearMissMargin, raw, "must be a number", "Use a color difference such as 1.0, or 0 to stop recording near misses.")
+		} else {
+			c.Scenes.NearMissMargin = &f
+		}
+	}
+	if raw := os.Getenv(EnvSceneAutoRelax); raw != "" {
+		if f, err := strconv.ParseFloat(raw, 64); err != nil {
+			found.add(EnvSceneAutoRelax, raw, "must be a number", "Use how far thresholds may be raised, such as 2, or 0 to only suggest.")
+		} else {
+			c.Scenes.AutoRelax = f
+		}
+	}
+	return found.err("")
+}
+
+// Logging returns the logging configuration.
+func (c *Config) Logging() *logging.Config {
+	cfg := logging.DefaultConfig()
+	if c.Log.Level != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(c.Log.Level)); err == nil {
+			cfg.Level = level
+		}
+	}
+	cfg.AddSource = c.Log.AddSource
+	return cfg
+}
+
+// Forwarder returns the webhook targets of important notifications.
+func (c *Config) Forwarder() *notify.ForwarderConfig {
+	return &notify.ForwarderConfig{
+		WebhookURL:     c.Notify.WebhookURL,
+		DiscordURL:     c.Notify.DiscordURL,
+		TelegramToken:  c.Notify.TelegramToken,
+		TelegramChatID: c.Notify.TelegramChatID,
+	}
+}
+
+// Mongo returns the MongoDB connection configuration.
+func (c *Config) Mongo() *repository.MongoDBConfig {
+	cfg := repository.DefaultMongoDBConfig()
+	if c.MongoDB.URI != "" {
+		cfg.URI = c.MongoDB.URI
+	}
+	if c.MongoDB.Database != "" {
+		cfg.Database = c.MongoDB.Database
+	}
+	return cfg
+}
+
+// OCRPool returns the OCR pool configuration, with the WARDENLY_OCR_*
+// variables applied over the file settings.
+func (c *Config) OCRPool() (*ocr.PoolConfig, error) {
+	cfg := ocr.DefaultPoolConfig()
+	var urls []string
+	for _, u := range c.OCR.URLs {
+		if u = strings.TrimRight(strings.TrimSpace(u), "/"); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) > 0 {
+		cfg.BaseURLs = urls
+	}
+	if c.OCR.Concurrency != nil {
+		cfg.MaxConcurrent = *c.OCR.Concurrency
+	}
+	if strings.EqualFold(c.OCR.Tesseract, "off") {
+		cfg.Tesseract = ""
+	} else if c.OCR.Tesseract != "" {
+		cfg.Tesseract = c.OCR.Tesseract
+	}
+	return cfg, cfg.ApplyEnv()
+}
+
+// Driver returns the base browser configuration of new sessions.
+func (c *Config) Driver() *browser.DriverConfig {
+	cfg := browser.DefaultDriverConfig()
+	flags := []struct {
+		value *bool
+		field *bool
+	}{
+		{c.Browser.Headless, &cfg.Headless},
+		{c.Browser.DisableGPU, &cfg.DisableGPU},
+		{c.Browser.MuteAudio, &cfg.MuteAudio},
+		{c.Browser.HideScrollbars, &cfg.HideScrollbars},
+		{c.Browser.DisableWebSecurity, &cfg.DisableWebSecurity},
+	}
+	for _, f := range flags {
+		if f.value != nil {
+			*f.field = *f.value
+		}
+	}
+	cfg.BlockedURLs = c.Browser.BlockURLs
+	return cfg
+}
+
+// LoginProfile returns the configured login profile; unset fields are left
+// for browser.LoadLoginProfile to default.
+func (c *Config) LoginProfile() browser.LoginProfile {
+	return browser.LoginProfile{
+		URLTemplate: c.Login.URLTemplate,
+		Username:    c.Login.Username,
+		Password:    c.Login.Password,
+		Submit:      c.Login.Submit,
+		Ready:       c.Login.Ready,
+		Frame:       c.Login.Frame,
+	}
+}
+
+// DataStore returns where accounts and groups are kept.
+func (c *Config) DataStore() *repository.StoreConfig {
+	cfg := &repository.StoreConfig{Kind: repository.StoreMongo, Dir: c.Store.Dir}
+	if c.Store.Kind != "" {
+		cfg.Kind = c.Store.Kind
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = repository.DefaultFileDir()
+	}
+	return cfg
+}
+
+// CaptchaSolver returns the captcha solver client, or nil if no solver
+// URL is configured.
+func (c *Config) CaptchaSolver() *captcha.Client {
+	if url := strings.TrimSpace(c.Captcha.SolverURL); url != "" {
+		return captcha.NewClient(url, 0)
+	}
+	return nil
+}
+
+// RemoteAPI returns the remote control API configuration.
+func (c *Config) RemoteAPI() *api.Config {
+	return &api.Config{Addr: c.API.Addr, Token: c.API.Token}
+}
+
+// EventStream returns the WebSocket event stream configuration.
+func (c *Config) EventStream() *eventstream.Config {
+	return &eventstream.Config{Addr: c.Events.Addr, Token: c.Events.Token}
+}
+
+// MetricsEndpoint returns the Prometheus metrics configuration.
+func (c *Config) MetricsEndpoint() *metrics.Config {
+	return &metrics.Config{Addr: c.Metrics.Addr, Token: c.Metrics.Token}
+}
+
+// EventJournal returns the event journal configuration.
+func (c *Config) EventJournal() *journal.Config {
+	return &journal.Config{
+		Disabled:  c.Journal.Disabled,
+		Dir:       c.Journal.Dir,
+		MaxDays:   c.Journal.MaxDays,
+		MaxSizeMB: c.Journal.MaxSizeMB,
+	}
+}
+
+// Tracing returns the script trace configuration.
+func (c *Config) Tracing() *trace.Config {
+	cfg := &trace.Config{
+		Disabled: c.Trace.Disabled,
+		Store:    trace.StoreFile,
+		Dir:      c.Trace.Dir,
+		MaxRuns:  c.Trace.MaxRuns,
+	}
+	if c.Trace.Store != "" {
+		cfg.Store = c.Trace.Store
+	}
+	return cfg
+}
+
+// PresenceRecords returns the session presence configuration. A TTL not
+// longer than the interval is raised to four intervals.
+func (c *Config) PresenceRecords() *presence.Config {
+	cfg := &presence.Config{
+		Disabled: c.Presence.Disabled,
+		Machine:  c.Presence.Machine,
+		Interval: DurationOr(c.Presence.Interval, presence.DefaultInterval),
+		TTL:      DurationOr(c.Presence.TTL, presence.DefaultTTL),
+	}
+	if cfg.TTL <= cfg.Interval {
+		cfg.TTL = 4 * cfg.Interval
+	}
+	return cfg
+}
+
+// Statistics returns the script run statistics configuration.
+func (c *Config) Statistics() *stats.Config {
+	return &stats.Config{Disabled: c.Stats.Disabled}
+}
+
+// Profiling returns the diagnostics configuration.
+func (c *Config) Profiling() *diagnostics.Config {
+	return &diagnostics.Config{
+		PprofAddr:  c.Diagnostics.PprofAddr,
+		PprofToken: c.Diagnostics.PprofToken,
+		Interval:   DurationOr(c.Diagnostics.ProfileInterval, 0),
+		CPUSample:  DurationOr(c.Diagnostics.CPUSample, 0),
+	}
+}
+
+// Updates returns the updater configuration; the version and executable
+// are left for the caller to set.
+func (c *Config) Updates() *update.Config {
+	cfg := &update.Config{FeedURL: c.Update.FeedURL}
+	if c.Update.PublicKey != "" {
+		cfg.PublicKey, _ = update.ParsePublicKey(c.Update.PublicKey)
+	}
+	return cfg
+}
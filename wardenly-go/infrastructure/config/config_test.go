@@ -0,0 +1,406 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"wardenly-go/infrastructure/diagnostics"
+	"wardenly-go/infrastructure/eventstream"
+	"wardenly-go/infrastructure/journal"
+	"wardenly-go/infrastructure/ocr"
+	"wardenly-go/infrastructure/presence"
+	"wardenly-go/infrastructure/repository"
+	"wardenly-go/infrastructure/trace"
+)
+
+const yamlConfig = `
+log:
+  level: debug
+mongodb:
+  uri: mongodb://db:27017
+ocr:
+  urls: ["http://ocr-a:8000/", "http://ocr-b:8000"]
+  concurrency: 0
+  tesseract: "off"
+browser:
+  headless: false
+  disableGpu: true
+  blockUrls: ["*://ads.example/*"]
+login:
+  urlTemplate: https://mirror.example/wly/{server}
+  submit: "#login"
+screencast:
+  quality: 60
+  maxFps: 10
+notify:
+  desktop: false
+  webhookUrl: https://hooks.example.com/wardenly
+`
+
+const tomlConfig = `
+[log]
+level = "debug"
+
+[mongodb]
+uri = "mongodb://db:27017"
+
+[ocr]
+urls = ["http://ocr-a:8000/", "http://ocr-b:8000"]
+concurrency = 0
+tesseract = "off"
+
+[browser]
+headless = false
+disableGpu = true
+blockUrls = ["*://ads.example/*"]
+
+[login]
+urlTemplate = "https://mirror.example/wly/{server}"
+submit = "#login"
+
+[screencast]
+quality = 60
+maxFps = 10
+
+[notify]
+desktop = false
+webhookUrl = "https://hooks.example.com/wardenly"
+`
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFromEnv_File(t *testing.T) {
+	files := map[string]string{"config.yaml": yamlConfig, "config.toml": tomlConfig}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(EnvPath, writeConfig(t, name, content))
+			t.Setenv(ocr.EnvURLs, "")
+			t.Setenv(ocr.EnvConcurrency, "")
+			t.Setenv(ocr.EnvTesseract, "")
+
+			cfg, err := FromEnv()
+			if err != nil {
+				t.Fatalf("FromEnv() error = %v", err)
+			}
+			if level := cfg.Logging().Level; level != slog.LevelDebug {
+				t.Errorf("log level = %v, want debug", level)
+			}
+			if mongo := cfg.Mongo(); mongo.URI != "mongodb://db:27017" || mongo.Database != "wardenly" {
+				t.Errorf("mongo config = %+v", mongo)
+			}
+			pool, err := cfg.OCRPool()
+			if err != nil || len(pool.BaseURLs) != 2 || pool.BaseURLs[0] != "http://ocr-a:8000" || pool.MaxConcurrent != 0 || pool.Tesseract != "" {
+				t.Errorf("OCR pool = %+v, %v", pool, err)
+			}
+			driver := cfg.Driver()
+			if driver.Headless || !driver.DisableGPU || !driver.MuteAudio || len(driver.BlockedURLs) != 1 {
+				t.Errorf("driver config = %+v", driver)
+			}
+			if login := cfg.LoginProfile(); login.URLTemplate != "https://mirror.example/wly/{server}" || login.Submit != "#login" || login.Username != "" {
+				t.Errorf("login profile = %+v", login)
+			}
+			if cfg.Screencast.Quality != 60 || cfg.Screencast.MaxFPS != 10 {
+				t.Errorf("screencast = %+v", cfg.Screencast)
+			}
+			if forwarder := cfg.Forwarder(); cfg.Notify.DesktopEnabled() || !forwarder.Enabled() || forwarder.WebhookURL != "https://hooks.example.com/wardenly" {
+				t.Errorf("notify = %+v", cfg.Notify)
+			}
+		})
+	}
+}
+
+func TestFromEnv_Overrides(t *testing.T) {
+	t.Setenv(EnvPath, writeConfig(t, "config.yaml", yamlConfig))
+	t.Setenv(EnvLogLevel, "warn")
+	t.Setenv(EnvMongoURI, "mongodb://other:27017")
+	t.Setenv(EnvHeadless, "true")
+	t.Setenv(EnvScreencastFPS, "2")
+	t.Setenv(ocr.EnvURLs, "http://ocr-env:8000")
+
+	cfg, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv() error = %v", err)
+	}
+	if level := cfg.Logging().Level; level != slog.LevelWarn {
+		t.Errorf("log level = %v, want warn", level)
+	}
+	if uri := cfg.Mongo().URI; uri != "mongodb://other:27017" {
+		t.Errorf("mongo URI = %s", uri)
+	}
+	if !cfg.Driver().Headless || cfg.Screencast.MaxFPS != 2 || cfg.Screencast.Quality != 60 {
+		t.Errorf("config = %+v", cfg)
+	}
+	if pool, _ := cfg.OCRPool(); len(pool.BaseURLs) != 1 || pool.BaseURLs[0] != "http://ocr-env:8000" {
+		t.Errorf("OCR URLs = %v, want the environment", pool.BaseURLs)
+	}
+}
+
+const servicesConfig = `
+store:
+  kind: file
+  dir: /data/wardenly
+loginRetry:
+  attempts: 1
+captcha:
+  solverUrl: http://solver:9000/solve
+reconnect:
+  attempts: 0
+  delay: 10s
+watchdog:
+  timeout: 5m
+  action: script
+  script: recover
+scenes:
+  nearMissMargin: 0
+  autoRelax: 2
+api:
+  addr: localhost:8631
+journal:
+  maxDays: 7
+trace:
+  store: mongo
+  maxRuns: 50
+presence:
+  interval: 1m
+stats:
+  disabled: true
+diagnostics:
+  profileInterval: 5m
+`
+
+func TestFromEnv_Sections(t *testing.T) {
+	t.Setenv(EnvPath, writeConfig(t, "config.yaml", servicesConfig))
+	t.Setenv(EnvWatchdogFreeze, "0")
+	t.Setenv(EnvReconnectMaxDelay, "30s")
+	t.Setenv(EnvLoginAttempts, "")
+	t.Setenv(journal.EnvDisabled, "true")
+	t.Setenv(eventstream.EnvAddr, "localhost:8632")
+	t.Setenv(presence.EnvTTL, "")
+
+	cfg, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv() error = %v", err)
+	}
+	if store := cfg.DataStore(); store.Kind != repository.StoreFile || store.Dir != "/data/wardenly" {
+		t.Errorf("store = %+v", store)
+	}
+	if ValueOr(cfg.LoginRetry.Attempts, 3) != 1 || cfg.CaptchaSolver() == nil {
+		t.Errorf("login retry = %+v, captcha = %+v", cfg.LoginRetry, cfg.Captcha)
+	}
+	if ValueOr(cfg.Reconnect.Attempts, 5) != 0 || DurationOr(cfg.Reconnect.Delay, 0) != 10*time.Second ||
+		DurationOr(cfg.Reconnect.MaxDelay, 0) != 30*time.Second {
+		t.Errorf("reconnect = %+v", cfg.Reconnect)
+	}
+	if DurationOr(cfg.Watchdog.Timeout, 0) != 5*time.Minute || DurationOr(cfg.Watchdog.FreezeTimeout, time.Hour) != 0 ||
+		cfg.Watchdog.Action != "script" {
+		t.Errorf("watchdog = %+v", cfg.Watchdog)
+	}
+	if ValueOr(cfg.Scenes.NearMissMargin, 1) != 0 || cfg.Scenes.AutoRelax != 2 {
+		t.Errorf("scenes = %+v", cfg.Scenes)
+	}
+	if !cfg.RemoteAPI().Enabled() || !cfg.EventStream().Enabled() || cfg.MetricsEndpoint().Enabled() {
+		t.Errorf("listeners = %+v, %+v, %+v", cfg.API, cfg.Events, cfg.Metrics)
+	}
+	if j := cfg.EventJournal(); j.Enabled() || j.MaxDays != 7 {
+		t.Errorf("journal = %+v", j)
+	}
+	if tr := cfg.Tracing(); tr.Store != trace.StoreMongo || tr.MaxRuns != 50 {
+		t.Errorf("trace = %+v", tr)
+	}
+	// The default TTL is raised above a longer interval
+	if p := cfg.PresenceRecords(); p.Interval != time.Minute || p.TTL != 2*time.Minute {
+		t.Errorf("presence = %+v", p)
+	}
+	if cfg.Statistics().Enabled() || cfg.Profiling().Interval != 5*time.Minute {
+		t.Errorf("stats = %+v, diagnostics = %+v", cfg.Stats, cfg.Diagnostics)
+	}
+	if u := cfg.Updates(); u.FeedURL != "" || u.PublicKey != nil {
+		t.Errorf("update = %+v", u)
+	}
+}
+
+func TestFromEnv_Invalid(t *testing.T) {
+	// A missing default file is fine, a missing named one is not
+	t.Setenv(EnvPath, "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if _, err := FromEnv(); err != nil {
+		t.Errorf("FromEnv() without a file error = %v", err)
+	}
+	t.Setenv(EnvPath, filepath.Join(t.TempDir(), "missing.yaml"))
+	if _, err := FromEnv(); err == nil {
+		t.Error("FromEnv() should report a missing WARDENLY_CONFIG file")
+	}
+
+	t.Setenv(EnvPath, writeConfig(t, "config.yaml", "log:\n  levle: debug\n"))
+	if _, err := FromEnv(); err == nil {
+		t.Error("FromEnv() should reject unknown keys")
+	}
+
+	t.Setenv(EnvPath, writeConfig(t, "config.yaml", "log:\n  level: loud\nscreencast:\n  quality: 150\n"))
+	t.Setenv(EnvScreencastFPS, "fast")
+	cfg, err := FromEnv()
+	if err == nil {
+		t.Fatal("FromEnv() should report invalid values")
+	}
+	if cfg.Logging().Level != slog.LevelInfo || cfg.Screencast.Quality != 0 || cfg.Screencast.MaxFPS != 0 {
+		t.Errorf("invalid values should keep the defaults, got %+v", cfg)
+	}
+}
+
+func TestFromEnv_Report(t *testing.T) {
+	t.Setenv(EnvScreencastFPS, "")
+	t.Setenv(EnvPath, writeConfig(t, "config.yaml", `
+mongodb:
+  uri: localhost:27017
+ocr:
+  urls: [http://ocr:8000, ocr-b]
+browser:
+  engine: firefox
+login:
+  urlTemplate: www.lequ.com/server/wly/s/{server}
+screencast:
+  maxFps: -1
+notify:
+  discordUrl: discord.com/api/webhooks/1
+  telegramToken: "123:abc"
+`))
+	cfg, err := FromEnv()
+	var report *Report
+	if !errors.As(err, &report) {
+		t.Fatalf("FromEnv() error = %v, want a *Report", err)
+	}
+	fields := map[string]Issue{}
+	for _, issue := range report.Issues {
+		fields[issue.Field] = issue
+	}
+	for _, field := range []string{"mongodb.uri", "ocr.urls", "browser.engine", "login.urlTemplate", "screencast.maxFps", "notify.discordUrl"} {
+		if issue, ok := fields[field]; !ok || issue.Value == "" || issue.Reason == "" || issue.Suggestion == "" {
+			t.Errorf("issue for %s = %+v", field, issue)
+		}
+	}
+	if fields["ocr.urls"].Value != "ocr-b" || len(cfg.OCR.URLs) != 1 {
+		t.Errorf("only the invalid OCR URL should be dropped: %v", cfg.OCR.URLs)
+	}
+	if _, ok := fields["notify.telegramToken"]; !ok || cfg.Forwarder().Enabled() {
+		t.Errorf("a Telegram token without a chat should be reported and dropped: %+v", cfg.Notify)
+	}
+	if details := report.Details(); !strings.Contains(details, `screencast.maxFps = "-1"`) || !strings.Contains(details, "7 problem(s)") {
+		t.Errorf("Details() = %s", details)
+	}
+}
+
+func TestFromEnv_SectionReport(t *testing.T) {
+	t.Setenv(EnvPath, writeConfig(t, "config.yaml", `
+store:
+  kind: sqlite
+reconnect:
+  attempts: -1
+  delay: soon
+watchdog:
+  action: script
+api:
+  addr: 8631
+trace:
+  store: s3
+presence:
+  interval: 1m
+  ttl: 30s
+update:
+  publicKey: not-a-key
+`))
+	t.Setenv(EnvReconnectAttempts, "")
+	t.Setenv(presence.EnvInterval, "")
+	t.Setenv(diagnostics.EnvCPUSample, "often")
+	t.Setenv(journal.EnvMaxDays, "two weeks")
+
+	cfg, err := FromEnv()
+	var report *Report
+	if !errors.As(err, &report) {
+		t.Fatalf("FromEnv() error = %v, want a *Report", err)
+	}
+	fields := map[string]Issue{}
+	for _, issue := range report.Issues {
+		fields[issue.Field] = issue
+	}
+	for _, field := range []string{
+		"store.kind", "reconnect.attempts", "reconnect.delay", "watchdog.action", "api.addr", "trace.store",
+		"presence.ttl", "update.publicKey", "diagnostics.cpuSample", journal.EnvMaxDays,
+	} {
+		if issue, ok := fields[field]; !ok || issue.Reason == "" || issue.Suggestion == "" {
+			t.Errorf("issue for %s = %+v", field, issue)
+		}
+	}
+	if cfg.DataStore().Kind != repository.StoreMongo || cfg.Reconnect.Attempts != nil || cfg.Watchdog.Action != "" ||
+		cfg.RemoteAPI().Enabled() || cfg.Tracing().Store != trace.StoreFile || cfg.Updates().PublicKey != nil {
+		t.Errorf("invalid values should keep the defaults, got %+v", cfg)
+	}
+	if p := cfg.PresenceRecords(); p.TTL != 4*time.Minute {
+		t.Errorf("presence TTL = %v, want four intervals", p.TTL)
+	}
+}
+
+func TestLoad_UnknownKeys(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": "log:\n  levle: debug\nscreencats:\n  quality: 5\n",
+		"config.toml": "[log]\nlevle = \"debug\"\n\n[screencats]\nquality = 5\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, name, content))
+			var report *Report
+			if !errors.As(err, &report) || len(report.Issues) != 2 {
+				t.Fatalf("Load() error = %v, want two unknown keys", err)
+			}
+			fields := map[string]Issue{}
+			for _, issue := range report.Issues {
+				fields[issue.Field] = issue
+			}
+			if issue := fields["log.levle"]; !strings.Contains(issue.Suggestion, "level, addSource") {
+				t.Errorf("log.levle issue = %+v", issue)
+			}
+			if issue := fields["screencats"]; !strings.Contains(issue.Suggestion, "screencast") {
+				t.Errorf("screencats issue = %+v", issue)
+			}
+		})
+	}
+}
+
+func TestSave_RoundTrip(t *testing.T) {
+	headless, none := false, 0
+	cfg := &Config{
+		OCR:        OCRConfig{URLs: []string{"http://ocr:8000"}, Concurrency: &none},
+		Browser:    BrowserConfig{Headless: &headless},
+		Screencast: ScreencastConfig{Quality: 70, StartDelay: "2s", IdlePause: "10m"},
+		Scenes:     ScenesConfig{Threshold: 7.5},
+	}
+	for _, name := range []string{"config.yaml", "config.toml"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nested", name)
+			if err := Save(path, cfg); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			got, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(got.OCR.URLs) != 1 || got.OCR.Concurrency == nil || *got.OCR.Concurrency != 0 ||
+				got.Browser.Headless == nil || *got.Browser.Headless || got.Browser.DisableGPU != nil ||
+				got.Screencast.Delay() != 2*time.Second || got.Screencast.PauseAfter() != 10*time.Minute || got.Scenes.Threshold != 7.5 || got.MongoDB.URI != "" {
+				t.Errorf("Load() = %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,477 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"wardenly-go/infrastructure/browser"
+	"wardenly-go/infrastructure/presence"
+	"wardenly-go/infrastructure/repository"
+	"wardenly-go/infrastructure/trace"
+	"wardenly-go/infrastructure/update"
+)
+
+// Issue describes a setting that could not be used. The component keeps
+// its default for it.
+type Issue struct {
+	// Field is the key in the file, such as screencast.maxFps, or the
+	// environment variable; empty for problems with the file as a whole
+	Field string
+	// Value is the value that was rejected, if any
+	Value string
+	// Reason says what is wrong with it
+	Reason string
+	// Suggestion says how to fix it
+	Suggestion string
+}
+
+func (i Issue) Error() string {
+	msg := i.Reason
+	if i.Value != "" {
+		msg = fmt.Sprintf("%s, got %q", msg, i.Value)
+	}
+	if i.Field != "" {
+		msg = i.Field + ": " + msg
+	}
+	return msg
+}
+
+// Report lists the problems found while loading the configuration. It is
+// returned as the error of FromEnv and Load.
+type Report struct {
+	// Path is the config file, empty if only the environment was checked
+	Path   string
+	Issues []Issue
+}
+
+func (r *Report) Error() string {
+	msgs := make([]string, len(r.Issues))
+	for i, issue := range r.Issues {
+		msgs[i] = issue.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Details formats the report for a terminal, one issue per paragraph with
+// its value and suggestion.
+func (r *Report) Details() string {
+	var b strings.Builder
+	source := r.Path
+	if source == "" {
+		source = "environment"
+	}
+	fmt.Fprintf(&b, "%s: %d problem(s); the defaults are used instead\n", source, len(r.Issues))
+	for _, issue := range r.Issues {
+		field := issue.Field
+		if field == "" {
+			field = "(file)"
+		}
+		b.WriteString("\n  " + field)
+		if issue.Value != "" {
+			fmt.Fprintf(&b, " = %q", issue.Value)
+		}
+		b.WriteString("\n    " + issue.Reason + "\n")
+		if issue.Suggestion != "" {
+			b.WriteString("    " + issue.Suggestion + "\n")
+		}
+	}
+	return b.String()
+}
+
+// LogValue logs the report as a group of its fields and reasons.
+func (r *Report) LogValue() slog.Value {
+	attrs := make([]slog.Attr, len(r.Issues))
+	for i, issue := range r.Issues {
+		field := issue.Field
+		if field == "" {
+			field = "file"
+		}
+		attrs[i] = slog.String(field, issue.Error())
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// issues collects the problems of one check.
+type issues []Issue
+
+func (l *issues) add(field, value, reason, suggestion string) {
+	*l = append(*l, Issue{Field: field, Value: value, Reason: reason, Suggestion: suggestion})
+}
+
+// merge adds the issues of err, or err itself if it is not a Report.
+func (l *issues) merge(err error) {
+	if err == nil {
+		return
+	}
+	var report *Report
+	if errors.As(err, &report) {
+		*l = append(*l, report.Issues...)
+		return
+	}
+	l.add("", "", err.Error(), "")
+}
+
+// err returns the issues as a Report, or nil if there are none.
+func (l issues) err(path string) error {
+	if len(l) == 0 {
+		return nil
+	}
+	return &Report{Path: path, Issues: l}
+}
+
+// validate reports settings out of range and resets them to the default.
+func (c *Config) validate() error {
+	var found issues
+	if c.Log.Level != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+			found.add("log.level", c.Log.Level, "unknown level", "Use debug, info, warn or error.")
+			c.Log.Level = ""
+		}
+	}
+	if kind := c.Store.Kind; kind != "" && kind != repository.StoreMongo && kind != repository.StoreFile {
+		found.add("store.kind", kind, "unknown store",
+			fmt.Sprintf("Use %s for MongoDB or %s for JSON files in store.dir.", repository.StoreMongo, repository.StoreFile))
+		c.Store.Kind = ""
+	}
+	if uri := c.MongoDB.URI; uri != "" {
+		if u, err := url.Parse(uri); err != nil || (u.Scheme != "mongodb" && u.Scheme != "mongodb+srv") || u.Host == "" {
+			found.add("mongodb.uri", uri, "not a MongoDB connection string",
+				"Use a URI such as mongodb://localhost:27017 or mongodb+srv://cluster.example.net.")
+			c.MongoDB.URI = ""
+		}
+	}
+	if len(c.OCR.URLs) > 0 {
+		var valid []string
+		for _, raw := range c.OCR.URLs {
+			if u, err := url.Parse(strings.TrimSpace(raw)); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				found.add("ocr.urls", raw, "not an http or https address",
+					"Use the full address of the OCR service, such as http://localhost:8000.")
+				continue
+			}
+			valid = append(valid, raw)
+		}
+		c.OCR.URLs = valid
+	}
+	if c.OCR.Concurrency != nil && *c.OCR.Concurrency < 0 {
+		found.add("ocr.concurrency", fmt.Sprint(*c.OCR.Concurrency), "must not be negative",
+			"Use 0 for no limit, or the number of requests the OCR service handles at once.")
+		c.OCR.Concurrency = nil
+	}
+	if engine := c.Browser.Engine; engine != "" && engine != browser.EngineChromeDP && engine != browser.EnginePlaywright {
+		found.add("browser.engine", engine, "unknown browser engine",
+			fmt.Sprintf("Use %s, or %s in builds with -tags playwright.", browser.EngineChromeDP, browser.EnginePlaywright))
+		c.Browser.Engine = ""
+	}
+	if raw := c.Login.URLTemplate; raw != "" {
+		if u, err := url.Parse(strings.ReplaceAll(raw, browser.ServerPlaceholder, "1")); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			found.add("login.urlTemplate", raw, "not an http or https address",
+				"Use the full login page address with {server} for the server ID, such as http://www.lequ.com/server/wly/s/{server}.")
+			c.Login.URLTemplate = ""
+		}
+	}
+	for _, retry := range []struct {
+		section            string
+		attempts           **int
+		delay, maxDelay    *string
+		attemptsSuggestion string
+	}{
+		{"loginRetry", &c.LoginRetry.Attempts, &c.LoginRetry.Delay, &c.LoginRetry.MaxDelay,
+			"Use the number of login tries; 1 doesn't retry."},
+		{"reconnect", &c.Reconnect.Attempts, &c.Reconnect.Delay, &c.Reconnect.MaxDelay,
+			"Use the number of restarts to try, or 0 to turn reconnecting off."},
+	} {
+		if n := *retry.attempts; n != nil && *n < 0 {
+			found.add(retry.section+".attempts", fmt.Sprint(*n), "must not be negative", retry.attemptsSuggestion)
+			*retry.attempts = nil
+		}
+		found.positiveDuration(retry.section+".delay", retry.delay, "Use a duration such as 5s.")
+		found.positiveDuration(retry.section+".maxDelay", retry.maxDelay, "Use a duration such as 1m.")
+	}
+	found.httpURL("captcha.solverUrl", &c.Captcha.SolverURL, "Use the full address of the captcha solver, such as http://localhost:9000/solve.")
+	for _, timeout := range []struct {
+		field string
+		value *string
+	}{
+		{"watchdog.timeout", &c.Watchdog.Timeout},
+		{"watchdog.freezeTimeout", &c.Watchdog.FreezeTimeout},
+	} {
+		if raw := *timeout.value; raw != "" {
+			if d, err := time.ParseDuration(raw); err != nil || d < 0 {
+				found.add(timeout.field, raw, "not a duration", "Use a duration such as 10m, or 0 to turn the check off.")
+				*timeout.value = ""
+			}
+		}
+	}
+	switch action := c.Watchdog.Action; action {
+	case "", "refresh", "stop":
+	case "script":
+		if c.Watchdog.Script == "" {
+			found.add("watchdog.action", action, "the script action needs watchdog.script",
+				"Set watchdog.script to the recovery script, or use refresh or stop.")
+			c.Watchdog.Action = ""
+		}
+	default:
+		found.add("watchdog.action", action, "unknown action", "Use refresh, script or stop.")
+		c.Watchdog.Action = ""
+	}
+	if q := c.Screencast.Quality; q < 0 || q > 100 {
+		found.add("screencast.quality", fmt.Sprint(q), "must be between 1 and 100",
+			"Lower values stream smaller frames; 80 is the default.")
+		c.Screencast.Quality = 0
+	}
+	if c.Screencast.MaxFPS < 0 {
+		found.add("screencast.maxFps", fmt.Sprint(c.Screencast.MaxFPS), "must not be negative",
+			"Use a frame rate such as 5, or remove the key for the default.")
+		c.Screencast.MaxFPS = 0
+	}
+	if raw := c.Screencast.StartDelay; raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d < 0 {
+			found.add("screencast.startDelay", raw, "not a duration", "Use a duration such as 1s or 500ms.")
+			c.Screencast.StartDelay = ""
+		}
+	}
+	if raw := c.Screencast.IdlePause; raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d < 0 {
+			found.add("screencast.idlePause", raw, "not a duration",
+				"Use a duration such as 10m, or remove the key to keep streaming.")
+			c.Screencast.IdlePause = ""
+		}
+	}
+	if c.Scenes.Threshold < 0 {
+		found.add("scenes.threshold", fmt.Sprint(c.Scenes.Threshold), "must not be negative",
+			"Use a positive color difference; 5 is the default.")
+		c.Scenes.Threshold = 0
+	}
+	if m := c.Scenes.NearMissMargin; m != nil && *m < 0 {
+		found.add("scenes.nearMissMargin", fmt.Sprint(*m), "must not be negative",
+			"Use a color difference such as 1.0, or 0 to stop recording near misses.")
+		c.Scenes.NearMissMargin = nil
+	}
+	for _, count := range []struct {
+		field string
+		value *int
+	}{
+		{"scenes.nearMissCount", &c.Scenes.NearMissCount},
+		{"scenes.nearMissSessions", &c.Scenes.NearMissSessions},
+		{"journal.maxDays", &c.Journal.MaxDays},
+		{"journal.maxSizeMb", &c.Journal.MaxSizeMB},
+		{"trace.maxRuns", &c.Trace.MaxRuns},
+	} {
+		if *count.value < 0 {
+			found.add(count.field, fmt.Sprint(*count.value), "must not be negative",
+				"Use a positive number, or remove the key for the default.")
+			*count.value = 0
+		}
+	}
+	if c.Scenes.AutoRelax < 0 {
+		found.add("scenes.autoRelax", fmt.Sprint(c.Scenes.AutoRelax), "must not be negative",
+			"Use how far thresholds may be raised, or 0 to only suggest them.")
+		c.Scenes.AutoRelax = 0
+	}
+	for _, hook := range []struct {
+		field string
+		value *string
+	}{
+		{"notify.webhookUrl", &c.Notify.WebhookURL},
+		{"notify.discordUrl", &c.Notify.DiscordURL},
+	} {
+		if raw := *hook.value; raw != "" {
+			if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				found.add(hook.field, raw, "not an http or https address", "Use the full URL the notifications are posted to.")
+				*hook.value = ""
+			}
+		}
+	}
+	if (c.Notify.TelegramToken == "") != (c.Notify.TelegramChatID == "") {
+		found.add("notify.telegramToken", "", "telegramToken and telegramChatId must be set together",
+			"Set the bot token from @BotFather and the chat ID to send to, or remove both.")
+		c.Notify.TelegramToken, c.Notify.TelegramChatID = "", ""
+	}
+	for _, listener := range []struct {
+		field string
+		value *string
+	}{
+		{"api.addr", &c.API.Addr},
+		{"events.addr", &c.Events.Addr},
+		{"metrics.addr", &c.Metrics.Addr},
+		{"diagnostics.pprofAddr", &c.Diagnostics.PprofAddr},
+	} {
+		if raw := *listener.value; raw != "" {
+			if _, _, err := net.SplitHostPort(raw); err != nil {
+				found.add(listener.field, raw, "not a listen address",
+					"Use a host and port such as localhost:8631, or remove the key to turn it off.")
+				*listener.value = ""
+			}
+		}
+	}
+	if store := c.Trace.Store; store != "" && store != trace.StoreFile && store != trace.StoreMongo {
+		found.add("trace.store", store, "unknown trace store",
+			fmt.Sprintf("Use %s, or %s with the MongoDB store.", trace.StoreFile, trace.StoreMongo))
+		c.Trace.Store = ""
+	}
+	found.positiveDuration("presence.interval", &c.Presence.Interval, "Use a duration such as 30s.")
+	found.positiveDuration("presence.ttl", &c.Presence.TTL, "Use a duration such as 2m.")
+	interval := DurationOr(c.Presence.Interval, presence.DefaultInterval)
+	if ttl := DurationOr(c.Presence.TTL, presence.DefaultTTL); ttl <= interval {
+		found.add("presence.ttl", ttl.String(), "must be longer than the "+interval.String()+" heartbeat interval",
+			fmt.Sprintf("Use a few intervals; %s is used until then.", 4*interval))
+	}
+	for _, d := range []struct {
+		field string
+		value *string
+	}{
+		{"diagnostics.profileInterval", &c.Diagnostics.ProfileInterval},
+		{"diagnostics.cpuSample", &c.Diagnostics.CPUSample},
+	} {
+		if raw := *d.value; raw != "" {
+			if v, err := time.ParseDuration(raw); err != nil || v < 0 {
+				found.add(d.field, raw, "not a duration", "Use a duration such as 5m, or 0 to turn it off.")
+				*d.value = ""
+			}
+		}
+	}
+	found.httpURL("update.feedUrl", &c.Update.FeedURL, "Use the full address of the release feed JSON.")
+	if key := c.Update.PublicKey; key != "" {
+		if _, err := update.ParsePublicKey(key); err != nil {
+			found.add("update.publicKey", key, err.Error(), "Use the base64 or hex Ed25519 public key releases are signed with.")
+			c.Update.PublicKey = ""
+		}
+	}
+	return found.err("")
+}
+
+// positiveDuration reports and clears a set value that is not a positive
+// duration.
+func (l *issues) positiveDuration(field string, value *string, suggestion string) {
+	if raw := *value; raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d <= 0 {
+			l.add(field, raw, "not a positive duration", suggestion)
+			*value = ""
+		}
+	}
+}
+
+// httpURL reports and clears a set value that is not an http or https address.
+func (l *issues) httpURL(field string, value *string, suggestion string) {
+	if raw := *value; raw != "" {
+		if u, err := url.Parse(strings.TrimSpace(raw)); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			l.add(field, raw, "not an http or https address", suggestion)
+			*value = ""
+		}
+	}
+}
+
+// yamlUnknownField matches yaml.v3's error for keys not in the struct.
+var yamlUnknownField = regexp.MustCompile(`^line (\d+): field (\S+) not found in type config\.(\w+)$`)
+
+// yamlKeyIssues turns the unknown key errors of a YAML decode into issues.
+// It returns nil if err has other errors as well.
+func yamlKeyIssues(err error) issues {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return nil
+	}
+	var found issues
+	for _, msg := range typeErr.Errors {
+		m := yamlUnknownField.FindStringSubmatch(msg)
+		if m == nil {
+			return nil
+		}
+		section := sectionOfType(m[3])
+		found = append(found, unknownKeyIssue(section, m[2], "line "+m[1]))
+	}
+	return found
+}
+
+// tomlKeyIssues turns undecoded TOML keys into issues.
+func tomlKeyIssues(keys []toml.Key) issues {
+	var found issues
+	for _, key := range keys {
+		// Keys below an unknown key are reported with it
+		if len(key) > 2 || (len(key) == 2 && !isSection(key[0])) {
+			continue
+		}
+		section := ""
+		if len(key) == 2 {
+			section = key[0]
+		}
+		found = append(found, unknownKeyIssue(section, key[len(key)-1], ""))
+	}
+	return found
+}
+
+func unknownKeyIssue(section, key, where string) Issue {
+	field := key
+	if section != "" {
+		field = section + "." + key
+	}
+	reason := "unknown key"
+	if where != "" {
+		reason += " (" + where + ")"
+	}
+	reason += "; the file is ignored until it is fixed"
+	known := knownKeys(section)
+	where = "at the top level"
+	if section != "" {
+		where = "under " + section
+	}
+	return Issue{
+		Field:      field,
+		Reason:     reason,
+		Suggestion: fmt.Sprintf("Check the spelling; the keys %s are %s.", where, strings.Join(known, ", ")),
+	}
+}
+
+// knownKeys returns the keys of a section, or the sections for "".
+func knownKeys(section string) []string {
+	t := reflect.TypeOf(Config{})
+	if section != "" {
+		for i := 0; i < t.NumField(); i++ {
+			if tagName(t.Field(i)) == section {
+				t = t.Field(i).Type
+				break
+			}
+		}
+	}
+	keys := make([]string, t.NumField())
+	for i := range keys {
+		keys[i] = tagName(t.Field(i))
+	}
+	return keys
+}
+
+func isSection(name string) bool {
+	for _, key := range knownKeys("") {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionOfType returns the section a Config struct type is decoded into.
+func sectionOfType(typeName string) string {
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type.Name() == typeName {
+			return tagName(t.Field(i))
+		}
+	}
+	return ""
+}
+
+func tagName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	return name
+}
@@ -0,0 +1,189 @@
+// Package crypto encrypts account secrets (game and proxy passwords and
+// cookie values) before they are stored, using AES-256-GCM. Encrypted values are strings
+// with a version prefix, so plaintext values written before encryption was
+// enabled can still be read and are encrypted on their next write.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"wardenly-go/infrastructure/appdir"
+)
+
+// Environment variables read by CipherFromEnv.
+const (
+	// EnvKey holds a base64 encoded 32 byte key.
+	EnvKey = "WARDENLY_SECRET_KEY"
+	// EnvKeyFile overrides where the key file is kept.
+	EnvKeyFile = "WARDENLY_SECRET_KEY_FILE"
+)
+
+// KeySize is the key length in bytes (AES-256).
+const KeySize = 32
+
+// prefix marks encrypted values; the rest is base64(nonce || ciphertext).
+const prefix = "enc:v1:"
+
+// ErrDecrypt is returned for encrypted values that can't be decrypted,
+// usually because they were encrypted with a different key.
+var ErrDecrypt = errors.New("cannot decrypt secret")
+
+// Cipher encrypts and decrypts secrets. A nil Cipher leaves values in
+// plaintext.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a cipher from a KeySize byte key.
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// minSealed is the size of an encrypted empty value: the GCM nonce and tag.
+const minSealed = 12 + 16
+
+// IsEncrypted returns true if value has the form produced by Encrypt: the
+// prefix followed by base64 long enough to hold a nonce and tag. A plaintext
+// value that merely starts with the prefix is not encrypted.
+func IsEncrypted(value string) bool {
+	payload, ok := strings.CutPrefix(value, prefix)
+	if !ok {
+		return false
+	}
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	return err == nil && len(sealed) >= minSealed
+}
+
+// Encrypt encrypts a secret. Empty values are returned unchanged. Values
+// that look encrypted are encrypted again rather than trusted, since a
+// password can start with the prefix too.
+func (c *Cipher) Encrypt(value string) (string, error) {
+	if c == nil || value == "" {
+		return value, nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(value), nil)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt. Plaintext values are
+// returned unchanged.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if c == nil {
+		return "", fmt.Errorf("%w: no key configured", ErrDecrypt)
+	}
+	sealed, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: wrong key or corrupted value", ErrDecrypt)
+	}
+	return string(plaintext), nil
+}
+
+// DefaultKeyPath returns where the generated key is kept. Unlike logs, the
+// key is never put in the temp directory: a key lost with it would leave
+// every stored secret undecryptable, so there being no user directory is an
+// error.
+func DefaultKeyPath() (string, error) {
+	return appdir.Lookup("secret.key")
+}
+
+// CipherFromEnv creates the cipher for stored secrets. The key is taken
+// from WARDENLY_SECRET_KEY if set, otherwise from the key file at
+// WARDENLY_SECRET_KEY_FILE, keyFile or DefaultKeyPath, in that order, which
+// is generated on first use and readable only by the current user.
+func CipherFromEnv(keyFile string) (*Cipher, error) {
+	if raw := os.Getenv(EnvKey); raw != "" {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s: must be base64 encoded: %w", EnvKey, err)
+		}
+		c, err := NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvKey, err)
+		}
+		return c, nil
+	}
+
+	path := os.Getenv(EnvKeyFile)
+	if path == "" {
+		path = keyFile
+	}
+	if path == "" {
+		var err error
+		if path, err = DefaultKeyPath(); err != nil {
+			return nil, fmt.Errorf("set %s or %s: %w", EnvKey, EnvKeyFile, err)
+		}
+	}
+	key, err := LoadOrCreateKey(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewCipher(key)
+}
+
+// LoadOrCreateKey reads a base64 encoded key from path, generating and
+// saving a new random key if the file doesn't exist.
+func LoadOrCreateKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key file %s: %w", path, err)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %w", err)
+	}
+	// O_EXCL so two instances starting together can't overwrite each other's key
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return LoadOrCreateKey(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key file: %w", err)
+	}
+	_, err = f.WriteString(base64.StdEncoding.EncodeToString(key) + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write key file: %w", err)
+	}
+	return key, nil
+}
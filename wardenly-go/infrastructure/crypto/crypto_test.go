@@ -0,0 +1,132 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func newTestCipher(t *testing.T, fill byte) *Cipher {
+	t.Helper()
+	c, err := NewCipher(bytes.Repeat([]byte{fill}, KeySize))
+	if err != nil {
+		t.Fatalf("NewCipher() error = %v", err)
+	}
+	return c
+}
+
+func TestCipher_RoundTrip(t *testing.T) {
+	c := newTestCipher(t, 1)
+
+	enc, err := c.Encrypt("hunter2")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !IsEncrypted(enc) || enc == "hunter2" {
+		t.Fatalf("Encrypt() = %q, want an encrypted value", enc)
+	}
+	if again, _ := c.Encrypt("hunter2"); again == enc {
+		t.Error("Encrypt() should use a fresh nonce each time")
+	}
+	// A value that looks encrypted is still encrypted, so it reads back as written
+	for _, value := range []string{enc, "enc:v1:hunter2"} {
+		twice, _ := c.Encrypt(value)
+		if dec, err := c.Decrypt(twice); twice == value || err != nil || dec != value {
+			t.Errorf("Encrypt(%q) = %q, decrypting to %q, %v", value, twice, dec, err)
+		}
+	}
+	if IsEncrypted("enc:v1:hunter2") {
+		t.Error("IsEncrypted() = true for plaintext with the prefix")
+	}
+
+	dec, err := c.Decrypt(enc)
+	if err != nil || dec != "hunter2" {
+		t.Errorf("Decrypt() = %q, %v, want hunter2", dec, err)
+	}
+	for _, legacy := range []string{"legacy", "enc:v1:legacy"} {
+		if dec, err := c.Decrypt(legacy); err != nil || dec != legacy {
+			t.Errorf("Decrypt(%q) = %q, %v, want it unchanged", legacy, dec, err)
+		}
+	}
+	if enc, _ := c.Encrypt(""); enc != "" {
+		t.Errorf("Encrypt(\"\") = %q, want empty", enc)
+	}
+}
+
+func TestCipher_WrongKey(t *testing.T) {
+	enc, _ := newTestCipher(t, 1).Encrypt("hunter2")
+
+	if _, err := newTestCipher(t, 2).Decrypt(enc); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Decrypt() with another key error = %v, want ErrDecrypt", err)
+	}
+	var none *Cipher
+	if _, err := none.Decrypt(enc); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Decrypt() without a key error = %v, want ErrDecrypt", err)
+	}
+	if plain, _ := none.Encrypt("hunter2"); plain != "hunter2" {
+		t.Errorf("nil Cipher Encrypt() = %q, want plaintext", plain)
+	}
+}
+
+func TestLoadOrCreateKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wardenly", "secret.key")
+
+	key, err := LoadOrCreateKey(path)
+	if err != nil || len(key) != KeySize {
+		t.Fatalf("LoadOrCreateKey() = %d bytes, %v", len(key), err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("key file mode = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+	again, err := LoadOrCreateKey(path)
+	if err != nil || !bytes.Equal(again, key) {
+		t.Error("LoadOrCreateKey() should reuse the saved key")
+	}
+}
+
+func TestCipherFromEnv(t *testing.T) {
+	t.Setenv(EnvKeyFile, filepath.Join(t.TempDir(), "secret.key"))
+	t.Setenv(EnvKey, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize)))
+
+	c, err := CipherFromEnv("")
+	if err != nil {
+		t.Fatalf("CipherFromEnv() error = %v", err)
+	}
+	enc, _ := newTestCipher(t, 1).Encrypt("hunter2")
+	if dec, err := c.Decrypt(enc); err != nil || dec != "hunter2" {
+		t.Errorf("cipher from %s can't decrypt: %v", EnvKey, err)
+	}
+
+	t.Setenv(EnvKey, "c2hvcnQ=")
+	if _, err := CipherFromEnv(""); err == nil {
+		t.Error("CipherFromEnv() should reject a short key")
+	}
+
+	t.Setenv(EnvKey, "")
+	if _, err := CipherFromEnv(""); err != nil {
+		t.Errorf("CipherFromEnv() with key file error = %v", err)
+	}
+
+	t.Setenv(EnvKeyFile, "")
+	keyFile := filepath.Join(t.TempDir(), "data", "secret.key")
+	if _, err := CipherFromEnv(keyFile); err != nil {
+		t.Fatalf("CipherFromEnv(keyFile) error = %v", err)
+	}
+	if _, err := os.Stat(keyFile); err != nil {
+		t.Errorf("key not created at the given path: %v", err)
+	}
+
+	if runtime.GOOS == "linux" {
+		// Without a config or cache dir the key must not fall back to /tmp
+		t.Setenv("HOME", "")
+		t.Setenv("XDG_CONFIG_HOME", "")
+		t.Setenv("XDG_CACHE_HOME", "")
+		if _, err := CipherFromEnv(""); err == nil {
+			t.Error("CipherFromEnv() created a key without a user directory")
+		}
+	}
+}
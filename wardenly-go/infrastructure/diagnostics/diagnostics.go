@@ -0,0 +1,319 @@
+// Package diagnostics provides optional profiling hooks for performance
+// bug reports: a net/http/pprof endpoint and periodic self-profiling
+// summaries written to the diagnostics directory.
+package diagnostics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"wardenly-go/infrastructure/appdir"
+	"wardenly-go/infrastructure/httpguard"
+)
+
+// Environment variables overriding the diagnostics section of the config file.
+const (
+	EnvPprofAddr       = "WARDENLY_PPROF_ADDR"
+	EnvPprofToken      = "WARDENLY_PPROF_TOKEN"
+	EnvProfileInterval = "WARDENLY_PROFILE_INTERVAL"
+	EnvCPUSample       = "WARDENLY_PROFILE_CPU"
+)
+
+// Config holds diagnostics configuration. The zero value disables everything.
+type Config struct {
+	// PprofAddr is the listen address for the pprof endpoint (e.g. "localhost:6060").
+	// Empty disables the endpoint.
+	PprofAddr string
+	// PprofToken is the bearer token clients must send. It may be empty
+	// only when PprofAddr is a loopback address.
+	PprofToken string
+	// Dir is where profiling summaries are written.
+	// If empty, defaults to DefaultDir().
+	Dir string
+	// Interval between self-profiling summaries. Zero disables them.
+	Interval time.Duration
+	// CPUSample is the length of the CPU profile captured with each summary.
+	// Zero skips CPU sampling.
+	CPUSample time.Duration
+	// TopGoroutines is the number of goroutine groups listed per summary.
+	TopGoroutines int
+	// MaxFiles is the number of summary files retained.
+	MaxFiles int
+}
+
+// DefaultDir returns the default diagnostics directory.
+func DefaultDir() string {
+	return appdir.Path("diagnostics")
+}
+
+// Profiler runs the pprof endpoint and the periodic summary loop.
+type Profiler struct {
+	config *Config
+	logger *slog.Logger
+
+	server *http.Server
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Start launches whichever diagnostics the config enables.
+// Stop must be called to release them.
+func Start(cfg *Config, logger *slog.Logger) (*Profiler, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = DefaultDir()
+	}
+	if cfg.TopGoroutines <= 0 {
+		cfg.TopGoroutines = 10
+	}
+	if cfg.MaxFiles <= 0 {
+		cfg.MaxFiles = 48
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Profiler{
+		config: cfg,
+		logger: logger,
+		cancel: cancel,
+	}
+
+	if cfg.PprofAddr != "" {
+		if err := p.startServer(); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	if cfg.Interval > 0 {
+		if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+			p.Stop()
+			return nil, fmt.Errorf("failed to create diagnostics dir: %w", err)
+		}
+		p.wg.Add(1)
+		go p.summaryLoop(ctx)
+		logger.Info("Self-profiling enabled", "dir", cfg.Dir, "interval", cfg.Interval)
+	}
+
+	return p, nil
+}
+
+// Stop shuts down the endpoint and the summary loop.
+func (p *Profiler) Stop() {
+	p.cancel()
+	if p.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		p.server.Shutdown(ctx)
+	}
+	p.wg.Wait()
+}
+
+func (p *Profiler) startServer() error {
+	token := p.config.PprofToken
+	if token == "" && !httpguard.IsLoopback(p.config.PprofAddr) {
+		return fmt.Errorf("%s is required when pprof listens on %s", EnvPprofToken, p.config.PprofAddr)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	ln, err := net.Listen("tcp", p.config.PprofAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for pprof: %w", err)
+	}
+
+	p.server = &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !httpguard.Authorized(r, token) {
+			http.Error(w, "missing or invalid token", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if err := p.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.logger.Error("pprof endpoint stopped", "error", err)
+		}
+	}()
+
+	p.logger.Info("pprof endpoint listening", "addr", ln.Addr().String(), "auth", token != "")
+	return nil
+}
+
+func (p *Profiler) summaryLoop(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.writeSummary(ctx, time.Now()); err != nil {
+				p.logger.Warn("Failed to write profiling summary", "error", err)
+			}
+		}
+	}
+}
+
+// writeSummary writes one summary file (and an optional CPU profile),
+// then prunes old files.
+func (p *Profiler) writeSummary(ctx context.Context, now time.Time) error {
+	stamp := now.Format("20060102-150405")
+
+	if p.config.CPUSample > 0 {
+		if err := p.writeCPUProfile(ctx, filepath.Join(p.config.Dir, "cpu-"+stamp+".pprof")); err != nil {
+			p.logger.Warn("Skipped CPU sample", "error", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Summarize(&buf, now, p.config.TopGoroutines); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(p.config.Dir, "summary-"+stamp+".txt"), buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	return prune(p.config.Dir, p.config.MaxFiles)
+}
+
+// writeCPUProfile samples the CPU for the configured duration.
+// It fails if another CPU profile (e.g. via the endpoint) is in progress.
+func (p *Profiler) writeCPUProfile(ctx context.Context, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := runtimepprof.StartCPUProfile(f); err != nil {
+		os.Remove(path)
+		return err
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(p.config.CPUSample):
+	}
+	runtimepprof.StopCPUProfile()
+	return nil
+}
+
+// Summarize writes a plain-text report of heap statistics and the most
+// populous goroutine groups.
+func Summarize(w io.Writer, now time.Time, topGoroutines int) error {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	fmt.Fprintf(w, "time: %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(w, "goroutines: %d\n", runtime.NumGoroutine())
+	fmt.Fprintf(w, "heap_alloc_mib: %.1f\n", float64(ms.HeapAlloc)/(1<<20))
+	fmt.Fprintf(w, "heap_inuse_mib: %.1f\n", float64(ms.HeapInuse)/(1<<20))
+	fmt.Fprintf(w, "heap_objects: %d\n", ms.HeapObjects)
+	fmt.Fprintf(w, "num_gc: %d\n", ms.NumGC)
+	fmt.Fprintf(w, "last_gc_pause: %s\n", time.Duration(ms.PauseNs[(ms.NumGC+255)%256]))
+
+	var profile bytes.Buffer
+	if err := runtimepprof.Lookup("goroutine").WriteTo(&profile, 1); err != nil {
+		return err
+	}
+	groups := goroutineGroups(profile.String())
+	if len(groups) > topGoroutines {
+		groups = groups[:topGoroutines]
+	}
+
+	fmt.Fprintf(w, "\ntop goroutine groups:\n")
+	for _, g := range groups {
+		fmt.Fprintf(w, "\n%s\n", g)
+	}
+	return nil
+}
+
+// goroutineGroups splits a debug=1 goroutine profile into its stack groups,
+// largest first. Each group starts with "<count> @ <pcs>".
+func goroutineGroups(profile string) []string {
+	var groups []string
+	var counts []int
+
+	scanner := bufio.NewScanner(strings.NewReader(profile))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var cur strings.Builder
+	flush := func() {
+		text := strings.TrimSpace(cur.String())
+		cur.Reset()
+		var n int
+		if _, err := fmt.Sscanf(text, "%d @", &n); err != nil {
+			return
+		}
+		groups = append(groups, text)
+		counts = append(counts, n)
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		cur.WriteString(line)
+		cur.WriteByte('\n')
+	}
+	flush()
+
+	idx := make([]int, len(groups))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return counts[idx[a]] > counts[idx[b]] })
+
+	sorted := make([]string, len(groups))
+	for i, j := range idx {
+		sorted[i] = groups[j]
+	}
+	return sorted
+}
+
+// prune removes the oldest summary and CPU files beyond maxFiles each.
+func prune(dir string, maxFiles int) error {
+	for _, pattern := range []string{"summary-*.txt", "cpu-*.pprof"} {
+		files, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return err
+		}
+		sort.Strings(files) // timestamped names sort chronologically
+		for len(files) > maxFiles {
+			if err := os.Remove(files[0]); err != nil {
+				return err
+			}
+			files = files[1:]
+		}
+	}
+	return nil
+}
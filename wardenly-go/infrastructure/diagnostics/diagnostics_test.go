@@ -0,0 +1,107 @@
+package diagnostics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoroutineGroups(t *testing.T) {
+	profile := `goroutine profile: total 6
+
+1 @ 0x1 0x2
+#	0x1	main.a+0x1	a.go:1
+
+4 @ 0x3 0x4
+#	0x3	main.b+0x1	b.go:1
+
+1 @ 0x5
+#	0x5	main.c+0x1	c.go:1
+`
+	groups := goroutineGroups(profile)
+	if len(groups) != 3 {
+		t.Fatalf("goroutineGroups() returned %d groups, want 3", len(groups))
+	}
+	if !strings.HasPrefix(groups[0], "4 @") || !strings.Contains(groups[1], "main.a") {
+		t.Errorf("groups not ordered by count, stable: %q", groups)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Summarize(&buf, time.Now(), 3); err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"goroutines:", "heap_alloc_mib:", "top goroutine groups:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q", want)
+		}
+	}
+}
+
+func TestProfiler_WritesAndPrunesSummaries(t *testing.T) {
+	dir := t.TempDir()
+	p, err := Start(&Config{Dir: dir, Interval: time.Hour, MaxFiles: 2}, nil)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer p.Stop()
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if err := p.writeSummary(t.Context(), base.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("writeSummary() error = %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "summary-*.txt"))
+	if len(files) != 2 {
+		t.Fatalf("kept %d summaries, want 2", len(files))
+	}
+	if filepath.Base(files[0]) != "summary-20260101-000001.txt" {
+		t.Errorf("oldest summary not pruned, kept %v", files)
+	}
+	if _, err := os.Stat(files[1]); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStart_Disabled(t *testing.T) {
+	p, err := Start(&Config{}, nil)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if p.server != nil {
+		t.Error("pprof endpoint started without an address")
+	}
+	p.Stop()
+}
+
+func TestStart_PprofRequiresTokenOffLoopback(t *testing.T) {
+	if _, err := Start(&Config{PprofAddr: "0.0.0.0:0"}, nil); err == nil {
+		t.Fatal("Start() served pprof on every interface without a token")
+	}
+
+	p, err := Start(&Config{PprofAddr: "127.0.0.1:0", PprofToken: "secret"}, nil)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer p.Stop()
+
+	rec := httptest.NewRecorder()
+	p.server.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/pprof/cmdline", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want 401", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	p.server.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/pprof/cmdline?token=secret", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status with token = %d, want 200", rec.Code)
+	}
+}
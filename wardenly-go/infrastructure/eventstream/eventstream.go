@@ -0,0 +1,100 @@
+// Package eventstream streams session events over WebSocket, so dashboards
+// and monitoring tools can follow sessions without going through the UI.
+package eventstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"wardenly-go/core/eventbus"
+	"wardenly-go/infrastructure/httpguard"
+)
+
+// Environment variables overriding the events section of the config file.
+const (
+	EnvAddr  = "WARDENLY_EVENTS_ADDR"
+	EnvToken = "WARDENLY_EVENTS_TOKEN"
+)
+
+// DefaultScreenshotInterval is the minimum gap between two screenshots of
+// the same session sent to a client.
+const DefaultScreenshotInterval = 2 * time.Second
+
+// Config holds event stream server configuration.
+type Config struct {
+	// Addr is the listen address (e.g. "localhost:8632"). Empty disables streaming.
+	Addr string
+	// Token is the token clients must send. It may be empty only when Addr
+	// is a loopback address.
+	Token string
+	// ScreenshotInterval throttles screenshots per session and client.
+	ScreenshotInterval time.Duration
+	Logger             *slog.Logger
+}
+
+// Enabled reports whether the event stream should be started.
+func (c *Config) Enabled() bool {
+	return c != nil && c.Addr != ""
+}
+
+// Server serves the event stream until stopped.
+type Server struct {
+	server   *http.Server
+	streamer *Streamer
+	logger   *slog.Logger
+}
+
+// Start listens on cfg.Addr and streams events from bus in the background.
+// Stop must be called to release the listener and disconnect clients.
+func Start(cfg *Config, bus eventbus.EventBus) (*Server, error) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.Token == "" && !httpguard.IsLoopback(cfg.Addr) {
+		return nil, fmt.Errorf("%s is required when the event stream listens on %s", EnvToken, cfg.Addr)
+	}
+
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", cfg.Addr, err)
+	}
+
+	streamer := NewStreamer(cfg, bus)
+	mux := http.NewServeMux()
+	mux.Handle("GET /events", streamer)
+
+	s := &Server{
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		streamer: streamer,
+		logger:   cfg.Logger,
+	}
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Event stream server failed", "error", err)
+		}
+	}()
+
+	s.logger.Info("Event stream listening", "addr", ln.Addr().String(), "auth", cfg.Token != "")
+	return s, nil
+}
+
+// Stop shuts the server down and disconnects all clients.
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Upgraded connections are not tracked by http.Server
+	s.streamer.Close()
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.logger.Warn("Event stream shutdown failed", "error", err)
+	}
+}
@@ -0,0 +1,179 @@
+package eventstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+	"wardenly-go/core/state"
+)
+
+func TestNewMessage(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	msg, ok := NewMessage(event.NewSessionStateChanged("s1", state.StateLoggingIn, state.StateReady), now)
+	if !ok {
+		t.Fatal("state change not streamed")
+	}
+	got, _ := json.Marshal(msg)
+	want := `{"type":"SessionStateChanged","sessionId":"s1","time":"2026-01-02T03:04:05Z","data":{"from":"LoggingIn","to":"Ready"}}`
+	if string(got) != want {
+		t.Errorf("message = %s, want %s", got, want)
+	}
+
+	msg, _ = NewMessage(event.Correlate(event.NewScriptStarted("s1", "daily"), "c1"), now)
+	got, _ = json.Marshal(msg)
+	want = `{"type":"ScriptStarted","sessionId":"s1","correlationId":"c1","time":"2026-01-02T03:04:05Z","data":{"script":"daily"}}`
+	if string(got) != want {
+		t.Errorf("correlated message = %s, want %s", got, want)
+	}
+
+	msg, _ = NewMessage(event.NewScriptStopped("s1", "daily", event.StopReasonError, errors.New("boom")), now)
+	if data := msg.Data.(map[string]string); data["reason"] != "Error" || data["error"] != "boom" {
+		t.Errorf("script stopped data = %v", data)
+	}
+
+	msg, _ = NewMessage(event.NewScriptStuck("s1", "daily", 10*time.Minute, "refresh"), now)
+	if data := msg.Data.(map[string]any); data["idleSeconds"] != int64(600) || data["action"] != "refresh" {
+		t.Errorf("script stuck data = %v", data)
+	}
+	msg, _ = NewMessage(event.NewScreenFrozen("s1", "daily", 3*time.Minute, "stop"), now)
+	if data := msg.Data.(map[string]any); data["frozenSeconds"] != int64(180) || data["action"] != "stop" {
+		t.Errorf("screen frozen data = %v", data)
+	}
+
+	msg, _ = NewMessage(event.NewScriptThrottled("s1", "daily", "click", 60, 12*time.Second), now)
+	if data := msg.Data.(map[string]any); data["limit"] != 60 || data["pauseSeconds"] != int64(12) {
+		t.Errorf("script throttled data = %v", data)
+	}
+
+	msg, _ = NewMessage(event.NewOCRResultRecognized("s1", "daily", "quit_when_exhausted", 5, 6, 4, true), now)
+	if data := msg.Data.(map[string]any); data["triggered"] != true || data["denominator"] != 6 {
+		t.Errorf("OCR data = %v", data)
+	}
+
+	msg, _ = NewMessage(event.NewOCRTextRecognized("s1", "daily", "match_text", []string{"讨伐山贼"}, "讨伐"), now)
+	if data := msg.Data.(map[string]any); data["matched"] != "讨伐" {
+		t.Errorf("OCR text data = %v", data)
+	}
+
+	if _, ok := NewMessage(event.NewScriptStepExecuted("s1", "daily", 0, "main", nil, "continue", ""), now); ok {
+		t.Error("step events should not be streamed")
+	}
+	if _, ok := NewMessage(event.NewActionPerformed("s1", "daily", "click", 1, 2, 1, 2), now); ok {
+		t.Error("action events should not be streamed")
+	}
+
+	msg, _ = NewMessage(event.NewInputLagChanged("s1", true, 620*time.Millisecond, 900*time.Millisecond), now)
+	if data := msg.Data.(map[string]any); data["lagging"] != true || data["meanMs"] != int64(620) {
+		t.Errorf("input lag data = %v", data)
+	}
+	msg, _ = NewMessage(event.NewGameDataCaptured("s1", "http://s1/api/player", map[string]int{"energy": 42}), now)
+	if data := msg.Data.(map[string]any); data["values"].(map[string]int)["energy"] != 42 {
+		t.Errorf("game data = %v", data)
+	}
+	if _, ok := NewMessage(event.NewLatencyUpdated("s1", 10, time.Millisecond, time.Millisecond, time.Millisecond, false), now); ok {
+		t.Error("periodic latency stats should not be streamed")
+	}
+}
+
+func TestNewMessage_Screenshot(t *testing.T) {
+	msg, ok := NewMessage(event.NewScreenCaptured("s1", image.NewRGBA(image.Rect(0, 0, 8, 6))), time.Now())
+	if !ok {
+		t.Fatal("screenshot not streamed")
+	}
+	shot := msg.Data.(*Screenshot)
+	raw, err := base64.StdEncoding.DecodeString(shot.Image)
+	if err != nil {
+		t.Fatalf("image is not base64: %v", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(raw))
+	if err != nil || img.Bounds().Dx() != 8 || shot.Width != 8 || shot.Height != 6 {
+		t.Errorf("screenshot = %dx%d, decode error %v", shot.Width, shot.Height, err)
+	}
+}
+
+func TestStreamer_Stream(t *testing.T) {
+	bus := eventbus.New(10)
+	defer bus.Close()
+
+	streamer := NewStreamer(&Config{Token: "secret"}, bus)
+	server := httptest.NewServer(streamer)
+	defer server.Close()
+	defer streamer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	if resp, err := http.Get(server.URL + "?token=wrong"); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong token = %v, %v", resp, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, _, err := ws.Dial(ctx, wsURL+"?token=secret&session=s1")
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	// Wait until the subscription is registered
+	time.Sleep(50 * time.Millisecond)
+
+	bus.Publish(event.NewScriptStarted("s2", "daily")) // Other session, filtered out
+	bus.Publish(event.NewScreenCaptured("s1", image.NewRGBA(image.Rect(0, 0, 2, 2))))
+	bus.Publish(event.NewScriptStarted("s1", "daily"))
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	payload, err := wsutil.ReadServerText(conn)
+	if err != nil {
+		t.Fatalf("ReadServerText() error = %v", err)
+	}
+	var msg Message
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatalf("bad message %s: %v", payload, err)
+	}
+	// Screenshots are opt-in, so the first message is the script start
+	if msg.Type != "ScriptStarted" || msg.SessionID != "s1" {
+		t.Errorf("message = %+v, want ScriptStarted for s1", msg)
+	}
+}
+
+func TestStreamer_RejectsCrossSite(t *testing.T) {
+	bus := eventbus.New(10)
+	defer bus.Close()
+
+	streamer := NewStreamer(&Config{}, bus)
+	server := httptest.NewServer(streamer)
+	defer server.Close()
+	defer streamer.Close()
+
+	dialer := ws.Dialer{Header: ws.HandshakeHeaderHTTP(http.Header{"Origin": {"https://evil.example"}})}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	if conn, _, _, err := dialer.Dial(ctx, wsURL); err == nil {
+		conn.Close()
+		t.Fatal("Dial() from a foreign origin succeeded")
+	}
+
+	dialer.Header = ws.HandshakeHeaderHTTP(http.Header{"Origin": {server.URL}})
+	conn, _, _, err := dialer.Dial(ctx, wsURL)
+	if err != nil {
+		t.Fatalf("Dial() from the local origin error = %v", err)
+	}
+	conn.Close()
+}
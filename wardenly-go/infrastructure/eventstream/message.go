@@ -0,0 +1,192 @@
+package eventstream
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/jpeg"
+	"time"
+
+	"wardenly-go/core/event"
+)
+
+// Message is the JSON frame sent to clients for each event.
+type Message struct {
+	Type      string `json:"type"`
+	SessionID string `json:"sessionId,omitempty"`
+	// CorrelationID identifies the command that caused the event
+	CorrelationID string    `json:"correlationId,omitempty"`
+	Time          time.Time `json:"time"`
+	Data          any       `json:"data,omitempty"`
+}
+
+// Screenshot is the data of a ScreenCaptured message.
+type Screenshot struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Format string `json:"format"`
+	Image  string `json:"image"` // base64-encoded
+}
+
+// screenshotQuality keeps frames small enough to stream to several clients.
+const screenshotQuality = 70
+
+// NewMessage converts an event to a message. It returns false for events
+// that are not streamed (internal or high-frequency ones).
+func NewMessage(e event.Event, now time.Time) (*Message, bool) {
+	msg := &Message{Type: e.EventName(), CorrelationID: event.CorrelationID(e), Time: now}
+	if se, ok := e.(event.SessionEvent); ok {
+		msg.SessionID = se.SessionID()
+	}
+
+	switch evt := e.(type) {
+	case *event.SessionStarted:
+		msg.Data = map[string]string{"accountId": evt.AccountID, "account": evt.AccountName}
+	case *event.SessionStopped:
+		msg.Data = errorData(evt.Error)
+	case *event.SessionStateChanged:
+		msg.Data = map[string]string{"from": evt.OldState.String(), "to": evt.NewState.String()}
+	case *event.SessionQueued:
+		msg.Data = map[string]int{"position": evt.Position}
+	case *event.SessionReconnecting:
+		data := map[string]any{"attempt": evt.Attempt, "delaySeconds": int64(evt.Delay.Seconds())}
+		if evt.Error != nil {
+			data["error"] = evt.Error.Error()
+		}
+		msg.Data = data
+	case *event.SessionReconnected:
+		msg.Data = map[string]int{"attempts": evt.Attempts}
+	case *event.LoginSucceeded:
+	case *event.LoginFailed:
+		msg.Data = errorData(evt.Error)
+	case *event.LoginRetrying:
+		data := map[string]any{"attempt": evt.Attempt, "delaySeconds": int64(evt.Delay.Seconds()), "password": evt.Password}
+		if evt.Error != nil {
+			data["error"] = evt.Error.Error()
+		}
+		msg.Data = data
+	case *event.ScriptStarted:
+		msg.Data = map[string]string{"script": evt.ScriptName}
+	case *event.ScriptStopped:
+		data := map[string]string{"script": evt.ScriptName, "reason": evt.Reason.String()}
+		if evt.Error != nil {
+			data["error"] = evt.Error.Error()
+		}
+		msg.Data = data
+	case *event.ScriptStuck:
+		msg.Data = map[string]any{
+			"script":      evt.ScriptName,
+			"idleSeconds": int64(evt.Idle.Seconds()),
+			"action":      evt.Action,
+		}
+	case *event.ScreenFrozen:
+		msg.Data = map[string]any{
+			"script":        evt.ScriptName,
+			"frozenSeconds": int64(evt.Frozen.Seconds()),
+			"action":        evt.Action,
+		}
+	case *event.ScriptThrottled:
+		msg.Data = map[string]any{
+			"script":       evt.ScriptName,
+			"action":       evt.Action,
+			"limit":        evt.Limit,
+			"pauseSeconds": int64(evt.Pause.Seconds()),
+		}
+	case *event.ScriptRefused:
+		data := map[string]string{"script": evt.ScriptName}
+		if evt.Reason != nil {
+			data["reason"] = evt.Reason.Error()
+		}
+		msg.Data = data
+	case *event.SetupFinished:
+		data := map[string]any{"script": evt.ScriptName, "completed": evt.Completed}
+		if evt.Error != nil {
+			data["error"] = evt.Error.Error()
+		}
+		msg.Data = data
+	case *event.ScriptsReloaded:
+		data := map[string]any{"scripts": evt.Names}
+		if evt.Error != nil {
+			data["error"] = evt.Error.Error()
+		}
+		msg.Data = data
+	case *event.AccountsSynced:
+		data := map[string]any{"added": evt.Added, "updated": evt.Updated, "skipped": evt.Skipped}
+		if evt.Error != nil {
+			data["error"] = evt.Error.Error()
+		}
+		msg.Data = data
+	case *event.SceneThresholdSuggested:
+		msg.Data = map[string]any{
+			"scene":     evt.SceneName,
+			"threshold": evt.Threshold,
+			"suggested": evt.Suggested,
+			"count":     evt.Count,
+			"sessions":  evt.Sessions,
+			"applied":   evt.Applied,
+		}
+	case *event.SceneRegressed:
+		msg.Data = map[string]any{
+			"scene":       evt.SceneName,
+			"frames":      evt.Frames,
+			"regressions": evt.Regressions,
+			"points":      evt.Points,
+		}
+	case *event.OCRResultRecognized:
+		msg.Data = map[string]any{
+			"script":      evt.ScriptName,
+			"rule":        evt.RuleName,
+			"numerator":   evt.Numerator,
+			"denominator": evt.Denominator,
+			"threshold":   evt.Threshold,
+			"triggered":   evt.Triggered,
+		}
+	case *event.OCRTextRecognized:
+		msg.Data = map[string]any{
+			"script":  evt.ScriptName,
+			"rule":    evt.RuleName,
+			"lines":   evt.Lines,
+			"matched": evt.Matched,
+		}
+	case *event.GameDataCaptured:
+		msg.Data = map[string]any{"url": evt.URL, "values": evt.Values}
+	case *event.InputLagChanged:
+		msg.Data = map[string]any{
+			"lagging": evt.Lagging,
+			"meanMs":  evt.Mean.Milliseconds(),
+			"p95Ms":   evt.P95.Milliseconds(),
+		}
+	case *event.ScreenCaptured:
+		if evt.Image == nil {
+			return nil, false
+		}
+		shot, err := encodeScreenshot(evt.Image)
+		if err != nil {
+			return nil, false
+		}
+		msg.Data = shot
+	default:
+		return nil, false
+	}
+	return msg, true
+}
+
+func errorData(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+	return map[string]string{"error": err.Error()}
+}
+
+func encodeScreenshot(img image.Image) (*Screenshot, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: screenshotQuality}); err != nil {
+		return nil, err
+	}
+	return &Screenshot{
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+		Format: "jpeg",
+		Image:  base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
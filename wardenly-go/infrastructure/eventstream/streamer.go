@@ -0,0 +1,227 @@
+package eventstream
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gobwas/ws"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+	"wardenly-go/infrastructure/httpguard"
+)
+
+const (
+	// clientBuffer is the number of events queued per client. A client that
+	// falls further behind loses events instead of slowing the bus down.
+	clientBuffer = 256
+	writeTimeout = 10 * time.Second
+)
+
+// Streamer upgrades requests to WebSocket and forwards bus events to them.
+//
+// Query parameters:
+//   - session: only stream events of this session
+//   - screenshots=true: include throttled ScreenCaptured frames
+//   - token: the access token (browsers can't set headers on WebSocket)
+type Streamer struct {
+	bus                eventbus.EventBus
+	token              string
+	screenshotInterval time.Duration
+	logger             *slog.Logger
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	clients   sync.WaitGroup
+}
+
+// NewStreamer creates a streamer for the bus.
+func NewStreamer(cfg *Config, bus eventbus.EventBus) *Streamer {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.ScreenshotInterval <= 0 {
+		cfg.ScreenshotInterval = DefaultScreenshotInterval
+	}
+	return &Streamer{
+		bus:                bus,
+		token:              cfg.Token,
+		screenshotInterval: cfg.ScreenshotInterval,
+		logger:             cfg.Logger,
+		closed:             make(chan struct{}),
+	}
+}
+
+// Close disconnects all clients and waits for them to finish.
+func (s *Streamer) Close() {
+	s.closeOnce.Do(func() { close(s.closed) })
+	s.clients.Wait()
+}
+
+// ServeHTTP handles one subscriber for the lifetime of its connection.
+// Without a token, only pages served by this machine may connect: browsers
+// let any page open a WebSocket to a loopback address.
+func (s *Streamer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !httpguard.Authorized(r, s.token) {
+		http.Error(w, "missing or invalid token", http.StatusUnauthorized)
+		return
+	}
+	if s.token == "" && !httpguard.LocalOrigin(r) {
+		http.Error(w, "cross-origin connection refused", http.StatusForbidden)
+		return
+	}
+	select {
+	case <-s.closed:
+		http.Error(w, "event stream is stopping", http.StatusServiceUnavailable)
+		return
+	default:
+	}
+
+	query := r.URL.Query()
+	sessionID := query.Get("session")
+	screenshots := query.Get("screenshots") == "true"
+
+	conn, rw, _, err := ws.UpgradeHTTP(r, w)
+	if err != nil {
+		// UpgradeHTTP has already answered the request
+		s.logger.Debug("Event stream upgrade failed", "error", err)
+		return
+	}
+
+	s.clients.Add(1)
+	defer s.clients.Done()
+
+	c := &client{
+		conn:        conn,
+		queue:       eventbus.NewQueue(clientBuffer),
+		control:     make(chan ws.Frame, 1),
+		gone:        make(chan struct{}),
+		screenshots: screenshots,
+		interval:    s.screenshotInterval,
+		lastShot:    make(map[string]time.Time),
+	}
+
+	var subID string
+	if sessionID != "" {
+		subID = s.bus.SubscribeSession(sessionID, c.enqueue)
+	} else {
+		subID = s.bus.Subscribe(c.enqueue)
+	}
+
+	s.logger.Info("Event stream client connected", "remote", r.RemoteAddr, "session", sessionID, "screenshots", screenshots)
+
+	var src io.Reader = conn
+	if rw != nil {
+		src = rw.Reader // May hold bytes read past the handshake
+	}
+	go c.readLoop(src)
+	c.writeLoop(s.closed)
+
+	s.bus.Unsubscribe(subID)
+	conn.Close()
+	s.logger.Info("Event stream client disconnected", "remote", r.RemoteAddr, "dropped", c.queue.Dropped())
+}
+
+// client is one WebSocket subscriber. Only writeLoop writes to conn.
+type client struct {
+	conn    net.Conn
+	queue   *eventbus.Queue
+	control chan ws.Frame // Pong and close replies from readLoop
+	gone    chan struct{} // Closed when the peer disconnects
+
+	screenshots bool
+	interval    time.Duration
+	lastShot    map[string]time.Time // Only touched by the bus goroutine
+}
+
+// enqueue runs on the bus dispatch goroutine and must not block.
+// Encoding happens in writeLoop so screenshots don't stall the bus.
+func (c *client) enqueue(e event.Event) {
+	now := time.Now()
+	if shot, ok := e.(*event.ScreenCaptured); ok {
+		if !c.screenshots {
+			return
+		}
+		if now.Sub(c.lastShot[shot.SessionID()]) < c.interval {
+			return
+		}
+		c.lastShot[shot.SessionID()] = now
+	}
+
+	c.queue.Push(e)
+}
+
+// writeLoop sends events until the peer disconnects or the server stops.
+func (c *client) writeLoop(closed <-chan struct{}) {
+	for {
+		select {
+		case q := <-c.queue.Events():
+			msg, ok := NewMessage(q.Event, q.At)
+			if !ok {
+				continue
+			}
+			payload, err := json.Marshal(msg)
+			if err != nil {
+				continue
+			}
+			if c.write(ws.NewTextFrame(payload)) != nil {
+				return
+			}
+		case frame := <-c.control:
+			if c.write(frame) != nil || frame.Header.OpCode == ws.OpClose {
+				return
+			}
+		case <-c.gone:
+			// Send a pending close reply before hanging up
+			select {
+			case frame := <-c.control:
+				c.write(frame)
+			default:
+			}
+			return
+		case <-closed:
+			body := ws.NewCloseFrameBody(ws.StatusGoingAway, "server stopping")
+			c.write(ws.NewCloseFrame(body))
+			return
+		}
+	}
+}
+
+func (c *client) write(frame ws.Frame) error {
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return ws.WriteFrame(c.conn, frame)
+}
+
+// readLoop answers pings and close requests; the stream is one-way, so
+// data frames from the client are ignored.
+func (c *client) readLoop(src io.Reader) {
+	defer close(c.gone)
+	for {
+		frame, err := ws.ReadFrame(src)
+		if err != nil {
+			return
+		}
+		frame = ws.UnmaskFrameInPlace(frame)
+
+		switch frame.Header.OpCode {
+		case ws.OpPing:
+			c.reply(ws.NewPongFrame(frame.Payload))
+		case ws.OpClose:
+			c.reply(ws.NewCloseFrame(frame.Payload))
+			return
+		}
+	}
+}
+
+// reply hands a control frame to writeLoop, skipping it if one is pending.
+func (c *client) reply(frame ws.Frame) {
+	select {
+	case c.control <- frame:
+	default:
+	}
+}
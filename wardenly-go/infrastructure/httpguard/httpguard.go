@@ -0,0 +1,58 @@
+// Package httpguard holds the access checks shared by the optional HTTP
+// endpoints: they serve without a token only on loopback addresses.
+package httpguard
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// IsLoopback reports whether addr only accepts local connections.
+func IsLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// LocalOrigin reports whether r comes from a page served by this machine,
+// or from a client that isn't a browser and sends no Origin. Browsers send
+// Origin with cross-site POSTs and WebSocket handshakes, so this keeps web
+// pages the user visits from driving a tokenless loopback endpoint.
+func LocalOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false // Includes "null" from sandboxed frames and files
+	}
+	if u.Hostname() == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(u.Hostname())
+	return ip != nil && ip.IsLoopback()
+}
+
+// Authorized reports whether r carries token as "Authorization: Bearer
+// <token>" or in the token query parameter, which browsers use where they
+// can't set headers. An empty token authorizes every request.
+func Authorized(r *http.Request, token string) bool {
+	if token == "" {
+		return true
+	}
+	got := r.URL.Query().Get("token")
+	if auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		got = auth
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
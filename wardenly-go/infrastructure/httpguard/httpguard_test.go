@@ -0,0 +1,68 @@
+package httpguard
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsLoopback(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"localhost:8631": true,
+		"127.0.0.1:8631": true,
+		"[::1]:8631":     true,
+		":8631":          false,
+		"0.0.0.0:8631":   false,
+		"10.0.0.2:8631":  false,
+		"localhost":      false,
+	} {
+		if got := IsLoopback(addr); got != want {
+			t.Errorf("IsLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestAuthorized(t *testing.T) {
+	r := httptest.NewRequest("GET", "/events", nil)
+	if !Authorized(r, "") {
+		t.Error("empty token should authorize every request")
+	}
+	if Authorized(r, "secret") {
+		t.Error("request without a token authorized")
+	}
+
+	r = httptest.NewRequest("GET", "/events?token=secret", nil)
+	if !Authorized(r, "secret") {
+		t.Error("query token rejected")
+	}
+
+	r = httptest.NewRequest("GET", "/events?token=secret", nil)
+	r.Header.Set("Authorization", "Bearer wrong")
+	if Authorized(r, "secret") {
+		t.Error("wrong bearer token should win over the query")
+	}
+	r.Header.Set("Authorization", "Bearer secret")
+	if !Authorized(r, "secret") {
+		t.Error("bearer token rejected")
+	}
+}
+
+func TestLocalOrigin(t *testing.T) {
+	for origin, want := range map[string]bool{
+		"":                      true,
+		"http://localhost:8631": true,
+		"http://127.0.0.1:8631": true,
+		"http://[::1]:8631":     true,
+		"https://evil.example":  false,
+		"http://192.168.1.2:80": false,
+		"null":                  false,
+		"file://":               false,
+	} {
+		r := httptest.NewRequest("POST", "/api/v1/sessions", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if got := LocalOrigin(r); got != want {
+			t.Errorf("LocalOrigin(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
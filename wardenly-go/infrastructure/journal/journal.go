@@ -0,0 +1,244 @@
+// Package journal persists session events as JSON lines, one file per day,
+// so that a past session can be reconstructed after an incident. Entries use
+// the same format as the WebSocket event stream; screenshots are not kept.
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+	"wardenly-go/infrastructure/appdir"
+	"wardenly-go/infrastructure/eventstream"
+)
+
+// Environment variables overriding the journal section of the config file.
+const (
+	EnvDisabled  = "WARDENLY_JOURNAL_DISABLED"
+	EnvDir       = "WARDENLY_JOURNAL_DIR"
+	EnvMaxDays   = "WARDENLY_JOURNAL_MAX_DAYS"
+	EnvMaxSizeMB = "WARDENLY_JOURNAL_MAX_SIZE_MB"
+)
+
+const (
+	// queueSize is the number of events buffered for the writer. Events
+	// beyond it are dropped rather than slowing the bus down.
+	queueSize = 1024
+	// dayLayout names the daily files: events-2006-01-02.jsonl.
+	dayLayout  = "2006-01-02"
+	filePrefix = "events-"
+	fileSuffix = ".jsonl"
+)
+
+// Config holds journal configuration.
+type Config struct {
+	// Disabled turns the journal off. It is on by default.
+	Disabled bool
+	// Dir is where daily files are written.
+	// If empty, defaults to DefaultDir().
+	Dir string
+	// MaxDays is the number of daily files retained.
+	MaxDays int
+	// MaxSizeMB caps the total size of the journal; the oldest days are
+	// removed first. Today's file is never removed.
+	MaxSizeMB int
+	Logger    *slog.Logger
+}
+
+// DefaultDir returns the default journal directory.
+func DefaultDir() string {
+	return appdir.Path("journal")
+}
+
+// Enabled reports whether the journal should be started.
+func (c *Config) Enabled() bool {
+	return c != nil && !c.Disabled
+}
+
+// Journal writes bus events to the current day's file until stopped.
+type Journal struct {
+	config *Config
+	bus    eventbus.EventBus
+	logger *slog.Logger
+	subID  string
+
+	queue *eventbus.Queue
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	// Only touched by the writer goroutine
+	file *os.File
+	day  string
+	size int64 // Total size of the journal directory
+}
+
+// Start prunes old files and begins journaling events from bus.
+// Stop must be called to flush pending events and close the file.
+func Start(cfg *Config, bus eventbus.EventBus) (*Journal, error) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = DefaultDir()
+	}
+	if cfg.MaxDays <= 0 {
+		cfg.MaxDays = 14
+	}
+	if cfg.MaxSizeMB <= 0 {
+		cfg.MaxSizeMB = 200
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal dir: %w", err)
+	}
+
+	j := &Journal{
+		config: cfg,
+		bus:    bus,
+		logger: cfg.Logger,
+		queue:  eventbus.NewQueue(queueSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if err := j.prune(""); err != nil {
+		j.logger.Warn("Failed to prune event journal", "error", err)
+	}
+
+	j.subID = bus.Subscribe(j.enqueue)
+	go j.run()
+
+	j.logger.Info("Event journal started", "dir", cfg.Dir, "maxDays", cfg.MaxDays, "maxSizeMB", cfg.MaxSizeMB)
+	return j, nil
+}
+
+// Stop unsubscribes, writes pending events and closes the file.
+func (j *Journal) Stop() {
+	j.once.Do(func() {
+		j.bus.Unsubscribe(j.subID)
+		close(j.stop)
+		<-j.done
+		if n := j.queue.Dropped(); n > 0 {
+			j.logger.Warn("Event journal dropped events", "count", n)
+		}
+	})
+}
+
+// enqueue runs on the bus dispatch goroutine and must not block.
+func (j *Journal) enqueue(e event.Event) {
+	if _, ok := e.(*event.ScreenCaptured); ok {
+		return
+	}
+	j.queue.Push(e)
+}
+
+func (j *Journal) run() {
+	defer close(j.done)
+	defer j.closeFile()
+
+	for {
+		select {
+		case q := <-j.queue.Events():
+			j.write(q)
+		case <-j.stop:
+			j.queue.Drain(j.write)
+			return
+		}
+	}
+}
+
+func (j *Journal) write(q eventbus.Queued) {
+	msg, ok := eventstream.NewMessage(q.Event, q.At)
+	if !ok {
+		return
+	}
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	if err := j.rotate(q.At); err != nil {
+		j.logger.Warn("Failed to open event journal", "error", err)
+		return
+	}
+	if _, err := j.file.Write(line); err != nil {
+		j.logger.Warn("Failed to write event journal", "error", err)
+		return
+	}
+	j.size += int64(len(line))
+	if j.size > j.maxSize() {
+		if err := j.prune(j.day); err != nil {
+			j.logger.Warn("Failed to prune event journal", "error", err)
+		}
+	}
+}
+
+// rotate opens the file for the day of at, pruning when the day changes.
+func (j *Journal) rotate(at time.Time) error {
+	day := at.Format(dayLayout)
+	if j.file != nil && day == j.day {
+		return nil
+	}
+	j.closeFile()
+
+	f, err := os.OpenFile(filepath.Join(j.config.Dir, FileName(day)), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	j.file = f
+	j.day = day
+	return j.prune(day)
+}
+
+func (j *Journal) closeFile() {
+	if j.file != nil {
+		j.file.Close()
+		j.file = nil
+	}
+}
+
+func (j *Journal) maxSize() int64 {
+	return int64(j.config.MaxSizeMB) << 20
+}
+
+// prune removes the oldest daily files beyond MaxDays, then until the
+// journal fits MaxSizeMB. The file for keep is never removed.
+func (j *Journal) prune(keep string) error {
+	days, err := Days(j.config.Dir)
+	if err != nil {
+		return err
+	}
+	sort.Strings(days) // Oldest first
+
+	sizes := make(map[string]int64, len(days))
+	var total int64
+	for _, day := range days {
+		if info, err := os.Stat(filepath.Join(j.config.Dir, FileName(day))); err == nil {
+			sizes[day] = info.Size()
+			total += info.Size()
+		}
+	}
+
+	for i, day := range days {
+		if day == keep {
+			continue
+		}
+		if len(days)-i <= j.config.MaxDays && total <= j.maxSize() {
+			break
+		}
+		if err := os.Remove(filepath.Join(j.config.Dir, FileName(day))); err != nil {
+			return err
+		}
+		total -= sizes[day]
+	}
+	j.size = total
+	return nil
+}
@@ -0,0 +1,129 @@
+package journal
+
+import (
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+)
+
+func TestJournal_WritesEvents(t *testing.T) {
+	dir := t.TempDir()
+	bus := eventbus.New(10)
+	defer bus.Close()
+
+	j, err := Start(&Config{Dir: dir}, bus)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	bus.Publish(event.NewSessionStarted("s1", "a1", "alice"))
+	bus.Publish(event.NewScreenCaptured("s1", image.NewRGBA(image.Rect(0, 0, 2, 2))))
+	bus.Publish(event.NewScriptStepExecuted("s1", "daily", 1, "main_city", []string{"click"}, "continue", ""))
+	bus.Publish(event.NewScriptStopped("s1", "daily", event.StopReasonError, errors.New("boom")))
+
+	day := time.Now().Format(dayLayout)
+	var entries []Entry
+	deadline := time.Now().Add(2 * time.Second)
+	for len(entries) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		entries, _ = ReadDay(dir, day)
+	}
+	j.Stop()
+
+	var types []string
+	for _, e := range entries {
+		types = append(types, e.Type)
+	}
+	// Screenshots and events the stream doesn't carry are not journaled
+	if want := []string{"SessionStarted", "ScriptStopped"}; !slices.Equal(types, want) {
+		t.Fatalf("journaled types = %v, want %v", types, want)
+	}
+	if got := entries[1].Details(); got != "error=boom reason=Error script=daily" {
+		t.Errorf("Details() = %q", got)
+	}
+}
+
+func writeDay(t *testing.T, dir, day string, size int) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, FileName(day)), make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJournal_Prune(t *testing.T) {
+	dir := t.TempDir()
+	for _, day := range []string{"2026-01-01", "2026-01-02", "2026-01-03", "2026-01-04"} {
+		writeDay(t, dir, day, 400<<10)
+	}
+
+	j := &Journal{config: &Config{Dir: dir, MaxDays: 3, MaxSizeMB: 1}}
+	if err := j.prune("2026-01-04"); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+
+	// MaxDays drops the first day; 3 x 400KB still exceeds 1MB, so the next oldest goes too
+	days, _ := Days(dir)
+	if want := []string{"2026-01-04", "2026-01-03"}; !slices.Equal(days, want) {
+		t.Errorf("days = %v, want %v", days, want)
+	}
+	if j.size != 800<<10 {
+		t.Errorf("size = %d, want %d", j.size, 800<<10)
+	}
+
+	// The kept day survives even when it alone exceeds the limit
+	writeDay(t, dir, "2026-01-05", 2<<20)
+	if err := j.prune("2026-01-05"); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if days, _ := Days(dir); !slices.Equal(days, []string{"2026-01-05"}) {
+		t.Errorf("days = %v, want only the kept day", days)
+	}
+}
+
+func TestReadDay(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"type":"SessionStarted","sessionId":"s1","time":"2026-01-02T08:00:00Z","data":{"account":"alice"}}
+{"type":"ScriptsReloaded","time":"2026-01-02T08:00:01Z","data":{"scripts":["daily"]}}
+{"type":"ScriptStarted","sessionId":"s2","correlationId":"c1","time":"2026-01-02T08:00:02Z","data":{"script":"tower"}}
+{"type":"ScriptStarted","sessionId":"s1","correlationId":"c1","time":"2026-01-02T08:00:03Z","data":{"script":"daily"}}
+{"type":"ScriptStop`
+	if err := os.WriteFile(filepath.Join(dir, FileName("2026-01-02")), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeDay(t, dir, "2026-01-01", 0)
+	writeDay(t, dir, "not-a-day", 0)
+
+	days, err := Days(dir)
+	if err != nil || !slices.Equal(days, []string{"2026-01-02", "2026-01-01"}) {
+		t.Errorf("Days() = %v, %v", days, err)
+	}
+
+	entries, err := ReadDay(dir, "2026-01-02")
+	if err != nil {
+		t.Fatalf("ReadDay: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("len(entries) = %d, want 4 (truncated line skipped)", len(entries))
+	}
+	if got := Sessions(entries); !slices.Equal(got, []string{"s1", "s2"}) {
+		t.Errorf("Sessions() = %v", got)
+	}
+	s1 := ForSession(entries, "s1")
+	if len(s1) != 2 || s1[1].Details() != "script=daily" {
+		t.Errorf("ForSession(s1) = %+v", s1)
+	}
+	if trace := ForCorrelation(entries, "c1"); len(trace) != 2 || trace[0].SessionID != "s2" {
+		t.Errorf("ForCorrelation(c1) = %+v", trace)
+	}
+
+	if days, err := Days(filepath.Join(dir, "missing")); err != nil || len(days) != 0 {
+		t.Errorf("Days(missing) = %v, %v; want empty", days, err)
+	}
+}
@@ -0,0 +1,137 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxLineSize bounds a single journal line when reading.
+const maxLineSize = 1 << 20
+
+// Entry is one journaled event.
+type Entry struct {
+	Type          string          `json:"type"`
+	SessionID     string          `json:"sessionId,omitempty"`
+	CorrelationID string          `json:"correlationId,omitempty"`
+	Time          time.Time       `json:"time"`
+	Data          json.RawMessage `json:"data,omitempty"`
+}
+
+// Details formats the entry's data as "key=value" pairs sorted by key.
+func (e *Entry) Details() string {
+	var data map[string]any
+	if len(e.Data) == 0 || json.Unmarshal(e.Data, &data) != nil {
+		return ""
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, data[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// FileName returns the file name of a day ("2006-01-02") in the journal.
+func FileName(day string) string {
+	return filePrefix + day + fileSuffix
+}
+
+// Days lists the days present in the journal directory, newest first.
+func Days(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read journal dir: %w", err)
+	}
+
+	var days []string
+	for _, e := range entries {
+		day, ok := strings.CutPrefix(e.Name(), filePrefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		day, ok = strings.CutSuffix(day, fileSuffix)
+		if !ok {
+			continue
+		}
+		if _, err := time.Parse(dayLayout, day); err != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(days)))
+	return days, nil
+}
+
+// ReadDay returns the entries journaled on day, in order. Lines that can't
+// be parsed (such as one cut short by a crash) are skipped.
+func ReadDay(dir, day string) ([]Entry, error) {
+	f, err := os.Open(filepath.Join(dir, FileName(day)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// Sessions returns the session IDs that appear in entries, in order of
+// first appearance.
+func Sessions(entries []Entry) []string {
+	var ids []string
+	for _, e := range entries {
+		if e.SessionID != "" && !slices.Contains(ids, e.SessionID) {
+			ids = append(ids, e.SessionID)
+		}
+	}
+	return ids
+}
+
+// ForCorrelation returns the entries caused by one command, across sessions.
+func ForCorrelation(entries []Entry, correlationID string) []Entry {
+	var out []Entry
+	for _, e := range entries {
+		if e.CorrelationID == correlationID {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// ForSession returns the entries of one session.
+func ForSession(entries []Entry, sessionID string) []Entry {
+	var out []Entry
+	for _, e := range entries {
+		if e.SessionID == sessionID {
+			out = append(out, e)
+		}
+	}
+	return out
+}
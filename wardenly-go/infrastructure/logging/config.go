@@ -5,8 +5,8 @@ package logging
 import (
 	"context"
 	"log/slog"
-	"os"
-	"path/filepath"
+
+	"wardenly-go/infrastructure/appdir"
 )
 
 // Config holds logging configuration options.
@@ -14,10 +14,12 @@ type Config struct {
 	// Level is the minimum log level to emit.
 	Level slog.Level
 	// Dir is the directory for log files (prod only).
-	// If empty, defaults to os.UserConfigDir()/wardenly/logs.
+	// If empty, defaults to DefaultLogDir().
 	Dir string
 	// MaxSizeMB is the maximum size in megabytes of a single log file before rotation.
 	MaxSizeMB int
+	// SessionMaxSizeMB is the same limit for the log file of each session.
+	SessionMaxSizeMB int
 	// MaxBackups is the maximum number of old log files to retain.
 	MaxBackups int
 	// MaxAgeDays is the maximum number of days to retain old log files.
@@ -31,27 +33,20 @@ type Config struct {
 // DefaultConfig returns sensible defaults for production logging.
 func DefaultConfig() *Config {
 	return &Config{
-		Level:      slog.LevelInfo,
-		Dir:        "", // will be resolved in Setup
-		MaxSizeMB:  50,
-		MaxBackups: 10,
-		MaxAgeDays: 14,
-		Compress:   true,
-		AddSource:  false,
+		Level:            slog.LevelInfo,
+		Dir:              "", // will be resolved in Setup
+		MaxSizeMB:        50,
+		SessionMaxSizeMB: 10,
+		MaxBackups:       10,
+		MaxAgeDays:       14,
+		Compress:         true,
+		AddSource:        false,
 	}
 }
 
 // DefaultLogDir returns the default log directory path.
-// Tries os.UserConfigDir, falls back to os.UserCacheDir, then os.TempDir.
 func DefaultLogDir() string {
-	dir, err := os.UserConfigDir()
-	if err != nil {
-		dir, err = os.UserCacheDir()
-		if err != nil {
-			dir = os.TempDir()
-		}
-	}
-	return filepath.Join(dir, "wardenly", "logs")
+	return appdir.Path("logs")
 }
 
 // --- Global logger access ---
@@ -72,6 +67,14 @@ func setGlobal(logger *slog.Logger) {
 	slog.SetDefault(logger)
 }
 
+var globalSessions *SessionLogs
+
+// Sessions returns the session logs of the logger created by Setup, or nil
+// if Setup has not been called. Its methods accept a nil receiver.
+func Sessions() *SessionLogs {
+	return globalSessions
+}
+
 // --- Context-based logging ---
 
 type ctxKey struct{}
@@ -0,0 +1,223 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Attribute keys that mark the loggers of a session. The coordinator names
+// the account and the session adds its ID.
+const (
+	sessionKey = "session_id"
+	accountKey = "account"
+)
+
+// SessionLine is a log record of a session, formatted for display.
+type SessionLine struct {
+	SessionID string
+	Time      time.Time
+	Level     slog.Level
+	// Text is the message followed by the record's key=value attributes,
+	// without the session and account
+	Text string
+}
+
+// SessionLogs copies the records of session loggers, those with a
+// session_id attribute, to a log file per account and to a sink, besides
+// the application log.
+type SessionLogs struct {
+	// open creates the writer of a session's file; nil writes no files
+	open func(name string) io.WriteCloser
+
+	mu    sync.Mutex
+	files map[string]io.WriteCloser // By session ID
+	sink  func(SessionLine)
+}
+
+// newSessionLogs creates session logs writing files opened by open, which
+// may be nil.
+func newSessionLogs(open func(name string) io.WriteCloser) *SessionLogs {
+	return &SessionLogs{open: open, files: make(map[string]io.WriteCloser)}
+}
+
+// SetSink sets the function every session line is passed to, such as one
+// publishing it for the UI; nil removes it. It is called on the logging
+// goroutine, so it must not block or log to a session logger.
+func (l *SessionLogs) SetSink(sink func(SessionLine)) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.sink = sink
+	l.mu.Unlock()
+}
+
+// Close closes the session files.
+func (l *SessionLogs) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var firstErr error
+	for id, f := range l.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(l.files, id)
+	}
+	return firstErr
+}
+
+// Handler wraps next so that session records are also written to the
+// session logs.
+func (l *SessionLogs) Handler(next slog.Handler) slog.Handler {
+	return &sessionHandler{next: next, logs: l}
+}
+
+// write writes a line to the session's file, opened on first use and named
+// after the account (the session ID if the first record lacks one), and
+// passes it to the sink.
+func (l *SessionLogs) write(line SessionLine, account string) {
+	l.mu.Lock()
+	if l.open != nil {
+		f, ok := l.files[line.SessionID]
+		if !ok {
+			name := account
+			if name == "" {
+				name = line.SessionID
+			}
+			f = l.open(fileName(name))
+			l.files[line.SessionID] = f
+		}
+		fmt.Fprintf(f, "%s %s %s\n", line.Time.Format("2006-01-02 15:04:05.000"), line.Level, line.Text)
+	}
+	sink := l.sink
+	l.mu.Unlock()
+
+	if sink != nil {
+		sink(line)
+	}
+}
+
+// fileName turns an account name such as "101 - Aster" into a file name,
+// keeping letters, digits, dashes and dots. It never collides with the
+// application log.
+func fileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "wardenly" {
+		name += "_"
+	}
+	return name + ".log"
+}
+
+// sessionHandler passes records to the next handler and, for session
+// loggers, to the session logs. Groups are flattened into dotted keys.
+type sessionHandler struct {
+	next      slog.Handler
+	logs      *SessionLogs
+	attrs     []slog.Attr
+	group     string // Prefix of the open groups, e.g. "browser."
+	sessionID string
+	account   string
+}
+
+func (h *sessionHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *sessionHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := h.next.Handle(ctx, r)
+
+	sessionID, account := h.sessionID, h.account
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		if h.group == "" && a.Key == sessionKey && sessionID == "" {
+			sessionID = a.Value.String()
+		} else if h.group == "" && a.Key == accountKey && account == "" {
+			account = a.Value.String()
+		}
+		a.Key = h.group + a.Key
+		attrs = append(attrs, a)
+		return true
+	})
+	if sessionID == "" {
+		return err
+	}
+
+	h.logs.write(SessionLine{
+		SessionID: sessionID,
+		Time:      r.Time,
+		Level:     r.Level,
+		Text:      formatLine(r.Message, append(slicesClone(h.attrs), attrs...)),
+	}, account)
+	return err
+}
+
+func (h *sessionHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.next = h.next.WithAttrs(attrs)
+	c.attrs = slicesClone(h.attrs)
+	for _, a := range attrs {
+		if h.group == "" && a.Key == sessionKey {
+			c.sessionID = a.Value.String()
+		} else if h.group == "" && a.Key == accountKey {
+			c.account = a.Value.String()
+		}
+		a.Key = h.group + a.Key
+		c.attrs = append(c.attrs, a)
+	}
+	return &c
+}
+
+func (h *sessionHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.next = h.next.WithGroup(name)
+	c.group = h.group + name + "."
+	return &c
+}
+
+// formatLine writes msg and the attributes other than the session and
+// account as key=value pairs, quoted like slog's text handler.
+func formatLine(msg string, attrs []slog.Attr) string {
+	var buf bytes.Buffer
+	text := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return a
+			}
+			switch a.Key {
+			case slog.TimeKey, slog.LevelKey, slog.MessageKey, sessionKey, accountKey:
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "", 0)
+	r.AddAttrs(attrs...)
+	_ = text.Handle(context.Background(), r)
+
+	if rest := strings.TrimSpace(buf.String()); rest != "" {
+		return msg + " " + rest
+	}
+	return msg
+}
+
+func slicesClone(attrs []slog.Attr) []slog.Attr {
+	return append([]slog.Attr(nil), attrs...)
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type bufferFile struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (f *bufferFile) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestSessionLogs(t *testing.T) {
+	files := make(map[string]*bufferFile)
+	logs := newSessionLogs(func(name string) io.WriteCloser {
+		f := &bufferFile{}
+		files[name] = f
+		return f
+	})
+	var lines []SessionLine
+	logs.SetSink(func(line SessionLine) { lines = append(lines, line) })
+
+	var app bytes.Buffer
+	logger := slog.New(logs.Handler(slog.NewTextHandler(&app, nil)))
+
+	logger.Info("Starting")
+	session := logger.With("account", "101 - Aster").With("session_id", "s1")
+	session.Info("Script started", "script", "farm")
+	session.WithGroup("browser").Warn("Slow frame", "ms", 350)
+	logger.Info("Ad hoc", "session_id", "s2")
+
+	if !strings.Contains(app.String(), "msg=Starting") || !strings.Contains(app.String(), "session_id=s1") {
+		t.Errorf("application log lacks records:\n%s", app.String())
+	}
+
+	if len(lines) != 3 {
+		t.Fatalf("got %d session lines, want 3: %+v", len(lines), lines)
+	}
+	for i, want := range []struct{ id, text string }{
+		{"s1", "Script started script=farm"},
+		{"s1", "Slow frame browser.ms=350"},
+		{"s2", "Ad hoc"},
+	} {
+		if lines[i].SessionID != want.id || lines[i].Text != want.text {
+			t.Errorf("line %d = %s %q, want %s %q", i, lines[i].SessionID, lines[i].Text, want.id, want.text)
+		}
+	}
+	if lines[1].Level != slog.LevelWarn {
+		t.Errorf("line 1 level = %v, want WARN", lines[1].Level)
+	}
+
+	if len(files) != 2 || files["101_-_Aster.log"] == nil || files["s2.log"] == nil {
+		t.Fatalf("files = %v", files)
+	}
+	if got := files["101_-_Aster.log"].String(); strings.Count(got, "\n") != 2 || !strings.Contains(got, " WARN Slow frame") {
+		t.Errorf("session file:\n%s", got)
+	}
+
+	if err := logs.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !files["s2.log"].closed {
+		t.Error("Close() left a session file open")
+	}
+}
+
+func TestFileName(t *testing.T) {
+	for name, want := range map[string]string{
+		"101 - Aster": "101_-_Aster.log",
+		"../etc":      ".._etc.log",
+		"wardenly":    "wardenly_.log",
+	} {
+		if got := fileName(name); got != want {
+			t.Errorf("fileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -8,7 +8,8 @@ import (
 )
 
 // Setup initializes logging for development mode.
-// Logs are written to os.Stdout only; no file output.
+// Logs are written to os.Stdout only; no file output. Session log lines
+// are still passed to the sink of Sessions.
 // Returns the configured logger, a no-op close function, and any error.
 func Setup(cfg *Config) (*slog.Logger, func() error, error) {
 	if cfg == nil {
@@ -20,7 +21,10 @@ func Setup(cfg *Config) (*slog.Logger, func() error, error) {
 		AddSource: cfg.AddSource,
 	})
 
-	logger := slog.New(handler)
+	sessions := newSessionLogs(nil)
+	globalSessions = sessions
+
+	logger := slog.New(sessions.Handler(handler))
 	setGlobal(logger)
 
 	// No resources to close in dev mode
@@ -3,6 +3,8 @@
 package logging
 
 import (
+	"errors"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -12,6 +14,8 @@ import (
 
 // Setup initializes logging for production mode.
 // Logs are written to rotating files via lumberjack; no console output.
+// Session loggers also write to a rotating file per account in the same
+// directory.
 // Returns the configured logger, a close function to flush/close the log file, and any error.
 func Setup(cfg *Config) (*slog.Logger, func() error, error) {
 	if cfg == nil {
@@ -44,11 +48,23 @@ func Setup(cfg *Config) (*slog.Logger, func() error, error) {
 		AddSource: cfg.AddSource,
 	})
 
-	logger := slog.New(handler)
+	sessions := newSessionLogs(func(name string) io.WriteCloser {
+		return &lumberjack.Logger{
+			Filename:   filepath.Join(dir, name),
+			MaxSize:    cfg.SessionMaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+			LocalTime:  true,
+		}
+	})
+	globalSessions = sessions
+
+	logger := slog.New(sessions.Handler(handler))
 	setGlobal(logger)
 
 	closeFn := func() error {
-		return lj.Close()
+		return errors.Join(sessions.Close(), lj.Close())
 	}
 
 	return logger, closeFn, nil
@@ -0,0 +1,234 @@
+// Package metrics exports counters and gauges of sessions, scripts, OCR and
+// streaming in the Prometheus text format, so long-running farms can be
+// graphed, e.g. in Grafana. Most values are taken from the event bus; OCR
+// latency and scene matching are reported by the components that measure
+// them.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/state"
+)
+
+// Environment variables overriding the metrics section of the config file.
+const (
+	EnvAddr  = "WARDENLY_METRICS_ADDR"
+	EnvToken = "WARDENLY_METRICS_TOKEN"
+)
+
+// fpsWindow is the span screencast frame rates are averaged over.
+const fpsWindow = 10 * time.Second
+
+// ocrBuckets are the upper bounds of the OCR latency histogram, in seconds.
+var ocrBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Collector keeps the current metric values. It is safe for concurrent use.
+type Collector struct {
+	now func() time.Time
+
+	mu          sync.Mutex
+	sessions    map[string]state.SessionState
+	scripts     map[string]bool // Sessions running a script
+	steps       map[stepKey]uint64
+	scriptStops map[string]uint64 // By stop reason
+	sceneChecks [2]uint64         // Unmatched, matched
+	ocrCounts   []uint64          // Per bucket, not cumulative; the last is +Inf
+	ocrSum      float64
+	ocrFailed   uint64
+	frames      uint64
+	frameTimes  map[string][]time.Time // Recent frames per session
+}
+
+type stepKey struct {
+	script string
+	result string
+}
+
+// NewCollector creates a collector with no values yet.
+func NewCollector() *Collector {
+	return &Collector{
+		now:         time.Now,
+		sessions:    make(map[string]state.SessionState),
+		scripts:     make(map[string]bool),
+		steps:       make(map[stepKey]uint64),
+		scriptStops: make(map[string]uint64),
+		ocrCounts:   make([]uint64, len(ocrBuckets)+1),
+		frameTimes:  make(map[string][]time.Time),
+	}
+}
+
+// HandleEvent updates the values an event changes. It is subscribed to the
+// event bus by Start.
+func (c *Collector) HandleEvent(e event.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch e := e.(type) {
+	case *event.SessionStarted:
+		if _, ok := c.sessions[e.SessionID()]; !ok {
+			c.sessions[e.SessionID()] = state.StateIdle
+		}
+	case *event.SessionStateChanged:
+		c.sessions[e.SessionID()] = e.NewState
+	case *event.SessionStopped:
+		delete(c.sessions, e.SessionID())
+		delete(c.scripts, e.SessionID())
+		delete(c.frameTimes, e.SessionID())
+	case *event.ScriptStarted:
+		c.scripts[e.SessionID()] = true
+	case *event.ScriptStopped:
+		delete(c.scripts, e.SessionID())
+		c.scriptStops[e.Reason.String()]++
+	case *event.ScriptStepExecuted:
+		c.steps[stepKey{script: e.ScriptName, result: e.Result}]++
+	case *event.ScreenCaptured:
+		c.frames++
+		now := c.now()
+		c.frameTimes[e.SessionID()] = append(recentFrames(c.frameTimes[e.SessionID()], now), now)
+	}
+}
+
+// ObserveOCR records the duration of an OCR request and whether it failed.
+func (c *Collector) ObserveOCR(elapsed time.Duration, err error) {
+	seconds := elapsed.Seconds()
+	bucket, _ := slices.BinarySearch(ocrBuckets, seconds)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ocrCounts[bucket]++
+	c.ocrSum += seconds
+	if err != nil {
+		c.ocrFailed++
+	}
+}
+
+// ObserveSceneMatch records whether a frame checked by a script matched the
+// scene of one of its steps.
+func (c *Collector) ObserveSceneMatch(matched bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if matched {
+		c.sceneChecks[1]++
+	} else {
+		c.sceneChecks[0]++
+	}
+}
+
+// recentFrames drops the frame times older than fpsWindow.
+func recentFrames(times []time.Time, now time.Time) []time.Time {
+	i := 0
+	for i < len(times) && now.Sub(times[i]) > fpsWindow {
+		i++
+	}
+	return times[i:]
+}
+
+// WriteTo writes all metrics in the Prometheus text format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	c.mu.Lock()
+	c.write(&b)
+	c.mu.Unlock()
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func (c *Collector) write(b *strings.Builder) {
+	byState := make(map[string]int)
+	for _, st := range c.sessions {
+		byState[st.String()]++
+	}
+	header(b, "wardenly_sessions_active", "gauge", "Sessions that have been started and not stopped.")
+	fmt.Fprintf(b, "wardenly_sessions_active %d\n", len(c.sessions))
+	header(b, "wardenly_sessions", "gauge", "Sessions by state.")
+	for _, name := range sortedKeys(byState) {
+		fmt.Fprintf(b, "wardenly_sessions{state=%q} %d\n", name, byState[name])
+	}
+
+	header(b, "wardenly_scripts_running", "gauge", "Sessions running a script.")
+	fmt.Fprintf(b, "wardenly_scripts_running %d\n", len(c.scripts))
+	header(b, "wardenly_scripts_stopped_total", "counter", "Script runs that ended, by reason.")
+	for _, reason := range sortedKeys(c.scriptStops) {
+		fmt.Fprintf(b, "wardenly_scripts_stopped_total{reason=%q} %d\n", reason, c.scriptStops[reason])
+	}
+
+	header(b, "wardenly_script_steps_total", "counter", "Script steps executed, by script and result.")
+	keys := make([]stepKey, 0, len(c.steps))
+	for k := range c.steps {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b stepKey) int {
+		if a.script != b.script {
+			return strings.Compare(a.script, b.script)
+		}
+		return strings.Compare(a.result, b.result)
+	})
+	for _, k := range keys {
+		fmt.Fprintf(b, "wardenly_script_steps_total{script=%q,result=%q} %d\n", k.script, k.result, c.steps[k])
+	}
+
+	header(b, "wardenly_scene_checks_total", "counter", "Frames checked by scripts, by whether a step's scene matched.")
+	fmt.Fprintf(b, "wardenly_scene_checks_total{result=\"matched\"} %d\n", c.sceneChecks[1])
+	fmt.Fprintf(b, "wardenly_scene_checks_total{result=\"unmatched\"} %d\n", c.sceneChecks[0])
+
+	header(b, "wardenly_ocr_request_duration_seconds", "histogram", "Duration of OCR requests, including the wait for a free slot.")
+	var cumulative uint64
+	for i, bound := range ocrBuckets {
+		cumulative += c.ocrCounts[i]
+		fmt.Fprintf(b, "wardenly_ocr_request_duration_seconds_bucket{le=\"%g\"} %d\n", bound, cumulative)
+	}
+	cumulative += c.ocrCounts[len(ocrBuckets)]
+	fmt.Fprintf(b, "wardenly_ocr_request_duration_seconds_bucket{le=\"+Inf\"} %d\n", cumulative)
+	fmt.Fprintf(b, "wardenly_ocr_request_duration_seconds_sum %g\n", c.ocrSum)
+	fmt.Fprintf(b, "wardenly_ocr_request_duration_seconds_count %d\n", cumulative)
+	header(b, "wardenly_ocr_requests_failed_total", "counter", "OCR requests that returned an error.")
+	fmt.Fprintf(b, "wardenly_ocr_requests_failed_total %d\n", c.ocrFailed)
+
+	header(b, "wardenly_screencast_frames_total", "counter", "Frames received from all sessions.")
+	fmt.Fprintf(b, "wardenly_screencast_frames_total %d\n", c.frames)
+	header(b, "wardenly_screencast_fps", "gauge", fmt.Sprintf("Frames per second of each session over the last %s.", fpsWindow))
+	now := c.now()
+	for _, id := range sortedKeys(c.frameTimes) {
+		times := recentFrames(c.frameTimes[id], now)
+		c.frameTimes[id] = times
+		fmt.Fprintf(b, "wardenly_screencast_fps{session=%q} %g\n", id, float64(len(times))/fpsWindow.Seconds())
+	}
+}
+
+func header(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Config holds metrics endpoint configuration.
+type Config struct {
+	// Addr is the listen address (e.g. "localhost:9632"). Empty disables
+	// the endpoint.
+	Addr string
+	// Token is the bearer token scrapers must send. It may be empty only
+	// when Addr is a loopback address.
+	Token  string
+	Logger *slog.Logger
+}
+
+// Enabled reports whether the metrics endpoint should be started.
+func (c *Config) Enabled() bool {
+	return c != nil && c.Addr != ""
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/state"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	for _, e := range []event.Event{
+		event.NewSessionStarted("s1", "a1", "alice"),
+		event.NewSessionStarted("s2", "a2", "bob"),
+		event.NewSessionStarted("s3", "a3", "carol"),
+		event.NewSessionStateChanged("s1", state.StateIdle, state.StateReady),
+		event.NewSessionStateChanged("s2", state.StateIdle, state.StateScriptRunning),
+		event.NewSessionStopped("s3", nil),
+		event.NewScriptStarted("s2", "farm"),
+		event.NewScriptStarted("s1", "farm"),
+		event.NewScriptStopped("s1", "farm", event.StopReasonManual, nil),
+		event.NewScriptStepExecuted("s2", "farm", 0, "tower", nil, "continue", ""),
+		event.NewScriptStepExecuted("s2", "farm", 1, "tower", nil, "continue", ""),
+		event.NewScriptStepExecuted("s2", "farm", 1, "tower", nil, "quit", ""),
+	} {
+		c.HandleEvent(e)
+	}
+	for i := 0; i < 20; i++ {
+		c.HandleEvent(event.NewScreenCaptured("s2", nil))
+	}
+	c.ObserveSceneMatch(true)
+	c.ObserveSceneMatch(false)
+	c.ObserveSceneMatch(true)
+	c.ObserveOCR(80*time.Millisecond, nil)
+	c.ObserveOCR(3*time.Second, errors.New("timeout"))
+	c.ObserveOCR(time.Minute, nil)
+
+	var b strings.Builder
+	if _, err := c.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# TYPE wardenly_sessions_active gauge\nwardenly_sessions_active 2\n",
+		`wardenly_sessions{state="Ready"} 1`,
+		"wardenly_scripts_running 1\n",
+		`wardenly_scripts_stopped_total{reason="Manual"} 1`,
+		`wardenly_script_steps_total{script="farm",result="continue"} 2`,
+		`wardenly_script_steps_total{script="farm",result="quit"} 1`,
+		`wardenly_scene_checks_total{result="matched"} 2`,
+		`wardenly_scene_checks_total{result="unmatched"} 1`,
+		`wardenly_ocr_request_duration_seconds_bucket{le="0.05"} 0`,
+		`wardenly_ocr_request_duration_seconds_bucket{le="0.1"} 1`,
+		`wardenly_ocr_request_duration_seconds_bucket{le="5"} 2`,
+		`wardenly_ocr_request_duration_seconds_bucket{le="+Inf"} 3`,
+		"wardenly_ocr_request_duration_seconds_count 3\n",
+		"wardenly_ocr_requests_failed_total 1\n",
+		"wardenly_screencast_frames_total 20\n",
+		`wardenly_screencast_fps{session="s2"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	// Frames older than the window no longer count
+	now = now.Add(2 * fpsWindow)
+	b.Reset()
+	c.WriteTo(&b)
+	if !strings.Contains(b.String(), `wardenly_screencast_fps{session="s2"} 0`) {
+		t.Errorf("fps after the window:\n%s", b.String())
+	}
+}
+
+func TestHandler_Token(t *testing.T) {
+	server := httptest.NewServer(Handler(NewCollector(), "secret"))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{"no token", "/metrics", "", http.StatusUnauthorized},
+		{"wrong token", "/metrics?token=nope", "", http.StatusUnauthorized},
+		{"query token", "/metrics?token=secret", "", http.StatusOK},
+		{"bearer token", "/metrics", "Bearer secret", http.StatusOK},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestStart_RequiresTokenOffLoopback(t *testing.T) {
+	if _, err := Start(&Config{Addr: "0.0.0.0:0"}, NewCollector(), nil); err == nil {
+		t.Error("Start() without a token on a public address should fail")
+	}
+}
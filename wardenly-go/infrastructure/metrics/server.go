@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"wardenly-go/core/eventbus"
+	"wardenly-go/infrastructure/httpguard"
+)
+
+// Server serves the metrics endpoint until stopped.
+type Server struct {
+	server *http.Server
+	bus    eventbus.EventBus
+	subID  string
+	logger *slog.Logger
+}
+
+// Start subscribes collector to bus, listens on cfg.Addr and serves
+// GET /metrics in the background. Stop must be called to release the
+// listener.
+func Start(cfg *Config, collector *Collector, bus eventbus.EventBus) (*Server, error) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.Token == "" && !httpguard.IsLoopback(cfg.Addr) {
+		return nil, fmt.Errorf("%s is required when metrics are served on %s", EnvToken, cfg.Addr)
+	}
+
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", cfg.Addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", Handler(collector, cfg.Token))
+
+	s := &Server{
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		bus:    bus,
+		subID:  bus.Subscribe(collector.HandleEvent),
+		logger: cfg.Logger,
+	}
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Metrics server failed", "error", err)
+		}
+	}()
+
+	s.logger.Info("Metrics listening", "addr", ln.Addr().String(), "auth", cfg.Token != "")
+	return s, nil
+}
+
+// Stop unsubscribes from the event bus and shuts the server down.
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	s.bus.Unsubscribe(s.subID)
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.logger.Warn("Metrics shutdown failed", "error", err)
+	}
+}
+
+// Handler serves the collector's metrics. A non-empty token must be sent as
+// a bearer token or in the token query parameter.
+func Handler(collector *Collector, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !httpguard.Authorized(r, token) {
+			http.Error(w, "missing or invalid token", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		collector.WriteTo(w)
+	})
+}
@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// ImportantKinds are the notification kinds worth interrupting someone
+// for: failed logins, scripts stopped by an error or exhausted resources,
+// and crashed sessions. They are shown as desktop notifications and
+// forwarded to webhooks.
+var ImportantKinds = []string{KindLoginFailed, KindScriptStopped, KindBrowserCrashed}
+
+// Important reports whether notifications of kind are in ImportantKinds.
+func Important(kind string) bool {
+	return slices.Contains(ImportantKinds, kind)
+}
+
+// telegramAPI is the Telegram Bot API base URL; tests replace it.
+var telegramAPI = "https://api.telegram.org"
+
+// forwardQueueSize bounds the notifications waiting to be sent; further
+// ones are dropped while a target is slow.
+const forwardQueueSize = 32
+
+// ForwarderConfig holds the targets important notifications are sent to.
+// Every target that is set receives each notification.
+type ForwarderConfig struct {
+	// WebhookURL receives the notification as JSON in a POST
+	WebhookURL string
+	// DiscordURL is a Discord channel webhook
+	DiscordURL string
+	// TelegramToken and TelegramChatID name the bot that sends the message
+	// and the chat it is sent to
+	TelegramToken  string
+	TelegramChatID string
+	// Timeout limits each request; defaults to 10s
+	Timeout time.Duration
+	Logger  *slog.Logger
+}
+
+// Enabled reports whether any target is set.
+func (c *ForwarderConfig) Enabled() bool {
+	return c != nil && (c.WebhookURL != "" || c.DiscordURL != "" || (c.TelegramToken != "" && c.TelegramChatID != ""))
+}
+
+// Forwarder sends important notifications to the configured targets in the
+// background, so a slow or unreachable target never delays the UI.
+type Forwarder struct {
+	config *ForwarderConfig
+	client *http.Client
+	logger *slog.Logger
+	queue  chan Notification
+	done   chan struct{}
+}
+
+// NewForwarder starts a forwarder. Close must be called to stop it.
+func NewForwarder(cfg *ForwarderConfig) *Forwarder {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	f := &Forwarder{
+		config: cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: cfg.Logger,
+		queue:  make(chan Notification, forwardQueueSize),
+		done:   make(chan struct{}),
+	}
+	go f.run()
+	return f
+}
+
+// Forward queues n to be sent if its kind is important. It never blocks;
+// the notification is dropped if the queue is full.
+func (f *Forwarder) Forward(n Notification) {
+	if f == nil || !Important(n.Kind) {
+		return
+	}
+	select {
+	case f.queue <- n:
+	default:
+		f.logger.Warn("Notification queue full, dropping notification", "kind", n.Kind, "title", n.Title)
+	}
+}
+
+// Close sends the queued notifications and stops the forwarder.
+func (f *Forwarder) Close() {
+	if f == nil {
+		return
+	}
+	close(f.queue)
+	<-f.done
+}
+
+func (f *Forwarder) run() {
+	defer close(f.done)
+	for n := range f.queue {
+		for _, target := range f.targets() {
+			if err := f.send(target.endpoint, target.body(n)); err != nil {
+				f.logger.Warn("Failed to forward notification", "target", target.name, "kind", n.Kind, "error", err)
+			}
+		}
+	}
+}
+
+// target is a configured destination and how it wants the notification.
+type target struct {
+	name     string
+	endpoint string
+	body     func(n Notification) any
+}
+
+func (f *Forwarder) targets() []target {
+	var targets []target
+	if f.config.WebhookURL != "" {
+		targets = append(targets, target{"webhook", f.config.WebhookURL, func(n Notification) any { return n }})
+	}
+	if f.config.DiscordURL != "" {
+		targets = append(targets, target{"discord", f.config.DiscordURL, func(n Notification) any {
+			return map[string]string{"content": "**" + n.Title + "**\n" + messageText(n)}
+		}})
+	}
+	if f.config.TelegramToken != "" && f.config.TelegramChatID != "" {
+		endpoint := telegramAPI + "/bot" + f.config.TelegramToken + "/sendMessage"
+		targets = append(targets, target{"telegram", endpoint, func(n Notification) any {
+			return map[string]string{"chat_id": f.config.TelegramChatID, "text": n.Title + "\n" + messageText(n)}
+		}})
+	}
+	return targets
+}
+
+// messageText prefixes the message with the account it is about.
+func messageText(n Notification) string {
+	if n.AccountName != "" {
+		return n.AccountName + ": " + n.Message
+	}
+	return n.Message
+}
+
+func (f *Forwarder) send(endpoint string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		// Leave out the URL, which holds the Telegram token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+// Package notify keeps the alerts raised while sessions run (script stops,
+// login failures, failed first-login setups, stuck and throttled scripts,
+// browser crashes), by scheduled account syncs and by scene threshold
+// suggestions, so they can be reviewed later instead of being lost with a
+// dismissed dialog. Notifications are saved to a JSON file and survive
+// restarts; the important ones can also be forwarded to webhooks.
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"wardenly-go/infrastructure/appdir"
+)
+
+// Notification kinds.
+const (
+	KindScriptStopped   = "script_stopped"
+	KindLoginFailed     = "login_failed"
+	KindScriptStuck     = "script_stuck"
+	KindScriptThrottled = "script_throttled"
+	KindAccountSync     = "account_sync"
+	KindBrowserCrashed  = "browser_crashed"
+	KindSceneTuning     = "scene_tuning"
+	KindSceneRegressed  = "scene_regressed"
+	KindSetupFailed     = "setup_failed"
+)
+
+// DefaultMaxItems is the number of notifications kept when Config.MaxItems
+// is zero; the oldest are dropped first.
+const DefaultMaxItems = 500
+
+// Notification is one recorded alert.
+type Notification struct {
+	ID          string    `json:"id"`
+	Time        time.Time `json:"time"`
+	Kind        string    `json:"kind"`
+	SessionID   string    `json:"sessionId,omitempty"`
+	AccountName string    `json:"accountName,omitempty"`
+	Title       string    `json:"title"`
+	Message     string    `json:"message"`
+	Read        bool      `json:"read,omitempty"`
+}
+
+// Group is the notifications of one session, newest first.
+type Group struct {
+	SessionID   string
+	AccountName string
+	Items       []Notification
+	Unread      int
+}
+
+// Config holds notification center configuration.
+type Config struct {
+	// Path is the file notifications are saved to.
+	// If empty, defaults to DefaultPath().
+	Path string
+	// MaxItems caps the number of notifications kept.
+	MaxItems int
+}
+
+// DefaultPath returns the default notifications file.
+func DefaultPath() string {
+	return appdir.Path("notifications.json")
+}
+
+// Center records notifications and their read state.
+type Center struct {
+	path     string
+	maxItems int
+
+	mu       sync.Mutex
+	items    []Notification // Oldest first
+	seq      int
+	onChange func()
+}
+
+// NewCenter creates a notification center and loads the notifications
+// saved in its file. A missing file starts an empty center; an unreadable
+// one is reported along with the empty center so alerts still work.
+func NewCenter(cfg *Config) (*Center, error) {
+	c := &Center{path: cfg.Path, maxItems: cfg.MaxItems}
+	if c.path == "" {
+		c.path = DefaultPath()
+	}
+	if c.maxItems <= 0 {
+		c.maxItems = DefaultMaxItems
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return c, fmt.Errorf("failed to read notifications: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.items); err != nil {
+		c.items = nil
+		return c, fmt.Errorf("failed to decode notifications: %w", err)
+	}
+	return c, nil
+}
+
+// SetOnChange registers a function called after notifications are added,
+// marked read or cleared. It runs on the caller's goroutine.
+func (c *Center) SetOnChange(fn func()) {
+	c.mu.Lock()
+	c.onChange = fn
+	c.mu.Unlock()
+}
+
+// Add records a notification, filling in its ID and time, and saves the
+// center. The notification is kept even if saving fails.
+func (c *Center) Add(n Notification) (Notification, error) {
+	c.mu.Lock()
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+	c.seq++
+	n.ID = strconv.FormatInt(n.Time.UnixNano(), 36) + "-" + strconv.Itoa(c.seq)
+	c.items = append(c.items, n)
+	if over := len(c.items) - c.maxItems; over > 0 {
+		c.items = slices.Delete(c.items, 0, over)
+	}
+	err := c.saveLocked()
+	onChange := c.onChange
+	c.mu.Unlock()
+
+	if onChange != nil {
+		onChange()
+	}
+	return n, err
+}
+
+// List returns all notifications, newest first.
+func (c *Center) List() []Notification {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	list := slices.Clone(c.items)
+	slices.Reverse(list)
+	return list
+}
+
+// Unread returns the number of unread notifications.
+func (c *Center) Unread() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, item := range c.items {
+		if !item.Read {
+			n++
+		}
+	}
+	return n
+}
+
+// MarkRead marks the notifications with the given IDs as read.
+func (c *Center) MarkRead(ids ...string) error {
+	return c.update(func() bool {
+		changed := false
+		for i := range c.items {
+			if !c.items[i].Read && slices.Contains(ids, c.items[i].ID) {
+				c.items[i].Read = true
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+// MarkAllRead marks every notification as read.
+func (c *Center) MarkAllRead() error {
+	return c.update(func() bool {
+		changed := false
+		for i := range c.items {
+			if !c.items[i].Read {
+				c.items[i].Read = true
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+// Clear removes every notification.
+func (c *Center) Clear() error {
+	return c.update(func() bool {
+		changed := len(c.items) > 0
+		c.items = nil
+		return changed
+	})
+}
+
+// update applies fn under the lock and saves and notifies if it reports a
+// change.
+func (c *Center) update(fn func() bool) error {
+	c.mu.Lock()
+	if !fn() {
+		c.mu.Unlock()
+		return nil
+	}
+	err := c.saveLocked()
+	onChange := c.onChange
+	c.mu.Unlock()
+
+	if onChange != nil {
+		onChange()
+	}
+	return err
+}
+
+func (c *Center) saveLocked() error {
+	data, err := json.Marshal(c.items)
+	if err != nil {
+		return fmt.Errorf("failed to encode notifications: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create notifications dir: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write notifications: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to write notifications: %w", err)
+	}
+	return nil
+}
+
+// GroupBySession groups notifications by session, keeping their order.
+// Groups are ordered by their newest notification when items are newest
+// first, as returned by List.
+func GroupBySession(items []Notification) []Group {
+	index := make(map[string]int)
+	var groups []Group
+	for _, n := range items {
+		i, ok := index[n.SessionID]
+		if !ok {
+			i = len(groups)
+			index[n.SessionID] = i
+			groups = append(groups, Group{SessionID: n.SessionID, AccountName: n.AccountName})
+		}
+		g := &groups[i]
+		if g.AccountName == "" {
+			g.AccountName = n.AccountName
+		}
+		g.Items = append(g.Items, n)
+		if !n.Read {
+			g.Unread++
+		}
+	}
+	return groups
+}
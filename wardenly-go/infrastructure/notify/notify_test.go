@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCenter_PersistsAndMarksRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "notifications.json")
+	c, err := NewCenter(&Config{Path: path})
+	if err != nil {
+		t.Fatalf("NewCenter: %v", err)
+	}
+
+	changes := 0
+	c.SetOnChange(func() { changes++ })
+
+	first, err := c.Add(Notification{Kind: KindLoginFailed, SessionID: "a1", AccountName: "alice", Title: "Login Failed", Message: "timeout"})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if first.ID == "" || first.Time.IsZero() {
+		t.Errorf("added = %+v, want ID and time", first)
+	}
+	if _, err := c.Add(Notification{Kind: KindScriptStuck, SessionID: "a2", Title: "Script Stuck"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if got := c.Unread(); got != 2 {
+		t.Errorf("Unread() = %d, want 2", got)
+	}
+	if err := c.MarkRead(first.ID); err != nil {
+		t.Fatalf("MarkRead: %v", err)
+	}
+	if err := c.MarkRead(first.ID); err != nil { // No change
+		t.Fatalf("MarkRead: %v", err)
+	}
+	if changes != 3 {
+		t.Errorf("changes = %d, want 3", changes)
+	}
+
+	reloaded, err := NewCenter(&Config{Path: path})
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	list := reloaded.List()
+	if len(list) != 2 || list[0].Kind != KindScriptStuck || list[1].ID != first.ID || !list[1].Read {
+		t.Fatalf("reloaded list = %+v", list)
+	}
+	if got := reloaded.Unread(); got != 1 {
+		t.Errorf("reloaded Unread() = %d, want 1", got)
+	}
+
+	if err := reloaded.MarkAllRead(); err != nil || reloaded.Unread() != 0 {
+		t.Errorf("MarkAllRead: err = %v, unread = %d", err, reloaded.Unread())
+	}
+	if err := reloaded.Clear(); err != nil || len(reloaded.List()) != 0 {
+		t.Errorf("Clear: err = %v, list = %+v", err, reloaded.List())
+	}
+}
+
+func TestCenter_DropsOldest(t *testing.T) {
+	c, err := NewCenter(&Config{Path: filepath.Join(t.TempDir(), "n.json"), MaxItems: 2})
+	if err != nil {
+		t.Fatalf("NewCenter: %v", err)
+	}
+	for _, title := range []string{"one", "two", "three"} {
+		if _, err := c.Add(Notification{Title: title}); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	list := c.List()
+	if len(list) != 2 || list[0].Title != "three" || list[1].Title != "two" {
+		t.Errorf("list = %+v, want three, two", list)
+	}
+}
+
+func TestNewCenter_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "n.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewCenter(&Config{Path: path})
+	if err == nil {
+		t.Error("want error for a corrupt file")
+	}
+	if c == nil || len(c.List()) != 0 {
+		t.Fatal("want an empty center alongside the error")
+	}
+	if _, err := c.Add(Notification{Title: "after"}); err != nil {
+		t.Errorf("Add after corrupt load: %v", err)
+	}
+}
+
+func TestGroupBySession(t *testing.T) {
+	now := time.Now()
+	items := []Notification{
+		{ID: "3", Time: now, SessionID: "b", AccountName: "bob"},
+		{ID: "2", Time: now.Add(-time.Minute), SessionID: "a", AccountName: "alice", Read: true},
+		{ID: "1", Time: now.Add(-2 * time.Minute), SessionID: "b"},
+	}
+	groups := GroupBySession(items)
+	if len(groups) != 2 {
+		t.Fatalf("groups = %+v, want 2", groups)
+	}
+	if g := groups[0]; g.SessionID != "b" || g.AccountName != "bob" || len(g.Items) != 2 || g.Unread != 2 {
+		t.Errorf("groups[0] = %+v", g)
+	}
+	if g := groups[1]; g.SessionID != "a" || len(g.Items) != 1 || g.Unread != 0 {
+		t.Errorf("groups[1] = %+v", g)
+	}
+}
+
+func TestForwarder(t *testing.T) {
+	type request struct {
+		path string
+		body map[string]any
+	}
+	requests := make(chan request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		requests <- request{r.URL.Path, body}
+	}))
+	defer server.Close()
+
+	old := telegramAPI
+	telegramAPI = server.URL
+	defer func() { telegramAPI = old }()
+
+	f := NewForwarder(&ForwarderConfig{
+		WebhookURL:     server.URL + "/hook",
+		DiscordURL:     server.URL + "/discord",
+		TelegramToken:  "123:abc",
+		TelegramChatID: "42",
+	})
+	f.Forward(Notification{Kind: KindScriptStuck, Title: "Script Stuck"}) // Not important
+	f.Forward(Notification{Kind: KindLoginFailed, AccountName: "alice", Title: "Login Failed", Message: "timeout"})
+	f.Close()
+	close(requests)
+
+	got := make(map[string]map[string]any)
+	for r := range requests {
+		got[r.path] = r.body
+	}
+	if len(got) != 3 {
+		t.Fatalf("requests = %v, want one per target", got)
+	}
+	if got["/hook"]["kind"] != KindLoginFailed || got["/hook"]["accountName"] != "alice" {
+		t.Errorf("webhook body = %v", got["/hook"])
+	}
+	if got["/discord"]["content"] != "**Login Failed**\nalice: timeout" {
+		t.Errorf("discord body = %v", got["/discord"])
+	}
+	if tg := got["/bot123:abc/sendMessage"]; tg["chat_id"] != "42" || tg["text"] != "Login Failed\nalice: timeout" {
+		t.Errorf("telegram body = %v", tg)
+	}
+}
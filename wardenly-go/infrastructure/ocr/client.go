@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"image"
 	"image/png"
@@ -12,11 +13,19 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// errUnreachable marks requests that never reached the OCR service, so a
+// Pool can retry them on another backend.
+var errUnreachable = errors.New("OCR service unreachable")
+
+// errNoRatio marks images in which no usage ratio was found.
+var errNoRatio = errors.New("no ratio found in image")
+
 // Client provides OCR recognition services.
 type Client interface {
 	// RecognizeUsageRatio recognizes a usage ratio (e.g., "1/10") from image bytes.
@@ -25,6 +34,18 @@ type Client interface {
 	// RecognizeUsageRatioFromImage recognizes a usage ratio from an image.Image.
 	RecognizeUsageRatioFromImage(ctx context.Context, img image.Image, roi *ROI) (*UsageRatioResult, error)
 
+	// RecognizeMany recognizes a usage ratio in each of several regions of
+	// one image, in a single request where the service allows. Results are
+	// in the order of rois, nil where no ratio was found.
+	RecognizeMany(ctx context.Context, img image.Image, rois []ROI) ([]*UsageRatioResult, error)
+
+	// RecognizeText recognizes lines of free text, with their boxes, from
+	// image bytes. opts may be nil to use the service defaults.
+	RecognizeText(ctx context.Context, imageBytes []byte, roi *ROI, opts *TextOptions) (*TextResult, error)
+
+	// RecognizeTextFromImage recognizes lines of free text from an image.Image.
+	RecognizeTextFromImage(ctx context.Context, img image.Image, roi *ROI, opts *TextOptions) (*TextResult, error)
+
 	// IsHealthy returns true if the OCR service is available.
 	IsHealthy() bool
 
@@ -49,6 +70,91 @@ type UsageRatioResult struct {
 	ElapsedMs   float64
 }
 
+// TextOptions are per-request hints for free text recognition.
+type TextOptions struct {
+	// Language selects the recognition model (e.g. "ch", "en").
+	// Empty uses the service default.
+	Language string
+	// Charset restricts the characters that may be recognized.
+	// Empty allows all characters of the language.
+	Charset string
+}
+
+// Box is where recognized text lies, in the coordinates of the whole
+// image rather than of the ROI it was read from. A zero Box means the
+// service didn't report one.
+type Box struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// TextWord is a single word of a recognized line.
+type TextWord struct {
+	Text       string
+	Box        Box
+	Confidence float64
+}
+
+// TextLine is a single line of recognized text.
+type TextLine struct {
+	Text       string
+	Confidence float64
+	Box        Box
+	// Words are the line's words, left to right, if the service split it
+	Words []TextWord
+}
+
+// TextResult contains the lines recognized in an image, top to bottom.
+type TextResult struct {
+	Lines     []TextLine
+	ElapsedMs float64
+}
+
+// Text returns the raw text of all lines, one per line.
+func (r *TextResult) Text() string {
+	lines := make([]string, len(r.Lines))
+	for i, l := range r.Lines {
+		lines[i] = l.Text
+	}
+	return strings.Join(lines, "\n")
+}
+
+// offset moves the reported boxes of the result by (dx, dy).
+func (r *TextResult) offset(dx, dy int) {
+	for i := range r.Lines {
+		line := &r.Lines[i]
+		line.Box.move(dx, dy)
+		for j := range line.Words {
+			line.Words[j].Box.move(dx, dy)
+		}
+	}
+}
+
+func (b *Box) move(dx, dy int) {
+	if *b != (Box{}) {
+		b.X += dx
+		b.Y += dy
+	}
+}
+
+// apiBox is a box as the service reports it, relative to the region it
+// read.
+type apiBox struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+func (b *apiBox) box() Box {
+	if b == nil {
+		return Box{}
+	}
+	return Box{X: b.X, Y: b.Y, Width: b.Width, Height: b.Height}
+}
+
 // ClientConfig contains configuration for the OCR client.
 type ClientConfig struct {
 	BaseURL        string
@@ -110,98 +216,270 @@ func (c *HTTPClient) RecognizeUsageRatio(ctx context.Context, imageBytes []byte,
 		return nil, fmt.Errorf("OCR service is currently unavailable")
 	}
 
-	// Build request URL
-	requestURL := fmt.Sprintf("%s/v1/ratios/usage", c.config.BaseURL)
+	body, status, err := c.post(ctx, "/v1/ratios/usage", imageBytes, roiParams(roi))
+	if err != nil {
+		return nil, err
+	}
+
+	if status == http.StatusNotFound {
+		return nil, errNoRatio
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", status, string(body))
+	}
+
+	// Parse response
+	var apiResp apiRatio
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return apiResp.result(), nil
+}
+
+// apiRatio is a usage ratio as the service reports it.
+type apiRatio struct {
+	Numerator   int `json:"numerator"`
+	Denominator int `json:"denominator"`
+	Debug       struct {
+		RawText    string  `json:"raw_text"`
+		Confidence float64 `json:"confidence"`
+		ElapsedMs  float64 `json:"elapsed_ms"`
+	} `json:"debug"`
+}
+
+func (r *apiRatio) result() *UsageRatioResult {
+	return &UsageRatioResult{
+		Numerator:   r.Numerator,
+		Denominator: r.Denominator,
+		RawText:     r.Debug.RawText,
+		Confidence:  r.Debug.Confidence,
+		ElapsedMs:   r.Debug.ElapsedMs,
+	}
+}
+
+// RecognizeMany sends the whole image once with a roi=x,y,width,height
+// query parameter per region. Services without the batch endpoint are
+// asked region by region instead.
+func (c *HTTPClient) RecognizeMany(ctx context.Context, img image.Image, rois []ROI) ([]*UsageRatioResult, error) {
+	if !c.IsHealthy() {
+		return nil, fmt.Errorf("OCR service is currently unavailable")
+	}
+
+	data, _, err := encodeImage(img, nil)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	for _, roi := range rois {
+		params.Add("roi", fmt.Sprintf("%d,%d,%d,%d", roi.X, roi.Y, roi.Width, roi.Height))
+	}
+
+	body, status, err := c.post(ctx, "/v1/ratios/usage/batch", data, params)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return recognizeEach(ctx, c, img, rois)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", status, string(body))
+	}
+
+	var apiResp struct {
+		Results []*apiRatio `json:"results"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(apiResp.Results) != len(rois) {
+		return nil, fmt.Errorf("got %d results for %d regions", len(apiResp.Results), len(rois))
+	}
+	results := make([]*UsageRatioResult, len(rois))
+	for i, r := range apiResp.Results {
+		if r != nil {
+			results[i] = r.result()
+		}
+	}
+	return results, nil
+}
+
+// recognizeEach implements RecognizeMany with a request per region.
+func recognizeEach(ctx context.Context, c Client, img image.Image, rois []ROI) ([]*UsageRatioResult, error) {
+	results := make([]*UsageRatioResult, len(rois))
+	for i := range rois {
+		result, err := c.RecognizeUsageRatioFromImage(ctx, img, &rois[i])
+		if errors.Is(err, errNoRatio) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// roiParams returns the query parameters of a server-side crop.
+func roiParams(roi *ROI) url.Values {
+	params := url.Values{}
 	if roi != nil {
-		params := url.Values{}
 		params.Add("x", strconv.Itoa(roi.X))
 		params.Add("y", strconv.Itoa(roi.Y))
 		params.Add("width", strconv.Itoa(roi.Width))
 		params.Add("height", strconv.Itoa(roi.Height))
+	}
+	return params
+}
+
+// post sends image bytes to an OCR endpoint and returns the response body and status.
+func (c *HTTPClient) post(ctx context.Context, path string, imageBytes []byte, params url.Values) ([]byte, int, error) {
+	// Build request URL
+	requestURL := c.config.BaseURL + path
+	if len(params) > 0 {
 		requestURL = fmt.Sprintf("%s?%s", requestURL, params.Encode())
 	}
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewReader(imageBytes))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/octet-stream")
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		if ctx.Err() != nil {
+			return nil, 0, fmt.Errorf("failed to execute request: %w", err)
+		}
+		// Treat the service as down until the next health check passes
+		c.healthy.Store(false)
+		return nil, 0, fmt.Errorf("%w: %w", errUnreachable, err)
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, 0, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("no ratio found in image")
+	return body, resp.StatusCode, nil
+}
+
+// RecognizeUsageRatioFromImage recognizes a usage ratio from an image.Image.
+func (c *HTTPClient) RecognizeUsageRatioFromImage(ctx context.Context, img image.Image, roi *ROI) (*UsageRatioResult, error) {
+	data, remoteROI, err := encodeImage(img, roi)
+	if err != nil {
+		return nil, err
 	}
+	return c.RecognizeUsageRatio(ctx, data, remoteROI)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+// RecognizeText recognizes lines of free text, with their word boxes when
+// the service reports them, from image bytes. The options are sent as the
+// lang and charset query parameters.
+func (c *HTTPClient) RecognizeText(ctx context.Context, imageBytes []byte, roi *ROI, opts *TextOptions) (*TextResult, error) {
+	if !c.IsHealthy() {
+		return nil, fmt.Errorf("OCR service is currently unavailable")
+	}
+
+	params := roiParams(roi)
+	if opts != nil {
+		if opts.Language != "" {
+			params.Set("lang", opts.Language)
+		}
+		if opts.Charset != "" {
+			params.Set("charset", opts.Charset)
+		}
+	}
+
+	body, status, err := c.post(ctx, "/v1/texts", imageBytes, params)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", status, string(body))
 	}
 
-	// Parse response
 	var apiResp struct {
-		Numerator   int `json:"numerator"`
-		Denominator int `json:"denominator"`
-		Debug       struct {
-			RawText    string  `json:"raw_text"`
+		Lines []struct {
+			Text       string  `json:"text"`
 			Confidence float64 `json:"confidence"`
-			ElapsedMs  float64 `json:"elapsed_ms"`
+			Box        *apiBox `json:"box"`
+			Words      []struct {
+				Text       string  `json:"text"`
+				Confidence float64 `json:"confidence"`
+				Box        *apiBox `json:"box"`
+			} `json:"words"`
+		} `json:"lines"`
+		Debug struct {
+			ElapsedMs float64 `json:"elapsed_ms"`
 		} `json:"debug"`
 	}
-
 	if err := json.Unmarshal(body, &apiResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	return &UsageRatioResult{
-		Numerator:   apiResp.Numerator,
-		Denominator: apiResp.Denominator,
-		RawText:     apiResp.Debug.RawText,
-		Confidence:  apiResp.Debug.Confidence,
-		ElapsedMs:   apiResp.Debug.ElapsedMs,
-	}, nil
+	result := &TextResult{
+		Lines:     make([]TextLine, len(apiResp.Lines)),
+		ElapsedMs: apiResp.Debug.ElapsedMs,
+	}
+	for i, l := range apiResp.Lines {
+		line := TextLine{Text: l.Text, Confidence: l.Confidence, Box: l.Box.box()}
+		for _, w := range l.Words {
+			line.Words = append(line.Words, TextWord{Text: w.Text, Confidence: w.Confidence, Box: w.Box.box()})
+		}
+		result.Lines[i] = line
+	}
+	if roi != nil {
+		result.offset(roi.X, roi.Y)
+	}
+	return result, nil
 }
 
-// RecognizeUsageRatioFromImage recognizes a usage ratio from an image.Image.
-func (c *HTTPClient) RecognizeUsageRatioFromImage(ctx context.Context, img image.Image, roi *ROI) (*UsageRatioResult, error) {
-	var targetImg image.Image
+// RecognizeTextFromImage recognizes lines of free text from an image.Image.
+// Boxes are in img's coordinates.
+func (c *HTTPClient) RecognizeTextFromImage(ctx context.Context, img image.Image, roi *ROI, opts *TextOptions) (*TextResult, error) {
+	data, remoteROI, err := encodeImage(img, roi)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.RecognizeText(ctx, data, remoteROI, opts)
+	if err != nil {
+		return nil, err
+	}
+	// A locally cropped ROI was sent as an image of its own
+	if roi != nil && remoteROI == nil {
+		result.offset(roi.X, roi.Y)
+	}
+	return result, nil
+}
+
+// encodeImage encodes img as PNG, cropping to roi locally when possible to
+// reduce network transfer. The returned ROI is non-nil only if the server
+// still has to crop.
+func encodeImage(img image.Image, roi *ROI) ([]byte, *ROI, error) {
+	targetImg := img
 	var remoteROI *ROI
 
-	// Crop locally if possible to reduce network transfer
 	if roi != nil {
 		if subImager, ok := img.(interface {
 			SubImage(r image.Rectangle) image.Image
 		}); ok {
 			rect := image.Rect(roi.X, roi.Y, roi.X+roi.Width, roi.Y+roi.Height)
 			targetImg = subImager.SubImage(rect)
-			remoteROI = nil
 		} else {
-			targetImg = img
 			remoteROI = roi
 		}
-	} else {
-		targetImg = img
-		remoteROI = nil
 	}
 
-	// Encode to PNG
 	buf := new(bytes.Buffer)
 	if err := png.Encode(buf, targetImg); err != nil {
-		return nil, fmt.Errorf("failed to encode image: %w", err)
+		return nil, nil, fmt.Errorf("failed to encode image: %w", err)
 	}
-
-	return c.RecognizeUsageRatio(ctx, buf.Bytes(), remoteROI)
+	return buf.Bytes(), remoteROI, nil
 }
 
 // IsHealthy returns true if the OCR service is available.
@@ -272,6 +550,18 @@ func (c *NoOpClient) RecognizeUsageRatioFromImage(ctx context.Context, img image
 	return nil, fmt.Errorf("OCR is disabled")
 }
 
+func (c *NoOpClient) RecognizeMany(ctx context.Context, img image.Image, rois []ROI) ([]*UsageRatioResult, error) {
+	return nil, fmt.Errorf("OCR is disabled")
+}
+
+func (c *NoOpClient) RecognizeText(ctx context.Context, imageBytes []byte, roi *ROI, opts *TextOptions) (*TextResult, error) {
+	return nil, fmt.Errorf("OCR is disabled")
+}
+
+func (c *NoOpClient) RecognizeTextFromImage(ctx context.Context, img image.Image, roi *ROI, opts *TextOptions) (*TextResult, error) {
+	return nil, fmt.Errorf("OCR is disabled")
+}
+
 func (c *NoOpClient) IsHealthy() bool {
 	return false
 }
@@ -1,6 +1,15 @@
 package ocr
 
-import "testing"
+import (
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
 
 func TestDefaultClientConfig(t *testing.T) {
 	config := DefaultClientConfig()
@@ -94,8 +103,157 @@ func TestNoOpClient(t *testing.T) {
 		}
 	})
 
+	t.Run("RecognizeMany", func(t *testing.T) {
+		_, err := client.RecognizeMany(nil, nil, nil)
+		if err == nil {
+			t.Error("NoOpClient.RecognizeMany() should return error")
+		}
+	})
+
+	t.Run("RecognizeText", func(t *testing.T) {
+		_, err := client.RecognizeText(nil, nil, nil, nil)
+		if err == nil {
+			t.Error("NoOpClient.RecognizeText() should return error")
+		}
+	})
+
 	t.Run("Close", func(t *testing.T) {
 		// Should not panic
 		client.Close()
 	})
 }
+
+func TestHTTPClient_RecognizeTextFromImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.WriteHeader(http.StatusOK)
+		case "/v1/texts":
+			if r.URL.RawQuery != "" {
+				t.Errorf("ROI should be cropped locally, got query %q", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"lines":[{"text":"Alice","confidence":0.9,"box":{"x":1,"y":2,"width":6,"height":3},` +
+				`"words":[{"text":"Alice","confidence":0.9,"box":{"x":1,"y":2,"width":6,"height":3}}]},` +
+				`{"text":"Bob","confidence":0.8}],"debug":{"elapsed_ms":12}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&ClientConfig{
+		BaseURL:        server.URL,
+		Timeout:        time.Second,
+		HealthInterval: time.Hour,
+		HealthTimeout:  time.Second,
+	})
+	defer client.Close()
+
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	result, err := client.RecognizeTextFromImage(context.Background(), img, &ROI{X: 5, Y: 5, Width: 10, Height: 10}, nil)
+	if err != nil {
+		t.Fatalf("RecognizeTextFromImage() error = %v", err)
+	}
+	if len(result.Lines) != 2 || result.Lines[0].Text != "Alice" || result.Lines[1].Confidence != 0.8 {
+		t.Errorf("Lines = %+v", result.Lines)
+	}
+	if result.ElapsedMs != 12 {
+		t.Errorf("ElapsedMs = %v, want 12", result.ElapsedMs)
+	}
+	// Boxes are moved from the cropped ROI into the image
+	want := Box{X: 6, Y: 7, Width: 6, Height: 3}
+	if line := result.Lines[0]; line.Box != want || len(line.Words) != 1 || line.Words[0].Box != want {
+		t.Errorf("Lines[0] = %+v, want box %+v", line, want)
+	}
+	if result.Lines[1].Box != (Box{}) || result.Lines[1].Words != nil {
+		t.Errorf("Lines[1] = %+v, want no box or words", result.Lines[1])
+	}
+	if got := result.Text(); got != "Alice\nBob" {
+		t.Errorf("Text() = %q", got)
+	}
+}
+
+func TestHTTPClient_RecognizeTextOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.WriteHeader(http.StatusOK)
+		case "/v1/texts":
+			q := r.URL.Query()
+			if q.Get("lang") != "ch" || q.Get("charset") != "0123456789" {
+				t.Errorf("query = %q, want lang=ch and charset", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"lines":[{"text":"讨伐山贼","confidence":0.9}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&ClientConfig{
+		BaseURL:        server.URL,
+		Timeout:        time.Second,
+		HealthInterval: time.Hour,
+		HealthTimeout:  time.Second,
+	})
+	defer client.Close()
+
+	result, err := client.RecognizeText(context.Background(), []byte("png"), nil, &TextOptions{Language: "ch", Charset: "0123456789"})
+	if err != nil {
+		t.Fatalf("RecognizeText() error = %v", err)
+	}
+	if len(result.Lines) != 1 || result.Lines[0].Text != "讨伐山贼" {
+		t.Errorf("Lines = %+v", result.Lines)
+	}
+}
+
+func TestHTTPClient_RecognizeMany(t *testing.T) {
+	var batches, singles atomic.Int32
+	var batch atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/health":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v1/ratios/usage/batch" && batch.Load():
+			batches.Add(1)
+			if rois := r.URL.Query()["roi"]; len(rois) != 2 || rois[0] != "1,2,10,5" {
+				t.Errorf("roi = %v, want one per region", rois)
+			}
+			w.Write([]byte(`{"results":[{"numerator":3,"denominator":10,"debug":{"confidence":0.9}},null]}`))
+		case r.URL.Path == "/v1/ratios/usage":
+			singles.Add(1)
+			if img, err := png.Decode(r.Body); err == nil && img.Bounds().Dx() == 10 {
+				w.Write([]byte(`{"numerator":3,"denominator":10,"debug":{"confidence":0.9}}`))
+				return
+			}
+			http.NotFound(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&ClientConfig{
+		BaseURL:        server.URL,
+		Timeout:        time.Second,
+		HealthInterval: time.Hour,
+		HealthTimeout:  time.Second,
+	})
+	defer client.Close()
+
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	rois := []ROI{{X: 1, Y: 2, Width: 10, Height: 5}, {X: 20, Y: 2, Width: 8, Height: 5}}
+	for _, supported := range []bool{true, false} {
+		batch.Store(supported)
+		results, err := client.RecognizeMany(context.Background(), img, rois)
+		if err != nil {
+			t.Fatalf("RecognizeMany(batch %v) error = %v", supported, err)
+		}
+		if len(results) != 2 || results[0] == nil || results[0].Numerator != 3 || results[1] != nil {
+			t.Errorf("RecognizeMany(batch %v) = %+v, want a ratio for the first region only", supported, results)
+		}
+	}
+	if batches.Load() != 1 || singles.Load() != 2 {
+		t.Errorf("requests = %d batch and %d single, want 1 and 2", batches.Load(), singles.Load())
+	}
+}
@@ -0,0 +1,344 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Environment variables read by PoolConfigFromEnv.
+const (
+	EnvURLs        = "WARDENLY_OCR_URLS"
+	EnvConcurrency = "WARDENLY_OCR_CONCURRENCY"
+	EnvTesseract   = "WARDENLY_OCR_TESSERACT"
+)
+
+// DefaultMaxConcurrent is the default cap on OCR requests in flight.
+const DefaultMaxConcurrent = 4
+
+// PoolConfig contains configuration for an OCR backend pool.
+type PoolConfig struct {
+	// BaseURLs lists the OCR backends. Requests go to the healthy ones in
+	// turn.
+	BaseURLs []string
+	// MaxConcurrent caps the requests in flight across all backends; zero
+	// means no cap.
+	MaxConcurrent  int
+	Timeout        time.Duration
+	HealthInterval time.Duration
+	HealthTimeout  time.Duration
+	// Tesseract is the local Tesseract command requests fall back to while
+	// no backend is healthy; empty disables the fallback
+	Tesseract string
+	// Observe is called with the duration of each request, including the
+	// wait for a free slot, and its error (optional)
+	Observe func(elapsed time.Duration, err error)
+}
+
+// DefaultPoolConfig returns a pool of the default OCR backend.
+func DefaultPoolConfig() *PoolConfig {
+	client := DefaultClientConfig()
+	return &PoolConfig{
+		BaseURLs:       []string{client.BaseURL},
+		MaxConcurrent:  DefaultMaxConcurrent,
+		Timeout:        client.Timeout,
+		HealthInterval: client.HealthInterval,
+		HealthTimeout:  client.HealthTimeout,
+		Tesseract:      DefaultTesseract,
+	}
+}
+
+// PoolConfigFromEnv builds a PoolConfig from WARDENLY_OCR_URLS, a comma
+// separated list of backends, WARDENLY_OCR_CONCURRENCY and
+// WARDENLY_OCR_TESSERACT, the local fallback command or "off". Invalid
+// settings are reported and left at their defaults.
+func PoolConfigFromEnv() (*PoolConfig, error) {
+	cfg := DefaultPoolConfig()
+	return cfg, cfg.ApplyEnv()
+}
+
+// ApplyEnv overrides c with the environment variables read by
+// PoolConfigFromEnv. Invalid settings are reported and left unchanged.
+func (c *PoolConfig) ApplyEnv() error {
+	var errs []error
+	if raw := os.Getenv(EnvURLs); raw != "" {
+		var urls []string
+		for _, u := range strings.Split(raw, ",") {
+			if u = strings.TrimRight(strings.TrimSpace(u), "/"); u != "" {
+				urls = append(urls, u)
+			}
+		}
+		if len(urls) == 0 {
+			errs = append(errs, fmt.Errorf("%s: no backend URLs in %q", EnvURLs, raw))
+		} else {
+			c.BaseURLs = urls
+		}
+	}
+	if raw := os.Getenv(EnvConcurrency); raw != "" {
+		if n, err := strconv.Atoi(raw); err != nil || n < 0 {
+			errs = append(errs, fmt.Errorf("%s: must be a non-negative integer, got %q", EnvConcurrency, raw))
+		} else {
+			c.MaxConcurrent = n
+		}
+	}
+	if raw := os.Getenv(EnvTesseract); raw != "" {
+		if strings.EqualFold(raw, "off") {
+			c.Tesseract = ""
+		} else {
+			c.Tesseract = raw
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type sessionKey struct{}
+
+// WithSession marks OCR requests made with ctx as coming from a session,
+// so a Pool can share its capacity fairly between sessions.
+func WithSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sessionID)
+}
+
+// sessionFrom returns the session set by WithSession, or "" if none.
+func sessionFrom(ctx context.Context) string {
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
+}
+
+// Pool implements Client over several OCR backends. Requests are spread
+// over the healthy backends round-robin, and a request that cannot reach
+// its backend is retried on the next one. At most MaxConcurrent requests
+// are in flight; while the pool is full, waiting sessions take turns.
+// While no backend is healthy, requests go to the local fallback, if any.
+type Pool struct {
+	backends []*HTTPClient
+	fallback Client
+	next     atomic.Uint64
+	limiter  *fairLimiter
+	observe  func(time.Duration, error)
+}
+
+// NewPool creates an OCR client pool with a health checker per backend.
+func NewPool(config *PoolConfig) *Pool {
+	if config == nil {
+		config = DefaultPoolConfig()
+	}
+
+	p := &Pool{observe: config.Observe}
+	for _, u := range config.BaseURLs {
+		p.backends = append(p.backends, NewHTTPClient(&ClientConfig{
+			BaseURL:        u,
+			Timeout:        config.Timeout,
+			HealthInterval: config.HealthInterval,
+			HealthTimeout:  config.HealthTimeout,
+		}))
+	}
+	if config.Tesseract != "" {
+		p.fallback = NewTesseractClient(config.Tesseract, config.Timeout)
+	}
+	if config.MaxConcurrent > 0 {
+		p.limiter = newFairLimiter(config.MaxConcurrent)
+	}
+	return p
+}
+
+// RecognizeUsageRatio recognizes a usage ratio from image bytes.
+func (p *Pool) RecognizeUsageRatio(ctx context.Context, imageBytes []byte, roi *ROI) (*UsageRatioResult, error) {
+	return poolDo(ctx, p, func(c Client) (*UsageRatioResult, error) {
+		return c.RecognizeUsageRatio(ctx, imageBytes, roi)
+	})
+}
+
+// RecognizeUsageRatioFromImage recognizes a usage ratio from an image.Image.
+// The image is encoded before waiting for a free slot.
+func (p *Pool) RecognizeUsageRatioFromImage(ctx context.Context, img image.Image, roi *ROI) (*UsageRatioResult, error) {
+	data, remoteROI, err := encodeImage(img, roi)
+	if err != nil {
+		return nil, err
+	}
+	return p.RecognizeUsageRatio(ctx, data, remoteROI)
+}
+
+// RecognizeMany recognizes a usage ratio in each of several regions of
+// an image in one request.
+func (p *Pool) RecognizeMany(ctx context.Context, img image.Image, rois []ROI) ([]*UsageRatioResult, error) {
+	return poolDo(ctx, p, func(c Client) ([]*UsageRatioResult, error) {
+		return c.RecognizeMany(ctx, img, rois)
+	})
+}
+
+// RecognizeText recognizes lines of free text from image bytes.
+func (p *Pool) RecognizeText(ctx context.Context, imageBytes []byte, roi *ROI, opts *TextOptions) (*TextResult, error) {
+	return poolDo(ctx, p, func(c Client) (*TextResult, error) {
+		return c.RecognizeText(ctx, imageBytes, roi, opts)
+	})
+}
+
+// RecognizeTextFromImage recognizes lines of free text from an image.Image.
+// The image is encoded before waiting for a free slot.
+func (p *Pool) RecognizeTextFromImage(ctx context.Context, img image.Image, roi *ROI, opts *TextOptions) (*TextResult, error) {
+	data, remoteROI, err := encodeImage(img, roi)
+	if err != nil {
+		return nil, err
+	}
+	return p.RecognizeText(ctx, data, remoteROI, opts)
+}
+
+// poolDo runs a request on the healthy backends in turn until one is
+// reached, or on the fallback if none is, holding a slot of the pool's
+// limit meanwhile.
+func poolDo[T any](ctx context.Context, p *Pool, call func(Client) (T, error)) (result T, err error) {
+	if p.observe != nil {
+		start := time.Now()
+		defer func() { p.observe(time.Since(start), err) }()
+	}
+
+	var zero T
+	if p.limiter != nil {
+		if err := p.limiter.acquire(ctx, sessionFrom(ctx)); err != nil {
+			return zero, err
+		}
+		defer p.limiter.release()
+	}
+
+	err = fmt.Errorf("OCR service is currently unavailable")
+	for range p.backends {
+		backend := p.pick()
+		if backend == nil {
+			break
+		}
+		result, err = call(backend)
+		if !errors.Is(err, errUnreachable) {
+			return result, err
+		}
+	}
+	if p.fallback != nil && p.fallback.IsHealthy() {
+		return call(p.fallback)
+	}
+	return zero, err
+}
+
+// pick returns the next healthy backend round-robin, or nil if none is.
+func (p *Pool) pick() *HTTPClient {
+	start := p.next.Add(1) - 1
+	for i := range p.backends {
+		backend := p.backends[(start+uint64(i))%uint64(len(p.backends))]
+		if backend.IsHealthy() {
+			return backend
+		}
+	}
+	return nil
+}
+
+// IsHealthy returns true if any backend, or the fallback, is available.
+func (p *Pool) IsHealthy() bool {
+	return slices.ContainsFunc(p.backends, (*HTTPClient).IsHealthy) ||
+		(p.fallback != nil && p.fallback.IsHealthy())
+}
+
+// Close stops the health checks of all backends.
+func (p *Pool) Close() {
+	for _, backend := range p.backends {
+		backend.Close()
+	}
+	if p.fallback != nil {
+		p.fallback.Close()
+	}
+}
+
+// Ensure Pool implements Client
+var _ Client = (*Pool)(nil)
+
+// fairLimiter caps concurrent requests. While it is full, waiting requests
+// are admitted one session at a time in turn, so a session that queues
+// many requests cannot starve the others.
+type fairLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	active  int
+	waiting map[string][]chan struct{}
+	turns   []string // sessions with waiting requests, next first
+}
+
+func newFairLimiter(limit int) *fairLimiter {
+	return &fairLimiter{
+		limit:   limit,
+		waiting: make(map[string][]chan struct{}),
+	}
+}
+
+// acquire waits for a free slot for a request of the session.
+func (l *fairLimiter) acquire(ctx context.Context, session string) error {
+	l.mu.Lock()
+	if l.active < l.limit && len(l.turns) == 0 {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	if len(l.waiting[session]) == 0 {
+		l.turns = append(l.turns, session)
+	}
+	l.waiting[session] = append(l.waiting[session], ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		select {
+		case <-ready:
+			// Admitted while giving up; hand the slot on
+			l.releaseLocked()
+		default:
+			l.remove(session, ready)
+		}
+		return ctx.Err()
+	}
+}
+
+// release frees a slot, handing it to the session whose turn it is.
+func (l *fairLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.releaseLocked()
+}
+
+func (l *fairLimiter) releaseLocked() {
+	if len(l.turns) == 0 {
+		l.active--
+		return
+	}
+
+	session := l.turns[0]
+	queue := l.waiting[session]
+	if len(queue) == 1 {
+		delete(l.waiting, session)
+		l.turns = l.turns[1:]
+	} else {
+		l.waiting[session] = queue[1:]
+		l.turns = append(l.turns[1:], session)
+	}
+	close(queue[0])
+}
+
+// remove drops a request that stopped waiting.
+func (l *fairLimiter) remove(session string, ready chan struct{}) {
+	queue := slices.DeleteFunc(l.waiting[session], func(c chan struct{}) bool { return c == ready })
+	if len(queue) > 0 {
+		l.waiting[session] = queue
+		return
+	}
+	delete(l.waiting, session)
+	l.turns = slices.DeleteFunc(l.turns, func(s string) bool { return s == session })
+}
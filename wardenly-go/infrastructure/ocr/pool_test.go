@@ -0,0 +1,280 @@
+package ocr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTextServer returns an OCR backend that counts text requests and
+// reports healthy if healthy is true.
+func newTextServer(t *testing.T, healthy bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			if !healthy {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		case "/v1/texts":
+			count.Add(1)
+			w.Write([]byte(`{"lines":[{"text":"ok","confidence":1}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &count
+}
+
+func newTestPool(urls ...string) *Pool {
+	return NewPool(&PoolConfig{
+		BaseURLs:       urls,
+		MaxConcurrent:  2,
+		Timeout:        time.Second,
+		HealthInterval: time.Hour,
+		HealthTimeout:  time.Second,
+	})
+}
+
+func TestPool_RoundRobin(t *testing.T) {
+	a, countA := newTextServer(t, true)
+	b, countB := newTextServer(t, true)
+	down, countDown := newTextServer(t, false)
+	pool := newTestPool(a.URL, down.URL, b.URL)
+	defer pool.Close()
+
+	if !pool.IsHealthy() {
+		t.Fatal("IsHealthy() = false with healthy backends")
+	}
+	for i := 0; i < 6; i++ {
+		if _, err := pool.RecognizeText(context.Background(), []byte("png"), nil, nil); err != nil {
+			t.Fatalf("RecognizeText() error = %v", err)
+		}
+	}
+	if countA.Load()+countB.Load() != 6 || countA.Load() == 0 || countB.Load() == 0 {
+		t.Errorf("requests = %d and %d, want both healthy backends used", countA.Load(), countB.Load())
+	}
+	if countDown.Load() != 0 {
+		t.Errorf("unhealthy backend got %d requests", countDown.Load())
+	}
+}
+
+func TestPool_FailsOverUnreachableBackend(t *testing.T) {
+	gone, _ := newTextServer(t, true)
+	b, countB := newTextServer(t, true)
+	pool := newTestPool(gone.URL, b.URL)
+	defer pool.Close()
+	gone.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := pool.RecognizeText(context.Background(), []byte("png"), nil, nil); err != nil {
+			t.Fatalf("RecognizeText() error = %v", err)
+		}
+	}
+	if countB.Load() != 2 {
+		t.Errorf("reachable backend got %d requests, want 2", countB.Load())
+	}
+	if pool.backends[0].IsHealthy() {
+		t.Error("unreachable backend should be marked unhealthy")
+	}
+}
+
+func TestPool_NoHealthyBackend(t *testing.T) {
+	down, _ := newTextServer(t, false)
+	pool := newTestPool(down.URL)
+	defer pool.Close()
+
+	if pool.IsHealthy() {
+		t.Error("IsHealthy() = true without healthy backends")
+	}
+	var observed error
+	pool.observe = func(_ time.Duration, err error) { observed = err }
+	if _, err := pool.RecognizeText(context.Background(), []byte("png"), nil, nil); err == nil {
+		t.Error("RecognizeText() should fail without healthy backends")
+	}
+	if observed == nil {
+		t.Error("Observe was not told about the failed request")
+	}
+}
+
+// stubClient is a healthy Client that answers text requests.
+type stubClient struct {
+	Client
+	texts int
+}
+
+func (c *stubClient) IsHealthy() bool { return true }
+
+func (c *stubClient) Close() {}
+
+func (c *stubClient) RecognizeText(context.Context, []byte, *ROI, *TextOptions) (*TextResult, error) {
+	c.texts++
+	return &TextResult{Lines: []TextLine{{Text: "local"}}}, nil
+}
+
+func TestPool_FallsBackWithoutHealthyBackend(t *testing.T) {
+	down, countDown := newTextServer(t, false)
+	up, countUp := newTextServer(t, true)
+	pool := newTestPool(down.URL)
+	defer pool.Close()
+	fallback := &stubClient{}
+	pool.fallback = fallback
+
+	if !pool.IsHealthy() {
+		t.Error("IsHealthy() = false with a healthy fallback")
+	}
+	result, err := pool.RecognizeText(context.Background(), []byte("png"), nil, nil)
+	if err != nil || result.Lines[0].Text != "local" {
+		t.Fatalf("RecognizeText() = %+v, %v, want the fallback's result", result, err)
+	}
+	if fallback.texts != 1 || countDown.Load() != 0 {
+		t.Errorf("fallback got %d requests, backend %d", fallback.texts, countDown.Load())
+	}
+
+	healthy := newTestPool(up.URL)
+	defer healthy.Close()
+	healthy.fallback = fallback
+	if _, err := healthy.RecognizeText(context.Background(), []byte("png"), nil, nil); err != nil {
+		t.Fatalf("RecognizeText() error = %v", err)
+	}
+	if fallback.texts != 1 || countUp.Load() != 1 {
+		t.Errorf("a healthy backend should be used over the fallback")
+	}
+}
+
+func TestPoolConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvURLs, "http://a:8000/, http://b:8000,")
+	t.Setenv(EnvConcurrency, "8")
+	cfg, err := PoolConfigFromEnv()
+	if err != nil {
+		t.Fatalf("PoolConfigFromEnv() error = %v", err)
+	}
+	if len(cfg.BaseURLs) != 2 || cfg.BaseURLs[0] != "http://a:8000" || cfg.BaseURLs[1] != "http://b:8000" {
+		t.Errorf("BaseURLs = %v", cfg.BaseURLs)
+	}
+	if cfg.MaxConcurrent != 8 {
+		t.Errorf("MaxConcurrent = %d, want 8", cfg.MaxConcurrent)
+	}
+	if cfg.Tesseract != DefaultTesseract {
+		t.Errorf("Tesseract = %q, want %q", cfg.Tesseract, DefaultTesseract)
+	}
+	t.Setenv(EnvTesseract, "off")
+	if cfg, _ = PoolConfigFromEnv(); cfg.Tesseract != "" {
+		t.Errorf("Tesseract = %q, want the fallback off", cfg.Tesseract)
+	}
+
+	t.Setenv(EnvURLs, " , ")
+	t.Setenv(EnvConcurrency, "-1")
+	cfg, err = PoolConfigFromEnv()
+	if err == nil {
+		t.Error("PoolConfigFromEnv() should report invalid settings")
+	}
+	def := DefaultPoolConfig()
+	if len(cfg.BaseURLs) != 1 || cfg.BaseURLs[0] != def.BaseURLs[0] || cfg.MaxConcurrent != def.MaxConcurrent {
+		t.Errorf("invalid settings should keep defaults, got %+v", cfg)
+	}
+}
+
+func TestWithSession(t *testing.T) {
+	if got := sessionFrom(context.Background()); got != "" {
+		t.Errorf("sessionFrom() = %q, want empty", got)
+	}
+	if got := sessionFrom(WithSession(context.Background(), "s1")); got != "s1" {
+		t.Errorf("sessionFrom() = %q, want s1", got)
+	}
+}
+
+// waitQueued waits until n requests are waiting in the limiter.
+func waitQueued(t *testing.T, l *fairLimiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		l.mu.Lock()
+		queued := 0
+		for _, q := range l.waiting {
+			queued += len(q)
+		}
+		l.mu.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d queued requests", n)
+}
+
+func TestFairLimiter_TakesTurns(t *testing.T) {
+	l := newFairLimiter(1)
+	if err := l.acquire(context.Background(), "busy"); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	enqueue := func(session, name string, queued int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.acquire(context.Background(), session); err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			l.release()
+		}()
+		waitQueued(t, l, queued)
+	}
+	enqueue("a", "a1", 1)
+	enqueue("a", "a2", 2)
+	enqueue("a", "a3", 3)
+	enqueue("b", "b1", 4)
+
+	l.release()
+	wg.Wait()
+
+	want := []string{"a1", "b1", "a2", "a3"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+	if l.active != 0 {
+		t.Errorf("active = %d after all releases, want 0", l.active)
+	}
+}
+
+func TestFairLimiter_CancelWhileWaiting(t *testing.T) {
+	l := newFairLimiter(1)
+	if err := l.acquire(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- l.acquire(ctx, "b") }()
+	waitQueued(t, l, 1)
+	cancel()
+	if err := <-done; err == nil {
+		t.Error("acquire() should fail when its context is cancelled")
+	}
+
+	l.release()
+	if err := l.acquire(context.Background(), "c"); err != nil {
+		t.Errorf("acquire() after cancel error = %v", err)
+	}
+	if len(l.turns) != 0 || len(l.waiting) != 0 {
+		t.Errorf("cancelled request left in queue: turns %v", l.turns)
+	}
+}
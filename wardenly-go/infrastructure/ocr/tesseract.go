@@ -0,0 +1,267 @@
+package ocr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTesseract is the Tesseract command used when no OCR service is
+// healthy.
+const DefaultTesseract = "tesseract"
+
+// ratioCharset restricts local usage ratio recognition to "x/y".
+const ratioCharset = "0123456789/"
+
+var ratioPattern = regexp.MustCompile(`(\d+)\s*/\s*(\d+)`)
+
+// tesseractLanguages maps the service's language codes to Tesseract's.
+var tesseractLanguages = map[string]string{
+	"ch": "chi_sim",
+	"en": "eng",
+}
+
+// TesseractClient implements Client with a local Tesseract install, run
+// once per request. It is slower and less accurate than the OCR service,
+// and is meant as a fallback while no service is reachable.
+type TesseractClient struct {
+	path    string
+	timeout time.Duration
+	// run executes Tesseract with args, feeding it stdin; replaced in tests
+	run func(ctx context.Context, args []string, stdin []byte) ([]byte, error)
+}
+
+// NewTesseractClient creates a client that runs the Tesseract command at
+// path, or DefaultTesseract if path is empty. timeout bounds each run;
+// zero means no bound.
+func NewTesseractClient(path string, timeout time.Duration) *TesseractClient {
+	if path == "" {
+		path = DefaultTesseract
+	}
+	c := &TesseractClient{path: path, timeout: timeout}
+	c.run = c.exec
+	return c
+}
+
+func (c *TesseractClient) exec(ctx context.Context, args []string, stdin []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, c.path, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("tesseract failed: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("tesseract failed: %w", err)
+	}
+	return out, nil
+}
+
+// RecognizeUsageRatio recognizes a usage ratio from image bytes.
+func (c *TesseractClient) RecognizeUsageRatio(ctx context.Context, imageBytes []byte, roi *ROI) (*UsageRatioResult, error) {
+	start := time.Now()
+	text, err := c.recognize(ctx, imageBytes, roi, &TextOptions{Charset: ratioCharset}, true)
+	if err != nil {
+		return nil, err
+	}
+	raw := text.Text()
+	m := ratioPattern.FindStringSubmatch(raw)
+	if m == nil {
+		return nil, errNoRatio
+	}
+	numerator, _ := strconv.Atoi(m[1])
+	denominator, _ := strconv.Atoi(m[2])
+
+	var confidence float64
+	for _, line := range text.Lines {
+		confidence += line.Confidence
+	}
+	if len(text.Lines) > 0 {
+		confidence /= float64(len(text.Lines))
+	}
+	return &UsageRatioResult{
+		Numerator:   numerator,
+		Denominator: denominator,
+		RawText:     raw,
+		Confidence:  confidence,
+		ElapsedMs:   float64(time.Since(start).Microseconds()) / 1000,
+	}, nil
+}
+
+// RecognizeUsageRatioFromImage recognizes a usage ratio from an image.Image.
+func (c *TesseractClient) RecognizeUsageRatioFromImage(ctx context.Context, img image.Image, roi *ROI) (*UsageRatioResult, error) {
+	data, remoteROI, err := encodeImage(img, roi)
+	if err != nil {
+		return nil, err
+	}
+	return c.RecognizeUsageRatio(ctx, data, remoteROI)
+}
+
+// RecognizeMany recognizes a usage ratio in each region in turn.
+func (c *TesseractClient) RecognizeMany(ctx context.Context, img image.Image, rois []ROI) ([]*UsageRatioResult, error) {
+	return recognizeEach(ctx, c, img, rois)
+}
+
+// RecognizeText recognizes lines of free text, with their word boxes, from
+// image bytes. The language is mapped to Tesseract's ("ch" to chi_sim, "en"
+// to eng) and the charset becomes its character whitelist.
+func (c *TesseractClient) RecognizeText(ctx context.Context, imageBytes []byte, roi *ROI, opts *TextOptions) (*TextResult, error) {
+	return c.recognize(ctx, imageBytes, roi, opts, false)
+}
+
+// RecognizeTextFromImage recognizes lines of free text from an image.Image.
+// Boxes are in img's coordinates.
+func (c *TesseractClient) RecognizeTextFromImage(ctx context.Context, img image.Image, roi *ROI, opts *TextOptions) (*TextResult, error) {
+	data, remoteROI, err := encodeImage(img, roi)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.RecognizeText(ctx, data, remoteROI, opts)
+	if err != nil {
+		return nil, err
+	}
+	if roi != nil && remoteROI == nil {
+		result.offset(roi.X, roi.Y)
+	}
+	return result, nil
+}
+
+// recognize runs Tesseract on the image, cropped to roi, and parses its TSV
+// output. singleLine treats the image as one line of text.
+func (c *TesseractClient) recognize(ctx context.Context, imageBytes []byte, roi *ROI, opts *TextOptions, singleLine bool) (*TextResult, error) {
+	start := time.Now()
+	if roi != nil {
+		cropped, err := cropPNG(imageBytes, roi)
+		if err != nil {
+			return nil, err
+		}
+		imageBytes = cropped
+	}
+
+	args := []string{"stdin", "stdout"}
+	if singleLine {
+		args = append(args, "--psm", "7")
+	}
+	if opts != nil {
+		if opts.Language != "" {
+			lang, ok := tesseractLanguages[opts.Language]
+			if !ok {
+				lang = opts.Language
+			}
+			args = append(args, "-l", lang)
+		}
+		if opts.Charset != "" {
+			args = append(args, "-c", "tessedit_char_whitelist="+opts.Charset)
+		}
+	}
+	args = append(args, "tsv")
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	out, err := c.run(ctx, args, imageBytes)
+	if err != nil {
+		return nil, err
+	}
+	result := parseTSV(out)
+	if roi != nil {
+		result.offset(roi.X, roi.Y)
+	}
+	result.ElapsedMs = float64(time.Since(start).Microseconds()) / 1000
+	return result, nil
+}
+
+// cropPNG decodes a PNG and encodes the part of it inside roi.
+func cropPNG(data []byte, roi *ROI) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return nil, fmt.Errorf("cannot crop %T", img)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, sub.SubImage(image.Rect(roi.X, roi.Y, roi.X+roi.Width, roi.Y+roi.Height))); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// parseTSV groups the words of Tesseract's TSV output into lines. Rows
+// are level, page, block, paragraph, line, word, left, top, width,
+// height, conf and text; only word rows (level 5) with text are kept.
+func parseTSV(out []byte) *TextResult {
+	type lineKey struct{ block, par, line string }
+	result := &TextResult{}
+	index := make(map[lineKey]int)
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 12 || fields[0] != "5" {
+			continue
+		}
+		text := strings.TrimSpace(fields[11])
+		conf, err := strconv.ParseFloat(fields[10], 64)
+		if text == "" || err != nil || conf < 0 {
+			continue
+		}
+		var box Box
+		box.X, _ = strconv.Atoi(fields[6])
+		box.Y, _ = strconv.Atoi(fields[7])
+		box.Width, _ = strconv.Atoi(fields[8])
+		box.Height, _ = strconv.Atoi(fields[9])
+		word := TextWord{Text: text, Box: box, Confidence: conf / 100}
+
+		key := lineKey{fields[2], fields[3], fields[4]}
+		i, ok := index[key]
+		if !ok {
+			i = len(result.Lines)
+			index[key] = i
+			result.Lines = append(result.Lines, TextLine{})
+		}
+		result.Lines[i].Words = append(result.Lines[i].Words, word)
+	}
+
+	for i := range result.Lines {
+		line := &result.Lines[i]
+		texts := make([]string, len(line.Words))
+		var total float64
+		bounds := image.Rectangle{}
+		for j, w := range line.Words {
+			texts[j] = w.Text
+			total += w.Confidence
+			bounds = bounds.Union(image.Rect(w.Box.X, w.Box.Y, w.Box.X+w.Box.Width, w.Box.Y+w.Box.Height))
+		}
+		line.Text = strings.Join(texts, " ")
+		line.Confidence = total / float64(len(line.Words))
+		line.Box = Box{X: bounds.Min.X, Y: bounds.Min.Y, Width: bounds.Dx(), Height: bounds.Dy()}
+	}
+	return result
+}
+
+// IsHealthy returns true if the Tesseract command can be found.
+func (c *TesseractClient) IsHealthy() bool {
+	_, err := exec.LookPath(c.path)
+	return err == nil
+}
+
+// Close releases resources. Tesseract runs per request, so there are none.
+func (c *TesseractClient) Close() {}
+
+// Ensure TesseractClient implements Client
+var _ Client = (*TesseractClient)(nil)
@@ -0,0 +1,105 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const tesseractTSV = "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+	"1\t1\t0\t0\t0\t0\t0\t0\t200\t60\t-1\t\n" +
+	"4\t1\t1\t1\t1\t0\t10\t5\t90\t20\t-1\t\n" +
+	"5\t1\t1\t1\t1\t1\t10\t5\t40\t20\t96.5\tGold:\n" +
+	"5\t1\t1\t1\t1\t2\t60\t6\t40\t18\t90.5\t1,250\n" +
+	"5\t1\t1\t1\t2\t1\t10\t35\t30\t20\t-1\t \n" +
+	"5\t1\t1\t1\t2\t2\t50\t35\t30\t20\t80\tEnergy\n"
+
+func TestParseTSV(t *testing.T) {
+	result := parseTSV([]byte(tesseractTSV))
+	if len(result.Lines) != 2 {
+		t.Fatalf("lines = %+v, want 2", result.Lines)
+	}
+	first := result.Lines[0]
+	if first.Text != "Gold: 1,250" || len(first.Words) != 2 {
+		t.Errorf("first line = %+v", first)
+	}
+	if first.Confidence < 0.934 || first.Confidence > 0.936 {
+		t.Errorf("confidence = %v, want the words' mean", first.Confidence)
+	}
+	if want := (Box{X: 10, Y: 5, Width: 90, Height: 20}); first.Box != want {
+		t.Errorf("line box = %+v, want %+v", first.Box, want)
+	}
+	if want := (Box{X: 60, Y: 6, Width: 40, Height: 18}); first.Words[1].Box != want {
+		t.Errorf("word box = %+v, want %+v", first.Words[1].Box, want)
+	}
+	if second := result.Lines[1]; second.Text != "Energy" || len(second.Words) != 1 {
+		t.Errorf("second line = %+v, want blank words dropped", second)
+	}
+}
+
+// fakeTesseract returns a client whose runs record their arguments and
+// image size and print out.
+func fakeTesseract(out string, args *[]string, size *image.Point) *TesseractClient {
+	c := NewTesseractClient("", time.Second)
+	c.run = func(_ context.Context, a []string, stdin []byte) ([]byte, error) {
+		*args = a
+		if img, err := png.Decode(bytes.NewReader(stdin)); err == nil {
+			*size = img.Bounds().Size()
+		}
+		return []byte(out), nil
+	}
+	return c
+}
+
+func TestTesseractClient_RecognizeText(t *testing.T) {
+	var args []string
+	var size image.Point
+	c := fakeTesseract(tesseractTSV, &args, &size)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 300))); err != nil {
+		t.Fatal(err)
+	}
+	result, err := c.RecognizeText(context.Background(), buf.Bytes(), &ROI{X: 100, Y: 50, Width: 200, Height: 60},
+		&TextOptions{Language: "ch", Charset: "0123456789"})
+	if err != nil {
+		t.Fatalf("RecognizeText() error = %v", err)
+	}
+	if size != image.Pt(200, 60) {
+		t.Errorf("image size = %v, want cropped to the ROI", size)
+	}
+	got := strings.Join(args, " ")
+	if !strings.Contains(got, "-l chi_sim") || !strings.Contains(got, "tessedit_char_whitelist=0123456789") {
+		t.Errorf("args = %q, want language and whitelist", got)
+	}
+	if box := result.Lines[0].Box; box.X != 110 || box.Y != 55 {
+		t.Errorf("line box = %+v, want it in image coordinates", box)
+	}
+}
+
+func TestTesseractClient_RecognizeUsageRatio(t *testing.T) {
+	var args []string
+	var size image.Point
+	c := fakeTesseract("5\t1\t1\t1\t1\t1\t0\t0\t30\t10\t88\t3/10\n", &args, &size)
+
+	result, err := c.RecognizeUsageRatioFromImage(context.Background(), image.NewRGBA(image.Rect(0, 0, 40, 20)), nil)
+	if err != nil {
+		t.Fatalf("RecognizeUsageRatioFromImage() error = %v", err)
+	}
+	if result.Numerator != 3 || result.Denominator != 10 || result.Confidence != 0.88 {
+		t.Errorf("result = %+v, want 3/10", result)
+	}
+	if !slices.Contains(args, "7") || !slices.Contains(args, "tessedit_char_whitelist="+ratioCharset) {
+		t.Errorf("args = %v, want a single line of ratio characters", args)
+	}
+
+	c = fakeTesseract("5\t1\t1\t1\t1\t1\t0\t0\t30\t10\t88\tx\n", &args, &size)
+	if _, err := c.RecognizeUsageRatioFromImage(context.Background(), image.NewRGBA(image.Rect(0, 0, 40, 20)), nil); err == nil {
+		t.Error("RecognizeUsageRatioFromImage() should fail without a ratio")
+	}
+}
@@ -0,0 +1,78 @@
+// Package presence publishes a record per running session, refreshed by a
+// heartbeat, so dashboards and other Wardenly instances sharing the store
+// can see which sessions run on which machine. Records carry their update
+// time and expire once the heartbeat stops, for example after a crash.
+package presence
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Environment variables overriding the presence section of the config file.
+const (
+	EnvDisabled = "WARDENLY_PRESENCE_DISABLED"
+	EnvMachine  = "WARDENLY_PRESENCE_MACHINE"
+	EnvInterval = "WARDENLY_PRESENCE_INTERVAL"
+	EnvTTL      = "WARDENLY_PRESENCE_TTL"
+)
+
+// Default heartbeat settings.
+const (
+	DefaultInterval = 30 * time.Second
+	DefaultTTL      = 2 * time.Minute
+)
+
+// Record describes a session running on a machine.
+type Record struct {
+	Machine     string
+	SessionID   string
+	AccountID   string
+	AccountName string
+	State       string
+	// Script is the running script, empty if none.
+	Script    string
+	StartedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Store keeps presence records of all machines.
+type Store interface {
+	// Put creates or refreshes records.
+	Put(ctx context.Context, records []Record) error
+	// Remove deletes the record of a session on a machine.
+	Remove(ctx context.Context, machine, sessionID string) error
+	// List returns the records that have not expired, by machine and
+	// account.
+	List(ctx context.Context) ([]Record, error)
+}
+
+// Config holds publisher configuration.
+type Config struct {
+	// Disabled turns presence records off. They are on by default.
+	Disabled bool
+	// Machine names this instance in its records.
+	// If empty, defaults to the host name.
+	Machine string
+	// Interval is how often records are refreshed.
+	Interval time.Duration
+	// TTL is how long a record lives without being refreshed. It should
+	// span a few intervals.
+	TTL    time.Duration
+	Logger *slog.Logger
+}
+
+// Enabled reports whether the publisher should be started.
+func (c *Config) Enabled() bool {
+	return c != nil && !c.Disabled
+}
+
+// DefaultMachine returns the host name, or "unknown" if it is unavailable.
+func DefaultMachine() string {
+	if name, err := os.Hostname(); err == nil && name != "" {
+		return name
+	}
+	return "unknown"
+}
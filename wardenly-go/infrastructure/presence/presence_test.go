@@ -0,0 +1,135 @@
+package presence
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+	"wardenly-go/core/state"
+)
+
+// memStore is an in-memory Store.
+type memStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+	fail    bool
+}
+
+func newMemStore() *memStore {
+	return &memStore{records: make(map[string]Record)}
+}
+
+func (s *memStore) Put(ctx context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("store down")
+	}
+	for _, rec := range records {
+		s.records[rec.Machine+"/"+rec.SessionID] = rec
+	}
+	return nil
+}
+
+func (s *memStore) Remove(ctx context.Context, machine, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, machine+"/"+sessionID)
+	return nil
+}
+
+func (s *memStore) List(ctx context.Context) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Record
+	for _, rec := range s.records {
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+func (s *memStore) get(key string) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[key]
+	return rec, ok
+}
+
+// waitFor polls until cond holds or a deadline passes.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before the deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPublisher_TracksSessions(t *testing.T) {
+	bus := eventbus.New(10)
+	defer bus.Close()
+	store := newMemStore()
+	p := Start(&Config{Machine: "rig-1", Interval: 20 * time.Millisecond}, bus, store)
+
+	bus.Publish(event.NewSessionStarted("s1", "a1", "alice"))
+	bus.Publish(event.NewSessionStateChanged("s1", state.StateLoggingIn, state.StateReady))
+	bus.Publish(event.NewScriptStarted("s1", "daily"))
+	bus.Publish(event.NewSessionStarted("s2", "a2", "bob"))
+	waitFor(t, func() bool {
+		rec, ok := store.get("rig-1/s1")
+		_, ok2 := store.get("rig-1/s2")
+		return ok && ok2 && rec.Script == "daily"
+	})
+	rec, _ := store.get("rig-1/s1")
+	if rec.AccountName != "alice" || rec.State != "Ready" || rec.StartedAt.IsZero() {
+		t.Errorf("record = %+v", rec)
+	}
+
+	// Heartbeats keep refreshing the records
+	first := rec.UpdatedAt
+	waitFor(t, func() bool {
+		rec, _ := store.get("rig-1/s1")
+		return rec.UpdatedAt.After(first)
+	})
+
+	bus.Publish(event.NewScriptStopped("s1", "daily", event.StopReasonNormal, nil))
+	bus.Publish(event.NewSessionStopped("s2", nil))
+	waitFor(t, func() bool {
+		rec, _ := store.get("rig-1/s1")
+		_, ok := store.get("rig-1/s2")
+		return rec.Script == "" && !ok
+	})
+
+	// Stop removes the records still running
+	p.Stop()
+	if records, _ := store.List(context.Background()); len(records) != 0 {
+		t.Errorf("records after Stop = %+v", records)
+	}
+}
+
+func TestPublisher_StoreFailure(t *testing.T) {
+	bus := eventbus.New(10)
+	defer bus.Close()
+	store := newMemStore()
+	store.fail = true
+	p := Start(&Config{Machine: "rig-1", Interval: 10 * time.Millisecond}, bus, store)
+	defer p.Stop()
+
+	bus.Publish(event.NewSessionStarted("s1", "a1", "alice"))
+	time.Sleep(30 * time.Millisecond)
+
+	// The next heartbeat writes the record once the store is back
+	store.mu.Lock()
+	store.fail = false
+	store.mu.Unlock()
+	waitFor(t, func() bool {
+		_, ok := store.get("rig-1/s1")
+		return ok
+	})
+}
@@ -0,0 +1,201 @@
+package presence
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+	"wardenly-go/core/state"
+)
+
+const (
+	// queueSize is the number of events buffered for the writer. Events
+	// beyond it are dropped rather than slowing the bus down; the next
+	// state change or heartbeat catches up.
+	queueSize = 256
+	// storeTimeout bounds a single store call.
+	storeTimeout = 5 * time.Second
+)
+
+// Publisher keeps the presence records of this machine's sessions in a
+// store, from SessionStarted until the session stops.
+type Publisher struct {
+	config *Config
+	bus    eventbus.EventBus
+	store  Store
+	subID  string
+
+	queue *eventbus.Queue
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	// Only touched by the writer goroutine
+	records map[string]*Record // Session ID -> record
+	failing bool               // The last store call failed
+}
+
+// Start begins publishing the sessions announced on bus to store. Stop
+// must be called to remove the records on shutdown.
+func Start(cfg *Config, bus eventbus.EventBus, store Store) *Publisher {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.Machine == "" {
+		cfg.Machine = DefaultMachine()
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+
+	p := &Publisher{
+		config:  cfg,
+		bus:     bus,
+		store:   store,
+		queue:   eventbus.NewQueue(queueSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		records: make(map[string]*Record),
+	}
+	p.subID = bus.Subscribe(p.enqueue)
+	go p.run()
+
+	cfg.Logger.Info("Session presence started", "machine", cfg.Machine, "interval", cfg.Interval)
+	return p
+}
+
+// Stop unsubscribes and removes the records of sessions still running.
+func (p *Publisher) Stop() {
+	p.once.Do(func() {
+		p.bus.Unsubscribe(p.subID)
+		close(p.stop)
+		<-p.done
+		if n := p.queue.Dropped(); n > 0 {
+			p.config.Logger.Warn("Session presence dropped events", "count", n)
+		}
+	})
+}
+
+// enqueue runs on the bus dispatch goroutine and must not block.
+func (p *Publisher) enqueue(e event.Event) {
+	switch e.(type) {
+	case *event.SessionStarted, *event.SessionStopped, *event.SessionStateChanged,
+		*event.ScriptStarted, *event.ScriptStopped:
+	default:
+		return
+	}
+	p.queue.Push(e)
+}
+
+func (p *Publisher) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case q := <-p.queue.Events():
+			p.handle(q.Event)
+		case <-ticker.C:
+			p.heartbeat()
+		case <-p.stop:
+			p.queue.Drain(func(q eventbus.Queued) { p.handle(q.Event) })
+			p.removeAll()
+			return
+		}
+	}
+}
+
+func (p *Publisher) handle(e event.Event) {
+	switch e := e.(type) {
+	case *event.SessionStarted:
+		now := time.Now()
+		rec := &Record{
+			Machine:     p.config.Machine,
+			SessionID:   e.SessionID(),
+			AccountID:   e.AccountID,
+			AccountName: e.AccountName,
+			State:       state.StateStarting.String(),
+			StartedAt:   now,
+		}
+		p.records[rec.SessionID] = rec
+		p.put(rec)
+	case *event.SessionStateChanged:
+		if e.NewState == state.StateStopped {
+			p.remove(e.SessionID())
+			return
+		}
+		if rec := p.records[e.SessionID()]; rec != nil {
+			rec.State = e.NewState.String()
+			p.put(rec)
+		}
+	case *event.ScriptStarted:
+		if rec := p.records[e.SessionID()]; rec != nil {
+			rec.Script = e.ScriptName
+			p.put(rec)
+		}
+	case *event.ScriptStopped:
+		if rec := p.records[e.SessionID()]; rec != nil && rec.Script == e.ScriptName {
+			rec.Script = ""
+			p.put(rec)
+		}
+	case *event.SessionStopped:
+		p.remove(e.SessionID())
+	}
+}
+
+// heartbeat refreshes all records so they don't expire.
+func (p *Publisher) heartbeat() {
+	if len(p.records) == 0 {
+		return
+	}
+	now := time.Now()
+	records := make([]Record, 0, len(p.records))
+	for _, rec := range p.records {
+		rec.UpdatedAt = now
+		records = append(records, *rec)
+	}
+	p.write(func(ctx context.Context) error { return p.store.Put(ctx, records) })
+}
+
+func (p *Publisher) put(rec *Record) {
+	rec.UpdatedAt = time.Now()
+	records := []Record{*rec}
+	p.write(func(ctx context.Context) error { return p.store.Put(ctx, records) })
+}
+
+func (p *Publisher) remove(sessionID string) {
+	if _, ok := p.records[sessionID]; !ok {
+		return
+	}
+	delete(p.records, sessionID)
+	p.write(func(ctx context.Context) error { return p.store.Remove(ctx, p.config.Machine, sessionID) })
+}
+
+func (p *Publisher) removeAll() {
+	for sessionID := range p.records {
+		p.remove(sessionID)
+	}
+}
+
+// write runs a store call, logging the first failure of a streak and the
+// recovery, so an unreachable store doesn't flood the log every heartbeat.
+func (p *Publisher) write(call func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := call(ctx); err != nil {
+		if !p.failing {
+			p.config.Logger.Warn("Failed to write session presence", "error", err)
+		}
+		p.failing = true
+		return
+	}
+	if p.failing {
+		p.config.Logger.Info("Session presence writes recovered")
+		p.failing = false
+	}
+}
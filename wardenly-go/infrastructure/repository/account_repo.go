@@ -4,51 +4,104 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"wardenly-go/domain/account"
+	"wardenly-go/infrastructure/crypto"
 )
 
-// accountDocument is the MongoDB document structure for accounts.
+// accountDocument is the stored document structure for accounts, in
+// MongoDB and in the JSON file store.
 type accountDocument struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty"`
-	RoleName string             `bson:"role_name"`
-	UserName string             `bson:"user_name"`
-	Password string             `bson:"password"`
-	Ranking  int                `bson:"ranking"`
-	ServerID int                `bson:"server_id"`
-	Cookies  []cookieDocument   `bson:"cookies,omitempty"`
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	RoleName string             `bson:"role_name" json:"role_name"`
+	UserName string             `bson:"user_name" json:"user_name"`
+	Password string             `bson:"password" json:"password"`
+	Ranking  int                `bson:"ranking" json:"ranking"`
+	ServerID int                `bson:"server_id" json:"server_id"`
+	Cookies  []cookieDocument   `bson:"cookies,omitempty" json:"cookies,omitempty"`
+
+	URLTemplate string `bson:"url_template" json:"url_template"` // empty uses the login profile's
+
+	ScriptParams   []scriptParamsDocument `bson:"remembered_params,omitempty" json:"remembered_params,omitempty"`
+	AllowedScripts []string               `bson:"allowed_scripts" json:"allowed_scripts"`
+	BlockedScripts []string               `bson:"blocked_scripts" json:"blocked_scripts"`
+	Archived       bool                   `bson:"archived" json:"archived"`
+	Proxy          *proxyDocument         `bson:"proxy" json:"proxy"`     // null clears it on update
+	Browser        *browserDocument       `bson:"browser" json:"browser"` // null clears it on update
+	Label          string                 `bson:"label" json:"label"`
+	LabelColor     string                 `bson:"label_color" json:"label_color"`
+
+	SetupScript      string     `bson:"setup_script" json:"setup_script"`
+	SetupCompletedAt *time.Time `bson:"setup_completed_at" json:"setup_completed_at"` // null clears it on update
 }
 
-// cookieDocument is the MongoDB document structure for cookies.
+// scriptParamsDocument holds the remembered prompt values of one script.
+// They are stored as a list rather than keyed by script name, since a name
+// containing dots or starting with $ isn't a valid MongoDB field path.
+type scriptParamsDocument struct {
+	Script string            `bson:"script" json:"script"`
+	Params map[string]string `bson:"params" json:"params"`
+}
+
+// proxyDocument is the document structure for an account proxy.
+type proxyDocument struct {
+	Host     string `bson:"host" json:"host"`
+	Port     int    `bson:"port" json:"port"`
+	Username string `bson:"username,omitempty" json:"username,omitempty"`
+	Password string `bson:"password,omitempty" json:"password,omitempty"`
+}
+
+// browserDocument is the document structure for account browser settings.
+type browserDocument struct {
+	Headless       *bool  `bson:"headless,omitempty" json:"headless,omitempty"`
+	ViewportWidth  int    `bson:"viewport_width,omitempty" json:"viewport_width,omitempty"`
+	ViewportHeight int    `bson:"viewport_height,omitempty" json:"viewport_height,omitempty"`
+	UserDataDir    string `bson:"user_data_dir,omitempty" json:"user_data_dir,omitempty"`
+
+	PageScale         float64 `bson:"page_scale,omitempty" json:"page_scale,omitempty"`
+	DeviceScaleFactor float64 `bson:"device_scale_factor,omitempty" json:"device_scale_factor,omitempty"`
+	Mobile            bool    `bson:"mobile,omitempty" json:"mobile,omitempty"`
+}
+
+// cookieDocument is the document structure for cookies.
 type cookieDocument struct {
-	Name         string `bson:"name"`
-	Value        string `bson:"value"`
-	Domain       string `bson:"domain"`
-	Path         string `bson:"path"`
-	HTTPOnly     bool   `bson:"http_only"`
-	Secure       bool   `bson:"secure"`
-	SourcePort   int    `bson:"source_port"`
-	SourceScheme string `bson:"source_scheme,omitempty"`
-	Priority     string `bson:"priority,omitempty"`
+	Name         string    `bson:"name" json:"name"`
+	Value        string    `bson:"value" json:"value"`
+	Domain       string    `bson:"domain" json:"domain"`
+	Path         string    `bson:"path" json:"path"`
+	HTTPOnly     bool      `bson:"http_only" json:"http_only"`
+	Secure       bool      `bson:"secure" json:"secure"`
+	SourcePort   int       `bson:"source_port" json:"source_port"`
+	SourceScheme string    `bson:"source_scheme,omitempty" json:"source_scheme,omitempty"`
+	Priority     string    `bson:"priority,omitempty" json:"priority,omitempty"`
+	Expires      time.Time `bson:"expires,omitempty" json:"expires,omitzero"`
 }
 
 // MongoAccountRepository implements account.Repository using MongoDB.
+// Game and proxy passwords and cookie values are encrypted at rest with
+// secrets and decrypted transparently on read.
 type MongoAccountRepository struct {
 	collection *mongo.Collection
+	secrets    *crypto.Cipher
 	logger     *slog.Logger
 }
 
 // NewMongoAccountRepository creates a new MongoDB-based account repository.
-func NewMongoAccountRepository(db *MongoDB, logger *slog.Logger) *MongoAccountRepository {
+// If secrets is nil, passwords and cookies are stored in plaintext.
+func NewMongoAccountRepository(db *MongoDB, secrets *crypto.Cipher, logger *slog.Logger) *MongoAccountRepository {
 	if logger == nil {
 		logger = slog.Default()
 	}
 	return &MongoAccountRepository{
 		collection: db.Collection("account"),
+		secrets:    secrets,
 		logger:     logger,
 	}
 }
@@ -68,6 +121,9 @@ func (r *MongoAccountRepository) FindByID(ctx context.Context, id string) (*acco
 		}
 		return nil, fmt.Errorf("failed to find account: %w", err)
 	}
+	if err := openSecrets(r.secrets, &doc); err != nil {
+		return nil, err
+	}
 
 	return documentToAccount(&doc), nil
 }
@@ -87,6 +143,9 @@ func (r *MongoAccountRepository) FindAll(ctx context.Context) ([]*account.Accoun
 
 	accounts := make([]*account.Account, len(docs))
 	for i, doc := range docs {
+		if err := openSecrets(r.secrets, &doc); err != nil {
+			return nil, err
+		}
 		accounts[i] = documentToAccount(&doc)
 	}
 
@@ -96,6 +155,9 @@ func (r *MongoAccountRepository) FindAll(ctx context.Context) ([]*account.Accoun
 // Insert creates a new account.
 func (r *MongoAccountRepository) Insert(ctx context.Context, acc *account.Account) error {
 	doc := accountToDocument(acc)
+	if err := sealSecrets(r.secrets, doc); err != nil {
+		return err
+	}
 	result, err := r.collection.InsertOne(ctx, doc)
 	if err != nil {
 		return fmt.Errorf("failed to insert account: %w", err)
@@ -119,6 +181,9 @@ func (r *MongoAccountRepository) Update(ctx context.Context, acc *account.Accoun
 
 	doc := accountToDocument(acc)
 	doc.ID = objectID
+	if err := sealSecrets(r.secrets, doc); err != nil {
+		return err
+	}
 
 	filter := bson.M{"_id": objectID}
 	update := bson.M{"$set": doc}
@@ -142,19 +207,9 @@ func (r *MongoAccountRepository) UpdateCookies(ctx context.Context, id string, c
 		return fmt.Errorf("invalid ID format: %w", err)
 	}
 
-	cookieDocs := make([]cookieDocument, len(cookies))
-	for i, c := range cookies {
-		cookieDocs[i] = cookieDocument{
-			Name:         c.Name,
-			Value:        c.Value,
-			Domain:       c.Domain,
-			Path:         c.Path,
-			HTTPOnly:     c.HTTPOnly,
-			Secure:       c.Secure,
-			SourcePort:   c.SourcePort,
-			SourceScheme: c.SourceScheme,
-			Priority:     c.Priority,
-		}
+	cookieDocs := cookiesToDocuments(cookies)
+	if err := sealCookies(r.secrets, cookieDocs); err != nil {
+		return err
 	}
 
 	filter := bson.M{"_id": objectID}
@@ -173,6 +228,46 @@ func (r *MongoAccountRepository) UpdateCookies(ctx context.Context, id string, c
 	return nil
 }
 
+// UpdateScriptParams stores the remembered prompt values for one script.
+func (r *MongoAccountRepository) UpdateScriptParams(ctx context.Context, id, scriptName string, params map[string]string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	// Replace the script's entry, or append one if there is none. Another
+	// writer can append the entry between the two, so try again once.
+	found := false
+	for range 2 {
+		filter := bson.M{"_id": objectID, "remembered_params.script": scriptName}
+		update := bson.M{"$set": bson.M{"remembered_params.$.params": params}}
+		result, err := r.collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return fmt.Errorf("failed to update script params: %w", err)
+		}
+		if result.MatchedCount > 0 {
+			found = true
+			break
+		}
+
+		filter = bson.M{"_id": objectID, "remembered_params.script": bson.M{"$ne": scriptName}}
+		update = bson.M{"$push": bson.M{"remembered_params": scriptParamsDocument{Script: scriptName, Params: params}}}
+		if result, err = r.collection.UpdateOne(ctx, filter, update); err != nil {
+			return fmt.Errorf("failed to update script params: %w", err)
+		}
+		if result.MatchedCount > 0 {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return account.ErrAccountNotFound
+	}
+
+	r.logger.Info("Script params updated", "id", id, "script", scriptName)
+	return nil
+}
+
 // Delete removes an account by its identifier.
 func (r *MongoAccountRepository) Delete(ctx context.Context, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -194,6 +289,129 @@ func (r *MongoAccountRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// EncryptSecrets encrypts game and proxy passwords and cookie values that
+// are still stored in plaintext, e.g. written before encryption was enabled,
+// and returns the number of accounts updated. It does nothing without a
+// cipher.
+func (r *MongoAccountRepository) EncryptSecrets(ctx context.Context) (int, error) {
+	if r.secrets == nil {
+		return 0, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.D{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find accounts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []accountDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return 0, fmt.Errorf("failed to decode accounts: %w", err)
+	}
+
+	updated := 0
+	for _, doc := range docs {
+		if !hasPlaintextSecrets(&doc) {
+			continue
+		}
+		if err := resealSecrets(r.secrets, &doc); err != nil {
+			return updated, err
+		}
+		// Only the secret fields, so concurrent edits of other fields are kept
+		set := bson.M{"password": doc.Password, "cookies": doc.Cookies}
+		if doc.Proxy != nil && doc.Proxy.Password != "" {
+			set["proxy.password"] = doc.Proxy.Password
+		}
+		update := bson.M{"$set": set}
+		if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": doc.ID}, update); err != nil {
+			return updated, fmt.Errorf("failed to encrypt account %s: %w", doc.ID.Hex(), err)
+		}
+		updated++
+	}
+
+	if updated > 0 {
+		r.logger.Info("Account secrets encrypted", "count", updated)
+	}
+	return updated, nil
+}
+
+// hasPlaintextSecrets returns true if a stored account has a password or
+// cookie value that isn't encrypted.
+func hasPlaintextSecrets(doc *accountDocument) bool {
+	if doc.Password != "" && !crypto.IsEncrypted(doc.Password) {
+		return true
+	}
+	if doc.Proxy != nil && doc.Proxy.Password != "" && !crypto.IsEncrypted(doc.Proxy.Password) {
+		return true
+	}
+	for _, c := range doc.Cookies {
+		if c.Value != "" && !crypto.IsEncrypted(c.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// sealSecrets encrypts the passwords and cookie values of a document
+// before it is written. The values must be plaintext.
+func sealSecrets(secrets *crypto.Cipher, doc *accountDocument) error {
+	password, err := secrets.Encrypt(doc.Password)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt password: %w", err)
+	}
+	doc.Password = password
+	if doc.Proxy != nil {
+		if doc.Proxy.Password, err = secrets.Encrypt(doc.Proxy.Password); err != nil {
+			return fmt.Errorf("failed to encrypt proxy password: %w", err)
+		}
+	}
+	return sealCookies(secrets, doc.Cookies)
+}
+
+// resealSecrets encrypts a stored document whose secrets may be partly
+// encrypted already, by decrypting them first.
+func resealSecrets(secrets *crypto.Cipher, doc *accountDocument) error {
+	if err := openSecrets(secrets, doc); err != nil {
+		return err
+	}
+	return sealSecrets(secrets, doc)
+}
+
+// sealCookies encrypts cookie values in place.
+func sealCookies(secrets *crypto.Cipher, cookies []cookieDocument) error {
+	for i := range cookies {
+		value, err := secrets.Encrypt(cookies[i].Value)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt cookie %s: %w", cookies[i].Name, err)
+		}
+		cookies[i].Value = value
+	}
+	return nil
+}
+
+// openSecrets decrypts the passwords and cookie values of a document that
+// was read. Plaintext values are kept as they are.
+func openSecrets(secrets *crypto.Cipher, doc *accountDocument) error {
+	password, err := secrets.Decrypt(doc.Password)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt password of account %s: %w", doc.ID.Hex(), err)
+	}
+	doc.Password = password
+	if doc.Proxy != nil {
+		if doc.Proxy.Password, err = secrets.Decrypt(doc.Proxy.Password); err != nil {
+			return fmt.Errorf("failed to decrypt proxy password of account %s: %w", doc.ID.Hex(), err)
+		}
+	}
+	for i := range doc.Cookies {
+		value, err := secrets.Decrypt(doc.Cookies[i].Value)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt cookie %s of account %s: %w", doc.Cookies[i].Name, doc.ID.Hex(), err)
+		}
+		doc.Cookies[i].Value = value
+	}
+	return nil
+}
+
 // documentToAccount converts a MongoDB document to a domain Account.
 func documentToAccount(doc *accountDocument) *account.Account {
 	acc := &account.Account{
@@ -203,6 +421,43 @@ func documentToAccount(doc *accountDocument) *account.Account {
 		Password: doc.Password,
 		Ranking:  doc.Ranking,
 		ServerID: doc.ServerID,
+
+		URLTemplate: doc.URLTemplate,
+
+		ScriptParams:   documentsToScriptParams(doc.ScriptParams),
+		AllowedScripts: doc.AllowedScripts,
+		BlockedScripts: doc.BlockedScripts,
+		Archived:       doc.Archived,
+		Label:          doc.Label,
+		LabelColor:     account.LabelColor(doc.LabelColor),
+
+		SetupScript: doc.SetupScript,
+	}
+
+	if doc.SetupCompletedAt != nil {
+		acc.SetupCompletedAt = *doc.SetupCompletedAt
+	}
+
+	if doc.Proxy != nil {
+		acc.Proxy = &account.Proxy{
+			Host:     doc.Proxy.Host,
+			Port:     doc.Proxy.Port,
+			Username: doc.Proxy.Username,
+			Password: doc.Proxy.Password,
+		}
+	}
+
+	if doc.Browser != nil {
+		acc.Browser = &account.BrowserSettings{
+			Headless:       doc.Browser.Headless,
+			ViewportWidth:  doc.Browser.ViewportWidth,
+			ViewportHeight: doc.Browser.ViewportHeight,
+			UserDataDir:    doc.Browser.UserDataDir,
+
+			PageScale:         doc.Browser.PageScale,
+			DeviceScaleFactor: doc.Browser.DeviceScaleFactor,
+			Mobile:            doc.Browser.Mobile,
+		}
 	}
 
 	if len(doc.Cookies) > 0 {
@@ -218,6 +473,7 @@ func documentToAccount(doc *accountDocument) *account.Account {
 				SourcePort:   c.SourcePort,
 				SourceScheme: c.SourceScheme,
 				Priority:     c.Priority,
+				Expires:      c.Expires,
 			}
 		}
 	}
@@ -233,6 +489,22 @@ func accountToDocument(acc *account.Account) *accountDocument {
 		Password: acc.Password,
 		Ranking:  acc.Ranking,
 		ServerID: acc.ServerID,
+
+		URLTemplate: acc.URLTemplate,
+
+		ScriptParams:   scriptParamsToDocuments(acc.ScriptParams),
+		AllowedScripts: acc.AllowedScripts,
+		BlockedScripts: acc.BlockedScripts,
+		Archived:       acc.Archived,
+		Label:          acc.Label,
+		LabelColor:     string(acc.LabelColor),
+
+		SetupScript: acc.SetupScript,
+	}
+
+	if !acc.SetupCompletedAt.IsZero() {
+		completed := acc.SetupCompletedAt
+		doc.SetupCompletedAt = &completed
 	}
 
 	if acc.ID != "" {
@@ -241,25 +513,80 @@ func accountToDocument(acc *account.Account) *accountDocument {
 		}
 	}
 
-	if len(acc.Cookies) > 0 {
-		doc.Cookies = make([]cookieDocument, len(acc.Cookies))
-		for i, c := range acc.Cookies {
-			doc.Cookies[i] = cookieDocument{
-				Name:         c.Name,
-				Value:        c.Value,
-				Domain:       c.Domain,
-				Path:         c.Path,
-				HTTPOnly:     c.HTTPOnly,
-				Secure:       c.Secure,
-				SourcePort:   c.SourcePort,
-				SourceScheme: c.SourceScheme,
-				Priority:     c.Priority,
-			}
+	if acc.Proxy.Enabled() {
+		doc.Proxy = &proxyDocument{
+			Host:     acc.Proxy.Host,
+			Port:     acc.Proxy.Port,
+			Username: acc.Proxy.Username,
+			Password: acc.Proxy.Password,
+		}
+	}
+
+	if !acc.Browser.IsZero() {
+		doc.Browser = &browserDocument{
+			Headless:       acc.Browser.Headless,
+			ViewportWidth:  acc.Browser.ViewportWidth,
+			ViewportHeight: acc.Browser.ViewportHeight,
+			UserDataDir:    acc.Browser.UserDataDir,
+
+			PageScale:         acc.Browser.PageScale,
+			DeviceScaleFactor: acc.Browser.DeviceScaleFactor,
+			Mobile:            acc.Browser.Mobile,
 		}
 	}
 
+	if len(acc.Cookies) > 0 {
+		doc.Cookies = cookiesToDocuments(acc.Cookies)
+	}
+
 	return doc
 }
 
+// cookiesToDocuments converts domain cookies to documents.
+func cookiesToDocuments(cookies []account.Cookie) []cookieDocument {
+	docs := make([]cookieDocument, len(cookies))
+	for i, c := range cookies {
+		docs[i] = cookieDocument{
+			Name:         c.Name,
+			Value:        c.Value,
+			Domain:       c.Domain,
+			Path:         c.Path,
+			HTTPOnly:     c.HTTPOnly,
+			Secure:       c.Secure,
+			SourcePort:   c.SourcePort,
+			SourceScheme: c.SourceScheme,
+			Priority:     c.Priority,
+			Expires:      c.Expires,
+		}
+	}
+	return docs
+}
+
+// scriptParamsToDocuments converts remembered prompt values to documents,
+// ordered by script name.
+func scriptParamsToDocuments(params map[string]map[string]string) []scriptParamsDocument {
+	if len(params) == 0 {
+		return nil
+	}
+	docs := make([]scriptParamsDocument, 0, len(params))
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		docs = append(docs, scriptParamsDocument{Script: name, Params: params[name]})
+	}
+	return docs
+}
+
+// documentsToScriptParams converts remembered prompt value documents back
+// to a map keyed by script name.
+func documentsToScriptParams(docs []scriptParamsDocument) map[string]map[string]string {
+	if len(docs) == 0 {
+		return nil
+	}
+	params := make(map[string]map[string]string, len(docs))
+	for _, d := range docs {
+		params[d.Script] = d.Params
+	}
+	return params
+}
+
 // Ensure MongoAccountRepository implements account.Repository
 var _ account.Repository = (*MongoAccountRepository)(nil)
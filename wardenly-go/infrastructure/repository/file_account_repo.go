@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"wardenly-go/domain/account"
+	"wardenly-go/infrastructure/crypto"
+)
+
+// FileAccountRepository implements account.Repository with accounts.json
+// in a FileDB. Secrets are encrypted as in MongoAccountRepository.
+type FileAccountRepository struct {
+	accounts fileCollection[accountDocument]
+	secrets  *crypto.Cipher
+	logger   *slog.Logger
+}
+
+// NewFileAccountRepository creates a file-based account repository.
+// If secrets is nil, passwords and cookies are stored in plaintext.
+func NewFileAccountRepository(db *FileDB, secrets *crypto.Cipher, logger *slog.Logger) *FileAccountRepository {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &FileAccountRepository{
+		accounts: fileCollection[accountDocument]{
+			db:   db,
+			name: accountsFile,
+			id:   func(d *accountDocument) primitive.ObjectID { return d.ID },
+		},
+		secrets: secrets,
+		logger:  logger,
+	}
+}
+
+// FindByID retrieves an account by its unique identifier.
+func (r *FileAccountRepository) FindByID(_ context.Context, id string) (*account.Account, error) {
+	doc, err := r.accounts.find(id)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	if err := openSecrets(r.secrets, doc); err != nil {
+		return nil, err
+	}
+	return documentToAccount(doc), nil
+}
+
+// FindAll retrieves all accounts.
+func (r *FileAccountRepository) FindAll(_ context.Context) ([]*account.Account, error) {
+	docs, err := r.accounts.load()
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]*account.Account, len(docs))
+	for i := range docs {
+		if err := openSecrets(r.secrets, &docs[i]); err != nil {
+			return nil, err
+		}
+		accounts[i] = documentToAccount(&docs[i])
+	}
+	return accounts, nil
+}
+
+// Insert creates a new account.
+func (r *FileAccountRepository) Insert(_ context.Context, acc *account.Account) error {
+	doc := accountToDocument(acc)
+	doc.ID = primitive.NewObjectID()
+	if err := sealSecrets(r.secrets, doc); err != nil {
+		return err
+	}
+	err := r.accounts.modify(func(docs []accountDocument) ([]accountDocument, error) {
+		return append(docs, *doc), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	acc.ID = doc.ID.Hex()
+	r.logger.Info("Account inserted", "id", acc.ID, "role_name", acc.RoleName)
+	return nil
+}
+
+// Update updates an existing account. Like the MongoDB update, empty
+// cookies and script params keep the stored ones.
+func (r *FileAccountRepository) Update(_ context.Context, acc *account.Account) error {
+	doc := accountToDocument(acc)
+	if err := sealSecrets(r.secrets, doc); err != nil {
+		return err
+	}
+	return r.accounts.modifyOne(acc.ID, account.ErrAccountNotFound, func(stored *accountDocument) {
+		doc.ID = stored.ID
+		if len(doc.Cookies) == 0 {
+			doc.Cookies = stored.Cookies
+		}
+		if len(doc.ScriptParams) == 0 {
+			doc.ScriptParams = stored.ScriptParams
+		}
+		*stored = *doc
+	})
+}
+
+// UpdateCookies updates only the cookies for an account.
+func (r *FileAccountRepository) UpdateCookies(_ context.Context, id string, cookies []account.Cookie) error {
+	cookieDocs := cookiesToDocuments(cookies)
+	if err := sealCookies(r.secrets, cookieDocs); err != nil {
+		return err
+	}
+	err := r.accounts.modifyOne(id, account.ErrAccountNotFound, func(stored *accountDocument) {
+		stored.Cookies = cookieDocs
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("Cookies updated", "id", id, "count", len(cookies))
+	return nil
+}
+
+// UpdateScriptParams stores the remembered prompt values for one script.
+func (r *FileAccountRepository) UpdateScriptParams(_ context.Context, id, scriptName string, params map[string]string) error {
+	err := r.accounts.modifyOne(id, account.ErrAccountNotFound, func(stored *accountDocument) {
+		i := slices.IndexFunc(stored.ScriptParams, func(d scriptParamsDocument) bool { return d.Script == scriptName })
+		if i < 0 {
+			stored.ScriptParams = append(stored.ScriptParams, scriptParamsDocument{Script: scriptName})
+			i = len(stored.ScriptParams) - 1
+		}
+		stored.ScriptParams[i].Params = params
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("Script params updated", "id", id, "script", scriptName)
+	return nil
+}
+
+// Delete removes an account by its identifier.
+func (r *FileAccountRepository) Delete(_ context.Context, id string) error {
+	if err := r.accounts.delete(id, account.ErrAccountNotFound); err != nil {
+		return err
+	}
+
+	r.logger.Info("Account deleted", "id", id)
+	return nil
+}
+
+// EncryptSecrets encrypts game and proxy passwords and cookie values that
+// are still stored in plaintext and returns the number of accounts updated. It does nothing
+// without a cipher.
+func (r *FileAccountRepository) EncryptSecrets(_ context.Context) (int, error) {
+	if r.secrets == nil {
+		return 0, nil
+	}
+	docs, err := r.accounts.load()
+	if err != nil || !slices.ContainsFunc(docs, func(d accountDocument) bool { return hasPlaintextSecrets(&d) }) {
+		return 0, err
+	}
+
+	updated := 0
+	err = r.accounts.modify(func(docs []accountDocument) ([]accountDocument, error) {
+		for i := range docs {
+			if !hasPlaintextSecrets(&docs[i]) {
+				continue
+			}
+			if err := resealSecrets(r.secrets, &docs[i]); err != nil {
+				return nil, err
+			}
+			updated++
+		}
+		return docs, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if updated > 0 {
+		r.logger.Info("Account secrets encrypted", "count", updated)
+	}
+	return updated, nil
+}
+
+// Ensure FileAccountRepository implements account.Repository
+var _ account.Repository = (*FileAccountRepository)(nil)
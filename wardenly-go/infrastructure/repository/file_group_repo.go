@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"wardenly-go/domain/group"
+)
+
+// FileGroupRepository implements group.Repository with groups.json in a FileDB.
+type FileGroupRepository struct {
+	groups fileCollection[groupDocument]
+	logger *slog.Logger
+}
+
+// NewFileGroupRepository creates a file-based group repository.
+func NewFileGroupRepository(db *FileDB, logger *slog.Logger) *FileGroupRepository {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &FileGroupRepository{
+		groups: fileCollection[groupDocument]{
+			db:   db,
+			name: groupsFile,
+			id:   func(d *groupDocument) primitive.ObjectID { return d.ID },
+		},
+		logger: logger,
+	}
+}
+
+// FindByID retrieves a group by its unique identifier.
+func (r *FileGroupRepository) FindByID(_ context.Context, id string) (*group.Group, error) {
+	doc, err := r.groups.find(id)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	return documentToGroup(doc), nil
+}
+
+// FindByName retrieves a group by its name.
+func (r *FileGroupRepository) FindByName(_ context.Context, name string) (*group.Group, error) {
+	groups, err := r.filter(func(d *groupDocument) bool { return d.Name == name })
+	if err != nil || len(groups) == 0 {
+		return nil, err
+	}
+	return groups[0], nil
+}
+
+// FindAll retrieves all groups.
+func (r *FileGroupRepository) FindAll(_ context.Context) ([]*group.Group, error) {
+	return r.filter(func(*groupDocument) bool { return true })
+}
+
+// FindByAccountID retrieves all groups containing a specific account.
+func (r *FileGroupRepository) FindByAccountID(_ context.Context, accountID string) ([]*group.Group, error) {
+	return r.filter(func(d *groupDocument) bool { return slices.Contains(d.AccountIDs, accountID) })
+}
+
+// filter returns the groups whose documents match.
+func (r *FileGroupRepository) filter(match func(*groupDocument) bool) ([]*group.Group, error) {
+	docs, err := r.groups.load()
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]*group.Group, 0, len(docs))
+	for i := range docs {
+		if match(&docs[i]) {
+			groups = append(groups, documentToGroup(&docs[i]))
+		}
+	}
+	return groups, nil
+}
+
+// Insert creates a new group.
+func (r *FileGroupRepository) Insert(_ context.Context, grp *group.Group) error {
+	doc := groupToDocument(grp)
+	doc.ID = primitive.NewObjectID()
+	err := r.groups.modify(func(docs []groupDocument) ([]groupDocument, error) {
+		return append(docs, *doc), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	grp.ID = doc.ID.Hex()
+	r.logger.Info("Group inserted", "id", grp.ID, "name", grp.Name)
+	return nil
+}
+
+// Update updates an existing group.
+func (r *FileGroupRepository) Update(_ context.Context, grp *group.Group) error {
+	doc := groupToDocument(grp)
+	err := r.groups.modifyOne(grp.ID, group.ErrGroupNotFound, func(stored *groupDocument) {
+		doc.ID = stored.ID
+		*stored = *doc
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("Group updated", "id", grp.ID, "name", grp.Name)
+	return nil
+}
+
+// Delete removes a group by its identifier.
+func (r *FileGroupRepository) Delete(_ context.Context, id string) error {
+	if err := r.groups.delete(id, group.ErrGroupNotFound); err != nil {
+		return err
+	}
+
+	r.logger.Info("Group deleted", "id", id)
+	return nil
+}
+
+// Ensure FileGroupRepository implements group.Repository
+var _ group.Repository = (*FileGroupRepository)(nil)
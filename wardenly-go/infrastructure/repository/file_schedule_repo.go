@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"wardenly-go/domain/schedule"
+)
+
+// FileScheduleRepository implements schedule.Repository with
+// schedules.json in a FileDB.
+type FileScheduleRepository struct {
+	schedules fileCollection[scheduleDocument]
+	logger    *slog.Logger
+}
+
+// NewFileScheduleRepository creates a file-based schedule repository.
+func NewFileScheduleRepository(db *FileDB, logger *slog.Logger) *FileScheduleRepository {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &FileScheduleRepository{
+		schedules: fileCollection[scheduleDocument]{
+			db:   db,
+			name: schedulesFile,
+			id:   func(d *scheduleDocument) primitive.ObjectID { return d.ID },
+		},
+		logger: logger,
+	}
+}
+
+// FindByID retrieves a schedule by its unique identifier.
+func (r *FileScheduleRepository) FindByID(_ context.Context, id string) (*schedule.Schedule, error) {
+	doc, err := r.schedules.find(id)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	return documentToSchedule(doc), nil
+}
+
+// FindAll retrieves all schedules.
+func (r *FileScheduleRepository) FindAll(_ context.Context) ([]*schedule.Schedule, error) {
+	docs, err := r.schedules.load()
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]*schedule.Schedule, len(docs))
+	for i := range docs {
+		schedules[i] = documentToSchedule(&docs[i])
+	}
+	return schedules, nil
+}
+
+// Insert creates a new schedule.
+func (r *FileScheduleRepository) Insert(_ context.Context, sch *schedule.Schedule) error {
+	doc := scheduleToDocument(sch)
+	doc.ID = primitive.NewObjectID()
+	err := r.schedules.modify(func(docs []scheduleDocument) ([]scheduleDocument, error) {
+		return append(docs, *doc), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sch.ID = doc.ID.Hex()
+	r.logger.Info("Schedule inserted", "id", sch.ID, "name", sch.Name)
+	return nil
+}
+
+// Update updates an existing schedule. Like the MongoDB update, a zero
+// last run time keeps the stored one.
+func (r *FileScheduleRepository) Update(_ context.Context, sch *schedule.Schedule) error {
+	doc := scheduleToDocument(sch)
+	err := r.schedules.modifyOne(sch.ID, schedule.ErrScheduleNotFound, func(stored *scheduleDocument) {
+		doc.ID = stored.ID
+		if doc.LastRunAt.IsZero() {
+			doc.LastRunAt = stored.LastRunAt
+		}
+		*stored = *doc
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("Schedule updated", "id", sch.ID, "name", sch.Name)
+	return nil
+}
+
+// UpdateLastRun records when a schedule last triggered.
+func (r *FileScheduleRepository) UpdateLastRun(_ context.Context, id string, at time.Time) error {
+	return r.schedules.modifyOne(id, schedule.ErrScheduleNotFound, func(stored *scheduleDocument) {
+		stored.LastRunAt = at
+	})
+}
+
+// Delete removes a schedule by its identifier.
+func (r *FileScheduleRepository) Delete(_ context.Context, id string) error {
+	if err := r.schedules.delete(id, schedule.ErrScheduleNotFound); err != nil {
+		return err
+	}
+
+	r.logger.Info("Schedule deleted", "id", id)
+	return nil
+}
+
+// Ensure FileScheduleRepository implements schedule.Repository
+var _ schedule.Repository = (*FileScheduleRepository)(nil)
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"wardenly-go/domain/group"
+)
+
+// FileTemplateRepository implements group.TemplateRepository with
+// templates.json in a FileDB.
+type FileTemplateRepository struct {
+	templates fileCollection[templateDocument]
+	logger    *slog.Logger
+}
+
+// NewFileTemplateRepository creates a file-based template repository.
+func NewFileTemplateRepository(db *FileDB, logger *slog.Logger) *FileTemplateRepository {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &FileTemplateRepository{
+		templates: fileCollection[templateDocument]{
+			db:   db,
+			name: templatesFile,
+			id:   func(d *templateDocument) primitive.ObjectID { return d.ID },
+		},
+		logger: logger,
+	}
+}
+
+// FindByID retrieves a template by its unique identifier.
+func (r *FileTemplateRepository) FindByID(_ context.Context, id string) (*group.Template, error) {
+	doc, err := r.templates.find(id)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	return documentToTemplate(doc), nil
+}
+
+// FindAll retrieves all templates.
+func (r *FileTemplateRepository) FindAll(_ context.Context) ([]*group.Template, error) {
+	docs, err := r.templates.load()
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]*group.Template, len(docs))
+	for i := range docs {
+		templates[i] = documentToTemplate(&docs[i])
+	}
+	return templates, nil
+}
+
+// Insert creates a new template.
+func (r *FileTemplateRepository) Insert(_ context.Context, tmpl *group.Template) error {
+	doc := templateToDocument(tmpl)
+	doc.ID = primitive.NewObjectID()
+	err := r.templates.modify(func(docs []templateDocument) ([]templateDocument, error) {
+		return append(docs, *doc), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	tmpl.ID = doc.ID.Hex()
+	r.logger.Info("Template inserted", "id", tmpl.ID, "name", tmpl.Name)
+	return nil
+}
+
+// Update updates an existing template.
+func (r *FileTemplateRepository) Update(_ context.Context, tmpl *group.Template) error {
+	doc := templateToDocument(tmpl)
+	err := r.templates.modifyOne(tmpl.ID, group.ErrTemplateNotFound, func(stored *templateDocument) {
+		doc.ID = stored.ID
+		*stored = *doc
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("Template updated", "id", tmpl.ID, "name", tmpl.Name)
+	return nil
+}
+
+// Delete removes a template by its identifier.
+func (r *FileTemplateRepository) Delete(_ context.Context, id string) error {
+	if err := r.templates.delete(id, group.ErrTemplateNotFound); err != nil {
+		return err
+	}
+
+	r.logger.Info("Template deleted", "id", id)
+	return nil
+}
+
+// Ensure FileTemplateRepository implements group.TemplateRepository
+var _ group.TemplateRepository = (*FileTemplateRepository)(nil)
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"wardenly-go/infrastructure/appdir"
+)
+
+// Environment variables overriding the store section of the config file.
+const (
+	EnvStore    = "WARDENLY_STORE"
+	EnvStoreDir = "WARDENLY_STORE_DIR"
+)
+
+// Store kinds for WARDENLY_STORE.
+const (
+	StoreMongo = "mongo"
+	StoreFile  = "file"
+)
+
+// File names of the JSON file store.
+const (
+	accountsFile  = "accounts.json"
+	groupsFile    = "groups.json"
+	templatesFile = "templates.json"
+	schedulesFile = "schedules.json"
+)
+
+const (
+	// lockTimeout bounds how long a write waits for another process.
+	lockTimeout = 5 * time.Second
+	// staleLockAge is when a lock file is considered left behind by a
+	// crashed process; writes hold it for milliseconds.
+	staleLockAge = 30 * time.Second
+	lockRetry    = 20 * time.Millisecond
+)
+
+// StoreConfig selects where accounts, groups, templates and schedules are kept.
+type StoreConfig struct {
+	// Kind is StoreMongo (default) or StoreFile
+	Kind string
+	// Dir holds the JSON files of the file store
+	Dir string
+}
+
+// DefaultFileDir returns the default directory of the JSON file store.
+func DefaultFileDir() string {
+	return appdir.Path("data")
+}
+
+// FileDB keeps each collection as a JSON array in its own file, so a
+// portable install needs no database. Writes replace the file atomically
+// and hold a lock file, so several processes can share the directory.
+type FileDB struct {
+	dir string
+	mu  sync.Mutex // Serializes writes within the process
+
+	// mem holds the collections of an in-memory store instead of files
+	mem   map[string][]byte
+	memMu sync.Mutex
+}
+
+// NewFileDB opens a file store in dir, creating it if needed.
+func NewFileDB(dir string) (*FileDB, error) {
+	if dir == "" {
+		dir = DefaultFileDir()
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create data dir: %w", err)
+	}
+	return &FileDB{dir: dir}, nil
+}
+
+// NewMemoryDB creates a store that keeps the collections in memory, for
+// demo mode and tests. Nothing is written to disk and the data is lost on
+// exit.
+func NewMemoryDB() *FileDB {
+	return &FileDB{mem: make(map[string][]byte)}
+}
+
+// Dir returns the directory of the store, empty for an in-memory store.
+func (db *FileDB) Dir() string {
+	return db.dir
+}
+
+// fileCollection is one JSON file of documents of type D.
+type fileCollection[D any] struct {
+	db   *FileDB
+	name string
+	// id returns a document's ID
+	id func(*D) primitive.ObjectID
+}
+
+// load reads all documents. A missing file is an empty collection.
+func (c *fileCollection[D]) load() ([]D, error) {
+	data, err := c.db.read(c.name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", c.name, err)
+	}
+
+	var docs []D
+	if err := json.Unmarshal(data, &docs); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", c.name, err)
+	}
+	return docs, nil
+}
+
+// find returns the document with id, or nil if there is none.
+func (c *fileCollection[D]) find(id string) (*D, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+	docs, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	if i := c.index(docs, objectID); i >= 0 {
+		return &docs[i], nil
+	}
+	return nil, nil
+}
+
+// index returns the position of the document with id, or -1.
+func (c *fileCollection[D]) index(docs []D, id primitive.ObjectID) int {
+	return slices.IndexFunc(docs, func(d D) bool { return c.id(&d) == id })
+}
+
+// modify applies fn to the documents and writes the result, holding the
+// lock so no other process writes in between.
+func (c *fileCollection[D]) modify(fn func([]D) ([]D, error)) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	unlock, err := c.db.lock(c.name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	docs, err := c.load()
+	if err != nil {
+		return err
+	}
+	if docs, err = fn(docs); err != nil {
+		return err
+	}
+	if docs == nil {
+		docs = []D{}
+	}
+	return c.db.write(c.name, docs)
+}
+
+// modifyOne applies fn to the document with id and writes the result.
+// It returns notFound if there is no such document.
+func (c *fileCollection[D]) modifyOne(id string, notFound error, fn func(*D)) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+	return c.modify(func(docs []D) ([]D, error) {
+		i := c.index(docs, objectID)
+		if i < 0 {
+			return nil, notFound
+		}
+		fn(&docs[i])
+		return docs, nil
+	})
+}
+
+// delete removes the document with id. It returns notFound if there is
+// no such document.
+func (c *fileCollection[D]) delete(id string, notFound error) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+	return c.modify(func(docs []D) ([]D, error) {
+		i := c.index(docs, objectID)
+		if i < 0 {
+			return nil, notFound
+		}
+		return slices.Delete(docs, i, i+1), nil
+	})
+}
+
+// read returns the content of a file.
+func (db *FileDB) read(name string) ([]byte, error) {
+	if db.mem != nil {
+		db.memMu.Lock()
+		defer db.memMu.Unlock()
+		data, ok := db.mem[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return data, nil
+	}
+	return os.ReadFile(filepath.Join(db.dir, name))
+}
+
+// write replaces a file atomically via a temp file and rename.
+func (db *FileDB) write(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	if db.mem != nil {
+		db.memMu.Lock()
+		db.mem[name] = data
+		db.memMu.Unlock()
+		return nil
+	}
+
+	path := filepath.Join(db.dir, name)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		// Flush before the rename so a yanked USB stick keeps the old or new file
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// lock takes the lock file of a collection, waiting for other processes
+// up to lockTimeout. A lock older than staleLockAge is taken over.
+func (db *FileDB) lock(name string) (unlock func(), err error) {
+	if db.mem != nil {
+		// No other process can see an in-memory store
+		return func() {}, nil
+	}
+	path := filepath.Join(db.dir, name+".lock")
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock %s: %w", name, err)
+		}
+
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to lock %s: held by another process (remove %s if none is running)", name, path)
+		}
+		time.Sleep(lockRetry)
+	}
+}
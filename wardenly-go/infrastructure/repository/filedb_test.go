@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"wardenly-go/domain/account"
+	"wardenly-go/domain/group"
+	"wardenly-go/domain/schedule"
+	"wardenly-go/infrastructure/crypto"
+)
+
+func newTestFileDB(t *testing.T) *FileDB {
+	t.Helper()
+	db, err := NewFileDB(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestFileAccountRepository(t *testing.T) {
+	ctx := context.Background()
+	secrets, err := crypto.NewCipher(bytes.Repeat([]byte{7}, crypto.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := newTestFileDB(t)
+	repo := NewFileAccountRepository(db, secrets, nil)
+
+	acc := &account.Account{
+		RoleName: "alice",
+		UserName: "alice@example.com",
+		Password: "secret",
+		ServerID: 1,
+		Cookies:  []account.Cookie{{Name: "session", Value: "abc123"}},
+	}
+	if err := repo.Insert(ctx, acc); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if acc.ID == "" {
+		t.Fatal("Insert() did not assign an ID")
+	}
+
+	raw, err := os.ReadFile(filepath.Join(db.Dir(), accountsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "secret") || strings.Contains(string(raw), "abc123") {
+		t.Errorf("accounts.json holds plaintext secrets:\n%s", raw)
+	}
+
+	// Updates without cookies keep the stored ones, as with MongoDB
+	edited := acc.Clone()
+	edited.Cookies = nil
+	edited.RoleName = "alice2"
+	if err := repo.Update(ctx, edited); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	for _, name := range []string{"daily", "v1.2", "daily"} {
+		if err := repo.UpdateScriptParams(ctx, acc.ID, name, map[string]string{"runs": "3"}); err != nil {
+			t.Fatalf("UpdateScriptParams(%q) error = %v", name, err)
+		}
+	}
+
+	got, err := repo.FindByID(ctx, acc.ID)
+	if err != nil || got == nil {
+		t.Fatalf("FindByID() = %v, %v", got, err)
+	}
+	if got.RoleName != "alice2" || got.Password != "secret" || len(got.Cookies) != 1 || got.Cookies[0].Value != "abc123" {
+		t.Errorf("FindByID() = %+v", got)
+	}
+	if len(got.ScriptParams) != 2 || got.ScriptParams["daily"]["runs"] != "3" || got.ScriptParams["v1.2"]["runs"] != "3" {
+		t.Errorf("ScriptParams = %v", got.ScriptParams)
+	}
+
+	if err := repo.UpdateCookies(ctx, acc.ID, nil); err != nil {
+		t.Fatalf("UpdateCookies() error = %v", err)
+	}
+	if got, _ := repo.FindByID(ctx, acc.ID); len(got.Cookies) != 0 {
+		t.Errorf("cookies = %v after clearing", got.Cookies)
+	}
+
+	if err := repo.Delete(ctx, acc.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := repo.Delete(ctx, acc.ID); !errors.Is(err, account.ErrAccountNotFound) {
+		t.Errorf("Delete() twice error = %v, want ErrAccountNotFound", err)
+	}
+	if got, err := repo.FindByID(ctx, acc.ID); got != nil || err != nil {
+		t.Errorf("FindByID() after delete = %v, %v", got, err)
+	}
+	if _, err := repo.FindByID(ctx, "not-an-id"); err == nil {
+		t.Error("FindByID() should reject an invalid ID")
+	}
+}
+
+func TestFileAccountRepository_EncryptSecrets(t *testing.T) {
+	ctx := context.Background()
+	db := newTestFileDB(t)
+	if err := NewFileAccountRepository(db, nil, nil).Insert(ctx, &account.Account{
+		RoleName: "bob",
+		Password: "plain",
+		Proxy:    &account.Proxy{Host: "proxy", Port: 1080, Password: "socks"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	secrets, err := crypto.NewCipher(bytes.Repeat([]byte{7}, crypto.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewFileAccountRepository(db, secrets, nil)
+	if n, err := repo.EncryptSecrets(ctx); n != 1 || err != nil {
+		t.Fatalf("EncryptSecrets() = %d, %v; want 1", n, err)
+	}
+	if n, err := repo.EncryptSecrets(ctx); n != 0 || err != nil {
+		t.Errorf("EncryptSecrets() again = %d, %v; want 0", n, err)
+	}
+	accounts, err := repo.FindAll(ctx)
+	if err != nil || len(accounts) != 1 || accounts[0].Password != "plain" || accounts[0].Proxy.Password != "socks" {
+		t.Errorf("FindAll() = %v, %v", accounts, err)
+	}
+	docs, _ := repo.accounts.load()
+	if !crypto.IsEncrypted(docs[0].Password) || !crypto.IsEncrypted(docs[0].Proxy.Password) {
+		t.Errorf("stored secrets = %q, %q, want them encrypted", docs[0].Password, docs[0].Proxy.Password)
+	}
+}
+
+func TestFileGroupRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewFileGroupRepository(newTestFileDB(t), nil)
+
+	if groups, err := repo.FindAll(ctx); err != nil || len(groups) != 0 {
+		t.Fatalf("FindAll() on a new store = %v, %v", groups, err)
+	}
+	daily := &group.Group{Name: "daily", AccountIDs: []string{"a", "b"}}
+	arena := &group.Group{Name: "arena", AccountIDs: []string{"b"}}
+	for _, g := range []*group.Group{daily, arena} {
+		if err := repo.Insert(ctx, g); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+	}
+
+	if got, err := repo.FindByName(ctx, "arena"); err != nil || got == nil || got.ID != arena.ID {
+		t.Errorf("FindByName(arena) = %+v, %v", got, err)
+	}
+	if got, err := repo.FindByName(ctx, "missing"); got != nil || err != nil {
+		t.Errorf("FindByName(missing) = %+v, %v", got, err)
+	}
+	if got, _ := repo.FindByAccountID(ctx, "b"); len(got) != 2 {
+		t.Errorf("FindByAccountID(b) = %d groups, want 2", len(got))
+	}
+
+	daily.Settings.ScriptName = "daily"
+	if err := repo.Update(ctx, daily); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, _ := repo.FindByID(ctx, daily.ID); got.Settings.ScriptName != "daily" {
+		t.Errorf("Settings = %+v after update", got.Settings)
+	}
+	missing := &group.Group{ID: "000000000000000000000000", Name: "missing"}
+	if err := repo.Update(ctx, missing); !errors.Is(err, group.ErrGroupNotFound) {
+		t.Errorf("Update(missing) error = %v, want ErrGroupNotFound", err)
+	}
+}
+
+func TestFileScheduleRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewFileScheduleRepository(newTestFileDB(t), nil)
+
+	sch := &schedule.Schedule{Name: "morning", TargetType: schedule.TargetAccount, TargetID: "a", ScriptName: "daily", Spec: "daily 04:30"}
+	if err := repo.Insert(ctx, sch); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 1, 2, 4, 30, 0, 0, time.UTC)
+	if err := repo.UpdateLastRun(ctx, sch.ID, at); err != nil {
+		t.Fatalf("UpdateLastRun() error = %v", err)
+	}
+
+	// A zero last run keeps the stored one, as with MongoDB
+	sch.Enabled = true
+	if err := repo.Update(ctx, sch); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := repo.FindByID(ctx, sch.ID)
+	if err != nil || !got.Enabled || !got.LastRunAt.Equal(at) {
+		t.Errorf("FindByID() = %+v, %v", got, err)
+	}
+}
+
+func TestFileDB_ConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// Separate FileDBs stand in for separate processes sharing the directory
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		db, err := NewFileDB(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		repo := NewFileGroupRepository(db, nil)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				if err := repo.Insert(ctx, &group.Group{Name: "g"}); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	db, _ := NewFileDB(dir)
+	if groups, err := NewFileGroupRepository(db, nil).FindAll(ctx); err != nil || len(groups) != 20 {
+		t.Errorf("FindAll() = %d groups, %v; want 20", len(groups), err)
+	}
+}
+
+func TestFileDB_StaleLock(t *testing.T) {
+	db := newTestFileDB(t)
+	lock := filepath.Join(db.Dir(), groupsFile+".lock")
+	if err := os.WriteFile(lock, []byte("1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewFileGroupRepository(db, nil).Insert(context.Background(), &group.Group{Name: "g"}); err != nil {
+		t.Fatalf("Insert() with a stale lock error = %v", err)
+	}
+	if _, err := os.Stat(lock); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
+func TestMemoryDB(t *testing.T) {
+	ctx := context.Background()
+	db := NewMemoryDB()
+	repo := NewFileGroupRepository(db, nil)
+	if err := repo.Insert(ctx, &group.Group{Name: "arena", AccountIDs: []string{"a"}}); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if got, err := repo.FindByName(ctx, "arena"); err != nil || got == nil || len(got.AccountIDs) != 1 {
+		t.Errorf("FindByName() = %v, %v", got, err)
+	}
+	if db.Dir() != "" {
+		t.Errorf("Dir() = %q, want none", db.Dir())
+	}
+	// Each store is separate
+	if groups, _ := NewFileGroupRepository(NewMemoryDB(), nil).FindAll(ctx); len(groups) != 0 {
+		t.Errorf("new memory store has groups %v", groups)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -12,13 +13,24 @@ import (
 	"wardenly-go/domain/group"
 )
 
-// groupDocument is the MongoDB document structure for groups.
+// groupDocument is the stored document structure for groups, in MongoDB
+// and in the JSON file store.
 type groupDocument struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty"`
-	Name        string             `bson:"name"`
-	Description string             `bson:"description,omitempty"`
-	AccountIDs  []string           `bson:"account_ids"`
-	Ranking     int                `bson:"ranking"`
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name        string              `bson:"name" json:"name"`
+	Description string              `bson:"description,omitempty" json:"description,omitempty"`
+	AccountIDs  []string            `bson:"account_ids" json:"account_ids"`
+	Query       string              `bson:"query" json:"query"` // empty clears it on update
+	Ranking     int                 `bson:"ranking" json:"ranking"`
+	Settings    runSettingsDocument `bson:"settings" json:"settings"`
+	TemplateID  string              `bson:"template_id" json:"template_id"`
+}
+
+// runSettingsDocument is the embedded run settings of groups and templates.
+type runSettingsDocument struct {
+	ScriptName      string `bson:"script_name,omitempty" json:"script_name,omitempty"`
+	StartIntervalMs int64  `bson:"start_interval_ms,omitempty" json:"start_interval_ms,omitempty"`
+	StopWhenDone    bool   `bson:"stop_when_done,omitempty" json:"stop_when_done,omitempty"`
 }
 
 // MongoGroupRepository implements group.Repository using MongoDB.
@@ -189,7 +201,10 @@ func documentToGroup(doc *groupDocument) *group.Group {
 		Name:        doc.Name,
 		Description: doc.Description,
 		AccountIDs:  accountIDs,
+		Query:       doc.Query,
 		Ranking:     doc.Ranking,
+		Settings:    documentToRunSettings(doc.Settings),
+		TemplateID:  doc.TemplateID,
 	}
 }
 
@@ -204,7 +219,10 @@ func groupToDocument(grp *group.Group) *groupDocument {
 		Name:        grp.Name,
 		Description: grp.Description,
 		AccountIDs:  accountIDs,
+		Query:       grp.Query,
 		Ranking:     grp.Ranking,
+		Settings:    runSettingsToDocument(grp.Settings),
+		TemplateID:  grp.TemplateID,
 	}
 
 	if grp.ID != "" {
@@ -216,5 +234,23 @@ func groupToDocument(grp *group.Group) *groupDocument {
 	return doc
 }
 
+// documentToRunSettings converts embedded run settings to the domain type.
+func documentToRunSettings(doc runSettingsDocument) group.RunSettings {
+	return group.RunSettings{
+		ScriptName:    doc.ScriptName,
+		StartInterval: time.Duration(doc.StartIntervalMs) * time.Millisecond,
+		StopWhenDone:  doc.StopWhenDone,
+	}
+}
+
+// runSettingsToDocument converts domain run settings to a document.
+func runSettingsToDocument(s group.RunSettings) runSettingsDocument {
+	return runSettingsDocument{
+		ScriptName:      s.ScriptName,
+		StartIntervalMs: s.StartInterval.Milliseconds(),
+		StopWhenDone:    s.StopWhenDone,
+	}
+}
+
 // Ensure MongoGroupRepository implements group.Repository
 var _ group.Repository = (*MongoGroupRepository)(nil)
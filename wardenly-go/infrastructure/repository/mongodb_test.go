@@ -1,6 +1,12 @@
 package repository
 
-import "testing"
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"wardenly-go/infrastructure/crypto"
+)
 
 func TestDefaultMongoDBConfig(t *testing.T) {
 	config := DefaultMongoDBConfig()
@@ -86,6 +92,21 @@ func TestAccountToDocument(t *testing.T) {
 	}
 }
 
+func TestScriptParamsDocuments(t *testing.T) {
+	params := map[string]map[string]string{"v1.2": {"runs": "3"}, "$daily": {"runs": "5"}}
+
+	docs := scriptParamsToDocuments(params)
+	if len(docs) != 2 || docs[0].Script != "$daily" || docs[1].Script != "v1.2" {
+		t.Fatalf("scriptParamsToDocuments() = %+v, want entries ordered by script", docs)
+	}
+	if got := documentsToScriptParams(docs); !reflect.DeepEqual(got, params) {
+		t.Errorf("documentsToScriptParams() = %v, want %v", got, params)
+	}
+	if scriptParamsToDocuments(nil) != nil || documentsToScriptParams(nil) != nil {
+		t.Error("empty params should convert to nil")
+	}
+}
+
 func TestCookieDocument(t *testing.T) {
 	cookie := cookieDocument{
 		Name:       "session",
@@ -116,3 +137,56 @@ func TestCookieDocument(t *testing.T) {
 		t.Errorf("SourcePort = %d, want 443", cookie.SourcePort)
 	}
 }
+
+func TestAccountSecrets(t *testing.T) {
+	secrets, err := crypto.NewCipher(bytes.Repeat([]byte{7}, crypto.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &accountDocument{
+		Password: "enc:v1:password",
+		Proxy:    &proxyDocument{Host: "proxy", Port: 1080, Password: "socks"},
+		Cookies:  []cookieDocument{{Name: "session", Value: "abc123"}},
+	}
+
+	if !hasPlaintextSecrets(doc) {
+		t.Error("hasPlaintextSecrets() = false for a plaintext document")
+	}
+	if err := sealSecrets(secrets, doc); err != nil {
+		t.Fatalf("sealSecrets() error = %v", err)
+	}
+	if !crypto.IsEncrypted(doc.Password) || !crypto.IsEncrypted(doc.Proxy.Password) || !crypto.IsEncrypted(doc.Cookies[0].Value) {
+		t.Fatalf("sealSecrets() left plaintext: %+v", doc)
+	}
+	if hasPlaintextSecrets(doc) {
+		t.Error("hasPlaintextSecrets() = true after sealing")
+	}
+
+	if err := openSecrets(secrets, doc); err != nil {
+		t.Fatalf("openSecrets() error = %v", err)
+	}
+	if doc.Password != "enc:v1:password" || doc.Proxy.Password != "socks" || doc.Cookies[0].Value != "abc123" {
+		t.Errorf("openSecrets() = %+v, want the original values", doc)
+	}
+
+	// A proxy password added to an encrypted document is encrypted once
+	if err := sealSecrets(secrets, doc); err != nil {
+		t.Fatal(err)
+	}
+	doc.Proxy.Password = "new"
+	if !hasPlaintextSecrets(doc) {
+		t.Error("hasPlaintextSecrets() = false with a plaintext proxy password")
+	}
+	if err := resealSecrets(secrets, doc); err != nil {
+		t.Fatalf("resealSecrets() error = %v", err)
+	}
+	if err := openSecrets(secrets, doc); err != nil || doc.Password != "enc:v1:password" || doc.Proxy.Password != "new" {
+		t.Errorf("openSecrets() after resealing = %+v, %v", doc, err)
+	}
+
+	// Documents written before encryption are read as they are
+	legacy := &accountDocument{Password: "legacy"}
+	if err := openSecrets(secrets, legacy); err != nil || legacy.Password != "legacy" {
+		t.Errorf("openSecrets(plaintext) = %q, %v", legacy.Password, err)
+	}
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"wardenly-go/infrastructure/presence"
+)
+
+// presenceDocument is the MongoDB document structure for session presence.
+// The ID joins machine and session, so each instance owns its documents.
+type presenceDocument struct {
+	ID          string    `bson:"_id"`
+	Machine     string    `bson:"machine"`
+	SessionID   string    `bson:"session_id"`
+	AccountID   string    `bson:"account_id,omitempty"`
+	AccountName string    `bson:"account_name,omitempty"`
+	State       string    `bson:"state"`
+	Script      string    `bson:"script,omitempty"`
+	StartedAt   time.Time `bson:"started_at"`
+	UpdatedAt   time.Time `bson:"updated_at"`
+}
+
+// MongoPresenceStore implements presence.Store using MongoDB. A TTL index
+// on updated_at lets MongoDB delete records whose heartbeat stopped.
+type MongoPresenceStore struct {
+	collection *mongo.Collection
+	ttl        time.Duration
+	logger     *slog.Logger
+}
+
+// NewMongoPresenceStore creates a new MongoDB-based session presence store
+// whose records expire ttl after their last update.
+func NewMongoPresenceStore(db *MongoDB, ttl time.Duration, logger *slog.Logger) *MongoPresenceStore {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if ttl <= 0 {
+		ttl = presence.DefaultTTL
+	}
+	return &MongoPresenceStore{
+		collection: db.Collection("session_presence"),
+		ttl:        ttl,
+		logger:     logger,
+	}
+}
+
+// EnsureIndex creates the TTL index, or updates its expiry if an index
+// with a different TTL exists.
+func (s *MongoPresenceStore) EnsureIndex(ctx context.Context) error {
+	seconds := int32(s.ttl.Round(time.Second) / time.Second)
+	model := mongo.IndexModel{
+		Keys:    bson.D{{Key: "updated_at", Value: 1}},
+		Options: options.Index().SetName("updated_at_ttl").SetExpireAfterSeconds(seconds),
+	}
+	_, err := s.collection.Indexes().CreateOne(ctx, model)
+	if err == nil {
+		return nil
+	}
+	// Changing the expiry of an existing index needs collMod
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) || (cmdErr.Name != "IndexOptionsConflict" && cmdErr.Name != "IndexKeySpecsConflict") {
+		return fmt.Errorf("failed to create presence TTL index: %w", err)
+	}
+	cmd := bson.D{
+		{Key: "collMod", Value: s.collection.Name()},
+		{Key: "index", Value: bson.D{{Key: "name", Value: "updated_at_ttl"}, {Key: "expireAfterSeconds", Value: seconds}}},
+	}
+	if err := s.collection.Database().RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("failed to update presence TTL index: %w", err)
+	}
+	return nil
+}
+
+// Put upserts records.
+func (s *MongoPresenceStore) Put(ctx context.Context, records []presence.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, len(records))
+	for i, rec := range records {
+		doc := presenceToDocument(&rec)
+		models[i] = mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": doc.ID}).SetReplacement(doc).SetUpsert(true)
+	}
+	if _, err := s.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to write session presence: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes a session's record.
+func (s *MongoPresenceStore) Remove(ctx context.Context, machine, sessionID string) error {
+	if _, err := s.collection.DeleteOne(ctx, bson.M{"_id": presenceID(machine, sessionID)}); err != nil {
+		return fmt.Errorf("failed to delete session presence: %w", err)
+	}
+	return nil
+}
+
+// List returns the records updated within the TTL, by machine and account.
+// MongoDB removes expired documents only about once a minute, so they are
+// filtered here as well.
+func (s *MongoPresenceStore) List(ctx context.Context) ([]presence.Record, error) {
+	filter := bson.M{"updated_at": bson.M{"$gt": time.Now().Add(-s.ttl)}}
+	opts := options.Find().SetSort(bson.D{{Key: "machine", Value: 1}, {Key: "account_name", Value: 1}})
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find session presence: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []presenceDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode session presence: %w", err)
+	}
+	records := make([]presence.Record, len(docs))
+	for i, doc := range docs {
+		records[i] = presence.Record{
+			Machine:     doc.Machine,
+			SessionID:   doc.SessionID,
+			AccountID:   doc.AccountID,
+			AccountName: doc.AccountName,
+			State:       doc.State,
+			Script:      doc.Script,
+			StartedAt:   doc.StartedAt,
+			UpdatedAt:   doc.UpdatedAt,
+		}
+	}
+	return records, nil
+}
+
+func presenceID(machine, sessionID string) string {
+	return machine + "/" + sessionID
+}
+
+func presenceToDocument(rec *presence.Record) *presenceDocument {
+	return &presenceDocument{
+		ID:          presenceID(rec.Machine, rec.SessionID),
+		Machine:     rec.Machine,
+		SessionID:   rec.SessionID,
+		AccountID:   rec.AccountID,
+		AccountName: rec.AccountName,
+		State:       rec.State,
+		Script:      rec.Script,
+		StartedAt:   rec.StartedAt,
+		UpdatedAt:   rec.UpdatedAt,
+	}
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"wardenly-go/domain/schedule"
+)
+
+// scheduleDocument is the stored document structure for schedules, in
+// MongoDB and in the JSON file store.
+type scheduleDocument struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name       string             `bson:"name" json:"name"`
+	TargetType string             `bson:"target_type" json:"target_type"`
+	TargetID   string             `bson:"target_id" json:"target_id"`
+	ScriptName string             `bson:"script_name" json:"script_name"`
+	Spec       string             `bson:"spec" json:"spec"`
+	Enabled    bool               `bson:"enabled" json:"enabled"`
+	LastRunAt  time.Time          `bson:"last_run_at,omitempty" json:"last_run_at,omitzero"`
+}
+
+// MongoScheduleRepository implements schedule.Repository using MongoDB.
+type MongoScheduleRepository struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+// NewMongoScheduleRepository creates a new MongoDB-based schedule repository.
+func NewMongoScheduleRepository(db *MongoDB, logger *slog.Logger) *MongoScheduleRepository {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &MongoScheduleRepository{
+		collection: db.Collection("schedule"),
+		logger:     logger,
+	}
+}
+
+// FindByID retrieves a schedule by its unique identifier.
+func (r *MongoScheduleRepository) FindByID(ctx context.Context, id string) (*schedule.Schedule, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID}
+	var doc scheduleDocument
+	if err := r.collection.FindOne(ctx, filter).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find schedule: %w", err)
+	}
+
+	return documentToSchedule(&doc), nil
+}
+
+// FindAll retrieves all schedules.
+func (r *MongoScheduleRepository) FindAll(ctx context.Context) ([]*schedule.Schedule, error) {
+	cursor, err := r.collection.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find schedules: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []scheduleDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode schedules: %w", err)
+	}
+
+	schedules := make([]*schedule.Schedule, len(docs))
+	for i, doc := range docs {
+		schedules[i] = documentToSchedule(&doc)
+	}
+
+	return schedules, nil
+}
+
+// Insert creates a new schedule.
+func (r *MongoScheduleRepository) Insert(ctx context.Context, sch *schedule.Schedule) error {
+	doc := scheduleToDocument(sch)
+	result, err := r.collection.InsertOne(ctx, doc)
+	if err != nil {
+		return fmt.Errorf("failed to insert schedule: %w", err)
+	}
+
+	// Update the schedule ID with the generated ObjectID
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		sch.ID = oid.Hex()
+	}
+
+	r.logger.Info("Schedule inserted", "id", sch.ID, "name", sch.Name)
+	return nil
+}
+
+// Update updates an existing schedule.
+func (r *MongoScheduleRepository) Update(ctx context.Context, sch *schedule.Schedule) error {
+	objectID, err := primitive.ObjectIDFromHex(sch.ID)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	doc := scheduleToDocument(sch)
+	doc.ID = objectID
+
+	filter := bson.M{"_id": objectID}
+	update := bson.M{"$set": doc}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return schedule.ErrScheduleNotFound
+	}
+
+	r.logger.Info("Schedule updated", "id", sch.ID, "name", sch.Name)
+	return nil
+}
+
+// UpdateLastRun records when a schedule last triggered.
+func (r *MongoScheduleRepository) UpdateLastRun(ctx context.Context, id string, at time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID}
+	update := bson.M{"$set": bson.M{"last_run_at": at}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update schedule last run: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return schedule.ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a schedule by its identifier.
+func (r *MongoScheduleRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return schedule.ErrScheduleNotFound
+	}
+
+	r.logger.Info("Schedule deleted", "id", id)
+	return nil
+}
+
+// documentToSchedule converts a MongoDB document to a domain Schedule.
+func documentToSchedule(doc *scheduleDocument) *schedule.Schedule {
+	return &schedule.Schedule{
+		ID:         doc.ID.Hex(),
+		Name:       doc.Name,
+		TargetType: schedule.TargetType(doc.TargetType),
+		TargetID:   doc.TargetID,
+		ScriptName: doc.ScriptName,
+		Spec:       doc.Spec,
+		Enabled:    doc.Enabled,
+		LastRunAt:  doc.LastRunAt,
+	}
+}
+
+// scheduleToDocument converts a domain Schedule to a MongoDB document.
+func scheduleToDocument(sch *schedule.Schedule) *scheduleDocument {
+	doc := &scheduleDocument{
+		Name:       sch.Name,
+		TargetType: string(sch.TargetType),
+		TargetID:   sch.TargetID,
+		ScriptName: sch.ScriptName,
+		Spec:       sch.Spec,
+		Enabled:    sch.Enabled,
+		LastRunAt:  sch.LastRunAt,
+	}
+
+	if sch.ID != "" {
+		if oid, err := primitive.ObjectIDFromHex(sch.ID); err == nil {
+			doc.ID = oid
+		}
+	}
+
+	return doc
+}
+
+// Ensure MongoScheduleRepository implements schedule.Repository
+var _ schedule.Repository = (*MongoScheduleRepository)(nil)
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"wardenly-go/infrastructure/stats"
+)
+
+// scriptRunDocument is the MongoDB document structure for script run
+// statistics.
+type scriptRunDocument struct {
+	AccountID   string         `bson:"account_id"`
+	AccountName string         `bson:"account_name,omitempty"`
+	ScriptName  string         `bson:"script_name"`
+	Day         string         `bson:"day"`
+	StartedAt   time.Time      `bson:"started_at"`
+	StoppedAt   time.Time      `bson:"stopped_at"`
+	StopReason  string         `bson:"stop_reason"`
+	Error       string         `bson:"error,omitempty"`
+	Counters    map[string]int `bson:"counters,omitempty"`
+}
+
+// MongoStatsStore implements stats.Store using MongoDB.
+type MongoStatsStore struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+// NewMongoStatsStore creates a new MongoDB-based script statistics store.
+func NewMongoStatsStore(db *MongoDB, logger *slog.Logger) *MongoStatsStore {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &MongoStatsStore{
+		collection: db.Collection("script_run_stats"),
+		logger:     logger,
+	}
+}
+
+// EnsureIndex creates the index used to look up runs by day and account.
+func (s *MongoStatsStore) EnsureIndex(ctx context.Context) error {
+	model := mongo.IndexModel{
+		Keys:    bson.D{{Key: "day", Value: 1}, {Key: "account_id", Value: 1}},
+		Options: options.Index().SetName("day_account"),
+	}
+	if _, err := s.collection.Indexes().CreateOne(ctx, model); err != nil {
+		return fmt.Errorf("failed to create script statistics index: %w", err)
+	}
+	return nil
+}
+
+// Add inserts a run.
+func (s *MongoStatsStore) Add(ctx context.Context, run *stats.Run) error {
+	doc := scriptRunDocument{
+		AccountID:   run.AccountID,
+		AccountName: run.AccountName,
+		ScriptName:  run.ScriptName,
+		Day:         run.Day,
+		StartedAt:   run.StartedAt,
+		StoppedAt:   run.StoppedAt,
+		StopReason:  run.StopReason,
+		Error:       run.Error,
+		Counters:    run.Counters,
+	}
+	if _, err := s.collection.InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("failed to insert script run: %w", err)
+	}
+	return nil
+}
+
+// Runs returns the runs of the days from through to, oldest first.
+func (s *MongoStatsStore) Runs(ctx context.Context, from, to string) ([]stats.Run, error) {
+	filter := bson.M{"day": bson.M{"$gte": from, "$lte": to}}
+	opts := options.Find().SetSort(bson.M{"started_at": 1})
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find script runs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []scriptRunDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode script runs: %w", err)
+	}
+	runs := make([]stats.Run, len(docs))
+	for i, doc := range docs {
+		runs[i] = stats.Run{
+			AccountID:   doc.AccountID,
+			AccountName: doc.AccountName,
+			ScriptName:  doc.ScriptName,
+			Day:         doc.Day,
+			StartedAt:   doc.StartedAt,
+			StoppedAt:   doc.StoppedAt,
+			StopReason:  doc.StopReason,
+			Error:       doc.Error,
+			Counters:    doc.Counters,
+		}
+	}
+	return runs, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"wardenly-go/domain/group"
+)
+
+// templateDocument is the stored document structure for group templates,
+// in MongoDB and in the JSON file store.
+type templateDocument struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name         string              `bson:"name" json:"name"`
+	Description  string              `bson:"description,omitempty" json:"description,omitempty"`
+	Settings     runSettingsDocument `bson:"settings" json:"settings"`
+	ScheduleSpec string              `bson:"schedule_spec" json:"schedule_spec"`
+}
+
+// MongoTemplateRepository implements group.TemplateRepository using MongoDB.
+type MongoTemplateRepository struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+// NewMongoTemplateRepository creates a new MongoDB-based template repository.
+func NewMongoTemplateRepository(db *MongoDB, logger *slog.Logger) *MongoTemplateRepository {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &MongoTemplateRepository{
+		collection: db.Collection("group_template"),
+		logger:     logger,
+	}
+}
+
+// FindByID retrieves a template by its unique identifier.
+func (r *MongoTemplateRepository) FindByID(ctx context.Context, id string) (*group.Template, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID}
+	var doc templateDocument
+	if err := r.collection.FindOne(ctx, filter).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find template: %w", err)
+	}
+
+	return documentToTemplate(&doc), nil
+}
+
+// FindAll retrieves all templates.
+func (r *MongoTemplateRepository) FindAll(ctx context.Context) ([]*group.Template, error) {
+	cursor, err := r.collection.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find templates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []templateDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode templates: %w", err)
+	}
+
+	templates := make([]*group.Template, len(docs))
+	for i, doc := range docs {
+		templates[i] = documentToTemplate(&doc)
+	}
+
+	return templates, nil
+}
+
+// Insert creates a new template.
+func (r *MongoTemplateRepository) Insert(ctx context.Context, tmpl *group.Template) error {
+	doc := templateToDocument(tmpl)
+	result, err := r.collection.InsertOne(ctx, doc)
+	if err != nil {
+		return fmt.Errorf("failed to insert template: %w", err)
+	}
+
+	// Update the template ID with the generated ObjectID
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		tmpl.ID = oid.Hex()
+	}
+
+	r.logger.Info("Template inserted", "id", tmpl.ID, "name", tmpl.Name)
+	return nil
+}
+
+// Update updates an existing template.
+func (r *MongoTemplateRepository) Update(ctx context.Context, tmpl *group.Template) error {
+	objectID, err := primitive.ObjectIDFromHex(tmpl.ID)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	doc := templateToDocument(tmpl)
+	doc.ID = objectID
+
+	filter := bson.M{"_id": objectID}
+	update := bson.M{"$set": doc}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update template: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return group.ErrTemplateNotFound
+	}
+
+	r.logger.Info("Template updated", "id", tmpl.ID, "name", tmpl.Name)
+	return nil
+}
+
+// Delete removes a template by its identifier.
+func (r *MongoTemplateRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return group.ErrTemplateNotFound
+	}
+
+	r.logger.Info("Template deleted", "id", id)
+	return nil
+}
+
+// documentToTemplate converts a MongoDB document to a domain Template.
+func documentToTemplate(doc *templateDocument) *group.Template {
+	return &group.Template{
+		ID:           doc.ID.Hex(),
+		Name:         doc.Name,
+		Description:  doc.Description,
+		Settings:     documentToRunSettings(doc.Settings),
+		ScheduleSpec: doc.ScheduleSpec,
+	}
+}
+
+// templateToDocument converts a domain Template to a MongoDB document.
+func templateToDocument(tmpl *group.Template) *templateDocument {
+	doc := &templateDocument{
+		Name:         tmpl.Name,
+		Description:  tmpl.Description,
+		Settings:     runSettingsToDocument(tmpl.Settings),
+		ScheduleSpec: tmpl.ScheduleSpec,
+	}
+
+	if tmpl.ID != "" {
+		if oid, err := primitive.ObjectIDFromHex(tmpl.ID); err == nil {
+			doc.ID = oid
+		}
+	}
+
+	return doc
+}
+
+// Ensure MongoTemplateRepository implements group.TemplateRepository
+var _ group.TemplateRepository = (*MongoTemplateRepository)(nil)
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"wardenly-go/infrastructure/trace"
+)
+
+// traceRunDocument is the MongoDB document structure for script runs.
+type traceRunDocument struct {
+	ID          string    `bson:"_id"`
+	SessionID   string    `bson:"session_id"`
+	AccountName string    `bson:"account_name,omitempty"`
+	ScriptName  string    `bson:"script_name"`
+	StartedAt   time.Time `bson:"started_at"`
+	StoppedAt   time.Time `bson:"stopped_at,omitempty"`
+	StopReason  string    `bson:"stop_reason,omitempty"`
+	Error       string    `bson:"error,omitempty"`
+	Steps       int       `bson:"steps"`
+}
+
+// traceEntryDocument is the MongoDB document structure for run entries.
+type traceEntryDocument struct {
+	RunID      string    `bson:"run_id"`
+	Seq        int64     `bson:"seq"`
+	Time       time.Time `bson:"time"`
+	Kind       string    `bson:"kind"`
+	Script     string    `bson:"script,omitempty"`
+	Step       int       `bson:"step"`
+	Scene      string    `bson:"scene,omitempty"`
+	Detail     string    `bson:"detail,omitempty"`
+	ScreenHash string    `bson:"screen_hash,omitempty"`
+}
+
+// MongoTraceStore implements trace.Store using MongoDB.
+type MongoTraceStore struct {
+	runs    *mongo.Collection
+	entries *mongo.Collection
+	logger  *slog.Logger
+}
+
+// NewMongoTraceStore creates a new MongoDB-based script trace store.
+func NewMongoTraceStore(db *MongoDB, logger *slog.Logger) *MongoTraceStore {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &MongoTraceStore{
+		runs:    db.Collection("trace_run"),
+		entries: db.Collection("trace_entry"),
+		logger:  logger,
+	}
+}
+
+// CreateRun inserts a new run.
+func (s *MongoTraceStore) CreateRun(ctx context.Context, run *trace.Run) error {
+	if _, err := s.runs.InsertOne(ctx, traceRunToDocument(run)); err != nil {
+		return fmt.Errorf("failed to insert trace run: %w", err)
+	}
+	return nil
+}
+
+// FinishRun replaces a run with its final state.
+func (s *MongoTraceStore) FinishRun(ctx context.Context, run *trace.Run) error {
+	if _, err := s.runs.ReplaceOne(ctx, bson.M{"_id": run.ID}, traceRunToDocument(run)); err != nil {
+		return fmt.Errorf("failed to update trace run: %w", err)
+	}
+	return nil
+}
+
+// AppendEntries inserts entries after the run's existing ones.
+func (s *MongoTraceStore) AppendEntries(ctx context.Context, runID string, entries []trace.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	// Entries of a run are written by one goroutine, so the next sequence
+	// number follows the stored count
+	seq, err := s.entries.CountDocuments(ctx, bson.M{"run_id": runID})
+	if err != nil {
+		return fmt.Errorf("failed to count trace entries: %w", err)
+	}
+
+	docs := make([]any, len(entries))
+	for i, e := range entries {
+		docs[i] = traceEntryDocument{
+			RunID:      runID,
+			Seq:        seq + int64(i),
+			Time:       e.Time,
+			Kind:       e.Kind,
+			Script:     e.Script,
+			Step:       e.Step,
+			Scene:      e.Scene,
+			Detail:     e.Detail,
+			ScreenHash: e.ScreenHash,
+		}
+	}
+	if _, err := s.entries.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("failed to insert trace entries: %w", err)
+	}
+	return nil
+}
+
+// Runs lists all runs, newest first.
+func (s *MongoTraceStore) Runs(ctx context.Context) ([]trace.Run, error) {
+	opts := options.Find().SetSort(bson.M{"started_at": -1})
+	cursor, err := s.runs.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trace runs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []traceRunDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode trace runs: %w", err)
+	}
+
+	runs := make([]trace.Run, len(docs))
+	for i, doc := range docs {
+		runs[i] = trace.Run{
+			ID:          doc.ID,
+			SessionID:   doc.SessionID,
+			AccountName: doc.AccountName,
+			ScriptName:  doc.ScriptName,
+			StartedAt:   doc.StartedAt,
+			StoppedAt:   doc.StoppedAt,
+			StopReason:  doc.StopReason,
+			Error:       doc.Error,
+			Steps:       doc.Steps,
+		}
+	}
+	return runs, nil
+}
+
+// Entries returns a run's entries in order.
+func (s *MongoTraceStore) Entries(ctx context.Context, runID string) ([]trace.Entry, error) {
+	opts := options.Find().SetSort(bson.M{"seq": 1})
+	cursor, err := s.entries.Find(ctx, bson.M{"run_id": runID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trace entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []traceEntryDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode trace entries: %w", err)
+	}
+
+	entries := make([]trace.Entry, len(docs))
+	for i, doc := range docs {
+		entries[i] = trace.Entry{
+			Time:       doc.Time,
+			Kind:       doc.Kind,
+			Script:     doc.Script,
+			Step:       doc.Step,
+			Scene:      doc.Scene,
+			Detail:     doc.Detail,
+			ScreenHash: doc.ScreenHash,
+		}
+	}
+	return entries, nil
+}
+
+// Prune deletes the oldest runs beyond keep with their entries.
+func (s *MongoTraceStore) Prune(ctx context.Context, keep int) error {
+	opts := options.Find().
+		SetSort(bson.M{"started_at": -1}).
+		SetSkip(int64(keep)).
+		SetProjection(bson.M{"_id": 1})
+	cursor, err := s.runs.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return fmt.Errorf("failed to find old trace runs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return fmt.Errorf("failed to decode old trace runs: %w", err)
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	if _, err := s.entries.DeleteMany(ctx, bson.M{"run_id": bson.M{"$in": ids}}); err != nil {
+		return fmt.Errorf("failed to delete trace entries: %w", err)
+	}
+	if _, err := s.runs.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		return fmt.Errorf("failed to delete trace runs: %w", err)
+	}
+	s.logger.Info("Pruned script traces", "count", len(ids))
+	return nil
+}
+
+func traceRunToDocument(run *trace.Run) *traceRunDocument {
+	return &traceRunDocument{
+		ID:          run.ID,
+		SessionID:   run.SessionID,
+		AccountName: run.AccountName,
+		ScriptName:  run.ScriptName,
+		StartedAt:   run.StartedAt,
+		StoppedAt:   run.StoppedAt,
+		StopReason:  run.StopReason,
+		Error:       run.Error,
+		Steps:       run.Steps,
+	}
+}
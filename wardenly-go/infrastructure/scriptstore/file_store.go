@@ -0,0 +1,203 @@
+// Package scriptstore persists script revisions on the local filesystem and
+// locates the user scripts and scenes directories.
+package scriptstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"wardenly-go/domain/script"
+	"wardenly-go/infrastructure/appdir"
+)
+
+// FileStoreConfig holds configuration for FileStore.
+type FileStoreConfig struct {
+	// Dir is the directory holding one index file per script.
+	// If empty, defaults to DefaultDir().
+	Dir string
+	// MaxRevisions is the number of revisions kept per script.
+	// The pinned revision is never dropped. Defaults to 20.
+	MaxRevisions int
+}
+
+// Environment variables overriding the user directories.
+const (
+	EnvScriptsDir = "WARDENLY_SCRIPTS_DIR"
+	EnvScenesDir  = "WARDENLY_SCENES_DIR"
+)
+
+// UserScriptsDir returns the directory of user scripts, which are loaded on
+// top of the embedded ones and reloaded when they change.
+func UserScriptsDir() string {
+	if dir := os.Getenv(EnvScriptsDir); dir != "" {
+		return dir
+	}
+	return appdir.Path("scripts")
+}
+
+// UserScenesDir returns the directory of user scene files, which are loaded
+// on top of the embedded ones and reloaded when they change.
+func UserScenesDir() string {
+	if dir := os.Getenv(EnvScenesDir); dir != "" {
+		return dir
+	}
+	return appdir.Path("scenes")
+}
+
+// DefaultDir returns the default version store directory.
+func DefaultDir() string {
+	return appdir.Path("script_versions")
+}
+
+// FileStore implements script.VersionStore with a JSON file per script.
+type FileStore struct {
+	dir          string
+	maxRevisions int
+	mu           sync.Mutex
+}
+
+// fileIndex is the on-disk layout of a script's history.
+type fileIndex struct {
+	Pinned    string         `json:"pinned,omitempty"`
+	Revisions []fileRevision `json:"revisions"` // oldest first
+}
+
+type fileRevision struct {
+	Hash    string    `json:"hash"`
+	SavedAt time.Time `json:"saved_at"`
+	Content string    `json:"content"`
+}
+
+// NewFileStore creates a file-backed version store, creating its directory.
+func NewFileStore(cfg FileStoreConfig) (*FileStore, error) {
+	if cfg.Dir == "" {
+		cfg.Dir = DefaultDir()
+	}
+	if cfg.MaxRevisions <= 0 {
+		cfg.MaxRevisions = 20
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create script version dir: %w", err)
+	}
+	return &FileStore{dir: cfg.Dir, maxRevisions: cfg.MaxRevisions}, nil
+}
+
+// Revisions returns all stored revisions of a script, newest first.
+func (s *FileStore) Revisions(name string) ([]script.Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]script.Revision, len(idx.Revisions))
+	for i, fr := range idx.Revisions {
+		revisions[len(revisions)-1-i] = script.Revision{
+			Hash:    fr.Hash,
+			SavedAt: fr.SavedAt,
+			Content: []byte(fr.Content),
+		}
+	}
+	return revisions, nil
+}
+
+// Append stores a new revision, dropping the oldest unpinned ones beyond the limit.
+func (s *FileStore) Append(name string, rev script.Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.read(name)
+	if err != nil {
+		return err
+	}
+
+	idx.Revisions = append(idx.Revisions, fileRevision{
+		Hash:    rev.Hash,
+		SavedAt: rev.SavedAt,
+		Content: string(rev.Content),
+	})
+	for excess := len(idx.Revisions) - s.maxRevisions; excess > 0; excess-- {
+		for i, fr := range idx.Revisions {
+			if fr.Hash != idx.Pinned {
+				idx.Revisions = append(idx.Revisions[:i], idx.Revisions[i+1:]...)
+				break
+			}
+		}
+	}
+	return s.write(name, idx)
+}
+
+// Pinned returns the hash of the pinned revision, or "" if none.
+func (s *FileStore) Pinned(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.read(name)
+	if err != nil {
+		return "", err
+	}
+	return idx.Pinned, nil
+}
+
+// SetPinned pins a revision by hash. An empty hash clears the pin.
+func (s *FileStore) SetPinned(name, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.read(name)
+	if err != nil {
+		return err
+	}
+	if idx.Pinned == hash {
+		return nil
+	}
+	idx.Pinned = hash
+	return s.write(name, idx)
+}
+
+func (s *FileStore) path(name string) string {
+	return filepath.Join(s.dir, url.PathEscape(name)+".json")
+}
+
+// read loads a script's index. A missing file yields an empty index.
+func (s *FileStore) read(name string) (*fileIndex, error) {
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return &fileIndex{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script versions: %w", err)
+	}
+
+	var idx fileIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("failed to parse script versions: %w", err)
+	}
+	return &idx, nil
+}
+
+// write saves a script's index atomically via a temp file and rename.
+func (s *FileStore) write(name string, idx *fileIndex) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode script versions: %w", err)
+	}
+
+	path := s.path(name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write script versions: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write script versions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package scriptstore
+
+import (
+	"testing"
+	"time"
+
+	"wardenly-go/domain/script"
+)
+
+func TestFileStore_AppendAndReload(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(FileStoreConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+
+	saved := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, hash := range []string{"a", "b"} {
+		rev := script.Revision{Hash: hash, SavedAt: saved, Content: []byte("name: " + hash)}
+		if err := store.Append("daily/task", rev); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	if err := store.SetPinned("daily/task", "a"); err != nil {
+		t.Fatalf("SetPinned() error = %v", err)
+	}
+
+	reopened, _ := NewFileStore(FileStoreConfig{Dir: dir})
+	revisions, err := reopened.Revisions("daily/task")
+	if err != nil {
+		t.Fatalf("Revisions() error = %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Hash != "b" || string(revisions[1].Content) != "name: a" {
+		t.Fatalf("Revisions() = %+v, want newest first", revisions)
+	}
+	if !revisions[0].SavedAt.Equal(saved) {
+		t.Errorf("SavedAt = %v, want %v", revisions[0].SavedAt, saved)
+	}
+	if pinned, _ := reopened.Pinned("daily/task"); pinned != "a" {
+		t.Errorf("Pinned() = %q, want a", pinned)
+	}
+}
+
+func TestFileStore_TrimKeepsPinned(t *testing.T) {
+	store, _ := NewFileStore(FileStoreConfig{Dir: t.TempDir(), MaxRevisions: 2})
+
+	store.Append("daily", script.Revision{Hash: "a"})
+	store.SetPinned("daily", "a")
+	store.Append("daily", script.Revision{Hash: "b"})
+	store.Append("daily", script.Revision{Hash: "c"})
+
+	revisions, _ := store.Revisions("daily")
+	if len(revisions) != 2 || revisions[0].Hash != "c" || revisions[1].Hash != "a" {
+		t.Errorf("Revisions() = %+v, want [c a]", revisions)
+	}
+}
+
+func TestFileStore_Missing(t *testing.T) {
+	store, _ := NewFileStore(FileStoreConfig{Dir: t.TempDir()})
+
+	revisions, err := store.Revisions("unknown")
+	if err != nil || len(revisions) != 0 {
+		t.Errorf("Revisions() = %v, %v, want empty", revisions, err)
+	}
+}
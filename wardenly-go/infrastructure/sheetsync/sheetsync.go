@@ -0,0 +1,205 @@
+// Package sheetsync reads account spreadsheets for account sync. A source is
+// a CSV or XLSX file exported from Excel or Google Sheets, or an http(s) URL
+// serving CSV, such as a published Google Sheet. Sync settings are saved to a
+// JSON file next to the other user settings.
+package sheetsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"wardenly-go/domain/account"
+	"wardenly-go/infrastructure/appdir"
+)
+
+// maxDownloadSize caps a spreadsheet fetched from a URL.
+const maxDownloadSize = 16 << 20
+
+// Settings configures account sync.
+type Settings struct {
+	// Source is a .csv or .xlsx file path, or an http(s) URL serving CSV
+	Source string `json:"source"`
+	// Columns maps account fields to the sheet's column headers
+	Columns account.ColumnMapping `json:"columns,omitempty"`
+	// IntervalMinutes re-syncs the source on a timer, applying changes
+	// without review; 0 syncs only on demand
+	IntervalMinutes int `json:"intervalMinutes,omitempty"`
+}
+
+// Interval returns the scheduled re-sync interval, or 0 if there is none.
+func (s Settings) Interval() time.Duration {
+	if s.Source == "" || s.IntervalMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(s.IntervalMinutes) * time.Minute
+}
+
+// DefaultPath returns where sync settings are stored.
+func DefaultPath() string {
+	return appdir.Path("account_sync.json")
+}
+
+// LoadSettings reads settings saved by SaveSettings. A missing file yields
+// empty settings.
+func LoadSettings(path string) (Settings, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Settings{}, nil
+	}
+	if err != nil {
+		return Settings{}, fmt.Errorf("failed to read account sync settings: %w", err)
+	}
+
+	var s Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Settings{}, fmt.Errorf("failed to parse account sync settings %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// SaveSettings writes settings, creating their directory.
+func SaveSettings(path string, s Settings) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode account sync settings: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create account sync settings dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write account sync settings: %w", err)
+	}
+	return nil
+}
+
+// Reader reads spreadsheets from files and URLs.
+type Reader struct {
+	client *http.Client
+}
+
+// NewReader creates a reader. A nil client uses one with a 30s timeout.
+func NewReader(client *http.Client) *Reader {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Reader{client: client}
+}
+
+// Read loads the first sheet of a source. The first non-empty row is the
+// header.
+func (r *Reader) Read(ctx context.Context, source string) (*account.Sheet, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, errors.New("no spreadsheet source set")
+	}
+
+	var rows [][]string
+	var err error
+	switch {
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		rows, err = r.fetchCSV(ctx, GoogleSheetsCSVURL(source))
+	case strings.EqualFold(filepath.Ext(source), ".xlsx"):
+		rows, err = ReadXLSX(source)
+	case strings.EqualFold(filepath.Ext(source), ".csv"):
+		var data []byte
+		if data, err = os.ReadFile(source); err == nil {
+			rows, err = parseCSV(data)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported spreadsheet %s: use a .csv or .xlsx file or a URL", source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toSheet(rows)
+}
+
+// fetchCSV downloads a CSV document.
+func (r *Reader) fetchCSV(ctx context.Context, rawURL string) ([][]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch spreadsheet: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("spreadsheet URL returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download spreadsheet: %w", err)
+	}
+	if len(data) > maxDownloadSize {
+		return nil, fmt.Errorf("spreadsheet is larger than %d MB", maxDownloadSize>>20)
+	}
+	return parseCSV(data)
+}
+
+// sheetsEditURL matches the address of a Google Sheet opened in the browser.
+var sheetsEditURL = regexp.MustCompile(`^https://docs\.google\.com/spreadsheets/d/([\w-]+)`)
+
+// GoogleSheetsCSVURL turns the browser address of a Google Sheet into its
+// CSV export URL, keeping the tab (gid). Other URLs are returned unchanged.
+// The sheet must be shared with anyone who has the link.
+func GoogleSheetsCSVURL(rawURL string) string {
+	m := sheetsEditURL.FindStringSubmatch(rawURL)
+	if m == nil || strings.Contains(rawURL, "/export") || strings.Contains(rawURL, "/pub") {
+		return rawURL
+	}
+	export := "https://docs.google.com/spreadsheets/d/" + m[1] + "/export?format=csv"
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return export
+	}
+	gid := u.Query().Get("gid")
+	if gid == "" {
+		if frag, err := url.ParseQuery(u.Fragment); err == nil {
+			gid = frag.Get("gid")
+		}
+	}
+	if gid != "" {
+		export += "&gid=" + url.QueryEscape(gid)
+	}
+	return export
+}
+
+// parseCSV reads CSV, tolerating a byte order mark and ragged rows.
+func parseCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	return rows, nil
+}
+
+// toSheet splits rows into a header and data rows, skipping leading blank rows.
+func toSheet(rows [][]string) (*account.Sheet, error) {
+	for i, row := range rows {
+		for _, cell := range row {
+			if strings.TrimSpace(cell) != "" {
+				return &account.Sheet{Header: row, Rows: rows[i+1:]}, nil
+			}
+		}
+	}
+	return nil, errors.New("spreadsheet is empty")
+}
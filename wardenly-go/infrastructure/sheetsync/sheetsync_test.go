@@ -0,0 +1,165 @@
+package sheetsync
+
+import (
+	"archive/zip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"wardenly-go/domain/account"
+)
+
+func TestReader_ReadCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.csv")
+	data := "\xef\xbb\xbf\n" + "server_id,role_name\n126,Alice\n127,\"Bob, Jr\",extra\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sheet, err := NewReader(nil).Read(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	want := &account.Sheet{
+		Header: []string{"server_id", "role_name"},
+		Rows:   [][]string{{"126", "Alice"}, {"127", "Bob, Jr", "extra"}},
+	}
+	if !reflect.DeepEqual(sheet, want) {
+		t.Errorf("Read() = %+v, want %+v", sheet, want)
+	}
+}
+
+func TestReader_ReadURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("server_id,role_name\n1,A\n"))
+	}))
+	defer srv.Close()
+
+	reader := NewReader(srv.Client())
+	sheet, err := reader.Read(context.Background(), srv.URL+"/accounts.csv")
+	if err != nil || len(sheet.Rows) != 1 {
+		t.Fatalf("Read() = %+v, %v", sheet, err)
+	}
+	if _, err := reader.Read(context.Background(), srv.URL+"/missing"); err == nil {
+		t.Error("Read() should fail on a non-200 response")
+	}
+}
+
+func TestReader_ReadXLSX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.xlsx")
+	writeZip(t, path, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Accounts" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId3" Target="worksheets/accounts.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst><si><t>server_id</t></si><si><t>role_name</t></si>` +
+			`<si><r><t>Al</t></r><r><t>ice</t></r></si></sst>`,
+		"xl/worksheets/accounts.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="inlineStr"><is><t>archived</t></is></c></row>` +
+			`<row r="2"><c r="A2"><v>126</v></c><c r="B2" t="s"><v>2</v></c><c r="D2" t="b"><v>1</v></c></row>` +
+			`</sheetData></worksheet>`,
+	})
+
+	sheet, err := NewReader(nil).Read(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	want := &account.Sheet{
+		Header: []string{"server_id", "role_name", "", "archived"},
+		Rows:   [][]string{{"126", "Alice", "", "true"}},
+	}
+	if !reflect.DeepEqual(sheet, want) {
+		t.Errorf("Read() = %+v, want %+v", sheet, want)
+	}
+}
+
+func TestReader_ReadUnsupported(t *testing.T) {
+	for _, source := range []string{"", "accounts.ods"} {
+		if _, err := NewReader(nil).Read(context.Background(), source); err == nil {
+			t.Errorf("Read(%q) should fail", source)
+		}
+	}
+}
+
+func TestGoogleSheetsCSVURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{
+			"https://docs.google.com/spreadsheets/d/abc-123/edit#gid=42",
+			"https://docs.google.com/spreadsheets/d/abc-123/export?format=csv&gid=42",
+		},
+		{
+			"https://docs.google.com/spreadsheets/d/abc/edit?usp=sharing",
+			"https://docs.google.com/spreadsheets/d/abc/export?format=csv",
+		},
+		{
+			"https://docs.google.com/spreadsheets/d/e/xyz/pub?output=csv",
+			"https://docs.google.com/spreadsheets/d/e/xyz/pub?output=csv",
+		},
+		{"https://example.com/accounts.csv", "https://example.com/accounts.csv"},
+	}
+	for _, tt := range tests {
+		if got := GoogleSheetsCSVURL(tt.in); got != tt.want {
+			t.Errorf("GoogleSheetsCSVURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSettings_LoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "account_sync.json")
+
+	if s, err := LoadSettings(path); err != nil || s.Source != "" {
+		t.Fatalf("LoadSettings(missing) = %+v, %v", s, err)
+	}
+
+	want := Settings{
+		Source:          "accounts.csv",
+		Columns:         account.ColumnMapping{account.SyncFieldRoleName: "Character"},
+		IntervalMinutes: 30,
+	}
+	if err := SaveSettings(path, want); err != nil {
+		t.Fatalf("SaveSettings() error = %v", err)
+	}
+	got, err := LoadSettings(path)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadSettings() = %+v, %v, want %+v", got, err, want)
+	}
+	if got.Interval().Minutes() != 30 {
+		t.Errorf("Interval() = %v, want 30m", got.Interval())
+	}
+	if (Settings{IntervalMinutes: 30}).Interval() != 0 {
+		t.Error("Interval() should be 0 without a source")
+	}
+}
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,196 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+)
+
+const (
+	// queueSize is the number of events buffered for the writer. Events
+	// beyond it are dropped rather than slowing the bus down.
+	queueSize = 256
+	// storeTimeout bounds a single store call.
+	storeTimeout = 5 * time.Second
+	// StopReasonInterrupted marks runs still open when the recorder stops.
+	StopReasonInterrupted = "Interrupted"
+)
+
+// ErrStopped is returned by Flush once the recorder has stopped.
+var ErrStopped = errors.New("script statistics stopped")
+
+// flushMarker is queued behind the events to flush; done is closed when
+// the writer reaches it.
+type flushMarker struct {
+	done chan struct{}
+}
+
+func (*flushMarker) EventName() string { return "stats.Flush" }
+
+// account identifies the account a session runs.
+type account struct {
+	id   string
+	name string
+}
+
+// Recorder stores a Run for each script run announced on the bus.
+type Recorder struct {
+	config *Config
+	bus    eventbus.EventBus
+	store  Store
+	subID  string
+
+	queue *eventbus.Queue
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	// Only touched by the writer goroutine
+	accounts map[string]account // Session ID -> account
+	runs     map[string]*Run    // Session ID -> running script
+	failing  bool               // The last store call failed
+}
+
+// Start begins recording the script runs announced on bus to store. Stop
+// must be called to record the runs still open.
+func Start(cfg *Config, bus eventbus.EventBus, store Store) *Recorder {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	r := &Recorder{
+		config:   cfg,
+		bus:      bus,
+		store:    store,
+		queue:    eventbus.NewQueue(queueSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		accounts: make(map[string]account),
+		runs:     make(map[string]*Run),
+	}
+	r.subID = bus.Subscribe(r.enqueue)
+	go r.run()
+
+	cfg.Logger.Info("Script statistics started")
+	return r
+}
+
+// Stop unsubscribes and records the runs still open as interrupted.
+func (r *Recorder) Stop() {
+	r.once.Do(func() {
+		r.bus.Unsubscribe(r.subID)
+		close(r.stop)
+		<-r.done
+		if n := r.queue.Dropped(); n > 0 {
+			r.config.Logger.Warn("Script statistics dropped events", "count", n)
+		}
+	})
+}
+
+// Flush waits until the runs announced on the bus so far are stored.
+func (r *Recorder) Flush(ctx context.Context) error {
+	m := &flushMarker{done: make(chan struct{})}
+	if !r.queue.Push(m) {
+		return errors.New("script statistics queue full")
+	}
+	select {
+	case <-m.done:
+		return nil
+	case <-r.done:
+		return ErrStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue runs on the bus dispatch goroutine and must not block.
+func (r *Recorder) enqueue(e event.Event) {
+	switch e.(type) {
+	case *event.SessionStarted, *event.SessionStopped, *event.ScriptStarted, *event.ScriptStopped:
+	default:
+		return
+	}
+	r.queue.Push(e)
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	for {
+		select {
+		case q := <-r.queue.Events():
+			r.handle(q)
+		case <-r.stop:
+			r.queue.Drain(r.handle)
+			r.interruptAll()
+			return
+		}
+	}
+}
+
+func (r *Recorder) handle(q eventbus.Queued) {
+	switch e := q.Event.(type) {
+	case *event.SessionStarted:
+		r.accounts[e.SessionID()] = account{id: e.AccountID, name: e.AccountName}
+	case *event.SessionStopped:
+		delete(r.accounts, e.SessionID())
+	case *event.ScriptStarted:
+		// A session runs one script at a time; close a run whose stop was lost
+		r.finish(e.SessionID(), StopReasonInterrupted, "", nil, q.At)
+		acc := r.accounts[e.SessionID()]
+		r.runs[e.SessionID()] = &Run{
+			AccountID:   acc.id,
+			AccountName: acc.name,
+			ScriptName:  e.ScriptName,
+			Day:         Day(q.At),
+			StartedAt:   q.At,
+		}
+	case *event.ScriptStopped:
+		errText := ""
+		if e.Error != nil {
+			errText = e.Error.Error()
+		}
+		r.finish(e.SessionID(), e.Reason.String(), errText, e.Counters, q.At)
+	case *flushMarker:
+		close(e.done)
+	}
+}
+
+// finish stores the session's open run, if any.
+func (r *Recorder) finish(sessionID, reason, errText string, counters map[string]int, at time.Time) {
+	run := r.runs[sessionID]
+	if run == nil {
+		return
+	}
+	delete(r.runs, sessionID)
+	run.StoppedAt = at
+	run.StopReason = reason
+	run.Error = errText
+	run.Counters = counters
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := r.store.Add(ctx, run); err != nil {
+		if !r.failing {
+			r.config.Logger.Warn("Failed to record script run", "account", run.AccountName, "script", run.ScriptName, "error", err)
+		}
+		r.failing = true
+		return
+	}
+	if r.failing {
+		r.config.Logger.Info("Script statistics writes recovered")
+		r.failing = false
+	}
+}
+
+func (r *Recorder) interruptAll() {
+	now := time.Now()
+	for sessionID := range r.runs {
+		r.finish(sessionID, StopReasonInterrupted, "", nil, now)
+	}
+}
@@ -0,0 +1,122 @@
+// Package stats records the outcome of every script run per account and
+// day: when it ran, why it stopped and the counters it stopped with. The
+// counters and stop reasons of a run are otherwise lost once the script
+// stops, so runs per account, success rates and farm time can only be
+// reviewed from these records.
+package stats
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// EnvDisabled overrides stats.disabled in the config file.
+const EnvDisabled = "WARDENLY_STATS_DISABLED"
+
+// DayLayout formats the local date a run started.
+const DayLayout = "2006-01-02"
+
+// Stop reasons that count a run as failed. The others, including runs the
+// user stopped or that ran out of resources, are successes.
+var failedReasons = []string{"Error", "Stuck", "BrowserStopped"}
+
+// Run is the outcome of one script run.
+type Run struct {
+	AccountID   string
+	AccountName string
+	ScriptName  string
+	// Day is the local date the run started, in DayLayout
+	Day        string
+	StartedAt  time.Time
+	StoppedAt  time.Time
+	StopReason string
+	Error      string
+	// Counters holds the script's counters when it stopped
+	Counters map[string]int
+}
+
+// Duration returns how long the run took.
+func (r *Run) Duration() time.Duration {
+	return max(0, r.StoppedAt.Sub(r.StartedAt))
+}
+
+// Succeeded reports whether the run stopped for a reason other than an
+// error, a stuck script or a lost browser.
+func (r *Run) Succeeded() bool {
+	return !slices.Contains(failedReasons, r.StopReason)
+}
+
+// Store keeps the runs of all accounts.
+type Store interface {
+	// Add saves a run.
+	Add(ctx context.Context, run *Run) error
+	// Runs returns the runs of the days from through to, inclusive and in
+	// DayLayout, oldest first.
+	Runs(ctx context.Context, from, to string) ([]Run, error)
+}
+
+// Config holds recorder configuration.
+type Config struct {
+	// Disabled turns statistics off. They are on by default.
+	Disabled bool
+	Logger   *slog.Logger
+}
+
+// Enabled reports whether the recorder should be started.
+func (c *Config) Enabled() bool {
+	return c != nil && !c.Disabled
+}
+
+// Day returns the local date of t in DayLayout.
+func Day(t time.Time) string {
+	return t.Local().Format(DayLayout)
+}
+
+// Summary totals the runs of an account.
+type Summary struct {
+	AccountID   string
+	AccountName string
+	Runs        int
+	Succeeded   int
+	// FarmTime is the time spent running scripts
+	FarmTime time.Duration
+}
+
+// SuccessRate returns the share of runs that succeeded, in [0, 1].
+func (s *Summary) SuccessRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Succeeded) / float64(s.Runs)
+}
+
+// Summarize totals runs per account, ordered by account name.
+func Summarize(runs []Run) []Summary {
+	index := make(map[string]int)
+	var summaries []Summary
+	for _, run := range runs {
+		i, ok := index[run.AccountID]
+		if !ok {
+			i = len(summaries)
+			index[run.AccountID] = i
+			summaries = append(summaries, Summary{AccountID: run.AccountID})
+		}
+		s := &summaries[i]
+		// The newest name wins, in case the role was renamed
+		if run.AccountName != "" {
+			s.AccountName = run.AccountName
+		}
+		s.Runs++
+		if run.Succeeded() {
+			s.Succeeded++
+		}
+		s.FarmTime += run.Duration()
+	}
+	slices.SortFunc(summaries, func(a, b Summary) int {
+		return cmp.Or(cmp.Compare(a.AccountName, b.AccountName), cmp.Compare(a.AccountID, b.AccountID))
+	})
+	return summaries
+}
@@ -0,0 +1,137 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+)
+
+// memStore is an in-memory Store.
+type memStore struct {
+	mu   sync.Mutex
+	runs []Run
+}
+
+func (s *memStore) Add(ctx context.Context, run *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs = append(s.runs, *run)
+	return nil
+}
+
+func (s *memStore) Runs(ctx context.Context, from, to string) ([]Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Run
+	for _, run := range s.runs {
+		if run.Day >= from && run.Day <= to {
+			out = append(out, run)
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.runs)
+}
+
+// waitFor polls until cond holds or a deadline passes.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before the deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRecorder_RecordsRuns(t *testing.T) {
+	bus := eventbus.New(10)
+	defer bus.Close()
+	store := &memStore{}
+	r := Start(&Config{}, bus, store)
+
+	bus.Publish(event.NewSessionStarted("s1", "a1", "alice"))
+	bus.Publish(event.NewScriptStarted("s1", "daily"))
+	stopped := event.NewScriptStopped("s1", "daily", event.StopReasonError, errors.New("boom"))
+	stopped.Counters = map[string]int{"runs": 3}
+	bus.Publish(stopped)
+	waitFor(t, func() bool { return store.count() == 1 })
+
+	run := store.runs[0]
+	if run.AccountID != "a1" || run.AccountName != "alice" || run.ScriptName != "daily" ||
+		run.StopReason != "Error" || run.Error != "boom" || run.Counters["runs"] != 3 {
+		t.Errorf("run = %+v", run)
+	}
+	if run.Day != Day(run.StartedAt) || run.StoppedAt.Before(run.StartedAt) {
+		t.Errorf("run times = %s, %v - %v", run.Day, run.StartedAt, run.StoppedAt)
+	}
+
+	// Stop records the runs still open
+	bus.Publish(event.NewScriptStarted("s1", "farm"))
+	time.Sleep(20 * time.Millisecond)
+	r.Stop()
+	if store.count() != 2 || store.runs[1].ScriptName != "farm" || store.runs[1].StopReason != StopReasonInterrupted {
+		t.Errorf("runs after Stop = %+v", store.runs)
+	}
+}
+
+func TestRecorder_Flush(t *testing.T) {
+	bus := eventbus.New(10)
+	defer bus.Close()
+	store := &memStore{}
+	r := Start(&Config{}, bus, store)
+
+	// As delivered by the bus
+	r.enqueue(event.NewScriptStarted("s1", "daily"))
+	r.enqueue(event.NewScriptStopped("s1", "daily", event.StopReasonNormal, nil))
+
+	if err := r.Flush(t.Context()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if n := store.count(); n != 1 {
+		t.Errorf("runs after Flush = %d, want 1", n)
+	}
+
+	r.Stop()
+	if err := r.Flush(t.Context()); !errors.Is(err, ErrStopped) {
+		t.Errorf("Flush() after Stop error = %v, want ErrStopped", err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	start := time.Date(2026, 10, 16, 8, 0, 0, 0, time.Local)
+	runs := []Run{
+		{AccountID: "a2", AccountName: "bob", StopReason: "Normal", StartedAt: start, StoppedAt: start.Add(time.Hour)},
+		{AccountID: "a1", AccountName: "old", StopReason: "Manual", StartedAt: start, StoppedAt: start.Add(10 * time.Minute)},
+		{AccountID: "a1", AccountName: "alice", StopReason: "Stuck", StartedAt: start, StoppedAt: start.Add(20 * time.Minute)},
+		{AccountID: "a1", AccountName: "alice", StopReason: "ResourceExhausted", StartedAt: start, StoppedAt: start.Add(30 * time.Minute)},
+	}
+
+	got := Summarize(runs)
+	if len(got) != 2 {
+		t.Fatalf("Summarize() = %+v", got)
+	}
+	alice := got[0]
+	if alice.AccountName != "alice" || alice.Runs != 3 || alice.Succeeded != 2 || alice.FarmTime != time.Hour {
+		t.Errorf("alice = %+v", alice)
+	}
+	if rate := alice.SuccessRate(); rate < 0.66 || rate > 0.67 {
+		t.Errorf("SuccessRate() = %v, want 2/3", rate)
+	}
+	if got[1].AccountName != "bob" || got[1].SuccessRate() != 1 {
+		t.Errorf("bob = %+v", got[1])
+	}
+	if (&Summary{}).SuccessRate() != 0 {
+		t.Error("SuccessRate() of no runs is not 0")
+	}
+}
@@ -0,0 +1,170 @@
+package timelapse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrFFmpegNotFound is returned when MP4 export is requested but ffmpeg is
+// not on PATH.
+var ErrFFmpegNotFound = errors.New("ffmpeg not found on PATH (required for MP4 export)")
+
+// Export renders shots to path. The format is chosen by extension: .gif is
+// encoded directly, .mp4 is encoded by ffmpeg.
+func Export(ctx context.Context, path string, shots []Shot, opts Options) error {
+	if len(shots) == 0 {
+		return ErrNoFrames
+	}
+	opts = opts.withDefaults()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".gif":
+		return exportGIF(ctx, path, shots, opts)
+	case ".mp4":
+		return exportMP4(ctx, path, shots, opts)
+	default:
+		return fmt.Errorf("unsupported output format %q (use .gif or .mp4)", ext)
+	}
+}
+
+// exportGIF quantizes frames to the Plan 9 palette in parallel and writes
+// them with their planned delays.
+func exportGIF(ctx context.Context, path string, shots []Shot, opts Options) error {
+	anim := &gif.GIF{
+		Image: make([]*image.Paletted, len(shots)),
+		Delay: make([]int, len(shots)),
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+		indexes  = make(chan int)
+	)
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				img, err := render(shots[i], opts.Width)
+				if err != nil {
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errMu.Unlock()
+					continue
+				}
+				frame := image.NewPaletted(img.Bounds(), palette.Plan9)
+				draw.FloydSteinberg.Draw(frame, frame.Bounds(), img, image.Point{})
+				anim.Image[i] = frame
+				// GIF delays are in hundredths of a second.
+				anim.Delay[i] = max(2, int(shots[i].Delay.Milliseconds()/10))
+			}
+		}()
+	}
+send:
+	for i := range shots {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	if err := gif.EncodeAll(w, anim); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to encode GIF: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write GIF: %w", err)
+	}
+	return f.Close()
+}
+
+// exportMP4 pipes PNG frames to ffmpeg at a constant frame rate, repeating
+// each frame for its planned delay.
+func exportMP4(ctx context.Context, path string, shots []Shot, opts Options) error {
+	bin, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return ErrFFmpegNotFound
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin,
+		"-y", "-loglevel", "error",
+		"-f", "image2pipe", "-framerate", strconv.Itoa(opts.FPS), "-i", "-",
+		// H.264 with yuv420p needs even dimensions.
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2",
+		"-c:v", "libx264", "-pix_fmt", "yuv420p",
+		path,
+	)
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	writeErr := writeFrames(stdin, shots, opts)
+	stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return writeErr
+}
+
+func writeFrames(w io.Writer, shots []Shot, opts Options) error {
+	enc := &png.Encoder{CompressionLevel: png.BestSpeed}
+	frameTime := 1 / float64(opts.FPS)
+	for _, shot := range shots {
+		img, err := render(shot, opts.Width)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := enc.Encode(&buf, img); err != nil {
+			return fmt.Errorf("failed to encode frame: %w", err)
+		}
+		repeat := max(1, int(math.Round(shot.Delay.Seconds()/frameTime)))
+		for range repeat {
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return fmt.Errorf("failed to write frame to ffmpeg: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package timelapse
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	textPadding = 4
+	lineHeight  = 15
+)
+
+var (
+	bandColor = image.NewUniform(color.RGBA{A: 160})
+	textColor = image.NewUniform(color.White)
+)
+
+// render decodes a shot's frame, scales it to width and burns in the
+// timestamp (top left) and notes (bottom left).
+func render(shot Shot, width int) (*image.RGBA, error) {
+	src, err := shot.Frame.Image()
+	if err != nil {
+		return nil, err
+	}
+	img := scale(src, width)
+
+	drawLines(img, img.Bounds().Min.Y, []string{shot.Frame.Time.Format(time.DateTime)})
+	if len(shot.Notes) > 0 {
+		drawLines(img, img.Bounds().Max.Y-len(shot.Notes)*lineHeight-2*textPadding, shot.Notes)
+	}
+	return img, nil
+}
+
+// drawLines draws text lines on a translucent band starting at y.
+func drawLines(img *image.RGBA, y int, lines []string) {
+	d := &font.Drawer{Dst: img, Src: textColor, Face: basicfont.Face7x13}
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, d.MeasureString(line).Ceil())
+	}
+	x := img.Bounds().Min.X
+	band := image.Rect(x, y, x+width+2*textPadding, y+len(lines)*lineHeight+2*textPadding)
+	draw.Draw(img, band.Intersect(img.Bounds()), bandColor, image.Point{}, draw.Over)
+
+	ascent := basicfont.Face7x13.Ascent
+	for i, line := range lines {
+		d.Dot = fixed.P(x+textPadding, y+textPadding+i*lineHeight+ascent)
+		d.DrawString(line)
+	}
+}
+
+// scale copies src into a new RGBA image, scaled down to width with
+// nearest-neighbour sampling. Width 0 or at least the source width keeps
+// the original size.
+func scale(src image.Image, width int) *image.RGBA {
+	b := src.Bounds()
+	if width <= 0 || width >= b.Dx() {
+		dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+		return dst
+	}
+
+	height := max(1, b.Dy()*width/b.Dx())
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		sy := b.Min.Y + y*b.Dy()/height
+		for x := range width {
+			dst.Set(x, y, src.At(b.Min.X+x*b.Dx()/width, sy))
+		}
+	}
+	return dst
+}
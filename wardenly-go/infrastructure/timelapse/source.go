@@ -0,0 +1,258 @@
+package timelapse
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register JPEG decoder for saved and streamed frames
+	_ "image/png"  // register PNG decoder for saved frames
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoFrames is returned when a source contains no usable frames.
+var ErrNoFrames = errors.New("no frames found")
+
+// LoadFrameDir lists the PNG and JPEG screenshots in dir. Frames are timed
+// by their file name when it is a Unix millisecond timestamp (as written by
+// Save Screenshot), otherwise by modification time.
+func LoadFrameDir(dir string) ([]Frame, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frame directory: %w", err)
+	}
+
+	var frames []Frame
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
+			continue
+		}
+
+		at, ok := fileNameTime(e.Name())
+		if !ok {
+			info, err := e.Info()
+			if err != nil {
+				return nil, fmt.Errorf("failed to stat %s: %w", e.Name(), err)
+			}
+			at = info.ModTime()
+		}
+		path := filepath.Join(dir, e.Name())
+		frames = append(frames, Frame{Time: at, open: func() (image.Image, error) { return decodeFile(path) }})
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoFrames, dir)
+	}
+	return frames, nil
+}
+
+// fileNameTime parses a "<unix millis>.<ext>" file name.
+func fileNameTime(name string) (time.Time, bool) {
+	ms, err := strconv.ParseInt(strings.TrimSuffix(name, filepath.Ext(name)), 10, 64)
+	if err != nil || ms <= 0 {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
+
+func decodeFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath.Base(path), err)
+	}
+	return img, nil
+}
+
+// Recording is a session's frames and annotations read from an event log.
+type Recording struct {
+	SessionID string
+	Frames    []Frame
+	Notes     []Note
+}
+
+// streamMessage mirrors the JSON frames written by the WebSocket event stream.
+type streamMessage struct {
+	Type      string          `json:"type"`
+	SessionID string          `json:"sessionId"`
+	Time      time.Time       `json:"time"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// LoadEventLog reads an event stream recording: the JSON messages sent on
+// /events, one per line, with screenshots enabled. Frames closer together
+// than spacing are dropped while reading to bound memory use.
+//
+// When sessionID is empty the log must contain a single session. A log
+// recorded without screenshots yields only notes.
+func LoadEventLog(r io.Reader, sessionID string, spacing time.Duration) (*Recording, error) {
+	rec := &Recording{SessionID: sessionID}
+	var (
+		notes    []sessionNote
+		lastKept time.Time
+	)
+
+	dec := json.NewDecoder(r)
+	for {
+		var msg streamMessage
+		if err := dec.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read event log: %w", err)
+		}
+		if msg.SessionID == "" {
+			continue
+		}
+
+		if msg.Type != "ScreenCaptured" {
+			if text := describe(&msg); text != "" {
+				notes = append(notes, sessionNote{sessionID: msg.SessionID, Note: Note{Time: msg.Time, Text: text}})
+			}
+			continue
+		}
+
+		if rec.SessionID == "" {
+			rec.SessionID = msg.SessionID
+		} else if msg.SessionID != rec.SessionID {
+			if sessionID != "" {
+				continue
+			}
+			return nil, fmt.Errorf("event log contains frames from sessions %s and %s; select one", rec.SessionID, msg.SessionID)
+		}
+		if len(rec.Frames) > 0 && msg.Time.Sub(lastKept) < spacing {
+			continue
+		}
+
+		frame, err := streamFrame(&msg)
+		if err != nil {
+			return nil, err
+		}
+		rec.Frames = append(rec.Frames, frame)
+		lastKept = msg.Time
+	}
+	if rec.SessionID == "" {
+		for _, n := range notes {
+			if rec.SessionID == "" {
+				rec.SessionID = n.sessionID
+			} else if n.sessionID != rec.SessionID {
+				return nil, fmt.Errorf("event log contains sessions %s and %s; select one", rec.SessionID, n.sessionID)
+			}
+		}
+	}
+
+	for _, n := range notes {
+		if n.sessionID == rec.SessionID {
+			rec.Notes = append(rec.Notes, n.Note)
+		}
+	}
+	return rec, nil
+}
+
+// sessionNote is a note whose session is not yet known to be selected.
+type sessionNote struct {
+	Note
+	sessionID string
+}
+
+func streamFrame(msg *streamMessage) (Frame, error) {
+	var shot struct {
+		Image string `json:"image"`
+	}
+	if err := json.Unmarshal(msg.Data, &shot); err != nil {
+		return Frame{}, fmt.Errorf("failed to read screenshot at %s: %w", msg.Time.Format(time.RFC3339), err)
+	}
+	data, err := base64.StdEncoding.DecodeString(shot.Image)
+	if err != nil {
+		return Frame{}, fmt.Errorf("failed to decode screenshot at %s: %w", msg.Time.Format(time.RFC3339), err)
+	}
+	return Frame{Time: msg.Time, open: func() (image.Image, error) {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		return img, err
+	}}, nil
+}
+
+// describe returns the annotation text for an event, or "" for events that
+// are not annotated.
+func describe(msg *streamMessage) string {
+	var data map[string]any
+	if len(msg.Data) > 0 {
+		_ = json.Unmarshal(msg.Data, &data)
+	}
+	text := func(key string) string {
+		if v, ok := data[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+	withError := func(s string) string {
+		if err := text("error"); err != "" {
+			return s + ": " + err
+		}
+		return s
+	}
+
+	switch msg.Type {
+	case "SessionStarted":
+		return "Session started: " + text("account")
+	case "SessionStopped":
+		return withError("Session stopped")
+	case "SessionStateChanged":
+		return "State: " + text("from") + " -> " + text("to")
+	case "LoginSucceeded":
+		return "Login succeeded"
+	case "LoginFailed":
+		return withError("Login failed")
+	case "ScriptStarted":
+		return "Script started: " + text("script")
+	case "ScriptStopped":
+		return withError(fmt.Sprintf("Script stopped: %s (%s)", text("script"), text("reason")))
+	case "ScriptRefused":
+		s := "Script refused: " + text("script")
+		if reason := text("reason"); reason != "" {
+			s += ": " + reason
+		}
+		return s
+	case "OCRResultRecognized":
+		s := fmt.Sprintf("OCR %s: %s/%s", text("rule"), text("numerator"), text("denominator"))
+		if data["triggered"] == true {
+			s += " (stop)"
+		}
+		return s
+	}
+	return ""
+}
+
+// Trim keeps the frames and notes within [from, to]. Zero bounds are open.
+func Trim(frames []Frame, notes []Note, from, to time.Time) ([]Frame, []Note) {
+	in := func(t time.Time) bool {
+		return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+	}
+	var keptFrames []Frame
+	for _, f := range frames {
+		if in(f.Time) {
+			keptFrames = append(keptFrames, f)
+		}
+	}
+	var keptNotes []Note
+	for _, n := range notes {
+		if in(n.Time) {
+			keptNotes = append(keptNotes, n)
+		}
+	}
+	return keptFrames, keptNotes
+}
@@ -0,0 +1,150 @@
+// Package timelapse assembles a session's saved screenshots or a recorded
+// event stream into a sped-up video with timestamps and script events
+// burned in, exported as GIF or MP4.
+package timelapse
+
+import (
+	"image"
+	"slices"
+	"time"
+)
+
+// maxNotes is the number of annotation lines shown on a frame at once.
+const maxNotes = 4
+
+// Frame is a source screenshot. Images are decoded on demand so that long
+// recordings do not have to fit in memory.
+type Frame struct {
+	Time time.Time
+	open func() (image.Image, error)
+}
+
+// Image decodes the frame.
+func (f Frame) Image() (image.Image, error) {
+	return f.open()
+}
+
+// Note is an event annotation shown from its time onwards.
+type Note struct {
+	Time time.Time
+	Text string
+}
+
+// Options controls the pacing of the time-lapse.
+type Options struct {
+	// Speed is the speed-up factor: 60 turns an hour into a minute.
+	Speed float64
+	// FPS is the maximum output frame rate. Source frames closer together
+	// than one output frame are dropped.
+	FPS int
+	// MaxHold caps how long a single frame stays on screen, so that long
+	// gaps between screenshots do not stall the video.
+	MaxHold time.Duration
+	// NoteHold is how long an annotation stays on screen.
+	NoteHold time.Duration
+	// Width scales frames down to this width (0 keeps the original size).
+	Width int
+}
+
+// DefaultOptions returns options suited to sharing a day of activity.
+func DefaultOptions() Options {
+	return Options{
+		Speed:    60,
+		FPS:      10,
+		MaxHold:  2 * time.Second,
+		NoteHold: 2 * time.Second,
+	}
+}
+
+// withDefaults fills unset fields from DefaultOptions.
+func (o Options) withDefaults() Options {
+	def := DefaultOptions()
+	if o.Speed <= 0 {
+		o.Speed = def.Speed
+	}
+	if o.FPS <= 0 {
+		o.FPS = def.FPS
+	}
+	if o.MaxHold <= 0 {
+		o.MaxHold = def.MaxHold
+	}
+	if o.NoteHold <= 0 {
+		o.NoteHold = def.NoteHold
+	}
+	return o
+}
+
+// FrameSpacing returns the source time covered by one output frame.
+func (o Options) FrameSpacing() time.Duration {
+	o = o.withDefaults()
+	return time.Duration(o.Speed * float64(time.Second) / float64(o.FPS))
+}
+
+// scaled converts a source duration to output time.
+func (o Options) scaled(d time.Duration) time.Duration {
+	return min(time.Duration(float64(d)/o.Speed), o.MaxHold)
+}
+
+// Shot is one output frame: a source frame, how long it is shown and the
+// annotations drawn on it.
+type Shot struct {
+	Frame Frame
+	Delay time.Duration
+	Notes []string
+}
+
+// Plan lays frames and notes out on the output timeline. Frames closer
+// together than FrameSpacing are dropped; each note is attached to the frame
+// covering its time and kept on the following frames for NoteHold.
+func Plan(frames []Frame, notes []Note, opts Options) []Shot {
+	opts = opts.withDefaults()
+	frames = slices.Clone(frames)
+	slices.SortStableFunc(frames, func(a, b Frame) int { return a.Time.Compare(b.Time) })
+	notes = slices.Clone(notes)
+	slices.SortStableFunc(notes, func(a, b Note) int { return a.Time.Compare(b.Time) })
+
+	type activeNote struct {
+		text      string
+		remaining time.Duration
+	}
+	var (
+		shots  []Shot
+		active []activeNote
+		next   int
+	)
+	spacing := opts.FrameSpacing()
+	for i := 0; i < len(frames); {
+		j := i + 1
+		for j < len(frames) && frames[j].Time.Sub(frames[i].Time) < spacing {
+			j++
+		}
+
+		delay := opts.MaxHold
+		last := j == len(frames)
+		if !last {
+			delay = opts.scaled(frames[j].Time.Sub(frames[i].Time))
+		}
+
+		// Notes before the first frame go on it; notes after the last one go on the last.
+		for next < len(notes) && (last || notes[next].Time.Before(frames[j].Time)) {
+			active = append(active, activeNote{text: notes[next].Text, remaining: opts.NoteHold})
+			next++
+		}
+		if len(active) > maxNotes {
+			active = active[len(active)-maxNotes:]
+		}
+
+		shot := Shot{Frame: frames[i], Delay: delay}
+		kept := active[:0]
+		for _, n := range active {
+			shot.Notes = append(shot.Notes, n.text)
+			if n.remaining -= delay; n.remaining > 0 {
+				kept = append(kept, n)
+			}
+		}
+		active = kept
+		shots = append(shots, shot)
+		i = j
+	}
+	return shots
+}
@@ -0,0 +1,199 @@
+package timelapse
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+
+func testFrame(offset time.Duration) Frame {
+	return Frame{Time: t0.Add(offset), open: func() (image.Image, error) {
+		return image.NewRGBA(image.Rect(0, 0, 4, 4)), nil
+	}}
+}
+
+func TestPlan(t *testing.T) {
+	frames := []Frame{
+		testFrame(70 * time.Second),
+		testFrame(0),
+		testFrame(time.Second),
+		testFrame(10 * time.Second),
+	}
+	notes := []Note{
+		{Time: t0.Add(5 * time.Second), Text: "started"},
+		{Time: t0.Add(-5 * time.Second), Text: "before"},
+		{Time: t0.Add(15 * time.Second), Text: "ocr"},
+		{Time: t0.Add(100 * time.Second), Text: "after"},
+	}
+	opts := Options{Speed: 10, FPS: 1, MaxHold: 2 * time.Second, NoteHold: 1500 * time.Millisecond}
+
+	shots := Plan(frames, notes, opts)
+
+	want := []struct {
+		offset time.Duration
+		delay  time.Duration
+		notes  []string
+	}{
+		// The frame at 1s is within one output frame (10s of source) and is dropped.
+		{0, time.Second, []string{"before", "started"}},
+		// 60s of source at 10x is 6s, capped at MaxHold; "before" and "started" are still held.
+		{10 * time.Second, 2 * time.Second, []string{"before", "started", "ocr"}},
+		{70 * time.Second, 2 * time.Second, []string{"after"}},
+	}
+	if len(shots) != len(want) {
+		t.Fatalf("len(shots) = %d, want %d", len(shots), len(want))
+	}
+	for i, w := range want {
+		s := shots[i]
+		if !s.Frame.Time.Equal(t0.Add(w.offset)) {
+			t.Errorf("shot %d time = %v, want %v", i, s.Frame.Time, t0.Add(w.offset))
+		}
+		if s.Delay != w.delay {
+			t.Errorf("shot %d delay = %v, want %v", i, s.Delay, w.delay)
+		}
+		if !slices.Equal(s.Notes, w.notes) {
+			t.Errorf("shot %d notes = %v, want %v", i, s.Notes, w.notes)
+		}
+	}
+}
+
+func TestPlan_ZeroOptionsUseDefaults(t *testing.T) {
+	shots := Plan([]Frame{testFrame(0)}, nil, Options{})
+	if len(shots) != 1 || shots[0].Delay != DefaultOptions().MaxHold {
+		t.Errorf("shots = %+v, want one shot held for MaxHold", shots)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	frames := []Frame{testFrame(0), testFrame(time.Minute), testFrame(2 * time.Minute)}
+	notes := []Note{{Time: t0, Text: "a"}, {Time: t0.Add(time.Minute), Text: "b"}}
+
+	gotFrames, gotNotes := Trim(frames, notes, t0.Add(time.Minute), time.Time{})
+	if len(gotFrames) != 2 || len(gotNotes) != 1 || gotNotes[0].Text != "b" {
+		t.Errorf("Trim = %d frames, notes %v; want 2 frames, notes [b]", len(gotFrames), gotNotes)
+	}
+}
+
+func encodePNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 6))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func eventLog(t *testing.T, msgs ...map[string]any) *strings.Reader {
+	t.Helper()
+	var sb strings.Builder
+	for _, m := range msgs {
+		line, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sb.Write(line)
+		sb.WriteByte('\n')
+	}
+	return strings.NewReader(sb.String())
+}
+
+func TestLoadEventLog(t *testing.T) {
+	shot := map[string]any{"width": 8, "height": 6, "format": "jpeg", "image": base64.StdEncoding.EncodeToString(encodePNG(t))}
+	msg := func(typ, session string, offset time.Duration, data any) map[string]any {
+		return map[string]any{"type": typ, "sessionId": session, "time": t0.Add(offset), "data": data}
+	}
+	log := func() *strings.Reader {
+		return eventLog(t,
+			msg("ScriptStarted", "s1", 0, map[string]string{"script": "daily"}),
+			msg("ScreenCaptured", "s1", 0, shot),
+			msg("ScreenCaptured", "s1", time.Second, shot),
+			msg("ScriptStarted", "s2", time.Second, map[string]string{"script": "tower"}),
+			msg("OCRResultRecognized", "s1", 2*time.Second, map[string]any{"rule": "stamina", "numerator": 3, "denominator": 50, "triggered": true}),
+			msg("ScriptStepExecuted", "s1", 2*time.Second, nil),
+			msg("ScriptsReloaded", "", 3*time.Second, map[string]any{"scripts": []string{"daily"}}),
+			msg("ScreenCaptured", "s1", 5*time.Second, shot),
+			msg("ScriptStopped", "s1", 6*time.Second, map[string]string{"script": "daily", "reason": "Error", "error": "boom"}),
+		)
+	}
+
+	rec, err := LoadEventLog(log(), "", 2*time.Second)
+	if err != nil {
+		t.Fatalf("LoadEventLog: %v", err)
+	}
+	if rec.SessionID != "s1" {
+		t.Errorf("SessionID = %q, want s1", rec.SessionID)
+	}
+	if len(rec.Frames) != 2 || !rec.Frames[1].Time.Equal(t0.Add(5*time.Second)) {
+		t.Fatalf("frames = %+v, want frames at 0s and 5s", rec.Frames)
+	}
+	img, err := rec.Frames[0].Image()
+	if err != nil || img.Bounds().Dx() != 8 {
+		t.Errorf("Image() = %v, %v; want 8px wide image", img, err)
+	}
+	var texts []string
+	for _, n := range rec.Notes {
+		texts = append(texts, n.Text)
+	}
+	wantNotes := []string{"Script started: daily", "OCR stamina: 3/50 (stop)", "Script stopped: daily (Error): boom"}
+	if !slices.Equal(texts, wantNotes) {
+		t.Errorf("notes = %q, want %q", texts, wantNotes)
+	}
+
+	rec, err = LoadEventLog(log(), "s2", 0)
+	if err != nil {
+		t.Fatalf("LoadEventLog(s2): %v", err)
+	}
+	if len(rec.Frames) != 0 || len(rec.Notes) != 1 || rec.Notes[0].Text != "Script started: tower" {
+		t.Errorf("s2 recording = %+v, want no frames and one note", rec)
+	}
+
+	mixed := eventLog(t, msg("ScreenCaptured", "s1", 0, shot), msg("ScreenCaptured", "s2", time.Second, shot))
+	if _, err := LoadEventLog(mixed, "", 0); err == nil {
+		t.Error("LoadEventLog with frames from two sessions succeeded, want error")
+	}
+}
+
+func TestLoadFrameDir(t *testing.T) {
+	dir := t.TempDir()
+	data := encodePNG(t)
+	modTime := t0.Add(time.Hour)
+	for _, name := range []string{"1767340800000.png", "manual.png", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chtimes(filepath.Join(dir, "manual.png"), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	frames, err := LoadFrameDir(dir)
+	if err != nil {
+		t.Fatalf("LoadFrameDir: %v", err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("len(frames) = %d, want 2", len(frames))
+	}
+	if !frames[0].Time.Equal(time.UnixMilli(1767340800000)) {
+		t.Errorf("frames[0].Time = %v, want time from file name", frames[0].Time)
+	}
+	if !frames[1].Time.Equal(modTime) {
+		t.Errorf("frames[1].Time = %v, want modification time %v", frames[1].Time, modTime)
+	}
+	if _, err := frames[0].Image(); err != nil {
+		t.Errorf("Image(): %v", err)
+	}
+
+	if _, err := LoadFrameDir(t.TempDir()); err == nil {
+		t.Error("LoadFrameDir on empty directory succeeded, want ErrNoFrames")
+	}
+}
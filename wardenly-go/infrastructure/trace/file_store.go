@@ -0,0 +1,159 @@
+package trace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxLineSize bounds a single entry line when reading.
+	maxLineSize = 1 << 20
+	runSuffix   = ".run.json"
+	entrySuffix = ".jsonl"
+)
+
+// FileStore keeps each run as a small JSON description next to a JSON
+// lines file of its entries.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a file store in dir, creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trace dir: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Dir returns the directory runs are written to.
+func (s *FileStore) Dir() string {
+	return s.dir
+}
+
+// CreateRun writes the run description.
+func (s *FileStore) CreateRun(_ context.Context, run *Run) error {
+	return s.writeRun(run)
+}
+
+// FinishRun rewrites the run description.
+func (s *FileStore) FinishRun(_ context.Context, run *Run) error {
+	return s.writeRun(run)
+}
+
+func (s *FileStore) writeRun(run *Run) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode run: %w", err)
+	}
+	path := filepath.Join(s.dir, run.ID+runSuffix)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write run: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write run: %w", err)
+	}
+	return nil
+}
+
+// AppendEntries appends entries to the run's lines file.
+func (s *FileStore) AppendEntries(_ context.Context, runID string, entries []Entry) error {
+	var buf []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode trace entry: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	f, err := os.OpenFile(filepath.Join(s.dir, runID+entrySuffix), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open trace: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(buf); err != nil {
+		return fmt.Errorf("failed to write trace: %w", err)
+	}
+	return nil
+}
+
+// Runs lists the runs in the directory, newest first. Unreadable run
+// descriptions are skipped.
+func (s *FileStore) Runs(_ context.Context) ([]Run, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace dir: %w", err)
+	}
+
+	var runs []Run
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), runSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, f.Name()))
+		if err != nil {
+			continue
+		}
+		var run Run
+		if json.Unmarshal(data, &run) != nil || run.ID == "" {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartedAt.After(runs[j].StartedAt) })
+	return runs, nil
+}
+
+// Entries reads a run's entries. Lines that can't be parsed (such as one
+// cut short by a crash) are skipped.
+func (s *FileStore) Entries(_ context.Context, runID string) ([]Entry, error) {
+	f, err := os.Open(filepath.Join(s.dir, runID+entrySuffix))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open trace: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read trace: %w", err)
+	}
+	return entries, nil
+}
+
+// Prune removes the oldest runs beyond keep.
+func (s *FileStore) Prune(ctx context.Context, keep int) error {
+	runs, err := s.Runs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, run := range runs[min(keep, len(runs)):] {
+		for _, suffix := range []string{runSuffix, entrySuffix} {
+			if err := os.Remove(filepath.Join(s.dir, run.ID+suffix)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove trace: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+)
+
+const (
+	// queueSize is the number of events buffered for the writer. Events
+	// beyond it are dropped rather than slowing the bus down.
+	queueSize = 1024
+	// flushInterval is how often pending entries are written.
+	flushInterval = time.Second
+	// storeTimeout bounds a single store call.
+	storeTimeout = 5 * time.Second
+	// StopReasonInterrupted marks runs still open when the recorder stops.
+	StopReasonInterrupted = "Interrupted"
+)
+
+// activeRun is a run being recorded with its unwritten entries.
+type activeRun struct {
+	run     Run
+	pending []Entry
+}
+
+// Recorder turns script events from the bus into stored runs until stopped.
+type Recorder struct {
+	config *Config
+	bus    eventbus.EventBus
+	store  Store
+	subID  string
+
+	queue *eventbus.Queue
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	// Only touched by the writer goroutine
+	accounts map[string]string     // Session ID -> account name
+	runs     map[string]*activeRun // Session ID -> run
+}
+
+// Start prunes old runs and begins recording script runs from bus into
+// store. A nil store writes files to cfg.Dir. Stop must be called to write
+// pending entries.
+func Start(cfg *Config, bus eventbus.EventBus, store Store) (*Recorder, error) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.MaxRuns <= 0 {
+		cfg.MaxRuns = 200
+	}
+	if store == nil {
+		fileStore, err := NewFileStore(cfg.Dir)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Dir = fileStore.Dir()
+		store = fileStore
+	}
+
+	r := &Recorder{
+		config:   cfg,
+		bus:      bus,
+		store:    store,
+		queue:    eventbus.NewQueue(queueSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		accounts: make(map[string]string),
+		runs:     make(map[string]*activeRun),
+	}
+	if err := r.prune(); err != nil {
+		cfg.Logger.Warn("Failed to prune script traces", "error", err)
+	}
+
+	r.subID = bus.Subscribe(r.enqueue)
+	go r.run()
+
+	cfg.Logger.Info("Script trace recorder started", "store", cfg.Store, "maxRuns", cfg.MaxRuns)
+	return r, nil
+}
+
+// Store returns the store runs are recorded to.
+func (r *Recorder) Store() Store {
+	return r.store
+}
+
+// Stop unsubscribes, writes pending entries and closes open runs as
+// interrupted.
+func (r *Recorder) Stop() {
+	r.once.Do(func() {
+		r.bus.Unsubscribe(r.subID)
+		close(r.stop)
+		<-r.done
+		if n := r.queue.Dropped(); n > 0 {
+			r.config.Logger.Warn("Script trace recorder dropped events", "count", n)
+		}
+	})
+}
+
+// enqueue runs on the bus dispatch goroutine and must not block.
+func (r *Recorder) enqueue(e event.Event) {
+	switch e.(type) {
+	case *event.SessionStarted, *event.ScriptStarted, *event.ScriptStopped,
+		*event.ScriptStepExecuted, *event.ActionPerformed,
+		*event.OCRResultRecognized, *event.OCRTextRecognized:
+	default:
+		return
+	}
+	r.queue.Push(e)
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case q := <-r.queue.Events():
+			r.handle(q)
+		case <-ticker.C:
+			r.flushAll()
+		case <-r.stop:
+			r.queue.Drain(r.handle)
+			r.interruptAll()
+			return
+		}
+	}
+}
+
+func (r *Recorder) handle(q eventbus.Queued) {
+	switch e := q.Event.(type) {
+	case *event.SessionStarted:
+		r.accounts[e.SessionID()] = e.AccountName
+	case *event.ScriptStarted:
+		r.begin(e.SessionID(), e.ScriptName, q.At)
+	case *event.ScriptStopped:
+		errText := ""
+		if e.Error != nil {
+			errText = e.Error.Error()
+		}
+		r.finish(e.SessionID(), e.Reason.String(), errText, q.At)
+	case *event.ScriptStepExecuted:
+		active := r.runs[e.SessionID()]
+		if active == nil {
+			return
+		}
+		active.run.Steps++
+		r.add(active, Entry{
+			Time:       q.At,
+			Kind:       KindStep,
+			Script:     calledScript(active, e.ScriptName),
+			Step:       e.StepIndex,
+			Scene:      e.SceneName,
+			Detail:     fmt.Sprintf("%s -> %s", strings.Join(e.Actions, ", "), e.Result),
+			ScreenHash: e.ScreenHash,
+		})
+	case *event.ActionPerformed:
+		detail := fmt.Sprintf("%s (%.0f, %.0f)", e.Action, e.FromX, e.FromY)
+		if e.ToX != e.FromX || e.ToY != e.FromY {
+			detail += fmt.Sprintf(" -> (%.0f, %.0f)", e.ToX, e.ToY)
+		}
+		r.addTo(e.SessionID(), Entry{Time: q.At, Kind: KindAction, Script: e.ScriptName, Detail: detail})
+	case *event.OCRResultRecognized:
+		detail := fmt.Sprintf("%s %d/%d threshold %d", e.RuleName, e.Numerator, e.Denominator, e.Threshold)
+		if e.Triggered {
+			detail += " triggered"
+		}
+		r.addTo(e.SessionID(), Entry{Time: q.At, Kind: KindOCR, Script: e.ScriptName, Detail: detail})
+	case *event.OCRTextRecognized:
+		detail := fmt.Sprintf("%s [%s]", e.RuleName, strings.Join(e.Lines, " | "))
+		if e.Matched != "" {
+			detail += " matched " + e.Matched
+		}
+		r.addTo(e.SessionID(), Entry{Time: q.At, Kind: KindText, Script: e.ScriptName, Detail: detail})
+	}
+}
+
+// calledScript returns name if it is not the run's own script.
+func calledScript(active *activeRun, name string) string {
+	if name == active.run.ScriptName {
+		return ""
+	}
+	return name
+}
+
+func (r *Recorder) addTo(sessionID string, entry Entry) {
+	if active := r.runs[sessionID]; active != nil {
+		entry.Script = calledScript(active, entry.Script)
+		r.add(active, entry)
+	}
+}
+
+func (r *Recorder) add(active *activeRun, entry Entry) {
+	active.pending = append(active.pending, entry)
+	if len(active.pending) >= queueSize {
+		r.flush(active)
+	}
+}
+
+func (r *Recorder) begin(sessionID, scriptName string, at time.Time) {
+	// A session runs one script at a time; close a run whose stop was lost
+	if _, ok := r.runs[sessionID]; ok {
+		r.finish(sessionID, StopReasonInterrupted, "", at)
+	}
+
+	active := &activeRun{run: Run{
+		ID:          RunID(sessionID, at),
+		SessionID:   sessionID,
+		AccountName: r.accounts[sessionID],
+		ScriptName:  scriptName,
+		StartedAt:   at,
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := r.store.CreateRun(ctx, &active.run); err != nil {
+		r.config.Logger.Warn("Failed to create script trace", "session", sessionID, "error", err)
+		return
+	}
+	r.runs[sessionID] = active
+
+	if err := r.prune(); err != nil {
+		r.config.Logger.Warn("Failed to prune script traces", "error", err)
+	}
+}
+
+func (r *Recorder) finish(sessionID, reason, errText string, at time.Time) {
+	active := r.runs[sessionID]
+	if active == nil {
+		return
+	}
+	delete(r.runs, sessionID)
+	r.flush(active)
+
+	active.run.StoppedAt = at
+	active.run.StopReason = reason
+	active.run.Error = errText
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := r.store.FinishRun(ctx, &active.run); err != nil {
+		r.config.Logger.Warn("Failed to finish script trace", "run", active.run.ID, "error", err)
+	}
+}
+
+func (r *Recorder) flush(active *activeRun) {
+	if len(active.pending) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := r.store.AppendEntries(ctx, active.run.ID, active.pending); err != nil {
+		r.config.Logger.Warn("Failed to write script trace", "run", active.run.ID, "error", err)
+	}
+	active.pending = nil
+}
+
+func (r *Recorder) flushAll() {
+	for _, active := range r.runs {
+		r.flush(active)
+	}
+}
+
+func (r *Recorder) interruptAll() {
+	now := time.Now()
+	for sessionID := range r.runs {
+		r.finish(sessionID, StopReasonInterrupted, "", now)
+	}
+}
+
+func (r *Recorder) prune() error {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	return r.store.Prune(ctx, r.config.MaxRuns)
+}
+
+// RunID names a run started in a session at a time. IDs sort by start time.
+func RunID(sessionID string, at time.Time) string {
+	safe := strings.Map(func(c rune) rune {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' {
+			return c
+		}
+		return '_'
+	}, sessionID)
+	return at.UTC().Format("20060102-150405.000") + "-" + safe
+}
@@ -0,0 +1,140 @@
+// Package trace records what each script run did: the steps it matched,
+// the clicks and drags it sent, the OCR values it read and a hash of the
+// screen each step acted on. A run is stored from ScriptStarted to
+// ScriptStopped so a long or looping run can be inspected afterwards.
+package trace
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"wardenly-go/infrastructure/appdir"
+)
+
+// Environment variables overriding the trace section of the config file.
+const (
+	EnvDisabled = "WARDENLY_TRACE_DISABLED"
+	EnvDir      = "WARDENLY_TRACE_DIR"
+	EnvStore    = "WARDENLY_TRACE_STORE"
+	EnvMaxRuns  = "WARDENLY_TRACE_MAX_RUNS"
+)
+
+// Store kinds for WARDENLY_TRACE_STORE.
+const (
+	StoreFile  = "file"
+	StoreMongo = "mongo"
+)
+
+// Entry kinds.
+const (
+	KindStep   = "step"
+	KindAction = "action"
+	KindOCR    = "ocr"
+	KindText   = "text"
+)
+
+// Run describes one script run.
+type Run struct {
+	ID          string    `json:"id"`
+	SessionID   string    `json:"sessionId"`
+	AccountName string    `json:"accountName,omitempty"`
+	ScriptName  string    `json:"scriptName"`
+	StartedAt   time.Time `json:"startedAt"`
+	StoppedAt   time.Time `json:"stoppedAt,omitzero"` // Zero while running
+	StopReason  string    `json:"stopReason,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	Steps       int       `json:"steps"`
+}
+
+// Entry is one recorded step, action or OCR result of a run.
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Kind   string    `json:"kind"`
+	Script string    `json:"script,omitempty"` // Set inside called scripts
+	Step   int       `json:"step"`             // Step index, for KindStep
+	Scene  string    `json:"scene,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+	// ScreenHash is the perceptual hash of the screen a step acted on.
+	ScreenHash string `json:"screenHash,omitempty"`
+}
+
+// Store persists runs and their entries.
+type Store interface {
+	// CreateRun saves a new run.
+	CreateRun(ctx context.Context, run *Run) error
+	// FinishRun updates a run once it has stopped.
+	FinishRun(ctx context.Context, run *Run) error
+	// AppendEntries adds entries to a run.
+	AppendEntries(ctx context.Context, runID string, entries []Entry) error
+	// Runs lists the stored runs, newest first.
+	Runs(ctx context.Context) ([]Run, error)
+	// Entries returns a run's entries in order.
+	Entries(ctx context.Context, runID string) ([]Entry, error)
+	// Prune deletes the oldest runs beyond keep.
+	Prune(ctx context.Context, keep int) error
+}
+
+// Config holds recorder configuration.
+type Config struct {
+	// Disabled turns tracing off. It is on by default.
+	Disabled bool
+	// Store selects where runs are kept: StoreFile (default) or StoreMongo.
+	Store string
+	// Dir is where the file store writes runs.
+	// If empty, defaults to DefaultDir().
+	Dir string
+	// MaxRuns is the number of runs retained.
+	MaxRuns int
+	Logger  *slog.Logger
+}
+
+// DefaultDir returns the default trace directory.
+func DefaultDir() string {
+	return appdir.Path("traces")
+}
+
+// Enabled reports whether the recorder should be started.
+func (c *Config) Enabled() bool {
+	return c != nil && !c.Disabled
+}
+
+// SceneCount is how often a run executed steps on a scene.
+type SceneCount struct {
+	Scene string
+	Count int
+	// Screens is the number of distinct screen hashes seen on the scene.
+	Screens int
+}
+
+// SceneCounts summarizes the executed steps of entries by scene, most
+// frequent first. A scene with many steps but few distinct screens is
+// where a run was stuck.
+func SceneCounts(entries []Entry) []SceneCount {
+	index := make(map[string]int)
+	screens := make(map[string]map[string]bool)
+	var counts []SceneCount
+	for _, e := range entries {
+		if e.Kind != KindStep {
+			continue
+		}
+		i, ok := index[e.Scene]
+		if !ok {
+			i = len(counts)
+			index[e.Scene] = i
+			counts = append(counts, SceneCount{Scene: e.Scene})
+			screens[e.Scene] = make(map[string]bool)
+		}
+		counts[i].Count++
+		if e.ScreenHash != "" {
+			screens[e.Scene][e.ScreenHash] = true
+		}
+	}
+	for i := range counts {
+		counts[i].Screens = len(screens[counts[i].Scene])
+	}
+	slices.SortStableFunc(counts, func(a, b SceneCount) int { return cmp.Compare(b.Count, a.Count) })
+	return counts
+}
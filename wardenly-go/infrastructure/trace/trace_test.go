@@ -0,0 +1,132 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"wardenly-go/core/event"
+	"wardenly-go/core/eventbus"
+)
+
+func TestRecorder_RecordsRun(t *testing.T) {
+	dir := t.TempDir()
+	bus := eventbus.New(10)
+	defer bus.Close()
+
+	r, err := Start(&Config{Dir: dir}, bus, nil)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	bus.Publish(event.NewSessionStarted("s1", "a1", "alice"))
+	bus.Publish(event.NewScriptStarted("s1", "daily"))
+	bus.Publish(event.NewScriptStepExecuted("s1", "daily", 2, "main_city", []string{"click", "wait"}, "continue", "00ff00ff00ff00ff"))
+	bus.Publish(event.NewActionPerformed("s1", "daily", "click", 10, 20, 10, 20))
+	bus.Publish(event.NewOCRResultRecognized("s1", "daily", "stamina", 3, 10, 5, true))
+	bus.Publish(event.NewScriptStepExecuted("s1", "collect", 0, "mail", []string{"drag"}, "skipped", ""))
+	bus.Publish(event.NewScriptStopped("s1", "daily", event.StopReasonError, errors.New("boom")))
+	bus.Publish(event.NewScriptStepExecuted("s1", "daily", 3, "late", nil, "continue", "")) // After the run
+
+	store := r.Store()
+	var runs []Run
+	deadline := time.Now().Add(2 * time.Second)
+	for (len(runs) == 0 || runs[0].StoppedAt.IsZero()) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		runs, _ = store.Runs(context.Background())
+	}
+	r.Stop()
+
+	if len(runs) != 1 {
+		t.Fatalf("runs = %+v, want 1", runs)
+	}
+	run := runs[0]
+	if run.AccountName != "alice" || run.ScriptName != "daily" || run.Steps != 2 ||
+		run.StopReason != "Error" || run.Error != "boom" {
+		t.Errorf("run = %+v", run)
+	}
+
+	entries, err := store.Entries(context.Background(), run.ID)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Kind+" "+e.Script+" "+e.Scene+" "+e.Detail+" "+e.ScreenHash)
+	}
+	want := []string{
+		"step  main_city click, wait -> continue 00ff00ff00ff00ff",
+		"action   click (10, 20) ",
+		"ocr   stamina 3/10 threshold 5 triggered ",
+		"step collect mail drag -> skipped ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("entries =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRecorder_InterruptsOpenRuns(t *testing.T) {
+	dir := t.TempDir()
+	bus := eventbus.New(10)
+	defer bus.Close()
+
+	r, err := Start(&Config{Dir: dir}, bus, nil)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	bus.Publish(event.NewScriptStarted("s1", "daily"))
+	time.Sleep(50 * time.Millisecond)
+	r.Stop()
+
+	runs, err := r.Store().Runs(context.Background())
+	if err != nil || len(runs) != 1 || runs[0].StopReason != StopReasonInterrupted || runs[0].StoppedAt.IsZero() {
+		t.Errorf("runs = %+v, err = %v", runs, err)
+	}
+}
+
+func TestFileStore_Prune(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		at := start.Add(time.Duration(i) * time.Hour)
+		run := &Run{ID: RunID("s1", at), SessionID: "s1", StartedAt: at}
+		if err := store.CreateRun(ctx, run); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.AppendEntries(ctx, run.ID, []Entry{{Kind: KindStep}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.Prune(ctx, 2); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	runs, _ := store.Runs(ctx)
+	if len(runs) != 2 || !runs[0].StartedAt.Equal(start.Add(2*time.Hour)) || !runs[1].StartedAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("runs after prune = %+v", runs)
+	}
+	if entries, _ := store.Entries(ctx, RunID("s1", start)); len(entries) != 0 {
+		t.Errorf("pruned run still has %d entries", len(entries))
+	}
+}
+
+func TestSceneCounts(t *testing.T) {
+	entries := []Entry{
+		{Kind: KindStep, Scene: "a", ScreenHash: "1"},
+		{Kind: KindStep, Scene: "b", ScreenHash: "2"},
+		{Kind: KindAction, Scene: "b"},
+		{Kind: KindStep, Scene: "b", ScreenHash: "2"},
+		{Kind: KindStep, Scene: "b", ScreenHash: "3"},
+	}
+	got := SceneCounts(entries)
+	want := []SceneCount{{Scene: "b", Count: 3, Screens: 2}, {Scene: "a", Count: 1, Screens: 1}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("SceneCounts() = %+v, want %+v", got, want)
+	}
+}
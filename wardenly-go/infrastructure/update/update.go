@@ -0,0 +1,351 @@
+// Package update checks a release feed for newer Wardenly builds, downloads
+// and verifies the binary for the running platform, and stages it so the
+// next restart runs the new version.
+package update
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables overriding the update section of the config file.
+const (
+	EnvFeedURL   = "WARDENLY_UPDATE_URL"
+	EnvPublicKey = "WARDENLY_UPDATE_PUBKEY"
+)
+
+// Suffixes of the files staged next to the executable.
+const (
+	newSuffix = ".new"
+	oldSuffix = ".old"
+)
+
+var (
+	// ErrNoAsset is returned when a release has no binary for this platform.
+	ErrNoAsset = errors.New("release has no binary for this platform")
+	// ErrChecksumMismatch is returned when a download doesn't match its SHA-256.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrBadSignature is returned when a download's signature doesn't verify.
+	ErrBadSignature = errors.New("signature verification failed")
+	// ErrNoPublicKey is returned when installing without a key to verify
+	// the release with; a checksum from the same feed proves nothing.
+	ErrNoPublicKey = errors.New("no update signing key configured")
+)
+
+// Asset is a downloadable binary for one platform.
+type Asset struct {
+	URL string `json:"url"`
+	// SHA256 is the hex-encoded SHA-256 digest of the binary.
+	SHA256 string `json:"sha256"`
+	// Signature is the base64 Ed25519 signature of SignedMessage for the
+	// release version, this asset's platform and its digest.
+	Signature string `json:"signature"`
+}
+
+// Release is the release feed document.
+type Release struct {
+	Version     string    `json:"version"`
+	PublishedAt time.Time `json:"published_at"`
+	Changelog   string    `json:"changelog"`
+	// Assets is keyed by "GOOS/GOARCH", e.g. "windows/amd64".
+	Assets map[string]Asset `json:"assets"`
+}
+
+// Config holds updater configuration.
+type Config struct {
+	// FeedURL is the URL of the release feed JSON. Empty disables updates.
+	FeedURL string
+	// PublicKey verifies release signatures. When nil, updates are disabled.
+	PublicKey ed25519.PublicKey
+	// CurrentVersion is the running build's version. Development builds
+	// without a parseable version never update.
+	CurrentVersion string
+	// ExecutablePath is the binary to replace. If empty, defaults to os.Executable().
+	ExecutablePath string
+	// HTTPClient is used for the feed and downloads.
+	// If nil, a client with a 5 minute timeout is used.
+	HTTPClient *http.Client
+	Logger     *slog.Logger
+}
+
+// ParsePublicKey decodes a base64 or hex encoded Ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	s = strings.TrimSpace(s)
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		if key, err = hex.DecodeString(s); err != nil {
+			return nil, fmt.Errorf("invalid public key encoding")
+		}
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size %d", len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Updater checks for and installs new releases.
+type Updater struct {
+	config *Config
+	client *http.Client
+	logger *slog.Logger
+}
+
+// NewUpdater creates an updater.
+func NewUpdater(cfg *Config) *Updater {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	return &Updater{
+		config: cfg,
+		client: client,
+		logger: cfg.Logger,
+	}
+}
+
+// CurrentVersion returns the running build's version.
+func (u *Updater) CurrentVersion() string {
+	return u.config.CurrentVersion
+}
+
+// Enabled reports whether a feed and a signing key are configured and the
+// running build has a version to compare against.
+func (u *Updater) Enabled() bool {
+	_, ok := parseVersion(u.config.CurrentVersion)
+	return u.config.FeedURL != "" && u.config.PublicKey != nil && ok
+}
+
+// Check fetches the release feed. It returns nil when the published release
+// is not newer than the running build.
+func (u *Updater) Check(ctx context.Context) (*Release, error) {
+	if !u.Enabled() {
+		return nil, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.config.FeedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("release feed returned status %d", resp.StatusCode)
+	}
+
+	var rel Release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return nil, fmt.Errorf("failed to decode release feed: %w", err)
+	}
+
+	if CompareVersions(rel.Version, u.config.CurrentVersion) <= 0 {
+		return nil, nil
+	}
+	return &rel, nil
+}
+
+// Install downloads the release binary for this platform, verifies it, and
+// swaps it in place of the running executable. The running process keeps
+// using the old binary; the new one takes effect on next restart.
+func (u *Updater) Install(ctx context.Context, rel *Release) error {
+	if u.config.PublicKey == nil {
+		return ErrNoPublicKey
+	}
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	asset, ok := rel.Assets[platform]
+	if !ok {
+		return ErrNoAsset
+	}
+
+	exe, err := u.executablePath()
+	if err != nil {
+		return err
+	}
+	staged := exe + newSuffix
+
+	digest, err := u.download(ctx, asset, staged)
+	if err == nil {
+		err = u.verifySignature(SignedMessage(rel.Version, platform, digest), asset.Signature)
+	}
+	if err != nil {
+		os.Remove(staged)
+		return err
+	}
+
+	// A running executable can be renamed (even on Windows) but not overwritten
+	backup := exe + oldSuffix
+	os.Remove(backup)
+	if err := os.Rename(exe, backup); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("failed to move current binary aside: %w", err)
+	}
+	if err := os.Rename(staged, exe); err != nil {
+		os.Rename(backup, exe)
+		os.Remove(staged)
+		return fmt.Errorf("failed to install new binary: %w", err)
+	}
+
+	u.logger.Info("Update installed, restart to apply", "version", rel.Version, "path", exe)
+	return nil
+}
+
+// CleanupPrevious removes the binary left behind by the last Install.
+// Call at startup, once the new binary is running.
+func (u *Updater) CleanupPrevious() {
+	exe, err := u.executablePath()
+	if err != nil {
+		return
+	}
+	if err := os.Remove(exe + oldSuffix); err == nil {
+		u.logger.Info("Removed previous binary after update", "version", u.config.CurrentVersion)
+	}
+}
+
+func (u *Updater) executablePath() (string, error) {
+	if u.config.ExecutablePath != "" {
+		return u.config.ExecutablePath, nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate executable: %w", err)
+	}
+	return exe, nil
+}
+
+// download streams the asset to path, verifies its checksum and returns
+// its SHA-256 digest.
+func (u *Updater) download(ctx context.Context, asset Asset, path string) ([]byte, error) {
+	want, err := hex.DecodeString(asset.SHA256)
+	if err != nil || len(want) != sha256.Size {
+		return nil, fmt.Errorf("invalid release checksum %q", asset.SHA256)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staged binary: %w", err)
+	}
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write staged binary: %w", err)
+	}
+
+	digest := h.Sum(nil)
+	if !bytes.Equal(digest, want) {
+		return nil, ErrChecksumMismatch
+	}
+	return digest, nil
+}
+
+// SignedMessage returns what a release asset's signature covers: the
+// version and platform with the binary's SHA-256 digest, so a signed
+// binary can't be offered as another version (e.g. to downgrade) or for
+// another platform.
+func SignedMessage(version, platform string, digest []byte) []byte {
+	msg := []byte("wardenly-update\x00" + version + "\x00" + platform + "\x00")
+	return append(msg, digest...)
+}
+
+func (u *Updater) verifySignature(message []byte, signature string) error {
+	if u.config.PublicKey == nil {
+		return ErrNoPublicKey
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(u.config.PublicKey, message, sig) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// CompareVersions compares dotted numeric versions such as "v1.4.2".
+// It returns -1, 0 or 1. Unparseable versions sort before everything else.
+func CompareVersions(a, b string) int {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	for i := 0; i < max(len(va), len(vb)); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseVersion parses "v1.2.3" (optional "v", pre-release suffix ignored).
+func parseVersion(s string) ([]int, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	if s == "" {
+		return nil, false
+	}
+
+	parts := strings.Split(s, ".")
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		nums[i] = n
+	}
+	return nums, true
+}
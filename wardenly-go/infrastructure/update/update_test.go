@@ -0,0 +1,211 @@
+package update
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.0", "1.2", 0},
+		{"v1.10.0", "v1.9.3", 1},
+		{"1.2.3-rc1", "1.2.4", -1},
+		{"dev", "v0.0.1", -1},
+		{"dev", "", 0},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	for _, s := range []string{base64.StdEncoding.EncodeToString(pub), hex.EncodeToString(pub)} {
+		key, err := ParsePublicKey(s)
+		if err != nil || !key.Equal(pub) {
+			t.Errorf("ParsePublicKey(%q) = %v, %v", s, key, err)
+		}
+	}
+	if _, err := ParsePublicKey("not a key"); err == nil {
+		t.Error("ParsePublicKey() accepted garbage")
+	}
+}
+
+// releaseServer serves a feed for version with the given binary and asset metadata.
+func releaseServer(t *testing.T, version string, binary []byte, asset Asset) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	asset.URL = srv.URL + "/bin"
+	mux.HandleFunc("/feed.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Release{
+			Version:   version,
+			Changelog: "- fixes",
+			Assets:    map[string]Asset{runtime.GOOS + "/" + runtime.GOARCH: asset},
+		})
+	})
+	mux.HandleFunc("/bin", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	})
+	return srv
+}
+
+// sign signs a release asset the way the release tooling does.
+func sign(priv ed25519.PrivateKey, version string, digest []byte) string {
+	msg := SignedMessage(version, runtime.GOOS+"/"+runtime.GOARCH, digest)
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(priv, msg))
+}
+
+func newTestUpdater(t *testing.T, srv *httptest.Server, pub ed25519.PublicKey) (*Updater, string) {
+	t.Helper()
+	exe := filepath.Join(t.TempDir(), "wardenly.exe")
+	if err := os.WriteFile(exe, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return NewUpdater(&Config{
+		FeedURL:        srv.URL + "/feed.json",
+		PublicKey:      pub,
+		CurrentVersion: "v1.0.0",
+		ExecutablePath: exe,
+	}), exe
+}
+
+func TestUpdater_CheckAndInstall(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	binary := []byte("new binary")
+	digest := sha256.Sum256(binary)
+
+	srv := releaseServer(t, "v1.1.0", binary, Asset{
+		SHA256:    hex.EncodeToString(digest[:]),
+		Signature: sign(priv, "v1.1.0", digest[:]),
+	})
+	u, exe := newTestUpdater(t, srv, pub)
+
+	rel, err := u.Check(t.Context())
+	if err != nil || rel == nil || rel.Version != "v1.1.0" {
+		t.Fatalf("Check() = %+v, %v", rel, err)
+	}
+
+	if err := u.Install(t.Context(), rel); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if got, _ := os.ReadFile(exe); string(got) != "new binary" {
+		t.Errorf("executable = %q, want new binary", got)
+	}
+	if got, _ := os.ReadFile(exe + oldSuffix); string(got) != "old" {
+		t.Errorf("backup = %q, want old binary", got)
+	}
+
+	u.CleanupPrevious()
+	if _, err := os.Stat(exe + oldSuffix); !os.IsNotExist(err) {
+		t.Error("CleanupPrevious() left the old binary")
+	}
+}
+
+func TestUpdater_CheckNotNewer(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	srv := releaseServer(t, "v1.0.0", nil, Asset{})
+	u, _ := newTestUpdater(t, srv, pub)
+
+	if rel, err := u.Check(t.Context()); rel != nil || err != nil {
+		t.Errorf("Check() = %+v, %v, want no release", rel, err)
+	}
+}
+
+func TestUpdater_InstallRejectsBadDownloads(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	binary := []byte("new binary")
+	digest := sha256.Sum256(binary)
+	wrong := sha256.Sum256([]byte("other"))
+
+	tests := []struct {
+		name    string
+		asset   Asset
+		wantErr error
+	}{
+		{"checksum", Asset{SHA256: hex.EncodeToString(wrong[:])}, ErrChecksumMismatch},
+		{"signature", Asset{
+			SHA256:    hex.EncodeToString(digest[:]),
+			Signature: sign(priv, "v2.0.0", wrong[:]),
+		}, ErrBadSignature},
+		// An older signed build offered as a newer version
+		{"replayed", Asset{
+			SHA256:    hex.EncodeToString(digest[:]),
+			Signature: sign(priv, "v1.0.0", digest[:]),
+		}, ErrBadSignature},
+		// The pre-version signature format, over the digest alone
+		{"digest only", Asset{
+			SHA256:    hex.EncodeToString(digest[:]),
+			Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priv, digest[:])),
+		}, ErrBadSignature},
+		{"unsigned", Asset{SHA256: hex.EncodeToString(digest[:])}, ErrBadSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := releaseServer(t, "v2.0.0", binary, tt.asset)
+			u, exe := newTestUpdater(t, srv, pub)
+
+			rel, err := u.Check(t.Context())
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if err := u.Install(t.Context(), rel); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Install() error = %v, want %v", err, tt.wantErr)
+			}
+			if got, _ := os.ReadFile(exe); string(got) != "old" {
+				t.Errorf("executable replaced by rejected download: %q", got)
+			}
+			if _, err := os.Stat(exe + newSuffix); !os.IsNotExist(err) {
+				t.Error("rejected download left a staged binary")
+			}
+		})
+	}
+}
+
+func TestUpdater_RequiresPublicKey(t *testing.T) {
+	binary := []byte("new binary")
+	digest := sha256.Sum256(binary)
+	srv := releaseServer(t, "v2.0.0", binary, Asset{SHA256: hex.EncodeToString(digest[:])})
+	u, exe := newTestUpdater(t, srv, nil)
+
+	if u.Enabled() {
+		t.Error("Enabled() = true without a signing key")
+	}
+	rel := &Release{Version: "v2.0.0", Assets: map[string]Asset{
+		runtime.GOOS + "/" + runtime.GOARCH: {URL: srv.URL + "/bin", SHA256: hex.EncodeToString(digest[:])},
+	}}
+	if err := u.Install(t.Context(), rel); !errors.Is(err, ErrNoPublicKey) {
+		t.Errorf("Install() error = %v, want ErrNoPublicKey", err)
+	}
+	if got, _ := os.ReadFile(exe); string(got) != "old" {
+		t.Errorf("executable replaced without a key: %q", got)
+	}
+}
+
+func TestUpdater_DisabledForDevBuilds(t *testing.T) {
+	u := NewUpdater(&Config{FeedURL: "http://example.invalid/feed.json", CurrentVersion: "dev"})
+	if u.Enabled() {
+		t.Error("Enabled() = true for a development build")
+	}
+	if rel, err := u.Check(t.Context()); rel != nil || err != nil {
+		t.Errorf("Check() = %+v, %v, want no-op", rel, err)
+	}
+}
@@ -1,7 +1,10 @@
 package presentation
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -14,8 +17,10 @@ import (
 
 // AccountFormConfig holds configuration for AccountForm.
 type AccountFormConfig struct {
-	OnSave   func(*account.Account)
-	OnDelete func(*account.Account)
+	ScriptNames []string // Choices for allowed/blocked scripts
+	OnSave      func(*account.Account)
+	OnDelete    func(*account.Account)
+	OnArchive   func(acc *account.Account, archived bool) // Archive or unarchive
 }
 
 // AccountForm provides a form for editing account details.
@@ -29,15 +34,53 @@ type AccountForm struct {
 	passwordEntry *widget.Entry
 	serverIDEntry *widget.Entry
 	rankingEntry  *widget.Entry
+	loginURLEntry *widget.Entry
+
+	// Session label
+	labelEntry       *widget.Entry
+	labelColorSelect *widget.Select
+
+	// Proxy
+	proxyHostEntry     *widget.Entry
+	proxyPortEntry     *widget.Entry
+	proxyUserEntry     *widget.Entry
+	proxyPasswordEntry *widget.Entry
+
+	// Browser overrides
+	headlessSelect   *widget.Select
+	viewportEntry    *widget.Entry
+	userDataDirEntry *widget.Entry
+	pageScaleEntry   *widget.Entry
+	deviceScaleEntry *widget.Entry
+	mobileCheck      *widget.Check
+
+	// Script restrictions
+	allowedScripts *widget.CheckGroup
+	blockedScripts *widget.CheckGroup
+
+	// First-login setup
+	setupSelect    *widget.Select
+	setupDoneCheck *widget.Check
 
 	// Buttons
-	saveBtn   *widget.Button
-	deleteBtn *widget.Button
+	saveBtn    *widget.Button
+	deleteBtn  *widget.Button
+	archiveBtn *widget.Button
 
 	// Current account being edited
 	current *account.Account
 }
 
+// setupNone is the setup script choice for accounts without one.
+const setupNone = "None"
+
+// Headless choices; headlessDefault keeps the global setting.
+const (
+	headlessDefault = "Default"
+	headlessOn      = "Headless"
+	headlessOff     = "Visible"
+)
+
 // NewAccountForm creates a new account editing form.
 func NewAccountForm(cfg *AccountFormConfig) *AccountForm {
 	af := &AccountForm{config: cfg}
@@ -61,6 +104,59 @@ func (af *AccountForm) build() {
 	af.rankingEntry = widget.NewEntry()
 	af.rankingEntry.SetPlaceHolder("Sort priority (lower = higher)")
 
+	af.loginURLEntry = widget.NewEntry()
+	af.loginURLEntry.SetPlaceHolder("Empty uses the configured login page")
+
+	af.labelEntry = widget.NewEntry()
+	af.labelEntry.SetPlaceHolder("e.g., MAIN, empty for none")
+
+	colors := make([]string, len(account.LabelColors))
+	for i, c := range account.LabelColors {
+		colors[i] = string(c)
+	}
+	af.labelColorSelect = widget.NewSelect(colors, nil)
+	af.labelColorSelect.SetSelected(string(account.LabelColorGray))
+
+	af.proxyHostEntry = widget.NewEntry()
+	af.proxyHostEntry.SetPlaceHolder("Empty connects directly")
+
+	af.proxyPortEntry = widget.NewEntry()
+	af.proxyPortEntry.SetPlaceHolder("e.g., 8080")
+
+	af.proxyUserEntry = widget.NewEntry()
+	af.proxyUserEntry.SetPlaceHolder("Optional")
+
+	af.proxyPasswordEntry = widget.NewPasswordEntry()
+	af.proxyPasswordEntry.SetPlaceHolder("Optional")
+
+	af.headlessSelect = widget.NewSelect([]string{headlessDefault, headlessOn, headlessOff}, nil)
+	af.headlessSelect.SetSelected(headlessDefault)
+
+	af.viewportEntry = widget.NewEntry()
+	af.viewportEntry.SetPlaceHolder("e.g., 1280x800, empty for default")
+
+	af.userDataDirEntry = widget.NewEntry()
+	af.userDataDirEntry.SetPlaceHolder("Empty uses a temporary profile")
+
+	af.pageScaleEntry = widget.NewEntry()
+	af.pageScaleEntry.SetPlaceHolder("e.g., 0.8, empty for 1")
+
+	af.deviceScaleEntry = widget.NewEntry()
+	af.deviceScaleEntry.SetPlaceHolder("e.g., 2, empty for 1")
+
+	af.mobileCheck = widget.NewCheck("Emulate a mobile device", nil)
+
+	af.allowedScripts = widget.NewCheckGroup(af.config.ScriptNames, nil)
+	af.allowedScripts.Horizontal = true
+	af.blockedScripts = widget.NewCheckGroup(af.config.ScriptNames, nil)
+	af.blockedScripts.Horizontal = true
+
+	af.setupSelect = widget.NewSelect(append([]string{setupNone}, af.config.ScriptNames...), func(string) {
+		// A newly chosen setup script has not run yet
+		af.setupDoneCheck.SetChecked(false)
+	})
+	af.setupDoneCheck = widget.NewCheck("Completed", nil)
+
 	// Use widget.Form for proper label-input alignment
 	form := widget.NewForm(
 		widget.NewFormItem("Role Name", af.roleNameEntry),
@@ -68,17 +164,37 @@ func (af *AccountForm) build() {
 		widget.NewFormItem("Password", af.passwordEntry),
 		widget.NewFormItem("Server ID", af.serverIDEntry),
 		widget.NewFormItem("Ranking", af.rankingEntry),
+		&widget.FormItem{Text: "Login URL", Widget: af.loginURLEntry, HintText: "{server} stands for the server ID, e.g. for a mirror domain"},
+		&widget.FormItem{Text: "Label", Widget: af.labelEntry, HintText: "Shown on the account's sessions"},
+		widget.NewFormItem("Label Color", af.labelColorSelect),
+		&widget.FormItem{Text: "Proxy Host", Widget: af.proxyHostEntry, HintText: "HTTP proxy for this account's browser"},
+		widget.NewFormItem("Proxy Port", af.proxyPortEntry),
+		widget.NewFormItem("Proxy User", af.proxyUserEntry),
+		widget.NewFormItem("Proxy Password", af.proxyPasswordEntry),
+		&widget.FormItem{Text: "Browser", Widget: af.headlessSelect, HintText: "Overrides the global headless setting"},
+		widget.NewFormItem("Viewport", af.viewportEntry),
+		widget.NewFormItem("User Data Dir", af.userDataDirEntry),
+		&widget.FormItem{Text: "Page Scale", Widget: af.pageScaleEntry, HintText: "Zooms the game to line up with shared coordinates"},
+		&widget.FormItem{Text: "Device Scale", Widget: af.deviceScaleEntry, HintText: "Emulated device pixel ratio (DPI)"},
+		widget.NewFormItem("Mobile", af.mobileCheck),
+		&widget.FormItem{Text: "Allowed Scripts", Widget: af.allowedScripts, HintText: "None checked allows all scripts"},
+		&widget.FormItem{Text: "Blocked Scripts", Widget: af.blockedScripts, HintText: "Never run on this account"},
+		&widget.FormItem{Text: "Setup Script", Widget: af.setupSelect, HintText: "Runs once, the first time a session reaches Ready"},
+		&widget.FormItem{Text: "Setup Done", Widget: af.setupDoneCheck, HintText: "Uncheck to run the setup again"},
 	)
 
 	// Buttons with icons - Delete on left, Save on right
 	af.deleteBtn = widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), af.onDelete)
 	af.deleteBtn.Importance = widget.DangerImportance
 
+	af.archiveBtn = widget.NewButtonWithIcon("Archive", theme.VisibilityOffIcon(), af.onArchive)
+
 	af.saveBtn = widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), af.onSave)
 	af.saveBtn.Importance = widget.HighImportance
 
 	buttonBar := container.NewHBox(
 		af.deleteBtn,
+		af.archiveBtn,
 		layout.NewSpacer(),
 		af.saveBtn,
 	)
@@ -106,15 +222,172 @@ func (af *AccountForm) SetAccount(acc *account.Account) {
 		af.passwordEntry.SetText("")
 		af.serverIDEntry.SetText("")
 		af.rankingEntry.SetText("0")
+		af.loginURLEntry.SetText("")
+		af.labelEntry.SetText("")
+		af.labelColorSelect.SetSelected(string(account.LabelColorGray))
+		af.setProxy(nil)
+		af.setBrowser(nil)
+		af.allowedScripts.SetSelected(nil)
+		af.blockedScripts.SetSelected(nil)
+		af.setSetup("", time.Time{})
 		af.deleteBtn.Disable()
+		af.archiveBtn.SetText("Archive")
+		af.archiveBtn.SetIcon(theme.VisibilityOffIcon())
+		af.archiveBtn.Disable()
 	} else {
 		af.roleNameEntry.SetText(acc.RoleName)
 		af.userNameEntry.SetText(acc.UserName)
 		af.passwordEntry.SetText(acc.Password)
 		af.serverIDEntry.SetText(strconv.Itoa(acc.ServerID))
 		af.rankingEntry.SetText(strconv.Itoa(acc.Ranking))
+		af.loginURLEntry.SetText(acc.URLTemplate)
+		af.labelEntry.SetText(acc.Label)
+		af.labelColorSelect.SetSelected(string(acc.LabelColor.OrDefault()))
+		af.setProxy(acc.Proxy)
+		af.setBrowser(acc.Browser)
+		af.allowedScripts.SetSelected(append([]string(nil), acc.AllowedScripts...))
+		af.blockedScripts.SetSelected(append([]string(nil), acc.BlockedScripts...))
+		af.setSetup(acc.SetupScript, acc.SetupCompletedAt)
 		af.deleteBtn.Enable()
+		if acc.Archived {
+			af.archiveBtn.SetText("Unarchive")
+			af.archiveBtn.SetIcon(theme.VisibilityIcon())
+		} else {
+			af.archiveBtn.SetText("Archive")
+			af.archiveBtn.SetIcon(theme.VisibilityOffIcon())
+		}
+		af.archiveBtn.Enable()
+	}
+}
+
+// setSetup fills the first-login setup fields.
+func (af *AccountForm) setSetup(scriptName string, completedAt time.Time) {
+	if scriptName == "" {
+		scriptName = setupNone
+	}
+	af.setupSelect.SetSelected(scriptName)
+	af.setupDoneCheck.SetChecked(!completedAt.IsZero())
+}
+
+// setup returns the setup script and completion time from the form. A
+// completion checked by hand is dated now.
+func (af *AccountForm) setup() (string, time.Time) {
+	scriptName := af.setupSelect.Selected
+	if scriptName == setupNone {
+		return "", time.Time{}
+	}
+	if !af.setupDoneCheck.Checked {
+		return scriptName, time.Time{}
+	}
+	if af.current != nil && af.current.SetupScript == scriptName && !af.current.SetupCompletedAt.IsZero() {
+		return scriptName, af.current.SetupCompletedAt
+	}
+	return scriptName, time.Now()
+}
+
+// setProxy fills the proxy fields; nil clears them.
+func (af *AccountForm) setProxy(proxy *account.Proxy) {
+	if !proxy.Enabled() {
+		af.proxyHostEntry.SetText("")
+		af.proxyPortEntry.SetText("")
+		af.proxyUserEntry.SetText("")
+		af.proxyPasswordEntry.SetText("")
+		return
+	}
+	af.proxyHostEntry.SetText(proxy.Host)
+	af.proxyPortEntry.SetText(strconv.Itoa(proxy.Port))
+	af.proxyUserEntry.SetText(proxy.Username)
+	af.proxyPasswordEntry.SetText(proxy.Password)
+}
+
+// proxy returns the proxy from the form, or nil when the host or port is missing.
+func (af *AccountForm) proxy() *account.Proxy {
+	port, _ := strconv.Atoi(af.proxyPortEntry.Text)
+	proxy := &account.Proxy{
+		Host:     strings.TrimSpace(af.proxyHostEntry.Text),
+		Port:     port,
+		Username: af.proxyUserEntry.Text,
+		Password: af.proxyPasswordEntry.Text,
+	}
+	if !proxy.Enabled() {
+		return nil
+	}
+	return proxy
+}
+
+// setBrowser fills the browser override fields; nil clears them.
+func (af *AccountForm) setBrowser(settings *account.BrowserSettings) {
+	if settings.IsZero() {
+		settings = &account.BrowserSettings{}
+	}
+
+	switch {
+	case settings.Headless == nil:
+		af.headlessSelect.SetSelected(headlessDefault)
+	case *settings.Headless:
+		af.headlessSelect.SetSelected(headlessOn)
+	default:
+		af.headlessSelect.SetSelected(headlessOff)
 	}
+
+	if settings.ViewportWidth > 0 && settings.ViewportHeight > 0 {
+		af.viewportEntry.SetText(fmt.Sprintf("%dx%d", settings.ViewportWidth, settings.ViewportHeight))
+	} else {
+		af.viewportEntry.SetText("")
+	}
+	af.userDataDirEntry.SetText(settings.UserDataDir)
+	af.pageScaleEntry.SetText(formatScale(settings.PageScale))
+	af.deviceScaleEntry.SetText(formatScale(settings.DeviceScaleFactor))
+	af.mobileCheck.SetChecked(settings.Mobile)
+}
+
+// formatScale formats a scale for an entry; unset scales are empty.
+func formatScale(scale float64) string {
+	if scale <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(scale, 'f', -1, 64)
+}
+
+// parseScale parses a scale entry; empty or invalid text yields zero.
+func parseScale(text string) float64 {
+	scale, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil || scale <= 0 {
+		return 0
+	}
+	return scale
+}
+
+// browser returns the browser overrides from the form, or nil when none are set.
+// A viewport that is not WIDTHxHEIGHT and scales that are not positive
+// numbers are ignored.
+func (af *AccountForm) browser() *account.BrowserSettings {
+	settings := &account.BrowserSettings{
+		UserDataDir:       strings.TrimSpace(af.userDataDirEntry.Text),
+		PageScale:         parseScale(af.pageScaleEntry.Text),
+		DeviceScaleFactor: parseScale(af.deviceScaleEntry.Text),
+		Mobile:            af.mobileCheck.Checked,
+	}
+
+	switch af.headlessSelect.Selected {
+	case headlessOn, headlessOff:
+		headless := af.headlessSelect.Selected == headlessOn
+		settings.Headless = &headless
+	}
+
+	if w, h, ok := strings.Cut(strings.TrimSpace(af.viewportEntry.Text), "x"); ok {
+		width, errW := strconv.Atoi(strings.TrimSpace(w))
+		height, errH := strconv.Atoi(strings.TrimSpace(h))
+		if errW == nil && errH == nil && width > 0 && height > 0 {
+			settings.ViewportWidth = width
+			settings.ViewportHeight = height
+		}
+	}
+
+	if settings.IsZero() {
+		return nil
+	}
+	return settings
 }
 
 // Clear resets the form to empty state.
@@ -132,12 +405,26 @@ func (af *AccountForm) onSave() {
 		Password: af.passwordEntry.Text,
 		ServerID: serverID,
 		Ranking:  ranking,
+
+		URLTemplate: strings.TrimSpace(af.loginURLEntry.Text),
+
+		Label:      strings.TrimSpace(af.labelEntry.Text),
+		LabelColor: account.LabelColor(af.labelColorSelect.Selected).OrDefault(),
+
+		AllowedScripts: af.allowedScripts.Selected,
+		BlockedScripts: af.blockedScripts.Selected,
+
+		Proxy:   af.proxy(),
+		Browser: af.browser(),
 	}
+	acc.SetupScript, acc.SetupCompletedAt = af.setup()
 
 	// Preserve existing data if editing
 	if af.current != nil {
 		acc.ID = af.current.ID
 		acc.Cookies = af.current.Cookies
+		acc.ScriptParams = af.current.ScriptParams
+		acc.Archived = af.current.Archived
 	}
 
 	if af.config.OnSave != nil {
@@ -145,6 +432,12 @@ func (af *AccountForm) onSave() {
 	}
 }
 
+func (af *AccountForm) onArchive() {
+	if af.current != nil && af.config.OnArchive != nil {
+		af.config.OnArchive(af.current, !af.current.Archived)
+	}
+}
+
 func (af *AccountForm) onDelete() {
 	if af.current != nil && af.config.OnDelete != nil {
 		af.config.OnDelete(af.current)
@@ -0,0 +1,332 @@
+package presentation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/application"
+	"wardenly-go/domain/account"
+	"wardenly-go/infrastructure/sheetsync"
+)
+
+// accountSyncTimeout bounds reading the spreadsheet or saving its changes.
+const accountSyncTimeout = time.Minute
+
+// AccountSyncDialogConfig holds configuration for the account sync dialog.
+type AccountSyncDialogConfig struct {
+	Sync *application.AccountSync
+	// OnApplied is called after changes were saved
+	OnApplied func()
+	Logger    *slog.Logger
+}
+
+// accountSyncDialog edits the sync settings, previews the differences
+// between the spreadsheet and the stored accounts, and applies the
+// changes the user keeps.
+type accountSyncDialog struct {
+	config *AccountSyncDialogConfig
+	window fyne.Window
+
+	sourceEntry   *widget.Entry
+	columnEntries map[account.SyncField]*widget.Entry
+	intervalEntry *widget.Entry
+
+	plan     *account.SyncPlan
+	selected []bool
+
+	statusLabel *widget.Label
+	changeList  *widget.List
+	issuesLabel *widget.Label
+	previewBtn  *widget.Button
+	applyBtn    *widget.Button
+}
+
+// ShowAccountSyncDialog opens a window that syncs accounts with an external
+// spreadsheet.
+func ShowAccountSyncDialog(cfg *AccountSyncDialogConfig) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	d := &accountSyncDialog{
+		config:        cfg,
+		columnEntries: make(map[account.SyncField]*widget.Entry),
+	}
+
+	d.window = fyne.CurrentApp().NewWindow("Sync Accounts")
+	d.buildUI()
+	d.showSettings(cfg.Sync.Settings())
+
+	d.window.Resize(fyne.NewSize(720, 620))
+	d.window.CenterOnScreen()
+	d.window.Show()
+}
+
+func (d *accountSyncDialog) buildUI() {
+	hint := widget.NewLabel("Reads a CSV or XLSX export, or a Google Sheets link shared with anyone who has it. " +
+		"Rows match accounts by server and role name; empty cells keep the stored value and " +
+		"accounts missing from the sheet are left alone.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	d.sourceEntry = widget.NewEntry()
+	d.sourceEntry.SetPlaceHolder("accounts.csv, accounts.xlsx or https://...")
+	browseBtn := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), d.browse)
+
+	form := widget.NewForm(widget.NewFormItem("Source",
+		container.NewBorder(nil, nil, nil, browseBtn, d.sourceEntry)))
+	columns := container.NewGridWithColumns(4)
+	for _, field := range account.SyncFields {
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder(string(field))
+		d.columnEntries[field] = entry
+		columns.Add(widget.NewLabel(syncFieldName(field)))
+		columns.Add(entry)
+	}
+	form.Append("Columns", columns)
+
+	d.intervalEntry = widget.NewEntry()
+	d.intervalEntry.SetPlaceHolder("0 = manual only")
+	d.intervalEntry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if n, err := strconv.Atoi(s); err != nil || n < 0 {
+			return fmt.Errorf("must be a whole number of minutes")
+		}
+		return nil
+	}
+	form.Append("Re-sync Every (min)", d.intervalEntry)
+
+	d.statusLabel = widget.NewLabel("Preview to see what would change.")
+	d.changeList = widget.NewList(
+		func() int {
+			if d.plan == nil {
+				return 0
+			}
+			return len(d.plan.Changes)
+		},
+		func() fyne.CanvasObject { return widget.NewCheck("", nil) },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			check := obj.(*widget.Check)
+			check.OnChanged = nil
+			check.SetText(describeSyncChange(d.plan.Changes[id]))
+			check.SetChecked(d.selected[id])
+			check.OnChanged = func(checked bool) {
+				d.selected[id] = checked
+				d.refreshApply()
+			}
+		},
+	)
+	d.issuesLabel = widget.NewLabel("")
+	d.issuesLabel.Wrapping = fyne.TextWrapWord
+
+	saveBtn := widget.NewButtonWithIcon("Save Settings", theme.DocumentSaveIcon(), func() {
+		if err := d.saveSettings(); err != nil {
+			dialog.ShowError(err, d.window)
+			return
+		}
+		d.statusLabel.SetText("Settings saved.")
+	})
+	d.previewBtn = widget.NewButtonWithIcon("Preview", theme.SearchIcon(), d.preview)
+	d.applyBtn = widget.NewButtonWithIcon("Apply Selected", theme.ConfirmIcon(), d.apply)
+	d.applyBtn.Importance = widget.HighImportance
+	d.applyBtn.Disable()
+
+	top := container.NewVBox(hint, form, container.NewHBox(saveBtn, d.previewBtn), d.statusLabel)
+	bottom := container.NewVBox(
+		container.NewVScroll(d.issuesLabel),
+		widget.NewSeparator(),
+		container.NewHBox(layout.NewSpacer(), widget.NewButton("Close", d.window.Close), d.applyBtn),
+	)
+	d.window.SetContent(container.NewPadded(container.NewBorder(top, bottom, nil, nil, d.changeList)))
+}
+
+// syncFieldName is the label of a field in the column mapping.
+func syncFieldName(field account.SyncField) string {
+	switch field {
+	case account.SyncFieldServerID:
+		return "Server *"
+	case account.SyncFieldRoleName:
+		return "Role Name *"
+	case account.SyncFieldUserName:
+		return "Username"
+	case account.SyncFieldPassword:
+		return "Password"
+	case account.SyncFieldRanking:
+		return "Ranking"
+	case account.SyncFieldLabel:
+		return "Label"
+	case account.SyncFieldLabelColor:
+		return "Label Color"
+	case account.SyncFieldArchived:
+		return "Archived"
+	}
+	return string(field)
+}
+
+// describeSyncChange summarizes a change on one line. Passwords are not shown.
+func describeSyncChange(c account.SyncChange) string {
+	parts := make([]string, 0, len(c.Fields))
+	for _, f := range c.Fields {
+		switch {
+		case f.Field == account.SyncFieldPassword:
+			parts = append(parts, "password changed")
+		case c.Kind == account.SyncAdd:
+			parts = append(parts, fmt.Sprintf("%s %s", f.Field, f.New))
+		default:
+			parts = append(parts, fmt.Sprintf("%s %q → %q", f.Field, f.Old, f.New))
+		}
+	}
+	prefix := "Update"
+	if c.Kind == account.SyncAdd {
+		prefix = "Add"
+	}
+	text := fmt.Sprintf("%s %s (row %d)", prefix, c.Account.Identity(), c.Row)
+	if len(parts) > 0 {
+		text += ": " + strings.Join(parts, ", ")
+	}
+	return text
+}
+
+// showSettings fills the form from saved settings.
+func (d *accountSyncDialog) showSettings(s sheetsync.Settings) {
+	d.sourceEntry.SetText(s.Source)
+	for field, entry := range d.columnEntries {
+		entry.SetText(s.Columns[field])
+	}
+	if s.IntervalMinutes > 0 {
+		d.intervalEntry.SetText(strconv.Itoa(s.IntervalMinutes))
+	}
+}
+
+// saveSettings stores the form as the sync settings.
+func (d *accountSyncDialog) saveSettings() error {
+	if err := d.intervalEntry.Validate(); err != nil {
+		return err
+	}
+	s := sheetsync.Settings{Source: strings.TrimSpace(d.sourceEntry.Text)}
+	for field, entry := range d.columnEntries {
+		if header := strings.TrimSpace(entry.Text); header != "" {
+			if s.Columns == nil {
+				s.Columns = make(account.ColumnMapping)
+			}
+			s.Columns[field] = header
+		}
+	}
+	s.IntervalMinutes, _ = strconv.Atoi(d.intervalEntry.Text)
+	return d.config.Sync.SetSettings(s)
+}
+
+// browse picks a spreadsheet file.
+func (d *accountSyncDialog) browse() {
+	open := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil || r == nil {
+			return
+		}
+		defer r.Close()
+		d.sourceEntry.SetText(r.URI().Path())
+	}, d.window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".xlsx"}))
+	open.Show()
+}
+
+// preview saves the settings and reads the spreadsheet off the UI thread.
+func (d *accountSyncDialog) preview() {
+	if err := d.saveSettings(); err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+	d.previewBtn.Disable()
+	d.applyBtn.Disable()
+	d.statusLabel.SetText("Reading spreadsheet...")
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), accountSyncTimeout)
+		defer cancel()
+		plan, err := d.config.Sync.Preview(ctx)
+
+		fyne.Do(func() {
+			d.previewBtn.Enable()
+			if err != nil {
+				d.config.Logger.Warn("Account sync preview failed", "error", err)
+				d.statusLabel.SetText("Preview failed")
+				dialog.ShowError(err, d.window)
+				return
+			}
+			d.showPlan(plan)
+		})
+	}()
+}
+
+// showPlan lists the changes of a preview, all selected.
+func (d *accountSyncDialog) showPlan(plan *account.SyncPlan) {
+	d.plan = plan
+	d.selected = make([]bool, len(plan.Changes))
+	for i := range d.selected {
+		d.selected[i] = true
+	}
+
+	added, updated := plan.Counts()
+	d.statusLabel.SetText(fmt.Sprintf("%d to add, %d to update, %d unchanged, %d not in the sheet",
+		added, updated, plan.Unchanged, len(plan.Missing)))
+
+	var issues []string
+	for _, err := range plan.RowErrors {
+		issues = append(issues, "Skipped "+err.Error())
+	}
+	d.issuesLabel.SetText(strings.Join(issues, "\n"))
+	d.changeList.Refresh()
+	d.refreshApply()
+}
+
+func (d *accountSyncDialog) refreshApply() {
+	for _, selected := range d.selected {
+		if selected {
+			d.applyBtn.Enable()
+			return
+		}
+	}
+	d.applyBtn.Disable()
+}
+
+// apply saves the selected changes.
+func (d *accountSyncDialog) apply() {
+	var changes []account.SyncChange
+	for i, change := range d.plan.Changes {
+		if d.selected[i] {
+			changes = append(changes, change)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), accountSyncTimeout)
+	defer cancel()
+	err := d.config.Sync.Apply(ctx, changes)
+
+	// Changes saved before a failure are kept, so reload either way
+	if d.config.OnApplied != nil {
+		d.config.OnApplied()
+	}
+	d.plan = nil
+	d.selected = nil
+	d.issuesLabel.SetText("")
+	d.changeList.Refresh()
+	d.applyBtn.Disable()
+	if err != nil {
+		d.statusLabel.SetText("Apply failed; preview again to see what is left")
+		dialog.ShowError(err, d.window)
+		return
+	}
+	d.statusLabel.SetText(fmt.Sprintf("Applied %d changes.", len(changes)))
+}
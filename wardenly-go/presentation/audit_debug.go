@@ -0,0 +1,12 @@
+//go:build !prod
+
+package presentation
+
+import "time"
+
+// Presentation audit is enabled in development builds only.
+const (
+	auditEnabled  = true
+	auditInterval = 30 * time.Second
+	auditGrace    = time.Minute
+)
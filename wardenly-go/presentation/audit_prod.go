@@ -0,0 +1,12 @@
+//go:build prod
+
+package presentation
+
+import "time"
+
+// Presentation audit is disabled in production builds.
+const (
+	auditEnabled  = false
+	auditInterval = 30 * time.Second
+	auditGrace    = time.Minute
+)
@@ -2,15 +2,22 @@
 package presentation
 
 import (
+	"context"
+	"fmt"
 	"image"
 	"log/slog"
 	"sync"
+	"time"
 
 	"wardenly-go/application"
+	"wardenly-go/application/session"
 	"wardenly-go/core/command"
 	"wardenly-go/core/event"
 	"wardenly-go/core/eventbus"
 	"wardenly-go/core/state"
+	"wardenly-go/domain/account"
+	"wardenly-go/domain/script"
+	"wardenly-go/infrastructure/browser"
 )
 
 // UIEventBridge bridges UI events to the application layer and routes events back to UI.
@@ -34,21 +41,46 @@ type UICallbacks struct {
 	OnSessionStarted      func(sessionID, accountName string)
 	OnSessionStopped      func(sessionID string, err error)
 	OnSessionStateChanged func(sessionID string, oldState, newState state.SessionState)
+	OnSessionQueued       func(sessionID string, position int)
+	OnSessionReconnecting func(sessionID string, attempt int, delay time.Duration, err error)
+	OnSessionReconnected  func(sessionID string, attempts int)
+	OnLogRecorded         func(sessionID string, t time.Time, level slog.Level, text string)
 
 	// Browser events
 	OnScreenCaptured    func(sessionID string, img image.Image)
 	OnLoginSucceeded    func(sessionID string)
 	OnLoginFailed       func(sessionID string, err error)
+	OnLoginRetrying     func(sessionID string, attempt int, delay time.Duration, password bool, err error)
 	OnCookiesSaved      func(sessionID string)
 	OnOperationFailed   func(sessionID, operation string, err error)
+	OnJSEvaluated       func(sessionID string, result []byte, err error)
 	OnScreencastStarted func(sessionID string, quality, maxFPS int)
 	OnScreencastStopped func(sessionID string)
 	OnDriverStarted     func(sessionID string)
+	OnLatencyUpdated    func(sessionID string, mean, p95 time.Duration, lagging bool)
+	OnInputLagChanged   func(sessionID string, lagging bool)
 
 	// Script events
 	OnScriptStarted          func(sessionID, scriptName string)
 	OnScriptStopped          func(sessionID, scriptName string, reason event.StopReason, err error)
 	OnScriptSelectionChanged func(sessionID, scriptName string)
+	OnSessionTuned           func(sessionID string, tuning session.Tuning)
+	OnCountersUpdated        func(sessionID, scriptName string, counters map[string]int)
+	OnScriptRefused          func(sessionID, scriptName, correlationID string, reason error)
+	OnSetupFinished          func(sessionID, scriptName string, completed bool, err error)
+	OnScriptStuck            func(sessionID, scriptName string, idle time.Duration, action string)
+	OnScreenFrozen           func(sessionID, scriptName string, frozen time.Duration, action string)
+	OnScriptHalted           func(sessionID, scriptName, step, action string, screen image.Image)
+	OnScriptThrottled        func(sessionID, scriptName, action string, limit int)
+	OnActionPerformed        func(sessionID, action string, fromX, fromY, toX, toY float64)
+	OnScriptsReloaded        func(names []string, err error)
+
+	// Scene events
+	OnSceneThresholdSuggested func(sceneName string, threshold, suggested float64, count, sessions int, applied bool)
+	OnSceneRegressed          func(sceneName string, frames, regressions int, points []string)
+
+	// Account events
+	OnAccountsSynced func(added, updated, skipped int, err error)
 }
 
 // BridgeConfig holds configuration for UIEventBridge.
@@ -95,104 +127,210 @@ func (b *UIEventBridge) Close() {
 
 // Command dispatching methods
 
-// StartSession starts a new session for an account.
-func (b *UIEventBridge) StartSession(accountID, roleName, userName, password string, serverID int, cookies []command.Cookie) error {
-	cmd := &command.StartSession{
-		AccountID: accountID,
-		RoleName:  roleName,
-		UserName:  userName,
-		Password:  password,
-		ServerID:  serverID,
-		Cookies:   cookies,
+// dispatch assigns a correlation ID to a UI command and dispatches it. The
+// ID is logged with the command and carried by the events it causes.
+func (b *UIEventBridge) dispatch(cmd command.Command) error {
+	correlationID := command.Correlate(cmd)
+	if err := b.coordinator.Dispatch(cmd); err != nil {
+		b.logger.Warn("Command failed", "command", cmd.CommandName(), "correlation_id", correlationID, "error", err)
+		return err
 	}
-	return b.coordinator.Dispatch(cmd)
+	return nil
+}
+
+// SessionLaunch holds the choices made in the toolbar for starting sessions.
+type SessionLaunch struct {
+	// Canvas is the canvas size, zero for the account's viewport
+	Canvas browser.CanvasSize
+	// Headful shows the browser window, whatever the account's setting
+	Headful bool
+}
+
+// StartSession starts a new session for an account with the launch
+// choices. Archived accounts are refused.
+func (b *UIEventBridge) StartSession(acc *account.Account, launch SessionLaunch) error {
+	if acc.Archived {
+		return fmt.Errorf("%w: %s", account.ErrAccountArchived, acc.Identity())
+	}
+	return b.dispatch(launchCommand(acc, launch))
+}
+
+// StartGroupSession starts a session for a group run. With stopWhenDone the
+// session stops itself once its script finishes.
+func (b *UIEventBridge) StartGroupSession(acc *account.Account, launch SessionLaunch, stopWhenDone bool) error {
+	if acc.Archived {
+		return fmt.Errorf("%w: %s", account.ErrAccountArchived, acc.Identity())
+	}
+	cmd := launchCommand(acc, launch)
+	cmd.StopOnScriptFinish = stopWhenDone
+	return b.dispatch(cmd)
+}
+
+// launchCommand builds the start command of acc with the launch choices
+// applied over the account's browser settings.
+func launchCommand(acc *account.Account, launch SessionLaunch) *command.StartSession {
+	cmd := application.StartSessionCommand(acc)
+	cmd.CanvasWidth, cmd.CanvasHeight = launch.Canvas.Width, launch.Canvas.Height
+	if launch.Headful {
+		headless := false
+		if cmd.Browser == nil {
+			cmd.Browser = &command.BrowserOverrides{}
+		}
+		cmd.Browser.Headless = &headless
+	}
+	return cmd
+}
+
+// DetachSession restarts a session's browser with its window shown. The
+// session logs in again with its cookies; a running script is stopped.
+func (b *UIEventBridge) DetachSession(sessionID string) error {
+	headless := false
+	return b.dispatch(command.NewRelaunchSession(sessionID, &command.BrowserOverrides{Headless: &headless}))
 }
 
 // StopSession stops a running session.
 func (b *UIEventBridge) StopSession(sessionID string) error {
-	return b.coordinator.Dispatch(command.NewStopSession(sessionID))
+	return b.dispatch(command.NewStopSession(sessionID))
 }
 
 // StopAllSessions stops all running sessions.
 func (b *UIEventBridge) StopAllSessions() error {
-	return b.coordinator.Dispatch(&command.StopAllSessions{})
+	return b.dispatch(&command.StopAllSessions{})
+}
+
+// SetStopOnScriptFinish toggles automatic stop of sessions whose script finished.
+func (b *UIEventBridge) SetStopOnScriptFinish(enabled bool) error {
+	return b.dispatch(&command.SetStopOnScriptFinish{Enabled: enabled})
 }
 
 // Click performs a click at the specified coordinates.
 func (b *UIEventBridge) Click(sessionID string, x, y float64) error {
-	return b.coordinator.Dispatch(command.NewClick(sessionID, x, y))
+	return b.dispatch(command.NewClick(sessionID, x, y))
+}
+
+// Scroll turns the mouse wheel at the specified coordinates.
+func (b *UIEventBridge) Scroll(sessionID string, x, y, deltaX, deltaY float64) error {
+	return b.dispatch(command.NewScroll(sessionID, x, y, deltaX, deltaY))
+}
+
+// KeyPress presses and releases a key in the session's browser.
+func (b *UIEventBridge) KeyPress(sessionID, key string) error {
+	return b.dispatch(command.NewKeyPress(sessionID, key))
+}
+
+// EvaluateJS runs a JavaScript expression in the session's page; the
+// result arrives through OnJSEvaluated.
+func (b *UIEventBridge) EvaluateJS(sessionID, expression string) error {
+	return b.dispatch(command.NewEvaluateJS(sessionID, expression))
 }
 
 // ClickAll performs a click on all active sessions.
 func (b *UIEventBridge) ClickAll(x, y float64) error {
-	return b.coordinator.Dispatch(&command.ClickAll{X: x, Y: y})
+	return b.dispatch(&command.ClickAll{X: x, Y: y})
 }
 
 // Drag performs a drag operation.
 func (b *UIEventBridge) Drag(sessionID string, fromX, fromY, toX, toY float64) error {
-	return b.coordinator.Dispatch(command.NewDragFromTo(sessionID, fromX, fromY, toX, toY))
+	return b.dispatch(command.NewDragFromTo(sessionID, fromX, fromY, toX, toY))
 }
 
 // DragAll performs a drag on all active sessions.
 func (b *UIEventBridge) DragAll(fromX, fromY, toX, toY float64) error {
-	return b.coordinator.Dispatch(&command.DragAll{
+	return b.dispatch(&command.DragAll{
 		Points: []command.Point{{X: fromX, Y: fromY}, {X: toX, Y: toY}},
 	})
 }
 
 // CaptureScreen captures the current browser screen.
 func (b *UIEventBridge) CaptureScreen(sessionID string, saveToFile bool) error {
-	return b.coordinator.Dispatch(command.NewCaptureScreen(sessionID, saveToFile))
+	return b.dispatch(command.NewCaptureScreen(sessionID, saveToFile))
 }
 
 // RefreshPage refreshes the browser page.
 func (b *UIEventBridge) RefreshPage(sessionID string) error {
-	return b.coordinator.Dispatch(command.NewRefreshPage(sessionID))
+	return b.dispatch(command.NewRefreshPage(sessionID))
 }
 
 // SaveCookies saves the current session cookies.
 func (b *UIEventBridge) SaveCookies(sessionID string) error {
-	return b.coordinator.Dispatch(command.NewSaveCookies(sessionID))
+	return b.dispatch(command.NewSaveCookies(sessionID))
 }
 
 // StartScreencast starts frame streaming for a session.
 func (b *UIEventBridge) StartScreencast(sessionID string, quality, maxFPS int) error {
-	return b.coordinator.Dispatch(command.NewStartScreencast(sessionID, quality, maxFPS))
+	return b.dispatch(command.NewStartScreencast(sessionID, quality, maxFPS))
 }
 
 // StopScreencast stops frame streaming for a session.
 func (b *UIEventBridge) StopScreencast(sessionID string) error {
-	return b.coordinator.Dispatch(command.NewStopScreencast(sessionID))
+	return b.dispatch(command.NewStopScreencast(sessionID))
 }
 
 // StartScript starts a script on a session.
 func (b *UIEventBridge) StartScript(sessionID, scriptName string) error {
-	return b.coordinator.Dispatch(command.NewStartScript(sessionID, scriptName))
+	return b.dispatch(command.NewStartScript(sessionID, scriptName))
+}
+
+// StartScriptWithParams starts a script with the values entered for its prompts.
+func (b *UIEventBridge) StartScriptWithParams(sessionID, scriptName string, params map[string]string) error {
+	return b.dispatch(command.NewStartScriptWithParams(sessionID, scriptName, params))
+}
+
+// DebugScript starts a script that halts before each action until
+// StepScript is called. params may be nil.
+func (b *UIEventBridge) DebugScript(sessionID, scriptName string, params map[string]string) error {
+	cmd := command.NewStartScriptWithParams(sessionID, scriptName, params)
+	cmd.Debug = true
+	return b.dispatch(cmd)
+}
+
+// StepScript lets a script halted in debug mode perform its pending
+// action. With cont, the script leaves debug mode and runs on.
+func (b *UIEventBridge) StepScript(sessionID string, cont bool) error {
+	cmd := command.NewStepScript(sessionID)
+	cmd.Continue = cont
+	return b.dispatch(cmd)
 }
 
 // StopScript stops the running script on a session.
 func (b *UIEventBridge) StopScript(sessionID string) error {
-	return b.coordinator.Dispatch(command.NewStopScript(sessionID))
+	return b.dispatch(command.NewStopScript(sessionID))
+}
+
+// PauseScript pauses the running script on a session.
+func (b *UIEventBridge) PauseScript(sessionID string) error {
+	return b.dispatch(command.NewPauseScript(sessionID))
+}
+
+// ResumeScript resumes the paused script on a session.
+func (b *UIEventBridge) ResumeScript(sessionID string) error {
+	return b.dispatch(command.NewResumeScript(sessionID))
+}
+
+// TuneSession changes a session's matching and pacing without restarting
+// its script.
+func (b *UIEventBridge) TuneSession(cmd *command.TuneSession) error {
+	return b.dispatch(cmd)
 }
 
 // StartAllScripts starts scripts on all sessions.
 func (b *UIEventBridge) StartAllScripts() error {
-	return b.coordinator.Dispatch(&command.StartAllScripts{})
+	return b.dispatch(&command.StartAllScripts{})
 }
 
 // StopAllScripts stops scripts on all sessions.
 func (b *UIEventBridge) StopAllScripts() error {
-	return b.coordinator.Dispatch(&command.StopAllScripts{})
+	return b.dispatch(&command.StopAllScripts{})
 }
 
 // SetScriptSelection sets the selected script for a session.
 func (b *UIEventBridge) SetScriptSelection(sessionID, scriptName string) error {
-	return b.coordinator.Dispatch(command.NewSetScriptSelection(sessionID, scriptName))
+	return b.dispatch(command.NewSetScriptSelection(sessionID, scriptName))
 }
 
 // SyncScriptSelection synchronizes script selection to all sessions.
 func (b *UIEventBridge) SyncScriptSelection(scriptName string) error {
-	return b.coordinator.Dispatch(&command.SyncScriptSelection{ScriptName: scriptName})
+	return b.dispatch(&command.SyncScriptSelection{ScriptName: scriptName})
 }
 
 // Query methods
@@ -223,6 +361,56 @@ func (b *UIEventBridge) IsScriptRunning(sessionID string) bool {
 	return sess != nil && sess.IsScriptRunning()
 }
 
+// ReadRoster captures a session's screen and returns the OCR'd text lines.
+// It blocks on the OCR service, so call it off the UI thread.
+func (b *UIEventBridge) ReadRoster(ctx context.Context, sessionID string) ([]string, error) {
+	return b.coordinator.ReadRoster(ctx, sessionID)
+}
+
+// Preflight checks that a group run of scriptName on accounts can succeed.
+// It may query the OCR service, so call it off the UI thread.
+func (b *UIEventBridge) Preflight(scriptName string, accounts []*account.Account) *application.PreflightReport {
+	return b.coordinator.Preflight(scriptName, accounts)
+}
+
+// LoginProfile returns the login page selectors used by new sessions.
+func (b *UIEventBridge) LoginProfile() browser.LoginProfile {
+	return b.coordinator.LoginProfile()
+}
+
+// CalibrateLogin records new login page selectors on the account's server.
+// It blocks until the user has clicked all fields, so call it off the UI thread.
+func (b *UIEventBridge) CalibrateLogin(ctx context.Context, acc *account.Account, onStep func(browser.LoginField)) (browser.LoginProfile, error) {
+	return b.coordinator.CalibrateLogin(ctx, acc, onStep)
+}
+
+// LastFrame returns the newest frame a session captured or streamed and
+// when it arrived.
+func (b *UIEventBridge) LastFrame(sessionID string) (image.Image, time.Time, bool) {
+	return b.coordinator.LastFrame(sessionID)
+}
+
+// Tuning returns the current matching and pacing settings of a session.
+func (b *UIEventBridge) Tuning(sessionID string) (session.Tuning, bool) {
+	return b.coordinator.Tuning(sessionID)
+}
+
+// CheckScenes matches every scene against a frame captured from a session.
+func (b *UIEventBridge) CheckScenes(sessionID string, frame image.Image) ([]session.SceneCheck, bool) {
+	return b.coordinator.CheckScenes(sessionID, frame)
+}
+
+// DetectFrameOrigin returns where the game frame of a running session starts.
+func (b *UIEventBridge) DetectFrameOrigin(ctx context.Context, sessionID string) (browser.Point, error) {
+	return b.coordinator.DetectFrameOrigin(ctx, sessionID)
+}
+
+// SetFrameOrigin saves where scene and script coordinates assume the game
+// frame starts; nil turns translation off.
+func (b *UIEventBridge) SetFrameOrigin(origin *browser.Point) (browser.LoginProfile, error) {
+	return b.coordinator.SetFrameOrigin(origin)
+}
+
 // Event handling
 
 func (b *UIEventBridge) handleEvent(e event.Event) {
@@ -250,6 +438,26 @@ func (b *UIEventBridge) handleEvent(e event.Event) {
 			callbacks.OnSessionStateChanged(evt.SessionID(), evt.OldState, evt.NewState)
 		}
 
+	case *event.SessionQueued:
+		if callbacks.OnSessionQueued != nil {
+			callbacks.OnSessionQueued(evt.SessionID(), evt.Position)
+		}
+
+	case *event.SessionReconnecting:
+		if callbacks.OnSessionReconnecting != nil {
+			callbacks.OnSessionReconnecting(evt.SessionID(), evt.Attempt, evt.Delay, evt.Error)
+		}
+
+	case *event.SessionReconnected:
+		if callbacks.OnSessionReconnected != nil {
+			callbacks.OnSessionReconnected(evt.SessionID(), evt.Attempts)
+		}
+
+	case *event.LogRecorded:
+		if callbacks.OnLogRecorded != nil {
+			callbacks.OnLogRecorded(evt.SessionID(), evt.Time, evt.Level, evt.Text)
+		}
+
 	case *event.ScreenCaptured:
 		if callbacks.OnScreenCaptured != nil {
 			callbacks.OnScreenCaptured(evt.SessionID(), evt.Image)
@@ -265,6 +473,11 @@ func (b *UIEventBridge) handleEvent(e event.Event) {
 			callbacks.OnLoginFailed(evt.SessionID(), evt.Error)
 		}
 
+	case *event.LoginRetrying:
+		if callbacks.OnLoginRetrying != nil {
+			callbacks.OnLoginRetrying(evt.SessionID(), evt.Attempt, evt.Delay, evt.Password, evt.Error)
+		}
+
 	case *event.CookiesSaved:
 		if callbacks.OnCookiesSaved != nil {
 			callbacks.OnCookiesSaved(evt.SessionID())
@@ -275,6 +488,21 @@ func (b *UIEventBridge) handleEvent(e event.Event) {
 			callbacks.OnOperationFailed(evt.SessionID(), evt.Operation, evt.Error)
 		}
 
+	case *event.JSEvaluated:
+		if callbacks.OnJSEvaluated != nil {
+			callbacks.OnJSEvaluated(evt.SessionID(), evt.Result, evt.Error)
+		}
+
+	case *event.LatencyUpdated:
+		if callbacks.OnLatencyUpdated != nil {
+			callbacks.OnLatencyUpdated(evt.SessionID(), evt.Mean, evt.P95, evt.Lagging)
+		}
+
+	case *event.InputLagChanged:
+		if callbacks.OnInputLagChanged != nil {
+			callbacks.OnInputLagChanged(evt.SessionID(), evt.Lagging)
+		}
+
 	case *event.ScriptStarted:
 		if callbacks.OnScriptStarted != nil {
 			callbacks.OnScriptStarted(evt.SessionID(), evt.ScriptName)
@@ -290,6 +518,74 @@ func (b *UIEventBridge) handleEvent(e event.Event) {
 			callbacks.OnScriptSelectionChanged(evt.SessionID(), evt.ScriptName)
 		}
 
+	case *event.SessionTuned:
+		if callbacks.OnSessionTuned != nil {
+			tuning := session.Tuning{SceneThreshold: evt.SceneThreshold, PollInterval: evt.PollInterval}
+			if evt.JitterOverride {
+				tuning.Jitter = &script.Jitter{Pixels: evt.JitterPixels, Wait: evt.JitterWait}
+			}
+			callbacks.OnSessionTuned(evt.SessionID(), tuning)
+		}
+
+	case *event.CountersUpdated:
+		if callbacks.OnCountersUpdated != nil {
+			callbacks.OnCountersUpdated(evt.SessionID(), evt.ScriptName, evt.Counters)
+		}
+
+	case *event.ScriptRefused:
+		if callbacks.OnScriptRefused != nil {
+			callbacks.OnScriptRefused(evt.SessionID(), evt.ScriptName, evt.CorrelationID(), evt.Reason)
+		}
+
+	case *event.SetupFinished:
+		if callbacks.OnSetupFinished != nil {
+			callbacks.OnSetupFinished(evt.SessionID(), evt.ScriptName, evt.Completed, evt.Error)
+		}
+
+	case *event.ScriptStuck:
+		if callbacks.OnScriptStuck != nil {
+			callbacks.OnScriptStuck(evt.SessionID(), evt.ScriptName, evt.Idle, evt.Action)
+		}
+	case *event.ScreenFrozen:
+		if callbacks.OnScreenFrozen != nil {
+			callbacks.OnScreenFrozen(evt.SessionID(), evt.ScriptName, evt.Frozen, evt.Action)
+		}
+
+	case *event.ScriptHalted:
+		if callbacks.OnScriptHalted != nil {
+			callbacks.OnScriptHalted(evt.SessionID(), evt.ScriptName, evt.Step, evt.Action, evt.Screen)
+		}
+
+	case *event.ScriptThrottled:
+		if callbacks.OnScriptThrottled != nil {
+			callbacks.OnScriptThrottled(evt.SessionID(), evt.ScriptName, evt.Action, evt.Limit)
+		}
+
+	case *event.ActionPerformed:
+		if callbacks.OnActionPerformed != nil {
+			callbacks.OnActionPerformed(evt.SessionID(), evt.Action, evt.FromX, evt.FromY, evt.ToX, evt.ToY)
+		}
+
+	case *event.ScriptsReloaded:
+		if callbacks.OnScriptsReloaded != nil {
+			callbacks.OnScriptsReloaded(evt.Names, evt.Error)
+		}
+
+	case *event.SceneThresholdSuggested:
+		if callbacks.OnSceneThresholdSuggested != nil {
+			callbacks.OnSceneThresholdSuggested(evt.SceneName, evt.Threshold, evt.Suggested, evt.Count, evt.Sessions, evt.Applied)
+		}
+
+	case *event.SceneRegressed:
+		if callbacks.OnSceneRegressed != nil {
+			callbacks.OnSceneRegressed(evt.SceneName, evt.Frames, evt.Regressions, evt.Points)
+		}
+
+	case *event.AccountsSynced:
+		if callbacks.OnAccountsSynced != nil {
+			callbacks.OnAccountsSynced(evt.Added, evt.Updated, evt.Skipped, evt.Error)
+		}
+
 	case *event.ScreencastStarted:
 		if callbacks.OnScreencastStarted != nil {
 			callbacks.OnScreencastStarted(evt.SessionID(), evt.Quality, evt.MaxFPS)
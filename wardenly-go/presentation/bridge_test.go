@@ -1,11 +1,14 @@
 package presentation
 
 import (
+	"errors"
 	"image"
 	"testing"
 
 	"wardenly-go/core/event"
 	"wardenly-go/core/state"
+	"wardenly-go/domain/account"
+	"wardenly-go/infrastructure/browser"
 )
 
 func TestUICallbacks_Nil(t *testing.T) {
@@ -110,3 +113,38 @@ func TestBridgeConfig(t *testing.T) {
 		t.Error("Logger should be nil by default")
 	}
 }
+
+func TestLaunchCommand(t *testing.T) {
+	headless := true
+	acc := &account.Account{ID: "a", Browser: &account.BrowserSettings{Headless: &headless, ViewportWidth: 1280}}
+
+	cmd := launchCommand(acc, SessionLaunch{Canvas: browser.CanvasSize{Width: 960, Height: 640}})
+	if cmd.CanvasWidth != 960 || cmd.CanvasHeight != 640 || !*cmd.Browser.Headless {
+		t.Errorf("command = %+v, browser = %+v", cmd, cmd.Browser)
+	}
+
+	cmd = launchCommand(acc, SessionLaunch{Headful: true})
+	if cmd.Browser.Headless == nil || *cmd.Browser.Headless || cmd.Browser.ViewportWidth != 1280 {
+		t.Errorf("headful browser = %+v", cmd.Browser)
+	}
+	if !*acc.Browser.Headless {
+		t.Error("the account's setting should not change")
+	}
+	if cmd := launchCommand(&account.Account{ID: "b"}, SessionLaunch{Headful: true}); cmd.Browser == nil || *cmd.Browser.Headless {
+		t.Errorf("headful without account settings = %+v", cmd.Browser)
+	}
+}
+
+func TestBridge_ScriptRefusedCorrelation(t *testing.T) {
+	var got string
+	b := &UIEventBridge{callbacks: &UICallbacks{
+		OnScriptRefused: func(sessionID, scriptName, correlationID string, reason error) {
+			got = correlationID
+		},
+	}}
+
+	b.handleEvent(event.Correlate(event.NewScriptRefused("s1", "daily", errors.New("blocked")), "c1"))
+	if got != "c1" {
+		t.Errorf("correlation ID = %q, want c1", got)
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"time"
 
 	"fyne.io/fyne/v2"
+
+	"wardenly-go/application/session"
+	"wardenly-go/infrastructure/browser"
 )
 
 // CanvasManager manages CanvasWindow lifecycle and callbacks with serial command processing.
@@ -20,6 +23,9 @@ type CanvasManager struct {
 	activeSessionID  string
 	sessionCallbacks map[string]*CanvasCallbacks
 	sessionCreatedAt map[string]time.Time // Cooldown management (migrated from MainWindow)
+	sessionTitles    map[string]string    // Shown in the window title
+	sessionSizes     map[string]browser.CanvasSize
+	sceneOverlays    map[string]bool // Sessions showing scene match results
 
 	// Screenshot throttling (preserves existing mechanism)
 	captureInProgress atomic.Bool
@@ -31,8 +37,9 @@ type CanvasManager struct {
 	cmdChan chan canvasCmd
 
 	// Dependencies
-	bridge *UIEventBridge
-	logger *slog.Logger
+	bridge        *UIEventBridge
+	logger        *slog.Logger
+	onInteraction func(sessionID string)
 
 	// Lifecycle
 	ctx    context.Context
@@ -45,6 +52,17 @@ type CanvasCallbacks struct {
 	sessionTab *SessionTab
 	onClick    func(x, y float32)
 	onDrag     func(fromX, fromY, toX, toY float32)
+	onScroll   func(x, y, deltaX, deltaY float32)
+	onKey      func(key string)
+}
+
+// Dispose drops the references to the session tab and its handlers.
+func (c *CanvasCallbacks) Dispose() {
+	c.sessionTab = nil
+	c.onClick = nil
+	c.onDrag = nil
+	c.onScroll = nil
+	c.onKey = nil
 }
 
 // canvasCmdType defines the type of canvas command.
@@ -57,6 +75,11 @@ const (
 	cmdDeactivate
 	cmdUpdateImage
 	cmdRequestCapture
+	cmdShowAction
+	cmdSetTitle
+	cmdSetSize
+	cmdShowNotice
+	cmdSetSceneOverlay
 )
 
 // canvasCmd represents a command to be processed by CanvasManager.
@@ -66,6 +89,17 @@ type canvasCmd struct {
 	tab       *SessionTab
 	image     image.Image
 	saveFile  bool
+	action    *actionCursor
+	title     string
+	size      browser.CanvasSize
+	notice    string
+	enabled   bool
+}
+
+// actionCursor is a script action to show with the ghost cursor.
+type actionCursor struct {
+	name                   string
+	fromX, fromY, toX, toY float32
 }
 
 // CanvasManagerConfig holds configuration for CanvasManager.
@@ -73,6 +107,9 @@ type CanvasManagerConfig struct {
 	App    fyne.App
 	Bridge *UIEventBridge
 	Logger *slog.Logger
+	// OnInteraction is called on the UI thread when the user clicks,
+	// drags, scrolls or types on a session's canvas; optional
+	OnInteraction func(sessionID string)
 }
 
 // NewCanvasManager creates a new CanvasManager.
@@ -87,9 +124,13 @@ func NewCanvasManager(cfg *CanvasManagerConfig) *CanvasManager {
 		canvasWindow:     NewCanvasWindow(cfg.App),
 		sessionCallbacks: make(map[string]*CanvasCallbacks),
 		sessionCreatedAt: make(map[string]time.Time),
+		sessionTitles:    make(map[string]string),
+		sessionSizes:     make(map[string]browser.CanvasSize),
+		sceneOverlays:    make(map[string]bool),
 		cmdChan:          make(chan canvasCmd, 100),
 		bridge:           cfg.Bridge,
 		logger:           cfg.Logger,
+		onInteraction:    cfg.OnInteraction,
 		ctx:              ctx,
 		cancel:           cancel,
 	}
@@ -138,6 +179,16 @@ func (m *CanvasManager) processCommand(cmd canvasCmd) {
 		m.handleUpdateImage(cmd)
 	case cmdRequestCapture:
 		m.handleRequestCapture(cmd)
+	case cmdShowAction:
+		m.handleShowAction(cmd)
+	case cmdSetTitle:
+		m.handleSetTitle(cmd)
+	case cmdSetSize:
+		m.handleSetSize(cmd)
+	case cmdShowNotice:
+		m.handleShowNotice(cmd)
+	case cmdSetSceneOverlay:
+		m.handleSetSceneOverlay(cmd)
 	}
 }
 
@@ -148,10 +199,33 @@ func (m *CanvasManager) handleRegisterSession(cmd canvasCmd) {
 	}
 
 	// Create callbacks that capture the canvasWindow reference
+	onClick := cmd.tab.HandleCanvasClick(m.canvasWindow)
+	onDrag := cmd.tab.HandleCanvasDrag(m.canvasWindow)
+	onScroll := cmd.tab.HandleCanvasScroll()
+	onKey := cmd.tab.HandleCanvasKey()
+	interacted := func() {
+		if m.onInteraction != nil {
+			m.onInteraction(cmd.sessionID)
+		}
+	}
 	callbacks := &CanvasCallbacks{
 		sessionTab: cmd.tab,
-		onClick:    cmd.tab.HandleCanvasClick(m.canvasWindow),
-		onDrag:     cmd.tab.HandleCanvasDrag(m.canvasWindow),
+		onClick: func(x, y float32) {
+			interacted()
+			onClick(x, y)
+		},
+		onDrag: func(fromX, fromY, toX, toY float32) {
+			interacted()
+			onDrag(fromX, fromY, toX, toY)
+		},
+		onScroll: func(x, y, deltaX, deltaY float32) {
+			interacted()
+			onScroll(x, y, deltaX, deltaY)
+		},
+		onKey: func(key string) {
+			interacted()
+			onKey(key)
+		},
 	}
 
 	m.sessionCallbacks[cmd.sessionID] = callbacks
@@ -162,8 +236,14 @@ func (m *CanvasManager) handleRegisterSession(cmd canvasCmd) {
 
 // handleUnregisterSession removes a session and handles canvas state.
 func (m *CanvasManager) handleUnregisterSession(cmd canvasCmd) {
+	if callbacks, exists := m.sessionCallbacks[cmd.sessionID]; exists {
+		callbacks.Dispose()
+	}
 	delete(m.sessionCallbacks, cmd.sessionID)
 	delete(m.sessionCreatedAt, cmd.sessionID)
+	delete(m.sessionTitles, cmd.sessionID)
+	delete(m.sessionSizes, cmd.sessionID)
+	delete(m.sceneOverlays, cmd.sessionID)
 
 	m.logger.Debug("Session unregistered from CanvasManager", "session_id", cmd.sessionID, "remaining_count", len(m.sessionCallbacks))
 
@@ -197,12 +277,36 @@ func (m *CanvasManager) handleActivateSession(cmd canvasCmd) {
 		return
 	}
 
+	switched := m.activeSessionID != cmd.sessionID
 	m.activeSessionID = cmd.sessionID
+	title := m.sessionTitles[cmd.sessionID]
+	size := m.sessionSizes[cmd.sessionID]
+
+	// Show the session's last frame right away when switching to it;
+	// fresh frames queue behind it and replace it as they arrive
+	var warm image.Image
+	var warmAge time.Duration
+	if switched && m.bridge != nil {
+		if img, at, ok := m.bridge.LastFrame(cmd.sessionID); ok {
+			warm, warmAge = img, time.Since(at)
+		}
+	}
 
 	// Set callbacks and show canvas on UI thread
 	fyne.Do(func() {
+		m.canvasWindow.SetSessionTitle(title)
+		m.canvasWindow.SetCanvasSize(size)
 		m.canvasWindow.SetOnClicked(callbacks.onClick)
 		m.canvasWindow.SetOnDragged(callbacks.onDrag)
+		m.canvasWindow.SetOnScrolled(callbacks.onScroll)
+		m.canvasWindow.SetOnKey(callbacks.onKey)
+		m.canvasWindow.HideCursor()
+		if switched {
+			m.canvasWindow.SetSceneOverlay(nil)
+		}
+		if warm != nil {
+			m.canvasWindow.SetStaleImage(warm, warmAge)
+		}
 		m.canvasWindow.Show()
 		m.logger.Debug("Canvas window Show() called", "session_id", cmd.sessionID)
 	})
@@ -210,12 +314,64 @@ func (m *CanvasManager) handleActivateSession(cmd canvasCmd) {
 	m.logger.Debug("Session activated", "session_id", cmd.sessionID)
 }
 
+// handleSetTitle stores a session's title, updating the window if the
+// session is shown.
+func (m *CanvasManager) handleSetTitle(cmd canvasCmd) {
+	m.sessionTitles[cmd.sessionID] = cmd.title
+	if cmd.sessionID != m.activeSessionID {
+		return
+	}
+	fyne.Do(func() {
+		m.canvasWindow.SetSessionTitle(cmd.title)
+	})
+}
+
+// handleSetSize stores a session's viewport size, resizing the window if
+// the session is shown.
+func (m *CanvasManager) handleSetSize(cmd canvasCmd) {
+	m.sessionSizes[cmd.sessionID] = cmd.size
+	if cmd.sessionID != m.activeSessionID {
+		return
+	}
+	fyne.Do(func() {
+		m.canvasWindow.SetCanvasSize(cmd.size)
+	})
+}
+
+// handleShowNotice labels the active session's frame until the next one.
+func (m *CanvasManager) handleShowNotice(cmd canvasCmd) {
+	if cmd.sessionID != m.activeSessionID {
+		return
+	}
+	fyne.Do(func() {
+		m.canvasWindow.ShowNotice(cmd.notice)
+	})
+}
+
+// handleSetSceneOverlay turns the scene overlay on or off for a session.
+// It is drawn from the next frame on and removed right away.
+func (m *CanvasManager) handleSetSceneOverlay(cmd canvasCmd) {
+	if cmd.enabled {
+		m.sceneOverlays[cmd.sessionID] = true
+		return
+	}
+	delete(m.sceneOverlays, cmd.sessionID)
+	if cmd.sessionID != m.activeSessionID {
+		return
+	}
+	fyne.Do(func() {
+		m.canvasWindow.SetSceneOverlay(nil)
+	})
+}
+
 // handleDeactivate deactivates the current session.
 func (m *CanvasManager) handleDeactivate() {
 	m.activeSessionID = ""
 
 	fyne.Do(func() {
 		m.canvasWindow.ClearCallbacks()
+		m.canvasWindow.HideCursor()
+		m.canvasWindow.SetSceneOverlay(nil)
 		m.canvasWindow.Hide()
 	})
 
@@ -243,14 +399,49 @@ func (m *CanvasManager) handleUpdateImage(cmd canvasCmd) {
 	// Get the session's callbacks to notify after image update
 	callbacks := m.sessionCallbacks[cmd.sessionID]
 
-	fyne.Do(func() {
-		m.canvasWindow.SetImage(cmd.image)
-		m.frameUpdatePending.Store(false)
+	show := func(checks []session.SceneCheck, overlay bool) {
+		fyne.Do(func() {
+			m.canvasWindow.SetImage(cmd.image)
+			if overlay {
+				m.canvasWindow.SetSceneOverlay(checks)
+			}
+			m.frameUpdatePending.Store(false)
 
-		// Notify SessionTab to update color if there's a pending color update
-		if callbacks != nil && callbacks.sessionTab != nil {
-			callbacks.sessionTab.OnScreenCaptured(m.canvasWindow)
-		}
+			// Notify SessionTab to update color if there's a pending color update
+			if callbacks != nil && callbacks.sessionTab != nil {
+				callbacks.sessionTab.OnScreenCaptured(m.canvasWindow)
+			}
+		})
+	}
+
+	if !m.sceneOverlays[cmd.sessionID] || m.bridge == nil {
+		show(nil, false)
+		return
+	}
+
+	// Matching every scene takes a while; keep it off the command loop.
+	// The pending flag holds back further frames meanwhile.
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				m.logger.Error("Scene check panicked", "error", r)
+				m.frameUpdatePending.Store(false)
+			}
+		}()
+		checks, _ := m.bridge.CheckScenes(cmd.sessionID, cmd.image)
+		show(checks, true)
+	}()
+}
+
+// handleShowAction moves the ghost cursor for the active session's script actions.
+func (m *CanvasManager) handleShowAction(cmd canvasCmd) {
+	if cmd.sessionID != m.activeSessionID || cmd.action == nil {
+		return
+	}
+
+	a := cmd.action
+	fyne.Do(func() {
+		m.canvasWindow.ShowAction(a.name, a.fromX, a.fromY, a.toX, a.toY)
 	})
 }
 
@@ -337,6 +528,43 @@ func (m *CanvasManager) ActivateSession(sessionID string) {
 	}
 }
 
+// SetSessionTitle sets the name shown in the canvas window title while the
+// session is active.
+func (m *CanvasManager) SetSessionTitle(sessionID, title string) {
+	select {
+	case m.cmdChan <- canvasCmd{typ: cmdSetTitle, sessionID: sessionID, title: title}:
+	case <-m.ctx.Done():
+	}
+}
+
+// SetSessionSize sets the viewport size the canvas window takes while the
+// session is active. Sessions without one use browser.BaseCanvas.
+func (m *CanvasManager) SetSessionSize(sessionID string, size browser.CanvasSize) {
+	select {
+	case m.cmdChan <- canvasCmd{typ: cmdSetSize, sessionID: sessionID, size: size}:
+	case <-m.ctx.Done():
+	}
+}
+
+// ShowNotice dims the session's shown frame and labels it with text until
+// the next frame arrives; an empty text removes the notice. Sessions that
+// are not shown are ignored.
+func (m *CanvasManager) ShowNotice(sessionID, text string) {
+	select {
+	case m.cmdChan <- canvasCmd{typ: cmdShowNotice, sessionID: sessionID, notice: text}:
+	case <-m.ctx.Done():
+	}
+}
+
+// SetSceneOverlay turns on or off drawing how every scene compares
+// against the session's frames while it is shown.
+func (m *CanvasManager) SetSceneOverlay(sessionID string, enabled bool) {
+	select {
+	case m.cmdChan <- canvasCmd{typ: cmdSetSceneOverlay, sessionID: sessionID, enabled: enabled}:
+	case <-m.ctx.Done():
+	}
+}
+
 // Deactivate deactivates the current canvas.
 func (m *CanvasManager) Deactivate() {
 	select {
@@ -354,6 +582,22 @@ func (m *CanvasManager) HandleScreenCaptured(sessionID string, img image.Image)
 	}
 }
 
+// HandleActionPerformed handles a script action event.
+// Called from the event bridge when a script clicks or drags.
+func (m *CanvasManager) HandleActionPerformed(sessionID, action string, fromX, fromY, toX, toY float64) {
+	a := &actionCursor{
+		name:  action,
+		fromX: float32(fromX),
+		fromY: float32(fromY),
+		toX:   float32(toX),
+		toY:   float32(toY),
+	}
+	select {
+	case m.cmdChan <- canvasCmd{typ: cmdShowAction, sessionID: sessionID, action: a}:
+	case <-m.ctx.Done():
+	}
+}
+
 // RequestCapture requests a screenshot capture for the active session.
 // Called by auto-refresh or manual capture requests.
 // Note: Uses empty sessionID; handleRequestCapture will use the current activeSessionID.
@@ -2,14 +2,32 @@ package presentation
 
 import (
 	"image"
+	"image/color"
 	"log/slog"
 	"sync"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/application/session"
+	"wardenly-go/infrastructure/browser"
+)
+
+// Ghost cursor timing.
+const (
+	cursorClickGlide = 150 * time.Millisecond
+	cursorDragGlide  = 400 * time.Millisecond
+	cursorIdleHide   = 2 * time.Second
+	cursorRadius     = 8
+	canvasTitle      = "Browser View"
 )
 
+// staleShadeColor dims a cached frame until a fresh one arrives.
+var staleShadeColor = color.NRGBA{A: 48}
+
 // CanvasWindow displays the browser view and handles user interactions.
 type CanvasWindow struct {
 	window    fyne.Window
@@ -21,15 +39,15 @@ type CanvasWindow struct {
 // NewCanvasWindow creates a new canvas window.
 func NewCanvasWindow(app fyne.App) *CanvasWindow {
 	w := &CanvasWindow{
-		window:    app.NewWindow("Browser View"),
-		canvas:    NewBrowserCanvas(fyne.NewSize(1080, 720)),
+		window:    app.NewWindow(canvasTitle),
+		canvas:    NewBrowserCanvas(canvasFyneSize(browser.BaseCanvas)),
 		isVisible: false,
 		logger:    slog.Default(),
 	}
 
 	w.window.SetPadded(false)
 	w.window.SetContent(w.canvas)
-	w.window.Resize(fyne.NewSize(1080, 720))
+	w.window.Resize(canvasFyneSize(browser.BaseCanvas))
 	w.window.SetFixedSize(true)
 	w.window.SetCloseIntercept(func() {
 		// Do nothing, preventing window from closing
@@ -40,6 +58,24 @@ func NewCanvasWindow(app fyne.App) *CanvasWindow {
 	return w
 }
 
+// canvasFyneSize converts a canvas size to a fyne size.
+func canvasFyneSize(size browser.CanvasSize) fyne.Size {
+	return fyne.NewSize(float32(size.Width), float32(size.Height))
+}
+
+// SetCanvasSize resizes the view and the window to a session's viewport.
+func (w *CanvasWindow) SetCanvasSize(size browser.CanvasSize) {
+	if size.IsZero() {
+		size = browser.BaseCanvas
+	}
+	fs := canvasFyneSize(size)
+	if w.canvas.Size() == fs {
+		return
+	}
+	w.canvas.SetSize(fs)
+	w.window.Resize(fs)
+}
+
 // Show displays the canvas window.
 func (w *CanvasWindow) Show() {
 	if !w.isVisible {
@@ -56,6 +92,16 @@ func (w *CanvasWindow) Hide() {
 	}
 }
 
+// SetSessionTitle names the shown session in the window title. An empty
+// name restores the default title.
+func (w *CanvasWindow) SetSessionTitle(name string) {
+	title := canvasTitle
+	if name != "" {
+		title += " - " + name
+	}
+	w.window.SetTitle(title)
+}
+
 // Close closes the canvas window.
 func (w *CanvasWindow) Close() {
 	w.window.Close()
@@ -71,6 +117,16 @@ func (w *CanvasWindow) SetOnDragged(fn func(fromX, fromY, toX, toY float32)) {
 	w.canvas.SetOnDragged(fn)
 }
 
+// SetOnScrolled sets the mouse wheel handler.
+func (w *CanvasWindow) SetOnScrolled(fn func(x, y, deltaX, deltaY float32)) {
+	w.canvas.SetOnScrolled(fn)
+}
+
+// SetOnKey sets the handler for keys typed while the canvas has focus.
+func (w *CanvasWindow) SetOnKey(fn func(key string)) {
+	w.canvas.SetOnKey(fn)
+}
+
 // SetImage sets the displayed image.
 func (w *CanvasWindow) SetImage(img image.Image) {
 	if img == nil {
@@ -79,6 +135,21 @@ func (w *CanvasWindow) SetImage(img image.Image) {
 	w.canvas.SetImage(img)
 }
 
+// SetStaleImage shows a cached frame, dimmed and labeled with its age,
+// until the next SetImage.
+func (w *CanvasWindow) SetStaleImage(img image.Image, age time.Duration) {
+	if img == nil {
+		return
+	}
+	w.canvas.SetStaleImage(img, age)
+}
+
+// ShowNotice dims the shown frame and labels it with text until the next
+// SetImage; an empty text removes the notice.
+func (w *CanvasWindow) ShowNotice(text string) {
+	w.canvas.ShowNotice(text)
+}
+
 // GetImage returns the current image.
 func (w *CanvasWindow) GetImage() image.Image {
 	return w.canvas.GetImage()
@@ -89,11 +160,31 @@ func (w *CanvasWindow) IsVisible() bool {
 	return w.isVisible
 }
 
+// ShowAction moves the ghost cursor to a script action. A click glides from
+// the previous cursor position to (toX, toY); a drag jumps to (fromX, fromY)
+// and glides to (toX, toY).
+func (w *CanvasWindow) ShowAction(action string, fromX, fromY, toX, toY float32) {
+	w.canvas.ShowAction(action, fyne.NewPos(fromX, fromY), fyne.NewPos(toX, toY))
+}
+
+// SetSceneOverlay draws scene match results over the shown frame; nil
+// removes them.
+func (w *CanvasWindow) SetSceneOverlay(checks []session.SceneCheck) {
+	w.canvas.SetSceneOverlay(checks)
+}
+
+// HideCursor hides the ghost cursor.
+func (w *CanvasWindow) HideCursor() {
+	w.canvas.HideCursor()
+}
+
 // ClearCallbacks clears all callbacks to avoid dangling references.
 // This should be called when switching sessions or before hiding the window.
 func (w *CanvasWindow) ClearCallbacks() {
 	w.canvas.SetOnClicked(nil)
 	w.canvas.SetOnDragged(nil)
+	w.canvas.SetOnScrolled(nil)
+	w.canvas.SetOnKey(nil)
 }
 
 // BrowserCanvas is a custom widget for displaying browser screenshots.
@@ -103,8 +194,25 @@ type BrowserCanvas struct {
 	imageMu   sync.RWMutex
 	onClicked func(x, y float32)
 	onDragged func(fromX, fromY, toX, toY float32)
+	onScroll  func(x, y, deltaX, deltaY float32)
+	onKey     func(key string)
+	focused   bool
 	dragMu    sync.Mutex
 	dragRec   *dragRecord
+
+	// Ghost cursor showing where scripts act (UI thread only)
+	cursorDot   *canvas.Circle
+	cursorLabel *canvas.Text
+	cursorPos   fyne.Position
+	cursorAnim  *fyne.Animation
+	cursorTimer *time.Timer
+
+	// Overlay dimming a cached or paused frame, with a caption (UI thread only)
+	staleShade *canvas.Rectangle
+	staleLabel *canvas.Text
+
+	// Scene match markers and list (UI thread only)
+	sceneOverlay *fyne.Container
 }
 
 type dragRecord struct {
@@ -119,9 +227,35 @@ func NewBrowserCanvas(size fyne.Size) *BrowserCanvas {
 	bc.ExtendBaseWidget(bc)
 	bc.canvas.Resize(size)
 	bc.canvas.FillMode = canvas.ImageFillOriginal
+
+	bc.cursorDot = canvas.NewCircle(color.NRGBA{R: 255, G: 64, B: 64, A: 160})
+	bc.cursorDot.StrokeColor = color.White
+	bc.cursorDot.StrokeWidth = 2
+	bc.cursorDot.Resize(fyne.NewSquareSize(2 * cursorRadius))
+	bc.cursorDot.Hide()
+	bc.cursorLabel = canvas.NewText("", color.White)
+	bc.cursorLabel.TextStyle = fyne.TextStyle{Bold: true}
+	bc.cursorLabel.Hide()
+
+	bc.staleShade = canvas.NewRectangle(staleShadeColor)
+	bc.staleShade.Resize(size)
+	bc.staleShade.Hide()
+	bc.staleLabel = canvas.NewText("", color.White)
+	bc.staleLabel.TextSize = 12
+	bc.staleLabel.Move(fyne.NewPos(8, 6))
+	bc.staleLabel.Hide()
+
+	bc.sceneOverlay = container.NewWithoutLayout()
 	return bc
 }
 
+// SetSize resizes the view, e.g. for a session with another viewport.
+func (b *BrowserCanvas) SetSize(size fyne.Size) {
+	b.canvas.Resize(size)
+	b.staleShade.Resize(size)
+	b.Resize(size)
+}
+
 // SetImage sets the displayed image.
 func (b *BrowserCanvas) SetImage(img image.Image) {
 	if img == nil {
@@ -130,10 +264,41 @@ func (b *BrowserCanvas) SetImage(img image.Image) {
 	b.imageMu.Lock()
 	b.canvas.Image = img
 	b.imageMu.Unlock()
+	b.staleShade.Hide()
+	b.staleLabel.Hide()
 	b.canvas.Refresh()
 	b.Refresh()
 }
 
+// SetStaleImage sets a cached image and marks it stale until the next
+// SetImage.
+func (b *BrowserCanvas) SetStaleImage(img image.Image, age time.Duration) {
+	b.SetImage(img)
+	b.ShowNotice(staleCaption(age))
+}
+
+// ShowNotice dims the current image and labels it with text until the
+// next SetImage; an empty text removes the notice.
+func (b *BrowserCanvas) ShowNotice(text string) {
+	if text == "" {
+		b.staleShade.Hide()
+		b.staleLabel.Hide()
+		return
+	}
+	b.staleLabel.Text = text
+	b.staleShade.Show()
+	b.staleLabel.Show()
+	b.staleLabel.Refresh()
+}
+
+// staleCaption describes how old a cached frame is.
+func staleCaption(age time.Duration) string {
+	if age < time.Second {
+		return "Last frame"
+	}
+	return "Last frame, " + age.Truncate(time.Second).String() + " ago"
+}
+
 // GetImage returns the current image.
 func (b *BrowserCanvas) GetImage() image.Image {
 	b.imageMu.RLock()
@@ -143,7 +308,76 @@ func (b *BrowserCanvas) GetImage() image.Image {
 
 // CreateRenderer creates the widget renderer.
 func (b *BrowserCanvas) CreateRenderer() fyne.WidgetRenderer {
-	return widget.NewSimpleRenderer(b.canvas)
+	return widget.NewSimpleRenderer(container.NewWithoutLayout(b.canvas, b.staleShade, b.staleLabel, b.sceneOverlay, b.cursorDot, b.cursorLabel))
+}
+
+// SetSceneOverlay draws how the scenes compare against the shown frame:
+// the matched scenes and the closest unmatched ones, with their points.
+// nil removes the overlay.
+func (b *BrowserCanvas) SetSceneOverlay(checks []session.SceneCheck) {
+	b.sceneOverlay.Objects = overlayObjects(overlayChecks(checks))
+	b.sceneOverlay.Refresh()
+}
+
+// ShowAction animates the ghost cursor for a script action and labels it
+// with the action name. The cursor hides itself after a short idle time.
+func (b *BrowserCanvas) ShowAction(action string, from, to fyne.Position) {
+	if b.cursorAnim != nil {
+		b.cursorAnim.Stop()
+	}
+
+	start, glide := b.cursorPos, cursorClickGlide
+	if from != to || !b.cursorDot.Visible() {
+		start = from
+	}
+	if from != to {
+		glide = cursorDragGlide
+	}
+
+	b.cursorLabel.Text = action
+	b.moveCursor(start)
+	b.cursorDot.Show()
+	b.cursorLabel.Show()
+
+	b.cursorAnim = fyne.NewAnimation(glide, func(p float32) {
+		b.moveCursor(lerpPosition(start, to, p))
+	})
+	b.cursorAnim.Start()
+
+	if b.cursorTimer != nil {
+		b.cursorTimer.Stop()
+	}
+	b.cursorTimer = time.AfterFunc(cursorIdleHide, func() {
+		fyne.Do(b.HideCursor)
+	})
+}
+
+// HideCursor stops the ghost cursor animation and hides it.
+func (b *BrowserCanvas) HideCursor() {
+	if b.cursorAnim != nil {
+		b.cursorAnim.Stop()
+		b.cursorAnim = nil
+	}
+	if b.cursorTimer != nil {
+		b.cursorTimer.Stop()
+		b.cursorTimer = nil
+	}
+	b.cursorDot.Hide()
+	b.cursorLabel.Hide()
+}
+
+// moveCursor centers the cursor dot on pos with the label beside it.
+func (b *BrowserCanvas) moveCursor(pos fyne.Position) {
+	b.cursorPos = pos
+	b.cursorDot.Move(pos.SubtractXY(cursorRadius, cursorRadius))
+	b.cursorLabel.Move(pos.AddXY(cursorRadius+4, -cursorRadius))
+	b.cursorDot.Refresh()
+	b.cursorLabel.Refresh()
+}
+
+// lerpPosition interpolates between from and to, with p in [0, 1].
+func lerpPosition(from, to fyne.Position, p float32) fyne.Position {
+	return fyne.NewPos(from.X+(to.X-from.X)*p, from.Y+(to.Y-from.Y)*p)
 }
 
 // SetOnClicked sets the click handler.
@@ -151,8 +385,12 @@ func (b *BrowserCanvas) SetOnClicked(fn func(x, y float32)) {
 	b.onClicked = fn
 }
 
-// Tapped handles tap events.
+// Tapped handles tap events. Tapping also focuses the canvas so typed keys
+// reach the game.
 func (b *BrowserCanvas) Tapped(e *fyne.PointEvent) {
+	if c := fyne.CurrentApp().Driver().CanvasForObject(b); c != nil && !b.focused {
+		c.Focus(b)
+	}
 	if b.onClicked != nil {
 		b.onClicked(e.Position.X, e.Position.Y)
 	}
@@ -196,6 +434,95 @@ func (b *BrowserCanvas) DragEnd() {
 	}
 }
 
+// SetOnScrolled sets the mouse wheel handler.
+func (b *BrowserCanvas) SetOnScrolled(fn func(x, y, deltaX, deltaY float32)) {
+	b.onScroll = fn
+}
+
+// Scrolled forwards mouse wheel events. Fyne reports wheel up as a
+// positive DY, the opposite of DOM wheel deltas, and in smaller steps.
+func (b *BrowserCanvas) Scrolled(e *fyne.ScrollEvent) {
+	if b.onScroll != nil {
+		dx, dy := wheelDelta(e.Scrolled)
+		b.onScroll(e.Position.X, e.Position.Y, dx, dy)
+	}
+}
+
+// wheelScale converts fyne scroll steps (10 per wheel notch) to DOM wheel
+// pixels (100 per notch).
+const wheelScale = 10
+
+// wheelDelta converts a fyne scroll delta to DOM wheel deltas.
+func wheelDelta(d fyne.Delta) (deltaX, deltaY float32) {
+	return -d.DX * wheelScale, -d.DY * wheelScale
+}
+
+// SetOnKey sets the handler for typed keys, given as DOM key values.
+func (b *BrowserCanvas) SetOnKey(fn func(key string)) {
+	b.onKey = fn
+}
+
+// FocusGained implements fyne.Focusable.
+func (b *BrowserCanvas) FocusGained() {
+	b.focused = true
+}
+
+// FocusLost implements fyne.Focusable.
+func (b *BrowserCanvas) FocusLost() {
+	b.focused = false
+}
+
+// TypedRune sends a typed character to the game.
+func (b *BrowserCanvas) TypedRune(r rune) {
+	if b.onKey != nil {
+		b.onKey(string(r))
+	}
+}
+
+// TypedKey sends keys without a character, such as Enter or arrows, to the
+// game. Character keys arrive through TypedRune.
+func (b *BrowserCanvas) TypedKey(e *fyne.KeyEvent) {
+	if key, ok := domKeys[e.Name]; ok && b.onKey != nil {
+		b.onKey(key)
+	}
+}
+
+// AcceptsTab keeps Tab in the game instead of moving focus.
+func (b *BrowserCanvas) AcceptsTab() bool {
+	return true
+}
+
+// domKeys maps fyne key names without a character to DOM key values.
+var domKeys = map[fyne.KeyName]string{
+	fyne.KeyReturn:    "Enter",
+	fyne.KeyEnter:     "Enter",
+	fyne.KeyBackspace: "Backspace",
+	fyne.KeyTab:       "Tab",
+	fyne.KeyEscape:    "Escape",
+	fyne.KeyDelete:    "Delete",
+	fyne.KeyInsert:    "Insert",
+	fyne.KeyUp:        "ArrowUp",
+	fyne.KeyDown:      "ArrowDown",
+	fyne.KeyLeft:      "ArrowLeft",
+	fyne.KeyRight:     "ArrowRight",
+	fyne.KeyHome:      "Home",
+	fyne.KeyEnd:       "End",
+	fyne.KeyPageUp:    "PageUp",
+	fyne.KeyPageDown:  "PageDown",
+	fyne.KeyF1:        "F1",
+	fyne.KeyF2:        "F2",
+	fyne.KeyF3:        "F3",
+	fyne.KeyF4:        "F4",
+	fyne.KeyF5:        "F5",
+	fyne.KeyF6:        "F6",
+	fyne.KeyF7:        "F7",
+	fyne.KeyF8:        "F8",
+	fyne.KeyF9:        "F9",
+	fyne.KeyF10:       "F10",
+	fyne.KeyF11:       "F11",
+	fyne.KeyF12:       "F12",
+}
+
 // MinSize returns the minimum size of the canvas.
 func (b *BrowserCanvas) MinSize() fyne.Size {
 	return b.canvas.MinSize()
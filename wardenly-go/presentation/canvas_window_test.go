@@ -2,7 +2,13 @@ package presentation
 
 import (
 	"image"
+	"slices"
 	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"wardenly-go/application/session"
 )
 
 func TestDragRecord(t *testing.T) {
@@ -27,6 +33,24 @@ func TestDragRecord(t *testing.T) {
 	}
 }
 
+func TestLerpPosition(t *testing.T) {
+	from, to := fyne.NewPos(10, 20), fyne.NewPos(110, 220)
+
+	tests := []struct {
+		p    float32
+		want fyne.Position
+	}{
+		{0, from},
+		{0.5, fyne.NewPos(60, 120)},
+		{1, to},
+	}
+	for _, tt := range tests {
+		if got := lerpPosition(from, to, tt.p); got != tt.want {
+			t.Errorf("lerpPosition(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
 func TestBrowserCanvas_GetImage_Nil(t *testing.T) {
 	// Test that GetImage returns nil when no image is set
 	// Note: We can't fully test BrowserCanvas without Fyne app context,
@@ -63,3 +87,90 @@ func TestCanvasWindowVisibility(t *testing.T) {
 		t.Error("Expected not visible after Hide")
 	}
 }
+
+func TestBrowserCanvas_Keys(t *testing.T) {
+	b := NewBrowserCanvas(fyne.NewSize(100, 100))
+	var keys []string
+	b.SetOnKey(func(key string) { keys = append(keys, key) })
+
+	b.TypedRune('a')
+	b.TypedKey(&fyne.KeyEvent{Name: fyne.KeyA}) // arrives as a rune instead
+	b.TypedKey(&fyne.KeyEvent{Name: fyne.KeyReturn})
+	b.TypedKey(&fyne.KeyEvent{Name: fyne.KeyLeft})
+
+	if want := []string{"a", "Enter", "ArrowLeft"}; !slices.Equal(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+}
+
+func TestWheelDelta(t *testing.T) {
+	// One notch down in fyne is -10; the DOM expects +100
+	if dx, dy := wheelDelta(fyne.Delta{DX: 0, DY: -10}); dx != 0 || dy != 100 {
+		t.Errorf("wheelDelta(down) = (%v, %v), want (0, 100)", dx, dy)
+	}
+	if dx, dy := wheelDelta(fyne.Delta{DX: 10, DY: 10}); dx != -100 || dy != -100 {
+		t.Errorf("wheelDelta(up-left) = (%v, %v), want (-100, -100)", dx, dy)
+	}
+}
+
+func TestStaleCaption(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{0, "Last frame"},
+		{800 * time.Millisecond, "Last frame"},
+		{12*time.Second + 400*time.Millisecond, "Last frame, 12s ago"},
+		{90 * time.Second, "Last frame, 1m30s ago"},
+	}
+	for _, tt := range tests {
+		if got := staleCaption(tt.age); got != tt.want {
+			t.Errorf("staleCaption(%v) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestOverlayChecks(t *testing.T) {
+	checks := []session.SceneCheck{
+		{Name: "home", Matched: true},
+		{Name: "hud", Matched: true},
+		{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"},
+	}
+	var names []string
+	for _, c := range overlayChecks(checks) {
+		names = append(names, c.Name)
+	}
+	if want := []string{"home", "hud", "a", "b", "c"}; !slices.Equal(names, want) {
+		t.Errorf("overlayChecks() = %v, want %v", names, want)
+	}
+	if overlayChecks(nil) != nil || overlayObjects(nil) != nil {
+		t.Error("an empty overlay draws something")
+	}
+}
+
+func TestOverlayLine(t *testing.T) {
+	tests := []struct {
+		check session.SceneCheck
+		want  string
+	}{
+		{
+			session.SceneCheck{Name: "home", Matched: true, AvgDiff: 1.24, Threshold: 5,
+				Points: []session.PointMark{{Matched: true}}},
+			"✓ home  diff 1.2 / 5.0",
+		},
+		{
+			session.SceneCheck{Name: "shop", AvgDiff: 12, Threshold: 5,
+				Points: []session.PointMark{{Matched: true}, {}, {}}},
+			"✗ shop  diff 12.0 / 5.0 · 2/3 points off",
+		},
+		{
+			session.SceneCheck{Name: "map", Template: &session.TemplateMark{Score: 0.931, Threshold: 0.95}},
+			"✗ map  score 0.93 / 0.95",
+		},
+	}
+	for _, tt := range tests {
+		if got := overlayLine(&tt.check); got != tt.want {
+			t.Errorf("overlayLine(%s) = %q, want %q", tt.check.Name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package presentation
+
+import (
+	"context"
+	"errors"
+	"image"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/application/session"
+)
+
+// errCaptchaDismissed is returned when the captcha window is closed
+// without an answer.
+var errCaptchaDismissed = errors.New("captcha window closed without an answer")
+
+// CaptchaPrompt solves login captchas by showing the captured frame in a
+// window and asking the user to type the characters.
+type CaptchaPrompt struct{}
+
+// NewCaptchaPrompt creates a captcha prompt. It opens its windows in the
+// running Fyne app, so it can be created before the app.
+func NewCaptchaPrompt() *CaptchaPrompt {
+	return &CaptchaPrompt{}
+}
+
+// Solve opens a window with the frame and waits for the user's answer.
+// The window closes when ctx is done.
+func (p *CaptchaPrompt) Solve(ctx context.Context, account string, img image.Image) (string, error) {
+	answers := make(chan string, 1)
+	var window fyne.Window
+	fyne.DoAndWait(func() {
+		window = fyne.CurrentApp().NewWindow("Captcha - " + account)
+
+		frame := canvas.NewImageFromImage(img)
+		frame.FillMode = canvas.ImageFillContain
+		frame.SetMinSize(fyne.NewSize(480, 320))
+
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder("Characters shown in the captcha")
+		submit := func() {
+			if entry.Text == "" {
+				return
+			}
+			select {
+			case answers <- entry.Text:
+			default:
+			}
+			window.Close()
+		}
+		entry.OnSubmitted = func(string) { submit() }
+		submitBtn := widget.NewButton("Submit", submit)
+		submitBtn.Importance = widget.HighImportance
+
+		hint := widget.NewLabel("Login for " + account + " is waiting on this captcha.")
+		window.SetContent(container.NewBorder(hint,
+			container.NewBorder(nil, nil, nil, submitBtn, entry), nil, nil, frame))
+		window.SetOnClosed(func() {
+			select {
+			case answers <- "":
+			default:
+			}
+		})
+		window.CenterOnScreen()
+		window.Show()
+		window.Canvas().Focus(entry)
+	})
+
+	select {
+	case answer := <-answers:
+		if answer == "" {
+			return "", errCaptchaDismissed
+		}
+		return answer, nil
+	case <-ctx.Done():
+		fyne.Do(window.Close)
+		return "", ctx.Err()
+	}
+}
+
+// Ensure CaptchaPrompt implements session.CaptchaSolver
+var _ session.CaptchaSolver = (*CaptchaPrompt)(nil)
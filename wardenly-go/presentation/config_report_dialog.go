@@ -0,0 +1,56 @@
+package presentation
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/infrastructure/config"
+)
+
+// ShowConfigReportDialog lists the config settings that could not be used,
+// each with the rejected value and a suggested fix.
+func ShowConfigReportDialog(report *config.Report, window fyne.Window) {
+	rows := container.NewVBox()
+	for _, issue := range report.Issues {
+		field := issue.Field
+		if field == "" {
+			field = "Config file"
+		}
+		if issue.Value != "" {
+			field = fmt.Sprintf("%s = %q", field, issue.Value)
+		}
+		reason := widget.NewLabel(issue.Reason)
+		reason.Wrapping = fyne.TextWrapWord
+		suggestion := widget.NewLabel(issue.Suggestion)
+		suggestion.Wrapping = fyne.TextWrapWord
+		suggestion.Importance = widget.LowImportance
+
+		rows.Add(container.NewBorder(nil, nil,
+			widget.NewIcon(theme.NewWarningThemedResource(theme.WarningIcon())), nil,
+			container.NewVBox(
+				widget.NewLabelWithStyle(field, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				reason,
+				suggestion,
+			)))
+	}
+
+	source := report.Path
+	if source == "" {
+		source = "the environment"
+	}
+	hint := widget.NewLabel(fmt.Sprintf("Some settings in %s could not be used; Wardenly runs with their defaults. "+
+		"Fix them and restart.", source))
+	hint.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVScroll(rows)
+	content.SetMinSize(fyne.NewSize(520, 260))
+
+	d := dialog.NewCustom("Configuration Problems", "Close", container.NewBorder(hint, nil, nil, nil, content), window)
+	d.Resize(fyne.NewSize(600, 420))
+	d.Show()
+}
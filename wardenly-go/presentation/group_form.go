@@ -1,7 +1,10 @@
 package presentation
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -13,10 +16,81 @@ import (
 	"wardenly-go/domain/group"
 )
 
+// noScriptOption is the script choice for groups without a default script.
+const noScriptOption = "(none)"
+
+// Membership choices: a static member list or a smart group query.
+const (
+	membershipStatic = "Static"
+	membershipSmart  = "Smart"
+)
+
 // GroupFormConfig holds configuration for GroupForm.
 type GroupFormConfig struct {
-	OnSave   func(*group.Group)
-	OnDelete func(*group.Group)
+	ScriptNames []string // Choices for the default script
+	// TemplateName returns the name of a template (optional)
+	TemplateName func(templateID string) string
+	OnSave       func(*group.Group)
+	OnDelete     func(*group.Group)
+}
+
+// runSettingsFields edits group.RunSettings; shared by group and template forms.
+type runSettingsFields struct {
+	scriptSelect  *widget.Select
+	intervalEntry *widget.Entry
+	stopCheck     *widget.Check
+}
+
+func newRunSettingsFields(scriptNames []string) *runSettingsFields {
+	f := &runSettingsFields{}
+	f.scriptSelect = widget.NewSelect(append([]string{noScriptOption}, scriptNames...), nil)
+	f.intervalEntry = widget.NewEntry()
+	f.intervalEntry.SetPlaceHolder(fmt.Sprintf("Seconds between starts (default %d)", int(group.DefaultStartInterval.Seconds())))
+	f.intervalEntry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if n, err := strconv.Atoi(s); err != nil || n < 0 {
+			return fmt.Errorf("must be a whole number of seconds")
+		}
+		return nil
+	}
+	f.stopCheck = widget.NewCheck("Stop sessions when the script finishes", nil)
+	return f
+}
+
+// formItems returns the fields as form rows.
+func (f *runSettingsFields) formItems() []*widget.FormItem {
+	return []*widget.FormItem{
+		widget.NewFormItem("Default Script", f.scriptSelect),
+		widget.NewFormItem("Start Interval", f.intervalEntry),
+		widget.NewFormItem("", f.stopCheck),
+	}
+}
+
+func (f *runSettingsFields) set(s group.RunSettings) {
+	if s.ScriptName == "" {
+		f.scriptSelect.SetSelected(noScriptOption)
+	} else {
+		f.scriptSelect.SetSelected(s.ScriptName)
+	}
+	if s.StartInterval > 0 {
+		f.intervalEntry.SetText(strconv.Itoa(int(s.StartInterval.Seconds())))
+	} else {
+		f.intervalEntry.SetText("")
+	}
+	f.stopCheck.SetChecked(s.StopWhenDone)
+}
+
+func (f *runSettingsFields) get() group.RunSettings {
+	s := group.RunSettings{StopWhenDone: f.stopCheck.Checked}
+	if f.scriptSelect.Selected != noScriptOption {
+		s.ScriptName = f.scriptSelect.Selected
+	}
+	if n, err := strconv.Atoi(f.intervalEntry.Text); err == nil && n > 0 {
+		s.StartInterval = time.Duration(n) * time.Second
+	}
+	return s
 }
 
 // GroupForm provides a form for editing group details.
@@ -28,14 +102,31 @@ type GroupForm struct {
 	nameEntry        *widget.Entry
 	descriptionEntry *widget.Entry
 	rankingEntry     *widget.Entry
+	settings         *runSettingsFields
+	templateLabel    *widget.Label
+
+	// Membership mode
+	membershipRadio *widget.RadioGroup
+
+	// Smart group query and builder
+	querySection *fyne.Container
+	queryEntry   *widget.Entry
+	joinSelect   *widget.Select
+	fieldSelect  *widget.Select
+	opSelect     *widget.Select
+	valueEntry   *widget.Entry
+	previewLabel *widget.Label
 
 	// Member selection
+	memberToolbar  *fyne.Container
 	memberChecks   []*widget.Check
 	memberPanel    *fyne.Container
 	memberScroll   *container.Scroll
 	selectAllBtn   *widget.Button
 	deselectAllBtn *widget.Button
-	allAccounts    []*account.Account
+	allAccounts    []*account.Account // Selectable (non-archived) accounts
+	archivedIDs    []string           // Archived members, kept on save
+	archivedLabel  *widget.Label
 
 	// Buttons
 	saveBtn   *widget.Button
@@ -63,24 +154,44 @@ func (gf *GroupForm) build() {
 	gf.rankingEntry = widget.NewEntry()
 	gf.rankingEntry.SetPlaceHolder("Sort priority (lower = higher)")
 
+	gf.settings = newRunSettingsFields(gf.config.ScriptNames)
+	gf.templateLabel = widget.NewLabel("")
+
 	// Use widget.Form for proper alignment
 	form := widget.NewForm(
 		widget.NewFormItem("Name", gf.nameEntry),
 		widget.NewFormItem("Description", gf.descriptionEntry),
 		widget.NewFormItem("Ranking", gf.rankingEntry),
 	)
+	for _, item := range gf.settings.formItems() {
+		form.AppendItem(item)
+	}
+	form.AppendItem(widget.NewFormItem("Template", gf.templateLabel))
 
 	// Member selection with Select All / Deselect All buttons
 	gf.selectAllBtn = widget.NewButton("Select All", gf.onSelectAll)
 	gf.deselectAllBtn = widget.NewButton("Deselect All", gf.onDeselectAll)
-	memberToolbar := container.NewHBox(gf.selectAllBtn, gf.deselectAllBtn)
+	gf.memberToolbar = container.NewHBox(gf.selectAllBtn, gf.deselectAllBtn)
+
+	gf.membershipRadio = widget.NewRadioGroup([]string{membershipStatic, membershipSmart}, gf.onMembershipChanged)
+	gf.membershipRadio.Horizontal = true
+	gf.membershipRadio.Required = true
+	gf.buildQuerySection()
+
+	gf.archivedLabel = widget.NewLabel("")
+	gf.archivedLabel.Wrapping = fyne.TextWrapWord
+	gf.archivedLabel.Importance = widget.LowImportance
+	gf.archivedLabel.Hide()
 
 	gf.memberPanel = container.NewVBox()
 	gf.memberScroll = container.NewVScroll(gf.memberPanel)
 	// No SetMinSize - let BorderLayout handle sizing
 
-	// Member header with label
-	memberHeader := widget.NewLabelWithStyle("Members", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	// Member header with label and membership mode
+	memberHeader := container.NewHBox(
+		widget.NewLabelWithStyle("Members", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		gf.membershipRadio,
+	)
 
 	// Buttons with icons - Delete on left, Save on right
 	gf.deleteBtn = widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), gf.onDelete)
@@ -100,7 +211,9 @@ func (gf *GroupForm) build() {
 		form,
 		widget.NewSeparator(),
 		memberHeader,
-		memberToolbar,
+		gf.querySection,
+		gf.memberToolbar,
+		gf.archivedLabel,
 	)
 
 	// Bottom section: separator + buttons
@@ -118,6 +231,84 @@ func (gf *GroupForm) build() {
 	))
 }
 
+// buildQuerySection builds the smart group query entry, the builder that
+// appends conditions to it and the match preview.
+func (gf *GroupForm) buildQuerySection() {
+	gf.queryEntry = widget.NewEntry()
+	gf.queryEntry.SetPlaceHolder("e.g., server_id=126 AND tag=farm")
+	gf.queryEntry.OnChanged = func(string) { gf.updatePreview() }
+
+	gf.joinSelect = widget.NewSelect([]string{"AND", "OR"}, nil)
+	gf.joinSelect.SetSelected("AND")
+	gf.fieldSelect = widget.NewSelect(group.QueryFields, nil)
+	gf.fieldSelect.SetSelected(group.QueryFields[0])
+	gf.opSelect = widget.NewSelect(group.QueryOperators, nil)
+	gf.opSelect.SetSelected(group.QueryOperators[0])
+	gf.valueEntry = widget.NewEntry()
+	gf.valueEntry.SetPlaceHolder("Value")
+	addBtn := widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), gf.onAddCondition)
+
+	gf.previewLabel = widget.NewLabel("")
+	gf.previewLabel.Wrapping = fyne.TextWrapWord
+	gf.previewLabel.Importance = widget.LowImportance
+
+	builder := container.NewBorder(nil, nil,
+		container.NewHBox(gf.joinSelect, gf.fieldSelect, gf.opSelect),
+		addBtn,
+		gf.valueEntry,
+	)
+	gf.querySection = container.NewVBox(gf.queryEntry, builder, gf.previewLabel)
+	gf.querySection.Hide()
+}
+
+// onAddCondition appends the builder's condition to the query.
+func (gf *GroupForm) onAddCondition() {
+	cond := group.FormatCondition(gf.fieldSelect.Selected, gf.opSelect.Selected, strings.TrimSpace(gf.valueEntry.Text))
+	if query := strings.TrimSpace(gf.queryEntry.Text); query != "" {
+		cond = query + " " + gf.joinSelect.Selected + " " + cond
+	}
+	gf.queryEntry.SetText(cond)
+	gf.valueEntry.SetText("")
+}
+
+// updatePreview shows which accounts the query currently matches.
+func (gf *GroupForm) updatePreview() {
+	text := strings.TrimSpace(gf.queryEntry.Text)
+	if text == "" {
+		gf.previewLabel.SetText("Build a query to select accounts at run time")
+		return
+	}
+	query, err := group.ParseQuery(text)
+	if err != nil {
+		gf.previewLabel.SetText(err.Error())
+		return
+	}
+	matched := query.Filter(gf.allAccounts)
+	names := make([]string, len(matched))
+	for i, acc := range matched {
+		names[i] = acc.Identity()
+	}
+	gf.previewLabel.SetText(fmt.Sprintf("Currently matches %d account(s): %s", len(matched), strings.Join(names, ", ")))
+}
+
+// onMembershipChanged switches between the member list and the query.
+func (gf *GroupForm) onMembershipChanged(mode string) {
+	if mode == membershipSmart {
+		gf.querySection.Show()
+		gf.memberToolbar.Hide()
+		gf.memberScroll.Hide()
+		gf.archivedLabel.Hide()
+		gf.updatePreview()
+		return
+	}
+	gf.querySection.Hide()
+	gf.memberToolbar.Show()
+	gf.memberScroll.Show()
+	if len(gf.archivedIDs) > 0 {
+		gf.archivedLabel.Show()
+	}
+}
+
 func (gf *GroupForm) onSelectAll() {
 	for _, check := range gf.memberChecks {
 		check.SetChecked(true)
@@ -137,9 +328,12 @@ func (gf *GroupForm) Container() fyne.CanvasObject {
 
 // SetGroup populates the form with group data.
 // Pass nil to clear the form for creating a new group.
-// accounts is the list of all available accounts for member selection.
+// accounts is the list of all accounts; archived ones are not offered for
+// selection, but archived members stay in the group.
 func (gf *GroupForm) SetGroup(grp *group.Group, accounts []*account.Account) {
 	gf.current = grp
+	gf.setArchivedMembers(grp, accounts)
+	accounts = account.ActiveAccounts(accounts)
 	gf.allAccounts = accounts
 
 	// Rebuild member checkboxes
@@ -156,6 +350,10 @@ func (gf *GroupForm) SetGroup(grp *group.Group, accounts []*account.Account) {
 		gf.nameEntry.SetText("")
 		gf.descriptionEntry.SetText("")
 		gf.rankingEntry.SetText("0")
+		gf.settings.set(group.RunSettings{})
+		gf.templateLabel.SetText("-")
+		gf.queryEntry.SetText("")
+		gf.membershipRadio.SetSelected(membershipStatic)
 		gf.deleteBtn.Disable()
 		// Uncheck all
 		for _, check := range gf.memberChecks {
@@ -165,6 +363,14 @@ func (gf *GroupForm) SetGroup(grp *group.Group, accounts []*account.Account) {
 		gf.nameEntry.SetText(grp.Name)
 		gf.descriptionEntry.SetText(grp.Description)
 		gf.rankingEntry.SetText(strconv.Itoa(grp.Ranking))
+		gf.settings.set(grp.Settings)
+		gf.templateLabel.SetText(gf.templateName(grp.TemplateID))
+		gf.queryEntry.SetText(grp.Query)
+		if grp.IsSmart() {
+			gf.membershipRadio.SetSelected(membershipSmart)
+		} else {
+			gf.membershipRadio.SetSelected(membershipStatic)
+		}
 		gf.deleteBtn.Enable()
 		// Check members that are in the group
 		memberSet := make(map[string]bool)
@@ -176,9 +382,59 @@ func (gf *GroupForm) SetGroup(grp *group.Group, accounts []*account.Account) {
 		}
 	}
 
+	// SetSelected skips the callback when the mode is unchanged
+	gf.onMembershipChanged(gf.membershipRadio.Selected)
 	gf.memberPanel.Refresh()
 }
 
+// templateName labels the template a group was created from.
+func (gf *GroupForm) templateName(templateID string) string {
+	if templateID == "" {
+		return "-"
+	}
+	if gf.config.TemplateName != nil {
+		if name := gf.config.TemplateName(templateID); name != "" {
+			return name
+		}
+	}
+	return "(deleted)"
+}
+
+// setArchivedMembers records the group's archived members and shows them.
+func (gf *GroupForm) setArchivedMembers(grp *group.Group, accounts []*account.Account) {
+	gf.archivedIDs = nil
+	var names []string
+	if grp != nil {
+		for _, acc := range accounts {
+			if acc.Archived && grp.ContainsAccount(acc.ID) {
+				gf.archivedIDs = append(gf.archivedIDs, acc.ID)
+				names = append(names, acc.Identity())
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		gf.archivedLabel.Hide()
+		return
+	}
+	gf.archivedLabel.SetText("Archived members (kept, skipped in runs): " + strings.Join(names, ", "))
+	gf.archivedLabel.Show()
+}
+
+// ProposeMembers checks exactly the given accounts, leaving the other
+// fields untouched so the proposal can be reviewed before saving. The group
+// is switched to a static member list.
+func (gf *GroupForm) ProposeMembers(accountIDs []string) {
+	gf.membershipRadio.SetSelected(membershipStatic)
+	proposed := make(map[string]bool, len(accountIDs))
+	for _, id := range accountIDs {
+		proposed[id] = true
+	}
+	for i, acc := range gf.allAccounts {
+		gf.memberChecks[i].SetChecked(proposed[acc.ID])
+	}
+}
+
 // Clear resets the form to empty state.
 func (gf *GroupForm) Clear() {
 	gf.SetGroup(nil, gf.allAccounts)
@@ -194,17 +450,24 @@ func (gf *GroupForm) onSave() {
 			accountIDs = append(accountIDs, gf.allAccounts[i].ID)
 		}
 	}
+	accountIDs = append(accountIDs, gf.archivedIDs...)
 
 	grp := &group.Group{
 		Name:        gf.nameEntry.Text,
 		Description: gf.descriptionEntry.Text,
 		Ranking:     ranking,
 		AccountIDs:  accountIDs,
+		Settings:    gf.settings.get(),
+	}
+	// The member list is kept for smart groups, so switching back restores it
+	if gf.membershipRadio.Selected == membershipSmart {
+		grp.Query = strings.TrimSpace(gf.queryEntry.Text)
 	}
 
-	// Preserve ID if editing
+	// Preserve ID and template link if editing
 	if gf.current != nil {
 		grp.ID = gf.current.ID
+		grp.TemplateID = gf.current.TemplateID
 	}
 
 	if gf.config.OnSave != nil {
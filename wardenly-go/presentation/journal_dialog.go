@@ -0,0 +1,315 @@
+package presentation
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/infrastructure/journal"
+	"wardenly-go/infrastructure/timelapse"
+)
+
+// JournalDialogConfig holds configuration for the event journal viewer.
+type JournalDialogConfig struct {
+	Dir string
+	// ScreenshotDir holds saved screenshots shown alongside events (optional).
+	ScreenshotDir string
+	Logger        *slog.Logger
+}
+
+// journalDialog steps through a past session's journaled events with the
+// closest saved screenshot. It can also trace one command across sessions
+// by its correlation ID.
+type journalDialog struct {
+	config *JournalDialogConfig
+	window fyne.Window
+
+	daySelect     *widget.Select
+	sessionSelect *widget.Select
+	traceEntry    *widget.Entry
+	traceBtn      *widget.Button
+	eventList     *widget.List
+	detailLabel   *widget.Label
+	shotLabel     *widget.Label
+	shotImage     *canvas.Image
+	positionLabel *widget.Label
+	prevBtn       *widget.Button
+	nextBtn       *widget.Button
+
+	entries  []journal.Entry   // Selected day
+	sessions map[string]string // Select label -> session ID
+	events   []journal.Entry   // Selected session or trace
+	frames   []timelapse.Frame
+	selected int
+}
+
+// ShowJournalDialog displays the event journal viewer.
+func ShowJournalDialog(cfg *JournalDialogConfig) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	d := &journalDialog{
+		config:   cfg,
+		selected: -1,
+	}
+
+	d.window = fyne.CurrentApp().NewWindow("Event Journal")
+	d.buildUI()
+	d.loadScreenshots()
+
+	d.window.Resize(fyne.NewSize(1000, 600))
+	d.window.CenterOnScreen()
+	d.window.Show()
+
+	days, err := journal.Days(cfg.Dir)
+	if err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+	d.daySelect.SetOptions(days)
+	if len(days) > 0 {
+		d.daySelect.SetSelectedIndex(0)
+	}
+}
+
+func (d *journalDialog) buildUI() {
+	d.daySelect = widget.NewSelect(nil, d.loadDay)
+	d.daySelect.PlaceHolder = "Select Day"
+	d.sessionSelect = widget.NewSelect(nil, d.loadSession)
+	d.sessionSelect.PlaceHolder = "Select Session"
+	d.traceEntry = widget.NewEntry()
+	d.traceEntry.SetPlaceHolder("Correlation ID")
+	d.traceEntry.OnSubmitted = d.loadTrace
+	d.traceBtn = widget.NewButton("Trace Command", d.traceSelected)
+
+	d.eventList = widget.NewList(
+		func() int { return len(d.events) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			e := d.events[id]
+			text := fmt.Sprintf("%s  %s  %s", e.Time.Local().Format("15:04:05"), e.Type, e.Details())
+			if d.traceEntry.Text != "" {
+				text = fmt.Sprintf("%s  [%s]  %s  %s", e.Time.Local().Format("15:04:05"), d.sessionLabel(e.SessionID), e.Type, e.Details())
+			}
+			obj.(*widget.Label).SetText(text)
+		},
+	)
+	d.eventList.OnSelected = d.showEvent
+
+	d.detailLabel = widget.NewLabel("")
+	d.detailLabel.Wrapping = fyne.TextWrapWord
+	d.shotLabel = widget.NewLabel("")
+	d.shotImage = canvas.NewImageFromImage(nil)
+	d.shotImage.FillMode = canvas.ImageFillContain
+
+	d.prevBtn = widget.NewButton("Previous", func() { d.step(-1) })
+	d.nextBtn = widget.NewButton("Next", func() { d.step(1) })
+	d.positionLabel = widget.NewLabel("")
+	d.updateButtons()
+
+	trace := container.NewGridWrap(fyne.NewSize(200, d.traceEntry.MinSize().Height), d.traceEntry)
+	top := container.NewHBox(d.daySelect, d.sessionSelect, trace, d.traceBtn)
+	right := container.NewBorder(container.NewVBox(d.detailLabel, d.shotLabel), nil, nil, nil, d.shotImage)
+	split := container.NewHSplit(d.eventList, right)
+	split.Offset = 0.45
+	bottom := container.NewHBox(d.prevBtn, d.nextBtn, d.positionLabel)
+	d.window.SetContent(container.NewBorder(top, bottom, nil, nil, split))
+}
+
+// loadScreenshots lists saved screenshots; they are decoded when shown.
+func (d *journalDialog) loadScreenshots() {
+	if d.config.ScreenshotDir == "" {
+		return
+	}
+	frames, err := timelapse.LoadFrameDir(d.config.ScreenshotDir)
+	if err != nil {
+		d.config.Logger.Debug("No saved screenshots for journal viewer", "dir", d.config.ScreenshotDir, "error", err)
+		return
+	}
+	slices.SortFunc(frames, func(a, b timelapse.Frame) int { return a.Time.Compare(b.Time) })
+	d.frames = frames
+}
+
+func (d *journalDialog) loadDay(day string) {
+	entries, err := journal.ReadDay(d.config.Dir, day)
+	if err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+	d.entries = entries
+
+	// Label sessions by account so they can be told apart
+	accounts := make(map[string]string)
+	for _, e := range entries {
+		if e.Type != "SessionStarted" {
+			continue
+		}
+		var data struct {
+			Account string `json:"account"`
+		}
+		if json.Unmarshal(e.Data, &data) == nil && data.Account != "" {
+			accounts[e.SessionID] = data.Account
+		}
+	}
+	d.sessions = make(map[string]string)
+	var labels []string
+	for _, id := range journal.Sessions(entries) {
+		label := id
+		if account, ok := accounts[id]; ok {
+			label = fmt.Sprintf("%s (%s)", account, id)
+		}
+		d.sessions[label] = id
+		labels = append(labels, label)
+	}
+
+	d.sessionSelect.ClearSelected()
+	d.sessionSelect.SetOptions(labels)
+	if len(labels) > 0 {
+		d.sessionSelect.SetSelectedIndex(0)
+	} else {
+		d.loadSession("")
+	}
+}
+
+func (d *journalDialog) loadSession(label string) {
+	if label != "" {
+		d.traceEntry.SetText("")
+	}
+	var events []journal.Entry
+	if id, ok := d.sessions[label]; ok {
+		events = journal.ForSession(d.entries, id)
+	}
+	d.showEvents(events)
+}
+
+// loadTrace lists the events of the selected day caused by one command,
+// across all sessions. An empty ID goes back to the selected session.
+func (d *journalDialog) loadTrace(correlationID string) {
+	correlationID = strings.TrimSpace(correlationID)
+	if correlationID == "" {
+		d.traceEntry.SetText("")
+		d.loadSession(d.sessionSelect.Selected)
+		return
+	}
+	d.traceEntry.SetText(correlationID)
+	d.showEvents(journal.ForCorrelation(d.entries, correlationID))
+}
+
+// traceSelected traces the command that caused the selected event.
+func (d *journalDialog) traceSelected() {
+	if d.selected >= 0 && d.selected < len(d.events) {
+		d.loadTrace(d.events[d.selected].CorrelationID)
+	}
+}
+
+// sessionLabel returns the select label of a session, or its ID.
+func (d *journalDialog) sessionLabel(sessionID string) string {
+	for label, id := range d.sessions {
+		if id == sessionID {
+			return label
+		}
+	}
+	return sessionID
+}
+
+func (d *journalDialog) showEvents(events []journal.Entry) {
+	d.events = events
+	d.selected = -1
+	d.eventList.UnselectAll()
+	d.eventList.Refresh()
+	d.detailLabel.SetText("")
+	d.shotLabel.SetText("")
+	d.shotImage.Image = nil
+	d.shotImage.Refresh()
+	d.updateButtons()
+	if len(d.events) > 0 {
+		d.eventList.Select(0)
+	}
+}
+
+func (d *journalDialog) step(delta int) {
+	next := d.selected + delta
+	if next < 0 || next >= len(d.events) {
+		return
+	}
+	d.eventList.Select(next)
+	d.eventList.ScrollTo(next)
+}
+
+func (d *journalDialog) showEvent(id widget.ListItemID) {
+	if id < 0 || id >= len(d.events) {
+		return
+	}
+	d.selected = id
+	e := d.events[id]
+	detail := fmt.Sprintf("%s  %s\n%s", e.Time.Local().Format("2006-01-02 15:04:05.000"), e.Type, e.Details())
+	if e.CorrelationID != "" {
+		detail += "\nCorrelation ID: " + e.CorrelationID
+	}
+	d.detailLabel.SetText(detail)
+	d.showScreenshot(e)
+	d.updateButtons()
+}
+
+// showScreenshot shows the last saved screenshot taken at or before the event.
+func (d *journalDialog) showScreenshot(e journal.Entry) {
+	i := sort.Search(len(d.frames), func(i int) bool { return d.frames[i].Time.After(e.Time) }) - 1
+	if i < 0 {
+		d.shotLabel.SetText("No saved screenshot before this event")
+		d.shotImage.Image = nil
+		d.shotImage.Refresh()
+		return
+	}
+
+	frame := d.frames[i]
+	img, err := frame.Image()
+	if err != nil {
+		d.config.Logger.Warn("Failed to load screenshot", "time", frame.Time, "error", err)
+		d.shotLabel.SetText("Failed to load screenshot: " + err.Error())
+		d.shotImage.Image = nil
+		d.shotImage.Refresh()
+		return
+	}
+	d.shotLabel.SetText(fmt.Sprintf("Screenshot at %s (%s before event)",
+		frame.Time.Local().Format("15:04:05"), e.Time.Sub(frame.Time).Round(time.Second)))
+	d.shotImage.Image = img
+	d.shotImage.Refresh()
+}
+
+func (d *journalDialog) updateButtons() {
+	if d.selected >= 0 && d.selected < len(d.events) && d.events[d.selected].CorrelationID != "" {
+		d.traceBtn.Enable()
+	} else {
+		d.traceBtn.Disable()
+	}
+	if d.selected > 0 {
+		d.prevBtn.Enable()
+	} else {
+		d.prevBtn.Disable()
+	}
+	if d.selected >= 0 && d.selected < len(d.events)-1 {
+		d.nextBtn.Enable()
+	} else {
+		d.nextBtn.Disable()
+	}
+	if d.selected >= 0 {
+		d.positionLabel.SetText(fmt.Sprintf("%d / %d", d.selected+1, len(d.events)))
+	} else {
+		d.positionLabel.SetText(fmt.Sprintf("%d events", len(d.events)))
+	}
+}
@@ -0,0 +1,81 @@
+package presentation
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+	"weak"
+)
+
+// lifecycleAudit tracks disposed SessionTabs through weak pointers so that
+// tabs still reachable long after disposal can be reported as leaks.
+type lifecycleAudit struct {
+	mu       sync.Mutex
+	disposed []disposedTab
+	logger   *slog.Logger
+}
+
+// disposedTab records a SessionTab at the time it was disposed.
+type disposedTab struct {
+	sessionID  string
+	ref        weak.Pointer[SessionTab]
+	disposedAt time.Time
+}
+
+func newLifecycleAudit(logger *slog.Logger) *lifecycleAudit {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &lifecycleAudit{logger: logger}
+}
+
+// TrackDisposed starts watching a tab that should no longer be referenced.
+func (a *lifecycleAudit) TrackDisposed(tab *SessionTab) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.disposed = append(a.disposed, disposedTab{
+		sessionID:  tab.SessionID(),
+		ref:        weak.Make(tab),
+		disposedAt: time.Now(),
+	})
+}
+
+// Leaked returns the session IDs of tabs still alive longer than grace after disposal.
+// Collected tabs are pruned from the watch list.
+func (a *lifecycleAudit) Leaked(grace time.Duration) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var leaked []string
+	alive := a.disposed[:0]
+	for _, d := range a.disposed {
+		if d.ref.Value() == nil {
+			continue
+		}
+		alive = append(alive, d)
+		if time.Since(d.disposedAt) >= grace {
+			leaked = append(leaked, d.sessionID)
+		}
+	}
+	a.disposed = alive
+	return leaked
+}
+
+// Pending returns the number of disposed tabs not yet collected.
+func (a *lifecycleAudit) Pending() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.disposed)
+}
+
+// Report logs tabs that outlived the grace period along with live session counts.
+func (a *lifecycleAudit) Report(grace time.Duration, liveTabs int) {
+	leaked := a.Leaked(grace)
+	if len(leaked) == 0 {
+		a.logger.Debug("Presentation audit clean", "live_tabs", liveTabs, "pending_disposed", a.Pending())
+		return
+	}
+	a.logger.Warn("Presentation audit found leaked session tabs",
+		"leaked", leaked, "live_tabs", liveTabs, "pending_disposed", a.Pending())
+}
@@ -0,0 +1,210 @@
+package presentation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/domain/account"
+	"wardenly-go/infrastructure/browser"
+)
+
+// LoginCalibrationDialogConfig holds configuration for the login calibration dialog.
+type LoginCalibrationDialogConfig struct {
+	// Account selects the server whose login page is opened, and its proxy
+	Account *account.Account
+	// Current is the login profile in use
+	Current browser.LoginProfile
+	// Calibrate runs the calibration browser until all fields are clicked
+	Calibrate func(ctx context.Context, onStep func(browser.LoginField)) (browser.LoginProfile, error)
+	// SessionID is the session whose game frame is measured (optional)
+	SessionID string
+	// DetectFrameOrigin measures where a session's game frame starts
+	DetectFrameOrigin func(ctx context.Context, sessionID string) (browser.Point, error)
+	// SetFrameOrigin saves the frame origin; nil turns translation off
+	SetFrameOrigin func(origin *browser.Point) (browser.LoginProfile, error)
+	Logger         *slog.Logger
+}
+
+// frameDetectTimeout bounds measuring the game frame of a session.
+const frameDetectTimeout = 10 * time.Second
+
+// loginCalibrationDialog walks the user through clicking the login form elements.
+type loginCalibrationDialog struct {
+	config *LoginCalibrationDialogConfig
+	window fyne.Window
+
+	stepLabel *widget.Label
+	form      *widget.Form
+	fields    map[browser.LoginField]*widget.Label
+	startBtn  *widget.Button
+	cancel    context.CancelFunc
+
+	frameLabel *widget.Label
+	detectBtn  *widget.Button
+}
+
+// ShowLoginCalibrationDialog opens a window that records new login page
+// selectors after a portal redesign.
+func ShowLoginCalibrationDialog(cfg *LoginCalibrationDialogConfig) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	d := &loginCalibrationDialog{
+		config: cfg,
+		fields: make(map[browser.LoginField]*widget.Label),
+	}
+
+	d.window = fyne.CurrentApp().NewWindow("Calibrate Login")
+	d.buildUI()
+	d.window.SetOnClosed(func() {
+		if d.cancel != nil {
+			d.cancel()
+		}
+	})
+
+	d.window.Resize(fyne.NewSize(520, 400))
+	d.window.CenterOnScreen()
+	d.window.Show()
+}
+
+func (d *loginCalibrationDialog) buildUI() {
+	hint := widget.NewLabel(fmt.Sprintf(
+		"Opens the login page of server %d in a browser window. Click the username field, "+
+			"the password field, and the login button in turn; the form is not submitted.",
+		d.config.Account.ServerID))
+	hint.Wrapping = fyne.TextWrapWord
+
+	d.stepLabel = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+
+	d.form = widget.NewForm()
+	for _, item := range []struct {
+		field browser.LoginField
+		name  string
+	}{
+		{browser.LoginFieldUsername, "Username"},
+		{browser.LoginFieldPassword, "Password"},
+		{browser.LoginFieldSubmit, "Login Button"},
+	} {
+		label := widget.NewLabel("")
+		label.Wrapping = fyne.TextWrapBreak
+		d.fields[item.field] = label
+		d.form.Append(item.name, label)
+	}
+
+	d.frameLabel = widget.NewLabel("")
+	d.detectBtn = widget.NewButton("Detect", d.detectFrame)
+	if d.config.SessionID == "" || d.config.DetectFrameOrigin == nil {
+		d.detectBtn.Disable()
+	}
+	clearBtn := widget.NewButton("Clear", d.clearFrame)
+	if d.config.SetFrameOrigin == nil {
+		clearBtn.Disable()
+	}
+	d.form.Append("Game Frame", container.NewBorder(nil, nil, nil,
+		container.NewHBox(d.detectBtn, clearBtn), d.frameLabel))
+	d.showProfile(d.config.Current)
+
+	closeBtn := widget.NewButton("Close", d.window.Close)
+	d.startBtn = widget.NewButtonWithIcon("Start", theme.MediaPlayIcon(), d.start)
+	d.startBtn.Importance = widget.HighImportance
+
+	top := container.NewVBox(hint, d.stepLabel, widget.NewSeparator())
+	bottom := container.NewVBox(widget.NewSeparator(), container.NewHBox(layout.NewSpacer(), closeBtn, d.startBtn))
+	d.window.SetContent(container.NewPadded(container.NewBorder(top, bottom, nil, nil, container.NewVScroll(d.form))))
+}
+
+// showProfile fills the field labels from a login profile.
+func (d *loginCalibrationDialog) showProfile(p browser.LoginProfile) {
+	d.fields[browser.LoginFieldUsername].SetText(p.Username)
+	d.fields[browser.LoginFieldPassword].SetText(p.Password)
+	d.fields[browser.LoginFieldSubmit].SetText(p.Submit)
+	if p.FrameOrigin == nil {
+		d.frameLabel.SetText("Not calibrated")
+	} else {
+		d.frameLabel.SetText(fmt.Sprintf("%s at (%.0f, %.0f)", p.Frame, p.FrameOrigin.X, p.FrameOrigin.Y))
+	}
+}
+
+// detectFrame records where the game frame of the current session starts,
+// so scenes and scripts keep working when the portal around it changes.
+func (d *loginCalibrationDialog) detectFrame() {
+	d.detectBtn.Disable()
+	d.stepLabel.SetText("Detecting game frame...")
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), frameDetectTimeout)
+		defer cancel()
+
+		origin, err := d.config.DetectFrameOrigin(ctx, d.config.SessionID)
+		var profile browser.LoginProfile
+		if err == nil {
+			profile, err = d.config.SetFrameOrigin(&origin)
+		}
+
+		fyne.Do(func() {
+			d.detectBtn.Enable()
+			if err != nil {
+				d.config.Logger.Warn("Game frame detection failed", "error", err)
+				d.stepLabel.SetText("Detection failed")
+				dialog.ShowError(err, d.window)
+				return
+			}
+			d.showProfile(profile)
+			d.stepLabel.SetText("Saved. New sessions follow the game frame.")
+		})
+	}()
+}
+
+// clearFrame turns game frame translation off.
+func (d *loginCalibrationDialog) clearFrame() {
+	profile, err := d.config.SetFrameOrigin(nil)
+	if err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+	d.showProfile(profile)
+	d.stepLabel.SetText("Saved. New sessions use viewport coordinates.")
+}
+
+// start runs the calibration browser off the UI thread.
+func (d *loginCalibrationDialog) start() {
+	d.startBtn.Disable()
+	d.stepLabel.SetText("Opening login page...")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	go func() {
+		defer cancel()
+		profile, err := d.config.Calibrate(ctx, func(field browser.LoginField) {
+			fyne.Do(func() {
+				d.stepLabel.SetText(fmt.Sprintf("Click the %s in the browser window", field))
+			})
+		})
+
+		fyne.Do(func() {
+			d.startBtn.Enable()
+			if err != nil {
+				if ctx.Err() != nil {
+					return // window closed
+				}
+				d.config.Logger.Warn("Login calibration failed", "error", err)
+				d.stepLabel.SetText("Calibration failed")
+				dialog.ShowError(err, d.window)
+				return
+			}
+			d.showProfile(profile)
+			d.stepLabel.SetText("Saved. New sessions log in with these selectors.")
+		})
+	}()
+}
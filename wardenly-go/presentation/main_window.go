@@ -2,16 +2,27 @@ package presentation
 
 import (
 	"context"
+	"fmt"
 	"image"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
-	"wardenly-go/core/command"
+	"wardenly-go/application"
+	"wardenly-go/application/session"
 	"wardenly-go/core/event"
 	"wardenly-go/core/state"
 	"wardenly-go/domain/account"
 	"wardenly-go/domain/group"
+	"wardenly-go/domain/schedule"
+	"wardenly-go/domain/script"
+	"wardenly-go/infrastructure/browser"
+	"wardenly-go/infrastructure/config"
+	"wardenly-go/infrastructure/notify"
+	"wardenly-go/infrastructure/stats"
+	"wardenly-go/infrastructure/trace"
+	"wardenly-go/infrastructure/update"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -28,6 +39,7 @@ type MainWindow struct {
 	screencastManager *ScreencastManager
 	bridge            *UIEventBridge
 	logger            *slog.Logger
+	preferences       fyne.Preferences
 
 	// UI components - Sidebar layout
 	sessionList *SessionList
@@ -35,28 +47,69 @@ type MainWindow struct {
 	emptyDetail fyne.CanvasObject
 
 	// UI components - Toolbar
-	accountSelect *widget.Select
-	groupSelect   *widget.Select
-	runAccountBtn *widget.Button
-	runGroupBtn   *widget.Button
-	manageBtn     *widget.Button
-	spreadToAllCb *widget.Check
-	autoRefreshCb *widget.Check
+	accountSelect  *widget.Select
+	groupSelect    *widget.Select
+	runAccountBtn  *widget.Button
+	runGroupBtn    *widget.Button
+	manageBtn      *widget.Button
+	versionsBtn    *widget.Button
+	journalBtn     *widget.Button
+	tracesBtn      *widget.Button
+	alertsBtn      *widget.Button
+	loginBtn       *widget.Button
+	updatesBtn     *widget.Button
+	preferencesBtn *widget.Button
+	spreadToAllCb  *widget.Check
+	autoRefreshCb  *widget.Check
+	stopWhenDoneCb *widget.Check
+	highContrastCb *widget.Check
+	headfulCb      *widget.Check
+	canvasSelect   *widget.Select
 
 	// Data
 	accounts         []*account.Account
 	groups           []*group.Group
 	scriptNames      []string
+	scriptRegistry   *script.Registry
+	scriptVersions   *script.VersionService
+	journalDir       string
+	screenshotDir    string
+	traceStore       trace.Store
+	statsStore       stats.Store
+	notifications    *notify.Center
+	forwarder        *notify.Forwarder
+	desktopNotify    bool
+	updater          *update.Updater
+	configPath       string
+	configReport     *config.Report
 	sessionMap       map[string]*SessionTab
 	sessionMapMu     sync.RWMutex
 	currentSessionID string
 
+	// Group runs: script to start once each session is ready
+	autoScripts   map[string]string
+	autoScriptsMu sync.Mutex
+
+	// Sessions whose running script was already reported as throttled
+	// (UI thread only)
+	throttleAlerted map[string]bool
+
+	// Script refusals not yet shown, by the correlation ID of the command
+	// that caused them (UI thread only)
+	pendingRefusals map[string][]error
+
 	// Cleanup
 	cleanupOnce sync.Once
+	audit       *lifecycleAudit
+	auditStop   chan struct{}
 
 	// Services
-	accountService *account.Service
-	groupService   *group.Service
+	accountService  *account.Service
+	groupService    *group.Service
+	templateService *group.TemplateService
+	scheduleService *schedule.Service
+	scheduler       *application.Scheduler
+	accountSync     *application.AccountSync
 }
 
 // MainWindowConfig holds configuration for MainWindow.
@@ -67,6 +120,35 @@ type MainWindowConfig struct {
 	AccountService *account.Service
 	GroupService   *group.Service
 	ScriptNames    []string
+	ScriptRegistry *script.Registry
+	ScriptVersions *script.VersionService // Optional; enables the Versions dialog
+	Updater        *update.Updater        // Optional; enables update checks
+	JournalDir     string                 // Optional; enables the event journal viewer
+	ScreenshotDir  string                 // Saved screenshots shown in the journal viewer
+	TraceStore     trace.Store            // Optional; enables the script trace viewer
+	StatsStore     stats.Store            // Optional; enables the Statistics management tab
+	Notifications  *notify.Center         // Optional; enables the notification center
+	Forwarder      *notify.Forwarder      // Optional; sends important notifications to webhooks
+	ConfigPath     string                 // Optional; enables the Preferences window
+	ConfigReport   *config.Report         // Optional; shown once the window opens
+	Demo           bool                   // Optional; marks the window as running demo data
+	// ScheduleService and Scheduler enable the Schedules management tab (optional)
+	ScheduleService *schedule.Service
+	Scheduler       *application.Scheduler
+	// TemplateService enables the Templates management tab (optional)
+	TemplateService *group.TemplateService
+	// AccountSync enables spreadsheet sync in the Accounts tab (optional)
+	AccountSync *application.AccountSync
+	// ScreencastQuality, ScreencastMaxFPS and ScreencastStartDelay tune
+	// Auto Refresh streaming (defaults if zero)
+	ScreencastQuality    int
+	ScreencastMaxFPS     int
+	ScreencastStartDelay time.Duration
+	// ScreencastIdlePause pauses the watched session's stream while its
+	// script runs this long untouched (disabled if zero)
+	ScreencastIdlePause time.Duration
+	// DesktopNotifications shows important notifications on the desktop
+	DesktopNotifications bool
 }
 
 // NewMainWindow creates a new main window.
@@ -75,13 +157,40 @@ func NewMainWindow(cfg *MainWindowConfig) *MainWindow {
 		cfg.Logger = slog.Default()
 	}
 
+	title := "Wardenly"
+	if cfg.Demo {
+		title += " (Demo)"
+	}
+
 	w := &MainWindow{
-		window:         cfg.App.NewWindow("Wardenly"),
-		bridge:         cfg.Bridge,
-		logger:         cfg.Logger,
-		sessionMap:     make(map[string]*SessionTab),
-		accountService: cfg.AccountService,
-		groupService:   cfg.GroupService,
+		window:          cfg.App.NewWindow(title),
+		bridge:          cfg.Bridge,
+		logger:          cfg.Logger,
+		preferences:     cfg.App.Preferences(),
+		sessionMap:      make(map[string]*SessionTab),
+		autoScripts:     make(map[string]string),
+		throttleAlerted: make(map[string]bool),
+		pendingRefusals: make(map[string][]error),
+		accountService:  cfg.AccountService,
+		groupService:    cfg.GroupService,
+		templateService: cfg.TemplateService,
+		scheduleService: cfg.ScheduleService,
+		scheduler:       cfg.Scheduler,
+		accountSync:     cfg.AccountSync,
+		scriptRegistry:  cfg.ScriptRegistry,
+		scriptVersions:  cfg.ScriptVersions,
+		journalDir:      cfg.JournalDir,
+		screenshotDir:   cfg.ScreenshotDir,
+		traceStore:      cfg.TraceStore,
+		statsStore:      cfg.StatsStore,
+		notifications:   cfg.Notifications,
+		forwarder:       cfg.Forwarder,
+		desktopNotify:   cfg.DesktopNotifications,
+		updater:         cfg.Updater,
+		configPath:      cfg.ConfigPath,
+		configReport:    cfg.ConfigReport,
+		audit:           newLifecycleAudit(cfg.Logger),
+		auditStop:       make(chan struct{}),
 	}
 
 	// Create CanvasManager (manages CanvasWindow lifecycle and callbacks)
@@ -89,12 +198,20 @@ func NewMainWindow(cfg *MainWindowConfig) *MainWindow {
 		App:    cfg.App,
 		Bridge: cfg.Bridge,
 		Logger: cfg.Logger,
+		OnInteraction: func(sessionID string) {
+			w.screencastManager.OnUserInteraction(sessionID)
+		},
 	})
 
 	// Create ScreencastManager (manages screencast lifecycle)
 	w.screencastManager = NewScreencastManager(&ScreencastManagerConfig{
-		Bridge: cfg.Bridge,
-		Logger: cfg.Logger,
+		Bridge:     cfg.Bridge,
+		Logger:     cfg.Logger,
+		Quality:    cfg.ScreencastQuality,
+		MaxFPS:     cfg.ScreencastMaxFPS,
+		StartDelay: cfg.ScreencastStartDelay,
+		IdlePause:  cfg.ScreencastIdlePause,
+		OnPause:    w.onScreencastPaused,
 	})
 
 	w.init(cfg.ScriptNames)
@@ -107,9 +224,35 @@ func NewMainWindow(cfg *MainWindowConfig) *MainWindow {
 		cfg.App.Quit()
 	})
 
+	if auditEnabled {
+		go w.runAudit()
+	}
+
+	if w.updater != nil && w.updater.Enabled() {
+		go w.checkForUpdates(false)
+	}
+
 	return w
 }
 
+// runAudit periodically reports session tabs that outlived their disposal.
+func (w *MainWindow) runAudit() {
+	ticker := time.NewTicker(auditInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.auditStop:
+			return
+		case <-ticker.C:
+			w.sessionMapMu.RLock()
+			liveTabs := len(w.sessionMap)
+			w.sessionMapMu.RUnlock()
+			w.audit.Report(auditGrace, liveTabs)
+		}
+	}
+}
+
 func (w *MainWindow) init(scriptNames []string) {
 	w.scriptNames = scriptNames
 
@@ -117,6 +260,7 @@ func (w *MainWindow) init(scriptNames []string) {
 
 	// Left sidebar - session list
 	w.sessionList = NewSessionList(w.onSessionSelected)
+	w.sessionList.SetHighContrast(w.highContrastCb.Checked)
 	listWithTitle := container.NewBorder(
 		widget.NewLabel("Sessions"),
 		nil, nil, nil,
@@ -144,11 +288,15 @@ func (w *MainWindow) setupEventCallbacks() {
 	w.bridge.SetCallbacks(&UICallbacks{
 		OnSessionStarted: func(sessionID, accountName string) {
 			w.logger.Info("Session started", "session_id", sessionID, "account", accountName)
+			w.adoptSession(sessionID)
 		},
 		OnSessionStopped: func(sessionID string, err error) {
 			w.logger.Info("Session stopped", "session_id", sessionID, "error", err)
 			// UI update must run on main thread
 			fyne.Do(func() {
+				if err != nil {
+					w.notify(notify.KindBrowserCrashed, sessionID, "Session Stopped", err.Error())
+				}
 				w.removeSession(sessionID)
 			})
 		},
@@ -164,16 +312,77 @@ func (w *MainWindow) setupEventCallbacks() {
 				w.onSessionBecameReady(sessionID)
 			}
 		},
+		OnSessionQueued: func(sessionID string, position int) {
+			w.logger.Debug("Session queued for login", "session_id", sessionID, "position", position)
+			fyne.Do(func() {
+				w.sessionList.SetSessionQueued(sessionID, position)
+			})
+		},
+		OnSessionReconnecting: func(sessionID string, attempt int, delay time.Duration, err error) {
+			w.logger.Warn("Session reconnecting", "session_id", sessionID, "attempt", attempt, "delay", delay, "error", err)
+			// Notify once per crash; later attempts only update the status
+			if attempt != 1 {
+				return
+			}
+			fyne.Do(func() {
+				w.notify(notify.KindBrowserCrashed, sessionID, "Browser Crashed",
+					fmt.Sprintf("%v; restarting the browser in %s", err, delay.Round(time.Second)))
+			})
+		},
+		OnSessionReconnected: func(sessionID string, attempts int) {
+			w.logger.Info("Session reconnected", "session_id", sessionID, "attempts", attempts)
+			fyne.Do(func() {
+				w.sessionList.SetSessionError(sessionID, nil)
+				w.notify(notify.KindBrowserCrashed, sessionID, "Browser Restarted",
+					fmt.Sprintf("Browser restarted after %d attempt(s)", attempts))
+			})
+		},
+		OnLogRecorded: func(sessionID string, t time.Time, level slog.Level, text string) {
+			// UI update must run on main thread
+			fyne.Do(func() {
+				w.sessionMapMu.RLock()
+				tab, exists := w.sessionMap[sessionID]
+				w.sessionMapMu.RUnlock()
+				if exists {
+					tab.AppendLog(t, level, text)
+				}
+			})
+		},
+		OnSessionTuned: func(sessionID string, tuning session.Tuning) {
+			fyne.Do(func() {
+				w.sessionMapMu.RLock()
+				tab, exists := w.sessionMap[sessionID]
+				w.sessionMapMu.RUnlock()
+				if exists {
+					tab.SetTuning(tuning)
+				}
+			})
+		},
+		OnCountersUpdated: func(sessionID, scriptName string, counters map[string]int) {
+			fyne.Do(func() {
+				w.sessionMapMu.RLock()
+				tab, exists := w.sessionMap[sessionID]
+				w.sessionMapMu.RUnlock()
+				if exists {
+					tab.SetCounters(counters)
+				}
+			})
+		},
 		OnScreenCaptured: func(sessionID string, img image.Image) {
 			// Delegate to CanvasManager (handles active session check and UI update)
 			if img != nil {
 				w.canvasManager.HandleScreenCaptured(sessionID, img)
 			}
 		},
+		OnActionPerformed: func(sessionID, action string, fromX, fromY, toX, toY float64) {
+			// Delegate to CanvasManager (only the active session's cursor is shown)
+			w.canvasManager.HandleActionPerformed(sessionID, action, fromX, fromY, toX, toY)
+		},
 		OnLoginSucceeded: func(sessionID string) {
 			w.logger.Info("Login succeeded", "session_id", sessionID)
 			// UI update must run on main thread
 			fyne.Do(func() {
+				w.sessionList.SetSessionError(sessionID, nil)
 				w.enableSessionControls(sessionID)
 			})
 		},
@@ -181,20 +390,158 @@ func (w *MainWindow) setupEventCallbacks() {
 			w.logger.Error("Login failed", "session_id", sessionID, "error", err)
 			// UI update must run on main thread
 			fyne.Do(func() {
+				w.sessionList.SetSessionError(sessionID, err)
+				w.notify(notify.KindLoginFailed, sessionID, "Login Failed", err.Error())
 				dialog.ShowError(err, w.window)
 				w.enableSessionControls(sessionID) // Enable controls even on failure
 			})
 		},
+		OnLoginRetrying: func(sessionID string, attempt int, delay time.Duration, password bool, err error) {
+			w.logger.Warn("Login retrying", "session_id", sessionID, "attempt", attempt, "delay", delay, "password", password, "error", err)
+			// Flag the session until the retry succeeds or fails for good
+			fyne.Do(func() {
+				w.sessionList.SetSessionError(sessionID, fmt.Errorf("login retry %d in %s: %w", attempt, delay.Round(time.Second), err))
+			})
+		},
+		OnLatencyUpdated: func(sessionID string, mean, p95 time.Duration, lagging bool) {
+			// UI update must run on main thread
+			fyne.Do(func() {
+				w.sessionMapMu.RLock()
+				tab, exists := w.sessionMap[sessionID]
+				w.sessionMapMu.RUnlock()
+				if exists {
+					tab.SetLatency(mean, p95, lagging)
+				}
+			})
+		},
+		OnJSEvaluated: func(sessionID string, result []byte, err error) {
+			fyne.Do(func() {
+				w.sessionMapMu.RLock()
+				tab, exists := w.sessionMap[sessionID]
+				w.sessionMapMu.RUnlock()
+				if exists {
+					tab.SetEvaluation(result, err)
+				}
+			})
+		},
+		OnInputLagChanged: func(sessionID string, lagging bool) {
+			// UI update must run on main thread
+			fyne.Do(func() {
+				w.sessionList.SetSessionLagging(sessionID, lagging)
+			})
+		},
 		OnScriptStarted: func(sessionID, scriptName string) {
 			// UI update must run on main thread
 			fyne.Do(func() {
+				delete(w.throttleAlerted, sessionID)
 				w.updateScriptState(sessionID, true)
+				w.screencastManager.OnScriptStarted(sessionID)
 			})
 		},
 		OnScriptStopped: func(sessionID, scriptName string, reason event.StopReason, err error) {
 			// UI update must run on main thread
 			fyne.Do(func() {
 				w.updateScriptState(sessionID, false)
+				w.screencastManager.OnScriptStopped(sessionID)
+				if reason == event.StopReasonError || reason == event.StopReasonStuck {
+					w.sessionList.SetSessionError(sessionID, err)
+				}
+				// Stuck stops are already recorded by OnScriptStuck
+				switch reason {
+				case event.StopReasonError:
+					message := scriptName + " stopped on an error"
+					if err != nil {
+						message += ": " + err.Error()
+					}
+					w.notify(notify.KindScriptStopped, sessionID, "Script Stopped", message)
+				case event.StopReasonResourceExhausted:
+					w.notify(notify.KindScriptStopped, sessionID, "Script Stopped", scriptName+" stopped: resources exhausted")
+				}
+			})
+		},
+		OnScriptRefused: func(sessionID, scriptName, correlationID string, reason error) {
+			w.logger.Warn("Script refused", "session_id", sessionID, "script", scriptName, "reason", reason)
+			// UI update must run on main thread
+			fyne.Do(func() {
+				w.queueScriptRefused(correlationID, reason)
+			})
+		},
+		OnSetupFinished: func(sessionID, scriptName string, completed bool, err error) {
+			// UI update must run on main thread
+			fyne.Do(func() {
+				if completed {
+					// The cached account must not start the setup again
+					w.loadAccounts()
+					return
+				}
+				w.notify(notify.KindSetupFailed, sessionID, "Setup Failed",
+					fmt.Sprintf("First-login setup %s: %v", scriptName, err))
+			})
+		},
+		OnScriptHalted: func(sessionID, scriptName, step, action string, screen image.Image) {
+			// Show the screen the pending action will act on
+			if screen != nil {
+				w.canvasManager.HandleScreenCaptured(sessionID, screen)
+			}
+			fyne.Do(func() {
+				w.sessionMapMu.RLock()
+				tab, exists := w.sessionMap[sessionID]
+				w.sessionMapMu.RUnlock()
+				if exists {
+					tab.SetHalted(scriptName, step, action)
+				}
+			})
+		},
+		OnScriptStuck: func(sessionID, scriptName string, idle time.Duration, action string) {
+			w.logger.Warn("Script stuck", "session_id", sessionID, "script", scriptName, "idle", idle, "action", action)
+			// UI update must run on main thread
+			fyne.Do(func() {
+				w.showScriptStuck(sessionID, scriptName, fmt.Sprintf("matched no scene for %s", idle), action)
+			})
+		},
+		OnScreenFrozen: func(sessionID, scriptName string, frozen time.Duration, action string) {
+			w.logger.Warn("Screen frozen", "session_id", sessionID, "script", scriptName, "frozen", frozen, "action", action)
+			// UI update must run on main thread
+			fyne.Do(func() {
+				w.showScriptStuck(sessionID, scriptName, fmt.Sprintf("showed an unchanged screen for %s", frozen), action)
+			})
+		},
+		OnScriptThrottled: func(sessionID, scriptName, action string, limit int) {
+			// UI update must run on main thread
+			fyne.Do(func() {
+				w.showScriptThrottled(sessionID, scriptName, action, limit)
+			})
+		},
+		OnScriptsReloaded: func(names []string, err error) {
+			// UI update must run on main thread
+			fyne.Do(func() {
+				w.scriptNames = names
+				w.sessionMapMu.RLock()
+				for _, tab := range w.sessionMap {
+					tab.SetScriptNames(names)
+				}
+				w.sessionMapMu.RUnlock()
+				if err != nil {
+					dialog.ShowError(err, w.window)
+				}
+			})
+		},
+		OnSceneThresholdSuggested: func(sceneName string, threshold, suggested float64, count, sessions int, applied bool) {
+			// UI update must run on main thread
+			fyne.Do(func() {
+				w.showSceneThresholdSuggested(sceneName, threshold, suggested, count, sessions, applied)
+			})
+		},
+		OnSceneRegressed: func(sceneName string, frames, regressions int, points []string) {
+			// UI update must run on main thread
+			fyne.Do(func() {
+				w.showSceneRegressed(sceneName, frames, regressions, points)
+			})
+		},
+		OnAccountsSynced: func(added, updated, skipped int, err error) {
+			// UI update must run on main thread
+			fyne.Do(func() {
+				w.showAccountsSynced(added, updated, skipped, err)
 			})
 		},
 		OnScreencastStarted: func(sessionID string, quality, maxFPS int) {
@@ -207,6 +554,10 @@ func (w *MainWindow) setupEventCallbacks() {
 			// Delegate to ScreencastManager (must run on UI thread)
 			fyne.Do(func() {
 				w.screencastManager.OnScreencastStopped(sessionID)
+				// Frames sent before the stop may have cleared the notice
+				if w.screencastManager.IsPaused() {
+					w.onScreencastPaused(sessionID, true)
+				}
 			})
 		},
 		OnDriverStarted: func(sessionID string) {
@@ -218,6 +569,12 @@ func (w *MainWindow) setupEventCallbacks() {
 	})
 }
 
+// Canvas choices for new sessions: a preset size, or each account's own viewport.
+const (
+	prefCanvasSize = "canvas_size"
+	canvasDefault  = "Default"
+)
+
 func (w *MainWindow) createToolbar() fyne.CanvasObject {
 	// Account selection with icon button
 	w.accountSelect = widget.NewSelect([]string{}, func(s string) {})
@@ -231,15 +588,68 @@ func (w *MainWindow) createToolbar() fyne.CanvasObject {
 
 	// Management button with icon
 	w.manageBtn = widget.NewButtonWithIcon("Manage...", theme.SettingsIcon(), w.showManagementDialog)
+	w.versionsBtn = widget.NewButtonWithIcon("Versions...", theme.HistoryIcon(), w.showScriptVersionsDialog)
+	if w.scriptVersions == nil {
+		w.versionsBtn.Disable()
+	}
+	w.journalBtn = widget.NewButtonWithIcon("Journal...", theme.ListIcon(), w.showJournalDialog)
+	if w.journalDir == "" {
+		w.journalBtn.Disable()
+	}
+	w.tracesBtn = widget.NewButtonWithIcon("Traces...", theme.SearchIcon(), w.showTraceDialog)
+	if w.traceStore == nil {
+		w.tracesBtn.Disable()
+	}
+	w.alertsBtn = widget.NewButtonWithIcon("Alerts", theme.WarningIcon(), w.showNotificationDialog)
+	if w.notifications == nil {
+		w.alertsBtn.Disable()
+	} else {
+		// Changes come from bridge callbacks and the dialog, both on the UI thread
+		w.notifications.SetOnChange(w.updateAlertsBadge)
+		w.updateAlertsBadge()
+	}
+	w.loginBtn = widget.NewButtonWithIcon("Login...", theme.LoginIcon(), w.showLoginCalibrationDialog)
+	w.updatesBtn = widget.NewButtonWithIcon("Updates...", theme.DownloadIcon(), func() {
+		go w.checkForUpdates(true)
+	})
+	if w.updater == nil || !w.updater.Enabled() {
+		w.updatesBtn.Disable()
+	}
+	w.preferencesBtn = widget.NewButtonWithIcon("Preferences...", theme.ComputerIcon(), w.showPreferencesDialog)
+	if w.configPath == "" {
+		w.preferencesBtn.Disable()
+	}
 
 	// Options
 	w.spreadToAllCb = widget.NewCheck("Spread to All", func(b bool) {})
-	w.autoRefreshCb = widget.NewCheck("Auto Refresh (1s)", func(checked bool) {
+	w.autoRefreshCb = widget.NewCheck(autoRefreshLabel(w.screencastManager.StartDelay()), func(checked bool) {
 		w.screencastManager.SetAutoRefreshEnabled(checked)
 	})
+	w.headfulCb = widget.NewCheck("Show Browser", func(bool) {})
+	w.stopWhenDoneCb = widget.NewCheck("Stop When Done", func(checked bool) {
+		if err := w.bridge.SetStopOnScriptFinish(checked); err != nil {
+			w.logger.Error("Failed to set stop on script finish", "error", err)
+		}
+	})
+	w.highContrastCb = widget.NewCheck("High Contrast Status", func(checked bool) {
+		w.preferences.SetBool(prefHighContrastStatus, checked)
+		w.sessionList.SetHighContrast(checked)
+	})
+	// Set directly so the handler doesn't run before the session list exists
+	w.highContrastCb.Checked = w.preferences.Bool(prefHighContrastStatus)
+
+	// Canvas size sessions are started with; scenes and scripts are scaled to it
+	canvasOptions := []string{canvasDefault}
+	for _, size := range browser.CanvasPresets {
+		canvasOptions = append(canvasOptions, size.String())
+	}
+	w.canvasSelect = widget.NewSelect(canvasOptions, func(s string) {
+		w.preferences.SetString(prefCanvasSize, s)
+	})
+	w.canvasSelect.Selected = w.preferences.StringWithFallback(prefCanvasSize, canvasDefault)
 
 	// Layout: Single toolbar row with logical grouping
-	// [Account ▼] [▶ Run] | [Group ▼] [▶▶ Run] | spacer | [⚙ Manage...]
+	// [Account ▼] [▶ Run] | [Group ▼] [▶▶ Run] | spacer | [Updates...] [Login...] [Versions...] [Journal...] [Traces...] [Alerts (n)] [Preferences...] [⚙ Manage...]
 	toolbarRow := container.NewHBox(
 		w.accountSelect,
 		w.runAccountBtn,
@@ -247,6 +657,13 @@ func (w *MainWindow) createToolbar() fyne.CanvasObject {
 		w.groupSelect,
 		w.runGroupBtn,
 		layout.NewSpacer(),
+		w.updatesBtn,
+		w.loginBtn,
+		w.versionsBtn,
+		w.journalBtn,
+		w.tracesBtn,
+		w.alertsBtn,
+		w.preferencesBtn,
 		w.manageBtn,
 	)
 
@@ -254,6 +671,11 @@ func (w *MainWindow) createToolbar() fyne.CanvasObject {
 	optionsRow := container.NewHBox(
 		w.spreadToAllCb,
 		w.autoRefreshCb,
+		w.headfulCb,
+		w.stopWhenDoneCb,
+		w.highContrastCb,
+		widget.NewLabel("Canvas:"),
+		w.canvasSelect,
 	)
 
 	return container.NewVBox(
@@ -276,6 +698,8 @@ func (w *MainWindow) loadAccounts() {
 		return
 	}
 
+	// Archived accounts can't be started, so they are not offered
+	accounts = account.ActiveAccounts(accounts)
 	w.accounts = accounts
 
 	// Update account select
@@ -313,20 +737,21 @@ func (w *MainWindow) loadGroups() {
 	w.groupSelect.Refresh()
 }
 
-func (w *MainWindow) handleRunAccount() {
+// selectedAccount returns the account chosen in the toolbar, or nil.
+func (w *MainWindow) selectedAccount() *account.Account {
 	if w.accountSelect.Selected == "" {
-		return
+		return nil
 	}
-
-	// Find selected account
-	var selectedAcc *account.Account
 	for _, acc := range w.accounts {
 		if acc.Identity() == w.accountSelect.Selected {
-			selectedAcc = acc
-			break
+			return acc
 		}
 	}
+	return nil
+}
 
+func (w *MainWindow) handleRunAccount() {
+	selectedAcc := w.selectedAccount()
 	if selectedAcc == nil {
 		return
 	}
@@ -343,7 +768,7 @@ func (w *MainWindow) handleRunAccount() {
 		return
 	}
 
-	w.runAccount(selectedAcc, true) // Single account run: always select after create
+	w.runAccount(selectedAcc, true, w.selectedLaunch(), nil) // Single account run: always select after create
 }
 
 func (w *MainWindow) handleRunGroup() {
@@ -382,13 +807,28 @@ func (w *MainWindow) handleRunGroup() {
 		return
 	}
 
+	// Check scenes, OCR and credentials before launching anything
+	settings := resolved.Group.Settings
+	go func() {
+		report := w.bridge.Preflight(settings.ScriptName, resolved.Accounts)
+		fyne.Do(func() {
+			ShowPreflightDialog(resolved.Group.Name, report, w.window, func() {
+				w.startGroup(resolved.Accounts, settings)
+			})
+		})
+	}()
+}
+
+// startGroup starts the accounts of a group run serially in the background,
+// staggered by the group's settings.
+func (w *MainWindow) startGroup(accounts []*account.Account, settings group.RunSettings) {
 	// Determine if there is already an active session
 	hadActiveSession := w.currentSessionID != ""
 	firstCreated := false
+	launch := w.selectedLaunch()
 
-	// Start accounts serially in background
 	go func() {
-		for i, acc := range resolved.Accounts {
+		for i, acc := range accounts {
 			// Check if already running
 			w.sessionMapMu.RLock()
 			_, exists := w.sessionMap[acc.ID]
@@ -402,20 +842,91 @@ func (w *MainWindow) handleRunGroup() {
 
 			// Only select if: no active session existed AND this is the first one we create
 			shouldSelect := !hadActiveSession && !firstCreated
-			w.runAccount(acc, shouldSelect)
+			w.runAccount(acc, shouldSelect, launch, &settings)
 			if shouldSelect {
 				firstCreated = true
 			}
 
 			// Wait between accounts
-			if i < len(resolved.Accounts)-1 {
-				time.Sleep(3 * time.Second)
+			if i < len(accounts)-1 {
+				time.Sleep(settings.Interval())
 			}
 		}
 	}()
 }
 
-func (w *MainWindow) runAccount(acc *account.Account, selectAfterCreate bool) {
+// selectedLaunch returns the launch choices of the toolbar: the canvas
+// preset, or a zero size for the accounts' own viewports, and whether to
+// show the browser windows.
+func (w *MainWindow) selectedLaunch() SessionLaunch {
+	launch := SessionLaunch{Headful: w.headfulCb.Checked}
+	if size, err := browser.ParseCanvasSize(w.canvasSelect.Selected); err == nil {
+		launch.Canvas = size
+	}
+	return launch
+}
+
+// runAccount starts a session for acc with the launch choices.
+// settings is set for group runs.
+func (w *MainWindow) runAccount(acc *account.Account, selectAfterCreate bool, launch SessionLaunch, settings *group.RunSettings) {
+	w.addSessionTab(acc, selectAfterCreate, launch.Canvas)
+	if settings != nil && settings.ScriptName != "" {
+		w.autoScriptsMu.Lock()
+		w.autoScripts[acc.ID] = settings.ScriptName
+		w.autoScriptsMu.Unlock()
+	}
+
+	// Start session via bridge
+	go func() {
+		var err error
+		if settings != nil {
+			err = w.bridge.StartGroupSession(acc, launch, settings.StopWhenDone)
+		} else {
+			err = w.bridge.StartSession(acc, launch)
+		}
+		if err != nil {
+			w.logger.Error("Failed to start session", "error", err)
+			dialog.ShowError(err, w.window)
+			w.removeSession(acc.ID)
+		}
+	}()
+}
+
+// adoptSession shows a session started outside the UI (e.g. by the scheduler).
+// Sessions the UI started already have a tab and are ignored.
+// Called from the event goroutine; the account is loaded before touching the UI.
+func (w *MainWindow) adoptSession(sessionID string) {
+	if w.hasSessionTab(sessionID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	acc, err := w.accountService.GetAccount(ctx, sessionID)
+	cancel()
+	if err != nil {
+		w.logger.Error("Failed to load account of external session", "session_id", sessionID, "error", err)
+		return
+	}
+
+	fyne.Do(func() {
+		if w.hasSessionTab(sessionID) {
+			return
+		}
+		w.logger.Info("Adopting session started outside the UI", "session_id", sessionID)
+		w.addSessionTab(acc, w.currentSessionID == "", browser.CanvasSize{})
+	})
+}
+
+func (w *MainWindow) hasSessionTab(sessionID string) bool {
+	w.sessionMapMu.RLock()
+	defer w.sessionMapMu.RUnlock()
+	_, exists := w.sessionMap[sessionID]
+	return exists
+}
+
+// addSessionTab creates the tab and sidebar entry for a session started at
+// the canvas size, or at the account's viewport if it is zero.
+func (w *MainWindow) addSessionTab(acc *account.Account, selectAfterCreate bool, canvas browser.CanvasSize) {
 	// Create session tab (reusing existing component)
 	sessionTab := NewSessionTab(&SessionTabConfig{
 		SessionID:   acc.ID,
@@ -426,6 +937,7 @@ func (w *MainWindow) runAccount(acc *account.Account, selectAfterCreate bool) {
 		OnStop: func(sessionID string) {
 			w.removeSession(sessionID)
 		},
+		OnDetach: w.detachSession,
 		ShouldSpreadToAll: func() bool {
 			return w.spreadToAllCb.Checked
 		},
@@ -435,8 +947,13 @@ func (w *MainWindow) runAccount(acc *account.Account, selectAfterCreate bool) {
 		OnSyncScript: func(scriptName string) {
 			w.syncScriptToAllTabs(scriptName)
 		},
+		OnShowScriptDoc:   w.showScriptDocDialog,
+		OnSceneOverlay:    w.canvasManager.SetSceneOverlay,
 		OnStartAllScripts: w.startAllScripts,
 		OnStopAllScripts:  w.stopAllScripts,
+		PromptScriptParams: func(scriptName string, start func(params map[string]string)) bool {
+			return w.promptScriptParams(acc, scriptName, start)
+		},
 	})
 
 	// Add to session map
@@ -446,42 +963,227 @@ func (w *MainWindow) runAccount(acc *account.Account, selectAfterCreate bool) {
 
 	// Register with CanvasManager (handles cooldown tracking)
 	w.canvasManager.RegisterSession(acc.ID, sessionTab)
+	if canvas.IsZero() && acc.Browser != nil {
+		canvas = browser.CanvasSize{Width: acc.Browser.ViewportWidth, Height: acc.Browser.ViewportHeight}
+	}
+	w.canvasManager.SetSessionSize(acc.ID, canvas)
 
 	// Add to sidebar list
 	w.sessionList.AddSession(acc.ID, acc.Identity())
+	w.applySessionLabel(acc)
 
 	// Optionally select the new session
 	// Note: SelectSession triggers OnSelected callback which calls onSessionSelected
 	if selectAfterCreate {
 		w.sessionList.SelectSession(acc.ID)
 	}
+}
 
-	// Start session via bridge
-	go func() {
-		// Convert cookies to command.Cookie
-		var cmdCookies []command.Cookie
-		if len(acc.Cookies) > 0 {
-			cmdCookies = make([]command.Cookie, len(acc.Cookies))
-			for i, c := range acc.Cookies {
-				cmdCookies[i] = command.Cookie{
-					Name:       c.Name,
-					Value:      c.Value,
-					Domain:     c.Domain,
-					Path:       c.Path,
-					HTTPOnly:   c.HTTPOnly,
-					Secure:     c.Secure,
-					SourcePort: c.SourcePort,
-				}
-			}
+// showScriptStuck tells the user the watchdog found a stuck script, how it
+// was stuck, and what it did about it.
+func (w *MainWindow) showScriptStuck(sessionID, scriptName, stuck, action string) {
+	name := sessionID
+	w.sessionMapMu.RLock()
+	if tab, ok := w.sessionMap[sessionID]; ok {
+		name = tab.AccountName()
+	}
+	w.sessionMapMu.RUnlock()
+
+	var outcome string
+	switch action {
+	case "refresh":
+		outcome = "The page was refreshed."
+	case "script":
+		outcome = "The recovery script was run."
+	default:
+		outcome = "The script was stopped."
+	}
+	message := fmt.Sprintf("%s %s.\n%s", scriptName, stuck, outcome)
+	w.notify(notify.KindScriptStuck, sessionID, "Script Stuck", message)
+	dialog.ShowInformation("Script Stuck", name+": "+message, w.window)
+}
+
+// refusalBatchDelay is how long refusals of one command are collected
+// before they are shown together.
+const refusalBatchDelay = 200 * time.Millisecond
+
+// queueScriptRefused collects the refusals caused by one command, e.g. Start
+// All on sessions whose accounts block the script, and shows them in a
+// single dialog. Must be called on the UI thread.
+func (w *MainWindow) queueScriptRefused(correlationID string, reason error) {
+	if pending, ok := w.pendingRefusals[correlationID]; ok {
+		w.pendingRefusals[correlationID] = append(pending, reason)
+		return
+	}
+	w.pendingRefusals[correlationID] = []error{reason}
+	time.AfterFunc(refusalBatchDelay, func() {
+		fyne.Do(func() {
+			reasons := w.pendingRefusals[correlationID]
+			delete(w.pendingRefusals, correlationID)
+			w.showScriptsRefused(reasons)
+		})
+	})
+}
+
+// showScriptsRefused shows the refusals of one command.
+func (w *MainWindow) showScriptsRefused(reasons []error) {
+	if len(reasons) == 1 {
+		dialog.ShowError(reasons[0], w.window)
+		return
+	}
+	lines := make([]string, len(reasons))
+	for i, reason := range reasons {
+		lines[i] = reason.Error()
+	}
+	message := fmt.Sprintf("%d scripts were not started:\n%s", len(reasons), strings.Join(lines, "\n"))
+	dialog.ShowInformation("Scripts Refused", message, w.window)
+}
+
+// showAccountsSynced reloads the accounts after a scheduled spreadsheet sync
+// and records failures and skipped rows in the notification center.
+func (w *MainWindow) showAccountsSynced(added, updated, skipped int, err error) {
+	var title, message string
+	switch {
+	case err != nil:
+		title, message = "Account Sync Failed", err.Error()
+	case skipped > 0:
+		title = "Account Sync"
+		message = fmt.Sprintf("%d added, %d updated, %d rows skipped because of invalid values.", added, updated, skipped)
+	}
+	if err == nil {
+		w.loadAccounts()
+		w.loadGroups()
+		w.refreshSessionLabels()
+	}
+	if title == "" || w.notifications == nil {
+		return
+	}
+
+	// Not tied to a session; the name labels the group in the alerts list
+	_, saveErr := w.notifications.Add(notify.Notification{
+		Kind:        notify.KindAccountSync,
+		AccountName: "Account Sync",
+		Title:       title,
+		Message:     message,
+	})
+	if saveErr != nil {
+		w.logger.Warn("Failed to save notification", "kind", notify.KindAccountSync, "error", saveErr)
+	}
+}
+
+// showSceneThresholdSuggested records a scene that keeps nearly matching in
+// the notification center, with the threshold that would have matched it.
+func (w *MainWindow) showSceneThresholdSuggested(sceneName string, threshold, suggested float64, count, sessions int, applied bool) {
+	if w.notifications == nil {
+		return
+	}
+	message := fmt.Sprintf("%s nearly matched %d times in %d sessions", sceneName, count, sessions)
+	if applied {
+		message += fmt.Sprintf("; its threshold was raised from %.1f to %.1f until restart.", threshold, suggested)
+	} else {
+		message += fmt.Sprintf(" at threshold %.1f; consider setting \"threshold: %.1f\" in its scene file.", threshold, suggested)
+	}
+
+	// Not tied to a session; the name labels the group in the alerts list
+	_, err := w.notifications.Add(notify.Notification{
+		Kind:        notify.KindSceneTuning,
+		AccountName: "Scene Tuning",
+		Title:       "Scene Threshold",
+		Message:     message,
+	})
+	if err != nil {
+		w.logger.Warn("Failed to save notification", "kind", notify.KindSceneTuning, "error", err)
+	}
+}
+
+// showSceneRegressed records an edited scene that stopped matching recent
+// frames in the notification center, with the points that missed.
+func (w *MainWindow) showSceneRegressed(sceneName string, frames, regressions int, points []string) {
+	if w.notifications == nil {
+		return
+	}
+	message := fmt.Sprintf("The edit of %s no longer matches %d of %d recent frames its previous version matched", sceneName, regressions, frames)
+	if len(points) > 0 {
+		message += ": " + strings.Join(points, ", ")
+	}
+
+	// Not tied to a session; the name labels the group in the alerts list
+	_, err := w.notifications.Add(notify.Notification{
+		Kind:        notify.KindSceneRegressed,
+		AccountName: "Scene Tuning",
+		Title:       "Scene Edit",
+		Message:     message + ".",
+	})
+	if err != nil {
+		w.logger.Warn("Failed to save notification", "kind", notify.KindSceneRegressed, "error", err)
+	}
+}
+
+// showScriptThrottled warns once per run that a script reached its action
+// limit; later pauses of the run are only logged.
+func (w *MainWindow) showScriptThrottled(sessionID, scriptName, action string, limit int) {
+	if w.throttleAlerted[sessionID] {
+		return
+	}
+	w.throttleAlerted[sessionID] = true
+
+	name := sessionID
+	w.sessionMapMu.RLock()
+	if tab, ok := w.sessionMap[sessionID]; ok {
+		name = tab.AccountName()
+	}
+	w.sessionMapMu.RUnlock()
+
+	message := fmt.Sprintf("%s reached its limit of %d %ss per minute and is paused until the rate drops.\n"+
+		"Check the script for a loop that repeats the action.", scriptName, limit, action)
+	w.notify(notify.KindScriptThrottled, sessionID, "Script Throttled", message)
+	dialog.ShowInformation("Script Throttled", name+": "+message, w.window)
+}
+
+// applySessionLabel shows an account's label on its session in the list
+// and the canvas window title.
+func (w *MainWindow) applySessionLabel(acc *account.Account) {
+	w.sessionList.SetSessionLabel(acc.ID, acc.Label, acc.LabelColor)
+	w.canvasManager.SetSessionTitle(acc.ID, acc.DisplayName())
+}
+
+// refreshSessionLabels reapplies account labels to running sessions after
+// accounts were edited.
+func (w *MainWindow) refreshSessionLabels() {
+	w.sessionMapMu.RLock()
+	defer w.sessionMapMu.RUnlock()
+	for _, acc := range w.accounts {
+		if _, ok := w.sessionMap[acc.ID]; ok {
+			w.applySessionLabel(acc)
 		}
+	}
+}
 
-		// Pass RoleName (not Identity) to avoid double-prefixing with ServerID
-		if err := w.bridge.StartSession(acc.ID, acc.RoleName, acc.UserName, acc.Password, acc.ServerID, cmdCookies); err != nil {
-			w.logger.Error("Failed to start session", "error", err)
-			dialog.ShowError(err, w.window)
-			w.removeSession(acc.ID)
+// promptScriptParams shows the prompt dialog for scripts that declare prompts.
+// Submitted values are remembered on the account for the next run.
+func (w *MainWindow) promptScriptParams(acc *account.Account, scriptName string, start func(params map[string]string)) bool {
+	if w.scriptRegistry == nil {
+		return false
+	}
+	s := w.scriptRegistry.Get(scriptName)
+	if s == nil || !s.HasPrompts() {
+		return false
+	}
+
+	ShowScriptParamsDialog(s, acc.RememberedParams(scriptName), w.window, func(params map[string]string) {
+		acc.RememberParams(scriptName, params)
+		start(params)
+
+		if w.accountService == nil {
+			return
 		}
-	}()
+		go func() {
+			if err := w.accountService.SaveScriptParams(context.Background(), acc.ID, scriptName, params); err != nil {
+				w.logger.Error("Failed to save script params", "account", acc.Identity(), "error", err)
+			}
+		}()
+	})
+	return true
 }
 
 // onSessionSelected handles selection of a session from the sidebar list.
@@ -521,9 +1223,14 @@ func (w *MainWindow) removeSession(sessionID string) {
 
 	// Remove from session map
 	w.sessionMapMu.Lock()
+	sessionTab := w.sessionMap[sessionID]
 	delete(w.sessionMap, sessionID)
 	w.sessionMapMu.Unlock()
 
+	w.autoScriptsMu.Lock()
+	delete(w.autoScripts, sessionID)
+	w.autoScriptsMu.Unlock()
+
 	// Unregister from CanvasManager (handles canvas state if this was active)
 	w.canvasManager.UnregisterSession(sessionID)
 
@@ -556,19 +1263,286 @@ func (w *MainWindow) removeSession(sessionID string) {
 			// CanvasManager already handles hiding when last session is unregistered
 		}
 	}
+
+	// Release the tab last, after the detail panel no longer shows it
+	if sessionTab != nil {
+		sessionTab.Dispose()
+		w.audit.TrackDisposed(sessionTab)
+	}
 }
 
 func (w *MainWindow) showManagementDialog() {
-	ShowManagementDialog(&ManagementDialogConfig{
+	cfg := &ManagementDialogConfig{
 		Parent:         w.window,
 		AccountService: w.accountService,
 		GroupService:   w.groupService,
+		ScriptNames:    w.scriptNames,
 		Logger:         w.logger,
+		// Templates create schedules when the Schedules tab is enabled
+		TemplateService: w.templateService,
+		AccountSync:     w.accountSync,
+		StatsStore:      w.statsStore,
 		OnDataChanged: func() {
 			// Reload accounts and groups in main window
 			w.loadAccounts()
 			w.loadGroups()
+			w.refreshSessionLabels()
 		},
+	}
+
+	if w.scheduleService != nil {
+		cfg.ScheduleService = w.scheduleService
+		if w.scheduler != nil {
+			cfg.NextScheduleRun = w.scheduler.NextRun
+			cfg.OnSchedulesChanged = func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				if err := w.scheduler.Reload(ctx); err != nil {
+					w.logger.Error("Failed to reload schedules", "error", err)
+				}
+			}
+		}
+	}
+
+	// The selected session is the reference for guild roster import
+	if sessionID := w.currentSessionID; sessionID != "" {
+		cfg.ReadRoster = func(ctx context.Context) ([]string, error) {
+			return w.bridge.ReadRoster(ctx, sessionID)
+		}
+		for _, acc := range w.accounts {
+			if acc.ID == sessionID {
+				cfg.RosterServerID = acc.ServerID
+				break
+			}
+		}
+	}
+
+	ShowManagementDialog(cfg)
+}
+
+func (w *MainWindow) showScriptVersionsDialog() {
+	if w.scriptVersions == nil {
+		return
+	}
+	ShowScriptVersionsDialog(&ScriptVersionsDialogConfig{
+		Versions:    w.scriptVersions,
+		ScriptNames: w.scriptNames,
+		Logger:      w.logger,
+	})
+}
+
+func (w *MainWindow) showScriptDocDialog(scriptName string) {
+	if w.scriptRegistry == nil {
+		return
+	}
+	ShowScriptDocDialog(&ScriptDocDialogConfig{
+		Registry:    w.scriptRegistry,
+		ScriptNames: w.scriptNames,
+		Selected:    scriptName,
+		Logger:      w.logger,
+	})
+}
+
+func (w *MainWindow) showJournalDialog() {
+	if w.journalDir == "" {
+		return
+	}
+	ShowJournalDialog(&JournalDialogConfig{
+		Dir:           w.journalDir,
+		ScreenshotDir: w.screenshotDir,
+		Logger:        w.logger,
+	})
+}
+
+func (w *MainWindow) showNotificationDialog() {
+	if w.notifications == nil {
+		return
+	}
+	ShowNotificationDialog(&NotificationDialogConfig{
+		Center: w.notifications,
+		Logger: w.logger,
+	})
+}
+
+// notify records an alert in the notification center and, for important
+// kinds, shows it on the desktop and forwards it to webhooks. Must be
+// called on the UI thread.
+func (w *MainWindow) notify(kind, sessionID, title, message string) {
+	name := ""
+	w.sessionMapMu.RLock()
+	if tab, ok := w.sessionMap[sessionID]; ok {
+		name = tab.AccountName()
+	}
+	w.sessionMapMu.RUnlock()
+
+	n := notify.Notification{
+		Kind:        kind,
+		SessionID:   sessionID,
+		AccountName: name,
+		Title:       title,
+		Message:     message,
+	}
+	if w.notifications != nil {
+		var err error
+		if n, err = w.notifications.Add(n); err != nil {
+			w.logger.Warn("Failed to save notification", "kind", kind, "error", err)
+		}
+	}
+
+	if !notify.Important(kind) {
+		return
+	}
+	if w.desktopNotify {
+		text := message
+		if name != "" {
+			text = name + ": " + message
+		}
+		fyne.CurrentApp().SendNotification(fyne.NewNotification(title, text))
+	}
+	w.forwarder.Forward(n)
+}
+
+// updateAlertsBadge shows the unread notification count on the toolbar.
+func (w *MainWindow) updateAlertsBadge() {
+	unread := w.notifications.Unread()
+	if unread == 0 {
+		w.alertsBtn.SetText("Alerts")
+		w.alertsBtn.Importance = widget.MediumImportance
+	} else {
+		w.alertsBtn.SetText(fmt.Sprintf("Alerts (%d)", unread))
+		w.alertsBtn.Importance = widget.DangerImportance
+	}
+	w.alertsBtn.Refresh()
+}
+
+func (w *MainWindow) showTraceDialog() {
+	if w.traceStore == nil {
+		return
+	}
+	ShowTraceDialog(&TraceDialogConfig{
+		Store:  w.traceStore,
+		Logger: w.logger,
+	})
+}
+
+// detachSession restarts a session's browser with its window shown, after
+// confirming, since the game is loaded again and a running script stops.
+func (w *MainWindow) detachSession(sessionID string) {
+	name := sessionID
+	w.sessionMapMu.RLock()
+	if tab, exists := w.sessionMap[sessionID]; exists {
+		name = tab.AccountName()
+	}
+	w.sessionMapMu.RUnlock()
+	dialog.ShowConfirm("Detach to Headful",
+		fmt.Sprintf("Restart the browser of %s with its window shown?\n"+
+			"The game is loaded again with the session's cookies, and a running script is stopped.", name),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			go func() {
+				if err := w.bridge.DetachSession(sessionID); err != nil {
+					w.logger.Error("Failed to detach session", "session_id", sessionID, "error", err)
+					fyne.Do(func() { dialog.ShowError(err, w.window) })
+				}
+			}()
+		}, w.window)
+}
+
+// showPreferencesDialog edits the config file. Screencast settings take
+// effect for the next stream.
+func (w *MainWindow) showPreferencesDialog() {
+	if w.configPath == "" {
+		return
+	}
+	ShowPreferencesDialog(&PreferencesDialogConfig{
+		Path:   w.configPath,
+		Logger: w.logger,
+		OnSaved: func(cfg *config.Config) {
+			// The environment still takes precedence over the file
+			effective := *cfg
+			if err := effective.ApplyEnv(); err != nil {
+				w.logger.Warn("Ignoring invalid environment settings", "error", err)
+			}
+			w.screencastManager.SetStreamSettings(effective.Screencast.Quality, effective.Screencast.MaxFPS, effective.Screencast.Delay())
+			w.screencastManager.SetIdlePause(effective.Screencast.PauseAfter())
+			w.desktopNotify = effective.Notify.DesktopEnabled()
+			w.autoRefreshCb.Text = autoRefreshLabel(w.screencastManager.StartDelay())
+			w.autoRefreshCb.Refresh()
+		},
+	})
+}
+
+// screencastPausedNotice is shown over the last frame of a stream paused
+// while its script runs unattended.
+const screencastPausedNotice = "Paused while the script runs unattended; click or type to resume"
+
+// onScreencastPaused marks the browser view of a stream paused or resumed
+// by the idle policy. Must be called from UI thread.
+func (w *MainWindow) onScreencastPaused(sessionID string, paused bool) {
+	notice := ""
+	if paused {
+		notice = screencastPausedNotice
+	}
+	w.canvasManager.ShowNotice(sessionID, notice)
+}
+
+// autoRefreshLabel names the Auto Refresh option with its start delay.
+func autoRefreshLabel(delay time.Duration) string {
+	return fmt.Sprintf("Auto Refresh (%s)", delay)
+}
+
+// showLoginCalibrationDialog recalibrates the login page selectors on the
+// selected account's server.
+func (w *MainWindow) showLoginCalibrationDialog() {
+	acc := w.selectedAccount()
+	if acc == nil {
+		dialog.ShowInformation("Calibrate Login",
+			"Select an account first; its server's login page is used for calibration.",
+			w.window)
+		return
+	}
+	ShowLoginCalibrationDialog(&LoginCalibrationDialogConfig{
+		Account: acc,
+		Current: w.bridge.LoginProfile(),
+		Calibrate: func(ctx context.Context, onStep func(browser.LoginField)) (browser.LoginProfile, error) {
+			return w.bridge.CalibrateLogin(ctx, acc, onStep)
+		},
+		SessionID:         w.currentSessionID,
+		DetectFrameOrigin: w.bridge.DetectFrameOrigin,
+		SetFrameOrigin:    w.bridge.SetFrameOrigin,
+		Logger:            w.logger,
+	})
+}
+
+// checkForUpdates queries the release feed off the UI thread. The startup
+// check stays silent unless a newer release exists; a manual check also
+// reports errors and the up-to-date case.
+func (w *MainWindow) checkForUpdates(manual bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+
+	rel, err := w.updater.Check(ctx)
+	if err != nil {
+		w.logger.Warn("Update check failed", "error", err)
+	}
+
+	fyne.Do(func() {
+		switch {
+		case rel != nil:
+			ShowUpdateDialog(&UpdateDialogConfig{
+				Updater: w.updater,
+				Release: rel,
+				Logger:  w.logger,
+			})
+		case !manual:
+		case err != nil:
+			dialog.ShowError(err, w.window)
+		default:
+			dialog.ShowInformation("Up to Date",
+				fmt.Sprintf("Wardenly %s is the latest version.", w.updater.CurrentVersion()), w.window)
+		}
 	})
 }
 
@@ -618,6 +1592,9 @@ func (w *MainWindow) updateSessionState(sessionID string, newState state.Session
 	}
 
 	tab.UpdateState(newState)
+
+	// Also update the sidebar list indicator
+	w.sessionList.UpdateSessionState(sessionID, newState)
 }
 
 func (w *MainWindow) updateScriptState(sessionID string, running bool) {
@@ -630,9 +1607,6 @@ func (w *MainWindow) updateScriptState(sessionID string, running bool) {
 	}
 
 	tab.SetScriptRunning(running)
-
-	// Also update the sidebar list indicator
-	w.sessionList.UpdateSessionState(sessionID, running)
 }
 
 func (w *MainWindow) enableSessionControls(sessionID string) {
@@ -648,8 +1622,30 @@ func (w *MainWindow) enableSessionControls(sessionID string) {
 }
 
 // onSessionBecameReady is called when a session transitions from LoggingIn to Ready.
-// This syncs script selection from UI to Session.
+// This syncs script selection from UI to Session, or starts the group's
+// default script for sessions of a group run.
 func (w *MainWindow) onSessionBecameReady(sessionID string) {
+	w.autoScriptsMu.Lock()
+	scriptName, auto := w.autoScripts[sessionID]
+	delete(w.autoScripts, sessionID)
+	w.autoScriptsMu.Unlock()
+
+	if auto {
+		w.sessionMapMu.RLock()
+		tab, exists := w.sessionMap[sessionID]
+		w.sessionMapMu.RUnlock()
+		if exists {
+			fyne.Do(func() { tab.SetScriptSelection(scriptName) })
+		}
+		// Dispatch outside the event goroutine; the session may be busy
+		go func() {
+			if err := w.bridge.StartScript(sessionID, scriptName); err != nil {
+				w.logger.Error("Failed to start group script", "session_id", sessionID, "script", scriptName, "error", err)
+			}
+		}()
+		return
+	}
+
 	// Sync script selection from UI to Session
 	w.sessionMapMu.RLock()
 	tab, exists := w.sessionMap[sessionID]
@@ -667,9 +1663,12 @@ func (w *MainWindow) onSessionBecameReady(sessionID string) {
 
 // Public methods
 
-// Show displays the main window.
+// Show displays the main window, with the config problems found at startup.
 func (w *MainWindow) Show() {
 	w.window.Show()
+	if w.configReport != nil && len(w.configReport.Issues) > 0 {
+		ShowConfigReportDialog(w.configReport, w.window)
+	}
 }
 
 // Cleanup releases resources.
@@ -677,6 +1676,8 @@ func (w *MainWindow) Cleanup() {
 	w.cleanupOnce.Do(func() {
 		w.logger.Info("Starting cleanup...")
 
+		close(w.auditStop)
+
 		// Close ScreencastManager (stops active screencast and pending timers)
 		if w.screencastManager != nil {
 			w.screencastManager.Close()
@@ -1,8 +1,11 @@
 package presentation
 
 import (
+	"errors"
 	"testing"
 
+	"fyne.io/fyne/v2/test"
+
 	"wardenly-go/domain/group"
 )
 
@@ -68,3 +71,16 @@ func TestGroupEntity(t *testing.T) {
 		t.Error("Should not contain acc2 after removal")
 	}
 }
+
+func TestMainWindow_ShowScriptsRefused(t *testing.T) {
+	a := test.NewTempApp(t)
+	w := &MainWindow{window: a.NewWindow("test")}
+
+	w.showScriptsRefused([]error{
+		errors.New("script \"daily\" is blocked for account 1-alice"),
+		errors.New("script \"daily\" is blocked for account 1-bob"),
+	})
+	if overlays := w.window.Canvas().Overlays().List(); len(overlays) != 1 {
+		t.Errorf("overlays = %d, want one dialog for both refusals", len(overlays))
+	}
+}
@@ -3,17 +3,26 @@ package presentation
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"wardenly-go/application"
 	"wardenly-go/domain/account"
 	"wardenly-go/domain/group"
+	"wardenly-go/domain/schedule"
+	"wardenly-go/infrastructure/stats"
 )
 
 // ManagementDialogConfig holds configuration for the management dialog.
@@ -21,11 +30,27 @@ type ManagementDialogConfig struct {
 	Parent         fyne.Window
 	AccountService *account.Service
 	GroupService   *group.Service
-	Logger         *slog.Logger
-	OnDataChanged  func() // Callback when data is modified
+	ScriptNames    []string // Choices for the per-account script lists
+	// ReadRoster OCRs the reference session's screen for roster import.
+	// Nil disables the import (no session selected).
+	ReadRoster     func(ctx context.Context) ([]string, error)
+	RosterServerID int // Server of the reference session
+	// AccountSync enables spreadsheet sync in the Accounts tab (optional)
+	AccountSync *application.AccountSync
+	// TemplateService enables the Templates tab (optional)
+	TemplateService *group.TemplateService
+	// StatsStore enables the Statistics tab (optional)
+	StatsStore stats.Store
+	// ScheduleService enables the Schedules tab (optional)
+	ScheduleService    *schedule.Service
+	NextScheduleRun    func(scheduleID string) time.Time // Optional: shown in the schedule list
+	OnSchedulesChanged func()                            // Callback when schedules are modified
+	Logger             *slog.Logger
+	OnDataChanged      func() // Callback when data is modified
 }
 
-// ManagementDialog provides CRUD operations for accounts and groups.
+// ManagementDialog provides CRUD operations for accounts, groups, templates
+// and schedules.
 type ManagementDialog struct {
 	config *ManagementDialogConfig
 	window fyne.Window
@@ -35,7 +60,9 @@ type ManagementDialog struct {
 
 	// Accounts tab
 	accountList     *widget.List
-	accounts        []*account.Account
+	accounts        []*account.Account // All accounts, including archived
+	listedAccounts  []*account.Account // Accounts shown in the list
+	showArchived    bool
 	selectedAccount *account.Account
 	accountForm     *AccountForm
 
@@ -44,6 +71,27 @@ type ManagementDialog struct {
 	groups        []*group.Group
 	selectedGroup *group.Group
 	groupForm     *GroupForm
+
+	// Templates tab
+	templateList     *widget.List
+	templates        []*group.Template
+	selectedTemplate *group.Template
+	templateForm     *TemplateForm
+
+	// Schedules tab
+	scheduleList     *widget.List
+	schedules        []*schedule.Schedule
+	selectedSchedule *schedule.Schedule
+	scheduleForm     *ScheduleForm
+
+	// Statistics tab
+	statsRange   *widget.Select
+	statsLabel   *widget.Label
+	statsTable   *widget.Table
+	statsRunList *widget.List
+	statsRuns    []stats.Run     // Runs in the selected range
+	summaries    []stats.Summary // Per account, shown in the table
+	accountRuns  []stats.Run     // Runs of the selected account, newest first
 }
 
 // ShowManagementDialog displays the account and group management dialog.
@@ -67,6 +115,10 @@ func ShowManagementDialog(cfg *ManagementDialogConfig) {
 
 	md.buildUI()
 	md.loadData()
+	if md.scheduleForm != nil {
+		// Targets are only known once data is loaded
+		md.scheduleForm.SetSchedule(nil, md.accounts, md.groups)
+	}
 
 	md.window.Resize(fyne.NewSize(800, 600))
 	md.window.CenterOnScreen()
@@ -79,6 +131,15 @@ func (md *ManagementDialog) buildUI() {
 	groupsTab := container.NewTabItemWithIcon("Groups", theme.FolderIcon(), md.buildGroupsTab())
 
 	md.tabs = container.NewAppTabs(accountsTab, groupsTab)
+	if md.config.TemplateService != nil {
+		md.tabs.Append(container.NewTabItemWithIcon("Templates", theme.FileIcon(), md.buildTemplatesTab()))
+	}
+	if md.config.ScheduleService != nil {
+		md.tabs.Append(container.NewTabItemWithIcon("Schedules", theme.HistoryIcon(), md.buildSchedulesTab()))
+	}
+	if md.config.StatsStore != nil {
+		md.tabs.Append(container.NewTabItemWithIcon("Statistics", theme.InfoIcon(), md.buildStatisticsTab()))
+	}
 	md.tabs.SetTabLocation(container.TabLocationTop)
 
 	// Tabs fill the entire window - no bottom bar needed (window X button suffices)
@@ -92,33 +153,60 @@ func (md *ManagementDialog) buildAccountsTab() fyne.CanvasObject {
 
 	// Account list
 	md.accountList = widget.NewList(
-		func() int { return len(md.accounts) },
+		func() int { return len(md.listedAccounts) },
 		func() fyne.CanvasObject {
-			return widget.NewLabel("Template Account Name")
+			return widget.NewLabel("Template Account Name (archived)")
 		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			if id < len(md.accounts) {
-				obj.(*widget.Label).SetText(md.accounts[id].Identity())
+			if id < len(md.listedAccounts) {
+				acc := md.listedAccounts[id]
+				label := acc.Identity()
+				if acc.Archived {
+					label += " (archived)"
+				}
+				obj.(*widget.Label).SetText(label)
 			}
 		},
 	)
 	md.accountList.OnSelected = func(id widget.ListItemID) {
-		if id < len(md.accounts) {
-			md.selectedAccount = md.accounts[id]
+		if id < len(md.listedAccounts) {
+			md.selectedAccount = md.listedAccounts[id]
 			md.accountForm.SetAccount(md.selectedAccount)
 		}
 	}
 
+	// Archived accounts are hidden unless asked for
+	showArchivedCheck := widget.NewCheck("Show archived", func(checked bool) {
+		md.showArchived = checked
+		md.refreshAccountList()
+		md.selectedAccount = nil
+		md.accountForm.SetAccount(nil)
+		md.accountList.UnselectAll()
+	})
+
+	// Spreadsheet sync previews and applies changes from an external sheet
+	syncBtn := widget.NewButtonWithIcon("Sync...", theme.ViewRefreshIcon(), md.onSyncAccounts)
+	if md.config.AccountSync == nil {
+		syncBtn.Disable()
+	}
+
+	// Export/import move accounts between machines as JSON or YAML
+	exportBtn := widget.NewButtonWithIcon("Export...", theme.UploadIcon(), md.onExportAccounts)
+	importBtn := widget.NewButtonWithIcon("Import...", theme.DownloadIcon(), md.onImportAccounts)
+
 	listPanel := container.NewBorder(
-		container.NewVBox(newBtn, widget.NewSeparator()),
+		container.NewVBox(newBtn, syncBtn, container.NewGridWithColumns(2, exportBtn, importBtn),
+			showArchivedCheck, widget.NewSeparator()),
 		nil, nil, nil,
 		md.accountList,
 	)
 
 	// Account form
 	md.accountForm = NewAccountForm(&AccountFormConfig{
-		OnSave:   md.onSaveAccount,
-		OnDelete: md.onDeleteAccount,
+		ScriptNames: md.config.ScriptNames,
+		OnSave:      md.onSaveAccount,
+		OnDelete:    md.onDeleteAccount,
+		OnArchive:   md.onArchiveAccount,
 	})
 
 	// Split layout
@@ -133,6 +221,12 @@ func (md *ManagementDialog) buildGroupsTab() fyne.CanvasObject {
 	newBtn := widget.NewButtonWithIcon("New Group", theme.ContentAddIcon(), md.onNewGroup)
 	newBtn.Importance = widget.HighImportance
 
+	// Roster import proposes members for the group being edited
+	importBtn := widget.NewButtonWithIcon("Import Roster...", theme.DownloadIcon(), md.onImportRoster)
+	if md.config.ReadRoster == nil {
+		importBtn.Disable()
+	}
+
 	// Group list
 	md.groupList = widget.NewList(
 		func() int { return len(md.groups) },
@@ -142,7 +236,11 @@ func (md *ManagementDialog) buildGroupsTab() fyne.CanvasObject {
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
 			if id < len(md.groups) {
 				grp := md.groups[id]
-				obj.(*widget.Label).SetText(fmt.Sprintf("%s (%d)", grp.Name, grp.AccountCount()))
+				if grp.IsSmart() {
+					obj.(*widget.Label).SetText(grp.Name + " (smart)")
+				} else {
+					obj.(*widget.Label).SetText(fmt.Sprintf("%s (%d)", grp.Name, grp.AccountCount()))
+				}
 			}
 		},
 	)
@@ -153,16 +251,22 @@ func (md *ManagementDialog) buildGroupsTab() fyne.CanvasObject {
 		}
 	}
 
+	exportBtn := widget.NewButtonWithIcon("Export...", theme.UploadIcon(), md.onExportGroups)
+	importGroupsBtn := widget.NewButtonWithIcon("Import...", theme.DownloadIcon(), md.onImportGroups)
+
 	listPanel := container.NewBorder(
-		container.NewVBox(newBtn, widget.NewSeparator()),
+		container.NewVBox(newBtn, importBtn, container.NewGridWithColumns(2, exportBtn, importGroupsBtn),
+			widget.NewSeparator()),
 		nil, nil, nil,
 		md.groupList,
 	)
 
 	// Group form
 	md.groupForm = NewGroupForm(&GroupFormConfig{
-		OnSave:   md.onSaveGroup,
-		OnDelete: md.onDeleteGroup,
+		ScriptNames:  md.config.ScriptNames,
+		TemplateName: md.templateName,
+		OnSave:       md.onSaveGroup,
+		OnDelete:     md.onDeleteGroup,
 	})
 	// Initialize with empty state
 	md.groupForm.SetGroup(nil, md.accounts)
@@ -174,6 +278,275 @@ func (md *ManagementDialog) buildGroupsTab() fyne.CanvasObject {
 	return split
 }
 
+func (md *ManagementDialog) buildTemplatesTab() fyne.CanvasObject {
+	// New template button
+	newBtn := widget.NewButtonWithIcon("New Template", theme.ContentAddIcon(), md.onNewTemplate)
+	newBtn.Importance = widget.HighImportance
+
+	// Template list
+	md.templateList = widget.NewList(
+		func() int { return len(md.templates) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template Name")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < len(md.templates) {
+				obj.(*widget.Label).SetText(md.templates[id].Name)
+			}
+		},
+	)
+	md.templateList.OnSelected = func(id widget.ListItemID) {
+		if id < len(md.templates) {
+			md.selectedTemplate = md.templates[id]
+			md.templateForm.SetTemplate(md.selectedTemplate, md.derivedCount(md.selectedTemplate.ID))
+		}
+	}
+
+	listPanel := container.NewBorder(
+		container.NewVBox(newBtn, widget.NewSeparator()),
+		nil, nil, nil,
+		md.templateList,
+	)
+
+	// Template form
+	md.templateForm = NewTemplateForm(&TemplateFormConfig{
+		ScriptNames: md.config.ScriptNames,
+		OnSave:      md.onSaveTemplate,
+		OnDelete:    md.onDeleteTemplate,
+		OnNewGroup:  md.onNewGroupFromTemplate,
+	})
+	md.templateForm.SetTemplate(nil, 0)
+
+	// Split layout
+	split := container.NewHSplit(listPanel, md.templateForm.Container())
+	split.SetOffset(0.35)
+
+	return split
+}
+
+func (md *ManagementDialog) buildSchedulesTab() fyne.CanvasObject {
+	// New schedule button
+	newBtn := widget.NewButtonWithIcon("New Schedule", theme.ContentAddIcon(), md.onNewSchedule)
+	newBtn.Importance = widget.HighImportance
+
+	// Schedule list
+	md.scheduleList = widget.NewList(
+		func() int { return len(md.schedules) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template Schedule Name (00-00 00:00)")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < len(md.schedules) {
+				obj.(*widget.Label).SetText(md.scheduleLabel(md.schedules[id]))
+			}
+		},
+	)
+	md.scheduleList.OnSelected = func(id widget.ListItemID) {
+		if id < len(md.schedules) {
+			md.selectedSchedule = md.schedules[id]
+			md.scheduleForm.SetSchedule(md.selectedSchedule, md.accounts, md.groups)
+		}
+	}
+
+	listPanel := container.NewBorder(
+		container.NewVBox(newBtn, widget.NewSeparator()),
+		nil, nil, nil,
+		md.scheduleList,
+	)
+
+	// Schedule form
+	md.scheduleForm = NewScheduleForm(&ScheduleFormConfig{
+		ScriptNames: md.config.ScriptNames,
+		OnSave:      md.onSaveSchedule,
+		OnDelete:    md.onDeleteSchedule,
+	})
+	md.scheduleForm.SetSchedule(nil, md.accounts, md.groups)
+
+	// Split layout
+	split := container.NewHSplit(listPanel, md.scheduleForm.Container())
+	split.SetOffset(0.35)
+
+	return split
+}
+
+// Ranges offered by the Statistics tab, in days including today.
+var statsRanges = []struct {
+	label string
+	days  int
+}{
+	{"Today", 1},
+	{"Last 7 Days", 7},
+	{"Last 30 Days", 30},
+}
+
+// statsLoadTimeout bounds loading runs from the statistics store.
+const statsLoadTimeout = 10 * time.Second
+
+func (md *ManagementDialog) buildStatisticsTab() fyne.CanvasObject {
+	labels := make([]string, len(statsRanges))
+	for i, r := range statsRanges {
+		labels[i] = r.label
+	}
+	md.statsRange = widget.NewSelect(labels, func(string) { md.loadStatistics() })
+	refreshBtn := widget.NewButtonWithIcon("Refresh", theme.ViewRefreshIcon(), md.loadStatistics)
+	md.statsLabel = widget.NewLabel("")
+
+	headers := []string{"Account", "Runs", "Success", "Farm Time"}
+	md.statsTable = widget.NewTable(
+		func() (int, int) { return len(md.summaries) + 1, len(headers) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			if id.Row == 0 {
+				label.TextStyle = fyne.TextStyle{Bold: true}
+				label.SetText(headers[id.Col])
+				return
+			}
+			label.TextStyle = fyne.TextStyle{}
+			if id.Row > len(md.summaries) {
+				label.SetText("")
+				return
+			}
+			label.SetText(statsCell(&md.summaries[id.Row-1], id.Col))
+		},
+	)
+	md.statsTable.SetColumnWidth(0, 260)
+	md.statsTable.SetColumnWidth(1, 80)
+	md.statsTable.SetColumnWidth(2, 100)
+	md.statsTable.SetColumnWidth(3, 120)
+	md.statsTable.OnSelected = func(id widget.TableCellID) {
+		if id.Row == 0 || id.Row > len(md.summaries) {
+			md.statsTable.UnselectAll()
+			return
+		}
+		md.showAccountRuns(md.summaries[id.Row-1].AccountID)
+	}
+
+	md.statsRunList = widget.NewList(
+		func() int { return len(md.accountRuns) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < len(md.accountRuns) {
+				obj.(*widget.Label).SetText(statsRunLine(&md.accountRuns[id]))
+			}
+		},
+	)
+
+	top := container.NewVBox(
+		container.NewBorder(nil, nil, md.statsRange, refreshBtn),
+		md.statsLabel,
+	)
+	split := container.NewVSplit(md.statsTable, md.statsRunList)
+	split.SetOffset(0.6)
+
+	md.statsRange.SetSelectedIndex(1)
+	return container.NewBorder(top, nil, nil, nil, split)
+}
+
+// loadStatistics reads the runs of the selected range and totals them per
+// account.
+func (md *ManagementDialog) loadStatistics() {
+	days := statsRanges[max(0, md.statsRange.SelectedIndex())].days
+	now := time.Now()
+	from, to := stats.Day(now.AddDate(0, 0, 1-days)), stats.Day(now)
+
+	ctx, cancel := context.WithTimeout(context.Background(), statsLoadTimeout)
+	defer cancel()
+	runs, err := md.config.StatsStore.Runs(ctx, from, to)
+	if err != nil {
+		md.config.Logger.Warn("Failed to load script statistics", "error", err)
+		dialog.ShowError(err, md.window)
+		return
+	}
+
+	md.statsRuns = runs
+	md.summaries = stats.Summarize(runs)
+	total := stats.Summary{}
+	for _, s := range md.summaries {
+		total.Runs += s.Runs
+		total.Succeeded += s.Succeeded
+		total.FarmTime += s.FarmTime
+	}
+	md.statsLabel.SetText(fmt.Sprintf("%d runs on %d accounts · %.0f%% succeeded · %s farm time",
+		total.Runs, len(md.summaries), total.SuccessRate()*100, formatFarmTime(total.FarmTime)))
+	md.statsTable.UnselectAll()
+	md.statsTable.Refresh()
+	md.showAccountRuns("")
+}
+
+// showAccountRuns lists the runs of an account, newest first.
+func (md *ManagementDialog) showAccountRuns(accountID string) {
+	md.accountRuns = md.accountRuns[:0]
+	for i := len(md.statsRuns) - 1; i >= 0; i-- {
+		if accountID != "" && md.statsRuns[i].AccountID == accountID {
+			md.accountRuns = append(md.accountRuns, md.statsRuns[i])
+		}
+	}
+	md.statsRunList.Refresh()
+}
+
+// statsCell returns the text of a summary column.
+func statsCell(s *stats.Summary, col int) string {
+	switch col {
+	case 0:
+		if s.AccountName != "" {
+			return s.AccountName
+		}
+		return s.AccountID
+	case 1:
+		return strconv.Itoa(s.Runs)
+	case 2:
+		return fmt.Sprintf("%.0f%%", s.SuccessRate()*100)
+	default:
+		return formatFarmTime(s.FarmTime)
+	}
+}
+
+// statsRunLine describes a run: when, which script, how long, why it
+// stopped and the counters it stopped with.
+func statsRunLine(run *stats.Run) string {
+	line := fmt.Sprintf("%s  %s  %s  %s", run.StartedAt.Local().Format("01-02 15:04"), run.ScriptName,
+		formatFarmTime(run.Duration()), run.StopReason)
+	if run.Error != "" {
+		line += ": " + run.Error
+	}
+	if len(run.Counters) > 0 {
+		keys := slices.Sorted(maps.Keys(run.Counters))
+		counters := make([]string, len(keys))
+		for i, k := range keys {
+			counters[i] = fmt.Sprintf("%s=%d", k, run.Counters[k])
+		}
+		line += "  [" + strings.Join(counters, " ") + "]"
+	}
+	return line
+}
+
+// formatFarmTime shows a duration in hours and minutes, e.g. "5h20m".
+func formatFarmTime(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// scheduleLabel shows a schedule's name with its next run or disabled state.
+func (md *ManagementDialog) scheduleLabel(sch *schedule.Schedule) string {
+	if !sch.Enabled {
+		return fmt.Sprintf("%s (disabled)", sch.Name)
+	}
+	if md.config.NextScheduleRun != nil {
+		if next := md.config.NextScheduleRun(sch.ID); !next.IsZero() {
+			return fmt.Sprintf("%s (%s)", sch.Name, next.Format("01-02 15:04"))
+		}
+	}
+	return sch.Name
+}
+
 func (md *ManagementDialog) loadData() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -194,15 +567,51 @@ func (md *ManagementDialog) loadData() {
 		md.groups = groups
 	}
 
+	// Load templates
+	if md.config.TemplateService != nil {
+		templates, err := md.config.TemplateService.ListTemplates(ctx)
+		if err != nil {
+			md.config.Logger.Error("Failed to load templates", "error", err)
+		} else {
+			md.templates = templates
+		}
+	}
+
+	// Load schedules
+	if md.config.ScheduleService != nil {
+		schedules, err := md.config.ScheduleService.ListSchedules(ctx)
+		if err != nil {
+			md.config.Logger.Error("Failed to load schedules", "error", err)
+		} else {
+			md.schedules = schedules
+		}
+	}
+
 	// Refresh lists
-	if md.accountList != nil {
-		md.accountList.Refresh()
+	if md.templateList != nil {
+		md.templateList.Refresh()
+	}
+	if md.scheduleList != nil {
+		md.scheduleList.Refresh()
 	}
+	md.refreshAccountList()
 	if md.groupList != nil {
 		md.groupList.Refresh()
 	}
 }
 
+// refreshAccountList applies the archived filter to the account list.
+func (md *ManagementDialog) refreshAccountList() {
+	if md.showArchived {
+		md.listedAccounts = md.accounts
+	} else {
+		md.listedAccounts = account.ActiveAccounts(md.accounts)
+	}
+	if md.accountList != nil {
+		md.accountList.Refresh()
+	}
+}
+
 // Account handlers
 
 func (md *ManagementDialog) onNewAccount() {
@@ -232,7 +641,7 @@ func (md *ManagementDialog) onSaveAccount(acc *account.Account) {
 
 	// Re-select the saved account if it was new
 	if acc.ID != "" {
-		for i, a := range md.accounts {
+		for i, a := range md.listedAccounts {
 			if a.ID == acc.ID {
 				md.accountList.Select(i)
 				break
@@ -241,6 +650,17 @@ func (md *ManagementDialog) onSaveAccount(acc *account.Account) {
 	}
 }
 
+func (md *ManagementDialog) onSyncAccounts() {
+	ShowAccountSyncDialog(&AccountSyncDialogConfig{
+		Sync: md.config.AccountSync,
+		OnApplied: func() {
+			md.loadData()
+			md.notifyDataChanged()
+		},
+		Logger: md.config.Logger,
+	})
+}
+
 func (md *ManagementDialog) onDeleteAccount(acc *account.Account) {
 	if acc == nil || acc.ID == "" {
 		return
@@ -276,8 +696,144 @@ func (md *ManagementDialog) onDeleteAccount(acc *account.Account) {
 	)
 }
 
+func (md *ManagementDialog) onArchiveAccount(acc *account.Account, archived bool) {
+	if acc == nil || acc.ID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := md.config.AccountService.SetArchived(ctx, acc.ID, archived); err != nil {
+		dialog.ShowError(err, md.window)
+		return
+	}
+
+	md.loadData()
+	md.notifyDataChanged()
+
+	// Keep the account selected if it is still listed
+	md.selectedAccount = nil
+	md.accountForm.SetAccount(nil)
+	md.accountList.UnselectAll()
+	for i, a := range md.listedAccounts {
+		if a.ID == acc.ID {
+			md.accountList.Select(i)
+			break
+		}
+	}
+}
+
+func (md *ManagementDialog) onExportAccounts() {
+	dialog.ShowConfirm("Export Accounts",
+		"The export contains passwords and cookies in plaintext. Keep the file safe.",
+		func(confirmed bool) {
+			if confirmed {
+				md.exportFile("accounts.json", md.config.AccountService.ExportAll)
+			}
+		},
+		md.window,
+	)
+}
+
+func (md *ManagementDialog) onImportAccounts() {
+	md.importFile("Import Accounts", md.config.AccountService.ImportAll)
+}
+
+// exportFile asks for a file and writes the export to it, as YAML if the
+// file name ends in .yaml or .yml and as JSON otherwise.
+func (md *ManagementDialog) exportFile(fileName string, export func(context.Context, account.Format) ([]byte, error)) {
+	save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil || w == nil {
+			return
+		}
+		defer w.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		data, err := export(ctx, account.FormatForPath(w.URI().Path()))
+		if err == nil {
+			_, err = w.Write(data)
+		}
+		if err != nil {
+			md.config.Logger.Error("Export failed", "file", w.URI().Path(), "error", err)
+			dialog.ShowError(err, md.window)
+		}
+	}, md.window)
+	save.SetFileName(fileName)
+	save.SetFilter(storage.NewExtensionFileFilter([]string{".json", ".yaml", ".yml"}))
+	save.Show()
+}
+
+// importFile asks for a file, imports it and shows what was added and skipped.
+func (md *ManagementDialog) importFile(title string, importAll func(context.Context, []byte, account.Format) (*account.ImportResult, error)) {
+	open := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil || r == nil {
+			return
+		}
+		defer r.Close()
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			dialog.ShowError(err, md.window)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		result, err := importAll(ctx, data, account.FormatForPath(r.URI().Path()))
+		if result != nil && result.Added > 0 {
+			md.loadData()
+			md.notifyDataChanged()
+		}
+		if err != nil {
+			md.config.Logger.Error("Import failed", "file", r.URI().Path(), "error", err)
+			dialog.ShowError(err, md.window)
+			return
+		}
+		dialog.ShowInformation(title, importSummary(result), md.window)
+	}, md.window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".json", ".yaml", ".yml"}))
+	open.Show()
+}
+
+func importSummary(result *account.ImportResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Added %d, skipped %d already present.", result.Added, len(result.Skipped))
+	if len(result.Skipped) > 0 {
+		b.WriteString("\n\nSkipped: " + strings.Join(result.Skipped, ", "))
+	}
+	if len(result.Unmatched) > 0 {
+		fmt.Fprintf(&b, "\n\n%d member(s) without a matching account were left out:\n", len(result.Unmatched))
+		b.WriteString(strings.Join(result.Unmatched, "\n"))
+	}
+	if len(result.Warnings) > 0 {
+		b.WriteString("\n\n" + strings.Join(result.Warnings, "\n"))
+	}
+	return b.String()
+}
+
 // Group handlers
 
+func (md *ManagementDialog) onExportGroups() {
+	md.exportFile("groups.json", md.config.GroupService.ExportAll)
+}
+
+// onImportGroups imports groups; members are matched to accounts by server
+// and user name, so accounts should be imported first.
+func (md *ManagementDialog) onImportGroups() {
+	md.importFile("Import Groups", md.config.GroupService.ImportAll)
+}
+
+func (md *ManagementDialog) onImportRoster() {
+	ShowRosterImportDialog(&RosterImportDialogConfig{
+		Accounts: account.ActiveAccounts(md.accounts),
+		ServerID: md.config.RosterServerID,
+		ReadPage: md.config.ReadRoster,
+		OnApply:  md.groupForm.ProposeMembers,
+		Logger:   md.config.Logger,
+	})
+}
+
 func (md *ManagementDialog) onNewGroup() {
 	md.selectedGroup = nil
 	md.groupForm.SetGroup(nil, md.accounts)
@@ -343,6 +899,249 @@ func (md *ManagementDialog) onDeleteGroup(grp *group.Group) {
 	)
 }
 
+// Template handlers
+
+// templateName returns the name of a loaded template, or "" if unknown.
+func (md *ManagementDialog) templateName(templateID string) string {
+	for _, tmpl := range md.templates {
+		if tmpl.ID == templateID {
+			return tmpl.Name
+		}
+	}
+	return ""
+}
+
+// derivedCount returns the number of loaded groups created from a template.
+func (md *ManagementDialog) derivedCount(templateID string) int {
+	n := 0
+	for _, grp := range md.groups {
+		if grp.TemplateID == templateID {
+			n++
+		}
+	}
+	return n
+}
+
+func (md *ManagementDialog) onNewTemplate() {
+	md.selectedTemplate = nil
+	md.templateForm.SetTemplate(nil, 0)
+	md.templateList.UnselectAll()
+}
+
+func (md *ManagementDialog) onSaveTemplate(tmpl *group.Template) {
+	if tmpl.ID == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := md.config.TemplateService.CreateTemplate(ctx, tmpl); err != nil {
+			dialog.ShowError(err, md.window)
+			return
+		}
+		md.afterTemplateSaved(tmpl)
+		return
+	}
+
+	derived := md.derivedCount(tmpl.ID)
+	if derived == 0 {
+		md.updateTemplate(tmpl, false)
+		return
+	}
+
+	// Derived groups only follow the template when asked to
+	confirm := dialog.NewConfirm("Update Derived Groups",
+		fmt.Sprintf("Apply the new settings to the %d group(s) created from '%s'?\nExisting schedules are not changed.", derived, tmpl.Name),
+		func(propagate bool) { md.updateTemplate(tmpl, propagate) },
+		md.window,
+	)
+	confirm.SetConfirmText("Apply to Groups")
+	confirm.SetDismissText("Template Only")
+	confirm.Show()
+}
+
+func (md *ManagementDialog) updateTemplate(tmpl *group.Template, propagate bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	updated, err := md.config.TemplateService.UpdateTemplate(ctx, tmpl, propagate)
+	if err != nil {
+		dialog.ShowError(err, md.window)
+		if updated == 0 {
+			return
+		}
+	}
+	if updated > 0 {
+		md.notifyDataChanged()
+	}
+	md.afterTemplateSaved(tmpl)
+}
+
+func (md *ManagementDialog) afterTemplateSaved(tmpl *group.Template) {
+	md.loadData()
+
+	// Re-select the saved template if it was new
+	for i, t := range md.templates {
+		if t.ID == tmpl.ID {
+			md.templateList.Select(i)
+			break
+		}
+	}
+}
+
+func (md *ManagementDialog) onDeleteTemplate(tmpl *group.Template) {
+	if tmpl == nil || tmpl.ID == "" {
+		return
+	}
+
+	dialog.ShowConfirm("Delete Template",
+		fmt.Sprintf("Are you sure you want to delete template '%s'?\nGroups created from it keep their settings.", tmpl.Name),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err := md.config.TemplateService.DeleteTemplate(ctx, tmpl.ID); err != nil {
+				dialog.ShowError(err, md.window)
+				return
+			}
+
+			md.selectedTemplate = nil
+			md.templateForm.SetTemplate(nil, 0)
+			md.loadData()
+			md.notifyDataChanged()
+		},
+		md.window,
+	)
+}
+
+// onNewGroupFromTemplate asks for a name, creates the group and, if the
+// template has one, its schedule. Members are then picked in the Groups tab.
+func (md *ManagementDialog) onNewGroupFromTemplate(tmpl *group.Template) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Group name")
+
+	dialog.ShowForm("New Group from "+tmpl.Name, "Create", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Name", nameEntry)},
+		func(ok bool) {
+			if !ok || nameEntry.Text == "" {
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			grp, err := md.config.TemplateService.CreateGroup(ctx, tmpl.ID, nameEntry.Text)
+			if err != nil {
+				dialog.ShowError(err, md.window)
+				return
+			}
+
+			if tmpl.ScheduleSpec != "" && md.config.ScheduleService != nil {
+				sch := &schedule.Schedule{
+					Name:       fmt.Sprintf("%s (%s)", grp.Name, tmpl.Name),
+					TargetType: schedule.TargetGroup,
+					TargetID:   grp.ID,
+					ScriptName: tmpl.Settings.ScriptName,
+					Spec:       tmpl.ScheduleSpec,
+					Enabled:    true,
+				}
+				if err := md.config.ScheduleService.CreateSchedule(ctx, sch); err != nil {
+					dialog.ShowError(fmt.Errorf("group created, but its schedule failed: %w", err), md.window)
+				} else {
+					md.notifySchedulesChanged()
+				}
+			}
+
+			md.loadData()
+			md.notifyDataChanged()
+
+			// Continue in the Groups tab to pick members
+			md.tabs.SelectIndex(1)
+			for i, g := range md.groups {
+				if g.ID == grp.ID {
+					md.groupList.Select(i)
+					break
+				}
+			}
+		},
+		md.window,
+	)
+}
+
+// Schedule handlers
+
+func (md *ManagementDialog) onNewSchedule() {
+	md.selectedSchedule = nil
+	md.scheduleForm.SetSchedule(nil, md.accounts, md.groups)
+	md.scheduleList.UnselectAll()
+}
+
+func (md *ManagementDialog) onSaveSchedule(sch *schedule.Schedule) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var err error
+	if sch.ID == "" {
+		err = md.config.ScheduleService.CreateSchedule(ctx, sch)
+	} else {
+		err = md.config.ScheduleService.UpdateSchedule(ctx, sch)
+	}
+
+	if err != nil {
+		dialog.ShowError(err, md.window)
+		return
+	}
+
+	md.notifySchedulesChanged()
+	md.loadData()
+
+	// Re-select the saved schedule if it was new
+	if sch.ID != "" {
+		for i, s := range md.schedules {
+			if s.ID == sch.ID {
+				md.scheduleList.Select(i)
+				break
+			}
+		}
+	}
+}
+
+func (md *ManagementDialog) onDeleteSchedule(sch *schedule.Schedule) {
+	if sch == nil || sch.ID == "" {
+		return
+	}
+
+	dialog.ShowConfirm("Delete Schedule",
+		fmt.Sprintf("Are you sure you want to delete schedule '%s'?", sch.Name),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err := md.config.ScheduleService.DeleteSchedule(ctx, sch.ID); err != nil {
+				dialog.ShowError(err, md.window)
+				return
+			}
+
+			md.selectedSchedule = nil
+			md.scheduleForm.SetSchedule(nil, md.accounts, md.groups)
+			md.notifySchedulesChanged()
+			md.loadData()
+		},
+		md.window,
+	)
+}
+
+func (md *ManagementDialog) notifySchedulesChanged() {
+	if md.config.OnSchedulesChanged != nil {
+		md.config.OnSchedulesChanged()
+	}
+}
+
 func (md *ManagementDialog) notifyDataChanged() {
 	if md.config.OnDataChanged != nil {
 		md.config.OnDataChanged()
@@ -0,0 +1,206 @@
+package presentation
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/infrastructure/notify"
+)
+
+// notificationAllSessions shows the notifications of every session.
+const notificationAllSessions = "All Sessions"
+
+// NotificationDialogConfig holds configuration for the notification center.
+type NotificationDialogConfig struct {
+	Center *notify.Center
+	Logger *slog.Logger
+}
+
+// notificationDialog lists recorded alerts grouped by session and lets the
+// user mark them read.
+type notificationDialog struct {
+	config *NotificationDialogConfig
+	window fyne.Window
+
+	sessionSelect *widget.Select
+	list          *widget.List
+	detailLabel   *widget.Label
+
+	sessions map[string]string     // Select label -> session ID
+	shown    []notify.Notification // Selected session, newest first
+}
+
+// ShowNotificationDialog displays the notification center.
+func ShowNotificationDialog(cfg *NotificationDialogConfig) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	d := &notificationDialog{config: cfg}
+	d.window = fyne.CurrentApp().NewWindow("Notifications")
+	d.buildUI()
+
+	d.window.Resize(fyne.NewSize(800, 500))
+	d.window.CenterOnScreen()
+	d.window.Show()
+
+	d.reload()
+}
+
+func (d *notificationDialog) buildUI() {
+	d.sessionSelect = widget.NewSelect(nil, func(string) { d.applyFilter() })
+	d.sessionSelect.PlaceHolder = "Select Session"
+
+	d.list = widget.NewList(
+		func() int { return len(d.shown) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			n := d.shown[id]
+			label := obj.(*widget.Label)
+			label.TextStyle = fyne.TextStyle{Bold: !n.Read}
+			label.SetText(notificationLine(n))
+		},
+	)
+	d.list.OnSelected = d.showNotification
+
+	d.detailLabel = widget.NewLabel("")
+	d.detailLabel.Wrapping = fyne.TextWrapWord
+
+	markAllBtn := widget.NewButton("Mark All Read", func() {
+		d.save(d.config.Center.MarkAllRead())
+	})
+	clearBtn := widget.NewButton("Clear", func() {
+		dialog.ShowConfirm("Clear Notifications", "Remove all notifications?", func(ok bool) {
+			if ok {
+				d.save(d.config.Center.Clear())
+			}
+		}, d.window)
+	})
+	refreshBtn := widget.NewButton("Refresh", d.reload)
+
+	top := container.NewBorder(nil, nil, nil,
+		container.NewHBox(markAllBtn, clearBtn, refreshBtn), d.sessionSelect)
+	split := container.NewHSplit(d.list, container.NewVScroll(d.detailLabel))
+	split.Offset = 0.6
+	d.window.SetContent(container.NewBorder(top, nil, nil, nil, split))
+}
+
+// save reports a failed write and shows the center's current state.
+func (d *notificationDialog) save(err error) {
+	if err != nil {
+		d.config.Logger.Warn("Failed to save notifications", "error", err)
+		dialog.ShowError(err, d.window)
+	}
+	d.reload()
+}
+
+// reload rebuilds the session choices from the center, keeping the
+// selected session when it still has notifications.
+func (d *notificationDialog) reload() {
+	all := d.config.Center.List()
+	groups := notify.GroupBySession(all)
+
+	selectedID, hadSelection := d.sessions[d.sessionSelect.Selected]
+	unread := 0
+	for _, n := range all {
+		if !n.Read {
+			unread++
+		}
+	}
+
+	allLabel := fmt.Sprintf("%s (%d unread)", notificationAllSessions, unread)
+	d.sessions = map[string]string{allLabel: ""}
+	labels := []string{allLabel}
+	selected := allLabel
+	for _, g := range groups {
+		label := notificationGroupLabel(g)
+		d.sessions[label] = g.SessionID
+		labels = append(labels, label)
+		if hadSelection && selectedID != "" && g.SessionID == selectedID {
+			selected = label
+		}
+	}
+
+	d.sessionSelect.SetOptions(labels)
+	d.sessionSelect.SetSelected(selected) // Calls applyFilter
+}
+
+// applyFilter shows the notifications of the selected session.
+func (d *notificationDialog) applyFilter() {
+	if d.list == nil {
+		return
+	}
+	sessionID, all := "", true
+	if id, ok := d.sessions[d.sessionSelect.Selected]; ok && id != "" {
+		sessionID, all = id, false
+	}
+
+	d.shown = d.shown[:0]
+	for _, n := range d.config.Center.List() {
+		if all || n.SessionID == sessionID {
+			d.shown = append(d.shown, n)
+		}
+	}
+	d.list.UnselectAll()
+	d.list.Refresh()
+	d.detailLabel.SetText(fmt.Sprintf("%d notifications", len(d.shown)))
+}
+
+// showNotification shows the full text of a notification and marks it read.
+func (d *notificationDialog) showNotification(id widget.ListItemID) {
+	if id < 0 || id >= len(d.shown) {
+		return
+	}
+	n := d.shown[id]
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %s\n", n.Time.Local().Format("2006-01-02 15:04:05"), n.Title)
+	if name := notificationAccount(n); name != "" {
+		fmt.Fprintf(&b, "Session: %s\n", name)
+	}
+	b.WriteString(n.Message)
+	d.detailLabel.SetText(b.String())
+
+	if !n.Read {
+		if err := d.config.Center.MarkRead(n.ID); err != nil {
+			d.config.Logger.Warn("Failed to save notifications", "error", err)
+		}
+		d.shown[id].Read = true
+		d.list.RefreshItem(id)
+	}
+}
+
+func notificationAccount(n notify.Notification) string {
+	if n.AccountName != "" {
+		return n.AccountName
+	}
+	return n.SessionID
+}
+
+func notificationLine(n notify.Notification) string {
+	line := n.Time.Local().Format("01-02 15:04:05") + "  " + n.Title
+	if name := notificationAccount(n); name != "" {
+		line += " - " + name
+	}
+	return line
+}
+
+func notificationGroupLabel(g notify.Group) string {
+	name := g.AccountName
+	if name == "" {
+		name = g.SessionID
+	}
+	if name == "" {
+		name = "(no session)"
+	}
+	return fmt.Sprintf("%s (%d, %d unread)", name, len(g.Items), g.Unread)
+}
@@ -0,0 +1,239 @@
+package presentation
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/infrastructure/browser"
+	"wardenly-go/infrastructure/config"
+)
+
+// PreferencesDialogConfig holds configuration for the preferences dialog.
+type PreferencesDialogConfig struct {
+	// Path is the config file the preferences are read from and saved to
+	Path string
+	// OnSaved is called with the saved file contents
+	OnSaved func(cfg *config.Config)
+	Logger  *slog.Logger
+}
+
+// preferencesDialog edits the settings of the config file that are
+// commonly tuned per machine. Other keys in the file are kept.
+type preferencesDialog struct {
+	config *PreferencesDialogConfig
+	window fyne.Window
+
+	ocrEntry       *widget.Entry
+	qualityEntry   *widget.Entry
+	fpsEntry       *widget.Entry
+	delayEntry     *widget.Entry
+	idleEntry      *widget.Entry
+	thresholdEntry *widget.Entry
+	loginURLEntry  *widget.Entry
+	headlessCheck  *widget.Check
+	desktopCheck   *widget.Check
+}
+
+// ShowPreferencesDialog opens a window that edits the config file.
+func ShowPreferencesDialog(cfg *PreferencesDialogConfig) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	d := &preferencesDialog{config: cfg}
+	d.window = fyne.CurrentApp().NewWindow("Preferences")
+	d.buildUI()
+
+	file, err := d.load()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("%s: %w", cfg.Path, err), d.window)
+	}
+	d.show(file)
+
+	d.window.Resize(fyne.NewSize(520, 420))
+	d.window.CenterOnScreen()
+	d.window.Show()
+}
+
+func (d *preferencesDialog) buildUI() {
+	hint := widget.NewLabel("Saved to " + d.config.Path + ". Screencast settings apply to the next stream; " +
+		"desktop notifications right away; the others when Wardenly is restarted. Empty fields use the defaults.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	d.ocrEntry = widget.NewEntry()
+	d.ocrEntry.SetPlaceHolder("http://localhost:8000, http://ocr-2:8000")
+	d.qualityEntry = newIntEntry(strconv.Itoa(DefaultScreencastQuality), 1, 100)
+	d.fpsEntry = newIntEntry(strconv.Itoa(DefaultScreencastMaxFPS), 1, 60)
+	d.delayEntry = newDurationEntry(DefaultScreencastStartDelay.String(), "1s or 500ms")
+	d.idleEntry = newDurationEntry("Never", "10m or 1h")
+	d.thresholdEntry = widget.NewEntry()
+	d.thresholdEntry.SetPlaceHolder("5")
+	d.thresholdEntry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if v, err := strconv.ParseFloat(s, 64); err != nil || v <= 0 {
+			return fmt.Errorf("must be a positive number")
+		}
+		return nil
+	}
+	d.loginURLEntry = widget.NewEntry()
+	d.loginURLEntry.SetPlaceHolder(browser.DefaultURLTemplate)
+	d.headlessCheck = widget.NewCheck("Run browsers without a window", nil)
+	d.desktopCheck = widget.NewCheck("Show failed logins, script errors and crashes", nil)
+
+	form := widget.NewForm(
+		widget.NewFormItem("OCR Endpoints", d.ocrEntry),
+		widget.NewFormItem("Stream Quality", d.qualityEntry),
+		widget.NewFormItem("Stream Max FPS", d.fpsEntry),
+		widget.NewFormItem("Auto Refresh Delay", d.delayEntry),
+		widget.NewFormItem("Pause Unattended After", d.idleEntry),
+		widget.NewFormItem("Scene Threshold", d.thresholdEntry),
+		&widget.FormItem{Text: "Login URL", Widget: d.loginURLEntry, HintText: "{server} stands for the server ID; accounts may override it"},
+		widget.NewFormItem("Headless", d.headlessCheck),
+		widget.NewFormItem("Desktop Notifications", d.desktopCheck),
+	)
+
+	var top fyne.CanvasObject = hint
+	if overrides := config.EnvOverrides(); len(overrides) > 0 {
+		warning := widget.NewLabel("Overridden by the environment: " + strings.Join(overrides, ", "))
+		warning.Wrapping = fyne.TextWrapWord
+		warning.Importance = widget.WarningImportance
+		top = container.NewVBox(hint, warning)
+	}
+
+	saveBtn := widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), d.save)
+	saveBtn.Importance = widget.HighImportance
+
+	d.window.SetContent(container.NewBorder(
+		top,
+		container.NewHBox(layout.NewSpacer(), widget.NewButton("Cancel", d.window.Close), saveBtn),
+		nil, nil,
+		container.NewVScroll(form),
+	))
+}
+
+// newIntEntry creates an entry for an optional whole number in [min, max].
+func newIntEntry(placeholder string, min, max int) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(placeholder)
+	entry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if n, err := strconv.Atoi(s); err != nil || n < min || n > max {
+			return fmt.Errorf("must be a whole number from %d to %d", min, max)
+		}
+		return nil
+	}
+	return entry
+}
+
+// newDurationEntry creates an entry for an optional duration.
+func newDurationEntry(placeholder, examples string) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(placeholder)
+	entry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if v, err := time.ParseDuration(s); err != nil || v < 0 {
+			return fmt.Errorf("must be a duration such as %s", examples)
+		}
+		return nil
+	}
+	return entry
+}
+
+// load reads the config file; a missing file is an empty config.
+func (d *preferencesDialog) load() (*config.Config, error) {
+	file, err := config.Load(d.config.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	return file, err
+}
+
+func (d *preferencesDialog) show(file *config.Config) {
+	d.ocrEntry.SetText(strings.Join(file.OCR.URLs, ", "))
+	d.qualityEntry.SetText(formatOptionalInt(file.Screencast.Quality))
+	d.fpsEntry.SetText(formatOptionalInt(file.Screencast.MaxFPS))
+	d.delayEntry.SetText(file.Screencast.StartDelay)
+	d.idleEntry.SetText(file.Screencast.IdlePause)
+	if file.Scenes.Threshold > 0 {
+		d.thresholdEntry.SetText(strconv.FormatFloat(file.Scenes.Threshold, 'g', -1, 64))
+	}
+	d.loginURLEntry.SetText(file.Login.URLTemplate)
+	headless := browser.DefaultDriverConfig().Headless
+	if file.Browser.Headless != nil {
+		headless = *file.Browser.Headless
+	}
+	d.headlessCheck.SetChecked(headless)
+	d.desktopCheck.SetChecked(file.Notify.DesktopEnabled())
+}
+
+func (d *preferencesDialog) save() {
+	for _, entry := range []*widget.Entry{d.qualityEntry, d.fpsEntry, d.delayEntry, d.idleEntry, d.thresholdEntry} {
+		if err := entry.Validate(); err != nil {
+			dialog.ShowError(err, d.window)
+			return
+		}
+	}
+
+	// Re-read the file so keys edited elsewhere since the dialog opened are
+	// kept; a file that no longer parses is not overwritten.
+	file, err := d.load()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("%s could not be read and was not changed: %w", d.config.Path, err), d.window)
+		return
+	}
+
+	var urls []string
+	for _, url := range strings.Split(d.ocrEntry.Text, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	file.OCR.URLs = urls
+	file.Screencast.Quality, _ = strconv.Atoi(d.qualityEntry.Text)
+	file.Screencast.MaxFPS, _ = strconv.Atoi(d.fpsEntry.Text)
+	file.Screencast.StartDelay = d.delayEntry.Text
+	file.Screencast.IdlePause = d.idleEntry.Text
+	file.Scenes.Threshold, _ = strconv.ParseFloat(d.thresholdEntry.Text, 64)
+	file.Login.URLTemplate = strings.TrimSpace(d.loginURLEntry.Text)
+	headless := d.headlessCheck.Checked
+	file.Browser.Headless = &headless
+	desktop := d.desktopCheck.Checked
+	file.Notify.Desktop = &desktop
+
+	if err := config.Save(d.config.Path, file); err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+	d.config.Logger.Info("Preferences saved", "path", d.config.Path)
+
+	if d.config.OnSaved != nil {
+		d.config.OnSaved(file)
+	}
+	d.window.Close()
+}
+
+// formatOptionalInt formats n, leaving zero (unset) empty.
+func formatOptionalInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
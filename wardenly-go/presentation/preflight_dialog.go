@@ -0,0 +1,54 @@
+package presentation
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/application"
+)
+
+// ShowPreflightDialog shows the preflight checklist of a group run.
+// onRun is called when the user confirms; a blocked report can only be closed.
+func ShowPreflightDialog(groupName string, report *application.PreflightReport, window fyne.Window, onRun func()) {
+	rows := container.NewVBox()
+	for _, check := range report.Checks {
+		detail := widget.NewLabel(check.Detail)
+		detail.Wrapping = fyne.TextWrapWord
+		rows.Add(container.NewBorder(nil, nil,
+			container.NewHBox(
+				widget.NewIcon(preflightIcon(check.Status)),
+				widget.NewLabelWithStyle(check.Name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			),
+			nil, detail))
+	}
+	content := container.NewVScroll(rows)
+	content.SetMinSize(fyne.NewSize(480, 200))
+
+	title := "Run " + groupName
+	if report.Blocked() {
+		d := dialog.NewCustom(title+": fix the failed checks first", "Close", content, window)
+		d.Show()
+		return
+	}
+	d := dialog.NewCustomConfirm(title, "Run", "Cancel", content, func(confirmed bool) {
+		if confirmed {
+			onRun()
+		}
+	}, window)
+	d.Show()
+}
+
+// preflightIcon returns the checklist icon of a check status.
+func preflightIcon(status application.PreflightStatus) fyne.Resource {
+	switch status {
+	case application.PreflightFailed:
+		return theme.NewErrorThemedResource(theme.ErrorIcon())
+	case application.PreflightWarning:
+		return theme.NewWarningThemedResource(theme.WarningIcon())
+	default:
+		return theme.NewSuccessThemedResource(theme.ConfirmIcon())
+	}
+}
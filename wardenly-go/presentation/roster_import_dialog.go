@@ -0,0 +1,153 @@
+package presentation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/domain/account"
+	"wardenly-go/domain/group"
+)
+
+// rosterReadTimeout bounds a single page capture + OCR round trip.
+const rosterReadTimeout = 30 * time.Second
+
+// RosterImportDialogConfig holds configuration for the roster import dialog.
+type RosterImportDialogConfig struct {
+	Accounts []*account.Account
+	// ServerID restricts matches to the reference session's server (0 = any)
+	ServerID int
+	// ReadPage captures and OCRs the reference session's current screen
+	ReadPage func(ctx context.Context) ([]string, error)
+	// OnApply receives the matched account IDs
+	OnApply func(accountIDs []string)
+	Logger  *slog.Logger
+}
+
+// rosterImportDialog collects guild roster pages and matches them to accounts.
+type rosterImportDialog struct {
+	config *RosterImportDialogConfig
+	window fyne.Window
+
+	lines []string
+	match *group.RosterMatch
+
+	statusLabel    *widget.Label
+	matchedList    *widget.List
+	unmatchedLabel *widget.Label
+	captureBtn     *widget.Button
+	applyBtn       *widget.Button
+}
+
+// ShowRosterImportDialog opens a window that captures guild member list pages
+// on a reference session and proposes group members from the matches.
+func ShowRosterImportDialog(cfg *RosterImportDialogConfig) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	d := &rosterImportDialog{
+		config: cfg,
+		match:  &group.RosterMatch{},
+	}
+
+	d.window = fyne.CurrentApp().NewWindow("Import Guild Roster")
+	d.buildUI()
+
+	d.window.Resize(fyne.NewSize(480, 520))
+	d.window.CenterOnScreen()
+	d.window.Show()
+}
+
+func (d *rosterImportDialog) buildUI() {
+	hint := widget.NewLabel("Open the guild member list in the selected session, then capture each page.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	d.statusLabel = widget.NewLabel("No pages captured")
+
+	d.matchedList = widget.NewList(
+		func() int { return len(d.match.Matched) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(d.match.Matched[id].Identity())
+		},
+	)
+
+	d.unmatchedLabel = widget.NewLabel("")
+	d.unmatchedLabel.Wrapping = fyne.TextWrapWord
+
+	d.captureBtn = widget.NewButtonWithIcon("Capture Page", theme.ContentAddIcon(), d.capturePage)
+	clearBtn := widget.NewButtonWithIcon("Clear", theme.ContentClearIcon(), func() {
+		d.lines = nil
+		d.refreshMatch(0)
+	})
+	d.applyBtn = widget.NewButtonWithIcon("Use Matches", theme.ConfirmIcon(), func() {
+		d.config.OnApply(d.match.AccountIDs())
+		d.window.Close()
+	})
+	d.applyBtn.Importance = widget.HighImportance
+	d.applyBtn.Disable()
+
+	top := container.NewVBox(hint, container.NewHBox(d.captureBtn, clearBtn), d.statusLabel,
+		widget.NewLabelWithStyle("Matched Accounts", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	bottom := container.NewVBox(
+		widget.NewLabelWithStyle("Unmatched Names", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		d.unmatchedLabel,
+		widget.NewSeparator(),
+		container.NewHBox(layout.NewSpacer(), d.applyBtn),
+	)
+
+	d.window.SetContent(container.NewPadded(container.NewBorder(top, bottom, nil, nil, d.matchedList)))
+}
+
+// capturePage reads one roster page off the UI thread and merges its lines.
+func (d *rosterImportDialog) capturePage() {
+	d.captureBtn.Disable()
+	d.statusLabel.SetText("Reading roster...")
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), rosterReadTimeout)
+		defer cancel()
+		lines, err := d.config.ReadPage(ctx)
+
+		fyne.Do(func() {
+			d.captureBtn.Enable()
+			if err != nil {
+				d.config.Logger.Warn("Failed to read roster page", "error", err)
+				d.statusLabel.SetText("Capture failed")
+				dialog.ShowError(err, d.window)
+				return
+			}
+			d.lines = append(d.lines, lines...)
+			d.refreshMatch(len(lines))
+		})
+	}()
+}
+
+func (d *rosterImportDialog) refreshMatch(newLines int) {
+	d.match = group.MatchRoster(d.lines, d.config.Accounts, d.config.ServerID)
+
+	if len(d.lines) == 0 {
+		d.statusLabel.SetText("No pages captured")
+	} else {
+		d.statusLabel.SetText(fmt.Sprintf("Read %d lines (%d new), %d accounts matched",
+			len(d.lines), newLines, len(d.match.Matched)))
+	}
+	d.unmatchedLabel.SetText(strings.Join(d.match.Unmatched, ", "))
+	d.matchedList.Refresh()
+
+	if len(d.match.Matched) > 0 {
+		d.applyBtn.Enable()
+	} else {
+		d.applyBtn.Disable()
+	}
+}
@@ -0,0 +1,121 @@
+package presentation
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+
+	"wardenly-go/application/session"
+)
+
+// Scene overlay layout.
+const (
+	// maxNearScenes is how many unmatched scenes the overlay lists after
+	// the matched ones, closest first
+	maxNearScenes   = 3
+	overlayRadius   = 5
+	overlayTextSize = 12
+	overlayLineGap  = 16
+)
+
+var (
+	overlayMatchColor = color.NRGBA{R: 64, G: 220, B: 96, A: 255}
+	overlayMissColor  = color.NRGBA{R: 255, G: 72, B: 72, A: 255}
+	overlayBackColor  = color.NRGBA{A: 160}
+)
+
+// overlayChecks picks the scenes the overlay shows: every matched scene
+// and the closest unmatched ones. checks are ordered as returned by
+// Session.CheckScenes.
+func overlayChecks(checks []session.SceneCheck) []session.SceneCheck {
+	var shown []session.SceneCheck
+	near := 0
+	for _, check := range checks {
+		if !check.Matched {
+			if near == maxNearScenes {
+				break
+			}
+			near++
+		}
+		shown = append(shown, check)
+	}
+	return shown
+}
+
+// overlayLine describes how a scene compares against the frame.
+func overlayLine(check *session.SceneCheck) string {
+	mark := "✗"
+	if check.Matched {
+		mark = "✓"
+	}
+	if check.Template != nil {
+		return fmt.Sprintf("%s %s  score %.2f / %.2f", mark, check.Name, check.Template.Score, check.Template.Threshold)
+	}
+	line := fmt.Sprintf("%s %s  diff %.1f / %.1f", mark, check.Name, check.AvgDiff, check.Threshold)
+	if off := missedPoints(check); off > 0 {
+		line += fmt.Sprintf(" · %d/%d points off", off, len(check.Points))
+	}
+	return line
+}
+
+// missedPoints counts the scene points whose diff exceeds the threshold.
+func missedPoints(check *session.SceneCheck) int {
+	n := 0
+	for _, p := range check.Points {
+		if !p.Matched {
+			n++
+		}
+	}
+	return n
+}
+
+func overlayColor(matched bool) color.Color {
+	if matched {
+		return overlayMatchColor
+	}
+	return overlayMissColor
+}
+
+// overlayObjects draws the shown scenes: a ring per point and a frame per
+// template placement, green where they match and red where they don't,
+// and a list of the scenes with their diffs below the caption line.
+func overlayObjects(checks []session.SceneCheck) []fyne.CanvasObject {
+	var marks, lines []fyne.CanvasObject
+	var width float32
+	for i := range checks {
+		check := &checks[i]
+		for _, p := range check.Points {
+			ring := canvas.NewCircle(color.Transparent)
+			ring.StrokeColor = overlayColor(p.Matched)
+			ring.StrokeWidth = 2
+			ring.Resize(fyne.NewSquareSize(2 * overlayRadius))
+			ring.Move(fyne.NewPos(float32(p.X)-overlayRadius, float32(p.Y)-overlayRadius))
+			marks = append(marks, ring)
+		}
+		if t := check.Template; t != nil {
+			box := canvas.NewRectangle(color.Transparent)
+			box.StrokeColor = overlayColor(check.Matched)
+			box.StrokeWidth = 2
+			box.Resize(fyne.NewSize(float32(t.Max.X-t.Min.X), float32(t.Max.Y-t.Min.Y)))
+			box.Move(fyne.NewPos(float32(t.Min.X), float32(t.Min.Y)))
+			marks = append(marks, box)
+		}
+
+		text := canvas.NewText(overlayLine(check), overlayColor(check.Matched))
+		text.TextSize = overlayTextSize
+		text.Move(fyne.NewPos(12, float32(26+i*overlayLineGap)))
+		width = max(width, text.MinSize().Width)
+		lines = append(lines, text)
+	}
+	if len(lines) == 0 {
+		return marks
+	}
+
+	back := canvas.NewRectangle(overlayBackColor)
+	back.CornerRadius = 4
+	back.Move(fyne.NewPos(8, 24))
+	back.Resize(fyne.NewSize(width+8, float32(len(lines)*overlayLineGap+4)))
+	return append(append(marks, back), lines...)
+}
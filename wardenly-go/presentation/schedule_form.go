@@ -0,0 +1,258 @@
+package presentation
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/domain/account"
+	"wardenly-go/domain/group"
+	"wardenly-go/domain/schedule"
+)
+
+// Target type choices shown in the schedule form.
+const (
+	scheduleTargetAccount = "Account"
+	scheduleTargetGroup   = "Group"
+)
+
+// ScheduleFormConfig holds configuration for ScheduleForm.
+type ScheduleFormConfig struct {
+	ScriptNames []string
+	OnSave      func(*schedule.Schedule)
+	OnDelete    func(*schedule.Schedule)
+}
+
+// ScheduleForm provides a form for editing a schedule.
+type ScheduleForm struct {
+	config    *ScheduleFormConfig
+	container *fyne.Container
+
+	// Form fields
+	nameEntry    *widget.Entry
+	targetType   *widget.RadioGroup
+	targetSelect *widget.Select
+	scriptSelect *widget.Select
+	specEntry    *widget.Entry
+	nextLabel    *widget.Label
+	enabledCheck *widget.Check
+	lastRunLabel *widget.Label
+
+	// Buttons
+	saveBtn   *widget.Button
+	deleteBtn *widget.Button
+
+	// Target choices
+	accounts []*account.Account
+	groups   []*group.Group
+
+	// Current schedule being edited
+	current *schedule.Schedule
+}
+
+// NewScheduleForm creates a new schedule editing form.
+func NewScheduleForm(cfg *ScheduleFormConfig) *ScheduleForm {
+	sf := &ScheduleForm{config: cfg}
+	sf.build()
+	return sf
+}
+
+func (sf *ScheduleForm) build() {
+	sf.nameEntry = widget.NewEntry()
+	sf.nameEntry.SetPlaceHolder("Schedule name")
+
+	sf.targetSelect = widget.NewSelect(nil, nil)
+	sf.targetSelect.PlaceHolder = "Select target"
+	sf.targetType = widget.NewRadioGroup([]string{scheduleTargetAccount, scheduleTargetGroup}, func(string) {
+		sf.refreshTargets("")
+	})
+	sf.targetType.Horizontal = true
+	sf.targetType.Required = true
+
+	sf.scriptSelect = widget.NewSelect(sf.config.ScriptNames, nil)
+	sf.scriptSelect.PlaceHolder = "Select script"
+
+	sf.specEntry = widget.NewEntry()
+	sf.specEntry.SetPlaceHolder("daily 04:30 or cron: 30 4 * * 1-5")
+	sf.specEntry.Validator = func(s string) error {
+		_, err := schedule.ParseSpec(s)
+		return err
+	}
+	sf.nextLabel = widget.NewLabel("")
+	sf.specEntry.OnChanged = func(string) { sf.refreshNextRun() }
+
+	sf.enabledCheck = widget.NewCheck("Enabled", nil)
+	sf.lastRunLabel = widget.NewLabel("")
+
+	// Use widget.Form for proper alignment
+	form := widget.NewForm(
+		widget.NewFormItem("Name", sf.nameEntry),
+		widget.NewFormItem("Target", container.NewVBox(sf.targetType, sf.targetSelect)),
+		widget.NewFormItem("Script", sf.scriptSelect),
+		widget.NewFormItem("Schedule", sf.specEntry),
+		widget.NewFormItem("Next Run", sf.nextLabel),
+		widget.NewFormItem("", sf.enabledCheck),
+		widget.NewFormItem("Last Run", sf.lastRunLabel),
+	)
+
+	// Buttons with icons - Delete on left, Save on right
+	sf.deleteBtn = widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), sf.onDelete)
+	sf.deleteBtn.Importance = widget.DangerImportance
+
+	sf.saveBtn = widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), sf.onSave)
+	sf.saveBtn.Importance = widget.HighImportance
+
+	buttonBar := container.NewHBox(
+		sf.deleteBtn,
+		layout.NewSpacer(),
+		sf.saveBtn,
+	)
+
+	sf.container = container.NewPadded(container.NewBorder(
+		nil,
+		container.NewVBox(widget.NewSeparator(), buttonBar),
+		nil, nil,
+		container.NewVScroll(form),
+	))
+}
+
+// Container returns the form container.
+func (sf *ScheduleForm) Container() fyne.CanvasObject {
+	return sf.container
+}
+
+// SetSchedule populates the form with schedule data.
+// Pass nil to clear the form for creating a new schedule.
+// accounts and groups are the available targets.
+func (sf *ScheduleForm) SetSchedule(sch *schedule.Schedule, accounts []*account.Account, groups []*group.Group) {
+	sf.current = sch
+	sf.accounts = accounts
+	sf.groups = groups
+
+	if sch == nil {
+		sf.nameEntry.SetText("")
+		sf.targetType.SetSelected(scheduleTargetGroup)
+		sf.refreshTargets("")
+		sf.scriptSelect.ClearSelected()
+		sf.specEntry.SetText("")
+		sf.enabledCheck.SetChecked(true)
+		sf.lastRunLabel.SetText("Never")
+		sf.deleteBtn.Disable()
+		return
+	}
+
+	sf.nameEntry.SetText(sch.Name)
+	if sch.TargetType == schedule.TargetAccount {
+		sf.targetType.SetSelected(scheduleTargetAccount)
+	} else {
+		sf.targetType.SetSelected(scheduleTargetGroup)
+	}
+	sf.refreshTargets(sch.TargetID)
+	sf.scriptSelect.SetSelected(sch.ScriptName)
+	sf.specEntry.SetText(sch.Spec)
+	sf.enabledCheck.SetChecked(sch.Enabled)
+	if sch.LastRunAt.IsZero() {
+		sf.lastRunLabel.SetText("Never")
+	} else {
+		sf.lastRunLabel.SetText(sch.LastRunAt.Local().Format("2006-01-02 15:04"))
+	}
+	sf.deleteBtn.Enable()
+}
+
+// refreshTargets fills the target select for the chosen type and selects targetID.
+func (sf *ScheduleForm) refreshTargets(targetID string) {
+	var options []string
+	selected := ""
+	if sf.targetType.Selected == scheduleTargetAccount {
+		for _, acc := range sf.accounts {
+			// An archived account is only listed if it is already the target
+			if acc.Archived && acc.ID != targetID {
+				continue
+			}
+			options = append(options, acc.Identity())
+			if acc.ID == targetID {
+				selected = acc.Identity()
+			}
+		}
+	} else {
+		for _, grp := range sf.groups {
+			options = append(options, grp.Name)
+			if grp.ID == targetID {
+				selected = grp.Name
+			}
+		}
+	}
+
+	sf.targetSelect.Options = options
+	if selected != "" {
+		sf.targetSelect.SetSelected(selected)
+	} else {
+		sf.targetSelect.ClearSelected()
+	}
+	sf.targetSelect.Refresh()
+}
+
+func (sf *ScheduleForm) refreshNextRun() {
+	spec, err := schedule.ParseSpec(sf.specEntry.Text)
+	if err != nil {
+		sf.nextLabel.SetText("-")
+		return
+	}
+	next := spec.Next(time.Now())
+	if next.IsZero() {
+		sf.nextLabel.SetText("Never")
+		return
+	}
+	sf.nextLabel.SetText(next.Format("2006-01-02 15:04 (Mon)"))
+}
+
+// selectedTarget returns the type and ID of the selected target.
+func (sf *ScheduleForm) selectedTarget() (schedule.TargetType, string) {
+	if sf.targetType.Selected == scheduleTargetAccount {
+		for _, acc := range sf.accounts {
+			if acc.Identity() == sf.targetSelect.Selected {
+				return schedule.TargetAccount, acc.ID
+			}
+		}
+		return schedule.TargetAccount, ""
+	}
+	for _, grp := range sf.groups {
+		if grp.Name == sf.targetSelect.Selected {
+			return schedule.TargetGroup, grp.ID
+		}
+	}
+	return schedule.TargetGroup, ""
+}
+
+func (sf *ScheduleForm) onSave() {
+	targetType, targetID := sf.selectedTarget()
+
+	sch := &schedule.Schedule{
+		Name:       sf.nameEntry.Text,
+		TargetType: targetType,
+		TargetID:   targetID,
+		ScriptName: sf.scriptSelect.Selected,
+		Spec:       sf.specEntry.Text,
+		Enabled:    sf.enabledCheck.Checked,
+	}
+
+	// Preserve ID and run history if editing
+	if sf.current != nil {
+		sch.ID = sf.current.ID
+		sch.LastRunAt = sf.current.LastRunAt
+	}
+
+	if sf.config.OnSave != nil {
+		sf.config.OnSave(sch)
+	}
+}
+
+func (sf *ScheduleForm) onDelete() {
+	if sf.current != nil && sf.config.OnDelete != nil {
+		sf.config.OnDelete(sf.current)
+	}
+}
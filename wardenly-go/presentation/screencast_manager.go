@@ -10,14 +10,18 @@ import (
 // ScreencastManager manages screencast lifecycle for auto-refresh.
 // All public methods must be called from the UI thread (via fyne.Do).
 // It handles:
-// - Delayed screencast start after driver startup (1s delay)
+// - Delayed screencast start after driver startup (1s by default)
 // - Tab switching (stop old, start new)
 // - Session removal cleanup
 // - Auto-refresh toggle
 // - Ack-based streaming state (via ScreencastStarted/ScreencastStopped events)
+// - Pausing the stream while a script runs unattended (see SetIdlePause)
 type ScreencastManager struct {
-	bridge *UIEventBridge
-	logger *slog.Logger
+	bridge     *UIEventBridge
+	logger     *slog.Logger
+	quality    int
+	maxFPS     int
+	startDelay time.Duration
 
 	// State (all access must be on UI thread)
 	autoRefreshEnabled bool
@@ -29,12 +33,39 @@ type ScreencastManager struct {
 	pendingTimer     *time.Timer
 	pendingSessionID string
 	pendingGen       uint64 // monotonic token to invalidate stale timer callbacks
+
+	// Idle pause of the active session's stream
+	idlePause      time.Duration
+	scriptsRunning map[string]bool
+	idleTimer      *time.Timer
+	idleGen        uint64
+	paused         bool
+	onPause        func(sessionID string, paused bool)
 }
 
+// Default screencast settings, used when the config leaves them zero.
+const (
+	DefaultScreencastQuality    = 80
+	DefaultScreencastMaxFPS     = 5
+	DefaultScreencastStartDelay = time.Second
+)
+
 // ScreencastManagerConfig holds configuration for ScreencastManager.
 type ScreencastManagerConfig struct {
 	Bridge *UIEventBridge
 	Logger *slog.Logger
+	// Quality is the JPEG quality of streamed frames (1-100)
+	Quality int
+	// MaxFPS caps the streamed frames per second
+	MaxFPS int
+	// StartDelay is how long after a driver starts streaming begins
+	StartDelay time.Duration
+	// IdlePause pauses the stream once a script has run this long without
+	// user interaction (disabled if zero)
+	IdlePause time.Duration
+	// OnPause is called when the active session's stream is paused or
+	// resumed by the idle policy; optional
+	OnPause func(sessionID string, paused bool)
 }
 
 // NewScreencastManager creates a new ScreencastManager.
@@ -43,11 +74,94 @@ func NewScreencastManager(cfg *ScreencastManagerConfig) *ScreencastManager {
 		cfg.Logger = slog.Default()
 	}
 
-	return &ScreencastManager{
+	m := &ScreencastManager{
 		bridge:          cfg.Bridge,
 		logger:          cfg.Logger,
 		driverStartedAt: make(map[string]time.Time),
+		scriptsRunning:  make(map[string]bool),
+		idlePause:       cfg.IdlePause,
+		onPause:         cfg.OnPause,
+	}
+	m.SetStreamSettings(cfg.Quality, cfg.MaxFPS, cfg.StartDelay)
+	return m
+}
+
+// SetStreamSettings changes the quality, frame rate and start delay of
+// later streams; zero values select the defaults. A running stream keeps
+// its settings until it is restarted.
+// Must be called from UI thread.
+func (m *ScreencastManager) SetStreamSettings(quality, maxFPS int, startDelay time.Duration) {
+	if quality <= 0 {
+		quality = DefaultScreencastQuality
+	}
+	if maxFPS <= 0 {
+		maxFPS = DefaultScreencastMaxFPS
+	}
+	if startDelay <= 0 {
+		startDelay = DefaultScreencastStartDelay
 	}
+	m.quality = quality
+	m.maxFPS = maxFPS
+	m.startDelay = startDelay
+}
+
+// StartDelay returns how long after a driver starts streaming begins.
+func (m *ScreencastManager) StartDelay() time.Duration {
+	return m.startDelay
+}
+
+// SetIdlePause changes how long a script must run without user interaction
+// before the active session's stream is paused; zero disables pausing and
+// resumes a paused stream.
+// Must be called from UI thread.
+func (m *ScreencastManager) SetIdlePause(d time.Duration) {
+	m.idlePause = d
+	if d <= 0 {
+		m.resume()
+		m.cancelIdle()
+		return
+	}
+	if !m.paused {
+		m.armIdle()
+	}
+}
+
+// IsPaused reports whether the active session's stream is paused by the
+// idle policy.
+// Must be called from UI thread.
+func (m *ScreencastManager) IsPaused() bool {
+	return m.paused
+}
+
+// OnScriptStarted starts the idle countdown if the session is shown.
+// Must be called from UI thread.
+func (m *ScreencastManager) OnScriptStarted(sessionID string) {
+	m.scriptsRunning[sessionID] = true
+	if sessionID == m.activeSessionID && !m.paused {
+		m.armIdle()
+	}
+}
+
+// OnScriptStopped resumes a stream paused while the script ran, so the
+// user sees where it ended.
+// Must be called from UI thread.
+func (m *ScreencastManager) OnScriptStopped(sessionID string) {
+	delete(m.scriptsRunning, sessionID)
+	if sessionID == m.activeSessionID {
+		m.cancelIdle()
+		m.resume()
+	}
+}
+
+// OnUserInteraction is called when the user clicks, drags, scrolls or types
+// on the canvas. It resumes a paused stream and restarts the idle countdown.
+// Must be called from UI thread.
+func (m *ScreencastManager) OnUserInteraction(sessionID string) {
+	if sessionID != m.activeSessionID {
+		return
+	}
+	m.resume()
+	m.armIdle()
 }
 
 // SetAutoRefreshEnabled enables or disables auto-refresh mode.
@@ -56,9 +170,12 @@ func NewScreencastManager(cfg *ScreencastManagerConfig) *ScreencastManager {
 // Must be called from UI thread.
 func (m *ScreencastManager) SetAutoRefreshEnabled(enabled bool) {
 	m.autoRefreshEnabled = enabled
+	m.cancelIdle()
+	m.setPaused(false)
 
 	if enabled {
 		m.startAutoRefreshForActiveSession()
+		m.armIdle()
 	} else {
 		m.stopAutoRefresh()
 	}
@@ -68,11 +185,22 @@ func (m *ScreencastManager) SetAutoRefreshEnabled(enabled bool) {
 // Handles screencast switching if auto-refresh is enabled.
 // Must be called from UI thread.
 func (m *ScreencastManager) SetActiveSession(sessionID string) {
+	previous := m.activeSessionID
 	m.activeSessionID = sessionID
 
 	// Cancel any pending start (for any session)
 	m.cancelPending()
 
+	// The idle countdown follows the shown session
+	m.cancelIdle()
+	if m.paused {
+		m.paused = false
+		if m.onPause != nil {
+			m.onPause(previous, false)
+		}
+	}
+	defer m.armIdle()
+
 	if !m.autoRefreshEnabled || sessionID == "" {
 		return
 	}
@@ -104,8 +232,8 @@ func (m *ScreencastManager) OnDriverStarted(sessionID string) {
 		return
 	}
 
-	// Schedule screencast start after 1 second
-	m.scheduleStart(sessionID, 1*time.Second)
+	// Schedule screencast start once the page has settled
+	m.scheduleStart(sessionID, m.startDelay)
 }
 
 // OnScreencastStarted is called when screencast actually starts (ack from Session).
@@ -140,12 +268,18 @@ func (m *ScreencastManager) OnSessionRemoved(sessionID string) {
 
 	// Cleanup driver start time
 	delete(m.driverStartedAt, sessionID)
+	delete(m.scriptsRunning, sessionID)
+	if sessionID == m.activeSessionID {
+		m.cancelIdle()
+		m.paused = false
+	}
 }
 
 // Close stops any active screencast and cleans up.
 // Must be called from UI thread.
 func (m *ScreencastManager) Close() {
 	m.cancelPending()
+	m.cancelIdle()
 
 	if m.streamingSessionID != "" {
 		if err := m.bridge.StopScreencast(m.streamingSessionID); err != nil {
@@ -211,7 +345,7 @@ func (m *ScreencastManager) handleTimerFired(sessionID string, gen uint64) {
 }
 
 func (m *ScreencastManager) requestStart(sessionID string) {
-	if err := m.bridge.StartScreencast(sessionID, 80, 5); err != nil {
+	if err := m.bridge.StartScreencast(sessionID, m.quality, m.maxFPS); err != nil {
 		m.logger.Error("Failed to start screencast", "session_id", sessionID, "error", err)
 	} else {
 		m.logger.Info("Screencast started", "session_id", sessionID)
@@ -259,3 +393,69 @@ func (m *ScreencastManager) stopAutoRefresh() {
 		// streamingSessionID will be cleared by OnScreencastStopped callback
 	}
 }
+
+// armIdle (re)starts the idle countdown of the active session if it is
+// streaming a running script and the policy is enabled.
+func (m *ScreencastManager) armIdle() {
+	m.cancelIdle()
+	sessionID := m.activeSessionID
+	if m.idlePause <= 0 || !m.autoRefreshEnabled || sessionID == "" || !m.scriptsRunning[sessionID] {
+		return
+	}
+	gen := m.idleGen
+	m.idleTimer = time.AfterFunc(m.idlePause, func() {
+		fyne.Do(func() {
+			m.handleIdle(sessionID, gen)
+		})
+	})
+}
+
+func (m *ScreencastManager) cancelIdle() {
+	if m.idleTimer != nil {
+		m.idleTimer.Stop()
+		m.idleTimer = nil
+	}
+	m.idleGen++
+}
+
+// handleIdle pauses the stream once the countdown runs out.
+func (m *ScreencastManager) handleIdle(sessionID string, gen uint64) {
+	if m.idleGen != gen || m.activeSessionID != sessionID {
+		return
+	}
+	m.idleTimer = nil
+	if !m.autoRefreshEnabled || !m.scriptsRunning[sessionID] || m.paused {
+		return
+	}
+
+	m.logger.Info("Pausing screencast of unattended script", "session_id", sessionID, "idle", m.idlePause)
+	m.cancelPending()
+	if m.streamingSessionID != "" {
+		m.requestStop(m.streamingSessionID)
+	}
+	m.setPaused(true)
+}
+
+// resume restarts a stream paused by the idle policy.
+func (m *ScreencastManager) resume() {
+	if !m.paused {
+		return
+	}
+	m.setPaused(false)
+	m.logger.Info("Resuming screencast", "session_id", m.activeSessionID)
+	// Start even if the stop is not acknowledged yet; the session handles
+	// the two requests in order
+	if _, driverReady := m.driverStartedAt[m.activeSessionID]; m.autoRefreshEnabled && driverReady {
+		m.requestStart(m.activeSessionID)
+	}
+}
+
+func (m *ScreencastManager) setPaused(paused bool) {
+	if m.paused == paused {
+		return
+	}
+	m.paused = paused
+	if m.onPause != nil && m.activeSessionID != "" {
+		m.onPause(m.activeSessionID, paused)
+	}
+}
@@ -0,0 +1,79 @@
+package presentation
+
+import (
+	"testing"
+	"time"
+
+	"wardenly-go/application"
+)
+
+func TestScreencastManager_IdlePause(t *testing.T) {
+	coord := application.NewCoordinator(&application.CoordinatorConfig{})
+	defer coord.Stop()
+
+	var changes []bool
+	m := NewScreencastManager(&ScreencastManagerConfig{
+		Bridge:    NewUIEventBridge(&BridgeConfig{Coordinator: coord}),
+		IdlePause: time.Hour,
+		OnPause: func(sessionID string, paused bool) {
+			if sessionID != "s1" {
+				t.Errorf("OnPause(%s), want s1", sessionID)
+			}
+			changes = append(changes, paused)
+		},
+	})
+	defer m.Close()
+
+	m.driverStartedAt["s1"] = time.Now()
+	m.SetActiveSession("s1")
+	m.SetAutoRefreshEnabled(true)
+	m.OnScreencastStarted("s1")
+
+	// Without a running script the countdown never starts
+	if m.idleTimer != nil {
+		t.Fatal("idle countdown started without a script")
+	}
+	m.OnScriptStarted("s1")
+	if m.idleTimer == nil {
+		t.Fatal("idle countdown not started for a running script")
+	}
+
+	m.handleIdle("s1", m.idleGen)
+	if !m.IsPaused() {
+		t.Fatal("stream not paused once the countdown ran out")
+	}
+
+	// Interaction with another session's canvas doesn't count
+	m.OnUserInteraction("s2")
+	if !m.IsPaused() {
+		t.Error("interaction with another session resumed the stream")
+	}
+	m.OnUserInteraction("s1")
+	if m.IsPaused() || m.idleTimer == nil {
+		t.Error("interaction should resume the stream and restart the countdown")
+	}
+
+	// A stale countdown is ignored
+	stale := m.idleGen
+	m.OnUserInteraction("s1")
+	m.handleIdle("s1", stale)
+	if m.IsPaused() {
+		t.Error("a restarted countdown should invalidate the previous one")
+	}
+
+	m.handleIdle("s1", m.idleGen)
+	m.OnScriptStopped("s1")
+	if m.IsPaused() || m.idleTimer != nil {
+		t.Error("the stream should resume without a countdown once the script stops")
+	}
+
+	want := []bool{true, false, true, false}
+	if len(changes) != len(want) {
+		t.Fatalf("pause changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("pause changes = %v, want %v", changes, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package presentation
+
+import (
+	"log/slog"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/domain/script"
+)
+
+// ScriptDocDialogConfig holds configuration for the script documentation dialog.
+type ScriptDocDialogConfig struct {
+	Registry    *script.Registry
+	ScriptNames []string
+	// Selected is the script shown first (optional)
+	Selected string
+	Logger   *slog.Logger
+}
+
+// scriptDocDialog shows the generated documentation of a script.
+type scriptDocDialog struct {
+	config *ScriptDocDialogConfig
+	window fyne.Window
+
+	scriptSelect *widget.Select
+	docText      *widget.RichText
+	docScroll    *container.Scroll
+	exportBtn    *widget.Button
+
+	markdown string
+}
+
+// ShowScriptDocDialog displays a script's steps, scenes, OCR rules, loops and
+// limits, with an export to Markdown.
+func ShowScriptDocDialog(cfg *ScriptDocDialogConfig) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	d := &scriptDocDialog{config: cfg}
+
+	d.window = fyne.CurrentApp().NewWindow("Script Documentation")
+	d.buildUI()
+	if cfg.Selected != "" {
+		d.scriptSelect.SetSelected(cfg.Selected)
+	}
+
+	d.window.Resize(fyne.NewSize(640, 560))
+	d.window.CenterOnScreen()
+	d.window.Show()
+}
+
+func (d *scriptDocDialog) buildUI() {
+	d.scriptSelect = widget.NewSelect(d.config.ScriptNames, d.loadScript)
+	d.scriptSelect.PlaceHolder = "Select Script"
+
+	d.docText = widget.NewRichTextFromMarkdown("")
+	d.docText.Wrapping = fyne.TextWrapWord
+	d.docScroll = container.NewVScroll(d.docText)
+
+	d.exportBtn = widget.NewButtonWithIcon("Export...", theme.DocumentSaveIcon(), d.export)
+	d.exportBtn.Disable()
+
+	top := container.NewBorder(nil, nil, nil, d.exportBtn, d.scriptSelect)
+	d.window.SetContent(container.NewBorder(top, nil, nil, nil, d.docScroll))
+}
+
+func (d *scriptDocDialog) loadScript(name string) {
+	s := d.config.Registry.Get(name)
+	if s == nil {
+		d.markdown = ""
+		d.docText.ParseMarkdown("Script not loaded.")
+		d.exportBtn.Disable()
+		return
+	}
+
+	d.markdown = s.Markdown()
+	d.docText.ParseMarkdown(d.markdown)
+	d.docScroll.ScrollToTop()
+	d.exportBtn.Enable()
+}
+
+// export saves the shown documentation as a Markdown file.
+func (d *scriptDocDialog) export() {
+	if d.markdown == "" {
+		return
+	}
+	markdown := d.markdown
+	save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil || w == nil {
+			return
+		}
+		defer w.Close()
+
+		if _, err := w.Write([]byte(markdown)); err != nil {
+			d.config.Logger.Error("Script documentation export failed", "file", w.URI().Path(), "error", err)
+			dialog.ShowError(err, d.window)
+		}
+	}, d.window)
+	save.SetFileName(d.scriptSelect.Selected + ".md")
+	save.SetFilter(storage.NewExtensionFileFilter([]string{".md"}))
+	save.Show()
+}
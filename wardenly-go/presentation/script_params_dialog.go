@@ -0,0 +1,52 @@
+package presentation
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/domain/script"
+)
+
+// ShowScriptParamsDialog asks the user for the values of a script's prompts.
+// Entries are prefilled from remembered values, falling back to prompt defaults.
+// onSubmit is only called when every value passes its prompt's type check.
+func ShowScriptParamsDialog(s *script.Script, remembered map[string]string, window fyne.Window, onSubmit func(params map[string]string)) {
+	entries := make([]*widget.Entry, len(s.Prompts))
+	items := make([]*widget.FormItem, len(s.Prompts))
+
+	for i := range s.Prompts {
+		p := &s.Prompts[i]
+
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder(string(p.Type))
+		if v, ok := remembered[p.Key]; ok {
+			entry.SetText(v)
+		} else {
+			entry.SetText(p.Default)
+		}
+		entry.Validator = func(text string) error {
+			_, err := p.Parse(text)
+			return err
+		}
+		entries[i] = entry
+
+		label := p.Label
+		if label == "" {
+			label = p.Key
+		}
+		items[i] = widget.NewFormItem(label, entry)
+	}
+
+	dialog.ShowForm("Start "+s.Name, "Start", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		params := make(map[string]string, len(entries))
+		for i, entry := range entries {
+			params[s.Prompts[i].Key] = entry.Text
+		}
+		onSubmit(params)
+	}, window)
+}
@@ -0,0 +1,152 @@
+package presentation
+
+import (
+	"fmt"
+	"log/slog"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/domain/script"
+)
+
+// ScriptVersionsDialogConfig holds configuration for the script versions dialog.
+type ScriptVersionsDialogConfig struct {
+	Versions    *script.VersionService
+	ScriptNames []string
+	Logger      *slog.Logger
+}
+
+// scriptVersionsDialog shows the changelog of a script and rolls it back.
+type scriptVersionsDialog struct {
+	config *ScriptVersionsDialogConfig
+	window fyne.Window
+
+	scriptSelect *widget.Select
+	revisionList *widget.List
+	rollbackBtn  *widget.Button
+	latestBtn    *widget.Button
+
+	scriptName string
+	revisions  []script.Revision
+	active     string
+	selected   int
+}
+
+// ShowScriptVersionsDialog displays the per-script changelog with rollback controls.
+func ShowScriptVersionsDialog(cfg *ScriptVersionsDialogConfig) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	d := &scriptVersionsDialog{
+		config:   cfg,
+		selected: -1,
+	}
+
+	d.window = fyne.CurrentApp().NewWindow("Script Versions")
+	d.buildUI()
+
+	d.window.Resize(fyne.NewSize(520, 420))
+	d.window.CenterOnScreen()
+	d.window.Show()
+}
+
+func (d *scriptVersionsDialog) buildUI() {
+	d.scriptSelect = widget.NewSelect(d.config.ScriptNames, d.loadScript)
+	d.scriptSelect.PlaceHolder = "Select Script"
+
+	d.revisionList = widget.NewList(
+		func() int { return len(d.revisions) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			rev := d.revisions[id]
+			text := fmt.Sprintf("%s  %s", rev.SavedAt.Format("2006-01-02 15:04:05"), rev.ShortHash())
+			if id == 0 {
+				text += "  latest"
+			}
+			if rev.Hash == d.active {
+				text += "  (active)"
+			}
+			obj.(*widget.Label).SetText(text)
+		},
+	)
+	d.revisionList.OnSelected = func(id widget.ListItemID) {
+		d.selected = id
+		d.updateButtons()
+	}
+
+	d.rollbackBtn = widget.NewButton("Roll Back", d.rollback)
+	d.latestBtn = widget.NewButton("Use Latest", d.useLatest)
+	d.updateButtons()
+
+	buttons := container.NewHBox(d.rollbackBtn, d.latestBtn)
+	d.window.SetContent(container.NewBorder(d.scriptSelect, buttons, nil, nil, d.revisionList))
+}
+
+func (d *scriptVersionsDialog) loadScript(name string) {
+	revisions, err := d.config.Versions.Changelog(name)
+	if err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+	active, err := d.config.Versions.Active(name)
+	if err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+
+	d.scriptName = name
+	d.revisions = revisions
+	d.active = active
+	d.selected = -1
+	d.revisionList.UnselectAll()
+	d.revisionList.Refresh()
+	d.updateButtons()
+}
+
+func (d *scriptVersionsDialog) updateButtons() {
+	if d.selected >= 0 && d.selected < len(d.revisions) && d.revisions[d.selected].Hash != d.active {
+		d.rollbackBtn.Enable()
+	} else {
+		d.rollbackBtn.Disable()
+	}
+	if len(d.revisions) > 0 && d.revisions[0].Hash != d.active {
+		d.latestBtn.Enable()
+	} else {
+		d.latestBtn.Disable()
+	}
+}
+
+func (d *scriptVersionsDialog) rollback() {
+	if d.selected < 0 || d.selected >= len(d.revisions) {
+		return
+	}
+	rev := d.revisions[d.selected]
+
+	msg := fmt.Sprintf("Roll back %s to revision %s?\nRunning scripts keep their current version until restarted.", d.scriptName, rev.ShortHash())
+	dialog.ShowConfirm("Roll Back", msg, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if err := d.config.Versions.Rollback(d.scriptName, rev.Hash); err != nil {
+			d.config.Logger.Error("Failed to roll back script", "script", d.scriptName, "revision", rev.ShortHash(), "error", err)
+			dialog.ShowError(err, d.window)
+			return
+		}
+		d.config.Logger.Info("Script rolled back", "script", d.scriptName, "revision", rev.ShortHash())
+		d.loadScript(d.scriptName)
+	}, d.window)
+}
+
+func (d *scriptVersionsDialog) useLatest() {
+	if err := d.config.Versions.UseLatest(d.scriptName); err != nil {
+		d.config.Logger.Error("Failed to restore latest script", "script", d.scriptName, "error", err)
+		dialog.ShowError(err, d.window)
+		return
+	}
+	d.config.Logger.Info("Script restored to latest revision", "script", d.scriptName)
+	d.loadScript(d.scriptName)
+}
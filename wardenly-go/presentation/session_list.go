@@ -1,28 +1,41 @@
 package presentation
 
 import (
+	"fmt"
 	"image/color"
+	"strings"
 	"sync"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/core/state"
+	"wardenly-go/domain/account"
 )
 
 // SessionListItem represents a single item in the session list.
 type SessionListItem struct {
 	SessionID   string
 	AccountName string
-	IsRunning   bool
+	State       state.SessionState
+	Err         error // Last login or script error, cleared on recovery
+	Lagging     bool  // Input latency is high
+	Queued      int   // Position in the login queue, 0 if not queued
+	Label       string
+	LabelColor  account.LabelColor
 }
 
 // SessionList is a scrollable list of sessions with status indicators.
 type SessionList struct {
 	widget.List
-	items      []*SessionListItem
-	itemsMu    sync.RWMutex
-	onSelected func(sessionID string)
+	items        []*SessionListItem
+	itemsMu      sync.RWMutex
+	highContrast bool
+	onSelected   func(sessionID string)
 }
 
 // NewSessionList creates a new session list widget.
@@ -62,17 +75,22 @@ func NewSessionList(onSelected func(sessionID string)) *SessionList {
 }
 
 func (sl *SessionList) createItem() fyne.CanvasObject {
-	// Status indicator (circle) - slightly larger for better visibility
-	indicator := canvas.NewCircle(color.RGBA{128, 128, 128, 255})
-	indicator.Resize(fyne.NewSize(12, 12))
+	// Status indicator - a distinct shape per state
+	indicator := widget.NewIcon(nil)
+
+	// User-defined account label, hidden when empty
+	chip := newLabelChip()
 
 	// Account name label
 	label := widget.NewLabel("Account Name")
 
+	// Status badge (text, so state does not rely on color)
+	badge := widget.NewLabelWithStyle("", fyne.TextAlignTrailing, fyne.TextStyle{Bold: true})
+
 	// Wrap in padded container for better touch targets and spacing
-	row := container.NewHBox(
-		container.NewCenter(container.NewGridWrap(fyne.NewSize(20, 20), indicator)),
-		label,
+	row := container.NewBorder(nil, nil,
+		container.NewHBox(container.NewGridWrap(fyne.NewSize(20, 20), indicator), chip, label),
+		badge,
 	)
 
 	return container.NewPadded(row)
@@ -90,23 +108,34 @@ func (sl *SessionList) updateItem(id widget.ListItemID, item fyne.CanvasObject)
 
 	// Navigate through the padded container structure
 	paddedContainer := item.(*fyne.Container)
-	hbox := paddedContainer.Objects[0].(*fyne.Container)
-
-	// Update indicator color
-	indicatorContainer := hbox.Objects[0].(*fyne.Container)
-	gridWrap := indicatorContainer.Objects[0].(*fyne.Container)
-	indicator := gridWrap.Objects[0].(*canvas.Circle)
-
-	if data.IsRunning {
-		indicator.FillColor = color.RGBA{0, 200, 0, 255} // Green = running
-	} else {
-		indicator.FillColor = color.RGBA{128, 128, 128, 255} // Gray = idle
+	row := paddedContainer.Objects[0].(*fyne.Container)
+	leading := row.Objects[0].(*fyne.Container)
+	badge := row.Objects[1].(*widget.Label)
+
+	status := statusFor(data.State, data.Err, sl.highContrast)
+
+	// Update indicator icon
+	gridWrap := leading.Objects[0].(*fyne.Container)
+	gridWrap.Objects[0].(*widget.Icon).SetResource(status.Icon)
+
+	// Update labels
+	leading.Objects[1].(*labelChip).SetLabel(data.Label, data.LabelColor)
+	leading.Objects[2].(*widget.Label).SetText(data.AccountName)
+	switch {
+	case data.Err != nil:
+		badge.SetText(status.Badge)
+		badge.Importance = widget.DangerImportance
+	case data.Queued > 0:
+		badge.SetText(fmt.Sprintf("Queued #%d", data.Queued))
+		badge.Importance = widget.LowImportance
+	case data.Lagging:
+		badge.SetText(strings.TrimPrefix(status.Badge+" · Lag", " · "))
+		badge.Importance = widget.WarningImportance
+	default:
+		badge.SetText(status.Badge)
+		badge.Importance = widget.MediumImportance
 	}
-	indicator.Refresh()
-
-	// Update label
-	label := hbox.Objects[1].(*widget.Label)
-	label.SetText(data.AccountName)
+	badge.Refresh()
 }
 
 // AddSession adds a new session to the list.
@@ -115,7 +144,7 @@ func (sl *SessionList) AddSession(sessionID, accountName string) {
 	sl.items = append(sl.items, &SessionListItem{
 		SessionID:   sessionID,
 		AccountName: accountName,
-		IsRunning:   false,
+		State:       state.StateIdle,
 	})
 	sl.itemsMu.Unlock()
 
@@ -136,12 +165,79 @@ func (sl *SessionList) RemoveSession(sessionID string) {
 	sl.Refresh()
 }
 
-// UpdateSessionState updates the running state of a session.
-func (sl *SessionList) UpdateSessionState(sessionID string, isRunning bool) {
+// UpdateSessionState updates the state shown for a session.
+// Starting a script clears any previous error; leaving Idle clears the
+// queue position.
+func (sl *SessionList) UpdateSessionState(sessionID string, st state.SessionState) {
+	sl.itemsMu.Lock()
+	for _, item := range sl.items {
+		if item.SessionID == sessionID {
+			item.State = st
+			if st != state.StateIdle {
+				item.Queued = 0
+			}
+			if st == state.StateScriptRunning {
+				item.Err = nil
+			}
+			break
+		}
+	}
+	sl.itemsMu.Unlock()
+
+	sl.Refresh()
+}
+
+// SetSessionError flags a session with an error badge until it recovers.
+// A nil err clears the badge.
+func (sl *SessionList) SetSessionError(sessionID string, err error) {
+	sl.itemsMu.Lock()
+	for _, item := range sl.items {
+		if item.SessionID == sessionID {
+			item.Err = err
+			break
+		}
+	}
+	sl.itemsMu.Unlock()
+
+	sl.Refresh()
+}
+
+// SetSessionLabel sets the user-defined label shown before a session's
+// account name. An empty label hides it.
+func (sl *SessionList) SetSessionLabel(sessionID, label string, c account.LabelColor) {
+	sl.itemsMu.Lock()
+	for _, item := range sl.items {
+		if item.SessionID == sessionID {
+			item.Label = label
+			item.LabelColor = c
+			break
+		}
+	}
+	sl.itemsMu.Unlock()
+
+	sl.Refresh()
+}
+
+// SetSessionQueued shows a session's position in the login queue.
+func (sl *SessionList) SetSessionQueued(sessionID string, position int) {
+	sl.itemsMu.Lock()
+	for _, item := range sl.items {
+		if item.SessionID == sessionID {
+			item.Queued = position
+			break
+		}
+	}
+	sl.itemsMu.Unlock()
+
+	sl.Refresh()
+}
+
+// SetSessionLagging flags a session whose input latency is high.
+func (sl *SessionList) SetSessionLagging(sessionID string, lagging bool) {
 	sl.itemsMu.Lock()
 	for _, item := range sl.items {
 		if item.SessionID == sessionID {
-			item.IsRunning = isRunning
+			item.Lagging = lagging
 			break
 		}
 	}
@@ -150,6 +246,16 @@ func (sl *SessionList) UpdateSessionState(sessionID string, isRunning bool) {
 	sl.Refresh()
 }
 
+// SetHighContrast switches between tinted icons and plain icons with
+// a text badge for every state.
+func (sl *SessionList) SetHighContrast(enabled bool) {
+	sl.itemsMu.Lock()
+	sl.highContrast = enabled
+	sl.itemsMu.Unlock()
+
+	sl.Refresh()
+}
+
 // SelectSession programmatically selects a session by ID.
 func (sl *SessionList) SelectSession(sessionID string) {
 	sl.itemsMu.RLock()
@@ -205,3 +311,57 @@ func (sl *SessionList) SessionIDAt(index int) string {
 	}
 	return sl.items[index].SessionID
 }
+
+// labelChipFills are the background colors of the label colors; text is
+// dark on the light ones and white otherwise.
+var labelChipFills = map[account.LabelColor]color.NRGBA{
+	account.LabelColorRed:    {R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff},
+	account.LabelColorOrange: {R: 0xf5, G: 0x7c, B: 0x00, A: 0xff},
+	account.LabelColorYellow: {R: 0xfb, G: 0xc0, B: 0x2d, A: 0xff},
+	account.LabelColorGreen:  {R: 0x38, G: 0x8e, B: 0x3c, A: 0xff},
+	account.LabelColorBlue:   {R: 0x19, G: 0x76, B: 0xd2, A: 0xff},
+	account.LabelColorPurple: {R: 0x7b, G: 0x1f, B: 0xa2, A: 0xff},
+	account.LabelColorGray:   {R: 0x61, G: 0x61, B: 0x61, A: 0xff},
+}
+
+// labelChip shows a session label as bold text on a rounded colored box.
+type labelChip struct {
+	widget.BaseWidget
+	box  *canvas.Rectangle
+	text *canvas.Text
+}
+
+func newLabelChip() *labelChip {
+	c := &labelChip{
+		box:  canvas.NewRectangle(color.Transparent),
+		text: canvas.NewText("", color.White),
+	}
+	c.box.CornerRadius = 4
+	c.text.TextStyle.Bold = true
+	c.text.TextSize = theme.CaptionTextSize()
+	c.ExtendBaseWidget(c)
+	c.Hide()
+	return c
+}
+
+// SetLabel shows text in color c, or hides the chip when text is empty.
+func (c *labelChip) SetLabel(text string, lc account.LabelColor) {
+	if text == "" {
+		c.Hide()
+		return
+	}
+	lc = lc.OrDefault()
+	c.box.FillColor = labelChipFills[lc]
+	c.text.Color = color.White
+	if lc == account.LabelColorYellow {
+		c.text.Color = color.Black
+	}
+	c.text.Text = text
+	c.Show()
+	c.Refresh()
+}
+
+func (c *labelChip) CreateRenderer() fyne.WidgetRenderer {
+	padded := container.New(layout.NewCustomPaddedLayout(1, 1, 5, 5), c.text)
+	return widget.NewSimpleRenderer(container.NewCenter(container.NewStack(c.box, padded)))
+}
@@ -0,0 +1,66 @@
+package presentation
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+
+	"wardenly-go/core/state"
+)
+
+// prefHighContrastStatus is the preference key for high-contrast status indicators.
+const prefHighContrastStatus = "high_contrast_status"
+
+// sessionStatus describes how a session's state is shown in the session list.
+// Every status has a distinct icon shape and a text label, so state never
+// depends on color alone.
+type sessionStatus struct {
+	Icon  fyne.Resource
+	Label string
+	// Badge is shown next to the account name for states worth calling out.
+	// In high-contrast mode every state gets a badge.
+	Badge string
+}
+
+// statusFor returns the indicator for a session. A non-nil err takes
+// precedence over the state. High contrast drops the tinted icons in favor
+// of the foreground color.
+func statusFor(st state.SessionState, err error, highContrast bool) sessionStatus {
+	var s sessionStatus
+	var tint func(fyne.Resource) fyne.Resource
+
+	switch {
+	case err != nil:
+		s = sessionStatus{Icon: theme.ErrorIcon(), Label: "Error", Badge: "Error"}
+		tint = func(r fyne.Resource) fyne.Resource { return theme.NewErrorThemedResource(r) }
+	case st == state.StateScriptRunning:
+		s = sessionStatus{Icon: theme.MediaPlayIcon(), Label: "Running", Badge: "Running"}
+		tint = func(r fyne.Resource) fyne.Resource { return theme.NewSuccessThemedResource(r) }
+	case st == state.StateScriptPaused:
+		s = sessionStatus{Icon: theme.MediaPauseIcon(), Label: "Paused", Badge: "Paused"}
+		tint = func(r fyne.Resource) fyne.Resource { return theme.NewWarningThemedResource(r) }
+	case st == state.StateLoggingIn:
+		s = sessionStatus{Icon: theme.LoginIcon(), Label: "Logging In", Badge: "Logging In"}
+		tint = func(r fyne.Resource) fyne.Resource { return theme.NewWarningThemedResource(r) }
+	case st == state.StateReconnecting:
+		s = sessionStatus{Icon: theme.WarningIcon(), Label: "Reconnecting", Badge: "Reconnecting"}
+		tint = func(r fyne.Resource) fyne.Resource { return theme.NewWarningThemedResource(r) }
+	case st == state.StateStarting:
+		s = sessionStatus{Icon: theme.ViewRefreshIcon(), Label: "Starting"}
+	case st == state.StateReady:
+		s = sessionStatus{Icon: theme.ConfirmIcon(), Label: "Ready"}
+		tint = func(r fyne.Resource) fyne.Resource { return theme.NewPrimaryThemedResource(r) }
+	case st == state.StateStopping:
+		s = sessionStatus{Icon: theme.CancelIcon(), Label: "Stopping"}
+	case st == state.StateStopped:
+		s = sessionStatus{Icon: theme.MediaStopIcon(), Label: "Stopped"}
+	default:
+		s = sessionStatus{Icon: theme.RadioButtonIcon(), Label: "Idle"}
+	}
+
+	if highContrast {
+		s.Badge = s.Label
+	} else if tint != nil {
+		s.Icon = tint(s.Icon)
+	}
+	return s
+}
@@ -0,0 +1,73 @@
+package presentation
+
+import (
+	"errors"
+	"testing"
+
+	"fyne.io/fyne/v2/theme"
+
+	"wardenly-go/core/state"
+)
+
+func TestStatusFor_DistinctPerState(t *testing.T) {
+	states := []state.SessionState{
+		state.StateIdle,
+		state.StateStarting,
+		state.StateLoggingIn,
+		state.StateReady,
+		state.StateScriptRunning,
+		state.StateStopping,
+		state.StateStopped,
+		state.StateReconnecting,
+		state.StateScriptPaused,
+	}
+
+	icons := make(map[string]state.SessionState)
+	labels := make(map[string]state.SessionState)
+	for _, st := range states {
+		s := statusFor(st, nil, true)
+		if prev, dup := icons[s.Icon.Name()]; dup {
+			t.Errorf("%v and %v share icon %s", prev, st, s.Icon.Name())
+		}
+		if prev, dup := labels[s.Label]; dup {
+			t.Errorf("%v and %v share label %q", prev, st, s.Label)
+		}
+		icons[s.Icon.Name()] = st
+		labels[s.Label] = st
+	}
+}
+
+func TestStatusFor_Badges(t *testing.T) {
+	tests := []struct {
+		name         string
+		state        state.SessionState
+		err          error
+		highContrast bool
+		wantBadge    string
+	}{
+		{"running", state.StateScriptRunning, nil, false, "Running"},
+		{"paused", state.StateScriptPaused, nil, false, "Paused"},
+		{"logging in", state.StateLoggingIn, nil, false, "Logging In"},
+		{"reconnecting", state.StateReconnecting, nil, false, "Reconnecting"},
+		{"ready has no badge", state.StateReady, nil, false, ""},
+		{"error wins", state.StateReady, errors.New("boom"), false, "Error"},
+		{"high contrast always badges", state.StateReady, nil, true, "Ready"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statusFor(tt.state, tt.err, tt.highContrast).Badge; got != tt.wantBadge {
+				t.Errorf("Badge = %q, want %q", got, tt.wantBadge)
+			}
+		})
+	}
+}
+
+func TestStatusFor_HighContrastUsesPlainIcons(t *testing.T) {
+	if got := statusFor(state.StateScriptRunning, nil, true).Icon; got != theme.MediaPlayIcon() {
+		t.Errorf("high contrast icon = %v, want untinted MediaPlayIcon", got.Name())
+	}
+	if got := statusFor(state.StateScriptRunning, nil, false).Icon; got == theme.MediaPlayIcon() {
+		t.Error("default icon should be tinted")
+	}
+}
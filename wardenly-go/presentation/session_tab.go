@@ -4,10 +4,15 @@ import (
 	"fmt"
 	"image/color"
 	"log/slog"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"wardenly-go/application/session"
+	"wardenly-go/core/command"
 	"wardenly-go/core/state"
 
 	"fyne.io/fyne/v2"
@@ -18,6 +23,16 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
+// maxLogLines is how many recent log lines a session tab keeps.
+const maxLogLines = 200
+
+// logLine is a session log line shown in the log panel.
+type logLine struct {
+	time  time.Time
+	level slog.Level
+	text  string
+}
+
 // SessionTab represents a tab for a single session.
 type SessionTab struct {
 	sessionID   string
@@ -27,37 +42,77 @@ type SessionTab struct {
 
 	// Callbacks
 	onStop               func(sessionID string)
+	onDetach             func(sessionID string)
 	shouldSpreadToAll    func() bool
 	isAutoRefreshEnabled func() bool
 	onSyncScript         func(scriptName string)
+	onShowScriptDoc      func(scriptName string)
+	onSceneOverlay       func(sessionID string, enabled bool)
 	onStartAllScripts    func()
 	onStopAllScripts     func()
+	promptScriptParams   func(scriptName string, start func(params map[string]string)) bool
 
 	// UI components
 	container *fyne.Container
 
 	// Browser control
 	stopBtn        *widget.Button
+	detachBtn      *widget.Button
 	refreshBtn     *widget.Button
 	saveCookiesBtn *widget.Button
+	latencyLabel   *widget.Label
 
 	// Script control
 	scriptBtn     *widget.Button
+	pauseBtn      *widget.Button
 	scriptSelect  *widget.Select
 	syncScriptBtn *widget.Button
+	scriptDocBtn  *widget.Button
 	allScriptsBtn *widget.Button
+	countersLabel *widget.Label
+
+	// Step-through debugging
+	debugCheck  *widget.Check
+	haltBox     *fyne.Container
+	haltLabel   *widget.Label
+	stepBtn     *widget.Button
+	continueBtn *widget.Button
 
 	// Canvas control
 	clickBtn         *widget.Button
 	saveScreenshotCb *widget.Check
+	sceneOverlayCb   *widget.Check
 	xEntry           *widget.Entry
 	yEntry           *widget.Entry
 	colorEntry       *widget.Entry
 	colorRect        *canvas.Rectangle
 	pointsArea       *widget.Entry
+	evalEntry        *widget.Entry
+	evalBtn          *widget.Button
+	evalResult       *widget.Entry
+
+	// Log panel
+	logList     *widget.List
+	logClearBtn *widget.Button
+	logLines    []logLine // Oldest first, at most maxLogLines
+
+	// Tuning panel
+	thresholdSlider    *widget.Slider
+	thresholdLabel     *widget.Label
+	pollSlider         *widget.Slider
+	pollLabel          *widget.Label
+	jitterCheck        *widget.Check
+	jitterPixelsSlider *widget.Slider
+	jitterPixelsLabel  *widget.Label
+	jitterWaitSlider   *widget.Slider
+	jitterWaitLabel    *widget.Label
+	tuneResetBtn       *widget.Button
+	// settingTuning keeps SetTuning from sending the values it shows back
+	settingTuning bool
 
 	// State
 	scriptRunning bool
+	scriptPaused  bool
 	// suppressScriptSelectSync prevents SetSelected* (programmatic) from triggering
 	// immediate script selection sync to backend before session is ready.
 	suppressScriptSelectSync bool
@@ -66,21 +121,34 @@ type SessionTab struct {
 	// Pending color update coordinates (for manual mode screenshot callback)
 	pendingColorX, pendingColorY int
 	hasPendingColor              bool
+
+	disposed bool
 }
 
 // SessionTabConfig holds configuration for SessionTab.
 type SessionTabConfig struct {
-	SessionID            string
-	AccountName          string
-	Bridge               *UIEventBridge
-	Logger               *slog.Logger
-	ScriptNames          []string
-	OnStop               func(sessionID string)
+	SessionID   string
+	AccountName string
+	Bridge      *UIEventBridge
+	Logger      *slog.Logger
+	ScriptNames []string
+	OnStop      func(sessionID string)
+	// OnDetach restarts the session's browser with its window shown (optional)
+	OnDetach             func(sessionID string)
 	ShouldSpreadToAll    func() bool
 	IsAutoRefreshEnabled func() bool
 	OnSyncScript         func(scriptName string)
 	OnStartAllScripts    func()
 	OnStopAllScripts     func()
+	// OnShowScriptDoc shows the documentation of a script (optional)
+	OnShowScriptDoc func(scriptName string)
+	// OnSceneOverlay turns the scene match overlay on the browser view on
+	// or off (optional)
+	OnSceneOverlay func(sessionID string, enabled bool)
+
+	// PromptScriptParams asks for a script's prompt values before starting it.
+	// It returns false when the script has no prompts; start is then not called.
+	PromptScriptParams func(scriptName string, start func(params map[string]string)) bool
 }
 
 // NewSessionTab creates a new session tab.
@@ -95,27 +163,108 @@ func NewSessionTab(cfg *SessionTabConfig) *SessionTab {
 		bridge:               cfg.Bridge,
 		logger:               cfg.Logger,
 		onStop:               cfg.OnStop,
+		onDetach:             cfg.OnDetach,
 		shouldSpreadToAll:    cfg.ShouldSpreadToAll,
 		isAutoRefreshEnabled: cfg.IsAutoRefreshEnabled,
 		onSyncScript:         cfg.OnSyncScript,
+		onShowScriptDoc:      cfg.OnShowScriptDoc,
+		onSceneOverlay:       cfg.OnSceneOverlay,
 		onStartAllScripts:    cfg.OnStartAllScripts,
 		onStopAllScripts:     cfg.OnStopAllScripts,
+		promptScriptParams:   cfg.PromptScriptParams,
 	}
 
 	// Wrap sections in Cards for visual hierarchy
 	browserCard := widget.NewCard("Browser Control", "", t.createBrowserControlBox())
 	scriptCard := widget.NewCard("Script Engine", "", t.createScriptControlBox(cfg.ScriptNames))
 	inspectorCard := widget.NewCard("Inspector", "", t.createCanvasControlBox())
+	logCard := widget.NewCard("Log", "", t.createLogBox())
+	tuningPanel := widget.NewAccordion(widget.NewAccordionItem("Tuning", t.createTuningBox()))
 
 	t.container = container.NewVBox(
 		browserCard,
 		scriptCard,
 		inspectorCard,
+		logCard,
+		tuningPanel,
 	)
 
 	return t
 }
 
+// Dispose releases the tab's widgets, callbacks and bridge reference once the
+// session is removed, so closures held by Fyne cannot keep the tab alive.
+// Calling Dispose more than once is a no-op.
+func (t *SessionTab) Dispose() {
+	t.stateMu.Lock()
+	if t.disposed {
+		t.stateMu.Unlock()
+		return
+	}
+	t.disposed = true
+	t.hasPendingColor = false
+	t.stateMu.Unlock()
+
+	t.bridge = nil
+	t.onStop = nil
+	t.onDetach = nil
+	t.shouldSpreadToAll = nil
+	t.isAutoRefreshEnabled = nil
+	t.onSyncScript = nil
+	t.onShowScriptDoc = nil
+	t.onSceneOverlay = nil
+	t.onStartAllScripts = nil
+	t.onStopAllScripts = nil
+	t.promptScriptParams = nil
+
+	for _, btn := range []*widget.Button{
+		t.stopBtn, t.detachBtn, t.refreshBtn, t.saveCookiesBtn,
+		t.scriptBtn, t.pauseBtn, t.syncScriptBtn, t.scriptDocBtn, t.allScriptsBtn, t.clickBtn,
+		t.stepBtn, t.continueBtn,
+		t.evalBtn, t.logClearBtn, t.tuneResetBtn,
+	} {
+		if btn != nil {
+			btn.OnTapped = nil
+		}
+	}
+	if t.scriptSelect != nil {
+		t.scriptSelect.OnChanged = nil
+	}
+	if t.evalEntry != nil {
+		t.evalEntry.OnSubmitted = nil
+	}
+	if t.logList != nil {
+		t.logList.Length = nil
+		t.logList.UpdateItem = nil
+	}
+	if t.saveScreenshotCb != nil {
+		t.saveScreenshotCb.OnChanged = nil
+	}
+	if t.sceneOverlayCb != nil {
+		t.sceneOverlayCb.OnChanged = nil
+	}
+	if t.jitterCheck != nil {
+		t.jitterCheck.OnChanged = nil
+	}
+	for _, slider := range []*widget.Slider{t.thresholdSlider, t.pollSlider, t.jitterPixelsSlider, t.jitterWaitSlider} {
+		if slider != nil {
+			slider.OnChanged = nil
+			slider.OnChangeEnded = nil
+		}
+	}
+	t.logLines = nil
+	if t.container != nil {
+		t.container.Objects = nil
+	}
+}
+
+// IsDisposed returns true once Dispose has been called.
+func (t *SessionTab) IsDisposed() bool {
+	t.stateMu.RLock()
+	defer t.stateMu.RUnlock()
+	return t.disposed
+}
+
 // Container returns the tab's container.
 func (t *SessionTab) Container() *fyne.Container {
 	return t.container
@@ -159,7 +308,16 @@ func (t *SessionTab) createBrowserControlBox() fyne.CanvasObject {
 	})
 	t.saveCookiesBtn.Disable()
 
-	return container.NewHBox(t.stopBtn, t.refreshBtn, t.saveCookiesBtn)
+	t.detachBtn = widget.NewButtonWithIcon("Detach", theme.ViewFullScreenIcon(), func() {
+		if t.onDetach != nil {
+			t.onDetach(t.sessionID)
+		}
+	})
+	t.detachBtn.Disable()
+
+	t.latencyLabel = widget.NewLabel("")
+
+	return container.NewHBox(t.stopBtn, t.refreshBtn, t.saveCookiesBtn, t.detachBtn, layout.NewSpacer(), t.latencyLabel)
 }
 
 func (t *SessionTab) createScriptControlBox(scriptNames []string) fyne.CanvasObject {
@@ -176,6 +334,20 @@ func (t *SessionTab) createScriptControlBox(scriptNames []string) fyne.CanvasObj
 	})
 	t.scriptBtn.Disable()
 
+	// Enabled only while a script runs; UI state follows the session state
+	t.pauseBtn = widget.NewButtonWithIcon("Pause", theme.MediaPauseIcon(), func() {
+		t.stateMu.RLock()
+		paused := t.scriptPaused
+		t.stateMu.RUnlock()
+
+		if paused {
+			t.ResumeScript()
+		} else {
+			t.PauseScript()
+		}
+	})
+	t.pauseBtn.Disable()
+
 	if scriptNames == nil {
 		scriptNames = []string{}
 	}
@@ -204,6 +376,16 @@ func (t *SessionTab) createScriptControlBox(scriptNames []string) fyne.CanvasObj
 	})
 	t.syncScriptBtn.Disable()
 
+	// Documentation is available whether or not the session is ready
+	t.scriptDocBtn = widget.NewButtonWithIcon("About", theme.InfoIcon(), func() {
+		if t.onShowScriptDoc != nil {
+			t.onShowScriptDoc(t.scriptSelect.Selected)
+		}
+	})
+	if t.onShowScriptDoc == nil {
+		t.scriptDocBtn.Disable()
+	}
+
 	t.allScriptsBtn = widget.NewButtonWithIcon("Run All", theme.MediaFastForwardIcon(), func() {
 		t.stateMu.RLock()
 		running := t.scriptRunning
@@ -221,11 +403,31 @@ func (t *SessionTab) createScriptControlBox(scriptNames []string) fyne.CanvasObj
 	})
 	t.allScriptsBtn.Disable()
 
+	// Start scripts halted before each action, for stepping through them
+	t.debugCheck = widget.NewCheck("Debug", nil)
+	t.debugCheck.Disable()
+
+	t.haltLabel = widget.NewLabel("")
+	t.haltLabel.Wrapping = fyne.TextWrapWord
+	t.stepBtn = widget.NewButtonWithIcon("Step", theme.MediaSkipNextIcon(), func() {
+		t.step(false)
+	})
+	t.continueBtn = widget.NewButtonWithIcon("Continue", theme.MediaFastForwardIcon(), func() {
+		t.step(true)
+	})
+	t.haltBox = container.NewBorder(nil, nil, nil, container.NewHBox(t.stepBtn, t.continueBtn), t.haltLabel)
+	t.haltBox.Hide()
+
 	// Two rows for better layout
-	row1 := container.NewHBox(t.scriptSelect, t.scriptBtn, t.syncScriptBtn)
-	row2 := container.NewHBox(t.allScriptsBtn)
+	row1 := container.NewHBox(t.scriptSelect, t.scriptBtn, t.pauseBtn, t.syncScriptBtn, t.scriptDocBtn)
+	row2 := container.NewHBox(t.allScriptsBtn, t.debugCheck)
+
+	// Counters of the running script, or of the last run once it stops
+	t.countersLabel = widget.NewLabel("")
+	t.countersLabel.Wrapping = fyne.TextWrapWord
+	t.countersLabel.Hide()
 
-	return container.NewVBox(row1, row2)
+	return container.NewVBox(row1, row2, t.haltBox, t.countersLabel)
 }
 
 func (t *SessionTab) createCanvasControlBox() fyne.CanvasObject {
@@ -257,6 +459,13 @@ func (t *SessionTab) createCanvasControlBox() fyne.CanvasObject {
 
 	t.saveScreenshotCb = widget.NewCheck("Save Screenshot", func(checked bool) {})
 
+	// Draws how every scene compares against the frames in the browser view
+	t.sceneOverlayCb = widget.NewCheck("Scene Overlay", func(checked bool) {
+		if t.onSceneOverlay != nil {
+			t.onSceneOverlay(t.sessionID, checked)
+		}
+	})
+
 	// Coordinate display
 	t.xEntry = widget.NewEntry()
 	t.xEntry.Disable()
@@ -283,13 +492,50 @@ func (t *SessionTab) createCanvasControlBox() fyne.CanvasObject {
 	t.pointsArea = widget.NewMultiLineEntry()
 	t.pointsArea.Disable()
 
+	// Reads DOM state, such as the canvas size, that pixels only hint at
+	t.evalEntry = widget.NewEntry()
+	t.evalEntry.SetPlaceHolder("JavaScript, e.g. document.querySelector('#S_Iframe').getBoundingClientRect()")
+	t.evalBtn = widget.NewButton("Evaluate", t.evaluate)
+	t.evalBtn.Disable()
+	t.evalEntry.OnSubmitted = func(string) {
+		if !t.evalBtn.Disabled() {
+			t.evaluate()
+		}
+	}
+	t.evalResult = widget.NewMultiLineEntry()
+	t.evalResult.Wrapping = fyne.TextWrapBreak
+	t.evalResult.Disable()
+
 	return container.NewVBox(
-		container.NewHBox(t.clickBtn, t.saveScreenshotCb),
+		container.NewHBox(t.clickBtn, t.saveScreenshotCb, t.sceneOverlayCb),
 		coordsColorBox,
 		container.NewGridWrap(fyne.NewSize(400, 200), t.pointsArea),
+		container.NewBorder(nil, nil, nil, t.evalBtn, t.evalEntry),
+		container.NewGridWrap(fyne.NewSize(400, 80), t.evalResult),
 	)
 }
 
+// evaluate runs the expression in the inspector's entry in the page.
+func (t *SessionTab) evaluate() {
+	expression := strings.TrimSpace(t.evalEntry.Text)
+	if expression == "" || t.bridge == nil {
+		return
+	}
+	t.evalResult.SetText("Evaluating...")
+	if err := t.bridge.EvaluateJS(t.sessionID, expression); err != nil {
+		t.SetEvaluation(nil, err)
+	}
+}
+
+// SetEvaluation shows the result of the inspector's last expression.
+func (t *SessionTab) SetEvaluation(result []byte, err error) {
+	if err != nil {
+		t.evalResult.SetText("Error: " + err.Error())
+		return
+	}
+	t.evalResult.SetText(string(result))
+}
+
 // HandleCanvasClick returns a handler for canvas click events.
 func (t *SessionTab) HandleCanvasClick(canvasWin *CanvasWindow) func(float32, float32) {
 	return func(x, y float32) {
@@ -348,6 +594,31 @@ func (t *SessionTab) HandleCanvasDrag(canvasWin *CanvasWindow) func(float32, flo
 	}
 }
 
+// HandleCanvasScroll returns a handler for mouse wheel events on the canvas.
+func (t *SessionTab) HandleCanvasScroll() func(x, y, deltaX, deltaY float32) {
+	return func(x, y, deltaX, deltaY float32) {
+		if t.bridge == nil {
+			return
+		}
+		if err := t.bridge.Scroll(t.sessionID, float64(x), float64(y), float64(deltaX), float64(deltaY)); err != nil {
+			t.logger.Error("Scroll failed", "error", err)
+		}
+	}
+}
+
+// HandleCanvasKey returns a handler for keys typed on the canvas, which are
+// pressed in this session's browser.
+func (t *SessionTab) HandleCanvasKey() func(string) {
+	return func(key string) {
+		if t.bridge == nil {
+			return
+		}
+		if err := t.bridge.KeyPress(t.sessionID, key); err != nil {
+			t.logger.Error("Key press failed", "error", err)
+		}
+	}
+}
+
 func (t *SessionTab) updateColorFromCanvas(canvasWin *CanvasWindow, x, y int) {
 	img := canvasWin.GetImage()
 	if img == nil {
@@ -417,16 +688,74 @@ func (t *SessionTab) SetScriptRunning(running bool) {
 		t.scriptBtn.SetIcon(theme.MediaStopIcon())
 		t.allScriptsBtn.SetText("Stop All")
 		t.allScriptsBtn.SetIcon(theme.MediaStopIcon())
+		t.pauseBtn.Enable()
 	} else {
 		t.scriptBtn.SetText("Start")
 		t.scriptBtn.SetIcon(theme.MediaPlayIcon())
 		t.allScriptsBtn.SetText("Run All")
 		t.allScriptsBtn.SetIcon(theme.MediaFastForwardIcon())
+		t.SetScriptPaused(false)
+		t.pauseBtn.Disable()
+		t.haltBox.Hide()
 	}
 	t.scriptBtn.Refresh()
 	t.allScriptsBtn.Refresh()
 }
 
+// SetScriptPaused sets whether the running script is paused.
+func (t *SessionTab) SetScriptPaused(paused bool) {
+	t.stateMu.Lock()
+	t.scriptPaused = paused
+	t.stateMu.Unlock()
+
+	if paused {
+		t.pauseBtn.SetText("Resume")
+		t.pauseBtn.SetIcon(theme.MediaPlayIcon())
+	} else {
+		t.pauseBtn.SetText("Pause")
+		t.pauseBtn.SetIcon(theme.MediaPauseIcon())
+	}
+}
+
+// SetHalted shows the action a script in debug mode is about to perform,
+// with the Step and Continue buttons.
+func (t *SessionTab) SetHalted(scriptName, step, action string) {
+	text := "Next: " + action
+	if step != "" {
+		text += " · " + scriptName + " / " + step
+	}
+	t.haltLabel.SetText(text)
+	t.haltBox.Show()
+}
+
+// step sends a StepScript and hides the pending action until the script
+// halts again.
+func (t *SessionTab) step(cont bool) {
+	t.haltBox.Hide()
+	if t.bridge == nil {
+		return
+	}
+	if err := t.bridge.StepScript(t.sessionID, cont); err != nil {
+		t.logger.Error("Failed to step script", "error", err)
+	}
+}
+
+// SetCounters shows a script's counters, sorted by name, such as
+// "battles_won: 17 · runs: 3". The label is hidden while there are none.
+func (t *SessionTab) SetCounters(counters map[string]int) {
+	if len(counters) == 0 {
+		t.countersLabel.SetText("")
+		t.countersLabel.Hide()
+		return
+	}
+	parts := make([]string, 0, len(counters))
+	for _, key := range slices.Sorted(maps.Keys(counters)) {
+		parts = append(parts, fmt.Sprintf("%s: %d", key, counters[key]))
+	}
+	t.countersLabel.SetText(strings.Join(parts, " · "))
+	t.countersLabel.Show()
+}
+
 // SetScriptSelection sets the selected script.
 func (t *SessionTab) SetScriptSelection(scriptName string) {
 	if t.scriptSelect != nil {
@@ -436,6 +765,259 @@ func (t *SessionTab) SetScriptSelection(scriptName string) {
 	}
 }
 
+// SetScriptNames replaces the script choices after a reload. The selection
+// is kept if the script still exists; otherwise the first script is selected
+// and synced to the session.
+func (t *SessionTab) SetScriptNames(names []string) {
+	if t.scriptSelect == nil {
+		return
+	}
+	t.scriptSelect.SetOptions(names)
+	if slices.Contains(names, t.scriptSelect.Selected) {
+		return
+	}
+	if len(names) == 0 {
+		t.scriptSelect.ClearSelected()
+		return
+	}
+	t.scriptSelect.SetSelectedIndex(0)
+}
+
+func (t *SessionTab) createLogBox() fyne.CanvasObject {
+	t.logList = widget.NewList(
+		func() int { return len(t.logLines) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.TextStyle = fyne.TextStyle{Monospace: true}
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(t.logLines) {
+				return
+			}
+			line := t.logLines[id]
+			label := obj.(*widget.Label)
+			label.Importance = logImportance(line.level)
+			label.SetText(fmt.Sprintf("%s %-5s %s", line.time.Local().Format("15:04:05"), line.level, line.text))
+		},
+	)
+
+	t.logClearBtn = widget.NewButton("Clear", func() {
+		t.logLines = nil
+		t.logList.Refresh()
+	})
+
+	return container.NewVBox(
+		container.NewHBox(widget.NewLabel(fmt.Sprintf("Last %d lines", maxLogLines)), layout.NewSpacer(), t.logClearBtn),
+		container.NewGridWrap(fyne.NewSize(500, 180), t.logList),
+	)
+}
+
+// AppendLog adds a line the session logged to the log panel, dropping the
+// oldest beyond maxLogLines, and scrolls to it.
+func (t *SessionTab) AppendLog(at time.Time, level slog.Level, text string) {
+	if t.IsDisposed() {
+		return
+	}
+	if len(t.logLines) == maxLogLines {
+		t.logLines = append(t.logLines[:0], t.logLines[1:]...)
+	}
+	t.logLines = append(t.logLines, logLine{time: at, level: level, text: text})
+	t.logList.Refresh()
+	t.logList.ScrollToBottom()
+}
+
+// logImportance highlights warnings and errors.
+func logImportance(level slog.Level) widget.Importance {
+	switch {
+	case level >= slog.LevelError:
+		return widget.DangerImportance
+	case level >= slog.LevelWarn:
+		return widget.WarningImportance
+	case level < slog.LevelInfo:
+		return widget.LowImportance
+	}
+	return widget.MediumImportance
+}
+
+// Ranges of the tuning sliders.
+const (
+	minTuneThreshold    = 1.0
+	maxTuneThreshold    = 30.0
+	minTunePollMs       = 100.0
+	maxTunePollMs       = 2000.0
+	maxTuneJitterPixels = 10.0
+	maxTuneJitterWait   = 0.5
+)
+
+func (t *SessionTab) createTuningBox() fyne.CanvasObject {
+	t.thresholdLabel = widget.NewLabel("")
+	t.thresholdSlider = t.newTuningSlider(minTuneThreshold, maxTuneThreshold, 0.5, t.thresholdLabel,
+		func(v float64) string { return fmt.Sprintf("%.1f", v) },
+		func(cmd *command.TuneSession, v float64) { cmd.SceneThreshold = &v })
+
+	t.pollLabel = widget.NewLabel("")
+	t.pollSlider = t.newTuningSlider(minTunePollMs, maxTunePollMs, 50, t.pollLabel,
+		func(v float64) string { return fmt.Sprintf("%.0f ms", v) },
+		func(cmd *command.TuneSession, v float64) {
+			d := time.Duration(v) * time.Millisecond
+			cmd.PollInterval = &d
+		})
+
+	t.jitterPixelsLabel = widget.NewLabel("")
+	t.jitterPixelsSlider = t.newTuningSlider(0, maxTuneJitterPixels, 0.5, t.jitterPixelsLabel,
+		func(v float64) string { return fmt.Sprintf("%.1f px", v) },
+		func(cmd *command.TuneSession, v float64) { cmd.JitterPixels = &v })
+
+	t.jitterWaitLabel = widget.NewLabel("")
+	t.jitterWaitSlider = t.newTuningSlider(0, maxTuneJitterWait, 0.05, t.jitterWaitLabel,
+		func(v float64) string { return fmt.Sprintf("±%.0f%%", v*100) },
+		func(cmd *command.TuneSession, v float64) { cmd.JitterWait = &v })
+
+	t.jitterCheck = widget.NewCheck("Override script jitter", func(checked bool) {
+		t.setJitterEnabled(checked)
+		if t.settingTuning {
+			return
+		}
+		cmd := command.NewTuneSession(t.sessionID)
+		if checked {
+			pixels, wait := t.jitterPixelsSlider.Value, t.jitterWaitSlider.Value
+			cmd.JitterPixels, cmd.JitterWait = &pixels, &wait
+		} else {
+			cmd.ClearJitter = true
+		}
+		t.tune(cmd)
+	})
+
+	t.tuneResetBtn = widget.NewButtonWithIcon("Reset", theme.ContentUndoIcon(), func() {
+		cmd := command.NewTuneSession(t.sessionID)
+		cmd.Reset = true
+		t.tune(cmd)
+	})
+
+	t.SetTuning(session.Tuning{SceneThreshold: minTuneThreshold, PollInterval: session.DefaultPollInterval})
+	t.setTuningEnabled(false)
+
+	row := func(name string, slider *widget.Slider, value *widget.Label) fyne.CanvasObject {
+		return container.NewBorder(nil, nil,
+			container.NewGridWrap(fyne.NewSize(110, 36), widget.NewLabel(name)),
+			container.NewGridWrap(fyne.NewSize(70, 36), value),
+			slider)
+	}
+	return container.NewVBox(
+		row("Threshold", t.thresholdSlider, t.thresholdLabel),
+		row("Poll interval", t.pollSlider, t.pollLabel),
+		t.jitterCheck,
+		row("Jitter", t.jitterPixelsSlider, t.jitterPixelsLabel),
+		row("Wait jitter", t.jitterWaitSlider, t.jitterWaitLabel),
+		container.NewHBox(layout.NewSpacer(), t.tuneResetBtn),
+	)
+}
+
+// newTuningSlider creates a slider showing its value in label, formatted by
+// format, that tunes the session with set once the user lets go of it.
+func (t *SessionTab) newTuningSlider(min, max, step float64, label *widget.Label, format func(float64) string,
+	set func(cmd *command.TuneSession, v float64)) *widget.Slider {
+	slider := widget.NewSlider(min, max)
+	slider.Step = step
+	slider.OnChanged = func(v float64) { label.SetText(format(v)) }
+	slider.OnChangeEnded = func(v float64) {
+		if t.settingTuning {
+			return
+		}
+		cmd := command.NewTuneSession(t.sessionID)
+		set(cmd, v)
+		t.tune(cmd)
+	}
+	return slider
+}
+
+func (t *SessionTab) tune(cmd *command.TuneSession) {
+	if t.bridge == nil {
+		return
+	}
+	if err := t.bridge.TuneSession(cmd); err != nil {
+		t.logger.Error("Failed to tune session", "error", err)
+	}
+}
+
+// SetTuning shows a session's matching and pacing settings in the tuning
+// panel without sending them back.
+func (t *SessionTab) SetTuning(tuning session.Tuning) {
+	if t.IsDisposed() {
+		return
+	}
+	t.settingTuning = true
+	defer func() { t.settingTuning = false }()
+
+	setSlider(t.thresholdSlider, tuning.SceneThreshold)
+	setSlider(t.pollSlider, float64(tuning.PollInterval.Milliseconds()))
+	t.jitterCheck.SetChecked(tuning.Jitter != nil)
+	if tuning.Jitter != nil {
+		setSlider(t.jitterPixelsSlider, tuning.Jitter.Pixels)
+		setSlider(t.jitterWaitSlider, tuning.Jitter.Wait)
+	}
+	t.setJitterEnabled(tuning.Jitter != nil && !t.thresholdSlider.Disabled())
+}
+
+// setSlider moves slider to v and updates its label, even when v is where
+// the slider already is.
+func setSlider(slider *widget.Slider, v float64) {
+	slider.SetValue(v)
+	if slider.OnChanged != nil {
+		slider.OnChanged(slider.Value)
+	}
+}
+
+func (t *SessionTab) setJitterEnabled(enabled bool) {
+	for _, slider := range []*widget.Slider{t.jitterPixelsSlider, t.jitterWaitSlider} {
+		if enabled {
+			slider.Enable()
+		} else {
+			slider.Disable()
+		}
+	}
+}
+
+// setTuningEnabled enables the tuning panel while the session is ready,
+// loading its current settings.
+func (t *SessionTab) setTuningEnabled(enabled bool) {
+	if enabled && t.bridge != nil {
+		if tuning, ok := t.bridge.Tuning(t.sessionID); ok {
+			t.SetTuning(tuning)
+		}
+	}
+	for _, slider := range []*widget.Slider{t.thresholdSlider, t.pollSlider} {
+		if enabled {
+			slider.Enable()
+		} else {
+			slider.Disable()
+		}
+	}
+	if enabled {
+		t.jitterCheck.Enable()
+		t.tuneResetBtn.Enable()
+	} else {
+		t.jitterCheck.Disable()
+		t.tuneResetBtn.Disable()
+	}
+	t.setJitterEnabled(enabled && t.jitterCheck.Checked)
+}
+
+// SetLatency shows the session's rolling browser round-trip times,
+// highlighted while the session is lagging.
+func (t *SessionTab) SetLatency(mean, p95 time.Duration, lagging bool) {
+	text := fmt.Sprintf("RTT %dms · p95 %dms", mean.Milliseconds(), p95.Milliseconds())
+	if lagging {
+		text += " · Lagging"
+		t.latencyLabel.Importance = widget.WarningImportance
+	} else {
+		t.latencyLabel.Importance = widget.MediumImportance
+	}
+	t.latencyLabel.SetText(text)
+}
+
 // UpdateState updates the tab based on session state.
 func (t *SessionTab) UpdateState(newState state.SessionState) {
 	switch newState {
@@ -443,6 +1025,9 @@ func (t *SessionTab) UpdateState(newState state.SessionState) {
 		t.EnableControls()
 	case state.StateScriptRunning:
 		t.SetScriptRunning(true)
+		t.SetScriptPaused(false)
+	case state.StateScriptPaused:
+		t.SetScriptPaused(true)
 	case state.StateStopped:
 		t.DisableControls()
 	}
@@ -451,24 +1036,35 @@ func (t *SessionTab) UpdateState(newState state.SessionState) {
 // EnableControls enables all control buttons.
 func (t *SessionTab) EnableControls() {
 	t.stopBtn.Enable()
+	if t.onDetach != nil {
+		t.detachBtn.Enable()
+	}
 	t.refreshBtn.Enable()
 	t.saveCookiesBtn.Enable()
 	t.scriptBtn.Enable()
+	t.debugCheck.Enable()
 	t.scriptSelect.Enable()
 	t.syncScriptBtn.Enable()
 	t.allScriptsBtn.Enable()
 	t.clickBtn.Enable()
+	t.evalBtn.Enable()
+	t.setTuningEnabled(true)
 }
 
 // DisableControls disables all control buttons except stop.
 func (t *SessionTab) DisableControls() {
+	t.detachBtn.Disable()
 	t.refreshBtn.Disable()
 	t.saveCookiesBtn.Disable()
 	t.scriptBtn.Disable()
+	t.pauseBtn.Disable()
+	t.debugCheck.Disable()
 	t.scriptSelect.Disable()
 	t.syncScriptBtn.Disable()
 	t.allScriptsBtn.Disable()
 	t.clickBtn.Disable()
+	t.evalBtn.Disable()
+	t.setTuningEnabled(false)
 }
 
 // StartScript starts the selected script.
@@ -478,12 +1074,55 @@ func (t *SessionTab) StartScript() {
 		return
 	}
 
+	scriptName := t.scriptSelect.Selected
+	debug := t.debugCheck.Checked
+	start := func(params map[string]string) error {
+		if debug {
+			return t.bridge.DebugScript(t.sessionID, scriptName, params)
+		}
+		if params != nil {
+			return t.bridge.StartScriptWithParams(t.sessionID, scriptName, params)
+		}
+		return t.bridge.StartScript(t.sessionID, scriptName)
+	}
+	if t.promptScriptParams != nil && t.promptScriptParams(scriptName, func(params map[string]string) {
+		if err := start(params); err != nil {
+			t.logger.Error("Failed to start script", "error", err)
+		}
+	}) {
+		return
+	}
+
 	// Just send command; UI state will be updated via event callback (OnScriptStarted)
-	if err := t.bridge.StartScript(t.sessionID, t.scriptSelect.Selected); err != nil {
+	if err := start(nil); err != nil {
 		t.logger.Error("Failed to start script", "error", err)
 	}
 }
 
+// PauseScript pauses the running script.
+// UI state is updated via OnSessionStateChanged event callback, not immediately.
+func (t *SessionTab) PauseScript() {
+	if t.bridge == nil {
+		return
+	}
+
+	if err := t.bridge.PauseScript(t.sessionID); err != nil {
+		t.logger.Error("Failed to pause script", "error", err)
+	}
+}
+
+// ResumeScript resumes the paused script.
+// UI state is updated via OnSessionStateChanged event callback, not immediately.
+func (t *SessionTab) ResumeScript() {
+	if t.bridge == nil {
+		return
+	}
+
+	if err := t.bridge.ResumeScript(t.sessionID); err != nil {
+		t.logger.Error("Failed to resume script", "error", err)
+	}
+}
+
 // StopScript stops the running script.
 // UI state is updated via OnScriptStopped event callback, not immediately.
 func (t *SessionTab) StopScript() {
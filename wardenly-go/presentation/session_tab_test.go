@@ -2,9 +2,12 @@ package presentation
 
 import (
 	"image/color"
+	"runtime"
 	"testing"
 
 	"wardenly-go/core/state"
+
+	"fyne.io/fyne/v2/test"
 )
 
 func TestSessionTabConfig(t *testing.T) {
@@ -69,6 +72,7 @@ func TestSessionState_UIBehavior(t *testing.T) {
 		{state.StateLoggingIn, false},
 		{state.StateReady, true},
 		{state.StateScriptRunning, true},
+		{state.StateScriptPaused, true},
 		{state.StateStopping, false},
 		{state.StateStopped, false},
 	}
@@ -83,3 +87,170 @@ func TestSessionState_UIBehavior(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionTab_Dispose(t *testing.T) {
+	test.NewTempApp(t)
+
+	tab := NewSessionTab(&SessionTabConfig{
+		SessionID:   "session-1",
+		AccountName: "Test Account",
+		ScriptNames: []string{"daily"},
+		OnStop:      func(string) {},
+	})
+
+	tab.Dispose()
+	tab.Dispose() // second call must be a no-op
+
+	if !tab.IsDisposed() {
+		t.Error("IsDisposed() = false after Dispose")
+	}
+	if tab.onStop != nil || tab.stopBtn.OnTapped != nil || tab.scriptSelect.OnChanged != nil {
+		t.Error("Dispose did not release callbacks")
+	}
+	if len(tab.Container().Objects) != 0 {
+		t.Error("Dispose did not clear the container")
+	}
+}
+
+func TestSessionTab_SetScriptNames(t *testing.T) {
+	test.NewTempApp(t)
+
+	tab := NewSessionTab(&SessionTabConfig{
+		SessionID:   "session-1",
+		AccountName: "Test Account",
+		ScriptNames: []string{"arena", "daily"},
+		OnStop:      func(string) {},
+	})
+	defer tab.Dispose()
+	tab.SetScriptSelection("daily")
+
+	tab.SetScriptNames([]string{"daily", "tower"})
+	if tab.scriptSelect.Selected != "daily" || len(tab.scriptSelect.Options) != 2 {
+		t.Errorf("selection = %q, options = %v; want daily kept", tab.scriptSelect.Selected, tab.scriptSelect.Options)
+	}
+
+	tab.SetScriptNames([]string{"tower"})
+	if tab.scriptSelect.Selected != "tower" {
+		t.Errorf("selection = %q, want tower after daily was removed", tab.scriptSelect.Selected)
+	}
+}
+
+func TestSessionTab_PauseButton(t *testing.T) {
+	test.NewTempApp(t)
+
+	tab := NewSessionTab(&SessionTabConfig{
+		SessionID:   "session-1",
+		AccountName: "Test Account",
+		OnStop:      func(string) {},
+	})
+	defer tab.Dispose()
+	if !tab.pauseBtn.Disabled() {
+		t.Error("pause enabled without a running script")
+	}
+
+	tab.UpdateState(state.StateScriptRunning)
+	if tab.pauseBtn.Disabled() || tab.pauseBtn.Text != "Pause" {
+		t.Errorf("running: pause disabled = %v, text = %q", tab.pauseBtn.Disabled(), tab.pauseBtn.Text)
+	}
+	tab.UpdateState(state.StateScriptPaused)
+	if tab.pauseBtn.Text != "Resume" || tab.scriptBtn.Text != "Stop" {
+		t.Errorf("paused: pause = %q, script = %q; want Resume and Stop", tab.pauseBtn.Text, tab.scriptBtn.Text)
+	}
+
+	// Stopping a paused script resets the button
+	tab.SetScriptRunning(false)
+	if !tab.pauseBtn.Disabled() || tab.pauseBtn.Text != "Pause" {
+		t.Errorf("stopped: pause disabled = %v, text = %q", tab.pauseBtn.Disabled(), tab.pauseBtn.Text)
+	}
+}
+
+func TestSessionTab_SetHalted(t *testing.T) {
+	test.NewTempApp(t)
+
+	tab := NewSessionTab(&SessionTabConfig{
+		SessionID:   "session-1",
+		AccountName: "Test Account",
+		OnStop:      func(string) {},
+	})
+	defer tab.Dispose()
+	tab.SetScriptRunning(true)
+
+	tab.SetHalted("daily", "open_bag", "Click at (10, 20)")
+	if !tab.haltBox.Visible() || tab.haltLabel.Text != "Next: Click at (10, 20) · daily / open_bag" {
+		t.Errorf("halt = %q (visible %v)", tab.haltLabel.Text, tab.haltBox.Visible())
+	}
+
+	// Stepping hides the action until the next halt
+	tab.stepBtn.OnTapped()
+	if tab.haltBox.Visible() {
+		t.Error("pending action shown after Step")
+	}
+
+	tab.SetHalted("daily", "", "Wait 1s")
+	tab.SetScriptRunning(false)
+	if tab.haltBox.Visible() {
+		t.Error("pending action shown after the script stopped")
+	}
+}
+
+func TestSessionTab_SetCounters(t *testing.T) {
+	test.NewTempApp(t)
+
+	tab := NewSessionTab(&SessionTabConfig{
+		SessionID:   "session-1",
+		AccountName: "Test Account",
+		OnStop:      func(string) {},
+	})
+	defer tab.Dispose()
+	if tab.countersLabel.Visible() {
+		t.Error("counters shown before any update")
+	}
+
+	tab.SetCounters(map[string]int{"runs": 3, "battles_won": 17})
+	if got := tab.countersLabel.Text; got != "battles_won: 17 · runs: 3" || !tab.countersLabel.Visible() {
+		t.Errorf("counters = %q, want sorted counters shown", got)
+	}
+
+	tab.SetCounters(nil)
+	if tab.countersLabel.Visible() {
+		t.Error("counters shown after an empty update")
+	}
+}
+
+func TestSessionTab_CreateRemoveCycles(t *testing.T) {
+	test.NewTempApp(t)
+	audit := newLifecycleAudit(nil)
+
+	cycle := func() {
+		tab := NewSessionTab(&SessionTabConfig{
+			SessionID:   "session-1",
+			AccountName: "Test Account",
+			ScriptNames: []string{"daily", "arena"},
+			OnStop:      func(string) {},
+		})
+		callbacks := &CanvasCallbacks{
+			sessionTab: tab,
+			onClick:    tab.HandleCanvasClick(nil),
+			onDrag:     tab.HandleCanvasDrag(nil),
+			onScroll:   tab.HandleCanvasScroll(),
+			onKey:      tab.HandleCanvasKey(),
+		}
+
+		callbacks.Dispose()
+		tab.Dispose()
+		audit.TrackDisposed(tab)
+	}
+
+	// Heap size is not checked: Fyne caches the renderers of disabled and
+	// refreshed widgets for a minute and frees them on a later paint, which
+	// never happens without a window.
+	for i := 0; i < 100; i++ {
+		cycle()
+	}
+	runtime.GC()
+	runtime.GC()
+
+	if leaked := audit.Leaked(0); len(leaked) != 0 {
+		t.Errorf("%d disposed tabs still reachable", len(leaked))
+	}
+}
@@ -0,0 +1,168 @@
+package presentation
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/domain/group"
+	"wardenly-go/domain/schedule"
+)
+
+// TemplateFormConfig holds configuration for TemplateForm.
+type TemplateFormConfig struct {
+	ScriptNames []string
+	OnSave      func(*group.Template)
+	OnDelete    func(*group.Template)
+	OnNewGroup  func(*group.Template)
+}
+
+// TemplateForm provides a form for editing a group template.
+type TemplateForm struct {
+	config    *TemplateFormConfig
+	container *fyne.Container
+
+	// Form fields
+	nameEntry        *widget.Entry
+	descriptionEntry *widget.Entry
+	settings         *runSettingsFields
+	specEntry        *widget.Entry
+	derivedLabel     *widget.Label
+
+	// Buttons
+	saveBtn     *widget.Button
+	deleteBtn   *widget.Button
+	newGroupBtn *widget.Button
+
+	// Current template being edited
+	current *group.Template
+}
+
+// NewTemplateForm creates a new template editing form.
+func NewTemplateForm(cfg *TemplateFormConfig) *TemplateForm {
+	tf := &TemplateForm{config: cfg}
+	tf.build()
+	return tf
+}
+
+func (tf *TemplateForm) build() {
+	tf.nameEntry = widget.NewEntry()
+	tf.nameEntry.SetPlaceHolder("Template name")
+
+	tf.descriptionEntry = widget.NewMultiLineEntry()
+	tf.descriptionEntry.SetPlaceHolder("Optional description")
+	tf.descriptionEntry.SetMinRowsVisible(2)
+
+	tf.settings = newRunSettingsFields(tf.config.ScriptNames)
+
+	tf.specEntry = widget.NewEntry()
+	tf.specEntry.SetPlaceHolder("Optional: daily 04:30 or cron: 30 4 * * 1-5")
+	tf.specEntry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		_, err := schedule.ParseSpec(s)
+		return err
+	}
+
+	tf.derivedLabel = widget.NewLabel("")
+
+	// Use widget.Form for proper alignment
+	form := widget.NewForm(
+		widget.NewFormItem("Name", tf.nameEntry),
+		widget.NewFormItem("Description", tf.descriptionEntry),
+	)
+	for _, item := range tf.settings.formItems() {
+		form.AppendItem(item)
+	}
+	form.AppendItem(widget.NewFormItem("Schedule", tf.specEntry))
+	form.AppendItem(widget.NewFormItem("Groups", tf.derivedLabel))
+
+	// Buttons with icons - Delete on left, Save on right
+	tf.deleteBtn = widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), tf.onDelete)
+	tf.deleteBtn.Importance = widget.DangerImportance
+
+	tf.newGroupBtn = widget.NewButtonWithIcon("New Group...", theme.ContentAddIcon(), tf.onNewGroup)
+
+	tf.saveBtn = widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), tf.onSave)
+	tf.saveBtn.Importance = widget.HighImportance
+
+	buttonBar := container.NewHBox(
+		tf.deleteBtn,
+		layout.NewSpacer(),
+		tf.newGroupBtn,
+		tf.saveBtn,
+	)
+
+	tf.container = container.NewPadded(container.NewBorder(
+		nil,
+		container.NewVBox(widget.NewSeparator(), buttonBar),
+		nil, nil,
+		container.NewVScroll(form),
+	))
+}
+
+// Container returns the form container.
+func (tf *TemplateForm) Container() fyne.CanvasObject {
+	return tf.container
+}
+
+// SetTemplate populates the form with template data.
+// Pass nil to clear the form for creating a new template.
+// derived is the number of groups created from the template.
+func (tf *TemplateForm) SetTemplate(tmpl *group.Template, derived int) {
+	tf.current = tmpl
+
+	if tmpl == nil {
+		tf.nameEntry.SetText("")
+		tf.descriptionEntry.SetText("")
+		tf.settings.set(group.RunSettings{})
+		tf.specEntry.SetText("")
+		tf.derivedLabel.SetText("-")
+		tf.deleteBtn.Disable()
+		tf.newGroupBtn.Disable()
+		return
+	}
+
+	tf.nameEntry.SetText(tmpl.Name)
+	tf.descriptionEntry.SetText(tmpl.Description)
+	tf.settings.set(tmpl.Settings)
+	tf.specEntry.SetText(tmpl.ScheduleSpec)
+	tf.derivedLabel.SetText(fmt.Sprintf("%d created from this template", derived))
+	tf.deleteBtn.Enable()
+	tf.newGroupBtn.Enable()
+}
+
+func (tf *TemplateForm) onSave() {
+	tmpl := &group.Template{
+		Name:         tf.nameEntry.Text,
+		Description:  tf.descriptionEntry.Text,
+		Settings:     tf.settings.get(),
+		ScheduleSpec: tf.specEntry.Text,
+	}
+
+	// Preserve ID if editing
+	if tf.current != nil {
+		tmpl.ID = tf.current.ID
+	}
+
+	if tf.config.OnSave != nil {
+		tf.config.OnSave(tmpl)
+	}
+}
+
+func (tf *TemplateForm) onDelete() {
+	if tf.current != nil && tf.config.OnDelete != nil {
+		tf.config.OnDelete(tf.current)
+	}
+}
+
+func (tf *TemplateForm) onNewGroup() {
+	if tf.current != nil && tf.config.OnNewGroup != nil {
+		tf.config.OnNewGroup(tf.current)
+	}
+}
@@ -0,0 +1,247 @@
+package presentation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/infrastructure/trace"
+)
+
+// traceLoadTimeout bounds loading runs or entries from the store.
+const traceLoadTimeout = 10 * time.Second
+
+// traceKindAll shows every entry kind in the trace viewer.
+const traceKindAll = "All"
+
+// TraceDialogConfig holds configuration for the script trace viewer.
+type TraceDialogConfig struct {
+	Store  trace.Store
+	Logger *slog.Logger
+}
+
+// traceDialog lists recorded script runs and the steps, actions and OCR
+// results of the selected one.
+type traceDialog struct {
+	config *TraceDialogConfig
+	window fyne.Window
+
+	runSelect    *widget.Select
+	kindSelect   *widget.Select
+	searchEntry  *widget.Entry
+	summaryLabel *widget.Label
+	entryList    *widget.List
+	detailLabel  *widget.Label
+
+	runs    map[string]trace.Run // Select label -> run
+	entries []trace.Entry        // Selected run
+	shown   []trace.Entry        // Entries passing the filters
+}
+
+// ShowTraceDialog displays the script trace viewer.
+func ShowTraceDialog(cfg *TraceDialogConfig) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	d := &traceDialog{config: cfg}
+	d.window = fyne.CurrentApp().NewWindow("Script Traces")
+	d.buildUI()
+
+	d.window.Resize(fyne.NewSize(1000, 600))
+	d.window.CenterOnScreen()
+	d.window.Show()
+
+	d.loadRuns()
+}
+
+func (d *traceDialog) buildUI() {
+	d.runSelect = widget.NewSelect(nil, d.loadRun)
+	d.runSelect.PlaceHolder = "Select Run"
+	d.kindSelect = widget.NewSelect(
+		[]string{traceKindAll, trace.KindStep, trace.KindAction, trace.KindOCR, trace.KindText},
+		func(string) { d.applyFilter() },
+	)
+	d.kindSelect.SetSelected(traceKindAll)
+	d.searchEntry = widget.NewEntry()
+	d.searchEntry.SetPlaceHolder("Filter scene, detail or hash")
+	d.searchEntry.OnChanged = func(string) { d.applyFilter() }
+	refreshBtn := widget.NewButton("Refresh", d.loadRuns)
+
+	d.summaryLabel = widget.NewLabel("")
+	d.summaryLabel.Wrapping = fyne.TextWrapWord
+
+	d.entryList = widget.NewList(
+		func() int { return len(d.shown) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(traceEntryLine(d.shown[id]))
+		},
+	)
+	d.entryList.OnSelected = d.showEntry
+
+	d.detailLabel = widget.NewLabel("")
+	d.detailLabel.Wrapping = fyne.TextWrapWord
+
+	filters := container.NewBorder(nil, nil, container.NewHBox(d.kindSelect), refreshBtn, d.searchEntry)
+	top := container.NewVBox(d.runSelect, filters, d.summaryLabel)
+	split := container.NewHSplit(d.entryList, container.NewVScroll(d.detailLabel))
+	split.Offset = 0.65
+	d.window.SetContent(container.NewBorder(top, nil, nil, nil, split))
+}
+
+func (d *traceDialog) loadRuns() {
+	ctx, cancel := context.WithTimeout(context.Background(), traceLoadTimeout)
+	defer cancel()
+	runs, err := d.config.Store.Runs(ctx)
+	if err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+
+	d.runs = make(map[string]trace.Run, len(runs))
+	labels := make([]string, 0, len(runs))
+	for _, run := range runs {
+		label := traceRunLabel(run)
+		d.runs[label] = run
+		labels = append(labels, label)
+	}
+	d.runSelect.ClearSelected()
+	d.runSelect.SetOptions(labels)
+	if len(labels) > 0 {
+		d.runSelect.SetSelectedIndex(0)
+	} else {
+		d.loadRun("")
+	}
+}
+
+func (d *traceDialog) loadRun(label string) {
+	d.entries = nil
+	run, ok := d.runs[label]
+	if ok {
+		ctx, cancel := context.WithTimeout(context.Background(), traceLoadTimeout)
+		defer cancel()
+		entries, err := d.config.Store.Entries(ctx, run.ID)
+		if err != nil {
+			d.config.Logger.Warn("Failed to load script trace", "run", run.ID, "error", err)
+			dialog.ShowError(err, d.window)
+		}
+		d.entries = entries
+	}
+
+	if !ok {
+		d.summaryLabel.SetText("No recorded runs")
+	} else {
+		d.summaryLabel.SetText(traceRunSummary(run, d.entries))
+	}
+	d.applyFilter()
+}
+
+// applyFilter shows the entries matching the kind and search text.
+func (d *traceDialog) applyFilter() {
+	if d.entryList == nil {
+		return
+	}
+	kind := d.kindSelect.Selected
+	query := strings.ToLower(strings.TrimSpace(d.searchEntry.Text))
+
+	d.shown = d.shown[:0]
+	for _, e := range d.entries {
+		if kind != "" && kind != traceKindAll && e.Kind != kind {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(e.Scene+" "+e.Script+" "+e.Detail+" "+e.ScreenHash), query) {
+			continue
+		}
+		d.shown = append(d.shown, e)
+	}
+	d.entryList.UnselectAll()
+	d.entryList.Refresh()
+	d.detailLabel.SetText(fmt.Sprintf("%d of %d entries", len(d.shown), len(d.entries)))
+}
+
+func (d *traceDialog) showEntry(id widget.ListItemID) {
+	if id < 0 || id >= len(d.shown) {
+		return
+	}
+	e := d.shown[id]
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %s\n", e.Time.Local().Format("2006-01-02 15:04:05.000"), e.Kind)
+	if e.Script != "" {
+		fmt.Fprintf(&b, "Called script: %s\n", e.Script)
+	}
+	if e.Kind == trace.KindStep {
+		fmt.Fprintf(&b, "Step %d on scene %s\n", e.Step, e.Scene)
+	}
+	if e.ScreenHash != "" {
+		fmt.Fprintf(&b, "Screen hash: %s\n", e.ScreenHash)
+	}
+	b.WriteString(e.Detail)
+	d.detailLabel.SetText(b.String())
+}
+
+func traceRunLabel(run trace.Run) string {
+	account := run.AccountName
+	if account == "" {
+		account = run.SessionID
+	}
+	status := run.StopReason
+	if run.StoppedAt.IsZero() {
+		status = "Running"
+	}
+	return fmt.Sprintf("%s  %s  %s  (%s, %d steps)",
+		run.StartedAt.Local().Format("2006-01-02 15:04"), account, run.ScriptName, status, run.Steps)
+}
+
+// traceRunSummary describes a run and the scenes it spent the most steps on.
+func traceRunSummary(run trace.Run, entries []trace.Entry) string {
+	end := run.StoppedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	summary := fmt.Sprintf("Ran %s", end.Sub(run.StartedAt).Round(time.Second))
+	if run.Error != "" {
+		summary += " · Error: " + run.Error
+	}
+
+	counts := trace.SceneCounts(entries)
+	if len(counts) > 5 {
+		counts = counts[:5]
+	}
+	if len(counts) > 0 {
+		parts := make([]string, len(counts))
+		for i, c := range counts {
+			parts[i] = fmt.Sprintf("%s ×%d (%d screens)", c.Scene, c.Count, c.Screens)
+		}
+		summary += "\nTop scenes: " + strings.Join(parts, ", ")
+	}
+	return summary
+}
+
+func traceEntryLine(e trace.Entry) string {
+	where := e.Scene
+	if e.Script != "" {
+		where = e.Script + ":" + where
+	}
+	if e.Kind == trace.KindStep {
+		where = fmt.Sprintf("#%d %s", e.Step, where)
+	}
+	parts := []string{e.Time.Local().Format("15:04:05.000"), e.Kind}
+	for _, s := range []string{where, e.Detail} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,84 @@
+package presentation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"wardenly-go/infrastructure/update"
+)
+
+// updateCheckTimeout bounds a release feed request.
+const updateCheckTimeout = 30 * time.Second
+
+// UpdateDialogConfig holds configuration for the update dialog.
+type UpdateDialogConfig struct {
+	Updater *update.Updater
+	Release *update.Release
+	Logger  *slog.Logger
+}
+
+// ShowUpdateDialog shows the changelog of a newer release and installs it on request.
+func ShowUpdateDialog(cfg *UpdateDialogConfig) {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	w := fyne.CurrentApp().NewWindow("Update Available")
+
+	header := widget.NewLabelWithStyle(
+		fmt.Sprintf("Wardenly %s is available (running %s).", cfg.Release.Version, cfg.Updater.CurrentVersion()),
+		fyne.TextAlignLeading, fyne.TextStyle{Bold: true},
+	)
+	changelog := widget.NewRichTextFromMarkdown(cfg.Release.Changelog)
+	changelog.Wrapping = fyne.TextWrapWord
+
+	progress := widget.NewProgressBarInfinite()
+	progress.Stop()
+	progress.Hide()
+
+	laterBtn := widget.NewButton("Later", w.Close)
+	var installBtn *widget.Button
+	installBtn = widget.NewButtonWithIcon("Install", theme.DownloadIcon(), func() {
+		installBtn.Disable()
+		laterBtn.Disable()
+		progress.Show()
+		progress.Start()
+
+		go func() {
+			err := cfg.Updater.Install(context.Background(), cfg.Release)
+
+			fyne.Do(func() {
+				progress.Stop()
+				progress.Hide()
+				laterBtn.Enable()
+				if err != nil {
+					cfg.Logger.Error("Failed to install update", "version", cfg.Release.Version, "error", err)
+					installBtn.Enable()
+					dialog.ShowError(err, w)
+					return
+				}
+				laterBtn.SetText("Close")
+				dialog.ShowInformation("Update Installed",
+					fmt.Sprintf("Wardenly %s will be used the next time the application starts.", cfg.Release.Version), w)
+			})
+		}()
+	})
+	installBtn.Importance = widget.HighImportance
+
+	top := container.NewVBox(header, widget.NewSeparator())
+	bottom := container.NewVBox(progress, container.NewHBox(layout.NewSpacer(), laterBtn, installBtn))
+	w.SetContent(container.NewPadded(container.NewBorder(top, bottom, nil, nil, container.NewVScroll(changelog))))
+
+	w.Resize(fyne.NewSize(480, 400))
+	w.CenterOnScreen()
+	w.Show()
+}
@@ -18,5 +18,7 @@ func GetAppIcon() fyne.Resource {
 //go:embed scripts/*.yaml
 var ScriptFiles embed.FS
 
-//go:embed scenes/*.yaml
+// Scene files and the template images they reference
+//
+//go:embed scenes
 var SceneFiles embed.FS